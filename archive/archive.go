@@ -0,0 +1,93 @@
+// Package archive provides archivers that retain the messages deleted by DynamoMQ as NDJSON objects,
+// one JSON document of a dynamomq.Message per line, in Amazon S3.
+// Set one with dynamomq.WithArchiver to archive every message before it is deleted.
+package archive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/google/uuid"
+	"github.com/vvatanabe/dynamomq"
+)
+
+// ContentType is the content type of the objects written by S3Archiver.
+const ContentType = "application/x-ndjson"
+
+// BatchArchiver archives several messages at once. Buffered groups the messages it is given into batches for it.
+type BatchArchiver[T any] interface {
+	// ArchiveBatch archives the messages. A returned error means that none of them may have been archived.
+	ArchiveBatch(ctx context.Context, msgs []*dynamomq.Message[T]) error
+}
+
+// S3API is the subset of the Amazon S3 API used by S3Archiver. *s3.Client satisfies this interface.
+type S3API interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+// S3Archiver writes every message or batch of messages it archives to a new NDJSON object of an S3 bucket.
+// The keys start with the prefix, followed by the date of the archive, so that a lifecycle rule
+// can expire the archives after the retention period:
+//
+//	<prefix>2006/01/02/150405.000000000-<uuid>.ndjson
+type S3Archiver[T any] struct {
+	api    S3API
+	bucket string
+	prefix string
+	now    func() time.Time
+}
+
+var (
+	_ dynamomq.Archiver[any] = (*S3Archiver[any])(nil)
+	_ BatchArchiver[any]     = (*S3Archiver[any])(nil)
+)
+
+// NewS3Archiver creates an S3Archiver that puts objects to the bucket, with keys starting with prefix.
+func NewS3Archiver[T any](api S3API, bucket, prefix string) *S3Archiver[T] {
+	return &S3Archiver[T]{
+		api:    api,
+		bucket: bucket,
+		prefix: prefix,
+		now:    time.Now,
+	}
+}
+
+// Archive writes the message to an object of its own.
+func (a *S3Archiver[T]) Archive(ctx context.Context, msg *dynamomq.Message[T]) error {
+	return a.ArchiveBatch(ctx, []*dynamomq.Message[T]{msg})
+}
+
+// ArchiveBatch writes the messages to a single object, one per line.
+func (a *S3Archiver[T]) ArchiveBatch(ctx context.Context, msgs []*dynamomq.Message[T]) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	var body bytes.Buffer
+	for _, msg := range msgs {
+		// The payload is archived even when it is excluded from the JSON of messages with dynamomq.SetJSONIncludeData.
+		line, err := dynamomq.MarshalMessageJSON(msg, true)
+		if err != nil {
+			return fmt.Errorf("failed to marshal message %s: %w", msg.ID, err)
+		}
+		body.Write(line)
+		body.WriteByte('\n')
+	}
+	if _, err := a.api.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(a.bucket),
+		Key:         aws.String(a.key()),
+		ContentType: aws.String(ContentType),
+		Body:        bytes.NewReader(body.Bytes()),
+	}); err != nil {
+		return fmt.Errorf("failed to put the archive to S3: %w", err)
+	}
+	return nil
+}
+
+func (a *S3Archiver[T]) key() string {
+	now := a.now().UTC()
+	return a.prefix + now.Format("2006/01/02/150405.000000000") + "-" + uuid.NewString() + ".ndjson"
+}
@@ -0,0 +1,173 @@
+package archive_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/archive"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func newMessages(ids ...string) []*dynamomq.Message[test.MessageData] {
+	msgs := make([]*dynamomq.Message[test.MessageData], 0, len(ids))
+	for _, id := range ids {
+		msgs = append(msgs, dynamomq.NewMessage(id, test.NewMessageData(id), test.DefaultTestDate))
+	}
+	return msgs
+}
+
+func decodeIDs(t *testing.T, body []byte) []string {
+	t.Helper()
+	var ids []string
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		var msg dynamomq.Message[test.MessageData]
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			t.Fatalf("line %q is not a message: %v", scanner.Text(), err)
+		}
+		ids = append(ids, msg.ID)
+	}
+	return ids
+}
+
+type object struct {
+	Bucket      string
+	Key         string
+	ContentType string
+	Body        []byte
+}
+
+type fakeS3 struct {
+	objects []object
+	err     error
+}
+
+func (f *fakeS3) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	body, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.objects = append(f.objects, object{
+		Bucket:      aws.ToString(params.Bucket),
+		Key:         aws.ToString(params.Key),
+		ContentType: aws.ToString(params.ContentType),
+		Body:        body,
+	})
+	return &s3.PutObjectOutput{}, nil
+}
+
+func TestS3Archiver(t *testing.T) {
+	t.Parallel()
+	api := &fakeS3{}
+	archiver := archive.NewS3Archiver[test.MessageData](api, "audit-bucket", "orders/")
+	if err := archiver.ArchiveBatch(context.Background(), newMessages("A-101", "A-102")); err != nil {
+		t.Fatalf("ArchiveBatch() error = %v", err)
+	}
+	if err := archiver.Archive(context.Background(), newMessages("A-103")[0]); err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+	objects := api.objects
+	if len(objects) != 2 {
+		t.Fatalf("objects = %d, want 2", len(objects))
+	}
+	for _, o := range objects {
+		if o.Bucket != "audit-bucket" || o.ContentType != archive.ContentType ||
+			!strings.HasPrefix(o.Key, "orders/") || !strings.HasSuffix(o.Key, ".ndjson") {
+			t.Errorf("object = %+v", o)
+		}
+	}
+	if objects[0].Key == objects[1].Key {
+		t.Errorf("objects share the key %s", objects[0].Key)
+	}
+	test.AssertDeepEqual(t, decodeIDs(t, objects[0].Body), []string{"A-101", "A-102"}, "first object")
+	test.AssertDeepEqual(t, decodeIDs(t, objects[1].Body), []string{"A-103"}, "second object")
+}
+
+func TestS3ArchiverError(t *testing.T) {
+	t.Parallel()
+	archiver := archive.NewS3Archiver[test.MessageData](&fakeS3{err: test.ErrTest}, "audit-bucket", "")
+	test.AssertError(t, archiver.Archive(context.Background(), newMessages("A-101")[0]), test.ErrTest, "Archive()")
+}
+
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches [][]string
+	err     error
+}
+
+func (r *batchRecorder) ArchiveBatch(ctx context.Context, msgs []*dynamomq.Message[test.MessageData]) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		ids = append(ids, msg.ID)
+	}
+	r.batches = append(r.batches, ids)
+	return r.err
+}
+
+func TestBufferedShouldWriteFullBatch(t *testing.T) {
+	t.Parallel()
+	recorder := &batchRecorder{}
+	buffered := archive.NewBuffered[test.MessageData](recorder,
+		archive.WithBatchSize(5),
+		archive.WithFlushInterval(time.Hour))
+	var wg sync.WaitGroup
+	for _, msg := range newMessages("A-101", "A-102", "A-103", "A-104", "A-105") {
+		wg.Add(1)
+		go func(msg *dynamomq.Message[test.MessageData]) {
+			defer wg.Done()
+			if err := buffered.Archive(context.Background(), msg); err != nil {
+				t.Errorf("Archive() error = %v", err)
+			}
+		}(msg)
+	}
+	wg.Wait()
+	if len(recorder.batches) != 1 || len(recorder.batches[0]) != 5 {
+		t.Errorf("batches = %v, want one batch of 5 messages", recorder.batches)
+	}
+}
+
+func TestBufferedShouldWriteBatchAfterFlushInterval(t *testing.T) {
+	t.Parallel()
+	recorder := &batchRecorder{err: test.ErrTest}
+	buffered := archive.NewBuffered[test.MessageData](recorder,
+		archive.WithBatchSize(100),
+		archive.WithFlushInterval(10*time.Millisecond))
+	err := buffered.Archive(context.Background(), newMessages("A-101")[0])
+	test.AssertError(t, err, test.ErrTest, "Archive()")
+	test.AssertDeepEqual(t, recorder.batches, [][]string{{"A-101"}}, "batches")
+}
+
+func TestBufferedClose(t *testing.T) {
+	t.Parallel()
+	recorder := &batchRecorder{}
+	buffered := archive.NewBuffered[test.MessageData](recorder, archive.WithFlushInterval(time.Hour))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// The caller gives up waiting, but the message is still written with its batch.
+	if err := buffered.Archive(ctx, newMessages("A-101")[0]); !errors.Is(err, context.Canceled) {
+		t.Errorf("Archive() error = %v, want %v", err, context.Canceled)
+	}
+	if err := buffered.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	test.AssertDeepEqual(t, recorder.batches, [][]string{{"A-101"}}, "batches")
+	if err := buffered.Archive(context.Background(), newMessages("A-102")[0]); !errors.Is(err, archive.ErrBufferedClosed) {
+		t.Errorf("Archive() after Close() error = %v, want %v", err, archive.ErrBufferedClosed)
+	}
+}
@@ -0,0 +1,163 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/vvatanabe/dynamomq"
+)
+
+const (
+	defaultBatchSize     = 100
+	defaultFlushInterval = time.Second
+)
+
+// ErrBufferedClosed is an error that indicates the Buffered archiver has been closed.
+var ErrBufferedClosed = errors.New("DynamoMQ: Buffered archiver closed")
+
+// BufferedOptions contains configuration options for a Buffered archiver.
+type BufferedOptions struct {
+	// BatchSize is the number of messages that makes a batch be written immediately.
+	BatchSize int
+	// FlushInterval is the maximum time a message waits for its batch to fill up before the batch is written.
+	FlushInterval time.Duration
+}
+
+// WithBatchSize sets the number of messages that makes a batch be written immediately.
+func WithBatchSize(batchSize int) func(o *BufferedOptions) {
+	return func(o *BufferedOptions) {
+		o.BatchSize = batchSize
+	}
+}
+
+// WithFlushInterval sets the maximum time a message waits for its batch to fill up before the batch is written.
+func WithFlushInterval(flushInterval time.Duration) func(o *BufferedOptions) {
+	return func(o *BufferedOptions) {
+		o.FlushInterval = flushInterval
+	}
+}
+
+// Buffered is an Archiver grouping the messages archived concurrently, for example by the workers of a Consumer,
+// into batches written with a BatchArchiver. A batch is written when it holds BatchSize messages,
+// or FlushInterval after its first message, whichever comes first.
+// Archive returns only once the batch of the message has been written, with the error of the batch,
+// so that no message is deleted before it has been archived.
+// Note: To create a new instance of Buffered, it is necessary to use the NewBuffered function.
+type Buffered[T any] struct {
+	sink          BatchArchiver[T]
+	batchSize     int
+	flushInterval time.Duration
+
+	mu      sync.Mutex
+	pending *batch[T]
+	closed  bool
+}
+
+var _ dynamomq.Archiver[any] = (*Buffered[any])(nil)
+
+type batch[T any] struct {
+	msgs  []*dynamomq.Message[T]
+	timer *time.Timer
+	done  chan struct{}
+	err   error
+}
+
+// NewBuffered creates a Buffered archiver writing its batches with sink.
+func NewBuffered[T any](sink BatchArchiver[T], opts ...func(o *BufferedOptions)) *Buffered[T] {
+	o := &BufferedOptions{
+		BatchSize:     defaultBatchSize,
+		FlushInterval: defaultFlushInterval,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = defaultBatchSize
+	}
+	return &Buffered[T]{
+		sink:          sink,
+		batchSize:     o.BatchSize,
+		flushInterval: o.FlushInterval,
+	}
+}
+
+// Archive adds the message to the current batch and waits until the batch has been written.
+// If ctx is done before, ctx.Err() is returned and the message is still written with its batch.
+func (b *Buffered[T]) Archive(ctx context.Context, msg *dynamomq.Message[T]) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrBufferedClosed
+	}
+	current := b.pending
+	if current == nil {
+		current = &batch[T]{done: make(chan struct{})}
+		current.timer = time.AfterFunc(b.flushInterval, func() {
+			b.flushBatch(current)
+		})
+		b.pending = current
+	}
+	current.msgs = append(current.msgs, msg)
+	full := len(current.msgs) >= b.batchSize
+	if full {
+		b.pending = nil
+	}
+	b.mu.Unlock()
+	if full {
+		b.write(current)
+	}
+	select {
+	case <-current.done:
+		return current.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Flush writes the current batch without waiting for it to fill up, and returns the error of the batch.
+func (b *Buffered[T]) Flush(ctx context.Context) error {
+	b.mu.Lock()
+	current := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+	if current == nil {
+		return nil
+	}
+	b.write(current)
+	select {
+	case <-current.done:
+		return current.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close writes the current batch and makes any later Archive return ErrBufferedClosed.
+func (b *Buffered[T]) Close(ctx context.Context) error {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	return b.Flush(ctx)
+}
+
+// flushBatch writes the batch when its flush interval has passed, unless it has already been taken.
+func (b *Buffered[T]) flushBatch(current *batch[T]) {
+	b.mu.Lock()
+	if b.pending != current {
+		b.mu.Unlock()
+		return
+	}
+	b.pending = nil
+	b.mu.Unlock()
+	b.write(current)
+}
+
+// write writes a batch that is no longer pending. The batch serves several callers,
+// so it is not bound to the context of any of them.
+func (b *Buffered[T]) write(current *batch[T]) {
+	current.timer.Stop()
+	current.err = b.sink.ArchiveBatch(context.Background(), current.msgs)
+	close(current.done)
+}
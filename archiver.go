@@ -0,0 +1,106 @@
+package dynamomq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// maxArchiveAttempts is the number of times DeleteMessage archives a message again
+// when it was updated between the archive and the deletion.
+const maxArchiveAttempts = 3
+
+// Archiver archives messages before they are deleted, for example to retain the processed messages for an audit.
+// Ready-made archivers writing NDJSON objects to Amazon S3, one message or a batch of messages at a time,
+// are provided by the archive module.
+type Archiver[T any] interface {
+	// Archive is called before the message is deleted. A returned error aborts the deletion.
+	Archive(ctx context.Context, msg *Message[T]) error
+}
+
+// ArchiverFunc is a functional type that implements the Archiver interface.
+type ArchiverFunc[T any] func(ctx context.Context, msg *Message[T]) error
+
+// Archive calls the ArchiverFunc itself.
+func (f ArchiverFunc[T]) Archive(ctx context.Context, msg *Message[T]) error {
+	return f(ctx, msg)
+}
+
+func archiverOf[T any](archiver any) (Archiver[T], error) {
+	if archiver == nil {
+		return nil, nil
+	}
+	a, ok := archiver.(Archiver[T])
+	if !ok {
+		return nil, InvalidArchiverError{Archiver: typeName(archiver), MessageType: typeNameOf[T]()}
+	}
+	return a, nil
+}
+
+func typeName(v any) string {
+	return fmt.Sprintf("%T", v)
+}
+
+func typeNameOf[T any]() string {
+	return reflect.TypeOf((*T)(nil)).Elem().String()
+}
+
+// archiveAndDeleteMessage archives the message before deleting it, and deletes it only if it has not been updated
+// since it was archived. A message updated in between, for example because its visibility timeout expired
+// and it was received again, is archived again, so an Archiver can see a message more than once.
+func (c *ClientImpl[T]) archiveAndDeleteMessage(ctx context.Context, params *DeleteMessageInput) error {
+	for attempt := 1; ; attempt++ {
+		retrieved, err := c.GetMessage(ctx, &GetMessageInput{
+			ID: params.ID,
+		})
+		if err != nil {
+			return err
+		}
+		message := retrieved.Message
+		if message == nil {
+			if params.StrictExistenceCheck || params.ExpectedVersion > 0 {
+				return &IDNotFoundError{}
+			}
+			return nil
+		}
+		if params.ExpectedVersion > 0 && message.Version != params.ExpectedVersion {
+			return VersionConflictError{ID: message.ID, Version: params.ExpectedVersion}
+		}
+		if err := c.archiver.Archive(ctx, message); err != nil {
+			return ArchiveError{ID: message.ID, Cause: err}
+		}
+		expr, err := expression.NewBuilder().
+			WithCondition(expression.Name(c.schema.VersionAttribute).Equal(expression.Value(message.Version))).
+			Build()
+		if err != nil {
+			return BuildingExpressionError{Cause: err}
+		}
+		_, err = c.dynamoDB.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+			TableName:                 aws.String(c.tableName),
+			Key:                       c.itemKey(message.ID),
+			ConditionExpression:       expr.Condition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+		})
+		if err != nil {
+			var cause *types.ConditionalCheckFailedException
+			if !errors.As(err, &cause) {
+				return handleDynamoDBError(err)
+			}
+			if params.ExpectedVersion > 0 || attempt == maxArchiveAttempts {
+				return VersionConflictError{ID: message.ID, Version: message.Version}
+			}
+			continue
+		}
+		if message.InFlightSlot {
+			c.releaseInFlightSlot(ctx, message.ID)
+		}
+		return nil
+	}
+}
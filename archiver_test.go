@@ -0,0 +1,219 @@
+package dynamomq_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+// newArchivingDynamoDB returns a table holding the message at the versions returned by successive reads,
+// whose deletions fail with a conditional check failure while conflicts remain.
+func newArchivingDynamoDB(versions []int, conflicts int, deletes *atomic.Int32) *mock.DynamoDB {
+	var reads atomic.Int32
+	return &mock.DynamoDB{
+		GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+			i := int(reads.Add(1)) - 1
+			if i >= len(versions) {
+				return &dynamodb.GetItemOutput{}, nil
+			}
+			msg := dynamomqtest.NewProcessingMessage("A-101", test.NewMessageData("A-101"), test.DefaultTestDate,
+				dynamomqtest.WithVersion(versions[i]))
+			return &dynamodb.GetItemOutput{Item: dynamomqtest.MarshalMap(msg)}, nil
+		},
+		DeleteItemFunc: func(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+			if int(deletes.Add(1)) <= conflicts {
+				return nil, &types.ConditionalCheckFailedException{Message: aws.String("version changed")}
+			}
+			return &dynamodb.DeleteItemOutput{}, nil
+		},
+		TransactWriteItemsFunc: func(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+			deletes.Add(1)
+			return &dynamodb.TransactWriteItemsOutput{}, nil
+		},
+	}
+}
+
+func TestDynamoMQClientDeleteMessageWithArchiver(t *testing.T) {
+	t.Parallel()
+	var archived []int
+	archiver := dynamomq.ArchiverFunc[test.MessageData](func(ctx context.Context, msg *dynamomq.Message[test.MessageData]) error {
+		archived = append(archived, msg.Version)
+		return nil
+	})
+	tests := []struct {
+		name         string
+		versions     []int
+		conflicts    int
+		strict       bool
+		wantErr      error
+		wantArchived []int
+		wantDeletes  int32
+	}{
+		{
+			name:         "should archive before deleting",
+			versions:     []int{2},
+			wantArchived: []int{2},
+			wantDeletes:  1,
+		},
+		{
+			name:         "should archive again a message updated after it was archived",
+			versions:     []int{2, 3},
+			conflicts:    1,
+			wantArchived: []int{2, 3},
+			wantDeletes:  2,
+		},
+		{
+			name:         "should return VersionConflictError when the message keeps being updated",
+			versions:     []int{2, 3, 4},
+			conflicts:    3,
+			wantErr:      dynamomq.VersionConflictError{ID: "A-101", Version: 4},
+			wantArchived: []int{2, 3, 4},
+			wantDeletes:  3,
+		},
+		{
+			name: "should ignore a missing message",
+		},
+		{
+			name:    "should return IDNotFoundError for a missing message with a strict existence check",
+			strict:  true,
+			wantErr: dynamomq.ErrIDNotFound,
+		},
+	}
+	for _, tt := range tests {
+		archived = nil
+		var deletes atomic.Int32
+		client := newTestClient[test.MessageData](t, newArchivingDynamoDB(tt.versions, tt.conflicts, &deletes),
+			dynamomq.WithArchiver(archiver))
+		_, err := client.DeleteMessage(context.Background(), &dynamomq.DeleteMessageInput{ID: "A-101", StrictExistenceCheck: tt.strict})
+		if tt.wantErr != nil {
+			test.AssertError(t, err, tt.wantErr, tt.name)
+		} else if err != nil {
+			t.Errorf("%s: DeleteMessage() error = %v", tt.name, err)
+		}
+		test.AssertDeepEqual(t, archived, tt.wantArchived, tt.name)
+		if got := deletes.Load(); got != tt.wantDeletes {
+			t.Errorf("%s: DeleteItem() calls = %d, want %d", tt.name, got, tt.wantDeletes)
+		}
+	}
+}
+
+func TestDynamoMQClientDeleteMessageWithArchiverOnTable(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	var (
+		client   dynamomq.Client[test.MessageData]
+		archived []int
+	)
+	archiver := dynamomq.ArchiverFunc[test.MessageData](func(ctx context.Context, msg *dynamomq.Message[test.MessageData]) error {
+		archived = append(archived, msg.Version)
+		if len(archived) > 1 {
+			return nil
+		}
+		// The message is updated between its archive and its deletion, so it is archived again.
+		_, err := client.UpdateMessageData(ctx, &dynamomq.UpdateMessageDataInput[test.MessageData]{
+			ID:   msg.ID,
+			Data: test.NewMessageData("A-101-updated"),
+		})
+		return err
+	})
+	client, clean := prepareTestClient(ctx, t, NewSetupFunc(newPutRequestWithProcessingItem("A-101", test.DefaultTestDate)),
+		mock.Clock{T: test.DefaultTestDate.Add(time.Second)}, false, nil, nil, nil, dynamomq.WithArchiver(archiver))
+	defer clean()
+	if _, err := client.DeleteMessage(ctx, &dynamomq.DeleteMessageInput{ID: "A-101"}); err != nil {
+		t.Fatalf("DeleteMessage() error = %v", err)
+	}
+	if len(archived) != 2 || archived[1] != archived[0]+1 {
+		t.Errorf("DeleteMessage() archived versions = %v, want two successive versions", archived)
+	}
+	got, err := client.GetMessage(ctx, &dynamomq.GetMessageInput{ID: "A-101"})
+	if err != nil {
+		t.Fatalf("GetMessage() error = %v", err)
+	}
+	if got.Message != nil {
+		t.Errorf("GetMessage() = %v, want the message deleted", got.Message)
+	}
+}
+
+func TestDynamoMQClientDeleteMessageShouldNotDeleteWhenArchiveFails(t *testing.T) {
+	t.Parallel()
+	var deletes atomic.Int32
+	client := newTestClient[test.MessageData](t, newArchivingDynamoDB([]int{1}, 0, &deletes),
+		dynamomq.WithArchiver(dynamomq.ArchiverFunc[test.MessageData](func(ctx context.Context, msg *dynamomq.Message[test.MessageData]) error {
+			return test.ErrTest
+		})))
+	_, err := client.DeleteMessage(context.Background(), &dynamomq.DeleteMessageInput{ID: "A-101"})
+	var archiveErr dynamomq.ArchiveError
+	if !errors.As(err, &archiveErr) || archiveErr.ID != "A-101" || !errors.Is(err, test.ErrTest) {
+		t.Errorf("DeleteMessage() error = %v, want an ArchiveError caused by %v", err, test.ErrTest)
+	}
+	if got := deletes.Load(); got != 0 {
+		t.Errorf("DeleteItem() calls = %d, want 0", got)
+	}
+}
+
+func TestDynamoMQClientDeleteMessageWithArchiverExpectedVersion(t *testing.T) {
+	t.Parallel()
+	var archived atomic.Int32
+	archiver := dynamomq.ArchiverFunc[test.MessageData](func(ctx context.Context, msg *dynamomq.Message[test.MessageData]) error {
+		archived.Add(1)
+		return nil
+	})
+	var deletes atomic.Int32
+	client := newTestClient[test.MessageData](t, newArchivingDynamoDB([]int{2, 2}, 1, &deletes), dynamomq.WithArchiver(archiver))
+	_, err := client.DeleteMessage(context.Background(), &dynamomq.DeleteMessageInput{ID: "A-101", ExpectedVersion: 1})
+	test.AssertError(t, err, dynamomq.VersionConflictError{ID: "A-101", Version: 1}, "DeleteMessage() of a stale version")
+	if archived.Load() != 0 || deletes.Load() != 0 {
+		t.Errorf("DeleteMessage() archived = %d, deletes = %d, want neither for a stale version", archived.Load(), deletes.Load())
+	}
+	_, err = client.DeleteMessage(context.Background(), &dynamomq.DeleteMessageInput{ID: "A-101", ExpectedVersion: 2})
+	test.AssertError(t, err, dynamomq.ErrVersionConflict, "DeleteMessage() of a message updated after its archive")
+	if archived.Load() != 1 || deletes.Load() != 1 {
+		t.Errorf("DeleteMessage() archived = %d, deletes = %d, want a single attempt", archived.Load(), deletes.Load())
+	}
+}
+
+func TestDynamoMQClientChainMessageWithArchiver(t *testing.T) {
+	t.Parallel()
+	var archived atomic.Int32
+	archiver := dynamomq.ArchiverFunc[test.MessageData](func(ctx context.Context, msg *dynamomq.Message[test.MessageData]) error {
+		archived.Add(1)
+		return nil
+	})
+	next := &dynamomq.SendMessageInput[test.MessageData]{ID: "A-102", Data: test.NewMessageData("A-102")}
+	var deletes atomic.Int32
+	client := newTestClient[test.MessageData](t, newArchivingDynamoDB([]int{2, 2}, 0, &deletes), dynamomq.WithArchiver(archiver))
+	_, err := client.ChainMessage(context.Background(), &dynamomq.ChainMessageInput[test.MessageData]{
+		DeleteID: "A-101", DeleteVersion: 1, Next: next,
+	})
+	test.AssertError(t, err, dynamomq.VersionConflictError{ID: "A-101", Version: 1}, "ChainMessage() of a stale version")
+	if _, err = client.ChainMessage(context.Background(), &dynamomq.ChainMessageInput[test.MessageData]{
+		DeleteID: "A-101", DeleteVersion: 2, Next: next,
+	}); err != nil {
+		t.Fatalf("ChainMessage() error = %v", err)
+	}
+	if archived.Load() != 1 || deletes.Load() != 1 {
+		t.Errorf("ChainMessage() archived = %d, transactions = %d, want 1, 1", archived.Load(), deletes.Load())
+	}
+}
+
+func TestNewFromConfigShouldReturnInvalidArchiverError(t *testing.T) {
+	t.Parallel()
+	_, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithArchiver(dynamomq.ArchiverFunc[string](func(ctx context.Context, msg *dynamomq.Message[string]) error {
+			return nil
+		})))
+	test.AssertError(t, err, dynamomq.InvalidArchiverError{
+		Archiver:    "dynamomq.ArchiverFunc[string]",
+		MessageType: "test.MessageData",
+	}, "NewFromConfig()")
+}
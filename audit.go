@@ -0,0 +1,75 @@
+package dynamomq
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+)
+
+// AttributeNameHistory holds the audit trail of the message when the client is configured with WithAuditTrail.
+const AttributeNameHistory = "history"
+
+// MaxHistoryLength is the number of transitions kept in the audit trail of a message. Older transitions are dropped.
+const MaxHistoryLength = 20
+
+// TransitionStatus represents a change in the lifecycle of a message recorded in its audit trail.
+type TransitionStatus string
+
+// Constants defining the transitions recorded in the audit trail.
+const (
+	// TransitionSent indicates that the message was sent to the queue.
+	TransitionSent TransitionStatus = "SENT"
+	// TransitionReceived indicates that the message was received from the queue.
+	TransitionReceived TransitionStatus = "RECEIVED"
+	// TransitionMovedToDLQ indicates that the message was moved to the DLQ.
+	TransitionMovedToDLQ TransitionStatus = "MOVED_TO_DLQ"
+	// TransitionRedriven indicates that the message was redriven from the DLQ to the STANDARD queue.
+	TransitionRedriven TransitionStatus = "REDRIVEN"
+	// TransitionPromoted indicates that a scheduled message was promoted to the STANDARD queue once due.
+	TransitionPromoted TransitionStatus = "PROMOTED"
+	// TransitionReclaimed indicates that the message was made visible again by ReclaimExpiredMessages
+	// after its visibility timeout expired.
+	TransitionReclaimed TransitionStatus = "RECLAIMED"
+	// TransitionHeld indicates that the message was held with HoldMessage.
+	TransitionHeld TransitionStatus = "HELD"
+	// TransitionReleased indicates that the message was released with ReleaseMessage.
+	TransitionReleased TransitionStatus = "RELEASED"
+)
+
+// Transition is an entry of the audit trail of a message.
+type Transition struct {
+	// Status is the transition that occurred.
+	Status TransitionStatus `json:"status" dynamodbav:"status"`
+	// At is the timestamp of the transition in RFC 3339 format with nanoseconds.
+	At string `json:"at" dynamodbav:"at"`
+	// Actor identifies the client that made the transition, as set with WithActorID. It is empty if not set.
+	Actor string `json:"actor,omitempty" dynamodbav:"actor,omitempty"`
+}
+
+func (m *Message[T]) appendHistory(status TransitionStatus, now time.Time, actor string) {
+	m.History = append(m.History, Transition{
+		Status: status,
+		At:     clock.FormatRFC3339Nano(now),
+		Actor:  actor,
+	})
+	if n := len(m.History); n > MaxHistoryLength {
+		m.History = m.History[n-MaxHistoryLength:]
+	}
+}
+
+// recordTransition appends a transition to the audit trail of the message when the audit trail is enabled.
+func (c *ClientImpl[T]) recordTransition(message *Message[T], status TransitionStatus, now time.Time) {
+	if c.auditTrail {
+		message.appendHistory(status, now, c.actorID)
+	}
+}
+
+// setHistory adds the audit trail of the message to an update expression when the audit trail is enabled.
+// The whole list is written, which is safe because every update is conditional on the version of the message.
+func (c *ClientImpl[T]) setHistory(update expression.UpdateBuilder, message *Message[T]) expression.UpdateBuilder {
+	if !c.auditTrail {
+		return update
+	}
+	return update.Set(expression.Name(c.schema.HistoryAttribute), expression.Value(message.History))
+}
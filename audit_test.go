@@ -0,0 +1,123 @@
+package dynamomq_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestDynamoMQClientAuditTrail(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	client, clean := prepareTestClient(ctx, t, NewSetupFunc(), mock.Clock{T: test.DefaultTestDate}, false, nil, nil, nil,
+		dynamomq.WithAuditTrail(true),
+		dynamomq.WithActorID("worker-1"))
+	defer clean()
+	if _, err := client.SendMessage(ctx, &dynamomq.SendMessageInput[test.MessageData]{
+		ID:   "A-101",
+		Data: test.NewMessageData("A-101"),
+	}); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if _, err := client.ReceiveMessage(ctx, nil); err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	if _, err := client.MoveMessageToDLQ(ctx, &dynamomq.MoveMessageToDLQInput{ID: "A-101"}); err != nil {
+		t.Fatalf("MoveMessageToDLQ() error = %v", err)
+	}
+	redriven, err := client.RedriveMessage(ctx, &dynamomq.RedriveMessageInput{ID: "A-101"})
+	if err != nil {
+		t.Fatalf("RedriveMessage() error = %v", err)
+	}
+	ts := clock.FormatRFC3339Nano(test.DefaultTestDate)
+	test.AssertDeepEqual(t, redriven.RedroveMessage.History, []dynamomq.Transition{
+		{Status: dynamomq.TransitionSent, At: ts, Actor: "worker-1"},
+		{Status: dynamomq.TransitionReceived, At: ts, Actor: "worker-1"},
+		{Status: dynamomq.TransitionMovedToDLQ, At: ts, Actor: "worker-1"},
+		{Status: dynamomq.TransitionRedriven, At: ts, Actor: "worker-1"},
+	}, "RedriveMessage() history")
+}
+
+func TestDynamoMQClientAuditTrailIsBounded(t *testing.T) {
+	t.Parallel()
+	message := NewTestMessageItemAsReady("A-101", test.DefaultTestDate)
+	for i := 0; i < dynamomq.MaxHistoryLength; i++ {
+		message.History = append(message.History, dynamomq.Transition{
+			Status: dynamomq.TransitionReceived,
+			At:     clock.FormatRFC3339Nano(test.DefaultTestDate.Add(-time.Duration(i) * time.Minute)),
+		})
+	}
+	item, err := marshalMap(message)
+	if err != nil {
+		t.Fatalf("marshalMap() error = %v", err)
+	}
+	var history []dynamomq.Transition
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithAuditTrail(true),
+		mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+				return &dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{item}}, nil
+			},
+			UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+				// The history is the only list in the update of a received message.
+				for _, v := range params.ExpressionAttributeValues {
+					if _, ok := v.(*types.AttributeValueMemberL); ok {
+						if err := attributevalue.Unmarshal(v, &history); err != nil {
+							t.Errorf("Unmarshal() error = %v", err)
+						}
+					}
+				}
+				return &dynamodb.UpdateItemOutput{Attributes: item}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	if _, err := client.ReceiveMessage(context.Background(), nil); err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	if len(history) != dynamomq.MaxHistoryLength {
+		t.Fatalf("UpdateItem() history length = %d, want %d", len(history), dynamomq.MaxHistoryLength)
+	}
+	test.AssertDeepEqual(t, history[0], message.History[1], "oldest kept transition")
+	test.AssertDeepEqual(t, history[len(history)-1], dynamomq.Transition{
+		Status: dynamomq.TransitionReceived,
+		At:     clock.FormatRFC3339Nano(test.DefaultTestDate),
+	}, "latest transition")
+}
+
+func TestDynamoMQClientAuditTrailDisabled(t *testing.T) {
+	t.Parallel()
+	var put *dynamodb.PutItemInput
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithActorID("worker-1"),
+		mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+				return &dynamodb.GetItemOutput{}, nil
+			},
+			PutItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+				put = params
+				return &dynamodb.PutItemOutput{}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	if _, err := client.SendMessage(context.Background(), &dynamomq.SendMessageInput[test.MessageData]{ID: "A-101"}); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if _, ok := put.Item[dynamomq.AttributeNameHistory]; ok {
+		t.Errorf("SendMessage() item has a history without WithAuditTrail: %v", put.Item)
+	}
+}
@@ -0,0 +1,110 @@
+package dynamomq
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// batchGetSize is the maximum number of keys DynamoDB accepts in one BatchGetItem call.
+const batchGetSize = 100
+
+// GetMessageBatchInput represents the input parameters for getting several messages by their IDs.
+type GetMessageBatchInput struct {
+	// IDs are the unique identifiers of the messages to get. Duplicated IDs are read once.
+	IDs []string
+	// ConsistentRead makes the reads strongly consistent, at twice the cost of the default eventually consistent reads.
+	ConsistentRead bool
+}
+
+// GetMessageBatchOutput represents the result of the operation to get several messages by their IDs.
+type GetMessageBatchOutput[T any] struct {
+	// Messages maps the IDs of the messages found to the messages.
+	Messages map[string]*Message[T] `json:"messages"`
+	// MissingIDs are the IDs of the messages not found, in the order of the input.
+	MissingIDs []string `json:"missing_ids"`
+}
+
+// GetMessageBatch gets several messages by their IDs with BatchGetItem, reading up to 100 messages per call
+// instead of one GetItem call per message. The keys DynamoDB leaves unprocessed are read again with an exponential
+// backoff, and a ThrottledError is returned if some are still unprocessed after several attempts.
+func (c *ClientImpl[T]) GetMessageBatch(ctx context.Context, params *GetMessageBatchInput) (*GetMessageBatchOutput[T], error) {
+	if params == nil {
+		params = &GetMessageBatchInput{}
+	}
+	ids := make([]string, 0, len(params.IDs))
+	seen := make(map[string]bool, len(params.IDs))
+	for _, id := range params.IDs {
+		if id == "" {
+			return &GetMessageBatchOutput[T]{}, &IDNotProvidedError{}
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	out := &GetMessageBatchOutput[T]{
+		Messages:   make(map[string]*Message[T], len(ids)),
+		MissingIDs: make([]string, 0),
+	}
+	for start := 0; start < len(ids); start += batchGetSize {
+		end := start + batchGetSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		keys := make([]map[string]types.AttributeValue, 0, end-start)
+		for _, id := range ids[start:end] {
+			keys = append(keys, c.itemKey(id))
+		}
+		if err := c.batchGetItems(ctx, keys, params.ConsistentRead, out.Messages); err != nil {
+			return &GetMessageBatchOutput[T]{}, err
+		}
+	}
+	for _, id := range ids {
+		if _, ok := out.Messages[id]; !ok {
+			out.MissingIDs = append(out.MissingIDs, id)
+		}
+	}
+	return out, nil
+}
+
+// batchGetItems reads the keys with BatchGetItem into messages, and reads again the keys DynamoDB left unprocessed
+// with an exponential backoff.
+func (c *ClientImpl[T]) batchGetItems(ctx context.Context, keys []map[string]types.AttributeValue,
+	consistentRead bool, messages map[string]*Message[T]) error {
+	backoff := batchUnprocessedBackoff
+	for attempt := 1; ; attempt++ {
+		resp, err := c.dynamoDB.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+			RequestItems: map[string]types.KeysAndAttributes{
+				c.tableName: {
+					Keys:           keys,
+					ConsistentRead: aws.Bool(consistentRead),
+				},
+			},
+		})
+		if err != nil {
+			return handleDynamoDBError(err)
+		}
+		for _, item := range resp.Responses[c.tableName] {
+			message := Message[T]{}
+			if err := c.unmarshalItem(item, &message); err != nil {
+				return UnmarshalingAttributeError{Cause: err}
+			}
+			messages[message.ID] = &message
+		}
+		keys = resp.UnprocessedKeys[c.tableName].Keys
+		if len(keys) == 0 {
+			return nil
+		}
+		if attempt == batchMaxUnprocessedAttempts {
+			return ThrottledError{Cause: fmt.Errorf("%d keys left unprocessed by BatchGetItem", len(keys))}
+		}
+		if !sleepContext(ctx, backoff) {
+			return OperationCanceledError{Cause: ctx.Err()}
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,133 @@
+package dynamomq_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/constant"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestDynamoMQClientGetMessageBatch(t *testing.T) {
+	t.Parallel()
+	table := map[string]map[string]types.AttributeValue{}
+	var ids []string
+	for i := 0; i < 150; i++ {
+		id := fmt.Sprintf("A-%03d", i)
+		ids = append(ids, id)
+		if i%50 != 7 {
+			table[id] = dynamomqtest.MarshalMap(NewTestMessageItemAsReady(id, test.DefaultTestDate))
+		}
+	}
+	// A duplicated ID is read once.
+	ids = append(ids, "A-000")
+	var (
+		calls      []int
+		consistent []bool
+	)
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			BatchGetItemFunc: func(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+				request := params.RequestItems[constant.DefaultTableName]
+				calls = append(calls, len(request.Keys))
+				consistent = append(consistent, aws.ToBool(request.ConsistentRead))
+				out := &dynamodb.BatchGetItemOutput{Responses: map[string][]map[string]types.AttributeValue{}}
+				keys := request.Keys
+				if len(calls) == 1 {
+					// DynamoDB leaves the last keys of the first call unprocessed.
+					out.UnprocessedKeys = map[string]types.KeysAndAttributes{
+						constant.DefaultTableName: {Keys: keys[90:], ConsistentRead: request.ConsistentRead},
+					}
+					keys = keys[:90]
+				}
+				for _, key := range keys {
+					id := key[dynamomq.AttributeNameID].(*types.AttributeValueMemberS).Value
+					if item, ok := table[id]; ok {
+						out.Responses[constant.DefaultTableName] = append(out.Responses[constant.DefaultTableName], item)
+					}
+				}
+				return out, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	out, err := client.GetMessageBatch(context.Background(), &dynamomq.GetMessageBatchInput{
+		IDs:            ids,
+		ConsistentRead: true,
+	})
+	if err != nil {
+		t.Fatalf("GetMessageBatch() error = %v", err)
+	}
+	test.AssertDeepEqual(t, calls, []int{100, 10, 50}, "BatchGetItem() keys per call")
+	test.AssertDeepEqual(t, consistent, []bool{true, true, true}, "BatchGetItem() consistent reads")
+	test.AssertDeepEqual(t, out.MissingIDs, []string{"A-007", "A-057", "A-107"}, "GetMessageBatch() missing IDs")
+	if len(out.Messages) != len(table) {
+		t.Errorf("GetMessageBatch() messages = %d, want %d", len(out.Messages), len(table))
+	}
+	test.AssertDeepEqual(t, out.Messages["A-149"], NewTestMessageItemAsReady("A-149", test.DefaultTestDate), "GetMessageBatch() message")
+}
+
+func TestDynamoMQClientGetMessageBatchErrors(t *testing.T) {
+	t.Parallel()
+	newClient := func(unprocessed bool) dynamomq.Client[test.MessageData] {
+		client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+			dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+				BatchGetItemFunc: func(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+					if !unprocessed {
+						return nil, test.ErrTest
+					}
+					return &dynamodb.BatchGetItemOutput{UnprocessedKeys: params.RequestItems}, nil
+				},
+			}))
+		if err != nil {
+			t.Fatalf("NewFromConfig() error = %v", err)
+		}
+		return client
+	}
+	tests := []struct {
+		name        string
+		unprocessed bool
+		canceled    bool
+		ids         []string
+		wantErr     error
+	}{
+		{
+			name:    "should return IDNotProvidedError for an empty ID",
+			ids:     []string{"A-101", ""},
+			wantErr: dynamomq.ErrIDNotProvided,
+		},
+		{
+			name:    "should return the error of BatchGetItem",
+			ids:     []string{"A-101"},
+			wantErr: dynamomq.DynamoDBAPIError{Cause: test.ErrTest},
+		},
+		{
+			name:        "should stop retrying the unprocessed keys when the context is canceled",
+			unprocessed: true,
+			canceled:    true,
+			ids:         []string{"A-101"},
+			wantErr:     dynamomq.OperationCanceledError{Cause: context.Canceled},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctx, cancel := context.WithCancel(context.Background())
+			if tt.canceled {
+				cancel()
+			}
+			defer cancel()
+			_, err := newClient(tt.unprocessed).GetMessageBatch(ctx, &dynamomq.GetMessageBatchInput{IDs: tt.ids})
+			test.AssertError(t, err, tt.wantErr, "GetMessageBatch()")
+		})
+	}
+}
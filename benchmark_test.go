@@ -0,0 +1,227 @@
+package dynamomq_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+// newBenchmarkClient returns a client whose table always holds the same ready message,
+// so that the benchmarks measure the work done by the client rather than by DynamoDB.
+func newBenchmarkClient(b *testing.B) dynamomq.Client[test.MessageData] {
+	b.Helper()
+	return newBenchmarkClientWithInvisibleHead(b, 0)
+}
+
+// newBenchmarkClientWithInvisibleHead returns a client whose queue starts with the given number of messages
+// being processed by other consumers, which ReceiveMessage reads and skips before the ready message.
+func newBenchmarkClientWithInvisibleHead(b *testing.B, invisible int) dynamomq.Client[test.MessageData] {
+	b.Helper()
+	now := test.DefaultTestDate.Add(time.Minute)
+	ready := dynamomqtest.MarshalMap(NewTestMessageItemAsReady("A-101", test.DefaultTestDate))
+	processing := dynamomqtest.MarshalMap(NewTestMessageItemAsProcessing("A-101", now))
+	page := make([]map[string]types.AttributeValue, 0, invisible+1)
+	for i := 0; i < invisible; i++ {
+		page = append(page, dynamomqtest.MarshalMap(NewTestMessageItemAsProcessing("B-101", now)))
+	}
+	page = append(page, ready)
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		mock.WithClock(mock.Clock{T: now}),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+				return &dynamodb.QueryOutput{Items: page}, nil
+			},
+			GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+				return &dynamodb.GetItemOutput{}, nil
+			},
+			PutItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+				return &dynamodb.PutItemOutput{}, nil
+			},
+			UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+				return &dynamodb.UpdateItemOutput{Attributes: processing}, nil
+			},
+			DeleteItemFunc: func(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+				return &dynamodb.DeleteItemOutput{}, nil
+			},
+			ScanFunc: func(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+				return &dynamodb.ScanOutput{Items: []map[string]types.AttributeValue{ready}}, nil
+			},
+		}))
+	if err != nil {
+		b.Fatalf("NewFromConfig() error = %v", err)
+	}
+	return client
+}
+
+func BenchmarkReceiveMessage(b *testing.B) {
+	client := newBenchmarkClient(b)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkReceiveMessageContended measures a receive that reads past messages held by other consumers,
+// which is where the client spends most of its time unmarshaling.
+func BenchmarkReceiveMessageContended(b *testing.B) {
+	client := newBenchmarkClientWithInvisibleHead(b, 9)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSendMessage(b *testing.B) {
+	client := newBenchmarkClient(b)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.SendMessage(ctx, &dynamomq.SendMessageInput[test.MessageData]{
+			ID:   "A-101",
+			Data: test.NewMessageData("A-101"),
+		}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshalMessage(b *testing.B) {
+	message := NewTestMessageItemAsReady("A-101", test.DefaultTestDate)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := attributevalue.MarshalMap(message); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalMessage(b *testing.B) {
+	item := dynamomqtest.MarshalMap(NewTestMessageItemAsProcessing("A-101", test.DefaultTestDate))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var message dynamomq.Message[test.MessageData]
+		if err := attributevalue.UnmarshalMap(item, &message); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkPayloads are the same payload as a struct, as opaque JSON and as bytes,
+// to compare the struct path of the marshaling with the fast path of raw payloads.
+func benchmarkPayloads(b *testing.B) (test.MessageData, json.RawMessage) {
+	b.Helper()
+	data := test.NewMessageData("A-101")
+	raw, err := json.Marshal(data)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return data, raw
+}
+
+func benchmarkMarshalPayload[T any](b *testing.B, data T) {
+	message := dynamomq.NewMessage("A-101", data, test.DefaultTestDate)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := message.MarshalMap(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkUnmarshalPayload[T any](b *testing.B, data T) {
+	item, err := dynamomq.NewMessage("A-101", data, test.DefaultTestDate).MarshalMap()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := dynamomq.UnmarshalMessage[T](item); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshalPayload(b *testing.B) {
+	data, raw := benchmarkPayloads(b)
+	b.Run("struct", func(b *testing.B) { benchmarkMarshalPayload(b, data) })
+	b.Run("json.RawMessage", func(b *testing.B) { benchmarkMarshalPayload(b, raw) })
+	b.Run("bytes", func(b *testing.B) { benchmarkMarshalPayload(b, []byte(raw)) })
+}
+
+func BenchmarkUnmarshalPayload(b *testing.B) {
+	data, raw := benchmarkPayloads(b)
+	b.Run("struct", func(b *testing.B) { benchmarkUnmarshalPayload(b, data) })
+	b.Run("json.RawMessage", func(b *testing.B) { benchmarkUnmarshalPayload(b, raw) })
+	b.Run("bytes", func(b *testing.B) { benchmarkUnmarshalPayload(b, []byte(raw)) })
+}
+
+func BenchmarkDeleteMessage(b *testing.B) {
+	client := newBenchmarkClient(b)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.DeleteMessage(ctx, &dynamomq.DeleteMessageInput{ID: "A-101", StrictExistenceCheck: true}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetQueueStats(b *testing.B) {
+	client := newBenchmarkClient(b)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.GetQueueStats(ctx, &dynamomq.GetQueueStatsInput{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkListMessages(b *testing.B) {
+	client := newBenchmarkClient(b)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.ListMessages(ctx, &dynamomq.ListMessagesInput{OmitData: true}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkBuildKeyCondition measures the cost of building the key condition of the queueing index,
+// which the client now pays once per queue type instead of on every receive and every stats call.
+func BenchmarkBuildKeyCondition(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, err := expression.NewBuilder().
+			WithKeyCondition(expression.Key(dynamomq.AttributeNameQueueType).Equal(expression.Value(dynamomq.QueueTypeStandard))).
+			Build()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package dynamomq
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+)
+
+// AttributeNameCanary marks the canary messages sent by SendCanary.
+const AttributeNameCanary = "canary"
+
+const (
+	defaultCanaryTimeout         = 30 * time.Second
+	defaultCanaryPollingInterval = time.Second
+	canaryIDPrefix               = "canary-"
+)
+
+// SendCanaryInput represents the input parameters for sending a canary message.
+type SendCanaryInput struct {
+	// ID is the unique identifier of the canary. If it is empty, a random ID prefixed with "canary-" is used.
+	ID string
+	// QueueType is the type of queue the canary is sent to. By default, it is STANDARD.
+	QueueType QueueType
+}
+
+// SendCanaryOutput represents the result of sending a canary message.
+type SendCanaryOutput[T any] struct {
+	// SentMessage is the canary, marked with Canary and holding the zero value of T as its data.
+	SentMessage *Message[T]
+}
+
+// SendCanary sends a synthetic message marked as a canary, to check end to end that the messages of the queue
+// are received and deleted in time with CheckCanary. A Consumer created with WithAutoAckCanaries deletes
+// the canaries it receives without passing them to its MessageProcessor.
+func (c *ClientImpl[T]) SendCanary(ctx context.Context, params *SendCanaryInput) (*SendCanaryOutput[T], error) {
+	if params == nil {
+		params = &SendCanaryInput{}
+	}
+	id := params.ID
+	if id == "" {
+		id = canaryIDPrefix + uuid.NewString()
+	}
+	retrieved, err := c.GetMessage(ctx, &GetMessageInput{
+		ID: id,
+	})
+	if err != nil {
+		return &SendCanaryOutput[T]{}, err
+	}
+	if retrieved.Message != nil {
+		return &SendCanaryOutput[T]{}, &IDDuplicatedError{}
+	}
+	var data T
+	message := c.newSentMessage(&SendMessageInput[T]{
+		ID:        id,
+		Data:      data,
+		QueueType: params.QueueType,
+	})
+	message.Canary = true
+	if err := c.put(ctx, message); err != nil {
+		return &SendCanaryOutput[T]{}, err
+	}
+	return &SendCanaryOutput[T]{
+		SentMessage: message,
+	}, nil
+}
+
+// CheckCanaryInput represents the input parameters for checking a canary message.
+type CheckCanaryInput struct {
+	// ID is the unique identifier of the canary, as returned by SendCanary.
+	ID string
+	// SentAt is the time the canary was sent. If it is zero, the SentAt of the canary is read on the first poll,
+	// and the latencies are not measured when the canary has already been deleted.
+	SentAt time.Time
+	// Timeout is the time the canary is given to be deleted. By default, it is 30 seconds.
+	Timeout time.Duration
+	// PollingInterval is the interval at which the canary is read. By default, it is one second.
+	PollingInterval time.Duration
+}
+
+// CheckCanaryOutput represents the timings measured by CheckCanary. The times are observed by polling,
+// so they are late by up to the PollingInterval.
+type CheckCanaryOutput struct {
+	// ID is the unique identifier of the canary.
+	ID string
+	// SentAt is the time the canary was sent.
+	SentAt time.Time
+	// ReceivedAt is the time the canary was last received, or zero if it was never seen in flight.
+	ReceivedAt time.Time
+	// DeletedAt is the time the canary was found deleted, or zero if it was not deleted before the timeout.
+	DeletedAt time.Time
+	// ReceiveLatency is the time between the sending and the last receive of the canary.
+	ReceiveLatency time.Duration
+	// EndToEndLatency is the time between the sending and the deletion of the canary.
+	EndToEndLatency time.Duration
+}
+
+// CheckCanary polls the canary sent by SendCanary until it is deleted, and returns the measured latencies.
+// If the canary is not deleted within the timeout, it returns the timings observed so far with a CanaryTimeoutError.
+// The OnCanaryChecked hook is invoked with the timings of a canary deleted in time.
+func (c *ClientImpl[T]) CheckCanary(ctx context.Context, params *CheckCanaryInput) (*CheckCanaryOutput, error) {
+	if params == nil {
+		params = &CheckCanaryInput{}
+	}
+	if params.ID == "" {
+		return &CheckCanaryOutput{}, &IDNotProvidedError{}
+	}
+	timeout := params.Timeout
+	if timeout <= 0 {
+		timeout = defaultCanaryTimeout
+	}
+	pollingInterval := params.PollingInterval
+	if pollingInterval <= 0 {
+		pollingInterval = defaultCanaryPollingInterval
+	}
+	out := &CheckCanaryOutput{
+		ID:     params.ID,
+		SentAt: params.SentAt,
+	}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		retrieved, err := c.GetMessage(ctx, &GetMessageInput{
+			ID: params.ID,
+		})
+		if err != nil {
+			return out, err
+		}
+		if retrieved.Message == nil {
+			out.DeletedAt = c.clock.Now()
+			if !out.SentAt.IsZero() {
+				out.EndToEndLatency = out.DeletedAt.Sub(out.SentAt)
+			}
+			c.hooks.canaryChecked(ctx, params, out)
+			return out, nil
+		}
+		if out.SentAt.IsZero() {
+			out.SentAt, _ = retrieved.Message.ParsedSentAt()
+		}
+		if receivedAt, err := clock.ParseRFC3339Nano(retrieved.Message.ReceivedAt); err == nil {
+			out.ReceivedAt = receivedAt
+			out.ReceiveLatency = receivedAt.Sub(out.SentAt)
+		}
+		poll := time.NewTimer(pollingInterval)
+		select {
+		case <-ctx.Done():
+			poll.Stop()
+			return out, ctx.Err()
+		case <-deadline.C:
+			poll.Stop()
+			return out, CanaryTimeoutError{ID: params.ID, Timeout: timeout}
+		case <-poll.C:
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package dynamomq_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+// newCanaryDynamoDB returns a table whose GetItem returns item for the given number of polls, and then no item.
+// A negative number of polls returns item forever.
+func newCanaryDynamoDB(t *testing.T, item map[string]types.AttributeValue, polls int) *mock.DynamoDB {
+	var calls atomic.Int32
+	return &mock.DynamoDB{
+		GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+			if polls >= 0 && int(calls.Add(1)) > polls {
+				return &dynamodb.GetItemOutput{}, nil
+			}
+			return &dynamodb.GetItemOutput{Item: item}, nil
+		},
+		PutItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+			if _, ok := params.Item[dynamomq.AttributeNameCanary]; !ok {
+				t.Errorf("PutItem() item has no %s attribute", dynamomq.AttributeNameCanary)
+			}
+			return &dynamodb.PutItemOutput{}, nil
+		},
+	}
+}
+
+func TestClientSendCanary(t *testing.T) {
+	t.Parallel()
+	client := newTestClient[test.MessageData](t, newCanaryDynamoDB(t, nil, 0), mock.WithClock(mock.Clock{T: test.DefaultTestDate}))
+	out, err := client.SendCanary(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("SendCanary() error = %v", err)
+	}
+	if !out.SentMessage.Canary {
+		t.Error("SendCanary() message is not marked as a canary")
+	}
+	if !strings.HasPrefix(out.SentMessage.ID, "canary-") {
+		t.Errorf("SendCanary() ID = %v, want the canary- prefix", out.SentMessage.ID)
+	}
+}
+
+func TestClientCheckCanary(t *testing.T) {
+	t.Parallel()
+	sentAt := test.DefaultTestDate
+	receivedAt := sentAt.Add(2 * time.Second)
+	deletedAt := sentAt.Add(10 * time.Second)
+	processing := dynamomqtest.NewProcessingMessage("canary-1", test.NewMessageData("canary-1"), receivedAt,
+		dynamomqtest.WithSentAt(sentAt))
+	var checked *dynamomq.CheckCanaryOutput
+	client := newTestClient[test.MessageData](t, newCanaryDynamoDB(t, dynamomqtest.MarshalMap(processing), 2),
+		mock.WithClock(mock.Clock{T: deletedAt}),
+		dynamomq.WithHooks(&dynamomq.Hooks[test.MessageData]{
+			OnCanaryChecked: func(ctx context.Context, params *dynamomq.CheckCanaryInput, out *dynamomq.CheckCanaryOutput) {
+				checked = out
+			},
+		}))
+	got, err := client.CheckCanary(context.Background(), &dynamomq.CheckCanaryInput{
+		ID:              "canary-1",
+		PollingInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("CheckCanary() error = %v", err)
+	}
+	want := &dynamomq.CheckCanaryOutput{
+		ID:              "canary-1",
+		SentAt:          sentAt,
+		ReceivedAt:      receivedAt,
+		DeletedAt:       deletedAt,
+		ReceiveLatency:  2 * time.Second,
+		EndToEndLatency: 10 * time.Second,
+	}
+	test.AssertDeepEqual(t, got, want, "CheckCanary()")
+	if checked != got {
+		t.Errorf("OnCanaryChecked() out = %v, want %v", checked, got)
+	}
+}
+
+func TestClientCanaryOnTable(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	now := test.DefaultTestDate
+	client, clean := prepareTestClient(ctx, t, NewSetupFunc(), mock.Clock{T: now}, false, nil, nil, nil)
+	defer clean()
+	sent, err := client.SendCanary(ctx, &dynamomq.SendCanaryInput{ID: "canary-1"})
+	if err != nil {
+		t.Fatalf("SendCanary() error = %v", err)
+	}
+	received, err := client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{})
+	if err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	if received.ReceivedMessage.ID != "canary-1" || !received.ReceivedMessage.Canary {
+		t.Errorf("ReceiveMessage() = %v, want the canary", received.ReceivedMessage)
+	}
+	input := &dynamomq.CheckCanaryInput{
+		ID:              "canary-1",
+		SentAt:          now,
+		Timeout:         10 * time.Millisecond,
+		PollingInterval: time.Millisecond,
+	}
+	got, err := client.CheckCanary(ctx, input)
+	var timeoutErr dynamomq.CanaryTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("CheckCanary() error = %v, want %T", err, timeoutErr)
+	}
+	if !got.ReceivedAt.Equal(now) {
+		t.Errorf("CheckCanary() ReceivedAt = %v, want %v", got.ReceivedAt, now)
+	}
+	if _, err = client.DeleteMessage(ctx, &dynamomq.DeleteMessageInput{ID: sent.SentMessage.ID}); err != nil {
+		t.Fatalf("DeleteMessage() error = %v", err)
+	}
+	got, err = client.CheckCanary(ctx, input)
+	if err != nil {
+		t.Fatalf("CheckCanary() error = %v", err)
+	}
+	if !got.DeletedAt.Equal(now) {
+		t.Errorf("CheckCanary() DeletedAt = %v, want %v", got.DeletedAt, now)
+	}
+}
+
+func TestClientCheckCanaryShouldTimeOut(t *testing.T) {
+	t.Parallel()
+	ready := dynamomqtest.MarshalMap(NewTestMessageItemAsReady("canary-1", test.DefaultTestDate))
+	client := newTestClient[test.MessageData](t, newCanaryDynamoDB(t, ready, -1), mock.WithClock(mock.Clock{T: test.DefaultTestDate}))
+	got, err := client.CheckCanary(context.Background(), &dynamomq.CheckCanaryInput{
+		ID:              "canary-1",
+		Timeout:         10 * time.Millisecond,
+		PollingInterval: time.Millisecond,
+	})
+	var timeoutErr dynamomq.CanaryTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("CheckCanary() error = %v, want %T", err, timeoutErr)
+	}
+	if !got.DeletedAt.IsZero() || !got.SentAt.Equal(test.DefaultTestDate) {
+		t.Errorf("CheckCanary() = %v, want the sending time and no deletion", got)
+	}
+}
+
+func TestConsumerShouldAutoAckCanaries(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	deleted := make(chan string, 1)
+	client := &mock.Client[test.MessageData]{
+		ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+			if calls.Add(1) != 1 {
+				return nil, &dynamomq.EmptyQueueError{}
+			}
+			canary := dynamomq.NewMessage("canary-1", test.MessageData{}, test.DefaultTestDate)
+			canary.Canary = true
+			return &dynamomq.ReceiveMessageOutput[test.MessageData]{ReceivedMessage: canary}, nil
+		},
+		DeleteMessageFunc: func(ctx context.Context, params *dynamomq.DeleteMessageInput) (*dynamomq.DeleteMessageOutput, error) {
+			deleted <- params.ID
+			return &dynamomq.DeleteMessageOutput{}, nil
+		},
+	}
+	processor := &CountProcessor[test.MessageData]{}
+	consumer := dynamomq.NewConsumer[test.MessageData](client, processor,
+		dynamomq.WithPollingInterval(time.Millisecond),
+		dynamomq.WithAutoAckCanaries())
+	go func() {
+		_ = consumer.StartConsuming()
+	}()
+	select {
+	case id := <-deleted:
+		if id != "canary-1" {
+			t.Errorf("DeleteMessage() ID = %v, want canary-1", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the consumer did not delete the canary")
+	}
+	_ = consumer.Shutdown(context.Background())
+	if got := processor.Count.Load(); got != 0 {
+		t.Errorf("Process() calls = %d, want 0", got)
+	}
+}
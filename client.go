@@ -3,7 +3,13 @@ package dynamomq
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
 	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -11,13 +17,20 @@ import (
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
 	"github.com/vvatanabe/dynamomq/internal/clock"
 	"github.com/vvatanabe/dynamomq/internal/constant"
 )
 
 const (
-	defaultQueryLimit       = 250
-	maxFirstMessagesInQueue = 100
+	defaultQueryLimit         = 250
+	defaultMinReceivePageSize = 10
+	// defaultReceiveConflictRetries is the number of times ReceiveMessage selects another message
+	// after the one it selected is received by another client first.
+	defaultReceiveConflictRetries = 3
+	maxFirstMessagesInQueue       = 100
+	// envEndpointURLDynamoDB is the environment variable of the AWS SDKs setting the endpoint of DynamoDB.
+	envEndpointURLDynamoDB = "AWS_ENDPOINT_URL_DYNAMODB"
 )
 
 // Client is an interface for interacting with a DynamoDB-based message queue system.
@@ -28,6 +41,8 @@ type Client[T any] interface {
 	SendMessage(ctx context.Context, params *SendMessageInput[T]) (*SendMessageOutput[T], error)
 	// ReceiveMessage retrieves and processes a message from a DynamoDB-based queue.
 	ReceiveMessage(ctx context.Context, params *ReceiveMessageInput) (*ReceiveMessageOutput[T], error)
+	// ReceiveMessageBatch receives up to a given number of messages in one call, skipping those received by other clients.
+	ReceiveMessageBatch(ctx context.Context, params *ReceiveMessageBatchInput) (*ReceiveMessageBatchOutput[T], error)
 	// ChangeMessageVisibility changes the visibility of a specific message in a DynamoDB-based queue.
 	ChangeMessageVisibility(ctx context.Context, params *ChangeMessageVisibilityInput) (*ChangeMessageVisibilityOutput[T], error)
 	// DeleteMessage deletes a specific message from a DynamoDB-based queue.
@@ -38,14 +53,86 @@ type Client[T any] interface {
 	RedriveMessage(ctx context.Context, params *RedriveMessageInput) (*RedriveMessageOutput[T], error)
 	// GetMessage get a specific message from a DynamoDB-based queue.
 	GetMessage(ctx context.Context, params *GetMessageInput) (*GetMessageOutput[T], error)
+	// GetMessageBatch gets several messages by their IDs with BatchGetItem.
+	GetMessageBatch(ctx context.Context, params *GetMessageBatchInput) (*GetMessageBatchOutput[T], error)
 	// GetQueueStats is a method for obtaining statistical information about a DynamoDB-based queue.
 	GetQueueStats(ctx context.Context, params *GetQueueStatsInput) (*GetQueueStatsOutput, error)
 	// GetDLQStats get statistical information about a DynamoDB-based Dead Letter Queue (DLQ).
 	GetDLQStats(ctx context.Context, params *GetDLQStatsInput) (*GetDLQStatsOutput, error)
+	// ListDLQMessages lists the messages of the DLQ with all their attributes, in the order they were moved to the DLQ.
+	ListDLQMessages(ctx context.Context, params *ListDLQMessagesInput) (*ListDLQMessagesOutput[T], error)
 	// ListMessages get a list of messages from a DynamoDB-based queue.
 	ListMessages(ctx context.Context, params *ListMessagesInput) (*ListMessagesOutput[T], error)
+	// ListMessageSummaries lists the first messages of a queue type with their status, in the order they are received.
+	ListMessageSummaries(ctx context.Context, params *ListMessageSummariesInput) (*ListMessageSummariesOutput, error)
 	// ReplaceMessage replace a specific message within a DynamoDB-based queue.
 	ReplaceMessage(ctx context.Context, params *ReplaceMessageInput[T]) (*ReplaceMessageOutput, error)
+	// DescribeQueue reports the health of the table and the queueing index along with the effective configuration of the client.
+	DescribeQueue(ctx context.Context, params *DescribeQueueInput) (*DescribeQueueOutput, error)
+	// VerifyQueue checks that the table is ready for use and the credentials are allowed to use it.
+	VerifyQueue(ctx context.Context, params *VerifyQueueInput) (*VerifyQueueOutput, error)
+	// SendMessageTransactWriteItem builds the conditional Put of a new message for use in a TransactWriteItems call.
+	SendMessageTransactWriteItem(params *SendMessageInput[T]) (*types.TransactWriteItem, error)
+	// SendMessagesInTransaction sends messages together with other write items in a single DynamoDB transaction.
+	SendMessagesInTransaction(ctx context.Context, params *SendMessagesInTransactionInput[T]) (*SendMessagesInTransactionOutput[T], error)
+	// ChainMessage deletes a processed message and sends the next message in a single DynamoDB transaction.
+	ChainMessage(ctx context.Context, params *ChainMessageInput[T]) (*ChainMessageOutput[T], error)
+	// DeleteExpiredMessages deletes the messages of a queue that are older than a retention period.
+	DeleteExpiredMessages(ctx context.Context, params *DeleteExpiredMessagesInput) (*DeleteExpiredMessagesOutput, error)
+	// AcquireLock acquires or extends a lock item stored in the queue table.
+	AcquireLock(ctx context.Context, params *AcquireLockInput) (*AcquireLockOutput, error)
+	// ReleaseLock deletes a lock item held by the owner.
+	ReleaseLock(ctx context.Context, params *ReleaseLockInput) (*ReleaseLockOutput, error)
+	// SetQueueEnabled pauses or resumes the queue for the clients that respect the queue control.
+	SetQueueEnabled(ctx context.Context, params *SetQueueEnabledInput) (*SetQueueEnabledOutput, error)
+	// SaveQueueConfig stores the defaults shared by every service using the queue in a configuration item.
+	SaveQueueConfig(ctx context.Context, params *SaveQueueConfigInput) (*SaveQueueConfigOutput, error)
+	// GetQueueConfig returns the configuration stored with SaveQueueConfig, as cached by the client.
+	GetQueueConfig(ctx context.Context, params *GetQueueConfigInput) (*GetQueueConfigOutput, error)
+	// ListInFlightMessages lists the messages being processed with the time each becomes visible again.
+	ListInFlightMessages(ctx context.Context, params *ListInFlightMessagesInput) (*ListInFlightMessagesOutput, error)
+	// ResetReceiveCount sets the receive count of a specific message back to zero.
+	ResetReceiveCount(ctx context.Context, params *ResetReceiveCountInput) (*ResetReceiveCountOutput[T], error)
+	// UpdateMessageData replaces the payload of a specific message with a conditional update.
+	UpdateMessageData(ctx context.Context, params *UpdateMessageDataInput[T]) (*UpdateMessageDataOutput[T], error)
+	// CopyMessage copies a specific message to another queue table.
+	CopyMessage(ctx context.Context, params *CopyMessageInput) (*CopyMessageOutput[T], error)
+	// MoveMessage moves a specific message to another queue table.
+	MoveMessage(ctx context.Context, params *MoveMessageInput) (*MoveMessageOutput[T], error)
+	// ReconcileInFlightCount sets the counter of the in-flight limit to the number of messages holding a slot.
+	ReconcileInFlightCount(ctx context.Context, params *ReconcileInFlightCountInput) (*ReconcileInFlightCountOutput, error)
+	// MoveMessagesToDLQ moves several messages to the DLQ, reporting the result of each.
+	MoveMessagesToDLQ(ctx context.Context, params *MoveMessagesToDLQInput) (*MoveMessagesToDLQOutput[T], error)
+	// MoveMessagesToDLQByFilter moves the messages of the STANDARD queue matching a filter to the DLQ.
+	MoveMessagesToDLQByFilter(ctx context.Context, params *MoveMessagesToDLQByFilterInput) (*MoveMessagesToDLQOutput[T], error)
+	// SendCanary sends a synthetic message marked as a canary to check the health of the queue.
+	SendCanary(ctx context.Context, params *SendCanaryInput) (*SendCanaryOutput[T], error)
+	// CheckCanary polls a canary until it is deleted and returns the measured latencies.
+	CheckCanary(ctx context.Context, params *CheckCanaryInput) (*CheckCanaryOutput, error)
+	// PromoteScheduledMessages moves the due messages of the SCHEDULED queue type to the STANDARD queue.
+	PromoteScheduledMessages(ctx context.Context, params *PromoteScheduledMessagesInput) (*PromoteScheduledMessagesOutput, error)
+	// ReclaimExpiredMessages makes the messages whose visibility timeout has expired visible again.
+	ReclaimExpiredMessages(ctx context.Context, params *ReclaimExpiredMessagesInput) (*ReclaimExpiredMessagesOutput, error)
+	// HoldMessage freezes a specific message so that it is neither received nor redriven until it is released.
+	HoldMessage(ctx context.Context, params *HoldMessageInput) (*HoldMessageOutput[T], error)
+	// ReleaseMessage releases a message held with HoldMessage.
+	ReleaseMessage(ctx context.Context, params *ReleaseMessageInput) (*ReleaseMessageOutput[T], error)
+}
+
+// DynamoDBAPI is the subset of the Amazon DynamoDB API used by the DynamoMQ client.
+// *dynamodb.Client satisfies this interface. Other implementations, such as fakes for testing, can be set with WithDynamoDBAPI.
+type DynamoDBAPI interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+	DescribeTimeToLive(ctx context.Context, params *dynamodb.DescribeTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error)
+	TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error)
+	BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+	BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error)
 }
 
 // ClientOptions defines configuration options for the DynamoMQ client.
@@ -61,8 +148,8 @@ type Client[T any] interface {
 //
 // In typical use, these testing fields should not be modified. They are provided to support advanced use cases, like unit testing, where control over these operations is necessary.
 type ClientOptions struct {
-	// DynamoDB is a pointer to the DynamoDB client used for database operations.
-	DynamoDB *dynamodb.Client
+	// DynamoDB is the DynamoDB client used for database operations.
+	DynamoDB DynamoDBAPI
 	// TableName is the name of the DynamoDB table used for the queue.
 	TableName string
 	// QueueingIndexName is the name of the index used for queueing operations.
@@ -72,9 +159,96 @@ type ClientOptions struct {
 	// UseFIFO is a boolean indicating if the queue should behave as a First-In-First-Out (FIFO) queue.
 	UseFIFO bool
 	// BaseEndpoint is the base endpoint URL for DynamoDB requests.
+	// If it is empty, the AWS_ENDPOINT_URL_DYNAMODB environment variable is used, if set.
 	BaseEndpoint string
 	// RetryMaxAttempts is the maximum number of attempts for retrying failed DynamoDB operations.
 	RetryMaxAttempts int
+	// SkipCorruptMessages is a boolean indicating if items that cannot be unmarshaled should be skipped while
+	// receiving messages or calculating statistics, instead of failing the whole operation with a CorruptMessageError.
+	SkipCorruptMessages bool
+	// OnCorruptMessage is an optional callback invoked for every item skipped because of SkipCorruptMessages.
+	OnCorruptMessage func(err CorruptMessageError)
+	// TableSchema defines the attribute names and the queueing index name used in the table.
+	TableSchema TableSchema
+	// ValidateSchema is a boolean indicating if NewFromConfig should verify the table schema with DescribeTable.
+	ValidateSchema bool
+	// DLQNotifier is notified every time a message is moved to the DLQ.
+	DLQNotifier DLQNotifier
+	// Archiver is the Archiver[T] set with WithArchiver, which archives every message before it is deleted.
+	// It is typed any because ClientOptions is shared by clients of every message type;
+	// NewFromConfig returns an InvalidArchiverError if it does not archive messages of the type of the client.
+	Archiver any
+	// Hooks are the Hooks[T] set with WithHooks, invoked after each operation that changes the state of a message.
+	// NewFromConfig returns an InvalidHooksError if they are not for the type of message of the client.
+	Hooks any
+	// Sampler holds the rate and the sink set with WithSampler, which receives a fraction of the received messages.
+	// NewFromConfig returns an InvalidSamplerError if the sink does not take messages of the type of the client.
+	Sampler any
+	// PayloadUpgrader holds the current version of the schema of the payload and the PayloadUpgrader[T] set with
+	// WithPayloadUpgrader. NewFromConfig returns an InvalidPayloadUpgraderError if it is not for the type of message
+	// of the client.
+	PayloadUpgrader any
+	// Validator is the Validator[T] set with WithValidator, which checks the payload of every message before it is
+	// written. NewFromConfig returns an InvalidValidatorError if it does not validate messages of the type of the client.
+	Validator any
+	// RespectQueueControl is a boolean indicating if ReceiveMessage should return a QueuePausedError
+	// while the queue is paused with SetQueueEnabled.
+	RespectQueueControl bool
+	// QueueControlRefreshInterval is the time interval at which the control item is read again
+	// when RespectQueueControl is enabled.
+	QueueControlRefreshInterval time.Duration
+	// UseStoredQueueConfig is a boolean indicating if the client should read the QueueConfig saved with SaveQueueConfig
+	// and use it as the defaults of the options left unset.
+	UseStoredQueueConfig bool
+	// QueueConfigRefreshInterval is the time interval at which the stored QueueConfig is read again.
+	QueueConfigRefreshInterval time.Duration
+	// AuditTrail is a boolean indicating if the transitions of each message should be recorded in its History.
+	AuditTrail bool
+	// ActorID identifies the client in the transitions it records in the audit trail.
+	ActorID string
+	// SortKeyTieBreaker is a boolean indicating if a tie-breaker should be appended to the SentAt of the messages
+	// the client writes to the queue, so that messages sent at the same time have a stable order.
+	SortKeyTieBreaker bool
+	// ConsumerID identifies the client in the messages it receives. By default, it is the host name and the process ID.
+	ConsumerID string
+	// ReceiveQueueTypes are the queue types ReceiveMessage tries in rotation when the input does not set a QueueType.
+	ReceiveQueueTypes []QueueTypeWeight
+	// EmptyQueueCooldown is the time a queue type found empty is skipped by the rotation of ReceiveQueueTypes.
+	EmptyQueueCooldown time.Duration
+	// ProcessingDeadline is the age, counted from SentAt, after which a message outside the DLQ is moved to the DLQ
+	// by ReceiveMessage instead of being received. Zero means no deadline.
+	ProcessingDeadline time.Duration
+	// StatsCacheTTL is the time GetQueueStats returns the statistics it has read before reading them again.
+	StatsCacheTTL time.Duration
+	// MinReceivePageSize is the number of items ReceiveMessage reads in its first query for a candidate message.
+	MinReceivePageSize int32
+	// MaxReceivePageSize is the number of items up to which the queries of ReceiveMessage grow
+	// while their pages contain no candidate message.
+	MaxReceivePageSize int32
+	// MaxInFlight is the maximum number of messages processed at the same time across every client of the queue.
+	// Zero means no limit.
+	MaxInFlight int
+	// ReceiveConflictRetries is the number of times ReceiveMessage selects the next message of the queue when
+	// the message it selected is received by another client first. Zero returns the conflict to the caller.
+	ReceiveConflictRetries int
+	// EmptyReceiveAsNil is a boolean indicating if ReceiveMessage should return a nil output and a nil error
+	// instead of an EmptyQueueError when there is no message to receive.
+	EmptyReceiveAsNil bool
+	// OperationTimeout is the time each DynamoDB API call is allowed to take, independently of the context
+	// passed by the caller. Zero means no timeout other than the one of the context.
+	OperationTimeout time.Duration
+	// DefaultOperationTimeout is the time an operation on a single message is allowed to take, including every
+	// DynamoDB API call it makes, when the context passed by the caller has no deadline. Zero means no timeout.
+	DefaultOperationTimeout time.Duration
+	// UseScheduledQueue is a boolean indicating if delayed messages should be sent to the SCHEDULED queue type,
+	// to be promoted to the standard queue by a Mover once due, instead of to the standard queue directly.
+	UseScheduledQueue bool
+	// TenantFairnessWindow is the number of messages at the head of the queue among which ReceiveMessage prefers
+	// the message of the least recently served tenant. Zero receives the messages in order, regardless of their tenant.
+	TenantFairnessWindow int
+	// ErrorLog is an optional logger for the errors the client recovers from without returning them, such as
+	// a failure to notify the DLQNotifier or a panic in a hook. If nil, the standard logger is used.
+	ErrorLog *log.Logger
 
 	// Clock is an abstraction of time operations, allowing control over time during tests.
 	Clock clock.Clock
@@ -119,13 +293,25 @@ func WithUseFIFO(useFIFO bool) func(*ClientOptions) {
 // This function is used to provide a pre-configured DynamoDB client that the DynamoMQ client will use for all interactions with DynamoDB.
 func WithAWSDynamoDBClient(client *dynamodb.Client) func(*ClientOptions) {
 	return func(s *ClientOptions) {
-		s.DynamoDB = client
+		if client != nil {
+			s.DynamoDB = client
+		}
+	}
+}
+
+// WithDynamoDBAPI is an option function to set a custom implementation of DynamoDBAPI for the DynamoMQ client.
+// This function is primarily useful for testing, where a fake implementation can stand in for Amazon DynamoDB.
+func WithDynamoDBAPI(api DynamoDBAPI) func(*ClientOptions) {
+	return func(s *ClientOptions) {
+		s.DynamoDB = api
 	}
 }
 
 // WithAWSBaseEndpoint is an option function to set a custom base endpoint for AWS services.
 // This function is useful when you want the client to interact with a specific AWS service endpoint, such as a local or a different regional endpoint.
-// If the DynamoDB client is set using the WithAWSDynamoDBClient function, this option function is ignored.
+// Without it, the endpoint set in the AWS_ENDPOINT_URL_DYNAMODB environment variable is used, if any, so that
+// DynamoDB Local or LocalStack can be targeted without changing the code.
+// If the DynamoDB client is set using the WithAWSDynamoDBClient function, this option function and the environment variable are ignored.
 func WithAWSBaseEndpoint(baseEndpoint string) func(*ClientOptions) {
 	return func(s *ClientOptions) {
 		s.BaseEndpoint = baseEndpoint
@@ -141,19 +327,304 @@ func WithAWSRetryMaxAttempts(retryMaxAttempts int) func(*ClientOptions) {
 	}
 }
 
+// WithSkipCorruptMessages is an option function to make the DynamoMQ client skip items that cannot be unmarshaled.
+// When enabled, ReceiveMessage, GetQueueStats and GetDLQStats continue with the remaining items of the page
+// instead of failing with a CorruptMessageError, and the statistics report how many items were skipped.
+// By default, this option is set to false.
+func WithSkipCorruptMessages(skipCorruptMessages bool) func(*ClientOptions) {
+	return func(s *ClientOptions) {
+		s.SkipCorruptMessages = skipCorruptMessages
+	}
+}
+
+// WithOnCorruptMessage is an option function to set a callback invoked for every item skipped as corrupt.
+// Use this function to log or collect the IDs of the offending items. It is only called when WithSkipCorruptMessages is enabled.
+func WithOnCorruptMessage(onCorruptMessage func(err CorruptMessageError)) func(*ClientOptions) {
+	return func(s *ClientOptions) {
+		s.OnCorruptMessage = onCorruptMessage
+	}
+}
+
+// WithDLQNotifier is an option function to set a notifier invoked every time a message is moved to the DLQ,
+// including the moves made by a Consumer. A failed notification does not fail the move; it is logged and counted.
+func WithDLQNotifier(notifier DLQNotifier) func(*ClientOptions) {
+	return func(s *ClientOptions) {
+		s.DLQNotifier = notifier
+	}
+}
+
+// WithArchiver is an option function to archive every message before DeleteMessage or ChainMessage deletes it,
+// including the deletions made by a Consumer after a message has been processed.
+// A failed archive aborts the deletion with an ArchiveError.
+func WithArchiver[T any](archiver Archiver[T]) func(*ClientOptions) {
+	return func(s *ClientOptions) {
+		s.Archiver = archiver
+	}
+}
+
+// WithRespectQueueControl is an option function to make ReceiveMessage return a QueuePausedError
+// while the queue is paused with SetQueueEnabled. The control item is cached and read again every
+// QueueControlRefreshInterval, five seconds by default.
+func WithRespectQueueControl(respectQueueControl bool) func(*ClientOptions) {
+	return func(s *ClientOptions) {
+		s.RespectQueueControl = respectQueueControl
+	}
+}
+
+// WithQueueControlRefreshInterval is an option function to set the time interval at which the control item is read again.
+func WithQueueControlRefreshInterval(interval time.Duration) func(*ClientOptions) {
+	return func(s *ClientOptions) {
+		s.QueueControlRefreshInterval = interval
+	}
+}
+
+// WithStoredQueueConfig is an option function to make the client use the QueueConfig saved with SaveQueueConfig
+// as the defaults of the options left unset, so that every service consuming the queue agrees on them.
+// The configuration is read by NewFromConfig and again every QueueConfigRefreshInterval, one minute by default.
+func WithStoredQueueConfig(useStoredQueueConfig bool) func(*ClientOptions) {
+	return func(s *ClientOptions) {
+		s.UseStoredQueueConfig = useStoredQueueConfig
+	}
+}
+
+// WithQueueConfigRefreshInterval is an option function to set the time interval at which the stored QueueConfig is read again.
+func WithQueueConfigRefreshInterval(interval time.Duration) func(*ClientOptions) {
+	return func(s *ClientOptions) {
+		s.QueueConfigRefreshInterval = interval
+	}
+}
+
+// WithAuditTrail is an option function to record the transitions of each message in its History:
+// when it is sent, received, moved to the DLQ and redriven. It is disabled by default because it increases the size of every write.
+func WithAuditTrail(auditTrail bool) func(*ClientOptions) {
+	return func(o *ClientOptions) {
+		o.AuditTrail = auditTrail
+	}
+}
+
+// WithSortKeyTieBreaker is an option function to append a tie-breaker to the SentAt of the messages the client sends,
+// redrives or moves to the DLQ, so that messages sent at the same time, or by producers whose clocks are skewed,
+// are received in a stable order from a FIFO queue. SentAt is then written with all nine digits of the fractional second,
+// followed by '#' and a sequence number of the process, so the messages sent by the process are received in the order
+// they were sent. The messages written before sort among the new ones by their time of sending, to within the second.
+// Every client reads the tie-breaker, so it can be enabled on the producers alone. It is disabled by default.
+func WithSortKeyTieBreaker(sortKeyTieBreaker bool) func(*ClientOptions) {
+	return func(o *ClientOptions) {
+		o.SortKeyTieBreaker = sortKeyTieBreaker
+	}
+}
+
+// WithActorID is an option function to set the identifier of the client, such as a host name or a service name,
+// recorded in the transitions of the audit trail.
+func WithActorID(actorID string) func(*ClientOptions) {
+	return func(o *ClientOptions) {
+		o.ActorID = actorID
+	}
+}
+
+// WithConsumerID is an option function to set the identifier that ReceiveMessage writes to the messages it receives,
+// so that the owner of a message being processed can be told. By default, it is the host name followed by the process ID.
+// An empty identifier disables the recording.
+func WithConsumerID(consumerID string) func(*ClientOptions) {
+	return func(o *ClientOptions) {
+		o.ConsumerID = consumerID
+	}
+}
+
+// WithReceiveQueueTypes is an option function to make ReceiveMessage try several queue types in rotation
+// when the input does not set a QueueType. Each receive starts with the next queue type in the given order,
+// so a busy queue type cannot starve the others. The message returned tells its queue type in its QueueType field.
+func WithReceiveQueueTypes(queueTypes ...QueueType) func(*ClientOptions) {
+	return func(o *ClientOptions) {
+		o.ReceiveQueueTypes = make([]QueueTypeWeight, 0, len(queueTypes))
+		for _, queueType := range queueTypes {
+			o.ReceiveQueueTypes = append(o.ReceiveQueueTypes, QueueTypeWeight{QueueType: queueType, Weight: 1})
+		}
+	}
+}
+
+// WithWeightedReceiveQueueTypes is an option function to make ReceiveMessage try several queue types in rotation
+// as WithReceiveQueueTypes does, with each queue type starting a share of the receives proportional to its weight.
+func WithWeightedReceiveQueueTypes(weights ...QueueTypeWeight) func(*ClientOptions) {
+	return func(o *ClientOptions) {
+		o.ReceiveQueueTypes = weights
+	}
+}
+
+// WithEmptyQueueCooldown is an option function to set the time a queue type found empty is skipped by the rotation
+// of WithReceiveQueueTypes, to save queries on quiet queues. By default, it is five seconds.
+func WithEmptyQueueCooldown(cooldown time.Duration) func(*ClientOptions) {
+	return func(o *ClientOptions) {
+		o.EmptyQueueCooldown = cooldown
+	}
+}
+
+// WithStatsCache is an option function to cache the statistics returned by GetQueueStats for the ttl,
+// per queue type. Each call of GetQueueStats reads every message of the queue, so dashboards polling it often
+// can cost more than the workload itself. A call with ForceRefresh reads the statistics again.
+// By default, the statistics are not cached.
+func WithStatsCache(ttl time.Duration) func(*ClientOptions) {
+	return func(o *ClientOptions) {
+		o.StatsCacheTTL = ttl
+	}
+}
+
+// WithReceivePageSize is an option function to set the bounds of the page size of the queries ReceiveMessage
+// makes to find a message. The first query reads minSize items, which is enough when the head of the queue is visible.
+// Every page without a message to receive doubles the size of the next query up to maxSize, so that a head contended
+// by other consumers is skipped in few queries, and the size goes back to minSize after a message is received.
+// By default, the bounds are 10 and 250.
+func WithReceivePageSize(minSize, maxSize int32) func(*ClientOptions) {
+	return func(o *ClientOptions) {
+		o.MinReceivePageSize = minSize
+		o.MaxReceivePageSize = maxSize
+	}
+}
+
+// WithProcessingDeadline is an option function to make ReceiveMessage move the messages outside the DLQ
+// that are older than the deadline, counted from when they were sent, to the DLQ with the reason "deadline exceeded"
+// instead of delivering them. It catches the messages that keep timing out without ever failing, which the
+// maximum number of receives does not. A message can override the deadline or be exempted from it when it is sent.
+// By default, there is no deadline.
+func WithProcessingDeadline(deadline time.Duration) func(*ClientOptions) {
+	return func(o *ClientOptions) {
+		o.ProcessingDeadline = deadline
+	}
+}
+
+// WithMaxInFlight is an option function to limit the number of messages processed at the same time across
+// every client of the queue, however many consumers are running. A counter item in the queue table is incremented
+// when a message is received and decremented when it is deleted, moved to the DLQ or made visible again,
+// and ReceiveMessage returns an InFlightLimitExceededError while the limit is reached. Every client of the queue
+// should use the same limit. By default, there is no limit.
+func WithMaxInFlight(maxInFlight int) func(*ClientOptions) {
+	return func(o *ClientOptions) {
+		o.MaxInFlight = maxInFlight
+	}
+}
+
+// WithReceiveConflictRetries is an option function to set the number of times ReceiveMessage selects the next
+// message of the queue when the message it selected is received by another client between its query and its update.
+// The messages lost this way are passed over by the following selections of the same call. When the retries are
+// exhausted, or set to zero, the ConditionalCheckFailedError of the last update is returned. By default, it is 3.
+func WithReceiveConflictRetries(retries int) func(*ClientOptions) {
+	return func(o *ClientOptions) {
+		o.ReceiveConflictRetries = retries
+	}
+}
+
+// WithEmptyReceiveAsNil is an option function to make ReceiveMessage return a nil output and a nil error
+// instead of an EmptyQueueError when there is no message to receive, so that an empty queue is not counted as a failure.
+// The retry hint of the EmptyQueueError is lost in this mode. Other errors, such as a QueuePausedError, are still returned.
+// By default, this option is set to false.
+func WithEmptyReceiveAsNil(emptyReceiveAsNil bool) func(*ClientOptions) {
+	return func(o *ClientOptions) {
+		o.EmptyReceiveAsNil = emptyReceiveAsNil
+	}
+}
+
+// WithOperationTimeout is an option function to bound each DynamoDB API call made by the client with a timeout.
+// Use this function to keep a single hung call from stalling an operation called with a long-lived context,
+// such as the polling loop of a Consumer. The timeout is derived from the context of the operation and applies
+// to every call separately, so a paginated operation such as GetQueueStats gives each page the full timeout.
+// A call that exceeds it fails with an OperationTimeoutError, which is retryable.
+// By default, there is no timeout other than the one of the context.
+func WithOperationTimeout(timeout time.Duration) func(*ClientOptions) {
+	return func(o *ClientOptions) {
+		o.OperationTimeout = timeout
+	}
+}
+
+// WithDefaultOperationTimeout is an option function to bound the operations on a single message, such as ReceiveMessage
+// or ChangeMessageVisibility, which make several DynamoDB API calls. The timeout covers the whole operation and applies
+// only when the context passed by the caller has no deadline, so a caller's own deadline always takes precedence.
+// An operation that exceeds it fails with a DefaultOperationTimeoutError naming the DynamoDB API call that timed out.
+// Operations that page through the queue, such as GetQueueStats, ListMessages or DumpMessages, are not bounded;
+// combine it with WithOperationTimeout to bound each of their calls as well.
+// By default, there is no timeout other than the one of the context.
+func WithDefaultOperationTimeout(timeout time.Duration) func(*ClientOptions) {
+	return func(o *ClientOptions) {
+		o.DefaultOperationTimeout = timeout
+	}
+}
+
+// WithScheduledQueue is an option function to send delayed messages to the SCHEDULED queue type instead of the standard queue.
+// Use this function when messages are delayed far ahead: the receive path skips the messages that are not due yet,
+// so a standard queue holding many of them makes every poll read items it cannot deliver.
+// With this option, a Mover promotes the scheduled messages to the standard queue once they are due.
+// Only the messages sent to the standard queue with a delay are scheduled; the others are sent as usual.
+// By default, this option is set to false.
+func WithScheduledQueue(useScheduledQueue bool) func(*ClientOptions) {
+	return func(o *ClientOptions) {
+		o.UseScheduledQueue = useScheduledQueue
+	}
+}
+
+// WithTenantFairness is an option function to share the receives among the tenants of a queue, set with the TenantID
+// of SendMessageInput, so that a tenant sending many messages does not starve the others.
+// ReceiveMessage then looks at up to window messages that can be received at the head of the queue, and receives
+// the oldest one of the tenant served least recently by the client, or of a tenant it has not served recently.
+// The fairness is approximate: each client remembers only the last 64 tenants it served, and a tenant whose messages
+// are all beyond the window waits for the messages before them. A larger window is fairer but reads more items
+// on every receive. The option is ignored in FIFO mode, where only the first message can be received.
+// By default, messages are received in order, regardless of their tenant.
+func WithTenantFairness(window int) func(*ClientOptions) {
+	return func(o *ClientOptions) {
+		o.TenantFairnessWindow = window
+	}
+}
+
+// WithTableSchema is an option function to set the attribute names and the queueing index name used in the table.
+// Use this function to adopt an existing table whose naming conventions differ from the defaults.
+// Messages are marshaled and unmarshaled under the configured names. Fields left empty keep their default names.
+func WithTableSchema(schema TableSchema) func(*ClientOptions) {
+	return func(s *ClientOptions) {
+		s.TableSchema = schema
+	}
+}
+
+// WithSchemaValidation is an option function to make NewFromConfig verify the schema of the table before returning the client.
+// When enabled, NewFromConfig calls DescribeTable and fails fast with a SchemaMismatchError if the key schema
+// or the queueing index differs from what DynamoMQ expects.
+// The validation requires the dynamodb:DescribeTable permission; pass false to skip it in environments where that permission is not granted.
+// By default, this option is set to false.
+func WithSchemaValidation(validateSchema bool) func(*ClientOptions) {
+	return func(s *ClientOptions) {
+		s.ValidateSchema = validateSchema
+	}
+}
+
+// WithClientErrorLog is an option function to set a custom logger for the errors the client recovers from
+// without returning them, such as a failure to notify the DLQNotifier, a panic in a hook or in the sink of
+// WithSampler, or a failure to release an in-flight slot.
+// By default, the standard logger is used.
+func WithClientErrorLog(errorLog *log.Logger) func(*ClientOptions) {
+	return func(s *ClientOptions) {
+		s.ErrorLog = errorLog
+	}
+}
+
 // NewFromConfig creates a new DynamoMQ client using the provided AWS configuration and any additional client options.
 // This function initializes a new client with default settings, which can be customized using option functions.
-// It returns an error if the initialization of the DynamoDB client fails.
+// It returns an error if the initialization of the DynamoDB client fails, or if the schema validation enabled with
+// WithSchemaValidation fails.
 func NewFromConfig[T any](cfg aws.Config, optFns ...func(*ClientOptions)) (Client[T], error) {
 	o := &ClientOptions{
-		TableName:           constant.DefaultTableName,
-		QueueingIndexName:   constant.DefaultQueueingIndexName,
-		RetryMaxAttempts:    constant.DefaultRetryMaxAttempts,
-		UseFIFO:             false,
-		Clock:               &clock.RealClock{},
-		MarshalMap:          attributevalue.MarshalMap,
-		UnmarshalMap:        attributevalue.UnmarshalMap,
-		UnmarshalListOfMaps: attributevalue.UnmarshalListOfMaps,
+		TableName:                   constant.DefaultTableName,
+		QueueingIndexName:           constant.DefaultQueueingIndexName,
+		RetryMaxAttempts:            constant.DefaultRetryMaxAttempts,
+		UseFIFO:                     false,
+		QueueControlRefreshInterval: defaultQueueControlRefreshInterval,
+		QueueConfigRefreshInterval:  defaultQueueConfigRefreshInterval,
+		ConsumerID:                  defaultConsumerID(),
+		EmptyQueueCooldown:          defaultEmptyQueueCooldown,
+		MinReceivePageSize:          defaultMinReceivePageSize,
+		MaxReceivePageSize:          defaultQueryLimit,
+		ReceiveConflictRetries:      defaultReceiveConflictRetries,
+		Clock:                       &clock.RealClock{},
+		MarshalMap:                  attributevalue.MarshalMap,
+		UnmarshalMap:                unmarshalMap,
+		UnmarshalListOfMaps:         attributevalue.UnmarshalListOfMaps,
 		BuildExpression: func(b expression.Builder) (expression.Expression, error) {
 			return b.Build()
 		},
@@ -161,43 +632,208 @@ func NewFromConfig[T any](cfg aws.Config, optFns ...func(*ClientOptions)) (Clien
 	for _, opt := range optFns {
 		opt(o)
 	}
+	if o.TableSchema.QueueingIndexName == "" {
+		o.TableSchema.QueueingIndexName = o.QueueingIndexName
+	}
+	schema := o.TableSchema.withDefaults()
+	if err := schema.validate(); err != nil {
+		return nil, err
+	}
+	archiver, err := archiverOf[T](o.Archiver)
+	if err != nil {
+		return nil, err
+	}
+	hooks, err := hooksOf[T](o.Hooks, o.ErrorLog)
+	if err != nil {
+		return nil, err
+	}
+	sampler, err := samplerOf[T](o.Sampler, o.ErrorLog)
+	if err != nil {
+		return nil, err
+	}
+	payloadUpgrade, err := payloadUpgradeOf[T](o.PayloadUpgrader)
+	if err != nil {
+		return nil, err
+	}
+	validator, err := validatorOf[T](o.Validator)
+	if err != nil {
+		return nil, err
+	}
 	c := &ClientImpl[T]{
-		tableName:           o.TableName,
-		queueingIndexName:   o.QueueingIndexName,
-		maximumReceives:     o.MaximumReceives,
-		useFIFO:             o.UseFIFO,
-		dynamoDB:            o.DynamoDB,
-		clock:               o.Clock,
-		marshalMap:          o.MarshalMap,
-		unmarshalMap:        o.UnmarshalMap,
-		unmarshalListOfMaps: o.UnmarshalListOfMaps,
-		buildExpression:     o.BuildExpression,
-	}
-	if c.dynamoDB != nil {
-		return c, nil
-	}
-	c.dynamoDB = dynamodb.NewFromConfig(cfg, func(options *dynamodb.Options) {
-		options.RetryMaxAttempts = o.RetryMaxAttempts
-		if o.BaseEndpoint != "" {
-			options.BaseEndpoint = aws.String(o.BaseEndpoint)
+		clientSettings: clientSettings[T]{
+			schema:                      schema,
+			toStorage:                   schema.renames(),
+			fromStorage:                 invertRenames(schema.renames()),
+			maximumReceives:             o.MaximumReceives,
+			useFIFO:                     o.UseFIFO,
+			skipCorruptMessages:         o.SkipCorruptMessages,
+			onCorruptMessage:            o.OnCorruptMessage,
+			dlqNotifier:                 o.DLQNotifier,
+			archiver:                    archiver,
+			hooks:                       hooks,
+			sampler:                     sampler,
+			payloadUpgrade:              payloadUpgrade,
+			validator:                   validator,
+			respectQueueControl:         o.RespectQueueControl,
+			queueControlRefreshInterval: o.QueueControlRefreshInterval,
+			useQueueConfig:              o.UseStoredQueueConfig,
+			queueConfigRefreshInterval:  o.QueueConfigRefreshInterval,
+			auditTrail:                  o.AuditTrail,
+			actorID:                     o.ActorID,
+			sortKeyTieBreaker:           o.SortKeyTieBreaker,
+			consumerID:                  o.ConsumerID,
+			receiveSchedule:             receiveSchedule(o.ReceiveQueueTypes),
+			emptyQueueCooldown:          o.EmptyQueueCooldown,
+			maxInFlight:                 o.MaxInFlight,
+			receiveConflictRetries:      max(o.ReceiveConflictRetries, 0),
+			emptyReceiveAsNil:           o.EmptyReceiveAsNil,
+			useScheduledQueue:           o.UseScheduledQueue,
+			defaultOperationTimeout:     o.DefaultOperationTimeout,
+			tenantFairnessWindow:        o.TenantFairnessWindow,
+			processingDeadline:          o.ProcessingDeadline,
+			statsCacheTTL:               o.StatsCacheTTL,
+			minReceivePageSize:          o.MinReceivePageSize,
+			maxReceivePageSize:          o.MaxReceivePageSize,
+			dynamoDB:                    o.DynamoDB,
+			errorLog:                    o.ErrorLog,
+			clock:                       o.Clock,
+			marshalMap:                  o.MarshalMap,
+			unmarshalMap:                o.UnmarshalMap,
+			unmarshalListOfMaps:         o.UnmarshalListOfMaps,
+			buildExpression:             o.BuildExpression,
+		},
+		tableName: o.TableName,
+		tenants:   newTenantLRU(maxRecentTenants),
+	}
+	if c.minReceivePageSize <= 0 {
+		c.minReceivePageSize = defaultMinReceivePageSize
+	}
+	if c.maxReceivePageSize < c.minReceivePageSize {
+		c.maxReceivePageSize = c.minReceivePageSize
+	}
+	c.receivePageSize.Store(c.minReceivePageSize)
+	if err := c.buildStaticExpressions(); err != nil {
+		return nil, err
+	}
+	if c.dynamoDB == nil {
+		baseEndpoint := o.BaseEndpoint
+		if baseEndpoint == "" {
+			baseEndpoint = os.Getenv(envEndpointURLDynamoDB)
 		}
-	})
+		c.dynamoDB = dynamodb.NewFromConfig(cfg, func(options *dynamodb.Options) {
+			options.RetryMaxAttempts = o.RetryMaxAttempts
+			if baseEndpoint != "" {
+				options.BaseEndpoint = aws.String(baseEndpoint)
+			}
+		})
+	}
+	if o.OperationTimeout > 0 || o.DefaultOperationTimeout > 0 {
+		c.dynamoDB = timeoutDynamoDB{api: c.dynamoDB, timeout: o.OperationTimeout}
+	}
+	if o.ValidateSchema {
+		if err := c.ValidateSchema(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+	if o.UseStoredQueueConfig {
+		if _, err := c.GetQueueConfig(context.Background(), nil); err != nil {
+			return nil, err
+		}
+	}
 	return c, nil
 }
 
 // ClientImpl is a concrete implementation of the dynamomq.Client interface.
 // Note: ClientImpl cannot be used directly. Always use the dynamomq.NewFromConfig function to create an instance.
 type ClientImpl[T any] struct {
-	dynamoDB            *dynamodb.Client
-	tableName           string
-	queueingIndexName   string
-	maximumReceives     int
-	useFIFO             bool
-	clock               clock.Clock
-	marshalMap          func(in interface{}) (map[string]types.AttributeValue, error)
-	unmarshalMap        func(m map[string]types.AttributeValue, out interface{}) error
-	unmarshalListOfMaps func(l []map[string]types.AttributeValue, out interface{}) error
-	buildExpression     func(b expression.Builder) (expression.Expression, error)
+	clientSettings[T]
+	tableName string
+	tenants   *tenantLRU
+
+	schemaMu         sync.Mutex
+	tableDescription *types.TableDescription
+
+	dlqNotificationFailures atomic.Int64
+
+	controlMu               sync.Mutex
+	queueEnabled            bool
+	queueControlRefreshedAt time.Time
+
+	configMu               sync.Mutex
+	queueConfig            QueueConfig
+	queueConfigRefreshedAt time.Time
+
+	rotationMu   sync.Mutex
+	rotationNext int
+	emptyUntil   map[QueueType]time.Time
+
+	receivePageSize atomic.Int32
+
+	statsCacheMu sync.Mutex
+	statsCache   map[QueueType]cachedQueueStats
+
+	keyConditionsMu sync.RWMutex
+	keyConditions   map[QueueType]expression.Expression
+}
+
+// clientSettings holds the configuration of a ClientImpl, which the clients derived from it with WithTable share.
+// The state a client keeps per table lives in ClientImpl itself.
+type clientSettings[T any] struct {
+	dynamoDB                    DynamoDBAPI
+	errorLog                    *log.Logger
+	schema                      TableSchema
+	toStorage                   map[string]string
+	fromStorage                 map[string]string
+	maximumReceives             int
+	useFIFO                     bool
+	skipCorruptMessages         bool
+	onCorruptMessage            func(err CorruptMessageError)
+	dlqNotifier                 DLQNotifier
+	archiver                    Archiver[T]
+	hooks                       *Hooks[T]
+	sampler                     *sampler[T]
+	payloadUpgrade              *payloadUpgrade[T]
+	validator                   Validator[T]
+	respectQueueControl         bool
+	queueControlRefreshInterval time.Duration
+	useQueueConfig              bool
+	queueConfigRefreshInterval  time.Duration
+	auditTrail                  bool
+	actorID                     string
+	sortKeyTieBreaker           bool
+	consumerID                  string
+	receiveSchedule             []QueueType
+	emptyQueueCooldown          time.Duration
+	maxInFlight                 int
+	receiveConflictRetries      int
+	emptyReceiveAsNil           bool
+	useScheduledQueue           bool
+	defaultOperationTimeout     time.Duration
+	tenantFairnessWindow        int
+	processingDeadline          time.Duration
+	statsCacheTTL               time.Duration
+	minReceivePageSize          int32
+	maxReceivePageSize          int32
+	clock                       clock.Clock
+	marshalMap                  func(in interface{}) (map[string]types.AttributeValue, error)
+	unmarshalMap                func(m map[string]types.AttributeValue, out interface{}) error
+	unmarshalListOfMaps         func(l []map[string]types.AttributeValue, out interface{}) error
+	buildExpression             func(b expression.Builder) (expression.Expression, error)
+	// static holds the expressions built from the schema, only read by the calls sharing them.
+	static staticExpressions
+}
+
+func (c *clientSettings[T]) logf(format string, args ...any) {
+	logTo(c.errorLog, format, args...)
+}
+
+// logTo logs to errorLog, or to the standard logger if errorLog is nil.
+func logTo(errorLog *log.Logger, format string, args ...any) {
+	if errorLog != nil {
+		errorLog.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
 }
 
 // SendMessageInput represents the input parameters for sending a message to a DynamoDB-based queue.
@@ -209,6 +845,24 @@ type SendMessageInput[T any] struct {
 	Data T
 	// DelaySeconds is the delay time (in seconds) before the message is sent to the queue.
 	DelaySeconds int
+	// QueueType is the type of queue the message is sent to, such as the name of a logical queue sharing the table.
+	// By default, it is STANDARD.
+	QueueType QueueType
+	// ProcessingDeadline overrides, for this message, the deadline set with WithProcessingDeadline.
+	// It is rounded up to whole seconds.
+	ProcessingDeadline time.Duration
+	// SkipProcessingDeadline exempts the message from the processing deadline.
+	SkipProcessingDeadline bool
+	// CorrelationID is the correlation ID stored on the message.
+	CorrelationID string
+	// TenantID is the tenant the message belongs to, used by clients configured with WithTenantFairness.
+	TenantID string
+	// Tags are stored on the message to select it later with the Tag of ListMessagesInput. Duplicated and empty tags
+	// are dropped.
+	Tags []string
+	// PayloadSchemaVersion is the version of the schema of Data, stored on the message.
+	// If it is zero, the version set with WithPayloadUpgrader is used, if any.
+	PayloadSchemaVersion int
 }
 
 // SendMessageOutput represents the result of a message sending operation.
@@ -223,9 +877,14 @@ type SendMessageOutput[T any] struct {
 // If the message ID already exists in the queue, it returns an IDDuplicatedError. Otherwise, it adds the message to the queue.
 // The function also handles message delays. If DelaySeconds is greater than 0 in the input parameter, the message will be delayed accordingly before being sent.
 func (c *ClientImpl[T]) SendMessage(ctx context.Context, params *SendMessageInput[T]) (*SendMessageOutput[T], error) {
+	ctx, cancel := c.withDefaultTimeout(ctx, "SendMessage")
+	defer cancel()
 	if params == nil {
 		params = &SendMessageInput[T]{}
 	}
+	if err := c.validator.validate(ctx, "SendMessage", params.ID, params.Data); err != nil {
+		return &SendMessageOutput[T]{}, err
+	}
 	retrieved, err := c.GetMessage(ctx, &GetMessageInput{
 		ID: params.ID,
 	})
@@ -235,26 +894,62 @@ func (c *ClientImpl[T]) SendMessage(ctx context.Context, params *SendMessageInpu
 	if retrieved.Message != nil {
 		return &SendMessageOutput[T]{}, &IDDuplicatedError{}
 	}
+	message := c.newSentMessage(params)
+	err = c.put(ctx, message)
+	if err != nil {
+		return &SendMessageOutput[T]{}, err
+	}
+	out := &SendMessageOutput[T]{
+		SentMessage: message,
+	}
+	c.hooks.sent(ctx, params, out)
+	return out, nil
+}
+
+// newSentMessage builds the message written by SendMessage and by the transactional variants.
+func (c *ClientImpl[T]) newSentMessage(params *SendMessageInput[T]) *Message[T] {
 	now := c.clock.Now()
 	message := NewMessage(params.ID, params.Data, now)
 	if params.DelaySeconds > 0 {
 		message.delayToSentAt(time.Duration(params.DelaySeconds) * time.Second)
 	}
-	err = c.put(ctx, message)
-	if err != nil {
-		return &SendMessageOutput[T]{}, err
+	if params.QueueType != "" {
+		message.QueueType = params.QueueType
 	}
-	return &SendMessageOutput[T]{
-		SentMessage: message,
-	}, nil
+	if c.useScheduledQueue && params.DelaySeconds > 0 && message.QueueType == QueueTypeStandard {
+		message.QueueType = QueueTypeScheduled
+	}
+	c.breakSentAtTie(message)
+	message.CorrelationID = params.CorrelationID
+	message.TenantID = params.TenantID
+	message.Tags = normalizeTags(params.Tags)
+	message.PayloadSchemaVersion = params.PayloadSchemaVersion
+	if message.PayloadSchemaVersion == 0 && c.payloadUpgrade != nil {
+		message.PayloadSchemaVersion = c.payloadUpgrade.version
+	}
+	switch {
+	case params.SkipProcessingDeadline:
+		message.ProcessingDeadline = -1
+	case params.ProcessingDeadline > 0:
+		message.ProcessingDeadline = int((params.ProcessingDeadline + time.Second - 1) / time.Second)
+	}
+	c.recordTransition(message, TransitionSent, now)
+	return message
 }
 
 // ReceiveMessageInput represents the input parameters for receiving a message from a DynamoDB-based queue.
 type ReceiveMessageInput struct {
 	// QueueType is the type of queue from which the message is to be retrieved. QueueType specifies the kind of queue, such as STANDARD or DLQ.
+	// If it is not set, the queue types configured with WithReceiveQueueTypes are tried in rotation, and then STANDARD is used.
 	QueueType QueueType
 	// VisibilityTimeout is the timeout in seconds during which the message becomes invisible to other receivers.
+	// If it is not set, the VisibilityTimeout of the stored QueueConfig is used, and then the default of 30 seconds.
 	VisibilityTimeout int
+	// Skip is the number of messages that could be received to pass over, so that the message after them is received
+	// while they are left untouched, as when a message further down the queue is to be inspected by hand.
+	// An EmptyQueueError is returned when there are no more than Skip messages that could be received.
+	// Since only the first message of a FIFO queue can be received, a positive Skip always finds a FIFO queue empty.
+	Skip int
 }
 
 // ReceiveMessageOutput represents the result of a message receiving operation.
@@ -268,111 +963,272 @@ type ReceiveMessageOutput[T any] struct {
 // ReceiveMessage retrieves and processes a message from a DynamoDB-based queue using the generic type T.
 // The selection process involves constructing and executing a DynamoDB query based on the queue type and visibility timeout.
 // After a message is selected, its status, including visibility and version, is updated to ensure the message remains invisible and in processing for a defined period. This process is crucial for maintaining queue integrity and preventing duplicate message delivery.
-// If no messages are available for reception, an EmptyQueueError is returned. Additionally, when FIFO (First In, First Out) is enabled, the method guarantees that only one valid message is processed at a time.
+// If no messages are available for reception, an EmptyQueueError is returned, or a nil output and a nil error
+// with WithEmptyReceiveAsNil, and while the queue is paused with SetQueueEnabled
+// and the client respects it, a QueuePausedError is returned. While the limit set with WithMaxInFlight is reached,
+// an InFlightLimitExceededError is returned. With WithProcessingDeadline, the messages found past their deadline
+// are moved to the DLQ instead of being received. Additionally, when FIFO (First In, First Out) is enabled, the method guarantees that only one valid message is processed at a time.
 func (c *ClientImpl[T]) ReceiveMessage(ctx context.Context, params *ReceiveMessageInput) (*ReceiveMessageOutput[T], error) {
+	ctx, cancel := c.withDefaultTimeout(ctx, "ReceiveMessage")
+	defer cancel()
 	if params == nil {
 		params = &ReceiveMessageInput{}
 	}
-	if params.QueueType == "" {
-		params.QueueType = QueueTypeStandard
+	if err := c.checkQueueEnabled(ctx); err != nil {
+		return &ReceiveMessageOutput[T]{}, err
+	}
+	if params.VisibilityTimeout <= 0 {
+		config, err := c.GetQueueConfig(ctx, nil)
+		if err != nil {
+			return &ReceiveMessageOutput[T]{}, err
+		}
+		params.VisibilityTimeout = config.Config.VisibilityTimeout
 	}
 	if params.VisibilityTimeout <= 0 {
 		params.VisibilityTimeout = constant.DefaultVisibilityTimeoutInSeconds
 	}
 
-	selected, err := c.selectMessage(ctx, params)
+	var (
+		received *Message[T]
+		err      error
+	)
+	if params.QueueType == "" && len(c.receiveSchedule) > 0 {
+		received, err = c.receiveInRotation(ctx, params)
+	} else {
+		if params.QueueType == "" {
+			params.QueueType = QueueTypeStandard
+		}
+		received, err = c.receive(ctx, params)
+	}
 	if err != nil {
+		if c.emptyReceiveAsNil && errors.Is(err, ErrEmptyQueue) {
+			return nil, nil
+		}
 		return &ReceiveMessageOutput[T]{}, err
 	}
+	out := &ReceiveMessageOutput[T]{
+		ReceivedMessage: received,
+	}
+	c.hooks.received(ctx, params, out)
+	c.sampler.sample(ctx, received)
+	return out, nil
+}
+
+func (c *ClientImpl[T]) receive(ctx context.Context, params *ReceiveMessageInput) (*Message[T], error) {
+	// lost are the IDs of the messages received by other clients between their selection and their update.
+	var lost []string
+	for {
+		selected, err := c.selectReceivable(ctx, params, lost)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := c.receiveSelected(ctx, selected)
+		var conflict *ConditionalCheckFailedError
+		if errors.As(err, &conflict) && len(lost) < c.receiveConflictRetries {
+			lost = append(lost, selected.ID)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return updated, nil
+	}
+}
+
+// selectReceivable selects the message to receive, moving the messages past their processing deadline to the DLQ
+// on the way.
+func (c *ClientImpl[T]) selectReceivable(ctx context.Context, params *ReceiveMessageInput, lost []string) (*Message[T], error) {
+	selected, err := c.selectMessage(ctx, params, lost)
+	for err == nil && c.deadlineExceeded(selected, c.clock.Now()) {
+		if err = c.moveDeadlineExceeded(ctx, selected); err == nil {
+			selected, err = c.selectMessage(ctx, params, lost)
+		}
+	}
+	return selected, err
+}
 
+// receiveSelected marks the selected message as being processed, with an update conditional on its version.
+func (c *ClientImpl[T]) receiveSelected(ctx context.Context, selected *Message[T]) (*Message[T], error) {
+	// A message whose visibility timeout expired before it was released still holds its slot.
+	acquired := false
+	if c.maxInFlight > 0 && !selected.InFlightSlot {
+		if err := c.acquireInFlightSlot(ctx); err != nil {
+			return nil, err
+		}
+		selected.InFlightSlot = true
+		acquired = true
+	}
 	updated, err := c.processSelectedMessage(ctx, selected)
 	if err != nil {
-		return &ReceiveMessageOutput[T]{}, err
+		if acquired {
+			c.releaseInFlightSlot(ctx, selected.ID)
+		}
+		return nil, err
 	}
-
-	return &ReceiveMessageOutput[T]{
-		ReceivedMessage: updated,
-	}, nil
+	c.receivePageSize.Store(c.minReceivePageSize)
+	if c.tenantFairnessWindow > 0 {
+		c.tenants.served(selected.TenantID)
+	}
+	return updated, nil
 }
 
-func (c *ClientImpl[T]) selectMessage(ctx context.Context, params *ReceiveMessageInput) (*Message[T], error) {
-	builder := expression.NewBuilder().
-		WithKeyCondition(expression.Key("queue_type").Equal(expression.Value(params.QueueType)))
-	expr, err := c.buildExpression(builder)
+func (c *ClientImpl[T]) selectMessage(ctx context.Context, params *ReceiveMessageInput, lost []string) (*Message[T], error) {
+	expr, err := c.queueTypeKeyCondition(params.QueueType)
 	if err != nil {
 		return nil, BuildingExpressionError{Cause: err}
 	}
 
-	selected, err := c.executeQuery(ctx, params, expr)
+	selected, nextVisibleAt, err := c.executeQuery(ctx, params, expr, lost)
 	if err != nil {
 		return nil, err
 	}
 
 	if selected == nil {
-		return nil, &EmptyQueueError{}
+		return nil, newEmptyQueueError(c.clock.Now(), nextVisibleAt)
 	}
 	return selected, nil
 }
 
-func (c *ClientImpl[T]) executeQuery(ctx context.Context, params *ReceiveMessageInput, expr expression.Expression) (*Message[T], error) {
+// executeQuery returns the first message of the queue that can be received, passing over the lost messages.
+// When there is none, it returns the soonest time one of the messages being processed becomes visible again, if any.
+func (c *ClientImpl[T]) executeQuery(ctx context.Context, params *ReceiveMessageInput, expr expression.Expression,
+	lost []string) (*Message[T], time.Time, error) {
 	var exclusiveStartKey map[string]types.AttributeValue
 	var selectedItem *Message[T]
+	var nextVisibleAt time.Time
+	skip := params.Skip
+	fair := c.newFairSelection()
 	for {
+		if err := ctx.Err(); err != nil {
+			if timeoutErr, ok := defaultTimeoutCause(ctx); ok {
+				timeoutErr.Step = "Query"
+				return nil, time.Time{}, timeoutErr
+			}
+			return nil, time.Time{}, OperationCanceledError{Cause: err}
+		}
 		queryResult, err := c.dynamoDB.Query(ctx, &dynamodb.QueryInput{
-			IndexName:                 aws.String(c.queueingIndexName),
+			IndexName:                 aws.String(c.schema.QueueingIndexName),
 			TableName:                 aws.String(c.tableName),
 			KeyConditionExpression:    expr.KeyCondition(),
 			ExpressionAttributeNames:  expr.Names(),
 			ExpressionAttributeValues: expr.Values(),
-			Limit:                     aws.Int32(defaultQueryLimit),
+			Limit:                     aws.Int32(c.receivePageSize.Load()),
 			ScanIndexForward:          aws.Bool(true),
 			ExclusiveStartKey:         exclusiveStartKey,
 		})
 		if err != nil {
-			return nil, handleDynamoDBError(err)
+			return nil, time.Time{}, handleDynamoDBError(err)
 		}
 
 		exclusiveStartKey = queryResult.LastEvaluatedKey
 
-		selectedItem, err = c.processQueryResult(params, queryResult)
+		var stop bool
+		selectedItem, stop, err = c.processQueryResult(params, queryResult, lost, &skip, fair, &nextVisibleAt)
 		if err != nil {
-			return nil, err
+			return nil, time.Time{}, err
+		}
+		if selectedItem != nil || stop {
+			break
 		}
-		if selectedItem != nil || exclusiveStartKey == nil {
+		c.growReceivePageSize()
+		if exclusiveStartKey == nil {
 			break
 		}
 	}
-	return selectedItem, nil
+	// The end of the queue was reached before the selection was done.
+	if selectedItem == nil && fair != nil && fair.best != nil {
+		selectedItem = fair.best
+		c.recordTransition(selectedItem, TransitionReceived, c.clock.Now())
+	}
+	return selectedItem, nextVisibleAt, nil
+}
+
+// growReceivePageSize doubles the page size of the next query for a candidate message, up to its maximum.
+func (c *ClientImpl[T]) growReceivePageSize() {
+	for {
+		current := c.receivePageSize.Load()
+		next := current * 2
+		if next > c.maxReceivePageSize || next <= 0 {
+			next = c.maxReceivePageSize
+		}
+		if next == current || c.receivePageSize.CompareAndSwap(current, next) {
+			return
+		}
+	}
 }
 
-func (c *ClientImpl[T]) processQueryResult(params *ReceiveMessageInput, queryResult *dynamodb.QueryOutput) (*Message[T], error) {
-	var selected *Message[T]
+// processQueryResult returns the first message of the page that can be received, and whether the query should stop
+// without one, as it does in a FIFO queue whose first message is being processed.
+// It passes over the messages that can be received until skip reaches zero, decrementing it for each of them,
+// and then offers them to fair, if any, returning its best message once its selection is done.
+// It moves nextVisibleAt back to the time a message of the page being processed becomes visible again, if it is sooner.
+// The lost messages, received by other clients since the page may have been read from the index, are passed over
+// like the messages being processed.
+func (c *ClientImpl[T]) processQueryResult(params *ReceiveMessageInput, queryResult *dynamodb.QueryOutput,
+	lost []string, skip *int, fair *fairSelection[T], nextVisibleAt *time.Time) (*Message[T], bool, error) {
+	// The candidates are unmarshaled into the same message, so that the page costs a single allocation
+	// however many of its messages are being processed by other consumers.
+	message := &Message[T]{}
 	for _, itemMap := range queryResult.Items {
-		message := Message[T]{}
-		if err := c.unmarshalMap(itemMap, &message); err != nil {
-			return nil, UnmarshalingAttributeError{Cause: err}
+		*message = Message[T]{}
+		if err := c.unmarshalItem(itemMap, message); err != nil {
+			if err = c.handleCorruptMessage(itemMap, err); err != nil {
+				return nil, false, err
+			}
+			continue
 		}
 
-		if err := message.markAsProcessing(c.clock.Now(), secToDur(params.VisibilityTimeout)); err == nil {
-			selected = &message
-			break
+		// A held message is passed over as if it were not in the queue, except that it blocks the queue in FIFO mode.
+		if message.Held {
+			if c.useFIFO {
+				return nil, true, nil
+			}
+			continue
+		}
+
+		if slices.Contains(lost, message.ID) {
+			if c.useFIFO {
+				return nil, true, nil
+			}
+			continue
+		}
+
+		now := c.clock.Now()
+		// Checking the status first avoids building the error markAsProcessing returns for a message being processed.
+		if message.GetStatus(now) != StatusProcessing {
+			if message.markAsProcessing(now, secToDur(params.VisibilityTimeout), c.consumerID) == nil {
+				if *skip <= 0 {
+					if fair == nil {
+						c.recordTransition(message, TransitionReceived, now)
+						return message, false, nil
+					}
+					if fair.consider(message) {
+						c.recordTransition(fair.best, TransitionReceived, now)
+						return fair.best, false, nil
+					}
+				} else {
+					*skip--
+				}
+			}
+		} else if visibleAt := clock.RFC3339NanoToTime(message.InvisibleUntilAt); nextVisibleAt.IsZero() || visibleAt.Before(*nextVisibleAt) {
+			*nextVisibleAt = visibleAt
 		}
 		if c.useFIFO {
-			return nil, &EmptyQueueError{}
+			return nil, true, nil
 		}
 	}
-	return selected, nil
+	return nil, false, nil
 }
 
 func (c *ClientImpl[T]) processSelectedMessage(ctx context.Context, message *Message[T]) (*Message[T], error) {
 	builder := expression.NewBuilder().
-		WithUpdate(expression.
-			Add(expression.Name("version"), expression.Value(1)).
-			Add(expression.Name("receive_count"), expression.Value(1)).
-			Set(expression.Name("updated_at"), expression.Value(message.UpdatedAt)).
-			Set(expression.Name("received_at"), expression.Value(message.ReceivedAt)).
-			Set(expression.Name("invisible_until_at"), expression.Value(message.InvisibleUntilAt))).
-		WithCondition(expression.Name("version").Equal(expression.Value(message.Version)))
+		WithUpdate(c.setHistory(c.setInFlightSlot(c.setConsumerID(expression.
+			Add(expression.Name(c.schema.VersionAttribute), expression.Value(1)).
+			Add(expression.Name(c.schema.ReceiveCountAttribute), expression.Value(1)).
+			Set(expression.Name(c.schema.UpdatedAtAttribute), expression.Value(message.UpdatedAt)).
+			Set(expression.Name(c.schema.ReceivedAtAttribute), expression.Value(message.ReceivedAt)).
+			Set(expression.Name(c.schema.InvisibleUntilAtAttribute), expression.Value(message.InvisibleUntilAt)), message), message), message)).
+		WithCondition(expression.Name(c.schema.VersionAttribute).Equal(expression.Value(message.Version)))
 	expr, err := c.buildExpression(builder)
 	if err != nil {
 		return nil, BuildingExpressionError{Cause: err}
@@ -405,6 +1261,8 @@ type ChangeMessageVisibilityOutput[T any] struct {
 // It retrieves the message based on the specified message ID and alters its visibility timeout.
 // The visibility timeout specifies the duration during which the message, once retrieved from the queue, becomes invisible to other clients. Modifying this timeout value allows dynamic adjustment of the message processing time.
 func (c *ClientImpl[T]) ChangeMessageVisibility(ctx context.Context, params *ChangeMessageVisibilityInput) (*ChangeMessageVisibilityOutput[T], error) {
+	ctx, cancel := c.withDefaultTimeout(ctx, "ChangeMessageVisibility")
+	defer cancel()
 	if params == nil {
 		params = &ChangeMessageVisibilityInput{}
 	}
@@ -418,13 +1276,21 @@ func (c *ClientImpl[T]) ChangeMessageVisibility(ctx context.Context, params *Cha
 		return &ChangeMessageVisibilityOutput[T]{}, &IDNotFoundError{}
 	}
 	message := retrieved.Message
-	message.changeVisibility(c.clock.Now(), secToDur(params.VisibilityTimeout))
+	now := c.clock.Now()
+	if from, to := message.LifecycleStatus(now), message.visibilityStatus(secToDur(params.VisibilityTimeout)); from != to {
+		if err := ValidateTransition("change message visibility", from, to); err != nil {
+			return &ChangeMessageVisibilityOutput[T]{}, err
+		}
+	}
+	held := message.InFlightSlot
+	message.changeVisibility(now, secToDur(params.VisibilityTimeout))
+	release := held && !message.InFlightSlot
 	builder := expression.NewBuilder().
-		WithUpdate(expression.
-			Add(expression.Name("version"), expression.Value(1)).
-			Set(expression.Name("updated_at"), expression.Value(message.UpdatedAt)).
-			Set(expression.Name("invisible_until_at"), expression.Value(message.InvisibleUntilAt))).
-		WithCondition(expression.Name("version").Equal(expression.Value(message.Version)))
+		WithUpdate(c.removeInFlightSlot(c.setConsumerID(expression.
+			Add(expression.Name(c.schema.VersionAttribute), expression.Value(1)).
+			Set(expression.Name(c.schema.UpdatedAtAttribute), expression.Value(message.UpdatedAt)).
+			Set(expression.Name(c.schema.InvisibleUntilAtAttribute), expression.Value(message.InvisibleUntilAt)), message), release)).
+		WithCondition(expression.Name(c.schema.VersionAttribute).Equal(expression.Value(message.Version)))
 	expr, err := c.buildExpression(builder)
 	if err != nil {
 		return &ChangeMessageVisibilityOutput[T]{}, BuildingExpressionError{Cause: err}
@@ -433,15 +1299,29 @@ func (c *ClientImpl[T]) ChangeMessageVisibility(ctx context.Context, params *Cha
 	if err != nil {
 		return &ChangeMessageVisibilityOutput[T]{}, err
 	}
-	return &ChangeMessageVisibilityOutput[T]{
+	if release {
+		c.releaseInFlightSlot(ctx, message.ID)
+	}
+	out := &ChangeMessageVisibilityOutput[T]{
 		ChangedMessage: retried,
-	}, nil
+	}
+	c.hooks.visibilityChanged(ctx, params, out)
+	return out, nil
 }
 
 // DeleteMessageInput represents the input parameters for deleting a specific message from a DynamoDB-based queue.
 type DeleteMessageInput struct {
 	// ID is the unique identifier of the message to be deleted from the queue.
 	ID string
+	// StrictExistenceCheck makes the deletion conditional on the existence of the message.
+	// When it is true and no message with the ID exists, IDNotFoundError is returned.
+	// When it is false (the default), deleting a non-existent message succeeds silently.
+	StrictExistenceCheck bool
+	// ExpectedVersion is the version the message must be at for the deletion to succeed, typically the version
+	// of the message that was read before deciding to delete it. If it is zero, the message is deleted at any version.
+	// When it is set, a VersionConflictError is returned if the message is at another version, and an
+	// IDNotFoundError if it does not exist.
+	ExpectedVersion int
 }
 
 // DeleteMessageOutput represents the result of the delete message operation.
@@ -450,7 +1330,11 @@ type DeleteMessageOutput struct{}
 
 // DeleteMessage deletes a specific message from a DynamoDB-based queue.
 // It directly deletes the message from DynamoDB based on the specified message ID.
+// No transition is recorded in the audit trail, which is deleted along with the message.
+// With WithArchiver, the message is read and archived first, and it is not deleted if the archive fails.
 func (c *ClientImpl[T]) DeleteMessage(ctx context.Context, params *DeleteMessageInput) (*DeleteMessageOutput, error) {
+	ctx, cancel := c.withDefaultTimeout(ctx, "DeleteMessage")
+	defer cancel()
 	if params == nil {
 		params = &DeleteMessageInput{}
 	}
@@ -458,17 +1342,53 @@ func (c *ClientImpl[T]) DeleteMessage(ctx context.Context, params *DeleteMessage
 	if params.ID == "" {
 		return out, &IDNotProvidedError{}
 	}
-	_, err := c.dynamoDB.DeleteItem(ctx, &dynamodb.DeleteItemInput{
-		TableName: &c.tableName,
-		Key: map[string]types.AttributeValue{
-			"id": &types.AttributeValueMemberS{
-				Value: params.ID,
-			},
-		},
-	})
+	if c.archiver != nil {
+		if err := c.archiveAndDeleteMessage(ctx, params); err != nil {
+			return out, err
+		}
+		c.hooks.deleted(ctx, params, out)
+		return out, nil
+	}
+	input := &dynamodb.DeleteItemInput{
+		TableName:    &c.tableName,
+		Key:          c.itemKey(params.ID),
+		ReturnValues: types.ReturnValueAllOld,
+	}
+	switch {
+	case params.ExpectedVersion > 0:
+		expr, err := c.buildExpression(expression.NewBuilder().
+			WithCondition(expression.Name(c.schema.VersionAttribute).Equal(expression.Value(params.ExpectedVersion))))
+		if err != nil {
+			return out, BuildingExpressionError{Cause: err}
+		}
+		input.ConditionExpression = expr.Condition()
+		input.ExpressionAttributeNames = expr.Names()
+		input.ExpressionAttributeValues = expr.Values()
+		input.ReturnValuesOnConditionCheckFailure = types.ReturnValuesOnConditionCheckFailureAllOld
+	case params.StrictExistenceCheck:
+		expr := c.static.idExists
+		input.ConditionExpression = expr.Condition()
+		input.ExpressionAttributeNames = expr.Names()
+	}
+	deleted, err := c.dynamoDB.DeleteItem(ctx, input)
 	if err != nil {
+		var cause *types.ConditionalCheckFailedException
+		if errors.As(err, &cause) {
+			switch {
+			case params.ExpectedVersion > 0 && cause.Item != nil:
+				return out, VersionConflictError{ID: params.ID, Version: params.ExpectedVersion}
+			case params.ExpectedVersion > 0 || params.StrictExistenceCheck:
+				return out, &IDNotFoundError{}
+			}
+		}
 		return out, handleDynamoDBError(err)
 	}
+	if deleted != nil {
+		if _, ok := deleted.Attributes[c.schema.InFlightSlotAttribute]; ok {
+			c.releaseInFlightSlot(ctx, params.ID)
+		}
+	}
+	c.hooks.deleted(ctx, params, out)
 	return out, nil
 }
 
@@ -476,6 +1396,8 @@ func (c *ClientImpl[T]) DeleteMessage(ctx context.Context, params *DeleteMessage
 type MoveMessageToDLQInput struct {
 	// ID is the unique identifier of the message to be moved to the DLQ.
 	ID string
+	// Reason describes why the message is moved to the DLQ. It is passed to the DLQNotifier.
+	Reason string
 }
 
 // MoveMessageToDLQOutput represents the result of the operation to move a message to the DLQ.
@@ -483,13 +1405,17 @@ type MoveMessageToDLQInput struct {
 type MoveMessageToDLQOutput[T any] struct {
 	// MovedMessage is a pointer to the Message type containing information about the moved message.
 	// The type T determines the format of the message content.
+	// It is the message as written by the move, payload included, read from the result of the update without another read.
 	MovedMessage *Message[T]
 }
 
 // MoveMessageToDLQ moves a specific message from a DynamoDB-based queue to a Dead Letter Queue (DLQ).
 // It locates the message based on the specified message ID and marks it for the DLQ.
 // Moving a message to the DLQ allows for the isolation of failed message processing, facilitating later analysis and reprocessing.
+// Once the message is moved, the DLQNotifier set with WithDLQNotifier is notified.
 func (c *ClientImpl[T]) MoveMessageToDLQ(ctx context.Context, params *MoveMessageToDLQInput) (*MoveMessageToDLQOutput[T], error) {
+	ctx, cancel := c.withDefaultTimeout(ctx, "MoveMessageToDLQ")
+	defer cancel()
 	if params == nil {
 		params = &MoveMessageToDLQInput{}
 	}
@@ -503,22 +1429,27 @@ func (c *ClientImpl[T]) MoveMessageToDLQ(ctx context.Context, params *MoveMessag
 		return &MoveMessageToDLQOutput[T]{}, &IDNotFoundError{}
 	}
 	message := retrieved.Message
-	if markedErr := message.markAsMovedToDLQ(c.clock.Now()); markedErr != nil {
+	now := c.clock.Now()
+	release := message.InFlightSlot
+	from, receiveCount := message.QueueType, message.ReceiveCount
+	if markedErr := message.markAsMovedToDLQ(now); markedErr != nil {
 		//lint:ignore nilerr reason
 		return &MoveMessageToDLQOutput[T]{
 			MovedMessage: message,
 		}, nil
 	}
+	c.breakSentAtTie(message)
+	c.recordTransition(message, TransitionMovedToDLQ, now)
 	builder := expression.NewBuilder().
-		WithUpdate(expression.
-			Add(expression.Name("version"), expression.Value(1)).
-			Set(expression.Name("receive_count"), expression.Value(message.ReceiveCount)).
-			Set(expression.Name("queue_type"), expression.Value(message.QueueType)).
-			Set(expression.Name("updated_at"), expression.Value(message.UpdatedAt)).
-			Set(expression.Name("sent_at"), expression.Value(message.SentAt)).
-			Set(expression.Name("received_at"), expression.Value(message.ReceivedAt)).
-			Set(expression.Name("invisible_until_at"), expression.Value(message.InvisibleUntilAt))).
-		WithCondition(expression.Name("version").Equal(expression.Value(message.Version)))
+		WithUpdate(c.setHistory(c.removeInFlightSlot(c.setConsumerID(expression.
+			Add(expression.Name(c.schema.VersionAttribute), expression.Value(1)).
+			Set(expression.Name(c.schema.ReceiveCountAttribute), expression.Value(message.ReceiveCount)).
+			Set(expression.Name(c.schema.QueueTypeAttribute), expression.Value(message.QueueType)).
+			Set(expression.Name(c.schema.UpdatedAtAttribute), expression.Value(message.UpdatedAt)).
+			Set(expression.Name(c.schema.SentAtAttribute), expression.Value(message.SentAt)).
+			Set(expression.Name(c.schema.ReceivedAtAttribute), expression.Value(message.ReceivedAt)).
+			Set(expression.Name(c.schema.InvisibleUntilAtAttribute), expression.Value(message.InvisibleUntilAt)), message), release), message)).
+		WithCondition(expression.Name(c.schema.VersionAttribute).Equal(expression.Value(message.Version)))
 	expr, err := c.buildExpression(builder)
 	if err != nil {
 		return &MoveMessageToDLQOutput[T]{}, BuildingExpressionError{Cause: err}
@@ -527,15 +1458,32 @@ func (c *ClientImpl[T]) MoveMessageToDLQ(ctx context.Context, params *MoveMessag
 	if err != nil {
 		return &MoveMessageToDLQOutput[T]{}, err
 	}
-	return &MoveMessageToDLQOutput[T]{
+	if release {
+		c.releaseInFlightSlot(ctx, params.ID)
+	}
+	c.notifyDLQ(ctx, updated, from, receiveCount, params.Reason)
+	out := &MoveMessageToDLQOutput[T]{
 		MovedMessage: updated,
-	}, nil
+	}
+	c.hooks.movedToDLQ(ctx, params, out)
+	return out, nil
 }
 
 // RedriveMessageInput represents the input parameters for restoring a specific message from a DynamoDB-based Dead Letter Queue (DLQ) back to the STANDARD queue.
 type RedriveMessageInput struct {
 	// ID is the unique identifier of the message to be redriven from the DLQ.
 	ID string
+	// ResetReceiveCount zeroes the receive count of the message as part of the redrive,
+	// so that it gets the full number of receives again before being moved back to the DLQ.
+	ResetReceiveCount bool
+	// DelaySeconds is the delay time (in seconds) before the redriven message can be received,
+	// as for SendMessageInput, so that it is not reprocessed before the cause of its failure is fixed.
+	DelaySeconds int
+	// ExpectedVersion is the version the message must be at for the redrive to succeed, typically the version
+	// of the message that was inspected before deciding to redrive it. If it is zero, the message is redriven
+	// at the version it is read at. When it is set, a VersionConflictError is returned if the message is
+	// at another version.
+	ExpectedVersion int
 }
 
 // RedriveMessageOutput represents the result of the operation to redrive a message from the DLQ.
@@ -543,6 +1491,7 @@ type RedriveMessageInput struct {
 type RedriveMessageOutput[T any] struct {
 	// RedroveMessage is a pointer to the Message type containing information about the redriven message.
 	// The type T determines the format of the message content.
+	// It is the message as written by the redrive, payload included, read from the result of the update without another read.
 	RedroveMessage *Message[T]
 }
 
@@ -550,6 +1499,8 @@ type RedriveMessageOutput[T any] struct {
 // It locates the message based on the specified message ID and marks it as restored from the DLQ to the standard queue.
 // This process is essential for reprocessing messages that have failed to be processed and is a crucial function in error handling within the message queue system.
 func (c *ClientImpl[T]) RedriveMessage(ctx context.Context, params *RedriveMessageInput) (*RedriveMessageOutput[T], error) {
+	ctx, cancel := c.withDefaultTimeout(ctx, "RedriveMessage")
+	defer cancel()
 	if params == nil {
 		params = &RedriveMessageInput{}
 	}
@@ -563,45 +1514,129 @@ func (c *ClientImpl[T]) RedriveMessage(ctx context.Context, params *RedriveMessa
 		return &RedriveMessageOutput[T]{}, &IDNotFoundError{}
 	}
 	message := retrieved.Message
-	err = message.markAsRestoredFromDLQ(c.clock.Now())
+	if params.ExpectedVersion > 0 && message.Version != params.ExpectedVersion {
+		return &RedriveMessageOutput[T]{}, VersionConflictError{ID: params.ID, Version: params.ExpectedVersion}
+	}
+	if message.Held {
+		return &RedriveMessageOutput[T]{}, MessageHeldError{ID: message.ID, Reason: message.HoldReason}
+	}
+	now := c.clock.Now()
+	err = message.markAsRestoredFromDLQ(now)
 	if err != nil {
 		return &RedriveMessageOutput[T]{}, err
 	}
+	if params.DelaySeconds > 0 {
+		message.delayToSentAt(time.Duration(params.DelaySeconds) * time.Second)
+		if c.useScheduledQueue {
+			message.QueueType = QueueTypeScheduled
+		}
+	}
+	c.breakSentAtTie(message)
+	c.recordTransition(message, TransitionRedriven, now)
+	update := expression.Add(
+		expression.Name(c.schema.VersionAttribute),
+		expression.Value(1),
+	)
+	if params.ResetReceiveCount {
+		update = update.Set(expression.Name(c.schema.ReceiveCountAttribute), expression.Value(0))
+	}
 	builder := expression.NewBuilder().
-		WithUpdate(expression.Add(
-			expression.Name("version"),
-			expression.Value(1),
-		).Set(
-			expression.Name("queue_type"),
+		WithUpdate(c.setHistory(c.setConsumerID(update.Set(
+			expression.Name(c.schema.QueueTypeAttribute),
 			expression.Value(message.QueueType),
 		).Set(
-			expression.Name("updated_at"),
+			expression.Name(c.schema.UpdatedAtAttribute),
 			expression.Value(message.UpdatedAt),
 		).Set(
-			expression.Name("sent_at"),
+			expression.Name(c.schema.SentAtAttribute),
 			expression.Value(message.SentAt),
 		).Set(
-			expression.Name("invisible_until_at"),
+			expression.Name(c.schema.InvisibleUntilAtAttribute),
 			expression.Value(message.InvisibleUntilAt),
-		)).
-		WithCondition(expression.Name("version").
+		), message), message)).
+		WithCondition(expression.Name(c.schema.VersionAttribute).
 			Equal(expression.Value(message.Version)))
 	expr, err := c.buildExpression(builder)
 	if err != nil {
-		return nil, BuildingExpressionError{Cause: err}
+		return &RedriveMessageOutput[T]{}, BuildingExpressionError{Cause: err}
 	}
 	updated, err := c.updateDynamoDBItem(ctx, params.ID, &expr)
+	var conflict *ConditionalCheckFailedError
+	if params.ExpectedVersion > 0 && errors.As(err, &conflict) {
+		return &RedriveMessageOutput[T]{}, VersionConflictError{ID: params.ID, Version: params.ExpectedVersion}
+	}
 	if err != nil {
 		return &RedriveMessageOutput[T]{}, err
 	}
-	return &RedriveMessageOutput[T]{
+	out := &RedriveMessageOutput[T]{
 		RedroveMessage: updated,
+	}
+	c.hooks.redriven(ctx, params, out)
+	return out, nil
+}
+
+// ResetReceiveCountInput represents the input parameters for resetting the receive count of a specific message.
+type ResetReceiveCountInput struct {
+	// ID is the unique identifier of the message whose receive count is reset.
+	ID string
+}
+
+// ResetReceiveCountOutput represents the result of the operation to reset the receive count of a message.
+// This struct uses the generic type T and contains information about the updated message.
+type ResetReceiveCountOutput[T any] struct {
+	// ResetMessage is a pointer to the Message type containing information about the updated message.
+	// The type T determines the format of the message content.
+	ResetMessage *Message[T]
+}
+
+// ResetReceiveCount sets the receive count of a specific message back to zero, so that after a downstream outage
+// it gets the full number of receives again before the consumer moves it to the DLQ.
+// The update is conditional on the version of the message, which is incremented.
+func (c *ClientImpl[T]) ResetReceiveCount(ctx context.Context, params *ResetReceiveCountInput) (*ResetReceiveCountOutput[T], error) {
+	ctx, cancel := c.withDefaultTimeout(ctx, "ResetReceiveCount")
+	defer cancel()
+	if params == nil {
+		params = &ResetReceiveCountInput{}
+	}
+	retrieved, err := c.GetMessage(ctx, &GetMessageInput{
+		ID: params.ID,
+	})
+	if err != nil {
+		return &ResetReceiveCountOutput[T]{}, err
+	}
+	if retrieved.Message == nil {
+		return &ResetReceiveCountOutput[T]{}, &IDNotFoundError{}
+	}
+	message := retrieved.Message
+	builder := expression.NewBuilder().
+		WithUpdate(expression.
+			Add(expression.Name(c.schema.VersionAttribute), expression.Value(1)).
+			Set(expression.Name(c.schema.ReceiveCountAttribute), expression.Value(0)).
+			Set(expression.Name(c.schema.UpdatedAtAttribute), expression.Value(clock.FormatRFC3339Nano(c.clock.Now())))).
+		WithCondition(expression.Name(c.schema.VersionAttribute).Equal(expression.Value(message.Version)))
+	expr, err := c.buildExpression(builder)
+	if err != nil {
+		return &ResetReceiveCountOutput[T]{}, BuildingExpressionError{Cause: err}
+	}
+	updated, err := c.updateDynamoDBItem(ctx, params.ID, &expr)
+	if err != nil {
+		return &ResetReceiveCountOutput[T]{}, err
+	}
+	return &ResetReceiveCountOutput[T]{
+		ResetMessage: updated,
 	}, nil
 }
 
 // GetQueueStatsInput represents the input parameters for obtaining statistical information about a DynamoDB-based queue.
-// This struct does not contain any fields as it's used to request general queue statistics without the need for specific parameters.
-type GetQueueStatsInput struct{}
+type GetQueueStatsInput struct {
+	// QueueType is the queue whose statistics are returned. By default, it is STANDARD.
+	QueueType QueueType
+	// ForceRefresh reads the statistics from the table even if the client caches them with WithStatsCache.
+	ForceRefresh bool
+	// CountByStatus sets TotalMessagesByStatus on the output. The messages are counted in the same pass
+	// over the queue as the other totals.
+	CountByStatus bool
+}
 
 // GetQueueStatsOutput represents the output containing statistical information about a DynamoDB-based queue.
 type GetQueueStatsOutput struct {
@@ -615,15 +1650,39 @@ type GetQueueStatsOutput struct {
 	TotalMessagesInQueueProcessing int `json:"total_messages_in_queue_processing"`
 	// TotalMessagesInQueueReady is the total number of messages in the queue that are ready to be processed and have not started processing yet.
 	TotalMessagesInQueueReady int `json:"total_messages_in_queue_ready"`
+	// TotalMessagesInQueueHeld is the total number of messages held with HoldMessage. They are counted neither as processing nor as ready.
+	TotalMessagesInQueueHeld int `json:"total_messages_in_queue_held"`
+	// TotalCorruptMessagesSkipped is the total number of items skipped because they could not be unmarshaled.
+	// It is always zero unless the client is configured with WithSkipCorruptMessages.
+	TotalCorruptMessagesSkipped int `json:"total_corrupt_messages_skipped"`
+	// ConsumerIDsInQueueProcessing maps the IDs in First100IDsInQueueProcessing to the consumer processing them.
+	// Messages received by a client without a consumer ID are not included.
+	ConsumerIDsInQueueProcessing map[string]string `json:"consumer_ids_in_queue_processing,omitempty"`
+	// TotalMessagesByStatus is the number of messages in each status reported by LifecycleStatus, so the messages
+	// of the DLQ are counted as DLQ or DLQ_PROCESSING. Held messages are counted in their status as well, so the counts
+	// add up to TotalMessagesInQueue. It is set only when CountByStatus is set on the input.
+	TotalMessagesByStatus map[Status]int `json:"total_messages_by_status,omitempty"`
 }
 
 // GetQueueStats get statistical information about a DynamoDB-based queue.
 // It provides statistics about the messages in the queue and their processing status. This includes the IDs of the first 100 messages in the queue, the first 100 IDs of messages selected for processing, the total number of records in the queue, the number of records currently in processing, and the number of records awaiting processing.
 // This function provides essential information for monitoring and analyzing the message queue system, aiding in understanding the status of the queue.
-func (c *ClientImpl[T]) GetQueueStats(ctx context.Context, _ *GetQueueStatsInput) (*GetQueueStatsOutput, error) {
-	builder := expression.NewBuilder().
-		WithKeyCondition(expression.KeyEqual(expression.Key("queue_type"), expression.Value(QueueTypeStandard)))
-	expr, err := c.buildExpression(builder)
+// When the client is created with WithStatsCache, the statistics read within the TTL are returned from the cache.
+func (c *ClientImpl[T]) GetQueueStats(ctx context.Context, params *GetQueueStatsInput) (*GetQueueStatsOutput, error) {
+	if params == nil {
+		params = &GetQueueStatsInput{}
+	}
+	queueType := params.QueueType
+	if queueType == "" {
+		queueType = QueueTypeStandard
+	}
+	if !params.ForceRefresh {
+		if stats, ok := c.cachedQueueStats(queueType); ok {
+			return withCountByStatus(stats, params.CountByStatus), nil
+		}
+	}
+
+	expr, err := c.queueTypeKeyCondition(queueType)
 	if err != nil {
 		return &GetQueueStatsOutput{}, BuildingExpressionError{Cause: err}
 	}
@@ -632,8 +1691,18 @@ func (c *ClientImpl[T]) GetQueueStats(ctx context.Context, _ *GetQueueStatsInput
 	if err != nil {
 		return &GetQueueStatsOutput{}, err
 	}
+	c.cacheQueueStats(queueType, stats)
 
-	return stats, nil
+	return withCountByStatus(stats, params.CountByStatus), nil
+}
+
+// withCountByStatus drops the counts by status, which are always taken so that they can be cached,
+// unless they are requested.
+func withCountByStatus(stats *GetQueueStatsOutput, countByStatus bool) *GetQueueStatsOutput {
+	if !countByStatus {
+		stats.TotalMessagesByStatus = nil
+	}
+	return stats
 }
 
 func (c *ClientImpl[T]) queryAndCalculateQueueStats(ctx context.Context, expr expression.Expression) (*GetQueueStatsOutput, error) {
@@ -644,13 +1713,17 @@ func (c *ClientImpl[T]) queryAndCalculateQueueStats(ctx context.Context, expr ex
 			TotalMessagesInQueue:           0,
 			TotalMessagesInQueueProcessing: 0,
 			TotalMessagesInQueueReady:      0,
+			TotalMessagesByStatus:          make(map[Status]int),
 		}
 		exclusiveStartKey map[string]types.AttributeValue
 	)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, OperationCanceledError{Cause: err}
+		}
 		queryOutput, err := c.dynamoDB.Query(ctx, &dynamodb.QueryInput{
-			IndexName:                 aws.String(c.queueingIndexName),
+			IndexName:                 aws.String(c.schema.QueueingIndexName),
 			TableName:                 aws.String(c.tableName),
 			ExpressionAttributeNames:  expr.Names(),
 			KeyConditionExpression:    expr.KeyCondition(),
@@ -673,18 +1746,21 @@ func (c *ClientImpl[T]) queryAndCalculateQueueStats(ctx context.Context, expr ex
 			break
 		}
 	}
-	stats.TotalMessagesInQueueReady = stats.TotalMessagesInQueue - stats.TotalMessagesInQueueProcessing
 	return stats, nil
 }
 
 func (c *ClientImpl[T]) processQueryItemsForQueueStats(items []map[string]types.AttributeValue, stats *GetQueueStatsOutput) error {
 	for _, itemMap := range items {
-		stats.TotalMessagesInQueue++
 		item := Message[T]{}
-		err := c.unmarshalMap(itemMap, &item)
+		err := c.unmarshalItem(itemMap, &item)
 		if err != nil {
-			return UnmarshalingAttributeError{Cause: err}
+			if err = c.handleCorruptMessage(itemMap, err); err != nil {
+				return err
+			}
+			stats.TotalCorruptMessagesSkipped++
+			continue
 		}
+		stats.TotalMessagesInQueue++
 
 		c.updateQueueStatsFromItem(&item, stats)
 	}
@@ -692,11 +1768,23 @@ func (c *ClientImpl[T]) processQueryItemsForQueueStats(items []map[string]types.
 }
 
 func (c *ClientImpl[T]) updateQueueStatsFromItem(message *Message[T], stats *GetQueueStatsOutput) {
-	if message.GetStatus(c.clock.Now()) == StatusProcessing {
+	stats.TotalMessagesByStatus[message.LifecycleStatus(c.clock.Now())]++
+	switch {
+	case message.Held:
+		stats.TotalMessagesInQueueHeld++
+	case message.GetStatus(c.clock.Now()) == StatusProcessing:
 		stats.TotalMessagesInQueueProcessing++
 		if len(stats.First100IDsInQueueProcessing) < maxFirstMessagesInQueue {
 			stats.First100IDsInQueueProcessing = append(stats.First100IDsInQueueProcessing, message.ID)
+			if message.ConsumerID != "" {
+				if stats.ConsumerIDsInQueueProcessing == nil {
+					stats.ConsumerIDsInQueueProcessing = make(map[string]string)
+				}
+				stats.ConsumerIDsInQueueProcessing[message.ID] = message.ConsumerID
+			}
 		}
+	default:
+		stats.TotalMessagesInQueueReady++
 	}
 	if len(stats.First100IDsInQueue) < maxFirstMessagesInQueue {
 		stats.First100IDsInQueue = append(stats.First100IDsInQueue, message.ID)
@@ -713,15 +1801,35 @@ type GetDLQStatsOutput struct {
 	First100IDsInQueue []string `json:"first_100_IDs_in_queue"`
 	// TotalMessagesInDLQ is the total number of messages present in the DLQ.
 	TotalMessagesInDLQ int `json:"total_messages_in_DLQ"`
+	// TotalCorruptMessagesSkipped is the total number of items skipped because they could not be unmarshaled.
+	// It is always zero unless the client is configured with WithSkipCorruptMessages.
+	TotalCorruptMessagesSkipped int `json:"total_corrupt_messages_skipped"`
+	// Entries describes the messages of First100IDsInQueue, in the same order.
+	Entries []DLQStatsEntry `json:"entries"`
+}
+
+// DLQStatsEntry describes a message in the DLQ, as listed by GetDLQStats.
+type DLQStatsEntry struct {
+	// ID is the unique identifier of the message.
+	ID string `json:"id"`
+	// Status is the status of the message at the time of the call: StatusProcessing while it is received from the DLQ.
+	Status Status `json:"status"`
+	// UpdatedAt is the timestamp when the message was last updated.
+	UpdatedAt string `json:"updated_at"`
+	// MovedToDLQAt is the timestamp when the message was moved to the DLQ.
+	MovedToDLQAt string `json:"moved_to_dlq_at"`
+	// ReceiveCount is the number of times the message has been received from the DLQ.
+	ReceiveCount int `json:"receive_count"`
+	// ReceivedAt is the timestamp when the message was last received from the DLQ, or empty if it has not been.
+	ReceivedAt string `json:"received_at,omitempty"`
 }
 
 // GetDLQStats get statistical information about a DynamoDB-based Dead Letter Queue (DLQ).
 // It provides statistics on the messages within the DLQ. This includes the IDs of the first 100 messages in the queue and the total number of records in the DLQ.
+// The timestamps, status and receive count of these messages are listed in Entries, read from the same query without additional reads.
 // This functions offers vital information for monitoring and analyzing the message queue system, aiding in understanding the status of the DLQ.
 func (c *ClientImpl[T]) GetDLQStats(ctx context.Context, _ *GetDLQStatsInput) (*GetDLQStatsOutput, error) {
-	builder := expression.NewBuilder().
-		WithKeyCondition(expression.KeyEqual(expression.Key("queue_type"), expression.Value(QueueTypeDLQ)))
-	expr, err := c.buildExpression(builder)
+	expr, err := c.queueTypeKeyCondition(QueueTypeDLQ)
 	if err != nil {
 		return &GetDLQStatsOutput{}, BuildingExpressionError{Cause: err}
 	}
@@ -739,12 +1847,16 @@ func (c *ClientImpl[T]) queryAndCalculateDLQStats(ctx context.Context, expr expr
 		stats = &GetDLQStatsOutput{
 			First100IDsInQueue: make([]string, 0),
 			TotalMessagesInDLQ: 0,
+			Entries:            make([]DLQStatsEntry, 0),
 		}
 		lastEvaluatedKey map[string]types.AttributeValue
 	)
 	for {
+		if err := ctx.Err(); err != nil {
+			return &GetDLQStatsOutput{}, OperationCanceledError{Cause: err}
+		}
 		queryOutput, err := c.dynamoDB.Query(ctx, &dynamodb.QueryInput{
-			IndexName:                 aws.String(c.queueingIndexName),
+			IndexName:                 aws.String(c.schema.QueueingIndexName),
 			TableName:                 aws.String(c.tableName),
 			ExpressionAttributeNames:  expr.Names(),
 			ExpressionAttributeValues: expr.Values(),
@@ -771,15 +1883,28 @@ func (c *ClientImpl[T]) queryAndCalculateDLQStats(ctx context.Context, expr expr
 }
 
 func (c *ClientImpl[T]) processQueryItemsForDLQStats(items []map[string]types.AttributeValue, stats *GetDLQStatsOutput) error {
+	now := c.clock.Now()
 	for _, itemMap := range items {
+		item := Message[T]{}
+		err := c.unmarshalItem(itemMap, &item)
+		if err != nil {
+			if err = c.handleCorruptMessage(itemMap, err); err != nil {
+				return err
+			}
+			stats.TotalCorruptMessagesSkipped++
+			continue
+		}
 		stats.TotalMessagesInDLQ++
 		if len(stats.First100IDsInQueue) < maxFirstMessagesInQueue {
-			item := Message[T]{}
-			err := c.unmarshalMap(itemMap, &item)
-			if err != nil {
-				return UnmarshalingAttributeError{Cause: err}
-			}
 			stats.First100IDsInQueue = append(stats.First100IDsInQueue, item.ID)
+			stats.Entries = append(stats.Entries, DLQStatsEntry{
+				ID:           item.ID,
+				Status:       item.GetStatus(now),
+				UpdatedAt:    item.UpdatedAt,
+				MovedToDLQAt: sentAtTimestamp(item.SentAt),
+				ReceiveCount: item.ReceiveCount,
+				ReceivedAt:   item.ReceivedAt,
+			})
 		}
 	}
 	return nil
@@ -789,6 +1914,9 @@ func (c *ClientImpl[T]) processQueryItemsForDLQStats(items []map[string]types.At
 type GetMessageInput struct {
 	// ID is the unique identifier of the message to be retrieved from the queue.
 	ID string
+	// OmitData leaves the payload of the message out of the read, so that checking the system attributes of a large
+	// message does not transfer its payload. The Data field of the retrieved message is then the zero value.
+	OmitData bool
 }
 
 // GetMessageOutput represents the result of the operation to retrieve a message.
@@ -797,24 +1925,32 @@ type GetMessageOutput[T any] struct {
 	// Message is a pointer to the Message type containing information about the retrieved message.
 	// The type T determines the format of the message content.
 	Message *Message[T]
+	// DataOmitted is true when the Data field of the message is the zero value because OmitData was set.
+	DataOmitted bool
 }
 
 // GetMessage get a specific message from a DynamoDB-based queue.
 // It retrieves the message from DynamoDB based on the specified message ID. The retrieved message is then unmarshaled into the specified generic type T.
+// With OmitData, the payload is not read, and only the system attributes of the message are retrieved.
 func (c *ClientImpl[T]) GetMessage(ctx context.Context, params *GetMessageInput) (*GetMessageOutput[T], error) {
+	ctx, cancel := c.withDefaultTimeout(ctx, "GetMessage")
+	defer cancel()
 	if params == nil {
 		params = &GetMessageInput{}
 	}
 	if params.ID == "" {
 		return &GetMessageOutput[T]{}, &IDNotProvidedError{}
 	}
-	resp, err := c.dynamoDB.GetItem(ctx, &dynamodb.GetItemInput{
-		Key: map[string]types.AttributeValue{
-			"id": &types.AttributeValueMemberS{Value: params.ID},
-		},
+	input := &dynamodb.GetItemInput{
+		Key:            c.itemKey(params.ID),
 		TableName:      aws.String(c.tableName),
 		ConsistentRead: aws.Bool(true),
-	})
+	}
+	if params.OmitData {
+		input.ProjectionExpression = c.static.omitData.Projection()
+		input.ExpressionAttributeNames = c.static.omitData.Names()
+	}
+	resp, err := c.dynamoDB.GetItem(ctx, input)
 	if err != nil {
 		return &GetMessageOutput[T]{}, handleDynamoDBError(err)
 	}
@@ -822,12 +1958,13 @@ func (c *ClientImpl[T]) GetMessage(ctx context.Context, params *GetMessageInput)
 		return &GetMessageOutput[T]{}, nil
 	}
 	item := Message[T]{}
-	err = c.unmarshalMap(resp.Item, &item)
+	err = c.unmarshalItem(resp.Item, &item)
 	if err != nil {
 		return &GetMessageOutput[T]{}, UnmarshalingAttributeError{Cause: err}
 	}
 	return &GetMessageOutput[T]{
-		Message: &item,
+		Message:     &item,
+		DataOmitted: params.OmitData,
 	}, nil
 }
 
@@ -835,6 +1972,14 @@ func (c *ClientImpl[T]) GetMessage(ctx context.Context, params *GetMessageInput)
 type ListMessagesInput struct {
 	// Size is the number of messages to be listed from the queue. It determines the maximum size of the returned message list.
 	Size int32
+	// OmitData leaves the payload of the messages out of the read, so that listing large messages for display
+	// transfers only their system attributes. The Data field of the listed messages is then the zero value.
+	OmitData bool
+	// Tag lists only the messages sent with the tag. The tag is matched with a filter expression, so the scan goes on
+	// until Size messages match or the whole table is read, and every item read is charged, matching or not.
+	Tag string
+	// NextToken is the token returned by the previous call, to list the messages after the ones it listed.
+	NextToken string
 }
 
 // ListMessagesOutput represents the result of the operation to list messages from the queue.
@@ -843,11 +1988,19 @@ type ListMessagesOutput[T any] struct {
 	// Messages is an array of pointers to Message types, containing information about each listed message.
 	// The type T determines the format of the message content for each message in the array.
 	Messages []*Message[T]
+	// DataOmitted is true when the Data field of the messages is the zero value because OmitData was set.
+	DataOmitted bool
+	// NextToken is set when the table has more items to be scanned. Pass it to the next call to list them.
+	// A page may hold fewer than Size messages, even none, while NextToken is set.
+	NextToken string
 }
 
 // ListMessages get a list of messages from a DynamoDB-based queue.
 // It scans and retrieves messages from DynamoDB based on the specified size parameter. If the size is not specified or is zero or less, a default maximum list size of 10 is used.
 // The retrieved messages are unmarshaled into an array of the generic type T and are sorted based on the update time.
+// With OmitData, the payloads are not read, which saves most of the transfer when the messages are only displayed.
+// With Tag, only the messages sent with the tag are listed.
+// A single call lists one page of the table; NextToken of the output lists the next one.
 func (c *ClientImpl[T]) ListMessages(ctx context.Context, params *ListMessagesInput) (*ListMessagesOutput[T], error) {
 	if params == nil {
 		params = &ListMessagesInput{}
@@ -855,22 +2008,152 @@ func (c *ClientImpl[T]) ListMessages(ctx context.Context, params *ListMessagesIn
 	if params.Size <= 0 {
 		params.Size = constant.DefaultMaxListMessages
 	}
-	output, err := c.dynamoDB.Scan(ctx, &dynamodb.ScanInput{
-		TableName: &c.tableName,
-		Limit:     aws.Int32(params.Size),
-	})
+	exclusiveStartKey, err := decodeNextToken(params.NextToken)
 	if err != nil {
-		return &ListMessagesOutput[T]{}, handleDynamoDBError(err)
+		return &ListMessagesOutput[T]{}, err
+	}
+	var output *dynamodb.ScanOutput
+	if params.Tag != "" {
+		output, err = c.scanTagged(ctx, params, exclusiveStartKey)
+	} else {
+		output, err = c.scanMessages(ctx, params, exclusiveStartKey)
 	}
-	var messages []*Message[T]
-	err = c.unmarshalListOfMaps(output.Items, &messages)
 	if err != nil {
-		return &ListMessagesOutput[T]{}, UnmarshalingAttributeError{Cause: err}
+		return &ListMessagesOutput[T]{}, err
+	}
+	var nextToken string
+	if output.LastEvaluatedKey != nil {
+		nextToken, err = encodeNextToken(output.LastEvaluatedKey)
+		if err != nil {
+			return &ListMessagesOutput[T]{}, err
+		}
+	}
+	var messages []*Message[T]
+	if (c.payloadUpgrade != nil || rawPayload[T]()) && !params.OmitData {
+		messages = make([]*Message[T], 0, len(output.Items))
+		for _, item := range output.Items {
+			message := &Message[T]{}
+			if err := c.unmarshalItem(item, message); err != nil {
+				return &ListMessagesOutput[T]{}, UnmarshalingAttributeError{Cause: err}
+			}
+			messages = append(messages, message)
+		}
+	} else {
+		items := make([]map[string]types.AttributeValue, 0, len(output.Items))
+		for _, item := range output.Items {
+			items = append(items, renameAttributes(item, c.fromStorage))
+		}
+		err = c.unmarshalListOfMaps(items, &messages)
+		if err != nil {
+			return &ListMessagesOutput[T]{}, UnmarshalingAttributeError{Cause: err}
+		}
 	}
 	sort.Slice(messages, func(i, j int) bool {
 		return messages[i].UpdatedAt < messages[j].UpdatedAt
 	})
-	return &ListMessagesOutput[T]{Messages: messages}, nil
+	return &ListMessagesOutput[T]{Messages: messages, DataOmitted: params.OmitData, NextToken: nextToken}, nil
+}
+
+// scanMessages scans a page of Size items of the table, starting after exclusiveStartKey.
+func (c *ClientImpl[T]) scanMessages(ctx context.Context, params *ListMessagesInput, exclusiveStartKey map[string]types.AttributeValue) (*dynamodb.ScanOutput, error) {
+	expr := c.static.listMessages
+	if params.OmitData {
+		expr = c.static.listMessagesOmitData
+	}
+	output, err := c.dynamoDB.Scan(ctx, &dynamodb.ScanInput{
+		TableName:                &c.tableName,
+		Limit:                    aws.Int32(params.Size),
+		FilterExpression:         expr.Filter(),
+		ProjectionExpression:     expr.Projection(),
+		ExpressionAttributeNames: expr.Names(),
+		ExclusiveStartKey:        exclusiveStartKey,
+	})
+	if err != nil {
+		return nil, handleDynamoDBError(err)
+	}
+	return output, nil
+}
+
+// scanTagged scans the table page by page for the first Size messages sent with the Tag of params, starting after
+// exclusiveStartKey. When Size messages are found, LastEvaluatedKey of the output is the key of the last one.
+func (c *ClientImpl[T]) scanTagged(ctx context.Context, params *ListMessagesInput, exclusiveStartKey map[string]types.AttributeValue) (*dynamodb.ScanOutput, error) {
+	builder := expression.NewBuilder().
+		WithFilter(expression.AttributeExists(expression.Name(c.schema.QueueTypeAttribute)).And(c.hasTag(params.Tag)))
+	if params.OmitData {
+		builder = builder.WithProjection(c.systemAttributeProjection())
+	}
+	expr, err := c.buildExpression(builder)
+	if err != nil {
+		return nil, BuildingExpressionError{Cause: err}
+	}
+	input := &dynamodb.ScanInput{
+		TableName:                 &c.tableName,
+		Limit:                     aws.Int32(max(params.Size, defaultQueryLimit)),
+		FilterExpression:          expr.Filter(),
+		ProjectionExpression:      expr.Projection(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ExclusiveStartKey:         exclusiveStartKey,
+	}
+	out := &dynamodb.ScanOutput{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, OperationCanceledError{Cause: err}
+		}
+		output, err := c.dynamoDB.Scan(ctx, input)
+		if err != nil {
+			return nil, handleDynamoDBError(err)
+		}
+		for _, item := range output.Items {
+			out.Items = append(out.Items, item)
+			if int32(len(out.Items)) == params.Size {
+				out.LastEvaluatedKey = c.tableKey(item)
+				return out, nil
+			}
+		}
+		if output.LastEvaluatedKey == nil {
+			return out, nil
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+}
+
+// systemAttributeProjection projects every attribute of a message except its payload.
+func (c *ClientImpl[T]) systemAttributeProjection() expression.ProjectionBuilder {
+	names := []string{
+		c.schema.PartitionKeyAttribute,
+		c.schema.SortKeyAttribute,
+		c.schema.IDAttribute,
+		c.schema.ReceiveCountAttribute,
+		c.schema.QueueTypeAttribute,
+		c.schema.VersionAttribute,
+		c.schema.CreatedAtAttribute,
+		c.schema.UpdatedAtAttribute,
+		c.schema.SentAtAttribute,
+		c.schema.ReceivedAtAttribute,
+		c.schema.InvisibleUntilAtAttribute,
+		c.schema.ConsumerIDAttribute,
+		c.schema.HistoryAttribute,
+		c.schema.ProcessingDeadlineAttribute,
+		c.schema.InFlightSlotAttribute,
+		c.schema.CanaryAttribute,
+		c.schema.CorrelationIDAttribute,
+		c.schema.TenantIDAttribute,
+		c.schema.TagsAttribute,
+		c.schema.HeldAttribute,
+		c.schema.HoldReasonAttribute,
+		c.schema.PayloadSchemaVersionAttribute,
+	}
+	var projection expression.ProjectionBuilder
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		projection = projection.AddNames(expression.Name(name))
+	}
+	return projection
 }
 
 // ReplaceMessageInput represents the input parameters for replacing a specific message in a DynamoDB-based queue.
@@ -889,34 +2172,138 @@ type ReplaceMessageOutput struct {
 // ReplaceMessage replace a specific message within a DynamoDB-based queue.
 // It searches for an existing message based on the specified message ID and deletes it if found. Then, a new message is added to the queue.
 // If a message with the specified ID does not exist, the new message is directly added to the queue.
+// The in-flight slot of the deleted message is released, unless the new message holds it.
+// The OnDeleted hook is invoked for the deleted message, and the OnSent hook for the new one.
 func (c *ClientImpl[T]) ReplaceMessage(ctx context.Context, params *ReplaceMessageInput[T]) (*ReplaceMessageOutput, error) {
+	ctx, cancel := c.withDefaultTimeout(ctx, "ReplaceMessage")
+	defer cancel()
 	if params == nil {
 		params = &ReplaceMessageInput[T]{
 			Message: &Message[T]{},
 		}
 	}
+	if err := c.validator.validate(ctx, "ReplaceMessage", params.Message.ID, params.Message.Data); err != nil {
+		return &ReplaceMessageOutput{}, err
+	}
 	retrieved, err := c.GetMessage(ctx, &GetMessageInput{
 		ID: params.Message.ID,
 	})
 	if err != nil {
 		return &ReplaceMessageOutput{}, err
 	}
+	var held bool
 	if retrieved.Message != nil {
-		_, delErr := c.dynamoDB.DeleteItem(ctx, &dynamodb.DeleteItemInput{
-			TableName: aws.String(c.tableName),
-			Key: map[string]types.AttributeValue{
-				"id": &types.AttributeValueMemberS{Value: params.Message.ID},
-			},
+		deleted, delErr := c.dynamoDB.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+			TableName:    aws.String(c.tableName),
+			Key:          c.itemKey(params.Message.ID),
+			ReturnValues: types.ReturnValueAllOld,
 		})
 		if delErr != nil {
 			return &ReplaceMessageOutput{}, handleDynamoDBError(delErr)
 		}
+		if deleted != nil {
+			_, held = deleted.Attributes[c.schema.InFlightSlotAttribute]
+		}
+	}
+	err = c.put(ctx, params.Message)
+	if held && (err != nil || !params.Message.InFlightSlot) {
+		c.releaseInFlightSlot(ctx, params.Message.ID)
+	}
+	if err != nil {
+		return &ReplaceMessageOutput{}, err
+	}
+	if retrieved.Message != nil {
+		c.hooks.deleted(ctx, &DeleteMessageInput{ID: params.Message.ID, StrictExistenceCheck: true}, &DeleteMessageOutput{})
+	}
+	c.hooks.sent(ctx, &SendMessageInput[T]{ID: params.Message.ID, Data: params.Message.Data}, &SendMessageOutput[T]{SentMessage: params.Message})
+	return &ReplaceMessageOutput{}, nil
+}
+
+// UpdateMessageDataInput represents the input parameters for replacing the payload of a specific message.
+type UpdateMessageDataInput[T any] struct {
+	// ID is the unique identifier of the message to be updated.
+	ID string
+	// Data is the new content of the message.
+	Data T
+	// ExpectedVersion is the version the message must be at for the update to succeed, typically the version
+	// of the message that was read before editing its data. If it is zero, the message is updated at any version.
+	ExpectedVersion int
+}
+
+// UpdateMessageDataOutput represents the result of the operation to update the payload of a message.
+// This struct uses the generic type T and contains information about the updated message.
+type UpdateMessageDataOutput[T any] struct {
+	// UpdatedMessage is a pointer to the Message type containing information about the updated message.
+	// The type T determines the format of the message content.
+	UpdatedMessage *Message[T]
+}
+
+// UpdateMessageData replaces the payload of a specific message, without rewriting the rest of the item as ReplaceMessage does.
+// Only the data, the update time and the version are written, with a conditional update, so that concurrent changes
+// to the state of the message are kept. With WithPayloadUpgrader, the payload schema version is written as well.
+// If the message does not exist, an IDNotFoundError is returned, and if it is not at ExpectedVersion,
+// a VersionConflictError is returned.
+func (c *ClientImpl[T]) UpdateMessageData(ctx context.Context, params *UpdateMessageDataInput[T]) (*UpdateMessageDataOutput[T], error) {
+	ctx, cancel := c.withDefaultTimeout(ctx, "UpdateMessageData")
+	defer cancel()
+	if params == nil {
+		params = &UpdateMessageDataInput[T]{}
+	}
+	if params.ID == "" {
+		return &UpdateMessageDataOutput[T]{}, &IDNotProvidedError{}
+	}
+	if err := c.validator.validate(ctx, "UpdateMessageData", params.ID, params.Data); err != nil {
+		return &UpdateMessageDataOutput[T]{}, err
+	}
+	condition := expression.AttributeExists(expression.Name(c.schema.PartitionKeyAttribute))
+	if params.ExpectedVersion > 0 {
+		condition = condition.And(expression.Name(c.schema.VersionAttribute).Equal(expression.Value(params.ExpectedVersion)))
+	}
+	var data any = params.Data
+	if rawPayload[T]() {
+		data = rawPayloadValue(params.Data)
+	}
+	builder := expression.NewBuilder().
+		WithUpdate(c.setPayloadSchemaVersion(expression.
+			Add(expression.Name(c.schema.VersionAttribute), expression.Value(1)).
+			Set(expression.Name(c.schema.DataAttribute), expression.Value(data)).
+			Set(expression.Name(c.schema.UpdatedAtAttribute), expression.Value(clock.FormatRFC3339Nano(c.clock.Now()))))).
+		WithCondition(condition)
+	expr, err := c.buildExpression(builder)
+	if err != nil {
+		return &UpdateMessageDataOutput[T]{}, BuildingExpressionError{Cause: err}
+	}
+	outcome, err := c.dynamoDB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		Key:                                 c.itemKey(params.ID),
+		TableName:                           aws.String(c.tableName),
+		ConditionExpression:                 expr.Condition(),
+		ExpressionAttributeNames:            expr.Names(),
+		ExpressionAttributeValues:           expr.Values(),
+		UpdateExpression:                    expr.Update(),
+		ReturnValues:                        types.ReturnValueAllNew,
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	})
+	if err != nil {
+		var cause *types.ConditionalCheckFailedException
+		if errors.As(err, &cause) {
+			if cause.Item == nil {
+				return &UpdateMessageDataOutput[T]{}, &IDNotFoundError{}
+			}
+			return &UpdateMessageDataOutput[T]{}, VersionConflictError{ID: params.ID, Version: params.ExpectedVersion}
+		}
+		return &UpdateMessageDataOutput[T]{}, handleDynamoDBError(err)
+	}
+	message := Message[T]{}
+	if err := c.unmarshalItem(outcome.Attributes, &message); err != nil {
+		return &UpdateMessageDataOutput[T]{}, UnmarshalingAttributeError{Cause: err}
 	}
-	return &ReplaceMessageOutput{}, c.put(ctx, params.Message)
+	return &UpdateMessageDataOutput[T]{
+		UpdatedMessage: &message,
+	}, nil
 }
 
 func (c *ClientImpl[T]) put(ctx context.Context, message *Message[T]) error {
-	item, err := c.marshalMap(message)
+	item, err := c.marshalItem(message)
 	if err != nil {
 		return MarshalingAttributeError{Cause: err}
 	}
@@ -933,11 +2320,7 @@ func (c *ClientImpl[T]) put(ctx context.Context, message *Message[T]) error {
 func (c *ClientImpl[T]) updateDynamoDBItem(ctx context.Context,
 	id string, expr *expression.Expression) (*Message[T], error) {
 	outcome, err := c.dynamoDB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
-		Key: map[string]types.AttributeValue{
-			"id": &types.AttributeValueMemberS{
-				Value: id,
-			},
-		},
+		Key:                       c.itemKey(id),
 		TableName:                 aws.String(c.tableName),
 		ConditionExpression:       expr.Condition(),
 		ExpressionAttributeNames:  expr.Names(),
@@ -949,17 +2332,125 @@ func (c *ClientImpl[T]) updateDynamoDBItem(ctx context.Context,
 		return nil, handleDynamoDBError(err)
 	}
 	message := Message[T]{}
-	err = c.unmarshalMap(outcome.Attributes, &message)
+	err = c.unmarshalItem(outcome.Attributes, &message)
 	if err != nil {
 		return nil, UnmarshalingAttributeError{Cause: err}
 	}
 	return &message, nil
 }
 
+func (c *ClientImpl[T]) handleCorruptMessage(item map[string]types.AttributeValue, cause error) error {
+	corrupt := CorruptMessageError{
+		ID:    c.itemID(item),
+		Cause: UnmarshalingAttributeError{Cause: cause},
+	}
+	if !c.skipCorruptMessages {
+		return corrupt
+	}
+	if c.onCorruptMessage != nil {
+		c.onCorruptMessage(corrupt)
+	}
+	return nil
+}
+
+func (c *ClientImpl[T]) itemID(item map[string]types.AttributeValue) string {
+	if id, ok := item[c.schema.IDAttribute].(*types.AttributeValueMemberS); ok {
+		return id.Value
+	}
+	return ""
+}
+
+func (c *ClientImpl[T]) itemKey(id string) map[string]types.AttributeValue {
+	return c.schema.key(id)
+}
+
+// tableKey returns the primary key of the item, which is where a scan resumes after the item.
+func (c *ClientImpl[T]) tableKey(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	key := map[string]types.AttributeValue{
+		c.schema.PartitionKeyAttribute: item[c.schema.PartitionKeyAttribute],
+	}
+	if c.schema.SortKeyAttribute != "" {
+		key[c.schema.SortKeyAttribute] = item[c.schema.SortKeyAttribute]
+	}
+	return key
+}
+
+// marshalItem marshals the message and renames its attributes according to the table schema.
+func (c *ClientImpl[T]) marshalItem(message *Message[T]) (map[string]types.AttributeValue, error) {
+	item, err := marshalMessage(message, c.marshalMap)
+	if err != nil {
+		return nil, err
+	}
+	item = renameAttributes(item, c.toStorage)
+	for k, v := range c.itemKey(message.ID) {
+		item[k] = v
+	}
+	return item, nil
+}
+
+// decoder is shared by every client, as attributevalue.UnmarshalMap would create an identical one for each item.
+// A Decoder only holds its options, so it is safe for concurrent use.
+var decoder = attributevalue.NewDecoder()
+
+// unmarshalMap unmarshals a map of attribute values like attributevalue.UnmarshalMap, with the shared decoder.
+func unmarshalMap(m map[string]types.AttributeValue, out interface{}) error {
+	return decoder.Decode(&types.AttributeValueMemberM{Value: m}, out)
+}
+
+// unmarshalItem restores the default attribute names of an item read from the table and unmarshals it.
+// The payload of the items written with an older version of its schema is converted by the PayloadUpgrader, if any.
+func (c *ClientImpl[T]) unmarshalItem(item map[string]types.AttributeValue, out *Message[T]) error {
+	item = renameAttributes(item, c.fromStorage)
+	if upgraded, err := c.upgradePayload(item, out); upgraded {
+		return err
+	}
+	return unmarshalMessage(item, out, c.unmarshalMap)
+}
+
+// setConsumerID adds the consumer ID of the message to an update expression, or removes it once it has been cleared.
+func (c *ClientImpl[T]) setConsumerID(update expression.UpdateBuilder, message *Message[T]) expression.UpdateBuilder {
+	if message.ConsumerID == "" {
+		return update.Remove(expression.Name(c.schema.ConsumerIDAttribute))
+	}
+	return update.Set(expression.Name(c.schema.ConsumerIDAttribute), expression.Value(message.ConsumerID))
+}
+
+func defaultConsumerID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
 func handleDynamoDBError(err error) error {
-	var cause *types.ConditionalCheckFailedException
-	if errors.As(err, &cause) {
-		return &ConditionalCheckFailedError{Cause: cause}
+	var (
+		conditionalCheckFailed *types.ConditionalCheckFailedException
+		throughputExceeded     *types.ProvisionedThroughputExceededException
+		requestLimitExceeded   *types.RequestLimitExceeded
+		resourceNotFound       *types.ResourceNotFoundException
+		apiErr                 smithy.APIError
+		operationTimeout       OperationTimeoutError
+		defaultTimeout         DefaultOperationTimeoutError
+	)
+	switch {
+	case errors.As(err, &operationTimeout):
+		return operationTimeout
+	case errors.As(err, &defaultTimeout):
+		return defaultTimeout
+	case errors.As(err, &conditionalCheckFailed):
+		return &ConditionalCheckFailedError{Cause: conditionalCheckFailed}
+	case errors.As(err, &throughputExceeded), errors.As(err, &requestLimitExceeded):
+		return ThrottledError{Cause: err}
+	case errors.As(err, &resourceNotFound):
+		return ResourceNotFoundError{Cause: err}
+	case errors.As(err, &apiErr):
+		switch apiErr.ErrorCode() {
+		case "ThrottlingException":
+			return ThrottledError{Cause: err}
+		case "ValidationException":
+			return ValidationError{Cause: err}
+		}
 	}
 	return DynamoDBAPIError{Cause: err}
 }
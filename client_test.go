@@ -4,19 +4,26 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
 	"github.com/google/uuid"
 	"github.com/upsidr/dynamotest"
 	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
 	"github.com/vvatanabe/dynamomq/internal/clock"
 	"github.com/vvatanabe/dynamomq/internal/constant"
 	"github.com/vvatanabe/dynamomq/internal/mock"
@@ -27,54 +34,26 @@ func SetupDynamoDB(t *testing.T, initialData ...*types.PutRequest) (tableName st
 	client, clean = dynamotest.NewDynamoDB(t)
 	tableName = constant.DefaultTableName + "-" + uuid.NewString()
 	dynamotest.PrepTable(t, client, dynamotest.InitialTableSetup{
-		Table: &dynamodb.CreateTableInput{
-			AttributeDefinitions: []types.AttributeDefinition{
-				{
-					AttributeName: aws.String("id"),
-					AttributeType: types.ScalarAttributeTypeS,
-				},
-				{
-					AttributeName: aws.String("queue_type"),
-					AttributeType: types.ScalarAttributeTypeS,
-				},
-				{
-					AttributeName: aws.String("sent_at"),
-					AttributeType: types.ScalarAttributeTypeS,
-				},
-			},
-			BillingMode:               types.BillingModePayPerRequest,
-			DeletionProtectionEnabled: aws.Bool(false),
-			GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
-				{
-					IndexName: aws.String("dynamo-mq-index-queue_type-sent_at"),
-					KeySchema: []types.KeySchemaElement{
-						{
-							AttributeName: aws.String("queue_type"),
-							KeyType:       types.KeyTypeHash,
-						},
-						{
-							AttributeName: aws.String("sent_at"),
-							KeyType:       types.KeyTypeRange,
-						},
-					},
-					Projection: &types.Projection{
-						ProjectionType: types.ProjectionTypeAll,
-					},
-				},
-			},
-			KeySchema: []types.KeySchemaElement{
-				{
-					AttributeName: aws.String("id"),
-					KeyType:       types.KeyTypeHash,
-				},
-			},
-			TableName: aws.String(tableName),
-		},
+		Table: dynamomq.NewCreateTableInput(&dynamomq.CreateQueueTableInput{
+			TableName: tableName,
+		}),
 		InitialData: initialData,
 	})
 	return
 }
 
+func SetupDynamoDBWithTableSchema(t *testing.T, schema dynamomq.TableSchema) (tableName string, client *dynamodb.Client, clean func()) {
+	client, clean = dynamotest.NewDynamoDB(t)
+	tableName = constant.DefaultTableName + "-" + uuid.NewString()
+	dynamotest.PrepTable(t, client, dynamotest.InitialTableSetup{
+		Table: dynamomq.NewCreateTableInput(&dynamomq.CreateQueueTableInput{
+			TableName:   tableName,
+			TableSchema: schema,
+		}),
+	})
+	return
+}
+
 func NewSetupFunc(initialData ...*types.PutRequest) func(t *testing.T) (string, *dynamodb.Client, func()) {
 	return func(t *testing.T) (string, *dynamodb.Client, func()) {
 		return SetupDynamoDB(t, initialData...)
@@ -88,6 +67,8 @@ type ClientTestCase[Args any, Want any] struct {
 	args     Args
 	want     Want
 	wantErr  error
+	// wantSentinel is the sentinel error that wantErr is expected to match, if any.
+	wantSentinel error
 }
 
 func TestDynamoMQClientShouldReturnError(t *testing.T) {
@@ -96,9 +77,10 @@ func TestDynamoMQClientShouldReturnError(t *testing.T) {
 		NewSetupFunc(newPutRequestWithReadyItem("A-101", clock.Now())), mock.Clock{}, false, nil, nil, nil)
 	defer cancel()
 	type testCase struct {
-		name      string
-		operation func() error
-		wantError error
+		name         string
+		operation    func() error
+		wantError    error
+		wantSentinel error
 	}
 	tests := []testCase{
 		{
@@ -107,7 +89,8 @@ func TestDynamoMQClientShouldReturnError(t *testing.T) {
 				_, err := client.SendMessage(context.Background(), nil)
 				return err
 			},
-			wantError: &dynamomq.IDNotProvidedError{},
+			wantError:    &dynamomq.IDNotProvidedError{},
+			wantSentinel: dynamomq.ErrIDNotProvided,
 		},
 		{
 			name: "SendMessage should return IDDuplicatedError",
@@ -118,7 +101,8 @@ func TestDynamoMQClientShouldReturnError(t *testing.T) {
 				})
 				return err
 			},
-			wantError: &dynamomq.IDDuplicatedError{},
+			wantError:    &dynamomq.IDDuplicatedError{},
+			wantSentinel: dynamomq.ErrIDDuplicated,
 		},
 		{
 			name: "ChangeMessageVisibility should return IDNotProvidedError",
@@ -126,7 +110,8 @@ func TestDynamoMQClientShouldReturnError(t *testing.T) {
 				_, err := client.ChangeMessageVisibility(context.Background(), nil)
 				return err
 			},
-			wantError: &dynamomq.IDNotProvidedError{},
+			wantError:    &dynamomq.IDNotProvidedError{},
+			wantSentinel: dynamomq.ErrIDNotProvided,
 		},
 		{
 			name: "ChangeMessageVisibility should return IDNotFoundError",
@@ -136,7 +121,8 @@ func TestDynamoMQClientShouldReturnError(t *testing.T) {
 				})
 				return err
 			},
-			wantError: &dynamomq.IDNotFoundError{},
+			wantError:    &dynamomq.IDNotFoundError{},
+			wantSentinel: dynamomq.ErrIDNotFound,
 		},
 		{
 			name: "MoveMessageToDLQ should return IDNotProvidedError",
@@ -144,7 +130,8 @@ func TestDynamoMQClientShouldReturnError(t *testing.T) {
 				_, err := client.MoveMessageToDLQ(context.Background(), nil)
 				return err
 			},
-			wantError: &dynamomq.IDNotProvidedError{},
+			wantError:    &dynamomq.IDNotProvidedError{},
+			wantSentinel: dynamomq.ErrIDNotProvided,
 		},
 		{
 			name: "MoveMessageToDLQ should return IDNotFoundError",
@@ -154,7 +141,8 @@ func TestDynamoMQClientShouldReturnError(t *testing.T) {
 				})
 				return err
 			},
-			wantError: &dynamomq.IDNotFoundError{},
+			wantError:    &dynamomq.IDNotFoundError{},
+			wantSentinel: dynamomq.ErrIDNotFound,
 		},
 		{
 			name: "RedriveMessage should return IDNotProvidedError",
@@ -162,7 +150,8 @@ func TestDynamoMQClientShouldReturnError(t *testing.T) {
 				_, err := client.RedriveMessage(context.Background(), nil)
 				return err
 			},
-			wantError: &dynamomq.IDNotProvidedError{},
+			wantError:    &dynamomq.IDNotProvidedError{},
+			wantSentinel: dynamomq.ErrIDNotProvided,
 		},
 		{
 			name: "RedriveMessage should return IDNotFoundError",
@@ -172,7 +161,8 @@ func TestDynamoMQClientShouldReturnError(t *testing.T) {
 				})
 				return err
 			},
-			wantError: &dynamomq.IDNotFoundError{},
+			wantError:    &dynamomq.IDNotFoundError{},
+			wantSentinel: dynamomq.ErrIDNotFound,
 		},
 		{
 			name: "DeleteMessage should return IDNotProvidedError",
@@ -180,7 +170,8 @@ func TestDynamoMQClientShouldReturnError(t *testing.T) {
 				_, err := client.DeleteMessage(context.Background(), nil)
 				return err
 			},
-			wantError: &dynamomq.IDNotProvidedError{},
+			wantError:    &dynamomq.IDNotProvidedError{},
+			wantSentinel: dynamomq.ErrIDNotProvided,
 		},
 		{
 			name: "GetMessage should return IDNotProvidedError",
@@ -188,7 +179,8 @@ func TestDynamoMQClientShouldReturnError(t *testing.T) {
 				_, err := client.GetMessage(context.Background(), nil)
 				return err
 			},
-			wantError: &dynamomq.IDNotProvidedError{},
+			wantError:    &dynamomq.IDNotProvidedError{},
+			wantSentinel: dynamomq.ErrIDNotProvided,
 		},
 		{
 			name: "ReplaceMessage should return IDNotProvidedError",
@@ -196,11 +188,14 @@ func TestDynamoMQClientShouldReturnError(t *testing.T) {
 				_, err := client.ReplaceMessage(context.Background(), nil)
 				return err
 			},
-			wantError: &dynamomq.IDNotProvidedError{},
+			wantError:    &dynamomq.IDNotProvidedError{},
+			wantSentinel: dynamomq.ErrIDNotProvided,
 		},
 	}
 	for _, tt := range tests {
-		test.AssertError(t, tt.operation(), tt.wantError, tt.name)
+		err := tt.operation()
+		test.AssertError(t, err, tt.wantError, tt.name)
+		test.AssertError(t, err, tt.wantSentinel, tt.name)
 	}
 }
 
@@ -259,8 +254,9 @@ func TestDynamoMQClientReceiveMessage(t *testing.T) {
 				newPutRequestWithProcessingItem("A-202", clock.Now()),
 				newPutRequestWithDLQItem("A-303", clock.Now()),
 			),
-			want:    nil,
-			wantErr: &dynamomq.EmptyQueueError{},
+			want:         nil,
+			wantErr:      &dynamomq.EmptyQueueError{},
+			wantSentinel: dynamomq.ErrEmptyQueue,
 		},
 		{
 			name:  "should return message when exists ready message",
@@ -281,9 +277,10 @@ func TestDynamoMQClientReceiveMessage(t *testing.T) {
 			},
 			want: func() *dynamomq.ReceiveMessageOutput[test.MessageData] {
 				m := NewTestMessageItemAsProcessing("B-202", test.DefaultTestDate)
-				MarkAsProcessing(m, test.DefaultTestDate.Add(10*time.Minute).Add(1*time.Second))
+				dynamomqtest.MarkAsProcessing(m, test.DefaultTestDate.Add(10*time.Minute).Add(1*time.Second))
 				m.Version = 2
 				m.ReceiveCount = 1
+				m.ConsumerID = testConsumerID
 				r := &dynamomq.ReceiveMessageOutput[test.MessageData]{
 					ReceivedMessage: m,
 				}
@@ -297,8 +294,12 @@ func TestDynamoMQClientReceiveMessage(t *testing.T) {
 			sdkClock: mock.Clock{
 				T: test.DefaultTestDate.Add(29 * time.Second),
 			},
-			want:    nil,
-			wantErr: &dynamomq.EmptyQueueError{},
+			want: nil,
+			wantErr: &dynamomq.EmptyQueueError{
+				NextVisibleAt: test.DefaultTestDate.Add(constant.DefaultVisibilityTimeout),
+				RetryAfter:    time.Second,
+			},
+			wantSentinel: dynamomq.ErrEmptyQueue,
 		},
 	}
 	runTestsParallel[any, *dynamomq.ReceiveMessageOutput[test.MessageData]](t, "ReceiveMessage()", tests,
@@ -343,6 +344,7 @@ func testDynamoMQClientReceiveMessageSequence(t *testing.T, useFIFO bool) {
 			VisibilityTimeout: constant.DefaultVisibilityTimeoutInSeconds,
 		})
 		test.AssertError(t, err, &dynamomq.EmptyQueueError{}, fmt.Sprintf("ReceiveMessage() [%d-3]", i))
+		test.AssertError(t, err, dynamomq.ErrEmptyQueue, fmt.Sprintf("ReceiveMessage() [%d-3]", i))
 
 		_, err = client.DeleteMessage(ctx, &dynamomq.DeleteMessageInput{
 			ID: result.ReceivedMessage.ID,
@@ -354,6 +356,7 @@ func testDynamoMQClientReceiveMessageSequence(t *testing.T, useFIFO bool) {
 		VisibilityTimeout: constant.DefaultVisibilityTimeoutInSeconds,
 	})
 	test.AssertError(t, err, &dynamomq.EmptyQueueError{}, "ReceiveMessage() [last]")
+	test.AssertError(t, err, dynamomq.ErrEmptyQueue, "ReceiveMessage() [last]")
 }
 
 func TestDynamoMQClientReceiveMessageUseFIFO(t *testing.T) {
@@ -366,6 +369,76 @@ func TestDynamoMQClientReceiveMessageNotUseFIFO(t *testing.T) {
 	testDynamoMQClientReceiveMessageSequence(t, false)
 }
 
+func TestDynamoMQClientRecordsConsumerID(t *testing.T) {
+	t.Parallel()
+	processing := NewTestMessageItemAsProcessing("A-101", test.DefaultTestDate)
+	processing.ConsumerID = "consumer-2"
+	item := dynamomqtest.MarshalMap(NewTestMessageItemAsReady("A-101", test.DefaultTestDate))
+	var updates []*dynamodb.UpdateItemInput
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+				return &dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{item}}, nil
+			},
+			GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+				return &dynamodb.GetItemOutput{Item: dynamomqtest.MarshalMap(processing)}, nil
+			},
+			UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+				updates = append(updates, params)
+				return &dynamodb.UpdateItemOutput{Attributes: item}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	ctx := context.Background()
+	if _, err := client.ReceiveMessage(ctx, nil); err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	if _, err := client.ChangeMessageVisibility(ctx, &dynamomq.ChangeMessageVisibilityInput{ID: "A-101"}); err != nil {
+		t.Fatalf("ChangeMessageVisibility() error = %v", err)
+	}
+	hostname, _ := os.Hostname()
+	received := updates[0]
+	if !containsValue(received.ExpressionAttributeNames, dynamomq.AttributeNameConsumerID) ||
+		!containsAttributeValue(received.ExpressionAttributeValues, &types.AttributeValueMemberS{Value: fmt.Sprintf("%s-%d", hostname, os.Getpid())}) {
+		t.Errorf("ReceiveMessage() update = %s %v, want the default consumer ID", *received.UpdateExpression, received.ExpressionAttributeValues)
+	}
+	if received.ConditionExpression == nil {
+		t.Error("ReceiveMessage() update is not conditioned on the version")
+	}
+	released := updates[1]
+	if !strings.Contains(*released.UpdateExpression, "REMOVE") || !containsValue(released.ExpressionAttributeNames, dynamomq.AttributeNameConsumerID) {
+		t.Errorf("ChangeMessageVisibility() update = %s, want the consumer ID removed", *released.UpdateExpression)
+	}
+}
+
+func TestDynamoMQClientGetQueueStatsReportsConsumerIDs(t *testing.T) {
+	t.Parallel()
+	owned := NewTestMessageItemAsProcessing("A-101", test.DefaultTestDate)
+	owned.ConsumerID = "consumer-1"
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+				return &dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{
+					dynamomqtest.MarshalMap(owned),
+					dynamomqtest.MarshalMap(NewTestMessageItemAsProcessing("A-102", test.DefaultTestDate)),
+					dynamomqtest.MarshalMap(NewTestMessageItemAsReady("A-103", test.DefaultTestDate)),
+				}}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	got, err := client.GetQueueStats(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("GetQueueStats() error = %v", err)
+	}
+	test.AssertDeepEqual(t, got.ConsumerIDsInQueueProcessing, map[string]string{"A-101": "consumer-1"}, "GetQueueStats() consumer IDs")
+}
+
 func TestDynamoMQClientChangeMessageVisibility(t *testing.T) {
 	t.Parallel()
 	type args struct {
@@ -408,7 +481,8 @@ func TestDynamoMQClientChangeMessageVisibility(t *testing.T) {
 func TestDynamoMQClientDeleteMessage(t *testing.T) {
 	t.Parallel()
 	type args struct {
-		id string
+		id     string
+		strict bool
 	}
 	tests := []ClientTestCase[args, *dynamomq.DeleteMessageOutput]{
 		{
@@ -427,15 +501,225 @@ func TestDynamoMQClientDeleteMessage(t *testing.T) {
 			},
 			want: &dynamomq.DeleteMessageOutput{},
 		},
+		{
+			name:  "should return IDNotFoundError when not existing id in strict mode",
+			setup: NewSetupFunc(newPutRequestWithReadyItem("A-101", clock.Now())),
+			args: args{
+				id:     "B-101",
+				strict: true,
+			},
+			want:         &dynamomq.DeleteMessageOutput{},
+			wantErr:      &dynamomq.IDNotFoundError{},
+			wantSentinel: dynamomq.ErrIDNotFound,
+		},
+		{
+			name:  "should succeed when id is found in strict mode",
+			setup: NewSetupFunc(newPutRequestWithReadyItem("A-101", clock.Now())),
+			args: args{
+				id:     "A-101",
+				strict: true,
+			},
+			want: &dynamomq.DeleteMessageOutput{},
+		},
 	}
 	runTestsParallel[args, *dynamomq.DeleteMessageOutput](t, "DeleteMessage()", tests,
 		func(client dynamomq.Client[test.MessageData], args args) (*dynamomq.DeleteMessageOutput, error) {
 			return client.DeleteMessage(context.Background(), &dynamomq.DeleteMessageInput{
-				ID: args.id,
+				ID:                   args.id,
+				StrictExistenceCheck: args.strict,
 			})
 		})
 }
 
+func TestDynamoMQClientCompositePrimaryKey(t *testing.T) {
+	t.Parallel()
+	schema := dynamomq.TableSchema{
+		PartitionKeyAttribute: "PK",
+		PartitionKeyTemplate:  "QUEUE#orders",
+		SortKeyAttribute:      "SK",
+		SortKeyTemplate:       "MSG#{id}",
+	}
+	ctx := context.Background()
+	client, clean := prepareTestClient(ctx, t, func(t *testing.T) (string, *dynamodb.Client, func()) {
+		return SetupDynamoDBWithTableSchema(t, schema)
+	}, mock.Clock{T: test.DefaultTestDate}, false, nil, nil, nil, dynamomq.WithTableSchema(schema))
+	defer clean()
+	for _, id := range []string{"A-101", "A-102"} {
+		if _, err := client.SendMessage(ctx, &dynamomq.SendMessageInput[test.MessageData]{
+			ID:   id,
+			Data: test.NewMessageData(id),
+		}); err != nil {
+			t.Fatalf("SendMessage() error = %v", err)
+		}
+	}
+	_, err := client.SendMessage(ctx, &dynamomq.SendMessageInput[test.MessageData]{ID: "A-101"})
+	if !errors.As(err, new(*dynamomq.IDDuplicatedError)) {
+		t.Errorf("SendMessage() error = %v, want IDDuplicatedError", err)
+	}
+	if !errors.Is(err, dynamomq.ErrIDDuplicated) {
+		t.Errorf("SendMessage() error = %v, want ErrIDDuplicated", err)
+	}
+	received, err := client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{})
+	if err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	id := received.ReceivedMessage.ID
+	if _, err := client.ChangeMessageVisibility(ctx, &dynamomq.ChangeMessageVisibilityInput{ID: id}); err != nil {
+		t.Fatalf("ChangeMessageVisibility() error = %v", err)
+	}
+	if _, err := client.MoveMessageToDLQ(ctx, &dynamomq.MoveMessageToDLQInput{ID: id}); err != nil {
+		t.Fatalf("MoveMessageToDLQ() error = %v", err)
+	}
+	if _, err := client.DeleteMessage(ctx, &dynamomq.DeleteMessageInput{ID: id, StrictExistenceCheck: true}); err != nil {
+		t.Fatalf("DeleteMessage() error = %v", err)
+	}
+	got, err := client.GetMessage(ctx, &dynamomq.GetMessageInput{ID: id})
+	if err != nil {
+		t.Fatalf("GetMessage() error = %v", err)
+	}
+	if got.Message != nil {
+		t.Errorf("GetMessage() = %v, want nil after delete", got.Message)
+	}
+	list, err := client.ListMessages(ctx, &dynamomq.ListMessagesInput{})
+	if err != nil {
+		t.Fatalf("ListMessages() error = %v", err)
+	}
+	if len(list.Messages) != 1 {
+		t.Errorf("ListMessages() = %d messages, want 1", len(list.Messages))
+	}
+}
+
+func TestDynamoMQClientClassifiesDynamoDBErrors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		err           error
+		wantType      error
+		wantRetryable bool
+	}{
+		{
+			name:          "provisioned throughput exceeded",
+			err:           &types.ProvisionedThroughputExceededException{},
+			wantType:      dynamomq.ThrottledError{},
+			wantRetryable: true,
+		},
+		{
+			name:          "request limit exceeded",
+			err:           &types.RequestLimitExceeded{},
+			wantType:      dynamomq.ThrottledError{},
+			wantRetryable: true,
+		},
+		{
+			name:          "throttling exception",
+			err:           &smithy.GenericAPIError{Code: "ThrottlingException"},
+			wantType:      dynamomq.ThrottledError{},
+			wantRetryable: true,
+		},
+		{
+			name:          "resource not found",
+			err:           &types.ResourceNotFoundException{},
+			wantType:      dynamomq.ResourceNotFoundError{},
+			wantRetryable: false,
+		},
+		{
+			name:          "validation exception",
+			err:           &smithy.GenericAPIError{Code: "ValidationException"},
+			wantType:      dynamomq.ValidationError{},
+			wantRetryable: false,
+		},
+		{
+			name:          "other error",
+			err:           test.ErrTest,
+			wantType:      dynamomq.DynamoDBAPIError{},
+			wantRetryable: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					DeleteItemFunc: func(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+						return nil, tt.err
+					},
+				}))
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			_, err = client.DeleteMessage(context.Background(), &dynamomq.DeleteMessageInput{ID: "A-101"})
+			if reflect.TypeOf(err) != reflect.TypeOf(tt.wantType) {
+				t.Errorf("DeleteMessage() error = %T, want %T", err, tt.wantType)
+			}
+			var retryable dynamomq.RetryableError
+			if !errors.As(err, &retryable) {
+				t.Fatalf("DeleteMessage() error = %v, want RetryableError", err)
+			}
+			if retryable.Retryable() != tt.wantRetryable {
+				t.Errorf("Retryable() = %v, want %v", retryable.Retryable(), tt.wantRetryable)
+			}
+			if tt.wantType != (dynamomq.DynamoDBAPIError{}) && !errors.Is(err, tt.err) {
+				t.Errorf("DeleteMessage() error = %v, does not wrap %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDynamoMQClientDeleteMessageStrictExistenceCheck(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		strict        bool
+		wantCondition bool
+		wantErr       error
+		wantSentinel  error
+	}{
+		{
+			name:          "lenient mode does not set a condition",
+			strict:        false,
+			wantCondition: false,
+			wantErr:       &dynamomq.ConditionalCheckFailedError{},
+		},
+		{
+			name:          "strict mode sets a condition and reports a missing id",
+			strict:        true,
+			wantCondition: true,
+			wantErr:       &dynamomq.IDNotFoundError{},
+			wantSentinel:  dynamomq.ErrIDNotFound,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var input *dynamodb.DeleteItemInput
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					DeleteItemFunc: func(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+						input = params
+						return nil, &types.ConditionalCheckFailedException{}
+					},
+				}))
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			_, err = client.DeleteMessage(context.Background(), &dynamomq.DeleteMessageInput{
+				ID:                   "A-101",
+				StrictExistenceCheck: tt.strict,
+			})
+			if reflect.TypeOf(err) != reflect.TypeOf(tt.wantErr) {
+				t.Errorf("DeleteMessage() error = %T, want %T", err, tt.wantErr)
+			}
+			if tt.wantSentinel != nil {
+				test.AssertError(t, err, tt.wantSentinel, "DeleteMessage()")
+			}
+			if got := input.ConditionExpression != nil; got != tt.wantCondition {
+				t.Errorf("DeleteMessage() condition set = %v, want %v", got, tt.wantCondition)
+			}
+		})
+	}
+}
+
 func TestDynamoMQClientMoveMessageToDLQ(t *testing.T) {
 	t.Parallel()
 	tests := []ClientTestCase[*dynamomq.MoveMessageToDLQInput, *dynamomq.MoveMessageToDLQOutput[test.MessageData]]{
@@ -465,7 +749,7 @@ func TestDynamoMQClientMoveMessageToDLQ(t *testing.T) {
 			},
 			want: func() *dynamomq.MoveMessageToDLQOutput[test.MessageData] {
 				m := NewTestMessageItemAsReady("A-101", test.DefaultTestDate)
-				MarkAsMovedToDLQ(m, test.DefaultTestDate.Add(10*time.Second))
+				dynamomqtest.MarkAsMovedToDLQ(m, test.DefaultTestDate.Add(10*time.Second))
 				m.Version = 2
 				r := &dynamomq.MoveMessageToDLQOutput[test.MessageData]{
 					MovedMessage: m,
@@ -484,7 +768,8 @@ func TestDynamoMQClientMoveMessageToDLQ(t *testing.T) {
 func TestDynamoMQClientRedriveMessage(t *testing.T) {
 	t.Parallel()
 	type args struct {
-		id string
+		id           string
+		delaySeconds int
 	}
 	tests := []ClientTestCase[args, *dynamomq.RedriveMessageOutput[test.MessageData]]{
 		{
@@ -499,7 +784,27 @@ func TestDynamoMQClientRedriveMessage(t *testing.T) {
 			want: &dynamomq.RedriveMessageOutput[test.MessageData]{
 				RedroveMessage: func() *dynamomq.Message[test.MessageData] {
 					m := NewTestMessageItemAsDLQ("A-101", test.DefaultTestDate)
-					MarkAsRestoredFromDLQ(m, test.DefaultTestDate.Add(10*time.Second))
+					dynamomqtest.MarkAsRestoredFromDLQ(m, test.DefaultTestDate.Add(10*time.Second))
+					m.Version = 2
+					return m
+				}(),
+			},
+		},
+		{
+			name:  "should delay the redriven message when delay seconds is set",
+			setup: NewSetupFunc(newPutRequestWithDLQItem("A-101", test.DefaultTestDate)),
+			sdkClock: mock.Clock{
+				T: test.DefaultTestDate.Add(10 * time.Second),
+			},
+			args: args{
+				id:           "A-101",
+				delaySeconds: 60,
+			},
+			want: &dynamomq.RedriveMessageOutput[test.MessageData]{
+				RedroveMessage: func() *dynamomq.Message[test.MessageData] {
+					m := NewTestMessageItemAsDLQ("A-101", test.DefaultTestDate)
+					dynamomqtest.MarkAsRestoredFromDLQ(m, test.DefaultTestDate.Add(10*time.Second))
+					m.SentAt = clock.FormatRFC3339Nano(test.DefaultTestDate.Add(70 * time.Second))
 					m.Version = 2
 					return m
 				}(),
@@ -519,6 +824,7 @@ func TestDynamoMQClientRedriveMessage(t *testing.T) {
 				Msg:       "can only redrive messages from DLQ",
 				Operation: "mark as restored from DLQ",
 				Current:   dynamomq.StatusReady,
+				Requested: dynamomq.StatusReady,
 			},
 		},
 		{
@@ -526,8 +832,8 @@ func TestDynamoMQClientRedriveMessage(t *testing.T) {
 			setup: NewSetupFunc(&types.PutRequest{
 				Item: func() map[string]types.AttributeValue {
 					msg := NewTestMessageItemAsDLQ("A-101", test.DefaultTestDate)
-					MarkAsProcessing(msg, test.DefaultTestDate)
-					return marshalMapUnsafe(msg)
+					dynamomqtest.MarkAsProcessing(msg, test.DefaultTestDate)
+					return dynamomqtest.MarshalMap(msg)
 				}(),
 			}),
 			sdkClock: mock.Clock{
@@ -540,14 +846,16 @@ func TestDynamoMQClientRedriveMessage(t *testing.T) {
 			wantErr: dynamomq.InvalidStateTransitionError{
 				Msg:       "can only redrive messages from READY",
 				Operation: "mark as restored from DLQ",
-				Current:   dynamomq.StatusProcessing,
+				Current:   dynamomq.StatusDLQProcessing,
+				Requested: dynamomq.StatusReady,
 			},
 		},
 	}
 	runTestsParallel[args, *dynamomq.RedriveMessageOutput[test.MessageData]](t, "RedriveMessage()", tests,
 		func(client dynamomq.Client[test.MessageData], args args) (*dynamomq.RedriveMessageOutput[test.MessageData], error) {
 			return client.RedriveMessage(context.Background(), &dynamomq.RedriveMessageInput{
-				ID: args.id,
+				ID:           args.id,
+				DelaySeconds: args.delaySeconds,
 			})
 		})
 }
@@ -611,8 +919,117 @@ func TestDynamoMQClientGetQueueStats(t *testing.T) {
 		})
 }
 
+func TestDynamoMQClientGetQueueStatsCountByStatus(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	// seed returns the items of several pages of the queue, with the messages of each kind interleaved.
+	seed := func(kinds map[string]int, newMessage func(kind, id string) *dynamomq.Message[test.MessageData]) []map[string]types.AttributeValue {
+		var items []map[string]types.AttributeValue
+		for i := 0; len(kinds) > 0; i++ {
+			for _, kind := range []string{"ready", "processing", "held", "dlq", "dlq-processing"} {
+				if kinds[kind] == 0 {
+					delete(kinds, kind)
+					continue
+				}
+				kinds[kind]--
+				items = append(items, dynamomqtest.MarshalMap(newMessage(kind, fmt.Sprintf("%s-%d", kind, i))))
+			}
+		}
+		return items
+	}
+	newMessage := func(kind, id string) *dynamomq.Message[test.MessageData] {
+		switch kind {
+		case "processing":
+			return NewTestMessageItemAsProcessing(id, now)
+		case "held":
+			return newHeldTestMessage(id)
+		case "dlq":
+			return NewTestMessageItemAsDLQ(id, test.DefaultTestDate)
+		case "dlq-processing":
+			m := NewTestMessageItemAsDLQ(id, test.DefaultTestDate)
+			dynamomqtest.MarkAsProcessing(m, now)
+			return m
+		default:
+			return NewTestMessageItemAsReady(id, test.DefaultTestDate)
+		}
+	}
+	tests := []struct {
+		name  string
+		kinds map[string]int
+		want  map[dynamomq.Status]int
+	}{
+		{
+			name:  "should count the messages of the queue by status across pages",
+			kinds: map[string]int{"ready": 120, "processing": 70, "held": 15},
+			want:  map[dynamomq.Status]int{dynamomq.StatusReady: 135, dynamomq.StatusProcessing: 70},
+		},
+		{
+			name:  "should count the messages of the DLQ as DLQ and DLQ_PROCESSING",
+			kinds: map[string]int{"dlq": 3, "dlq-processing": 2},
+			want:  map[dynamomq.Status]int{dynamomq.StatusDLQ: 3, dynamomq.StatusDLQProcessing: 2},
+		},
+		{
+			name: "should return no counts when the queue is empty",
+			want: map[dynamomq.Status]int{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			queue := &pagedQueue{items: seed(tt.kinds, newMessage)}
+			client := newPagedQueueClient(t, queue, now)
+			got, err := client.GetQueueStats(context.Background(), &dynamomq.GetQueueStatsInput{CountByStatus: true})
+			if err != nil {
+				t.Fatalf("GetQueueStats() error = %v", err)
+			}
+			test.AssertDeepEqual(t, got.TotalMessagesByStatus, tt.want, "GetQueueStats() TotalMessagesByStatus")
+			var total int
+			for _, n := range got.TotalMessagesByStatus {
+				total += n
+			}
+			if total != got.TotalMessagesInQueue {
+				t.Errorf("GetQueueStats() counts by status add up to %d, want TotalMessagesInQueue %d", total, got.TotalMessagesInQueue)
+			}
+			if n := got.TotalMessagesByStatus[dynamomq.StatusProcessing] + got.TotalMessagesByStatus[dynamomq.StatusDLQProcessing]; n != got.TotalMessagesInQueueProcessing {
+				t.Errorf("GetQueueStats() counts processing %d, want TotalMessagesInQueueProcessing %d", n, got.TotalMessagesInQueueProcessing)
+			}
+			if queue.fetched != len(queue.items) {
+				t.Errorf("GetQueueStats() read %d items, want a single pass over %d", queue.fetched, len(queue.items))
+			}
+		})
+	}
+}
+
+func TestDynamoMQClientGetQueueStatsCountByStatusCached(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	queue := &pagedQueue{items: []map[string]types.AttributeValue{
+		dynamomqtest.MarshalMap(NewTestMessageItemAsReady("A-101", test.DefaultTestDate)),
+		dynamomqtest.MarshalMap(NewTestMessageItemAsProcessing("A-102", now)),
+	}}
+	client := newPagedQueueClient(t, queue, now, dynamomq.WithStatsCache(time.Minute))
+	got, err := client.GetQueueStats(context.Background(), &dynamomq.GetQueueStatsInput{})
+	if err != nil {
+		t.Fatalf("GetQueueStats() error = %v", err)
+	}
+	if got.TotalMessagesByStatus != nil {
+		t.Errorf("GetQueueStats() TotalMessagesByStatus = %v, want nil without CountByStatus", got.TotalMessagesByStatus)
+	}
+	got, err = client.GetQueueStats(context.Background(), &dynamomq.GetQueueStatsInput{CountByStatus: true})
+	if err != nil {
+		t.Fatalf("GetQueueStats() error = %v", err)
+	}
+	test.AssertDeepEqual(t, got.TotalMessagesByStatus,
+		map[dynamomq.Status]int{dynamomq.StatusReady: 1, dynamomq.StatusProcessing: 1}, "GetQueueStats() TotalMessagesByStatus")
+	if len(queue.limits) != 1 {
+		t.Errorf("Query() calls = %d, want the counts read from the cache", len(queue.limits))
+	}
+}
+
 func TestDynamoMQClientGetDLQStats(t *testing.T) {
 	t.Parallel()
+	now := test.DefaultTestDate
 	tests := []ClientTestCase[any, *dynamomq.GetDLQStatsOutput]{
 		{
 			name: "should return empty items when no items in DLQ",
@@ -624,21 +1041,56 @@ func TestDynamoMQClientGetDLQStats(t *testing.T) {
 			want: &dynamomq.GetDLQStatsOutput{
 				First100IDsInQueue: []string{},
 				TotalMessagesInDLQ: 0,
+				Entries:            []dynamomq.DLQStatsEntry{},
 			},
 		},
 		{
 			name: "should return three DLQ items when items in DLQ",
 			setup: NewSetupFunc(
-				newPutRequestWithReadyItem("A-101", clock.Now().Add(time.Second)),
-				newPutRequestWithReadyItem("B-202", clock.Now().Add(time.Second)),
-				newPutRequestWithProcessingItem("C-303", clock.Now().Add(2*time.Second)),
-				newPutRequestWithDLQItem("D-404", clock.Now().Add(3*time.Second)),
-				newPutRequestWithDLQItem("E-505", clock.Now().Add(4*time.Second)),
-				newPutRequestWithDLQItem("F-606", clock.Now().Add(5*time.Second)),
+				newPutRequestWithReadyItem("A-101", now.Add(time.Second)),
+				newPutRequestWithReadyItem("B-202", now.Add(time.Second)),
+				newPutRequestWithProcessingItem("C-303", now.Add(2*time.Second)),
+				newPutRequestWithDLQItem("D-404", now.Add(3*time.Second)),
+				newPutRequestWithDLQItem("E-505", now.Add(4*time.Second)),
+				newPutRequestWithDLQItem("F-606", now.Add(5*time.Second)),
 			),
+			sdkClock: mock.Clock{T: now.Add(time.Minute)},
 			want: &dynamomq.GetDLQStatsOutput{
 				First100IDsInQueue: []string{"D-404", "E-505", "F-606"},
 				TotalMessagesInDLQ: 3,
+				Entries: []dynamomq.DLQStatsEntry{
+					newDLQStatsEntry("D-404", now.Add(3*time.Second)),
+					newDLQStatsEntry("E-505", now.Add(4*time.Second)),
+					newDLQStatsEntry("F-606", now.Add(5*time.Second)),
+				},
+			},
+		},
+		{
+			name: "should return the receive metadata of DLQ items being received",
+			setup: NewSetupFunc(
+				newPutRequestWithDLQItem("D-404", now),
+				func() *types.PutRequest {
+					m := NewTestMessageItemAsDLQ("E-505", now.Add(time.Second))
+					dynamomqtest.MarkAsProcessing(m, now.Add(2*time.Second))
+					m.ReceiveCount = 2
+					return dynamomqtest.NewPutRequest(m)
+				}(),
+			),
+			sdkClock: mock.Clock{T: now.Add(3 * time.Second)},
+			want: &dynamomq.GetDLQStatsOutput{
+				First100IDsInQueue: []string{"D-404", "E-505"},
+				TotalMessagesInDLQ: 2,
+				Entries: []dynamomq.DLQStatsEntry{
+					newDLQStatsEntry("D-404", now),
+					{
+						ID:           "E-505",
+						Status:       dynamomq.StatusProcessing,
+						UpdatedAt:    clock.FormatRFC3339Nano(now.Add(2 * time.Second)),
+						MovedToDLQAt: clock.FormatRFC3339Nano(now.Add(time.Second)),
+						ReceiveCount: 2,
+						ReceivedAt:   clock.FormatRFC3339Nano(now.Add(2 * time.Second)),
+					},
+				},
 			},
 		},
 	}
@@ -685,6 +1137,77 @@ func TestDynamoMQClientGetMessage(t *testing.T) {
 		})
 }
 
+func TestDynamoMQClientGetMessageOmitData(t *testing.T) {
+	t.Parallel()
+	message := NewTestMessageItemAsReady("A-101", test.DefaultTestDate)
+	tests := []struct {
+		name            string
+		omitData        bool
+		wantProjected   bool
+		wantDataOmitted bool
+	}{
+		{
+			name:            "should project every attribute but the payload",
+			omitData:        true,
+			wantProjected:   true,
+			wantDataOmitted: true,
+		},
+		{
+			name: "should read the whole message without OmitData",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got *dynamodb.GetItemInput
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+						got = params
+						item := dynamomqtest.MarshalMap(message)
+						if params.ProjectionExpression != nil {
+							delete(item, dynamomq.AttributeNameData)
+						}
+						return &dynamodb.GetItemOutput{Item: item}, nil
+					},
+				}))
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			out, err := client.GetMessage(context.Background(), &dynamomq.GetMessageInput{ID: "A-101", OmitData: tt.omitData})
+			if err != nil {
+				t.Fatalf("GetMessage() error = %v", err)
+			}
+			if (got.ProjectionExpression != nil) != tt.wantProjected {
+				t.Fatalf("GetItem() projection expression = %v, want a projection %v", aws.ToString(got.ProjectionExpression), tt.wantProjected)
+			}
+			projected := make(map[string]bool)
+			for _, name := range got.ExpressionAttributeNames {
+				projected[name] = true
+			}
+			if projected[dynamomq.AttributeNameData] {
+				t.Errorf("GetItem() projects %s", dynamomq.AttributeNameData)
+			}
+			if tt.wantProjected {
+				for _, name := range []string{dynamomq.AttributeNameID, dynamomq.AttributeNameQueueType, dynamomq.AttributeNameVersion} {
+					if !projected[name] {
+						t.Errorf("GetItem() does not project %s", name)
+					}
+				}
+			}
+			want := *message
+			if tt.wantDataOmitted {
+				want.Data = test.MessageData{}
+			}
+			test.AssertDeepEqual(t, out, &dynamomq.GetMessageOutput[test.MessageData]{
+				Message:     &want,
+				DataOmitted: tt.wantDataOmitted,
+			}, "GetMessage()")
+		})
+	}
+}
+
 func TestDynamoMQClientReplaceMessage(t *testing.T) {
 	t.Parallel()
 	type args struct {
@@ -782,6 +1305,49 @@ func TestDynamoMQClientListMessages(t *testing.T) {
 		})
 }
 
+func TestDynamoMQClientListMessagesOmitData(t *testing.T) {
+	t.Parallel()
+	message := NewTestMessageItemAsReady("A-101", test.DefaultTestDate)
+	item := dynamomqtest.MarshalMap(message)
+	delete(item, dynamomq.AttributeNameData)
+	var scanned *dynamodb.ScanInput
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			ScanFunc: func(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+				scanned = params
+				return &dynamodb.ScanOutput{Items: []map[string]types.AttributeValue{item}}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	out, err := client.ListMessages(context.Background(), &dynamomq.ListMessagesInput{OmitData: true})
+	if err != nil {
+		t.Fatalf("ListMessages() error = %v", err)
+	}
+	if scanned.ProjectionExpression == nil {
+		t.Fatal("Scan() has no projection expression")
+	}
+	projected := make(map[string]bool)
+	for _, name := range scanned.ExpressionAttributeNames {
+		projected[name] = true
+	}
+	if projected[dynamomq.AttributeNameData] {
+		t.Errorf("Scan() projects %s", dynamomq.AttributeNameData)
+	}
+	for _, name := range []string{dynamomq.AttributeNameID, dynamomq.AttributeNameQueueType, dynamomq.AttributeNameVersion} {
+		if !projected[name] {
+			t.Errorf("Scan() does not project %s", name)
+		}
+	}
+	want := *message
+	want.Data = test.MessageData{}
+	test.AssertDeepEqual(t, out, &dynamomq.ListMessagesOutput[test.MessageData]{
+		Messages:    []*dynamomq.Message[test.MessageData]{&want},
+		DataOmitted: true,
+	}, "ListMessages()")
+}
+
 func runTestsParallel[Args any, Want any](t *testing.T, prefix string,
 	tests []ClientTestCase[Args, Want], operation func(dynamomq.Client[test.MessageData], Args) (Want, error)) {
 	for _, tt := range tests {
@@ -793,6 +1359,9 @@ func runTestsParallel[Args any, Want any](t *testing.T, prefix string,
 			result, err := operation(client, tt.args)
 			if tt.wantErr != nil {
 				test.AssertError(t, err, tt.wantErr, prefix)
+				if tt.wantSentinel != nil {
+					test.AssertError(t, err, tt.wantSentinel, prefix)
+				}
 				return
 			}
 			test.AssertDeepEqual(t, result, tt.want, prefix)
@@ -807,6 +1376,7 @@ func prepareTestClient(ctx context.Context, t *testing.T,
 	unmarshalMap func(m map[string]types.AttributeValue, out interface{}) error,
 	marshalMap func(in interface{}) (map[string]types.AttributeValue, error),
 	unmarshalListOfMaps func(l []map[string]types.AttributeValue, out interface{}) error,
+	opts ...func(*dynamomq.ClientOptions),
 ) (dynamomq.Client[test.MessageData], func()) {
 	t.Helper()
 	tableName, raw, clean := setupTable(t)
@@ -818,10 +1388,12 @@ func prepareTestClient(ctx context.Context, t *testing.T,
 		mock.WithClock(sdkClock),
 		dynamomq.WithUseFIFO(useFIFO),
 		dynamomq.WithAWSRetryMaxAttempts(constant.DefaultRetryMaxAttempts),
+		dynamomq.WithConsumerID(testConsumerID),
 		WithUnmarshalMap(unmarshalMap),
 		WithMarshalMap(marshalMap),
 		WithUnmarshalListOfMaps(unmarshalListOfMaps),
 	}
+	optFns = append(optFns, opts...)
 	cfg, err := config.LoadDefaultConfig(ctx)
 	if err != nil {
 		t.Fatalf("failed to load aws config: %s\n", err)
@@ -835,21 +1407,46 @@ func prepareTestClient(ctx context.Context, t *testing.T,
 	return client, clean
 }
 
-func newPutRequestWithReadyItem(id string, now time.Time) *types.PutRequest {
-	return &types.PutRequest{
-		Item: marshalMapUnsafe(NewTestMessageItemAsReady(id, now)),
+// newTestClient returns a client of the default table calling api, which is a mock.DynamoDB or the client of
+// a table set up with SetupDynamoDB.
+func newTestClient[T any](t *testing.T, api dynamomq.DynamoDBAPI, opts ...func(*dynamomq.ClientOptions)) dynamomq.Client[T] {
+	t.Helper()
+	client, err := dynamomq.NewFromConfig[T](aws.Config{},
+		append([]func(*dynamomq.ClientOptions){dynamomq.WithDynamoDBAPI(api)}, opts...)...)
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
 	}
+	return client
+}
+
+func newPutRequestWithReadyItem(id string, now time.Time) *types.PutRequest {
+	return dynamomqtest.NewPutRequest(NewTestMessageItemAsReady(id, now))
 }
 
 func newPutRequestWithProcessingItem(id string, now time.Time) *types.PutRequest {
-	return &types.PutRequest{
-		Item: marshalMapUnsafe(NewTestMessageItemAsProcessing(id, now)),
-	}
+	return dynamomqtest.NewPutRequest(NewTestMessageItemAsProcessing(id, now))
 }
 
 func newPutRequestWithDLQItem(id string, now time.Time) *types.PutRequest {
+	return dynamomqtest.NewPutRequest(NewTestMessageItemAsDLQ(id, now))
+}
+
+// newDLQStatsEntry returns the entry of GetDLQStats for a message moved to the DLQ at movedAt and not received since.
+func newDLQStatsEntry(id string, movedAt time.Time) dynamomq.DLQStatsEntry {
+	return dynamomq.DLQStatsEntry{
+		ID:           id,
+		Status:       dynamomq.StatusReady,
+		UpdatedAt:    clock.FormatRFC3339Nano(movedAt),
+		MovedToDLQAt: clock.FormatRFC3339Nano(movedAt),
+	}
+}
+
+func newPutRequestWithCorruptItem(id string, queueType dynamomq.QueueType, now time.Time) *types.PutRequest {
+	item := dynamomqtest.MarshalMap(NewTestMessageItemAsReady(id, now))
+	item["queue_type"] = &types.AttributeValueMemberS{Value: string(queueType)}
+	item["receive_count"] = &types.AttributeValueMemberS{Value: "not a number"}
 	return &types.PutRequest{
-		Item: marshalMapUnsafe(NewTestMessageItemAsDLQ(id, now)),
+		Item: item,
 	}
 }
 
@@ -866,23 +1463,168 @@ func generatePutRequests(messages []*dynamomq.Message[test.MessageData]) []*type
 	var puts []*types.PutRequest
 	for _, message := range messages {
 		puts = append(puts, &types.PutRequest{
-			Item: marshalMapUnsafe(message),
+			Item: dynamomqtest.MarshalMap(message),
 		})
 	}
 	return puts
 }
 
-func marshalMapUnsafe[T any](m *dynamomq.Message[T]) map[string]types.AttributeValue {
-	item, _ := marshalMap(m)
-	return item
+func marshalMap[T any](m *dynamomq.Message[T]) (map[string]types.AttributeValue, error) {
+	return m.MarshalMap()
 }
 
-func marshalMap[T any](m *dynamomq.Message[T]) (map[string]types.AttributeValue, error) {
-	item, err := attributevalue.MarshalMap(m)
-	if err != nil {
-		return nil, dynamomq.MarshalingAttributeError{Cause: err}
+func TestDynamoMQClientCorruptMessages(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate
+	setupFunc := NewSetupFunc(
+		newPutRequestWithCorruptItem("A-000", dynamomq.QueueTypeStandard, now),
+		newPutRequestWithReadyItem("A-101", now.Add(time.Second)),
+		newPutRequestWithCorruptItem("B-000", dynamomq.QueueTypeDLQ, now),
+		newPutRequestWithDLQItem("B-101", now.Add(time.Second)),
+	)
+	t.Run("should return CorruptMessageError by default", func(t *testing.T) {
+		t.Parallel()
+		client, clean := prepareTestClient(context.Background(), t, setupFunc, mock.Clock{T: now}, false, nil, nil, nil)
+		defer clean()
+		operations := map[string]func() error{
+			"ReceiveMessage": func() error {
+				_, err := client.ReceiveMessage(context.Background(), nil)
+				return err
+			},
+			"GetQueueStats": func() error {
+				_, err := client.GetQueueStats(context.Background(), nil)
+				return err
+			},
+			"GetDLQStats": func() error {
+				_, err := client.GetDLQStats(context.Background(), nil)
+				return err
+			},
+		}
+		for name, operation := range operations {
+			corrupt, ok := assertErrorType[dynamomq.CorruptMessageError](operation())
+			if !ok {
+				t.Errorf("%s() error type = %T, want CorruptMessageError", name, corrupt)
+				continue
+			}
+			if corrupt.ID != "A-000" && corrupt.ID != "B-000" {
+				t.Errorf("%s() corrupt ID = %s", name, corrupt.ID)
+			}
+			if _, ok := assertErrorType[dynamomq.UnmarshalingAttributeError](corrupt); !ok {
+				t.Errorf("%s() error does not wrap UnmarshalingAttributeError", name)
+			}
+		}
+	})
+	t.Run("should skip corrupt items when enabled", func(t *testing.T) {
+		t.Parallel()
+		var (
+			mu      sync.Mutex
+			skipped []string
+		)
+		client, clean := prepareTestClient(context.Background(), t, setupFunc, mock.Clock{T: now.Add(time.Minute)}, false, nil, nil, nil,
+			dynamomq.WithSkipCorruptMessages(true),
+			dynamomq.WithOnCorruptMessage(func(err dynamomq.CorruptMessageError) {
+				mu.Lock()
+				defer mu.Unlock()
+				skipped = append(skipped, err.ID)
+			}))
+		defer clean()
+		stats, err := client.GetQueueStats(context.Background(), nil)
+		test.AssertError(t, err, nil, "GetQueueStats()")
+		test.AssertDeepEqual(t, stats, &dynamomq.GetQueueStatsOutput{
+			First100IDsInQueue:           []string{"A-101"},
+			First100IDsInQueueProcessing: []string{},
+			TotalMessagesInQueue:         1,
+			TotalMessagesInQueueReady:    1,
+			TotalCorruptMessagesSkipped:  1,
+		}, "GetQueueStats()")
+		dlqStats, err := client.GetDLQStats(context.Background(), nil)
+		test.AssertError(t, err, nil, "GetDLQStats()")
+		test.AssertDeepEqual(t, dlqStats, &dynamomq.GetDLQStatsOutput{
+			First100IDsInQueue:          []string{"B-101"},
+			TotalMessagesInDLQ:          1,
+			TotalCorruptMessagesSkipped: 1,
+			Entries:                     []dynamomq.DLQStatsEntry{newDLQStatsEntry("B-101", now.Add(time.Second))},
+		}, "GetDLQStats()")
+		received, err := client.ReceiveMessage(context.Background(), nil)
+		test.AssertError(t, err, nil, "ReceiveMessage()")
+		if received.ReceivedMessage.ID != "A-101" {
+			t.Errorf("ReceiveMessage() id = %s, want %s", received.ReceivedMessage.ID, "A-101")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		test.AssertDeepEqual(t, skipped, []string{"A-000", "B-000", "A-000"}, "OnCorruptMessage")
+	})
+}
+
+func TestDynamoMQClientStopsPaginationWhenContextCanceled(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		name      string
+		queueType dynamomq.QueueType
+		operation func(context.Context, dynamomq.Client[test.MessageData]) error
+	}
+	tests := []testCase{
+		{
+			name:      "ReceiveMessage",
+			queueType: dynamomq.QueueTypeStandard,
+			operation: func(ctx context.Context, client dynamomq.Client[test.MessageData]) error {
+				_, err := client.ReceiveMessage(ctx, nil)
+				return err
+			},
+		},
+		{
+			name:      "GetQueueStats",
+			queueType: dynamomq.QueueTypeStandard,
+			operation: func(ctx context.Context, client dynamomq.Client[test.MessageData]) error {
+				_, err := client.GetQueueStats(ctx, nil)
+				return err
+			},
+		},
+		{
+			name:      "GetDLQStats",
+			queueType: dynamomq.QueueTypeDLQ,
+			operation: func(ctx context.Context, client dynamomq.Client[test.MessageData]) error {
+				_, err := client.GetDLQStats(ctx, nil)
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			var queries atomic.Int32
+			item := NewTestMessageItemAsProcessing("A-101", test.DefaultTestDate)
+			item.QueueType = tt.queueType
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+				mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+						queries.Add(1)
+						cancel()
+						return &dynamodb.QueryOutput{
+							Items:            []map[string]types.AttributeValue{dynamomqtest.MarshalMap(item)},
+							LastEvaluatedKey: map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "A-101"}},
+						}, nil
+					},
+				}))
+			if err != nil {
+				t.Fatalf("failed to create DynamoMQ client: %s\n", err)
+			}
+			err = tt.operation(ctx, client)
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("%s() error = %v, want %v", tt.name, err, context.Canceled)
+			}
+			if _, ok := assertErrorType[dynamomq.OperationCanceledError](err); !ok {
+				t.Errorf("%s() error type = %T, want OperationCanceledError", tt.name, err)
+			}
+			if got := queries.Load(); got != 1 {
+				t.Errorf("%s() Query calls = %d, want 1", tt.name, got)
+			}
+		})
 	}
-	return item, nil
 }
 
 func TestNewFromConfig(t *testing.T) {
@@ -892,6 +1634,70 @@ func TestNewFromConfig(t *testing.T) {
 	}
 }
 
+func TestNewFromConfigBaseEndpoint(t *testing.T) {
+	// Each server stands for a DynamoDB endpoint, and records the operations it receives.
+	newEndpoint := func(t *testing.T) (*httptest.Server, *[]string) {
+		var targets []string
+		var mu sync.Mutex
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			targets = append(targets, r.Header.Get("X-Amz-Target"))
+			mu.Unlock()
+			w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+			_, _ = w.Write([]byte("{}"))
+		}))
+		t.Cleanup(server.Close)
+		return server, &targets
+	}
+	cfg := aws.Config{
+		Region: "us-east-1",
+		Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "dummy", SecretAccessKey: "dummy"}, nil
+		}),
+	}
+	tests := []struct {
+		name      string
+		option    bool
+		env       bool
+		wantOnOpt int
+		wantOnEnv int
+	}{
+		{name: "should send requests to the endpoint of the option", option: true, wantOnOpt: 1},
+		{name: "should send requests to the endpoint of the environment variable", env: true, wantOnEnv: 1},
+		{name: "should prefer the option to the environment variable", option: true, env: true, wantOnOpt: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			optEndpoint, onOpt := newEndpoint(t)
+			envEndpoint, onEnv := newEndpoint(t)
+			t.Setenv("AWS_ENDPOINT_URL_DYNAMODB", "")
+			if tt.env {
+				t.Setenv("AWS_ENDPOINT_URL_DYNAMODB", envEndpoint.URL)
+			}
+			var opts []func(*dynamomq.ClientOptions)
+			if tt.option {
+				opts = append(opts, dynamomq.WithAWSBaseEndpoint(optEndpoint.URL))
+			}
+			client, err := dynamomq.NewFromConfig[any](cfg, opts...)
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			if _, err := client.GetMessage(context.Background(), &dynamomq.GetMessageInput{ID: "A-101"}); err != nil {
+				t.Fatalf("GetMessage() error = %v", err)
+			}
+			if len(*onOpt) != tt.wantOnOpt || len(*onEnv) != tt.wantOnEnv {
+				t.Fatalf("requests = %v on the option endpoint and %v on the environment endpoint, want %d and %d",
+					*onOpt, *onEnv, tt.wantOnOpt, tt.wantOnEnv)
+			}
+			for _, target := range append(*onOpt, *onEnv...) {
+				if target != "DynamoDB_20120810.GetItem" {
+					t.Errorf("X-Amz-Target = %s, want DynamoDB_20120810.GetItem", target)
+				}
+			}
+		})
+	}
+}
+
 func TestTestDynamoMQClientReturnUnmarshalingAttributeError(t *testing.T) {
 	t.Parallel()
 	setupFunc := NewSetupFunc(
@@ -1162,3 +1968,334 @@ func WithBuildExpression(f func(b expression.Builder) (expression.Expression, er
 		}
 	}
 }
+
+func TestDynamoMQClientResetReceiveCount(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	dlq := NewTestMessageItemAsDLQ("B-101", test.DefaultTestDate)
+	dlq.ReceiveCount = 3
+	client, clean := prepareTestClient(context.Background(), t, NewSetupFunc(
+		&types.PutRequest{Item: dynamomqtest.MarshalMap(dlq)},
+	), mock.Clock{T: now}, false, nil, nil, nil)
+	defer clean()
+	ctx := context.Background()
+	got, err := client.ResetReceiveCount(ctx, &dynamomq.ResetReceiveCountInput{ID: "B-101"})
+	if err != nil {
+		t.Fatalf("ResetReceiveCount() error = %v", err)
+	}
+	want := NewTestMessageItemAsDLQ("B-101", test.DefaultTestDate)
+	want.Version = 2
+	want.UpdatedAt = clock.FormatRFC3339Nano(now)
+	test.AssertDeepEqual(t, got.ResetMessage, want, "ResetReceiveCount()")
+	_, err = client.ResetReceiveCount(ctx, &dynamomq.ResetReceiveCountInput{ID: "B-999"})
+	test.AssertError(t, err, &dynamomq.IDNotFoundError{}, "ResetReceiveCount()")
+	test.AssertError(t, err, dynamomq.ErrIDNotFound, "ResetReceiveCount()")
+}
+
+func TestDynamoMQClientResetReceiveCountConflict(t *testing.T) {
+	t.Parallel()
+	message := NewTestMessageItemAsDLQ("B-101", test.DefaultTestDate)
+	message.ReceiveCount = 3
+	var condition *string
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+				return &dynamodb.GetItemOutput{Item: dynamomqtest.MarshalMap(message)}, nil
+			},
+			UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+				condition = params.ConditionExpression
+				// Another client updated the message after it was read.
+				return nil, &types.ConditionalCheckFailedException{}
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	_, err = client.ResetReceiveCount(context.Background(), &dynamomq.ResetReceiveCountInput{ID: "B-101"})
+	if !errors.As(err, new(*dynamomq.ConditionalCheckFailedError)) {
+		t.Errorf("ResetReceiveCount() error = %v, want ConditionalCheckFailedError", err)
+	}
+	if errors.Is(err, dynamomq.ErrVersionConflict) {
+		t.Errorf("ResetReceiveCount() error = %v, want no match of ErrVersionConflict", err)
+	}
+	if condition == nil {
+		t.Error("ResetReceiveCount() update is not conditioned on the version")
+	}
+}
+
+func TestDynamoMQClientRedriveWithResetReceiveCount(t *testing.T) {
+	t.Parallel()
+	const maximumReceives = 3
+	ctx := context.Background()
+	dlq := NewTestMessageItemAsDLQ("B-101", test.DefaultTestDate)
+	dlq.ReceiveCount = maximumReceives
+	client, clean := prepareTestClient(ctx, t, NewSetupFunc(
+		&types.PutRequest{Item: dynamomqtest.MarshalMap(dlq)},
+	), clock.RealClock{}, false, nil, nil, nil)
+	defer clean()
+	redriven, err := client.RedriveMessage(ctx, &dynamomq.RedriveMessageInput{ID: "B-101", ResetReceiveCount: true})
+	if err != nil {
+		t.Fatalf("RedriveMessage() error = %v", err)
+	}
+	if redriven.RedroveMessage.ReceiveCount != 0 {
+		t.Fatalf("RedriveMessage() receive count = %d, want 0", redriven.RedroveMessage.ReceiveCount)
+	}
+	var failures atomic.Int32
+	processor := dynamomq.MessageProcessorFunc[test.MessageData](func(m *dynamomq.Message[test.MessageData]) error {
+		failures.Add(1)
+		return test.ErrTest
+	})
+	consumer := dynamomq.NewConsumer[test.MessageData](client, processor,
+		dynamomq.WithPollingInterval(10*time.Millisecond),
+		dynamomq.WithRetryInterval(0),
+		dynamomq.WithMaximumReceives(maximumReceives))
+	go func() {
+		_ = consumer.StartConsuming()
+	}()
+	defer func() {
+		_ = consumer.Shutdown(ctx)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		got, err := client.GetMessage(ctx, &dynamomq.GetMessageInput{ID: "B-101"})
+		if err != nil {
+			t.Fatalf("GetMessage() error = %v", err)
+		}
+		if got.Message.IsDLQ() {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// The message survives maximumReceives-1 failures and is moved back to the DLQ on the last one.
+	if got := failures.Load(); got != maximumReceives {
+		t.Errorf("failures before moving to DLQ = %d, want %d", got, maximumReceives)
+	}
+}
+
+func TestDynamoMQClientUpdateMessageData(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	client, clean := prepareTestClient(context.Background(), t, NewSetupFunc(
+		newPutRequestWithDLQItem("B-101", test.DefaultTestDate),
+	), mock.Clock{T: now}, false, nil, nil, nil)
+	defer clean()
+	ctx := context.Background()
+	data := test.NewMessageData("B-101")
+	data.Data1 = "fixed"
+	got, err := client.UpdateMessageData(ctx, &dynamomq.UpdateMessageDataInput[test.MessageData]{
+		ID:              "B-101",
+		Data:            data,
+		ExpectedVersion: 1,
+	})
+	if err != nil {
+		t.Fatalf("UpdateMessageData() error = %v", err)
+	}
+	want := NewTestMessageItemAsDLQ("B-101", test.DefaultTestDate)
+	want.Data = data
+	want.Version = 2
+	want.UpdatedAt = clock.FormatRFC3339Nano(now)
+	test.AssertDeepEqual(t, got.UpdatedMessage, want, "UpdateMessageData()")
+
+	_, err = client.UpdateMessageData(ctx, &dynamomq.UpdateMessageDataInput[test.MessageData]{
+		ID:              "B-101",
+		Data:            test.NewMessageData("B-101"),
+		ExpectedVersion: 1,
+	})
+	test.AssertError(t, err, dynamomq.VersionConflictError{ID: "B-101", Version: 1}, "UpdateMessageData() with a stale version")
+	_, err = client.UpdateMessageData(ctx, &dynamomq.UpdateMessageDataInput[test.MessageData]{ID: "B-999"})
+	test.AssertError(t, err, &dynamomq.IDNotFoundError{}, "UpdateMessageData() of a missing message")
+	test.AssertError(t, err, dynamomq.ErrIDNotFound, "UpdateMessageData() of a missing message")
+	retrieved, err := client.GetMessage(ctx, &dynamomq.GetMessageInput{ID: "B-101"})
+	if err != nil {
+		t.Fatalf("GetMessage() error = %v", err)
+	}
+	test.AssertDeepEqual(t, retrieved.Message, want, "GetMessage() after a conflict")
+}
+
+func TestDynamoMQClientUpdateMessageDataConflict(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		item         map[string]types.AttributeValue
+		wantErr      error
+		wantSentinel error
+	}{
+		{
+			name:         "should return VersionConflictError when the message was changed",
+			item:         dynamomqtest.MarshalMap(NewTestMessageItemAsDLQ("B-101", test.DefaultTestDate)),
+			wantErr:      dynamomq.VersionConflictError{ID: "B-101", Version: 2},
+			wantSentinel: dynamomq.ErrVersionConflict,
+		},
+		{
+			name:         "should return IDNotFoundError when the message does not exist",
+			wantErr:      &dynamomq.IDNotFoundError{},
+			wantSentinel: dynamomq.ErrIDNotFound,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var input *dynamodb.UpdateItemInput
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+				mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+						input = params
+						return nil, &types.ConditionalCheckFailedException{Item: tt.item}
+					},
+				}))
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			_, err = client.UpdateMessageData(context.Background(), &dynamomq.UpdateMessageDataInput[test.MessageData]{
+				ID:              "B-101",
+				Data:            test.NewMessageData("B-101"),
+				ExpectedVersion: 2,
+			})
+			test.AssertError(t, err, tt.wantErr, "UpdateMessageData()")
+			test.AssertError(t, err, tt.wantSentinel, "UpdateMessageData()")
+			if !strings.Contains(*input.UpdateExpression, "SET") || len(input.ExpressionAttributeNames) != 4 {
+				t.Errorf("UpdateItem() expression = %s %v, want only data, updated_at and version", *input.UpdateExpression, input.ExpressionAttributeNames)
+			}
+		})
+	}
+}
+
+func TestDynamoMQClientExpectedVersion(t *testing.T) {
+	t.Parallel()
+	client, clean := prepareTestClient(context.Background(), t, NewSetupFunc(
+		newPutRequestWithDLQItem("B-101", test.DefaultTestDate),
+	), mock.Clock{T: test.DefaultTestDate.Add(time.Minute)}, false, nil, nil, nil)
+	defer clean()
+	ctx := context.Background()
+	_, err := client.RedriveMessage(ctx, &dynamomq.RedriveMessageInput{ID: "B-101", ExpectedVersion: 2})
+	test.AssertError(t, err, dynamomq.VersionConflictError{ID: "B-101", Version: 2}, "RedriveMessage() with a stale version")
+	redriven, err := client.RedriveMessage(ctx, &dynamomq.RedriveMessageInput{ID: "B-101", ExpectedVersion: 1})
+	if err != nil {
+		t.Fatalf("RedriveMessage() error = %v", err)
+	}
+	if redriven.RedroveMessage.Version != 2 {
+		t.Errorf("RedriveMessage() version = %d, want 2", redriven.RedroveMessage.Version)
+	}
+	_, err = client.DeleteMessage(ctx, &dynamomq.DeleteMessageInput{ID: "B-101", ExpectedVersion: 1})
+	test.AssertError(t, err, dynamomq.VersionConflictError{ID: "B-101", Version: 1}, "DeleteMessage() with a stale version")
+	retrieved, err := client.GetMessage(ctx, &dynamomq.GetMessageInput{ID: "B-101"})
+	if err != nil || retrieved.Message == nil {
+		t.Fatalf("GetMessage() = %v, %v, want the message kept after a conflict", retrieved, err)
+	}
+	if _, err := client.DeleteMessage(ctx, &dynamomq.DeleteMessageInput{ID: "B-101", ExpectedVersion: 2}); err != nil {
+		t.Fatalf("DeleteMessage() error = %v", err)
+	}
+	_, err = client.DeleteMessage(ctx, &dynamomq.DeleteMessageInput{ID: "B-101", ExpectedVersion: 2})
+	test.AssertError(t, err, &dynamomq.IDNotFoundError{}, "DeleteMessage() of a deleted message")
+}
+
+func TestDynamoMQClientDeleteMessageExpectedVersionConflict(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		item    map[string]types.AttributeValue
+		wantErr error
+	}{
+		{
+			name:    "should return VersionConflictError when the message was changed",
+			item:    dynamomqtest.MarshalMap(NewTestMessageItemAsReady("A-101", test.DefaultTestDate)),
+			wantErr: dynamomq.VersionConflictError{ID: "A-101", Version: 2},
+		},
+		{
+			name:    "should return IDNotFoundError when the message does not exist",
+			wantErr: &dynamomq.IDNotFoundError{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var input *dynamodb.DeleteItemInput
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					DeleteItemFunc: func(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+						input = params
+						return nil, &types.ConditionalCheckFailedException{Item: tt.item}
+					},
+				}))
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			_, err = client.DeleteMessage(context.Background(), &dynamomq.DeleteMessageInput{ID: "A-101", ExpectedVersion: 2})
+			test.AssertError(t, err, tt.wantErr, "DeleteMessage()")
+			if !containsValue(input.ExpressionAttributeNames, dynamomq.AttributeNameVersion) {
+				t.Errorf("DeleteItem() names = %v, want a condition on the version", input.ExpressionAttributeNames)
+			}
+		})
+	}
+}
+
+func TestDynamoMQClientMoveMessageToDLQAndRedriveMessageShouldReturnUpdatedMessage(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	moved := NewTestMessageItemAsDLQ("A-101", now)
+	moved.Version = 2
+	redriven := NewTestMessageItemAsReady("A-101", now)
+	redriven.Version = 3
+	type testCase struct {
+		name    string
+		stored  *dynamomq.Message[test.MessageData]
+		updated *dynamomq.Message[test.MessageData]
+		call    func(client dynamomq.Client[test.MessageData]) (*dynamomq.Message[test.MessageData], error)
+	}
+	tests := []testCase{
+		{
+			name:    "MoveMessageToDLQ",
+			stored:  NewTestMessageItemAsProcessing("A-101", test.DefaultTestDate),
+			updated: moved,
+			call: func(client dynamomq.Client[test.MessageData]) (*dynamomq.Message[test.MessageData], error) {
+				out, err := client.MoveMessageToDLQ(context.Background(), &dynamomq.MoveMessageToDLQInput{ID: "A-101"})
+				return out.MovedMessage, err
+			},
+		},
+		{
+			name:    "RedriveMessage",
+			stored:  NewTestMessageItemAsDLQ("A-101", test.DefaultTestDate),
+			updated: redriven,
+			call: func(client dynamomq.Client[test.MessageData]) (*dynamomq.Message[test.MessageData], error) {
+				out, err := client.RedriveMessage(context.Background(), &dynamomq.RedriveMessageInput{ID: "A-101"})
+				return out.RedroveMessage, err
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var reads atomic.Int32
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+				mock.WithClock(mock.Clock{T: now}),
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+						reads.Add(1)
+						return &dynamodb.GetItemOutput{Item: dynamomqtest.MarshalMap(tt.stored)}, nil
+					},
+					UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+						if params.ReturnValues != types.ReturnValueAllNew {
+							t.Errorf("UpdateItem() ReturnValues = %v, want %v", params.ReturnValues, types.ReturnValueAllNew)
+						}
+						return &dynamodb.UpdateItemOutput{Attributes: dynamomqtest.MarshalMap(tt.updated)}, nil
+					},
+				}))
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			got, err := tt.call(client)
+			if err != nil {
+				t.Fatalf("%s() error = %v", tt.name, err)
+			}
+			test.AssertDeepEqual(t, got, tt.updated, tt.name+"()")
+			if n := reads.Load(); n != 1 {
+				t.Errorf("%s() read the message %d times, want 1", tt.name, n)
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+// Package clienttest provides a conformance suite for implementations of dynamomq.Client.
+//
+// Wrappers around the DynamoMQ client, such as caching or instrumenting clients, can run the suite to make sure
+// they keep the semantics consumers rely on: the errors returned, the progression of versions and receive counts,
+// the blocking of FIFO queues, the expiry of the visibility timeout and the transitions to and from the DLQ.
+// The suite is also run against the DynamoMQ client itself, so the contract cannot drift silently.
+package clienttest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+// MessageData is the type of the data of the messages sent by the suite.
+type MessageData = test.MessageData
+
+// Factory returns the client under test, working on an empty queue. The options must be applied to the
+// DynamoMQ client wrapped by the implementation: the suite uses them to control the clock and to enable FIFO.
+type Factory func(t *testing.T, opts ...func(*dynamomq.ClientOptions)) dynamomq.Client[MessageData]
+
+// RunConformanceTests runs the conformance suite against the clients returned by the factory.
+// Every subtest gets its own client.
+func RunConformanceTests(t *testing.T, factory Factory) {
+	t.Helper()
+	tests := []struct {
+		name string
+		fifo bool
+		run  func(t *testing.T, s *suite)
+	}{
+		{name: "Errors", run: testErrors},
+		{name: "VersionAndReceiveCount", run: testVersionAndReceiveCount},
+		{name: "VisibilityExpiry", run: testVisibilityExpiry},
+		{name: "FIFOBlocking", fifo: true, run: testFIFOBlocking},
+		{name: "DLQTransitions", run: testDLQTransitions},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &Clock{now: test.DefaultTestDate}
+			opts := []func(*dynamomq.ClientOptions){
+				func(o *dynamomq.ClientOptions) {
+					o.Clock = clock
+				},
+				dynamomq.WithUseFIFO(tt.fifo),
+			}
+			tt.run(t, &suite{
+				ctx:    context.Background(),
+				client: factory(t, opts...),
+				clock:  clock,
+			})
+		})
+	}
+}
+
+// Clock is the clock the suite advances to expire visibility timeouts and to order the messages it sends.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// Now returns the current time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+const visibilityTimeout = 30
+
+type suite struct {
+	ctx    context.Context
+	client dynamomq.Client[MessageData]
+	clock  *Clock
+}
+
+func (s *suite) send(t *testing.T, id string) *dynamomq.Message[MessageData] {
+	t.Helper()
+	out, err := s.client.SendMessage(s.ctx, &dynamomq.SendMessageInput[MessageData]{
+		ID:   id,
+		Data: test.NewMessageData(id),
+	})
+	if err != nil {
+		t.Fatalf("SendMessage(%s) error = %v", id, err)
+	}
+	// The next message is sent later, so that the order of the queue does not depend on the order of the IDs.
+	s.clock.Advance(time.Second)
+	return out.SentMessage
+}
+
+func (s *suite) receive(t *testing.T) *dynamomq.Message[MessageData] {
+	t.Helper()
+	out, err := s.client.ReceiveMessage(s.ctx, &dynamomq.ReceiveMessageInput{VisibilityTimeout: visibilityTimeout})
+	if err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	if out == nil || out.ReceivedMessage == nil {
+		t.Fatal("ReceiveMessage() returned no message")
+	}
+	return out.ReceivedMessage
+}
+
+func (s *suite) receiveEmpty(t *testing.T) {
+	t.Helper()
+	out, err := s.client.ReceiveMessage(s.ctx, &dynamomq.ReceiveMessageInput{VisibilityTimeout: visibilityTimeout})
+	if out == nil && err == nil {
+		// The client was created with WithEmptyReceiveAsNil.
+		return
+	}
+	if !errors.Is(err, dynamomq.ErrEmptyQueue) {
+		t.Fatalf("ReceiveMessage() error = %v, want %v", err, dynamomq.ErrEmptyQueue)
+	}
+	if out == nil || out.ReceivedMessage != nil {
+		t.Fatalf("ReceiveMessage() output = %+v, want an output without a message", out)
+	}
+}
+
+func (s *suite) get(t *testing.T, id string) *dynamomq.Message[MessageData] {
+	t.Helper()
+	out, err := s.client.GetMessage(s.ctx, &dynamomq.GetMessageInput{ID: id})
+	if err != nil {
+		t.Fatalf("GetMessage(%s) error = %v", id, err)
+	}
+	return out.Message
+}
+
+func assertMessage(t *testing.T, op string, got *dynamomq.Message[MessageData],
+	id string, version, receiveCount int, queueType dynamomq.QueueType, status dynamomq.Status, now time.Time) {
+	t.Helper()
+	if got == nil {
+		t.Fatalf("%s message = nil, want %s", op, id)
+	}
+	if got.ID != id {
+		t.Errorf("%s id = %s, want %s", op, got.ID, id)
+	}
+	if got.Version != version {
+		t.Errorf("%s version = %d, want %d", op, got.Version, version)
+	}
+	if got.ReceiveCount != receiveCount {
+		t.Errorf("%s receive count = %d, want %d", op, got.ReceiveCount, receiveCount)
+	}
+	if got.QueueType != queueType {
+		t.Errorf("%s queue type = %s, want %s", op, got.QueueType, queueType)
+	}
+	if s := got.GetStatus(now); s != status {
+		t.Errorf("%s status = %s, want %s", op, s, status)
+	}
+	test.AssertDeepEqual(t, got.Data, test.NewMessageData(id), op+" data")
+}
+
+func testErrors(t *testing.T, s *suite) {
+	s.receiveEmpty(t)
+
+	_, err := s.client.SendMessage(s.ctx, &dynamomq.SendMessageInput[MessageData]{})
+	test.AssertError(t, err, dynamomq.ErrIDNotProvided, "SendMessage() without ID")
+	s.send(t, "A-101")
+	_, err = s.client.SendMessage(s.ctx, &dynamomq.SendMessageInput[MessageData]{ID: "A-101"})
+	test.AssertError(t, err, dynamomq.ErrIDDuplicated, "SendMessage() with a duplicated ID")
+
+	if got := s.get(t, "B-101"); got != nil {
+		t.Errorf("GetMessage() of an unknown ID = %+v, want nil", got)
+	}
+	_, err = s.client.ChangeMessageVisibility(s.ctx, &dynamomq.ChangeMessageVisibilityInput{ID: "B-101"})
+	test.AssertError(t, err, dynamomq.ErrIDNotFound, "ChangeMessageVisibility() of an unknown ID")
+	_, err = s.client.MoveMessageToDLQ(s.ctx, &dynamomq.MoveMessageToDLQInput{ID: "B-101"})
+	test.AssertError(t, err, dynamomq.ErrIDNotFound, "MoveMessageToDLQ() of an unknown ID")
+	_, err = s.client.RedriveMessage(s.ctx, &dynamomq.RedriveMessageInput{ID: "B-101"})
+	test.AssertError(t, err, dynamomq.ErrIDNotFound, "RedriveMessage() of an unknown ID")
+	_, err = s.client.RedriveMessage(s.ctx, &dynamomq.RedriveMessageInput{ID: "A-101"})
+	var invalid dynamomq.InvalidStateTransitionError
+	if !errors.As(err, &invalid) {
+		t.Errorf("RedriveMessage() of a message not in the DLQ error = %v, want %T", err, invalid)
+	}
+
+	_, err = s.client.DeleteMessage(s.ctx, &dynamomq.DeleteMessageInput{})
+	test.AssertError(t, err, dynamomq.ErrIDNotProvided, "DeleteMessage() without ID")
+	if _, err = s.client.DeleteMessage(s.ctx, &dynamomq.DeleteMessageInput{ID: "B-101"}); err != nil {
+		t.Errorf("DeleteMessage() of an unknown ID error = %v, want nil", err)
+	}
+	_, err = s.client.DeleteMessage(s.ctx, &dynamomq.DeleteMessageInput{ID: "B-101", StrictExistenceCheck: true})
+	test.AssertError(t, err, dynamomq.ErrIDNotFound, "DeleteMessage() of an unknown ID with StrictExistenceCheck")
+}
+
+func testVersionAndReceiveCount(t *testing.T, s *suite) {
+	sent := s.send(t, "A-101")
+	assertMessage(t, "SendMessage()", sent, "A-101", 1, 0, dynamomq.QueueTypeStandard, dynamomq.StatusReady, s.clock.Now())
+
+	received := s.receive(t)
+	assertMessage(t, "ReceiveMessage()", received, "A-101", 2, 1, dynamomq.QueueTypeStandard, dynamomq.StatusProcessing, s.clock.Now())
+	assertMessage(t, "GetMessage()", s.get(t, "A-101"), "A-101", 2, 1, dynamomq.QueueTypeStandard, dynamomq.StatusProcessing, s.clock.Now())
+
+	changed, err := s.client.ChangeMessageVisibility(s.ctx, &dynamomq.ChangeMessageVisibilityInput{ID: "A-101"})
+	if err != nil {
+		t.Fatalf("ChangeMessageVisibility() error = %v", err)
+	}
+	// A message stays invisible until the time it is made visible at has passed.
+	s.clock.Advance(time.Nanosecond)
+	assertMessage(t, "ChangeMessageVisibility()", changed.ChangedMessage, "A-101", 3, 1, dynamomq.QueueTypeStandard, dynamomq.StatusReady, s.clock.Now())
+
+	received = s.receive(t)
+	assertMessage(t, "ReceiveMessage()", received, "A-101", 4, 2, dynamomq.QueueTypeStandard, dynamomq.StatusProcessing, s.clock.Now())
+
+	if _, err = s.client.DeleteMessage(s.ctx, &dynamomq.DeleteMessageInput{ID: "A-101"}); err != nil {
+		t.Fatalf("DeleteMessage() error = %v", err)
+	}
+	if got := s.get(t, "A-101"); got != nil {
+		t.Errorf("GetMessage() of a deleted message = %+v, want nil", got)
+	}
+	s.receiveEmpty(t)
+}
+
+func testVisibilityExpiry(t *testing.T, s *suite) {
+	s.send(t, "A-101")
+	s.receive(t)
+	s.receiveEmpty(t)
+
+	s.clock.Advance(visibilityTimeout * time.Second)
+	s.receiveEmpty(t)
+
+	s.clock.Advance(time.Nanosecond)
+	received := s.receive(t)
+	assertMessage(t, "ReceiveMessage() after the visibility timeout", received,
+		"A-101", 3, 2, dynamomq.QueueTypeStandard, dynamomq.StatusProcessing, s.clock.Now())
+}
+
+func testFIFOBlocking(t *testing.T, s *suite) {
+	s.send(t, "A-101")
+	s.send(t, "A-102")
+
+	if received := s.receive(t); received.ID != "A-101" {
+		t.Fatalf("ReceiveMessage() id = %s, want A-101", received.ID)
+	}
+	s.receiveEmpty(t)
+
+	if _, err := s.client.DeleteMessage(s.ctx, &dynamomq.DeleteMessageInput{ID: "A-101"}); err != nil {
+		t.Fatalf("DeleteMessage() error = %v", err)
+	}
+	if received := s.receive(t); received.ID != "A-102" {
+		t.Fatalf("ReceiveMessage() id = %s, want A-102", received.ID)
+	}
+}
+
+func testDLQTransitions(t *testing.T, s *suite) {
+	s.send(t, "A-101")
+	s.receive(t)
+
+	moved, err := s.client.MoveMessageToDLQ(s.ctx, &dynamomq.MoveMessageToDLQInput{ID: "A-101"})
+	if err != nil {
+		t.Fatalf("MoveMessageToDLQ() error = %v", err)
+	}
+	assertMessage(t, "MoveMessageToDLQ()", moved.MovedMessage, "A-101", 3, 0, dynamomq.QueueTypeDLQ, dynamomq.StatusReady, s.clock.Now())
+	s.receiveEmpty(t)
+
+	again, err := s.client.MoveMessageToDLQ(s.ctx, &dynamomq.MoveMessageToDLQInput{ID: "A-101"})
+	if err != nil {
+		t.Fatalf("MoveMessageToDLQ() of a message in the DLQ error = %v", err)
+	}
+	assertMessage(t, "MoveMessageToDLQ() of a message in the DLQ", again.MovedMessage,
+		"A-101", 3, 0, dynamomq.QueueTypeDLQ, dynamomq.StatusReady, s.clock.Now())
+
+	stats, err := s.client.GetDLQStats(s.ctx, &dynamomq.GetDLQStatsInput{})
+	if err != nil {
+		t.Fatalf("GetDLQStats() error = %v", err)
+	}
+	test.AssertDeepEqual(t, stats.First100IDsInQueue, []string{"A-101"}, "GetDLQStats() IDs")
+
+	redriven, err := s.client.RedriveMessage(s.ctx, &dynamomq.RedriveMessageInput{ID: "A-101"})
+	if err != nil {
+		t.Fatalf("RedriveMessage() error = %v", err)
+	}
+	assertMessage(t, "RedriveMessage()", redriven.RedroveMessage, "A-101", 4, 0, dynamomq.QueueTypeStandard, dynamomq.StatusReady, s.clock.Now())
+
+	received := s.receive(t)
+	assertMessage(t, "ReceiveMessage() after RedriveMessage()", received,
+		"A-101", 5, 1, dynamomq.QueueTypeStandard, dynamomq.StatusProcessing, s.clock.Now())
+}
@@ -0,0 +1,18 @@
+package dynamomq_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/clienttest"
+)
+
+func TestDynamoMQClientConformance(t *testing.T) {
+	t.Parallel()
+	clienttest.RunConformanceTests(t, func(t *testing.T, opts ...func(*dynamomq.ClientOptions)) dynamomq.Client[clienttest.MessageData] {
+		client, clean := prepareTestClient(context.Background(), t, NewSetupFunc(), nil, false, nil, nil, nil, opts...)
+		t.Cleanup(clean)
+		return client
+	})
+}
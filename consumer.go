@@ -9,7 +9,9 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/vvatanabe/dynamomq/internal/clock"
 	"github.com/vvatanabe/dynamomq/internal/constant"
+	"github.com/vvatanabe/dynamomq/retry"
 )
 
 const (
@@ -30,12 +32,15 @@ var ErrConsumerClosed = errors.New("DynamoMQ: Consumer closed")
 // Consumer functions for setting various ConsumerOptions.
 type ConsumerOptions struct {
 	// PollingInterval specifies the time interval at which the Consumer polls the DynamoDB queue for new messages.
+	// An empty queue is polled again sooner when a message being processed by another consumer becomes visible before it elapses.
 	PollingInterval time.Duration
 	// Concurrency sets the number of concurrent message processing workers.
 	Concurrency int
 	// MaximumReceives defines the maximum number of times a message can be delivered.
+	// If it is zero, the MaximumReceives of the stored QueueConfig is used, and otherwise deliveries are unlimited.
 	MaximumReceives int
 	// VisibilityTimeout sets the duration (in seconds) a message remains invisible in the queue after being received.
+	// If it is zero, the VisibilityTimeout of the stored QueueConfig is used, and then the default of 30 seconds.
 	VisibilityTimeout int
 	// RetryInterval defines the time interval (in seconds) before a failed message is retried.
 	RetryInterval int
@@ -45,6 +50,26 @@ type ConsumerOptions struct {
 	ErrorLog *log.Logger
 	// OnShutdown is a slice of functions called when the Consumer is shutting down.
 	OnShutdown []func()
+	// PerMessageTimeout makes the Consumer cancel the context given to a ContextMessageProcessor
+	// when the visibility timeout of the message is about to expire, PerMessageTimeoutMargin before it does,
+	// instead of when it expires.
+	// A message whose handler returns after the cancellation is neither deleted nor retried,
+	// and it becomes visible again when its visibility timeout expires.
+	PerMessageTimeout bool
+	// PerMessageTimeoutMargin is the safety margin subtracted from the visibility timeout of a message
+	// to set the deadline of its handler when PerMessageTimeout is true.
+	PerMessageTimeoutMargin time.Duration
+	// RetryPolicy sets the delays between the receives failed with a retryable error, such as a throttled request,
+	// instead of the polling interval. The Consumer stops with the last error after MaxAttempts consecutive failures.
+	// If it is nil, the Consumer polls again after the polling interval, indefinitely.
+	RetryPolicy *retry.Policy
+	// AutoAckCanaries makes the Consumer delete the canaries sent by SendCanary without passing them
+	// to its MessageProcessor.
+	AutoAckCanaries bool
+	// IdempotencyStore makes the Consumer skip the messages it marks as processed, as set with WithEnsureExactlyOnce.
+	IdempotencyStore IdempotencyStore
+	// Clock is an abstraction of time operations, allowing control over time during tests.
+	Clock clock.Clock
 }
 
 // WithPollingInterval sets the polling interval for the Consumer.
@@ -112,16 +137,57 @@ func WithOnShutdown(onShutdown []func()) func(o *ConsumerOptions) {
 	}
 }
 
+// WithPerMessageTimeout makes the Consumer cancel the context of each handler
+// safetyMargin before the visibility timeout of its message expires.
+// This function enables PerMessageTimeout, which only ContextMessageProcessor can observe.
+func WithPerMessageTimeout(safetyMargin time.Duration) func(o *ConsumerOptions) {
+	return func(o *ConsumerOptions) {
+		o.PerMessageTimeout = true
+		o.PerMessageTimeoutMargin = safetyMargin
+	}
+}
+
+// WithConsumerRetryPolicy sets the retry policy applied to the receives failed with a retryable error.
+// This function configures the backoff between consecutive failures and when the Consumer gives up.
+func WithConsumerRetryPolicy(policy *retry.Policy) func(o *ConsumerOptions) {
+	return func(o *ConsumerOptions) {
+		o.RetryPolicy = policy
+	}
+}
+
+// WithAutoAckCanaries makes the Consumer delete the canaries it receives without processing them.
+// This function keeps the health checks of the queue out of the business logic of the MessageProcessor.
+func WithAutoAckCanaries() func(o *ConsumerOptions) {
+	return func(o *ConsumerOptions) {
+		o.AutoAckCanaries = true
+	}
+}
+
+// WithEnsureExactlyOnce makes the Consumer record the messages it has processed in the store, and delete
+// without processing them the messages delivered again once they are recorded.
+// A message is marked after it is processed successfully and before it is deleted, so that a Consumer crashing
+// or failing to delete the message does not process it again. Marking it before processing it instead would
+// lose the message if the Consumer crashed while processing it.
+// The trade-off is that a message processed successfully is processed again if the Consumer stops before marking it,
+// or if another Consumer receives it while it is processed, after its visibility timeout expired:
+// handlers with side effects that must never be repeated still need to be idempotent within that window.
+// A message is not processed while the store cannot be read; it becomes visible again after its visibility timeout.
+func WithEnsureExactlyOnce(store IdempotencyStore) func(o *ConsumerOptions) {
+	return func(o *ConsumerOptions) {
+		o.IdempotencyStore = store
+	}
+}
+
 // NewConsumer creates a new Consumer instance with the specified client, message processor, and options.
 // It configures the Consumer with default values which can be overridden by the provided option functions.
 func NewConsumer[T any](client Client[T], processor MessageProcessor[T], opts ...func(o *ConsumerOptions)) *Consumer[T] {
 	o := &ConsumerOptions{
-		PollingInterval:   defaultPollingInterval,
-		Concurrency:       defaultConcurrency,
-		MaximumReceives:   defaultMaximumReceives,
-		VisibilityTimeout: constant.DefaultVisibilityTimeoutInSeconds,
-		RetryInterval:     defaultRetryIntervalInSeconds,
-		QueueType:         defaultQueueType,
+		PollingInterval: defaultPollingInterval,
+		Concurrency:     defaultConcurrency,
+		MaximumReceives: defaultMaximumReceives,
+		RetryInterval:   defaultRetryIntervalInSeconds,
+		QueueType:       defaultQueueType,
+		Clock:           &clock.RealClock{},
 	}
 	for _, opt := range opts {
 		opt(o)
@@ -137,11 +203,18 @@ func NewConsumer[T any](client Client[T], processor MessageProcessor[T], opts ..
 		queueType:         o.QueueType,
 		errorLog:          o.ErrorLog,
 		onShutdown:        o.OnShutdown,
+		perMessageTimeout: o.PerMessageTimeout,
+		timeoutMargin:     o.PerMessageTimeoutMargin,
+		clock:             o.Clock,
+		retryPolicy:       o.RetryPolicy,
+		autoAckCanaries:   o.AutoAckCanaries,
+		idempotencyStore:  o.IdempotencyStore,
 		inShutdown:        0,
 		mu:                sync.Mutex{},
 		activeMessages:    make(map[*Message[T]]struct{}),
 		activeMessagesWG:  sync.WaitGroup{},
 		doneChan:          make(chan struct{}),
+		wakeChan:          make(chan struct{}, 1),
 	}
 }
 
@@ -163,6 +236,28 @@ func (f MessageProcessorFunc[T]) Process(msg *Message[T]) error {
 	return f(msg)
 }
 
+// ContextMessageProcessor is a MessageProcessor whose processing can be cancelled.
+// The Consumer calls ProcessContext instead of Process, with a context whose deadline is the time the message
+// becomes visible again, as returned by VisibleAgainAt, or the safety margin before it with PerMessageTimeout.
+type ContextMessageProcessor[T any] interface {
+	MessageProcessor[T]
+	// ProcessContext handles the processing of a message until ctx is done.
+	ProcessContext(ctx context.Context, msg *Message[T]) error
+}
+
+// ContextMessageProcessorFunc is a functional type that implements the ContextMessageProcessor interface.
+type ContextMessageProcessorFunc[T any] func(ctx context.Context, msg *Message[T]) error
+
+// Process calls the ContextMessageProcessorFunc with a context that is never cancelled.
+func (f ContextMessageProcessorFunc[T]) Process(msg *Message[T]) error {
+	return f(context.Background(), msg)
+}
+
+// ProcessContext calls the ContextMessageProcessorFunc itself to process the message.
+func (f ContextMessageProcessorFunc[T]) ProcessContext(ctx context.Context, msg *Message[T]) error {
+	return f(ctx, msg)
+}
+
 // Consumer is a struct responsible for consuming messages from a DynamoDB-based queue.
 // It supports generic message types and includes settings such as concurrency, polling intervals, and more.
 // Note: To create a new instance of Consumer, it is necessary to use the NewConsumer function.
@@ -177,12 +272,21 @@ type Consumer[T any] struct {
 	queueType         QueueType
 	errorLog          *log.Logger
 	onShutdown        []func()
+	perMessageTimeout bool
+	timeoutMargin     time.Duration
+	clock             clock.Clock
+	retryPolicy       *retry.Policy
+	autoAckCanaries   bool
+	idempotencyStore  IdempotencyStore
+	source            string
+	stats             consumerStats
 
 	inShutdown       int32
 	mu               sync.Mutex
 	activeMessages   map[*Message[T]]struct{}
 	activeMessagesWG sync.WaitGroup
 	doneChan         chan struct{}
+	wakeChan         chan struct{}
 }
 
 // StartConsuming starts the message consumption process, polling the queue for messages and processing them.
@@ -191,6 +295,10 @@ func (c *Consumer[T]) StartConsuming() error {
 	msgChan := make(chan *Message[T], c.concurrency)
 	defer close(msgChan)
 
+	c.stats.startedAt.Store(c.clock.Now().UnixNano())
+	c.stats.state.Store(ConsumerStateRunning)
+	defer c.stats.state.Store(ConsumerStateStopped)
+
 	for i := 0; i < c.concurrency; i++ {
 		go func() {
 			for msg := range msgChan {
@@ -199,6 +307,7 @@ func (c *Consumer[T]) StartConsuming() error {
 		}()
 	}
 
+	failures := 0
 	for {
 		ctx := context.Background()
 		r, err := c.client.ReceiveMessage(ctx, &ReceiveMessageInput{
@@ -209,76 +318,222 @@ func (c *Consumer[T]) StartConsuming() error {
 			if c.shuttingDown() {
 				return ErrConsumerClosed
 			}
+			c.recordReceiveError(err)
 			if !isTemporary(err) {
 				return fmt.Errorf("DynamoMQ: Failed to receive a message: %w", err)
 			}
-			time.Sleep(c.pollingInterval)
+			if c.retryPolicy == nil || !retry.Retryable(err) {
+				c.wait(c.idleDelay(err))
+				continue
+			}
+			failures++
+			if c.retryPolicy.Exhausted(failures) {
+				return fmt.Errorf("DynamoMQ: Failed to receive a message after %d attempts: %w", failures, err)
+			}
+			c.wait(c.retryPolicy.NextDelay(failures))
+			continue
+		}
+		failures = 0
+		if r == nil || r.ReceivedMessage == nil {
+			// The client returns no message and no error for an empty queue with WithEmptyReceiveAsNil.
+			if c.shuttingDown() {
+				return ErrConsumerClosed
+			}
+			c.stats.state.Store(ConsumerStateRunning)
+			c.wait(c.pollingInterval)
 			continue
 		}
+		c.recordReceived()
+		r.ReceivedMessage.Source = c.source
 		msgChan <- r.ReceivedMessage
 	}
 }
 
+// Wake makes the Consumer poll the queue immediately instead of waiting for the rest of the polling interval.
+// It never blocks, and wakes received while the Consumer is already polling are coalesced into one.
+// A StreamNotifier calls it when a new message is written to the table.
+func (c *Consumer[T]) Wake() {
+	select {
+	case c.wakeChan <- struct{}{}:
+	default:
+	}
+}
+
+// idleDelay returns how long to wait before polling again after err.
+// When the queue is empty but a message being processed by another consumer becomes visible again before
+// the polling interval elapses, it waits only until then.
+func (c *Consumer[T]) idleDelay(err error) time.Duration {
+	var emptyQueueErr *EmptyQueueError
+	if errors.As(err, &emptyQueueErr) && !emptyQueueErr.NextVisibleAt.IsZero() && emptyQueueErr.RetryAfter < c.pollingInterval {
+		return emptyQueueErr.RetryAfter
+	}
+	return c.pollingInterval
+}
+
+// wait blocks for d, or until the Consumer is woken up.
+func (c *Consumer[T]) wait(d time.Duration) {
+	c.stats.idleBackoff.Store(int64(d))
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-c.wakeChan:
+	}
+}
+
 func (c *Consumer[T]) trackAndProcessMessage(ctx context.Context, msg *Message[T]) {
 	c.trackMessage(msg, true)
+	c.stats.activeWorkers.Add(1)
 	c.processMessage(ctx, msg)
+	c.stats.activeWorkers.Add(-1)
+	c.stats.inFlight.Add(-1)
 	c.trackMessage(msg, false)
 }
 
 func (c *Consumer[T]) processMessage(ctx context.Context, msg *Message[T]) {
-	if err := c.messageProcessor.Process(msg); err != nil {
-		c.handleError(ctx, msg)
+	if msg.CorrelationID != "" {
+		ctx = ContextWithCorrelationID(ctx, msg.CorrelationID)
+	}
+	if msg.Canary && c.autoAckCanaries {
+		c.deleteMessage(ctx, msg)
 		return
 	}
+	if c.idempotencyStore != nil {
+		processed, err := c.idempotencyStore.IsProcessed(ctx, msg.ID)
+		if err != nil {
+			c.recordError(err)
+			c.logMessagef(msg, "DynamoMQ: Failed to check whether a message was processed. %s", err)
+			return
+		}
+		if processed {
+			c.deleteMessage(ctx, msg)
+			return
+		}
+	}
+	if err := c.process(ctx, msg); err != nil {
+		c.stats.failed.Add(1)
+		if errors.Is(err, errHandlerDeadlineExceeded) {
+			c.logMessagef(msg, "DynamoMQ: Failed to process a message before its visibility timeout.")
+			return
+		}
+		c.handleError(ctx, msg, err)
+		return
+	}
+	c.stats.processed.Add(1)
+	if c.idempotencyStore != nil {
+		if err := c.idempotencyStore.MarkProcessed(ctx, msg.ID); err != nil {
+			c.recordError(err)
+			c.logMessagef(msg, "DynamoMQ: Failed to mark a message as processed. %s", err)
+		}
+	}
 	c.deleteMessage(ctx, msg)
 }
 
-func (c *Consumer[T]) handleError(ctx context.Context, msg *Message[T]) {
-	if c.shouldRetry(msg) {
+// errHandlerDeadlineExceeded is returned by process when the handler returned after its deadline,
+// when the Consumer may no longer own the message.
+var errHandlerDeadlineExceeded = errors.New("DynamoMQ: Handler deadline exceeded")
+
+func (c *Consumer[T]) process(ctx context.Context, msg *Message[T]) error {
+	p, ok := c.messageProcessor.(ContextMessageProcessor[T])
+	if !ok {
+		return c.messageProcessor.Process(msg)
+	}
+	ctx, cancel := context.WithDeadline(ctx, c.handlerDeadline(msg))
+	defer cancel()
+	err := p.ProcessContext(ctx, msg)
+	if c.perMessageTimeout && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return errHandlerDeadlineExceeded
+	}
+	return err
+}
+
+// handlerDeadline returns the deadline of the context of the handler of the message: the time the message becomes
+// visible again, minus the safety margin with PerMessageTimeout. If the message does not tell when it becomes visible,
+// or its timestamp is malformed, the visibility timeout of the Consumer is assumed to have started now. The time left is measured with the clock
+// of the Consumer, and added to the current time of the process, which the deadlines of contexts are compared to.
+func (c *Consumer[T]) handlerDeadline(msg *Message[T]) time.Time {
+	now := c.clock.Now()
+	visibleAgainAt, err := msg.VisibleAgainAt()
+	if err != nil || visibleAgainAt.IsZero() {
+		visibilityTimeout := constant.DefaultVisibilityTimeout
+		if c.visibilityTimeout > 0 {
+			visibilityTimeout = time.Duration(c.visibilityTimeout) * time.Second
+		}
+		visibleAgainAt = now.Add(visibilityTimeout)
+	}
+	left := visibleAgainAt.Sub(now)
+	if c.perMessageTimeout {
+		left -= c.timeoutMargin
+	}
+	return time.Now().Add(left)
+}
+
+func (c *Consumer[T]) handleError(ctx context.Context, msg *Message[T], err error) {
+	if c.shouldRetry(ctx, msg) {
 		c.retryMessage(ctx, msg)
 	} else {
-		c.handleFailure(ctx, msg)
+		c.handleFailure(ctx, msg, err)
 	}
 }
 
-func (c *Consumer[T]) shouldRetry(msg *Message[T]) bool {
-	if c.maximumReceives == 0 {
+func (c *Consumer[T]) shouldRetry(ctx context.Context, msg *Message[T]) bool {
+	maximumReceives := c.maximumReceives
+	if maximumReceives == 0 {
+		maximumReceives = c.storedMaximumReceives(ctx)
+	}
+	if maximumReceives == 0 {
 		return true
 	}
-	if msg.ReceiveCount < c.maximumReceives {
+	if msg.ReceiveCount < maximumReceives {
 		return true
 	}
 	return false
 }
 
+// storedMaximumReceives returns the MaximumReceives of the stored QueueConfig, or zero if it cannot be read.
+func (c *Consumer[T]) storedMaximumReceives(ctx context.Context) int {
+	out, err := c.client.GetQueueConfig(ctx, &GetQueueConfigInput{})
+	if err != nil {
+		return 0
+	}
+	return out.Config.MaximumReceives
+}
+
 func (c *Consumer[T]) retryMessage(ctx context.Context, msg *Message[T]) {
 	in := &ChangeMessageVisibilityInput{
 		ID:                msg.ID,
 		VisibilityTimeout: c.retryInterval,
 	}
 	if _, err := c.client.ChangeMessageVisibility(ctx, in); err != nil {
-		c.logf("DynamoMQ: Failed to update a message as visible. %s", err)
+		c.recordError(err)
+		c.logMessagef(msg, "DynamoMQ: Failed to update a message as visible. %s", err)
 	}
 }
 
-func (c *Consumer[T]) handleFailure(ctx context.Context, msg *Message[T]) {
+func (c *Consumer[T]) handleFailure(ctx context.Context, msg *Message[T], err error) {
 	switch c.queueType {
 	case QueueTypeStandard:
-		c.moveToDLQ(ctx, msg)
+		c.moveToDLQ(ctx, msg, err)
 	case QueueTypeDLQ:
 		c.deleteMessage(ctx, msg)
 	}
 }
 
-func (c *Consumer[T]) moveToDLQ(ctx context.Context, msg *Message[T]) {
-	if _, err := c.client.MoveMessageToDLQ(ctx, &MoveMessageToDLQInput{ID: msg.ID}); err != nil {
-		c.logf("DynamoMQ: Failed to move a message to DLQ. %s", err)
+func (c *Consumer[T]) moveToDLQ(ctx context.Context, msg *Message[T], cause error) {
+	in := &MoveMessageToDLQInput{
+		ID:     msg.ID,
+		Reason: cause.Error(),
+	}
+	if _, err := c.client.MoveMessageToDLQ(ctx, in); err != nil {
+		c.recordError(err)
+		c.logMessagef(msg, "DynamoMQ: Failed to move a message to DLQ. %s", err)
 	}
 }
 
 func (c *Consumer[T]) deleteMessage(ctx context.Context, msg *Message[T]) {
 	if _, err := c.client.DeleteMessage(ctx, &DeleteMessageInput{ID: msg.ID}); err != nil {
-		c.logf("DynamoMQ: Failed to delete a message. %s", err)
+		c.recordError(err)
+		c.logMessagef(msg, "DynamoMQ: Failed to delete a message. %s", err)
 	}
 }
 
@@ -342,20 +597,41 @@ func (c *Consumer[T]) logf(format string, args ...any) {
 	}
 }
 
+// logMessagef logs about a message, followed by its ID, its correlation ID and its source, if any.
+func (c *Consumer[T]) logMessagef(msg *Message[T], format string, args ...any) {
+	format += " (ID: %s"
+	args = append(args, msg.ID)
+	if msg.CorrelationID != "" {
+		format += ", correlation ID: %s"
+		args = append(args, msg.CorrelationID)
+	}
+	if msg.Source != "" {
+		format += ", source: %s"
+		args = append(args, msg.Source)
+	}
+	c.logf(format+")", args...)
+}
+
 func isTemporary(err error) bool {
+	var retryable RetryableError
+	if errors.As(err, &retryable) {
+		return retryable.Retryable()
+	}
 	var (
 		conditionalCheckFailedError *ConditionalCheckFailedError
 		dynamoDBAPIError            *DynamoDBAPIError
-		emptyQueueError             *EmptyQueueError
-		idNotProvidedError          *IDNotProvidedError
-		idNotFoundError             *IDNotFoundError
+		queuePausedError            *QueuePausedError
+		inFlightLimitExceededError  InFlightLimitExceededError
 	)
 	switch {
 	case errors.As(err, &conditionalCheckFailedError),
+		errors.Is(err, ErrVersionConflict),
 		errors.As(err, &dynamoDBAPIError),
-		errors.As(err, &emptyQueueError),
-		errors.As(err, &idNotProvidedError),
-		errors.As(err, &idNotFoundError):
+		errors.Is(err, ErrEmptyQueue),
+		errors.As(err, &queuePausedError),
+		errors.As(err, &inFlightLimitExceededError),
+		errors.Is(err, ErrIDNotProvided),
+		errors.Is(err, ErrIDNotFound):
 		return true
 	default:
 		return false
@@ -0,0 +1,159 @@
+package dynamomq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ConsumerSource is a queue consumed by a MultiSourceConsumer, such as a table in another AWS account.
+type ConsumerSource[T any] struct {
+	// Name identifies the source. It is set as the Source of the messages received from it,
+	// and it is reported in the logs and in the stats.
+	Name string
+	// Client is the client of the queue.
+	Client Client[T]
+	// Options are applied after the options given to NewMultiSourceConsumer, to override them for this source,
+	// typically WithConcurrency to give the source its share of the workers, and WithQueueType.
+	Options []func(o *ConsumerOptions)
+}
+
+// MultiSourceConsumer consumes the messages of several queues with the same MessageProcessor.
+// Each source is polled by its own loop with its own workers, so that a source that is empty, throttled or paused
+// does not hold back the others, and each gets the share of the workers set by its concurrency.
+// Note: To create a new instance of MultiSourceConsumer, it is necessary to use the NewMultiSourceConsumer function.
+type MultiSourceConsumer[T any] struct {
+	consumers  []*Consumer[T]
+	onShutdown []func()
+
+	mu       sync.Mutex
+	doneChan chan struct{}
+}
+
+// NewMultiSourceConsumer creates a Consumer for each of the sources, with the options followed by the Options
+// of the source. The OnShutdown functions of the options are called once by Shutdown, not once per source.
+func NewMultiSourceConsumer[T any](sources []ConsumerSource[T], processor MessageProcessor[T],
+	opts ...func(o *ConsumerOptions)) *MultiSourceConsumer[T] {
+	shared := &ConsumerOptions{}
+	for _, opt := range opts {
+		opt(shared)
+	}
+	m := &MultiSourceConsumer[T]{
+		consumers:  make([]*Consumer[T], 0, len(sources)),
+		onShutdown: shared.OnShutdown,
+		doneChan:   make(chan struct{}),
+	}
+	for _, source := range sources {
+		sourceOpts := make([]func(o *ConsumerOptions), 0, len(opts)+1+len(source.Options))
+		sourceOpts = append(sourceOpts, opts...)
+		sourceOpts = append(sourceOpts, WithOnShutdown(nil))
+		sourceOpts = append(sourceOpts, source.Options...)
+		consumer := NewConsumer[T](source.Client, processor, sourceOpts...)
+		consumer.source = source.Name
+		m.consumers = append(m.consumers, consumer)
+	}
+	return m
+}
+
+// StartConsuming starts consuming every source. It blocks until a source stops with an error, which is returned
+// with the name of the source, or until Shutdown is called, and then it returns ErrConsumerClosed.
+// The other sources keep consuming after a source stops with an error, until Shutdown is called.
+func (m *MultiSourceConsumer[T]) StartConsuming() error {
+	select {
+	case <-m.doneChan:
+		return ErrConsumerClosed
+	default:
+	}
+	errs := make(chan error, len(m.consumers))
+	for _, consumer := range m.consumers {
+		consumer := consumer
+		go func() {
+			if err := consumer.StartConsuming(); err != nil && !errors.Is(err, ErrConsumerClosed) {
+				errs <- fmt.Errorf("DynamoMQ: Source %s stopped: %w", consumer.source, err)
+			}
+		}()
+	}
+	select {
+	case err := <-errs:
+		return err
+	case <-m.doneChan:
+		return ErrConsumerClosed
+	}
+}
+
+// Wake makes every source poll its queue immediately instead of waiting for the rest of its polling interval.
+func (m *MultiSourceConsumer[T]) Wake() {
+	for _, consumer := range m.consumers {
+		consumer.Wake()
+	}
+}
+
+// Shutdown gracefully shuts down every source, and waits until the messages being processed from all of them
+// are processed or ctx is done.
+func (m *MultiSourceConsumer[T]) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	select {
+	case <-m.doneChan:
+	default:
+		close(m.doneChan)
+		for _, f := range m.onShutdown {
+			go f()
+		}
+	}
+	m.mu.Unlock()
+
+	errs := make(chan error, len(m.consumers))
+	for _, consumer := range m.consumers {
+		consumer := consumer
+		go func() {
+			errs <- consumer.Shutdown(ctx)
+		}()
+	}
+	var err error
+	for range m.consumers {
+		if e := <-errs; e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Stats returns a snapshot of the activity of all the sources, with the stats of each source in Sources.
+// The counters are summed over the sources. The state is RUNNING while a source is running, then PAUSED while
+// a source is paused, and STOPPED once every source is stopped.
+func (m *MultiSourceConsumer[T]) Stats() ConsumerStats {
+	stats := ConsumerStats{
+		State:   ConsumerStateStopped,
+		Sources: make([]ConsumerStats, 0, len(m.consumers)),
+	}
+	for i, consumer := range m.consumers {
+		s := consumer.Stats()
+		stats.Sources = append(stats.Sources, s)
+		switch {
+		case s.State == ConsumerStateRunning:
+			stats.State = ConsumerStateRunning
+		case s.State == ConsumerStatePaused && stats.State == ConsumerStateStopped:
+			stats.State = ConsumerStatePaused
+		}
+		if !s.StartedAt.IsZero() && (stats.StartedAt.IsZero() || s.StartedAt.Before(stats.StartedAt)) {
+			stats.StartedAt = s.StartedAt
+		}
+		if s.LastReceivedAt.After(stats.LastReceivedAt) {
+			stats.LastReceivedAt = s.LastReceivedAt
+		}
+		if s.LastError != "" && s.LastErrorAt.After(stats.LastErrorAt) {
+			stats.LastError = s.LastError
+			stats.LastErrorAt = s.LastErrorAt
+		}
+		if i == 0 || s.IdleBackoff < stats.IdleBackoff {
+			stats.IdleBackoff = s.IdleBackoff
+		}
+		stats.Concurrency += s.Concurrency
+		stats.ActiveWorkers += s.ActiveWorkers
+		stats.InFlight += s.InFlight
+		stats.Processed += s.Processed
+		stats.Failed += s.Failed
+	}
+	return stats
+}
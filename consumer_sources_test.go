@@ -0,0 +1,140 @@
+package dynamomq_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+// newSourceClient returns a client delivering size messages whose IDs start with prefix, and then an empty queue.
+func newSourceClient(prefix string, size int, queueTypes chan<- dynamomq.QueueType) *mock.Client[test.MessageData] {
+	var received atomic.Int32
+	return &mock.Client[test.MessageData]{
+		ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+			select {
+			case queueTypes <- params.QueueType:
+			default:
+			}
+			i := int(received.Add(1))
+			if i > size {
+				return nil, &dynamomq.EmptyQueueError{}
+			}
+			id := fmt.Sprintf("%s-%d", prefix, i)
+			return &dynamomq.ReceiveMessageOutput[test.MessageData]{
+				ReceivedMessage: dynamomq.NewMessage(id, test.NewMessageData(id), test.DefaultTestDate),
+			}, nil
+		},
+		DeleteMessageFunc: func(ctx context.Context, params *dynamomq.DeleteMessageInput) (*dynamomq.DeleteMessageOutput, error) {
+			return &dynamomq.DeleteMessageOutput{}, nil
+		},
+	}
+}
+
+func TestMultiSourceConsumer(t *testing.T) {
+	t.Parallel()
+	queueTypes := make(chan dynamomq.QueueType, 1)
+	var (
+		sources    sync.Map
+		shutdowns  atomic.Int32
+		processing sync.WaitGroup
+	)
+	processing.Add(30)
+	processor := dynamomq.MessageProcessorFunc[test.MessageData](func(msg *dynamomq.Message[test.MessageData]) error {
+		sources.Store(msg.ID, msg.Source)
+		processing.Done()
+		return nil
+	})
+	consumer := dynamomq.NewMultiSourceConsumer[test.MessageData]([]dynamomq.ConsumerSource[test.MessageData]{
+		{Name: "account-a", Client: newSourceClient("A", 20, nil)},
+		{
+			Name:    "account-b",
+			Client:  newSourceClient("B", 10, queueTypes),
+			Options: []func(*dynamomq.ConsumerOptions){dynamomq.WithConcurrency(1), dynamomq.WithQueueType(dynamomq.QueueTypeDLQ)},
+		},
+	}, processor,
+		dynamomq.WithConcurrency(3),
+		dynamomq.WithPollingInterval(time.Hour),
+		dynamomq.WithOnShutdown([]func(){func() { shutdowns.Add(1) }}))
+	started := make(chan error, 1)
+	go func() {
+		started <- consumer.StartConsuming()
+	}()
+	processing.Wait()
+
+	if got := <-queueTypes; got != dynamomq.QueueTypeDLQ {
+		t.Errorf("ReceiveMessage() queue type of account-b = %s, want %s", got, dynamomq.QueueTypeDLQ)
+	}
+	sources.Range(func(key, value any) bool {
+		want := "account-a"
+		if strings.HasPrefix(key.(string), "B-") {
+			want = "account-b"
+		}
+		if value != want {
+			t.Errorf("message %s source = %v, want %s", key, value, want)
+		}
+		return true
+	})
+	deadline := time.Now().Add(5 * time.Second)
+	stats := consumer.Stats()
+	for stats.Processed != 30 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+		stats = consumer.Stats()
+	}
+	if stats.State != dynamomq.ConsumerStateRunning || stats.Concurrency != 4 || stats.Processed != 30 {
+		t.Errorf("Stats() = %+v, want a running consumer with 4 workers that processed 30 messages", stats)
+	}
+	if len(stats.Sources) != 2 ||
+		stats.Sources[0].Source != "account-a" || stats.Sources[0].Processed != 20 ||
+		stats.Sources[1].Source != "account-b" || stats.Sources[1].Processed != 10 {
+		t.Errorf("Stats().Sources = %+v, want 20 messages from account-a and 10 from account-b", stats.Sources)
+	}
+
+	if err := consumer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := <-started; !errors.Is(err, dynamomq.ErrConsumerClosed) {
+		t.Errorf("StartConsuming() error = %v, want %v", err, dynamomq.ErrConsumerClosed)
+	}
+	for shutdowns.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := shutdowns.Load(); got != 1 {
+		t.Errorf("OnShutdown calls = %d, want 1", got)
+	}
+	if got := consumer.Stats().State; got != dynamomq.ConsumerStateStopped {
+		t.Errorf("Stats().State = %s, want %s", got, dynamomq.ConsumerStateStopped)
+	}
+}
+
+func TestMultiSourceConsumerShouldReturnErrorOfSource(t *testing.T) {
+	t.Parallel()
+	failing := &mock.Client[test.MessageData]{
+		ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+			return nil, dynamomq.ValidationError{Cause: test.ErrTest}
+		},
+	}
+	consumer := dynamomq.NewMultiSourceConsumer[test.MessageData]([]dynamomq.ConsumerSource[test.MessageData]{
+		{Name: "account-a", Client: newSourceClient("A", 0, nil)},
+		{Name: "account-b", Client: failing},
+	}, &CountProcessor[test.MessageData]{}, dynamomq.WithPollingInterval(time.Hour))
+	err := consumer.StartConsuming()
+	var validationErr dynamomq.ValidationError
+	if !errors.As(err, &validationErr) || !strings.Contains(err.Error(), "account-b") {
+		t.Errorf("StartConsuming() error = %v, want the ValidationError of account-b", err)
+	}
+	if err := consumer.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	if err := consumer.StartConsuming(); !errors.Is(err, dynamomq.ErrConsumerClosed) {
+		t.Errorf("StartConsuming() after Shutdown() error = %v, want %v", err, dynamomq.ErrConsumerClosed)
+	}
+}
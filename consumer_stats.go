@@ -0,0 +1,143 @@
+package dynamomq
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ConsumerState is the state of a Consumer reported by its Stats.
+type ConsumerState string
+
+const (
+	// ConsumerStateStopped is the state of a Consumer that is not consuming, before StartConsuming
+	// or after Shutdown is called.
+	ConsumerStateStopped ConsumerState = "STOPPED"
+	// ConsumerStateRunning is the state of a Consumer polling the queue.
+	ConsumerStateRunning ConsumerState = "RUNNING"
+	// ConsumerStatePaused is the state of a Consumer whose last poll found the queue paused with SetQueueEnabled.
+	ConsumerStatePaused ConsumerState = "PAUSED"
+)
+
+// ConsumerStats is a snapshot of the activity of a Consumer, returned by Consumer.Stats.
+type ConsumerStats struct {
+	// Source is the name of the source of a MultiSourceConsumer the stats are for, or empty for a Consumer.
+	Source string `json:"source,omitempty"`
+	// State is the state of the Consumer.
+	State ConsumerState `json:"state"`
+	// StartedAt is the time StartConsuming was called, or zero if it has not been called.
+	StartedAt time.Time `json:"started_at"`
+	// Concurrency is the number of workers of the Consumer.
+	Concurrency int `json:"concurrency"`
+	// ActiveWorkers is the number of workers processing a message.
+	ActiveWorkers int `json:"active_workers"`
+	// InFlight is the number of messages received and not yet processed, including those waiting for a worker.
+	InFlight int `json:"in_flight"`
+	// LastReceivedAt is the time the Consumer last received a message, or zero if it has received none.
+	LastReceivedAt time.Time `json:"last_received_at"`
+	// LastError is the last error returned by the client to the Consumer, other than for an empty or paused queue.
+	// The errors returned by the message processor are counted in Failed instead.
+	LastError string `json:"last_error,omitempty"`
+	// LastErrorAt is the time of LastError, or zero if there has been no error.
+	LastErrorAt time.Time `json:"last_error_at"`
+	// Processed is the number of messages processed successfully since the Consumer was created.
+	Processed int64 `json:"processed"`
+	// Failed is the number of messages whose processing failed since the Consumer was created.
+	Failed int64 `json:"failed"`
+	// IdleBackoff is the time the Consumer waits before polling again, or zero while messages are received.
+	IdleBackoff time.Duration `json:"idle_backoff"`
+	// Sources are the stats of each source of a MultiSourceConsumer, in the order of the sources.
+	Sources []ConsumerStats `json:"sources,omitempty"`
+}
+
+// consumerStats holds the counters of ConsumerStats, updated without locks by the workers and the polling loop.
+type consumerStats struct {
+	state          atomic.Value
+	startedAt      atomic.Int64
+	activeWorkers  atomic.Int64
+	inFlight       atomic.Int64
+	lastReceivedAt atomic.Int64
+	lastError      atomic.Pointer[consumerError]
+	processed      atomic.Int64
+	failed         atomic.Int64
+	idleBackoff    atomic.Int64
+}
+
+type consumerError struct {
+	msg string
+	at  time.Time
+}
+
+// Stats returns a snapshot of the activity of the Consumer. It is safe to call concurrently and does not block,
+// so it can back a readiness probe; see ConsumerStatsHandler.
+func (c *Consumer[T]) Stats() ConsumerStats {
+	stats := ConsumerStats{
+		Source:         c.source,
+		State:          ConsumerStateStopped,
+		StartedAt:      unixNanoToTime(c.stats.startedAt.Load()),
+		Concurrency:    c.concurrency,
+		ActiveWorkers:  int(c.stats.activeWorkers.Load()),
+		InFlight:       int(c.stats.inFlight.Load()),
+		LastReceivedAt: unixNanoToTime(c.stats.lastReceivedAt.Load()),
+		Processed:      c.stats.processed.Load(),
+		Failed:         c.stats.failed.Load(),
+		IdleBackoff:    time.Duration(c.stats.idleBackoff.Load()),
+	}
+	if state, ok := c.stats.state.Load().(ConsumerState); ok && !c.shuttingDown() {
+		stats.State = state
+	}
+	if e := c.stats.lastError.Load(); e != nil {
+		stats.LastError = e.msg
+		stats.LastErrorAt = e.at
+	}
+	return stats
+}
+
+// recordError records err as the last error of the Consumer.
+func (c *Consumer[T]) recordError(err error) {
+	c.stats.lastError.Store(&consumerError{msg: err.Error(), at: c.clock.Now()})
+}
+
+// recordReceiveError records the error of a poll, which tells whether the queue is paused.
+func (c *Consumer[T]) recordReceiveError(err error) {
+	var queuePausedErr *QueuePausedError
+	if errors.As(err, &queuePausedErr) {
+		c.stats.state.Store(ConsumerStatePaused)
+		return
+	}
+	c.stats.state.Store(ConsumerStateRunning)
+	if !errors.Is(err, ErrEmptyQueue) {
+		c.recordError(err)
+	}
+}
+
+// recordReceived records a message received by a poll.
+func (c *Consumer[T]) recordReceived() {
+	c.stats.state.Store(ConsumerStateRunning)
+	c.stats.lastReceivedAt.Store(c.clock.Now().UnixNano())
+	c.stats.inFlight.Add(1)
+	c.stats.idleBackoff.Store(0)
+}
+
+func unixNanoToTime(nsec int64) time.Time {
+	if nsec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nsec).UTC()
+}
+
+// ConsumerStatsHandler returns an http.Handler writing the Stats of the consumer as JSON, to be used as a health endpoint.
+// It responds with 200 OK while the consumer is running or paused, and 503 Service Unavailable while it is stopped,
+// so that it can serve a readiness probe as it is.
+func ConsumerStatsHandler(consumer interface{ Stats() ConsumerStats }) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stats := consumer.Stats()
+		w.Header().Set("Content-Type", "application/json")
+		if stats.State == ConsumerStateStopped {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(stats)
+	})
+}
@@ -0,0 +1,162 @@
+package dynamomq_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+// waitForStats polls the stats of the consumer until cond holds.
+func waitForStats(t *testing.T, consumer *dynamomq.Consumer[test.MessageData], cond func(dynamomq.ConsumerStats) bool) dynamomq.ConsumerStats {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stats := consumer.Stats()
+		if cond(stats) {
+			return stats
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Stats() = %+v, the condition was never met", stats)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func serveStats(t *testing.T, consumer *dynamomq.Consumer[test.MessageData]) (int, dynamomq.ConsumerStats) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	dynamomq.ConsumerStatsHandler(consumer).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %s, want application/json", got)
+	}
+	var stats dynamomq.ConsumerStats
+	if err := json.NewDecoder(rec.Body).Decode(&stats); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	return rec.Code, stats
+}
+
+func TestConsumerStats(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	messages := []*dynamomq.Message[test.MessageData]{
+		NewTestMessageItemAsReady("A-101", test.DefaultTestDate),
+		NewTestMessageItemAsReady("A-102", test.DefaultTestDate),
+	}
+	var polls atomic.Int32
+	client := &mock.Client[test.MessageData]{
+		ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+			if i := int(polls.Add(1)) - 1; i < len(messages) {
+				return &dynamomq.ReceiveMessageOutput[test.MessageData]{ReceivedMessage: messages[i]}, nil
+			}
+			return nil, &dynamomq.EmptyQueueError{}
+		},
+		DeleteMessageFunc: func(ctx context.Context, params *dynamomq.DeleteMessageInput) (*dynamomq.DeleteMessageOutput, error) {
+			return &dynamomq.DeleteMessageOutput{}, nil
+		},
+		ChangeMessageVisibilityFunc: func(ctx context.Context, params *dynamomq.ChangeMessageVisibilityInput) (*dynamomq.ChangeMessageVisibilityOutput[test.MessageData], error) {
+			return nil, test.ErrTest
+		},
+	}
+	consumer := dynamomq.NewConsumer[test.MessageData](client,
+		dynamomq.MessageProcessorFunc[test.MessageData](func(msg *dynamomq.Message[test.MessageData]) error {
+			if msg.ID == "A-102" {
+				return test.ErrTest
+			}
+			return nil
+		}),
+		dynamomq.WithConcurrency(2),
+		dynamomq.WithPollingInterval(time.Hour),
+		dynamomq.WithErrorLog(log.New(io.Discard, "", 0)),
+		mock.WithConsumerClock(mock.Clock{T: now}))
+
+	if code, stats := serveStats(t, consumer); code != http.StatusServiceUnavailable || stats.State != dynamomq.ConsumerStateStopped {
+		t.Errorf("ConsumerStatsHandler() before StartConsuming = %d %s, want %d %s",
+			code, stats.State, http.StatusServiceUnavailable, dynamomq.ConsumerStateStopped)
+	}
+
+	go func() {
+		_ = consumer.StartConsuming()
+	}()
+	got := waitForStats(t, consumer, func(s dynamomq.ConsumerStats) bool {
+		return s.Processed+s.Failed == 2 && s.InFlight == 0 && s.LastError != "" && s.IdleBackoff > 0
+	})
+	want := dynamomq.ConsumerStats{
+		State:          dynamomq.ConsumerStateRunning,
+		StartedAt:      now,
+		Concurrency:    2,
+		LastReceivedAt: now,
+		LastError:      test.ErrTest.Error(),
+		LastErrorAt:    now,
+		Processed:      1,
+		Failed:         1,
+		IdleBackoff:    time.Hour,
+	}
+	test.AssertDeepEqual(t, got, want, "Stats()")
+
+	code, served := serveStats(t, consumer)
+	if code != http.StatusOK {
+		t.Errorf("ConsumerStatsHandler() status = %d, want %d", code, http.StatusOK)
+	}
+	test.AssertDeepEqual(t, served, want, "ConsumerStatsHandler()")
+
+	if err := consumer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if got := consumer.Stats().State; got != dynamomq.ConsumerStateStopped {
+		t.Errorf("Stats() state after Shutdown = %s, want %s", got, dynamomq.ConsumerStateStopped)
+	}
+}
+
+func TestConsumerStatsInFlight(t *testing.T) {
+	t.Parallel()
+	var polls atomic.Int32
+	client := &mock.Client[test.MessageData]{
+		ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+			if polls.Add(1) == 1 {
+				return &dynamomq.ReceiveMessageOutput[test.MessageData]{ReceivedMessage: NewTestMessageItemAsReady("A-101", test.DefaultTestDate)}, nil
+			}
+			return nil, &dynamomq.QueuePausedError{}
+		},
+		DeleteMessageFunc: func(ctx context.Context, params *dynamomq.DeleteMessageInput) (*dynamomq.DeleteMessageOutput, error) {
+			return &dynamomq.DeleteMessageOutput{}, nil
+		},
+	}
+	release := make(chan struct{})
+	consumer := dynamomq.NewConsumer[test.MessageData](client,
+		dynamomq.MessageProcessorFunc[test.MessageData](func(msg *dynamomq.Message[test.MessageData]) error {
+			<-release
+			return nil
+		}),
+		dynamomq.WithPollingInterval(time.Hour),
+		dynamomq.WithErrorLog(log.New(io.Discard, "", 0)))
+	go func() {
+		_ = consumer.StartConsuming()
+	}()
+	got := waitForStats(t, consumer, func(s dynamomq.ConsumerStats) bool {
+		return s.State == dynamomq.ConsumerStatePaused && s.ActiveWorkers == 1
+	})
+	if got.InFlight != 1 {
+		t.Errorf("Stats() in flight = %d, want 1", got.InFlight)
+	}
+	if got.LastError != "" {
+		t.Errorf("Stats() last error = %q, want none for a paused queue", got.LastError)
+	}
+	close(release)
+	waitForStats(t, consumer, func(s dynamomq.ConsumerStats) bool {
+		return s.ActiveWorkers == 0 && s.InFlight == 0 && s.Processed == 1
+	})
+	if err := consumer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+}
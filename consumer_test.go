@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"sync"
@@ -12,8 +13,10 @@ import (
 	"time"
 
 	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
 	"github.com/vvatanabe/dynamomq/internal/mock"
 	"github.com/vvatanabe/dynamomq/internal/test"
+	"github.com/vvatanabe/dynamomq/retry"
 )
 
 func TestConsumerStartConsumingShouldReturnErrConsumerClosed(t *testing.T) {
@@ -49,6 +52,91 @@ func TestConsumerStartConsumingShouldReturnNoTemporaryError(t *testing.T) {
 	}
 }
 
+func TestConsumerStartConsumingShouldReturnNonRetryableError(t *testing.T) {
+	t.Parallel()
+	client := &mock.Client[test.MessageData]{
+		ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+			return nil, dynamomq.ValidationError{Cause: test.ErrTest}
+		},
+	}
+	consumer := dynamomq.NewConsumer[test.MessageData](client, &CountProcessor[test.MessageData]{})
+	err := consumer.StartConsuming()
+	var validationErr dynamomq.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("StartConsuming() error = %v, want = %v", err, dynamomq.ValidationError{Cause: test.ErrTest})
+	}
+}
+
+func TestConsumerStartConsumingShouldGiveUpAfterRetryPolicyMaxAttempts(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	client := &mock.Client[test.MessageData]{
+		ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+			// The success of the third call resets the count of consecutive failures.
+			if calls.Add(1) == 3 {
+				return &dynamomq.ReceiveMessageOutput[test.MessageData]{
+					ReceivedMessage: dynamomq.NewMessage("A-101", test.NewMessageData("A-101"), test.DefaultTestDate),
+				}, nil
+			}
+			return nil, dynamomq.ThrottledError{Cause: test.ErrTest}
+		},
+		DeleteMessageFunc: func(ctx context.Context, params *dynamomq.DeleteMessageInput) (*dynamomq.DeleteMessageOutput, error) {
+			return &dynamomq.DeleteMessageOutput{}, nil
+		},
+	}
+	consumer := dynamomq.NewConsumer[test.MessageData](client, &CountProcessor[test.MessageData]{},
+		dynamomq.WithPollingInterval(time.Hour),
+		dynamomq.WithConsumerRetryPolicy(&retry.Policy{
+			InitialInterval: time.Millisecond,
+			MaxAttempts:     3,
+		}))
+	err := consumer.StartConsuming()
+	var throttledErr dynamomq.ThrottledError
+	if !errors.As(err, &throttledErr) {
+		t.Errorf("StartConsuming() error = %v, want = %v", err, dynamomq.ThrottledError{Cause: test.ErrTest})
+	}
+	if got := calls.Load(); got != 6 {
+		t.Errorf("ReceiveMessage() calls = %d, want 6", got)
+	}
+}
+
+func TestConsumerWakeShouldPollImmediately(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	processed := make(chan struct{})
+	client := &mock.Client[test.MessageData]{
+		ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+			if calls.Add(1) != 2 {
+				return nil, &dynamomq.EmptyQueueError{}
+			}
+			return &dynamomq.ReceiveMessageOutput[test.MessageData]{
+				ReceivedMessage: dynamomq.NewMessage("A-101", test.NewMessageData("A-101"), test.DefaultTestDate),
+			}, nil
+		},
+		DeleteMessageFunc: func(ctx context.Context, params *dynamomq.DeleteMessageInput) (*dynamomq.DeleteMessageOutput, error) {
+			close(processed)
+			return &dynamomq.DeleteMessageOutput{}, nil
+		},
+	}
+	consumer := dynamomq.NewConsumer[test.MessageData](client, &CountProcessor[test.MessageData]{},
+		dynamomq.WithPollingInterval(time.Hour),
+		dynamomq.WithConcurrency(1))
+	go func() {
+		_ = consumer.StartConsuming()
+	}()
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	consumer.Wake()
+	consumer.Wake()
+	select {
+	case <-processed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Wake() did not make the consumer poll before the polling interval")
+	}
+	_ = consumer.Shutdown(context.Background())
+}
+
 func TestConsumerStartConsuming(t *testing.T) {
 	t.Parallel()
 	type testCase struct {
@@ -307,3 +395,180 @@ func NewClientForConsumerTest(queue, dlq chan *dynamomq.Message[test.MessageData
 		},
 	}
 }
+
+func newPerMessageTimeoutClient(msg *dynamomq.Message[test.MessageData], deleted *atomic.Int32) *mock.Client[test.MessageData] {
+	var received atomic.Bool
+	return &mock.Client[test.MessageData]{
+		ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+			if received.Swap(true) {
+				return nil, &dynamomq.EmptyQueueError{}
+			}
+			return &dynamomq.ReceiveMessageOutput[test.MessageData]{ReceivedMessage: msg}, nil
+		},
+		DeleteMessageFunc: func(ctx context.Context, params *dynamomq.DeleteMessageInput) (*dynamomq.DeleteMessageOutput, error) {
+			deleted.Add(1)
+			return &dynamomq.DeleteMessageOutput{}, nil
+		},
+	}
+}
+
+func TestConsumerPerMessageTimeoutShouldCancelSlowHandler(t *testing.T) {
+	t.Parallel()
+	msg := dynamomqtest.NewProcessingMessage("A-101", test.NewMessageData("A-101"), test.DefaultTestDate,
+		dynamomqtest.WithVisibilityTimeout(30*time.Second))
+	// The handler has 100ms left: the clock is 100ms plus the safety margin before the message becomes visible.
+	now := test.DefaultTestDate.Add(30*time.Second - time.Second - 100*time.Millisecond)
+	var deleted, handled atomic.Int32
+	client := newPerMessageTimeoutClient(msg, &deleted)
+	client.ChangeMessageVisibilityFunc = func(ctx context.Context, params *dynamomq.ChangeMessageVisibilityInput) (*dynamomq.ChangeMessageVisibilityOutput[test.MessageData], error) {
+		handled.Add(1)
+		return &dynamomq.ChangeMessageVisibilityOutput[test.MessageData]{}, nil
+	}
+	client.MoveMessageToDLQFunc = func(ctx context.Context, params *dynamomq.MoveMessageToDLQInput) (*dynamomq.MoveMessageToDLQOutput[test.MessageData], error) {
+		handled.Add(1)
+		return &dynamomq.MoveMessageToDLQOutput[test.MessageData]{}, nil
+	}
+	returned := make(chan error, 1)
+	consumer := dynamomq.NewConsumer[test.MessageData](client,
+		dynamomq.ContextMessageProcessorFunc[test.MessageData](func(ctx context.Context, msg *dynamomq.Message[test.MessageData]) error {
+			select {
+			case <-ctx.Done():
+				returned <- ctx.Err()
+			case <-time.After(5 * time.Second):
+				returned <- nil
+			}
+			return test.ErrTest
+		}),
+		dynamomq.WithPollingInterval(time.Hour),
+		dynamomq.WithConcurrency(1),
+		dynamomq.WithPerMessageTimeout(time.Second),
+		dynamomq.WithErrorLog(log.New(io.Discard, "", 0)),
+		mock.WithConsumerClock(mock.Clock{T: now}))
+	go func() {
+		_ = consumer.StartConsuming()
+	}()
+	if err := <-returned; !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("handler context error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if err := consumer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if got := deleted.Load(); got != 0 {
+		t.Errorf("DeleteMessage() calls = %d, want 0", got)
+	}
+	if got := handled.Load(); got != 0 {
+		t.Errorf("the message was retried or moved to the DLQ %d times, want 0", got)
+	}
+}
+
+func TestConsumerHandlerContextDeadline(t *testing.T) {
+	t.Parallel()
+	received := dynamomqtest.NewProcessingMessage("A-101", test.NewMessageData("A-101"), test.DefaultTestDate,
+		dynamomqtest.WithVisibilityTimeout(45*time.Second))
+	unknown := dynamomqtest.NewProcessingMessage("A-101", test.NewMessageData("A-101"), test.DefaultTestDate)
+	unknown.InvisibleUntilAt = ""
+	tests := []struct {
+		name string
+		msg  *dynamomq.Message[test.MessageData]
+		opts []func(*dynamomq.ConsumerOptions)
+		// elapsed is the time elapsed on the clock of the Consumer since the message was received.
+		elapsed time.Duration
+		want    time.Duration
+	}{
+		{
+			name:    "should end at the visibility timeout of the message",
+			msg:     received,
+			elapsed: 10 * time.Second,
+			want:    35 * time.Second,
+		},
+		{
+			name:    "should end the safety margin of PerMessageTimeout before the visibility timeout of the message",
+			msg:     received,
+			opts:    []func(*dynamomq.ConsumerOptions){dynamomq.WithPerMessageTimeout(5 * time.Second)},
+			elapsed: 10 * time.Second,
+			want:    30 * time.Second,
+		},
+		{
+			name: "should end at the visibility timeout of the Consumer when the message does not tell",
+			msg:  unknown,
+			opts: []func(*dynamomq.ConsumerOptions){dynamomq.WithVisibilityTimeout(20)},
+			want: 20 * time.Second,
+		},
+		{
+			name: "should end at the default visibility timeout when neither the message nor the Consumer tells",
+			msg:  unknown,
+			want: 30 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var deleted atomic.Int32
+			remaining := make(chan time.Duration, 1)
+			opts := append([]func(*dynamomq.ConsumerOptions){
+				dynamomq.WithPollingInterval(time.Hour),
+				dynamomq.WithConcurrency(1),
+				mock.WithConsumerClock(mock.Clock{T: test.DefaultTestDate.Add(tt.elapsed)}),
+			}, tt.opts...)
+			consumer := dynamomq.NewConsumer[test.MessageData](newPerMessageTimeoutClient(tt.msg, &deleted),
+				dynamomq.ContextMessageProcessorFunc[test.MessageData](func(ctx context.Context, msg *dynamomq.Message[test.MessageData]) error {
+					deadline, ok := ctx.Deadline()
+					if !ok {
+						remaining <- 0
+						return nil
+					}
+					remaining <- time.Until(deadline)
+					return nil
+				}), opts...)
+			go func() {
+				_ = consumer.StartConsuming()
+			}()
+			if got := <-remaining; got <= tt.want-time.Second || got > tt.want {
+				t.Errorf("handler deadline in %v, want %v", got, tt.want)
+			}
+			for deleted.Load() == 0 {
+				time.Sleep(time.Millisecond)
+			}
+			if err := consumer.Shutdown(context.Background()); err != nil {
+				t.Fatalf("Shutdown() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestConsumerPerMessageTimeoutShouldDeleteMessageProcessedInTime(t *testing.T) {
+	t.Parallel()
+	msg := dynamomqtest.NewProcessingMessage("A-101", test.NewMessageData("A-101"), test.DefaultTestDate,
+		dynamomqtest.WithVisibilityTimeout(30*time.Second))
+	now := test.DefaultTestDate.Add(10 * time.Second)
+	var deleted atomic.Int32
+	remaining := make(chan time.Duration, 1)
+	consumer := dynamomq.NewConsumer[test.MessageData](newPerMessageTimeoutClient(msg, &deleted),
+		dynamomq.ContextMessageProcessorFunc[test.MessageData](func(ctx context.Context, msg *dynamomq.Message[test.MessageData]) error {
+			deadline, ok := ctx.Deadline()
+			if !ok {
+				remaining <- 0
+				return nil
+			}
+			remaining <- time.Until(deadline)
+			return nil
+		}),
+		dynamomq.WithPollingInterval(time.Hour),
+		dynamomq.WithConcurrency(1),
+		dynamomq.WithPerMessageTimeout(5*time.Second),
+		mock.WithConsumerClock(mock.Clock{T: now}))
+	go func() {
+		_ = consumer.StartConsuming()
+	}()
+	// 30s of visibility timeout, 10s already elapsed and 5s of safety margin leave 15s.
+	if got := <-remaining; got <= 14*time.Second || got > 15*time.Second {
+		t.Errorf("handler deadline in %v, want 15s", got)
+	}
+	for deleted.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if err := consumer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package dynamomq
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+)
+
+const (
+	// AttributeNameQueueEnabled holds whether the queue is enabled in the control item.
+	AttributeNameQueueEnabled = "queue_enabled"
+
+	controlItemID                      = "dynamomq-control#queue"
+	defaultQueueControlRefreshInterval = 5 * time.Second
+)
+
+// SetQueueEnabledInput represents the input parameters for pausing or resuming a queue.
+type SetQueueEnabledInput struct {
+	// Enabled is false to pause the queue and true to resume it.
+	Enabled bool
+}
+
+// SetQueueEnabledOutput represents the result of the operation to pause or resume a queue.
+// This struct is empty as the operation does not return any specific information.
+type SetQueueEnabledOutput struct{}
+
+// SetQueueEnabled pauses or resumes the queue for every client created with WithRespectQueueControl,
+// by writing a control item in the queue table. Other clients see the change within their refresh interval.
+// Sending messages is not affected, so that producers keep working while consumers are paused.
+func (c *ClientImpl[T]) SetQueueEnabled(ctx context.Context, params *SetQueueEnabledInput) (*SetQueueEnabledOutput, error) {
+	if params == nil {
+		params = &SetQueueEnabledInput{}
+	}
+	now := c.clock.Now()
+	item := c.itemKey(controlItemID)
+	item[c.schema.IDAttribute] = &types.AttributeValueMemberS{Value: controlItemID}
+	item[AttributeNameQueueEnabled] = &types.AttributeValueMemberBOOL{Value: params.Enabled}
+	item[c.schema.UpdatedAtAttribute] = &types.AttributeValueMemberS{Value: clock.FormatRFC3339Nano(now)}
+	_, err := c.dynamoDB.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(c.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		return &SetQueueEnabledOutput{}, handleDynamoDBError(err)
+	}
+	c.controlMu.Lock()
+	c.queueEnabled = params.Enabled
+	c.queueControlRefreshedAt = now
+	c.controlMu.Unlock()
+	return &SetQueueEnabledOutput{}, nil
+}
+
+// checkQueueEnabled returns a QueuePausedError if the queue has been paused with SetQueueEnabled.
+// The control item is read again only once the refresh interval has passed since it was last read.
+func (c *ClientImpl[T]) checkQueueEnabled(ctx context.Context) error {
+	if !c.respectQueueControl {
+		return nil
+	}
+	c.controlMu.Lock()
+	defer c.controlMu.Unlock()
+	now := c.clock.Now()
+	if c.queueControlRefreshedAt.IsZero() || now.Sub(c.queueControlRefreshedAt) >= c.queueControlRefreshInterval {
+		out, err := c.dynamoDB.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName:      aws.String(c.tableName),
+			Key:            c.itemKey(controlItemID),
+			ConsistentRead: aws.Bool(true),
+		})
+		if err != nil {
+			return handleDynamoDBError(err)
+		}
+		c.queueEnabled = true
+		if enabled, ok := out.Item[AttributeNameQueueEnabled].(*types.AttributeValueMemberBOOL); ok {
+			c.queueEnabled = enabled.Value
+		}
+		c.queueControlRefreshedAt = now
+	}
+	if !c.queueEnabled {
+		return &QueuePausedError{}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package dynamomq_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+// steppingClock is a clock whose time is advanced by the test.
+type steppingClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *steppingClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *steppingClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestDynamoMQClientRespectQueueControl(t *testing.T) {
+	t.Parallel()
+	var (
+		reads   int
+		enabled = false
+	)
+	clk := &steppingClock{now: test.DefaultTestDate}
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithRespectQueueControl(true),
+		dynamomq.WithQueueControlRefreshInterval(5*time.Second),
+		mock.WithClock(clk),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+				reads++
+				return &dynamodb.GetItemOutput{
+					Item: map[string]types.AttributeValue{
+						dynamomq.AttributeNameQueueEnabled: &types.AttributeValueMemberBOOL{Value: enabled},
+					},
+				}, nil
+			},
+			QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+				return &dynamodb.QueryOutput{}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	receive := func() error {
+		_, err := client.ReceiveMessage(context.Background(), nil)
+		return err
+	}
+	test.AssertError(t, receive(), &dynamomq.QueuePausedError{}, "ReceiveMessage()")
+
+	enabled = true
+	clk.Advance(4 * time.Second)
+	test.AssertError(t, receive(), &dynamomq.QueuePausedError{}, "ReceiveMessage() before refresh")
+	if reads != 1 {
+		t.Errorf("control item reads = %d, want 1", reads)
+	}
+
+	clk.Advance(time.Second)
+	test.AssertError(t, receive(), dynamomq.ErrEmptyQueue, "ReceiveMessage() after refresh")
+	if reads != 2 {
+		t.Errorf("control item reads = %d, want 2", reads)
+	}
+}
+
+func TestDynamoMQClientSetQueueEnabled(t *testing.T) {
+	t.Parallel()
+	var put *dynamodb.PutItemInput
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithRespectQueueControl(true),
+		mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			PutItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+				put = params
+				return &dynamodb.PutItemOutput{}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	if _, err := client.SetQueueEnabled(context.Background(), &dynamomq.SetQueueEnabledInput{Enabled: false}); err != nil {
+		t.Fatalf("SetQueueEnabled() error = %v", err)
+	}
+	test.AssertDeepEqual(t, put.Item[dynamomq.AttributeNameQueueEnabled], &types.AttributeValueMemberBOOL{Value: false}, "control item")
+	if _, ok := put.Item[dynamomq.AttributeNameQueueType]; ok {
+		t.Errorf("control item has a queue type: %v", put.Item)
+	}
+	// The client that paused the queue sees the change without reading the control item.
+	_, err = client.ReceiveMessage(context.Background(), nil)
+	test.AssertError(t, err, &dynamomq.QueuePausedError{}, "ReceiveMessage()")
+}
+
+func TestConsumerKeepsPollingWhileQueueIsPaused(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	client := &mock.Client[test.MessageData]{
+		ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+			calls.Add(1)
+			return nil, &dynamomq.QueuePausedError{}
+		},
+	}
+	consumer := dynamomq.NewConsumer[test.MessageData](client, &CountProcessor[test.MessageData]{},
+		dynamomq.WithPollingInterval(time.Millisecond))
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- consumer.StartConsuming()
+	}()
+	for calls.Load() < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	if err := consumer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := <-errCh; !errors.Is(err, dynamomq.ErrConsumerClosed) {
+		t.Errorf("StartConsuming() error = %v, want %v", err, dynamomq.ErrConsumerClosed)
+	}
+}
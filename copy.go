@@ -0,0 +1,175 @@
+package dynamomq
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// CopyMessageInput represents the input parameters for copying a specific message to another queue table.
+type CopyMessageInput struct {
+	// ID is the unique identifier of the message to be copied.
+	ID string
+	// TargetTableName is the name of the table the message is copied to. It must use the same TableSchema
+	// as the table of the client. If it is empty, the message is copied within the table of the client, which requires NewID.
+	TargetTableName string
+	// NewID is the identifier of the copy. If it is empty, the copy keeps the ID of the source message.
+	NewID string
+	// ResetSystemInfo makes the copy a new READY message in the STANDARD queue, with a receive count of zero,
+	// instead of keeping the queue type, the receive count and the timestamps of the source message.
+	ResetSystemInfo bool
+}
+
+// CopyMessageOutput represents the result of the operation to copy a message.
+// This struct uses the generic type T and contains information about the copy.
+type CopyMessageOutput[T any] struct {
+	// CopiedMessage is a pointer to the Message type containing information about the copy written to the target table.
+	// The type T determines the format of the message content.
+	CopiedMessage *Message[T]
+}
+
+// CopyMessage copies a specific message to another table, for example to replay a message of a production DLQ
+// in the queue of a staging environment. The copy is written only if no message with its ID exists in the target table,
+// otherwise an IDDuplicatedError is returned.
+func (c *ClientImpl[T]) CopyMessage(ctx context.Context, params *CopyMessageInput) (*CopyMessageOutput[T], error) {
+	ctx, cancel := c.withDefaultTimeout(ctx, "CopyMessage")
+	defer cancel()
+	if params == nil {
+		params = &CopyMessageInput{}
+	}
+	copied, _, err := c.copyMessage(ctx, params)
+	if err != nil {
+		return &CopyMessageOutput[T]{}, err
+	}
+	return &CopyMessageOutput[T]{
+		CopiedMessage: copied,
+	}, nil
+}
+
+// MoveMessageInput represents the input parameters for moving a specific message to another queue table.
+// Its fields have the same meaning as those of CopyMessageInput.
+type MoveMessageInput struct {
+	// ID is the unique identifier of the message to be moved.
+	ID string
+	// TargetTableName is the name of the table the message is moved to.
+	TargetTableName string
+	// NewID is the identifier of the message in the target table. If it is empty, the message keeps its ID.
+	NewID string
+	// ResetSystemInfo makes the moved message a new READY message in the STANDARD queue.
+	ResetSystemInfo bool
+}
+
+// MoveMessageOutput represents the result of the operation to move a message.
+// This struct uses the generic type T and contains information about the moved message.
+type MoveMessageOutput[T any] struct {
+	// MovedMessage is a pointer to the Message type containing information about the message written to the target table.
+	// The type T determines the format of the message content.
+	MovedMessage *Message[T]
+}
+
+// MoveMessage copies a specific message to another table as CopyMessage does, and then deletes the source message.
+// The source is deleted only once the copy has been written, and only if it has not been updated since it was read;
+// otherwise a VersionConflictError is returned and both messages are kept.
+// Once the source is deleted, the OnDeleted hook is invoked for it.
+func (c *ClientImpl[T]) MoveMessage(ctx context.Context, params *MoveMessageInput) (*MoveMessageOutput[T], error) {
+	ctx, cancel := c.withDefaultTimeout(ctx, "MoveMessage")
+	defer cancel()
+	if params == nil {
+		params = &MoveMessageInput{}
+	}
+	copied, source, err := c.copyMessage(ctx, &CopyMessageInput{
+		ID:              params.ID,
+		TargetTableName: params.TargetTableName,
+		NewID:           params.NewID,
+		ResetSystemInfo: params.ResetSystemInfo,
+	})
+	if err != nil {
+		return &MoveMessageOutput[T]{}, err
+	}
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.Name(c.schema.VersionAttribute).Equal(expression.Value(source.Version))).
+		Build()
+	if err != nil {
+		return &MoveMessageOutput[T]{}, BuildingExpressionError{Cause: err}
+	}
+	_, err = c.dynamoDB.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:                 aws.String(c.tableName),
+		Key:                       c.itemKey(source.ID),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		var cause *types.ConditionalCheckFailedException
+		if errors.As(err, &cause) {
+			return &MoveMessageOutput[T]{}, VersionConflictError{ID: source.ID, Version: source.Version}
+		}
+		return &MoveMessageOutput[T]{}, handleDynamoDBError(err)
+	}
+	if source.InFlightSlot {
+		c.releaseInFlightSlot(ctx, source.ID)
+	}
+	c.hooks.deleted(ctx, &DeleteMessageInput{ID: source.ID, StrictExistenceCheck: true}, &DeleteMessageOutput{})
+	return &MoveMessageOutput[T]{
+		MovedMessage: copied,
+	}, nil
+}
+
+func (c *ClientImpl[T]) copyMessage(ctx context.Context, params *CopyMessageInput) (copied, source *Message[T], err error) {
+	if params.ID == "" {
+		return nil, nil, &IDNotProvidedError{}
+	}
+	targetTableName := params.TargetTableName
+	if targetTableName == "" {
+		targetTableName = c.tableName
+	}
+	retrieved, err := c.GetMessage(ctx, &GetMessageInput{
+		ID: params.ID,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if retrieved.Message == nil {
+		return nil, nil, &IDNotFoundError{}
+	}
+	source = retrieved.Message
+	id := source.ID
+	if params.NewID != "" {
+		id = params.NewID
+	}
+	if targetTableName == c.tableName && id == source.ID {
+		return nil, nil, &IDDuplicatedError{}
+	}
+	if params.ResetSystemInfo {
+		copied = NewMessage(id, source.Data, c.clock.Now())
+		copied.PayloadSchemaVersion = source.PayloadSchemaVersion
+	} else {
+		duplicate := *source
+		duplicate.ID = id
+		duplicate.InFlightSlot = false
+		copied = &duplicate
+	}
+	item, err := c.marshalItem(copied)
+	if err != nil {
+		return nil, nil, MarshalingAttributeError{Cause: err}
+	}
+	expr := c.static.keyNotExists
+	_, err = c.dynamoDB.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                aws.String(targetTableName),
+		Item:                     item,
+		ConditionExpression:      expr.Condition(),
+		ExpressionAttributeNames: expr.Names(),
+	})
+	if err != nil {
+		var cause *types.ConditionalCheckFailedException
+		if errors.As(err, &cause) {
+			return nil, nil, &IDDuplicatedError{}
+		}
+		return nil, nil, handleDynamoDBError(err)
+	}
+	return copied, source, nil
+}
@@ -0,0 +1,141 @@
+package dynamomq_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+	"github.com/upsidr/dynamotest"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/constant"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestDynamoMQClientCopyAndMoveMessageAcrossTables(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Hour)
+	sourceTableName, raw, clean := SetupDynamoDB(t,
+		newPutRequestWithDLQItem("B-101", test.DefaultTestDate),
+		newPutRequestWithDLQItem("B-102", test.DefaultTestDate),
+	)
+	defer clean()
+	targetTableName := constant.DefaultTableName + "-" + uuid.NewString()
+	dynamotest.PrepTable(t, raw, dynamotest.InitialTableSetup{
+		Table: dynamomq.NewCreateTableInput(&dynamomq.CreateQueueTableInput{TableName: targetTableName}),
+	})
+	newClient := func(tableName string) dynamomq.Client[test.MessageData] {
+		client, _ := prepareTestClient(context.Background(), t, func(t *testing.T) (string, *dynamodb.Client, func()) {
+			return tableName, raw, func() {}
+		}, mock.Clock{T: now}, false, nil, nil, nil)
+		return client
+	}
+	source, target := newClient(sourceTableName), newClient(targetTableName)
+	ctx := context.Background()
+
+	copied, err := source.CopyMessage(ctx, &dynamomq.CopyMessageInput{ID: "B-101", TargetTableName: targetTableName})
+	if err != nil {
+		t.Fatalf("CopyMessage() error = %v", err)
+	}
+	test.AssertDeepEqual(t, copied.CopiedMessage, NewTestMessageItemAsDLQ("B-101", test.DefaultTestDate), "CopyMessage()")
+	_, err = source.CopyMessage(ctx, &dynamomq.CopyMessageInput{ID: "B-101", TargetTableName: targetTableName})
+	test.AssertError(t, err, dynamomq.ErrIDDuplicated, "CopyMessage() to an existing ID")
+
+	moved, err := source.MoveMessage(ctx, &dynamomq.MoveMessageInput{
+		ID:              "B-102",
+		TargetTableName: targetTableName,
+		NewID:           "C-102",
+		ResetSystemInfo: true,
+	})
+	if err != nil {
+		t.Fatalf("MoveMessage() error = %v", err)
+	}
+	test.AssertDeepEqual(t, moved.MovedMessage, dynamomq.NewMessage("C-102", test.NewMessageData("B-102"), now), "MoveMessage()")
+
+	for _, tc := range []struct {
+		client dynamomq.Client[test.MessageData]
+		id     string
+		exists bool
+	}{
+		{source, "B-101", true},
+		{source, "B-102", false},
+		{target, "B-101", true},
+		{target, "C-102", true},
+	} {
+		got, err := tc.client.GetMessage(ctx, &dynamomq.GetMessageInput{ID: tc.id})
+		if err != nil {
+			t.Fatalf("GetMessage() error = %v", err)
+		}
+		if exists := got.Message != nil; exists != tc.exists {
+			t.Errorf("message %s exists = %v, want %v", tc.id, exists, tc.exists)
+		}
+	}
+	received, err := target.ReceiveMessage(ctx, nil)
+	if err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	if received.ReceivedMessage.ID != "C-102" {
+		t.Errorf("ReceiveMessage() id = %s, want C-102", received.ReceivedMessage.ID)
+	}
+}
+
+func TestDynamoMQClientMoveMessageKeepsSource(t *testing.T) {
+	t.Parallel()
+	message := NewTestMessageItemAsDLQ("B-101", test.DefaultTestDate)
+	tests := []struct {
+		name        string
+		putErr      error
+		deleteErr   error
+		wantErr     error
+		wantDeletes int
+	}{
+		{
+			name:    "should not delete the source when the copy fails",
+			putErr:  &types.ConditionalCheckFailedException{},
+			wantErr: dynamomq.ErrIDDuplicated,
+		},
+		{
+			name:        "should return VersionConflictError when the source was updated during the move",
+			deleteErr:   &types.ConditionalCheckFailedException{},
+			wantErr:     dynamomq.VersionConflictError{ID: "B-101", Version: 1},
+			wantDeletes: 1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			deletes := 0
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+				mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+						return &dynamodb.GetItemOutput{Item: dynamomqtest.MarshalMap(message)}, nil
+					},
+					PutItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+						if *params.TableName != "staging" {
+							t.Errorf("PutItem() table = %s, want staging", *params.TableName)
+						}
+						return &dynamodb.PutItemOutput{}, tt.putErr
+					},
+					DeleteItemFunc: func(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+						deletes++
+						return &dynamodb.DeleteItemOutput{}, tt.deleteErr
+					},
+				}))
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			_, err = client.MoveMessage(context.Background(), &dynamomq.MoveMessageInput{ID: "B-101", TargetTableName: "staging"})
+			test.AssertError(t, err, tt.wantErr, "MoveMessage()")
+			if deletes != tt.wantDeletes {
+				t.Errorf("DeleteItem() calls = %d, want %d", deletes, tt.wantDeletes)
+			}
+		})
+	}
+}
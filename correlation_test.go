@@ -0,0 +1,184 @@
+package dynamomq_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestProducerProduceShouldSetCorrelationID(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		params *dynamomq.ProduceInput[test.MessageData]
+		want   string
+	}{
+		{
+			name:   "should use the correlation ID of the context",
+			ctx:    dynamomq.ContextWithCorrelationID(context.Background(), "corr-ctx"),
+			params: &dynamomq.ProduceInput[test.MessageData]{},
+			want:   "corr-ctx",
+		},
+		{
+			name:   "should prefer the correlation ID of the input",
+			ctx:    dynamomq.ContextWithCorrelationID(context.Background(), "corr-ctx"),
+			params: &dynamomq.ProduceInput[test.MessageData]{CorrelationID: "corr-input"},
+			want:   "corr-input",
+		},
+		{
+			name:   "should leave the correlation ID empty without one",
+			ctx:    context.Background(),
+			params: &dynamomq.ProduceInput[test.MessageData]{},
+			want:   "",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got string
+			producer := dynamomq.NewProducer[test.MessageData](&mock.Client[test.MessageData]{
+				SendMessageFunc: func(ctx context.Context,
+					params *dynamomq.SendMessageInput[test.MessageData]) (*dynamomq.SendMessageOutput[test.MessageData], error) {
+					got = params.CorrelationID
+					return &dynamomq.SendMessageOutput[test.MessageData]{}, nil
+				},
+			})
+			if _, err := producer.Produce(tt.ctx, tt.params); err != nil {
+				t.Fatalf("Produce() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("SendMessage() CorrelationID = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageCorrelationIDShouldBeStored(t *testing.T) {
+	t.Parallel()
+	m := NewTestMessageItemAsReady("A-101", test.DefaultTestDate)
+	m.CorrelationID = "corr-1"
+	item := dynamomqtest.MarshalMap(m)
+	if _, ok := item[dynamomq.AttributeNameCorrelationID]; !ok {
+		t.Fatalf("MarshalMap() has no %s attribute", dynamomq.AttributeNameCorrelationID)
+	}
+	var got dynamomq.Message[test.MessageData]
+	if err := attributevalue.UnmarshalMap(item, &got); err != nil {
+		t.Fatalf("UnmarshalMap() error = %v", err)
+	}
+	if got.CorrelationID != "corr-1" {
+		t.Errorf("UnmarshalMap() CorrelationID = %v, want corr-1", got.CorrelationID)
+	}
+}
+
+func TestConsumerShouldInjectCorrelationIDIntoContexts(t *testing.T) {
+	t.Parallel()
+	message := NewTestMessageItemAsReady("A-101", test.DefaultTestDate)
+	message.CorrelationID = "corr-1"
+	deleted := make(chan string, 1)
+	client := &mock.Client[test.MessageData]{
+		ReceiveMessageFunc: newSingleMessageReceiver(message),
+		DeleteMessageFunc: func(ctx context.Context, params *dynamomq.DeleteMessageInput) (*dynamomq.DeleteMessageOutput, error) {
+			correlationID, _ := dynamomq.CorrelationIDFromContext(ctx)
+			deleted <- correlationID
+			return &dynamomq.DeleteMessageOutput{}, nil
+		},
+	}
+	handled := make(chan string, 1)
+	processor := dynamomq.ContextMessageProcessorFunc[test.MessageData](func(ctx context.Context, msg *dynamomq.Message[test.MessageData]) error {
+		correlationID, _ := dynamomq.CorrelationIDFromContext(ctx)
+		handled <- correlationID
+		return nil
+	})
+	consumer := dynamomq.NewConsumer[test.MessageData](client, processor, dynamomq.WithPollingInterval(time.Millisecond))
+	go func() {
+		_ = consumer.StartConsuming()
+	}()
+	defer func() {
+		_ = consumer.Shutdown(context.Background())
+	}()
+	for name, ch := range map[string]chan string{"the handler": handled, "DeleteMessage": deleted} {
+		select {
+		case got := <-ch:
+			if got != "corr-1" {
+				t.Errorf("correlation ID in the context of %s = %v, want corr-1", name, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s was not called", name)
+		}
+	}
+}
+
+// newSingleMessageReceiver returns a ReceiveMessageFunc receiving the message once, and then finding the queue empty.
+func newSingleMessageReceiver(message *dynamomq.Message[test.MessageData]) func(ctx context.Context,
+	params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+	received := make(chan struct{})
+	return func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+		select {
+		case <-received:
+			return nil, &dynamomq.EmptyQueueError{}
+		default:
+			close(received)
+			return &dynamomq.ReceiveMessageOutput[test.MessageData]{ReceivedMessage: message}, nil
+		}
+	}
+}
+
+func TestCorrelationIDRoundTrip(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	deleted := make(chan string, 1)
+	client, clean := prepareTestClient(ctx, t, NewSetupFunc(), clock.RealClock{}, false, nil, nil, nil,
+		dynamomq.WithHooks(&dynamomq.Hooks[test.MessageData]{
+			OnDeleted: func(ctx context.Context, params *dynamomq.DeleteMessageInput, out *dynamomq.DeleteMessageOutput) {
+				correlationID, _ := dynamomq.CorrelationIDFromContext(ctx)
+				deleted <- correlationID
+			},
+		}))
+	defer clean()
+	producer := dynamomq.NewProducer[test.MessageData](client)
+	if _, err := producer.Produce(dynamomq.ContextWithCorrelationID(ctx, "corr-1"), &dynamomq.ProduceInput[test.MessageData]{
+		Data: test.NewMessageData("A-101"),
+	}); err != nil {
+		t.Fatalf("Produce() error = %v", err)
+	}
+	handled := make(chan *dynamomq.Message[test.MessageData], 1)
+	processor := dynamomq.ContextMessageProcessorFunc[test.MessageData](func(ctx context.Context, msg *dynamomq.Message[test.MessageData]) error {
+		if correlationID, _ := dynamomq.CorrelationIDFromContext(ctx); correlationID != msg.CorrelationID {
+			t.Errorf("correlation ID in the context of the handler = %v, want %v", correlationID, msg.CorrelationID)
+		}
+		handled <- msg
+		return nil
+	})
+	consumer := dynamomq.NewConsumer[test.MessageData](client, processor, dynamomq.WithPollingInterval(10*time.Millisecond))
+	go func() {
+		_ = consumer.StartConsuming()
+	}()
+	defer func() {
+		_ = consumer.Shutdown(ctx)
+	}()
+	select {
+	case msg := <-handled:
+		if msg.CorrelationID != "corr-1" {
+			t.Errorf("received CorrelationID = %v, want corr-1", msg.CorrelationID)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("the message was not consumed")
+	}
+	select {
+	case got := <-deleted:
+		if got != "corr-1" {
+			t.Errorf("correlation ID in the context of OnDeleted = %v, want corr-1", got)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("the message was not deleted")
+	}
+}
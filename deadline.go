@@ -0,0 +1,50 @@
+package dynamomq
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	// AttributeNameProcessingDeadline holds the processing deadline of the message in seconds.
+	AttributeNameProcessingDeadline = "processing_deadline"
+	// ReasonDeadlineExceeded is the reason given to the DLQNotifier for a message moved to the DLQ
+	// because it was older than its processing deadline.
+	ReasonDeadlineExceeded = "deadline exceeded"
+)
+
+// deadlineExceeded reports whether the message is older than its processing deadline, or the deadline of the client.
+// The messages of the DLQ have no deadline.
+func (c *ClientImpl[T]) deadlineExceeded(message *Message[T], now time.Time) bool {
+	if message.IsDLQ() || message.ProcessingDeadline < 0 {
+		return false
+	}
+	deadline := c.processingDeadline
+	if message.ProcessingDeadline > 0 {
+		deadline = time.Duration(message.ProcessingDeadline) * time.Second
+	}
+	if deadline <= 0 {
+		return false
+	}
+	sentAt, err := message.ParsedSentAt()
+	if err != nil {
+		return false
+	}
+	return now.Sub(sentAt) > deadline
+}
+
+// moveDeadlineExceeded moves a message past its processing deadline to the DLQ.
+// A conflict or a missing message means that another client has changed the message in the meantime,
+// so it is left to that client.
+func (c *ClientImpl[T]) moveDeadlineExceeded(ctx context.Context, message *Message[T]) error {
+	_, err := c.MoveMessageToDLQ(ctx, &MoveMessageToDLQInput{
+		ID:     message.ID,
+		Reason: ReasonDeadlineExceeded,
+	})
+	var conflict *ConditionalCheckFailedError
+	if errors.As(err, &conflict) || errors.Is(err, ErrIDNotFound) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,178 @@
+package dynamomq_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestDynamoMQClientProcessingDeadline(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		deadline     int
+		now          time.Time
+		wantReceived string
+		wantMoved    []string
+	}{
+		{
+			name:         "should receive a message at its deadline",
+			now:          test.DefaultTestDate.Add(time.Minute),
+			wantReceived: "A-101",
+		},
+		{
+			name:         "should move a message past its deadline to DLQ and receive the next one",
+			now:          test.DefaultTestDate.Add(time.Minute + time.Nanosecond),
+			wantReceived: "A-102",
+			wantMoved:    []string{"A-101"},
+		},
+		{
+			name:         "should receive a message exempted from the deadline",
+			deadline:     -1,
+			now:          test.DefaultTestDate.Add(time.Hour),
+			wantReceived: "A-101",
+		},
+		{
+			name:         "should receive a message within its own deadline",
+			deadline:     120,
+			now:          test.DefaultTestDate.Add(2 * time.Minute),
+			wantReceived: "A-101",
+		},
+		{
+			name:         "should move a message past its own deadline to DLQ",
+			deadline:     30,
+			now:          test.DefaultTestDate.Add(30*time.Second + time.Nanosecond),
+			wantReceived: "A-102",
+			wantMoved:    []string{"A-101"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			first := NewTestMessageItemAsReady("A-101", test.DefaultTestDate)
+			first.ProcessingDeadline = tt.deadline
+			items := map[string]map[string]types.AttributeValue{
+				"A-101": dynamomqtest.MarshalMap(first),
+				"A-102": dynamomqtest.MarshalMap(NewTestMessageItemAsReady("A-102", tt.now)),
+			}
+			var (
+				moved   []string
+				reasons []string
+			)
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+				dynamomq.WithProcessingDeadline(time.Minute),
+				dynamomq.WithDLQNotifier(dynamomq.DLQNotifierFunc(func(ctx context.Context, n *dynamomq.DLQNotification) error {
+					reasons = append(reasons, n.Reason)
+					return nil
+				})),
+				mock.WithClock(mock.Clock{T: tt.now}),
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+						out := &dynamodb.QueryOutput{}
+						for _, id := range []string{"A-101", "A-102"} {
+							if item, ok := items[id]; ok {
+								out.Items = append(out.Items, item)
+							}
+						}
+						return out, nil
+					},
+					GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+						id := params.Key[dynamomq.AttributeNameID].(*types.AttributeValueMemberS).Value
+						return &dynamodb.GetItemOutput{Item: items[id]}, nil
+					},
+					UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+						id := params.Key[dynamomq.AttributeNameID].(*types.AttributeValueMemberS).Value
+						if containsName(params.ExpressionAttributeNames, dynamomq.AttributeNameQueueType) {
+							moved = append(moved, id)
+							delete(items, id)
+							return &dynamodb.UpdateItemOutput{Attributes: dynamomqtest.MarshalMap(NewTestMessageItemAsDLQ(id, tt.now))}, nil
+						}
+						return &dynamodb.UpdateItemOutput{Attributes: dynamomqtest.MarshalMap(NewTestMessageItemAsProcessing(id, tt.now))}, nil
+					},
+				}))
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			out, err := client.ReceiveMessage(context.Background(), &dynamomq.ReceiveMessageInput{})
+			if err != nil {
+				t.Fatalf("ReceiveMessage() error = %v", err)
+			}
+			if out.ReceivedMessage.ID != tt.wantReceived {
+				t.Errorf("ReceiveMessage() id = %s, want %s", out.ReceivedMessage.ID, tt.wantReceived)
+			}
+			test.AssertDeepEqual(t, moved, tt.wantMoved, "moved to DLQ")
+			if len(tt.wantMoved) > 0 {
+				test.AssertDeepEqual(t, reasons, []string{dynamomq.ReasonDeadlineExceeded}, "DLQ notification reasons")
+			}
+		})
+	}
+}
+
+func containsName(names map[string]string, name string) bool {
+	for _, v := range names {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDynamoMQClientSendMessageProcessingDeadline(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		send *dynamomq.SendMessageInput[test.MessageData]
+		want int
+	}{
+		{
+			name: "should round the deadline up to seconds",
+			send: &dynamomq.SendMessageInput[test.MessageData]{ID: "A-101", ProcessingDeadline: 90*time.Second + time.Millisecond},
+			want: 91,
+		},
+		{
+			name: "should exempt the message from the deadline",
+			send: &dynamomq.SendMessageInput[test.MessageData]{ID: "A-101", ProcessingDeadline: time.Minute, SkipProcessingDeadline: true},
+			want: -1,
+		},
+		{
+			name: "should leave the deadline to the client",
+			send: &dynamomq.SendMessageInput[test.MessageData]{ID: "A-101"},
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+				mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+						return &dynamodb.GetItemOutput{}, nil
+					},
+					PutItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+						return &dynamodb.PutItemOutput{}, nil
+					},
+				}))
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			out, err := client.SendMessage(context.Background(), tt.send)
+			if err != nil {
+				t.Fatalf("SendMessage() error = %v", err)
+			}
+			if out.SentMessage.ProcessingDeadline != tt.want {
+				t.Errorf("SendMessage() processing deadline = %d, want %d", out.SentMessage.ProcessingDeadline, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package dynamomq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/vvatanabe/dynamomq/internal/constant"
+)
+
+// DescribeQueueInput represents the input parameters for describing a DynamoDB-based queue.
+type DescribeQueueInput struct {
+	// ExpectedTTLAttribute is the attribute on which Time to Live is expected to be enabled.
+	// When it is empty, TTLEnabled reports whether TTL is enabled on any attribute.
+	ExpectedTTLAttribute string
+}
+
+// DescribeQueueOutput represents the health of the table and the queueing index of a queue.
+// When a DescribeTable or DescribeTimeToLive call is denied, the fields it would have filled are left
+// at their zero values and the reason is added to Warnings.
+type DescribeQueueOutput struct {
+	// TableName is the name of the table used by the client.
+	TableName string `json:"table_name"`
+	// TableStatus is the status of the table, such as ACTIVE or UPDATING.
+	TableStatus types.TableStatus `json:"table_status"`
+	// BillingMode is the billing mode of the table.
+	BillingMode types.BillingMode `json:"billing_mode"`
+	// ItemCount is the approximate number of items in the table. DynamoDB updates it about every six hours.
+	ItemCount int64 `json:"item_count"`
+	// IndexName is the name of the queueing index used by the client.
+	IndexName string `json:"index_name"`
+	// IndexStatus is the status of the queueing index. It is empty if the index does not exist.
+	IndexStatus types.IndexStatus `json:"index_status"`
+	// IndexBackfilling is true while the queueing index is being backfilled after its creation.
+	IndexBackfilling bool `json:"index_backfilling"`
+	// TTLStatus is the status of Time to Live on the table.
+	TTLStatus types.TimeToLiveStatus `json:"ttl_status"`
+	// TTLAttribute is the attribute on which Time to Live is enabled, if any.
+	TTLAttribute string `json:"ttl_attribute"`
+	// TTLEnabled is true if Time to Live is enabled on the expected attribute.
+	TTLEnabled bool `json:"ttl_enabled"`
+	// Configuration is the effective configuration of the client.
+	Configuration QueueConfiguration `json:"configuration"`
+	// Warnings lists the parts of the description that could not be obtained and the problems that were found.
+	Warnings []string `json:"warnings"`
+}
+
+// QueueConfiguration represents the effective configuration of a DynamoMQ client.
+type QueueConfiguration struct {
+	// DefaultQueueType is the queue type used by ReceiveMessage when none is given.
+	DefaultQueueType QueueType `json:"default_queue_type"`
+	// DefaultVisibilityTimeout is the visibility timeout in seconds used by ReceiveMessage when none is given.
+	DefaultVisibilityTimeout int `json:"default_visibility_timeout"`
+	// UseFIFO is true if the queue behaves as a First-In-First-Out (FIFO) queue.
+	UseFIFO bool `json:"use_fifo"`
+	// MaximumReceives is the maximum number of receives before a message is moved to the DLQ. Zero means unlimited.
+	MaximumReceives int `json:"maximum_receives"`
+	// SkipCorruptMessages is true if items that cannot be unmarshaled are skipped.
+	SkipCorruptMessages bool `json:"skip_corrupt_messages"`
+	// TableSchema is the attribute names and the queueing index name used in the table.
+	TableSchema TableSchema `json:"table_schema"`
+}
+
+// DescribeQueue reports the health of the table and the queueing index along with the effective configuration of the client.
+// It only calls DescribeTable and DescribeTimeToLive. If either call is denied by IAM, a partial result is returned
+// with a warning instead of an error.
+func (c *ClientImpl[T]) DescribeQueue(ctx context.Context, params *DescribeQueueInput) (*DescribeQueueOutput, error) {
+	if params == nil {
+		params = &DescribeQueueInput{}
+	}
+	out := &DescribeQueueOutput{
+		TableName: c.tableName,
+		IndexName: c.schema.QueueingIndexName,
+		Configuration: QueueConfiguration{
+			DefaultQueueType:         QueueTypeStandard,
+			DefaultVisibilityTimeout: constant.DefaultVisibilityTimeoutInSeconds,
+			UseFIFO:                  c.useFIFO,
+			MaximumReceives:          c.maximumReceives,
+			SkipCorruptMessages:      c.skipCorruptMessages,
+			TableSchema:              c.schema,
+		},
+	}
+	table, err := c.dynamoDB.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+		TableName: &c.tableName,
+	})
+	switch {
+	case isAccessDenied(err):
+		out.Warnings = append(out.Warnings, fmt.Sprintf("DescribeTable was denied: %v", err))
+	case err != nil:
+		return out, handleDynamoDBError(err)
+	default:
+		c.describeTable(out, table.Table)
+	}
+	ttl, err := c.dynamoDB.DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{
+		TableName: &c.tableName,
+	})
+	switch {
+	case isAccessDenied(err):
+		out.Warnings = append(out.Warnings, fmt.Sprintf("DescribeTimeToLive was denied: %v", err))
+	case err != nil:
+		return out, handleDynamoDBError(err)
+	default:
+		describeTimeToLive(out, ttl.TimeToLiveDescription, params.ExpectedTTLAttribute)
+	}
+	return out, nil
+}
+
+func (c *ClientImpl[T]) describeTable(out *DescribeQueueOutput, table *types.TableDescription) {
+	if table == nil {
+		return
+	}
+	out.TableStatus = table.TableStatus
+	out.BillingMode = types.BillingModeProvisioned
+	if table.BillingModeSummary != nil && table.BillingModeSummary.BillingMode != "" {
+		out.BillingMode = table.BillingModeSummary.BillingMode
+	}
+	if table.ItemCount != nil {
+		out.ItemCount = *table.ItemCount
+	}
+	for _, gsi := range table.GlobalSecondaryIndexes {
+		if gsi.IndexName == nil || *gsi.IndexName != c.schema.QueueingIndexName {
+			continue
+		}
+		out.IndexStatus = gsi.IndexStatus
+		out.IndexBackfilling = gsi.Backfilling != nil && *gsi.Backfilling
+	}
+	if out.TableStatus != types.TableStatusActive {
+		out.Warnings = append(out.Warnings, fmt.Sprintf("table is %s", out.TableStatus))
+	}
+	switch {
+	case out.IndexStatus == "":
+		out.Warnings = append(out.Warnings, fmt.Sprintf("index '%s' does not exist", c.schema.QueueingIndexName))
+	case out.IndexBackfilling:
+		out.Warnings = append(out.Warnings, fmt.Sprintf("index '%s' is backfilling", c.schema.QueueingIndexName))
+	case out.IndexStatus != types.IndexStatusActive:
+		out.Warnings = append(out.Warnings, fmt.Sprintf("index '%s' is %s", c.schema.QueueingIndexName, out.IndexStatus))
+	}
+}
+
+func describeTimeToLive(out *DescribeQueueOutput, ttl *types.TimeToLiveDescription, expectedAttribute string) {
+	if ttl == nil {
+		return
+	}
+	out.TTLStatus = ttl.TimeToLiveStatus
+	if ttl.AttributeName != nil {
+		out.TTLAttribute = *ttl.AttributeName
+	}
+	out.TTLEnabled = out.TTLStatus == types.TimeToLiveStatusEnabled &&
+		(expectedAttribute == "" || expectedAttribute == out.TTLAttribute)
+	if expectedAttribute != "" && !out.TTLEnabled {
+		out.Warnings = append(out.Warnings, fmt.Sprintf("TTL is not enabled on '%s'", expectedAttribute))
+	}
+}
+
+func isAccessDenied(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "AccessDeniedException"
+}
@@ -0,0 +1,152 @@
+package dynamomq_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestDynamoMQClientDescribeQueue(t *testing.T) {
+	t.Parallel()
+	accessDenied := &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "denied"}
+	healthyTable := func() *types.TableDescription {
+		table := newActiveTableDescription()
+		table.ItemCount = aws.Int64(42)
+		table.BillingModeSummary = &types.BillingModeSummary{BillingMode: types.BillingModePayPerRequest}
+		table.GlobalSecondaryIndexes[0].IndexStatus = types.IndexStatusActive
+		return table
+	}
+	enabledTTL := &types.TimeToLiveDescription{
+		AttributeName:    aws.String("expires_at"),
+		TimeToLiveStatus: types.TimeToLiveStatusEnabled,
+	}
+	tests := []struct {
+		name     string
+		table    *types.TableDescription
+		tableErr error
+		ttl      *types.TimeToLiveDescription
+		ttlErr   error
+		input    *dynamomq.DescribeQueueInput
+		want     func(out *dynamomq.DescribeQueueOutput)
+		wantErr  bool
+	}{
+		{
+			name:  "should report a healthy queue",
+			table: healthyTable(),
+			ttl:   enabledTTL,
+			input: &dynamomq.DescribeQueueInput{ExpectedTTLAttribute: "expires_at"},
+			want: func(out *dynamomq.DescribeQueueOutput) {
+				out.TableStatus = types.TableStatusActive
+				out.BillingMode = types.BillingModePayPerRequest
+				out.ItemCount = 42
+				out.IndexStatus = types.IndexStatusActive
+				out.TTLStatus = types.TimeToLiveStatusEnabled
+				out.TTLAttribute = "expires_at"
+				out.TTLEnabled = true
+			},
+		},
+		{
+			name: "should warn about a backfilling index and TTL on another attribute",
+			table: func() *types.TableDescription {
+				table := healthyTable()
+				table.GlobalSecondaryIndexes[0].IndexStatus = types.IndexStatusCreating
+				table.GlobalSecondaryIndexes[0].Backfilling = aws.Bool(true)
+				return table
+			}(),
+			ttl:   enabledTTL,
+			input: &dynamomq.DescribeQueueInput{ExpectedTTLAttribute: "ttl"},
+			want: func(out *dynamomq.DescribeQueueOutput) {
+				out.TableStatus = types.TableStatusActive
+				out.BillingMode = types.BillingModePayPerRequest
+				out.ItemCount = 42
+				out.IndexStatus = types.IndexStatusCreating
+				out.IndexBackfilling = true
+				out.TTLStatus = types.TimeToLiveStatusEnabled
+				out.TTLAttribute = "expires_at"
+				out.Warnings = []string{
+					"index 'dynamo-mq-index-queue_type-sent_at' is backfilling",
+					"TTL is not enabled on 'ttl'",
+				}
+			},
+		},
+		{
+			name:     "should return a partial result when DescribeTable is denied",
+			tableErr: accessDenied,
+			ttl:      enabledTTL,
+			want: func(out *dynamomq.DescribeQueueOutput) {
+				out.TTLStatus = types.TimeToLiveStatusEnabled
+				out.TTLAttribute = "expires_at"
+				out.TTLEnabled = true
+				out.Warnings = []string{"DescribeTable was denied: api error AccessDeniedException: denied"}
+			},
+		},
+		{
+			name:   "should return a partial result when DescribeTimeToLive is denied",
+			table:  healthyTable(),
+			ttlErr: accessDenied,
+			want: func(out *dynamomq.DescribeQueueOutput) {
+				out.TableStatus = types.TableStatusActive
+				out.BillingMode = types.BillingModePayPerRequest
+				out.ItemCount = 42
+				out.IndexStatus = types.IndexStatusActive
+				out.Warnings = []string{"DescribeTimeToLive was denied: api error AccessDeniedException: denied"}
+			},
+		},
+		{
+			name:     "should return an error when the table does not exist",
+			tableErr: &types.ResourceNotFoundException{},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+				dynamomq.WithUseFIFO(true),
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					DescribeTableFunc: func(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+						if tt.tableErr != nil {
+							return nil, tt.tableErr
+						}
+						return &dynamodb.DescribeTableOutput{Table: tt.table}, nil
+					},
+					DescribeTimeToLiveFunc: func(ctx context.Context, params *dynamodb.DescribeTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error) {
+						if tt.ttlErr != nil {
+							return nil, tt.ttlErr
+						}
+						return &dynamodb.DescribeTimeToLiveOutput{TimeToLiveDescription: tt.ttl}, nil
+					},
+				}))
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			got, err := client.DescribeQueue(context.Background(), tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DescribeQueue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			want := &dynamomq.DescribeQueueOutput{
+				TableName: "dynamo-mq-table",
+				IndexName: "dynamo-mq-index-queue_type-sent_at",
+				Configuration: dynamomq.QueueConfiguration{
+					DefaultQueueType:         dynamomq.QueueTypeStandard,
+					DefaultVisibilityTimeout: 30,
+					UseFIFO:                  true,
+					TableSchema:              dynamomq.DefaultTableSchema(),
+				},
+			}
+			tt.want(want)
+			test.AssertDeepEqual(t, got, want, "DescribeQueue()")
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package dynamomq
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// MoveMessagesToDLQInput represents the input parameters for moving several messages to the DLQ at once.
+type MoveMessagesToDLQInput struct {
+	// IDs are the unique identifiers of the messages to move to the DLQ.
+	IDs []string
+	// Reason is passed to the DLQ notifier for every message moved.
+	Reason string
+}
+
+// MoveMessagesToDLQByFilterInput represents the input parameters for moving the messages of the STANDARD queue
+// that match a filter to the DLQ.
+type MoveMessagesToDLQByFilterInput struct {
+	// MinReceiveCount selects the messages received at least this number of times. Zero selects every message.
+	MinReceiveCount int
+	// Reason is passed to the DLQ notifier for every message moved.
+	Reason string
+	// MaxMessages is the maximum number of messages to move. Zero means unlimited.
+	MaxMessages int
+}
+
+// MoveMessageToDLQResult is the result of moving one message of a batch to the DLQ.
+type MoveMessageToDLQResult[T any] struct {
+	// ID is the unique identifier of the message.
+	ID string
+	// MovedMessage is the message as moved to the DLQ. It is nil if Err is set.
+	MovedMessage *Message[T]
+	// Err is the error returned by MoveMessageToDLQ for the message, such as a ConditionalCheckFailedError
+	// if the message was updated concurrently, or an IDNotFoundError if it does not exist.
+	Err error
+}
+
+// MoveMessagesToDLQOutput represents the result of the operation to move several messages to the DLQ.
+type MoveMessagesToDLQOutput[T any] struct {
+	// Results are the results of every message, in the order the messages were moved.
+	Results []MoveMessageToDLQResult[T]
+	// Moved is the number of messages moved to the DLQ.
+	Moved int
+	// Failed is the number of messages whose result has an error.
+	Failed int
+}
+
+func (o *MoveMessagesToDLQOutput[T]) add(id string, moved *MoveMessageToDLQOutput[T], err error) {
+	result := MoveMessageToDLQResult[T]{ID: id, Err: err}
+	if err != nil {
+		o.Failed++
+	} else {
+		result.MovedMessage = moved.MovedMessage
+		o.Moved++
+	}
+	o.Results = append(o.Results, result)
+}
+
+// MoveMessagesToDLQ moves the messages with the given IDs to the DLQ, one at a time like MoveMessageToDLQ.
+// The failure of a message, such as a conditional check failure because it was received concurrently,
+// is reported in its result and does not stop the others from being moved.
+// Only the cancellation of the context stops the operation, with an OperationCanceledError
+// and the results of the messages already handled.
+func (c *ClientImpl[T]) MoveMessagesToDLQ(ctx context.Context, params *MoveMessagesToDLQInput) (*MoveMessagesToDLQOutput[T], error) {
+	if params == nil {
+		params = &MoveMessagesToDLQInput{}
+	}
+	out := &MoveMessagesToDLQOutput[T]{Results: make([]MoveMessageToDLQResult[T], 0, len(params.IDs))}
+	for _, id := range params.IDs {
+		if err := ctx.Err(); err != nil {
+			return out, OperationCanceledError{Cause: err}
+		}
+		moved, err := c.MoveMessageToDLQ(ctx, &MoveMessageToDLQInput{
+			ID:     id,
+			Reason: params.Reason,
+		})
+		out.add(id, moved, err)
+	}
+	return out, nil
+}
+
+// MoveMessagesToDLQByFilter moves the messages of the STANDARD queue that match the filter to the DLQ.
+// It walks the queueing index from the oldest message, and moves every match like MoveMessagesToDLQ,
+// reporting the failure of a message in its result without stopping the operation.
+func (c *ClientImpl[T]) MoveMessagesToDLQByFilter(ctx context.Context, params *MoveMessagesToDLQByFilterInput) (*MoveMessagesToDLQOutput[T], error) {
+	if params == nil {
+		params = &MoveMessagesToDLQByFilterInput{}
+	}
+	builder := expression.NewBuilder().
+		WithKeyCondition(expression.Key(c.schema.QueueTypeAttribute).Equal(expression.Value(QueueTypeStandard))).
+		WithFilter(expression.Name(c.schema.ReceiveCountAttribute).GreaterThanEqual(expression.Value(params.MinReceiveCount))).
+		WithProjection(expression.NamesList(expression.Name(c.schema.IDAttribute)))
+	expr, err := c.buildExpression(builder)
+	if err != nil {
+		return &MoveMessagesToDLQOutput[T]{}, BuildingExpressionError{Cause: err}
+	}
+	out := &MoveMessagesToDLQOutput[T]{Results: make([]MoveMessageToDLQResult[T], 0)}
+	var exclusiveStartKey map[string]types.AttributeValue
+	for {
+		if err := ctx.Err(); err != nil {
+			return out, OperationCanceledError{Cause: err}
+		}
+		queryOutput, err := c.dynamoDB.Query(ctx, &dynamodb.QueryInput{
+			IndexName:                 aws.String(c.schema.QueueingIndexName),
+			TableName:                 aws.String(c.tableName),
+			KeyConditionExpression:    expr.KeyCondition(),
+			FilterExpression:          expr.Filter(),
+			ProjectionExpression:      expr.Projection(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			ScanIndexForward:          aws.Bool(true),
+			Limit:                     aws.Int32(defaultQueryLimit),
+			ExclusiveStartKey:         exclusiveStartKey,
+		})
+		if err != nil {
+			return out, handleDynamoDBError(err)
+		}
+		for _, item := range queryOutput.Items {
+			if params.MaxMessages > 0 && len(out.Results) >= params.MaxMessages {
+				return out, nil
+			}
+			id, ok := item[c.schema.IDAttribute].(*types.AttributeValueMemberS)
+			if !ok {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return out, OperationCanceledError{Cause: err}
+			}
+			moved, err := c.MoveMessageToDLQ(ctx, &MoveMessageToDLQInput{
+				ID:     id.Value,
+				Reason: params.Reason,
+			})
+			out.add(id.Value, moved, err)
+		}
+		exclusiveStartKey = queryOutput.LastEvaluatedKey
+		if exclusiveStartKey == nil {
+			return out, nil
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package dynamomq_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestDynamoMQClientMoveMessagesToDLQShouldReportFailuresWithoutAborting(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	items := map[string]*dynamomq.Message[test.MessageData]{
+		"A-101": dynamomqtest.NewProcessingMessage("A-101", test.NewMessageData("A-101"), now),
+		"A-102": dynamomqtest.NewProcessingMessage("A-102", test.NewMessageData("A-102"), now),
+		"A-103": dynamomqtest.NewReadyMessage("A-103", test.NewMessageData("A-103"), now),
+	}
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		mock.WithClock(mock.Clock{T: now}),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+				id := params.Key[dynamomq.AttributeNameID].(*types.AttributeValueMemberS).Value
+				if m, ok := items[id]; ok {
+					return &dynamodb.GetItemOutput{Item: dynamomqtest.MarshalMap(m)}, nil
+				}
+				return &dynamodb.GetItemOutput{}, nil
+			},
+			UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+				id := params.Key[dynamomq.AttributeNameID].(*types.AttributeValueMemberS).Value
+				if id == "A-102" {
+					return nil, &types.ConditionalCheckFailedException{Message: aws.String("version changed")}
+				}
+				moved := dynamomqtest.NewDLQMessage(id, test.NewMessageData(id), now)
+				return &dynamodb.UpdateItemOutput{Attributes: dynamomqtest.MarshalMap(moved)}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	out, err := client.MoveMessagesToDLQ(context.Background(), &dynamomq.MoveMessagesToDLQInput{
+		IDs:    []string{"A-101", "A-102", "A-103", "A-104"},
+		Reason: "downstream unavailable",
+	})
+	if err != nil {
+		t.Fatalf("MoveMessagesToDLQ() error = %v", err)
+	}
+	if out.Moved != 2 || out.Failed != 2 {
+		t.Errorf("MoveMessagesToDLQ() moved = %d, failed = %d, want 2, 2", out.Moved, out.Failed)
+	}
+	wantErrs := []error{nil, &dynamomq.ConditionalCheckFailedError{}, nil, dynamomq.ErrIDNotFound}
+	if len(out.Results) != len(wantErrs) {
+		t.Fatalf("MoveMessagesToDLQ() results = %d, want %d", len(out.Results), len(wantErrs))
+	}
+	for i, result := range out.Results {
+		if wantErrs[i] == nil {
+			if result.Err != nil || result.MovedMessage == nil || result.MovedMessage.QueueType != dynamomq.QueueTypeDLQ {
+				t.Errorf("result of %s = %+v, want a message moved to the DLQ", result.ID, result)
+			}
+			continue
+		}
+		if !errors.Is(result.Err, wantErrs[i]) {
+			t.Errorf("result of %s error = %v, want %v", result.ID, result.Err, wantErrs[i])
+		}
+	}
+}
+
+func TestDynamoMQClientMoveMessagesToDLQByFilter(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	var puts []*types.PutRequest
+	for i, receiveCount := range []int{0, 3, 5, 2, 4} {
+		id := fmt.Sprintf("A-%d", 101+i)
+		puts = append(puts, dynamomqtest.NewPutRequest(
+			dynamomqtest.NewProcessingMessage(id, test.NewMessageData(id), test.DefaultTestDate.Add(time.Duration(i)*time.Second),
+				dynamomqtest.WithReceiveCount(receiveCount))))
+	}
+	puts = append(puts, newPutRequestWithDLQItem("B-101", test.DefaultTestDate))
+	client, clean := prepareTestClient(context.Background(), t, func(t *testing.T) (string, *dynamodb.Client, func()) {
+		return SetupDynamoDB(t, puts...)
+	}, mock.Clock{T: now}, false, nil, nil, nil)
+	defer clean()
+	out, err := client.MoveMessagesToDLQByFilter(context.Background(), &dynamomq.MoveMessagesToDLQByFilterInput{
+		MinReceiveCount: 3,
+		Reason:          "downstream unavailable",
+	})
+	if err != nil {
+		t.Fatalf("MoveMessagesToDLQByFilter() error = %v", err)
+	}
+	var ids []string
+	for _, result := range out.Results {
+		if result.Err != nil {
+			t.Errorf("result of %s error = %v", result.ID, result.Err)
+		}
+		ids = append(ids, result.ID)
+	}
+	test.AssertDeepEqual(t, ids, []string{"A-102", "A-103", "A-105"}, "MoveMessagesToDLQByFilter() IDs")
+	if out.Moved != 3 || out.Failed != 0 {
+		t.Errorf("MoveMessagesToDLQByFilter() moved = %d, failed = %d, want 3, 0", out.Moved, out.Failed)
+	}
+	stats, err := client.GetDLQStats(context.Background(), &dynamomq.GetDLQStatsInput{})
+	if err != nil {
+		t.Fatalf("GetDLQStats() error = %v", err)
+	}
+	if stats.TotalMessagesInDLQ != 4 {
+		t.Errorf("GetDLQStats() total = %d, want 4", stats.TotalMessagesInDLQ)
+	}
+}
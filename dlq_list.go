@@ -0,0 +1,81 @@
+package dynamomq
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/vvatanabe/dynamomq/internal/constant"
+)
+
+// ListDLQMessagesInput represents the input parameters for listing the messages of the DLQ.
+type ListDLQMessagesInput struct {
+	// Size is the maximum number of messages listed in one page. By default, it is 10.
+	Size int32
+	// NextToken is the token returned by the previous page, or empty for the first page.
+	NextToken string
+}
+
+// ListDLQMessagesOutput represents the result of the operation to list the messages of the DLQ.
+type ListDLQMessagesOutput[T any] struct {
+	// Messages are the messages of the DLQ with their payloads, in the order they were moved to the DLQ.
+	Messages []*Message[T]
+	// NextToken is set when more messages may follow. Pass it in the next input to get the next page.
+	// The last page may hold no message while the one before it has a NextToken.
+	NextToken string
+}
+
+// ListDLQMessages lists the messages of the DLQ with all their attributes, so that they can be triaged without
+// a GetMessage call for each of the IDs reported by GetDLQStats. It queries the queueing index for the DLQ queue type,
+// which lists the messages in the order they were moved to the DLQ, one page of at most Size messages per call.
+// Corrupt items fail the call unless WithSkipCorruptMessages is set.
+func (c *ClientImpl[T]) ListDLQMessages(ctx context.Context, params *ListDLQMessagesInput) (*ListDLQMessagesOutput[T], error) {
+	if params == nil {
+		params = &ListDLQMessagesInput{}
+	}
+	if params.Size <= 0 {
+		params.Size = constant.DefaultMaxListMessages
+	}
+	exclusiveStartKey, err := decodeNextToken(params.NextToken)
+	if err != nil {
+		return &ListDLQMessagesOutput[T]{}, err
+	}
+	builder := expression.NewBuilder().
+		WithKeyCondition(expression.Key(c.schema.QueueTypeAttribute).Equal(expression.Value(QueueTypeDLQ)))
+	expr, err := c.buildExpression(builder)
+	if err != nil {
+		return &ListDLQMessagesOutput[T]{}, BuildingExpressionError{Cause: err}
+	}
+	queryOutput, err := c.dynamoDB.Query(ctx, &dynamodb.QueryInput{
+		IndexName:                 aws.String(c.schema.QueueingIndexName),
+		TableName:                 aws.String(c.tableName),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ScanIndexForward:          aws.Bool(true),
+		Limit:                     aws.Int32(params.Size),
+		ExclusiveStartKey:         exclusiveStartKey,
+	})
+	if err != nil {
+		return &ListDLQMessagesOutput[T]{}, handleDynamoDBError(err)
+	}
+	out := &ListDLQMessagesOutput[T]{Messages: make([]*Message[T], 0, len(queryOutput.Items))}
+	for _, item := range queryOutput.Items {
+		message := &Message[T]{}
+		if err := c.unmarshalItem(item, message); err != nil {
+			if err = c.handleCorruptMessage(item, err); err != nil {
+				return &ListDLQMessagesOutput[T]{}, err
+			}
+			continue
+		}
+		out.Messages = append(out.Messages, message)
+	}
+	if queryOutput.LastEvaluatedKey != nil {
+		out.NextToken, err = encodeNextToken(queryOutput.LastEvaluatedKey)
+		if err != nil {
+			return &ListDLQMessagesOutput[T]{}, err
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,123 @@
+package dynamomq_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestDynamoMQClientListDLQMessages(t *testing.T) {
+	t.Parallel()
+	var (
+		puts []*types.PutRequest
+		want []string
+	)
+	for i := 0; i < 25; i++ {
+		id := fmt.Sprintf("B-%03d", i)
+		want = append(want, id)
+		puts = append(puts, newPutRequestWithDLQItem(id, test.DefaultTestDate.Add(time.Duration(i)*time.Second)))
+	}
+	puts = append(puts, newPutRequestWithReadyItem("A-101", test.DefaultTestDate))
+	ctx := context.Background()
+	client, clean := prepareTestClient(ctx, t, NewSetupFunc(puts...), mock.Clock{T: test.DefaultTestDate.Add(time.Hour)},
+		false, nil, nil, nil)
+	defer clean()
+	var (
+		got   []string
+		pages int
+		token string
+	)
+	for {
+		out, err := client.ListDLQMessages(ctx, &dynamomq.ListDLQMessagesInput{Size: 10, NextToken: token})
+		if err != nil {
+			t.Fatalf("ListDLQMessages() error = %v", err)
+		}
+		pages++
+		for _, m := range out.Messages {
+			if m.QueueType != dynamomq.QueueTypeDLQ {
+				t.Errorf("ListDLQMessages() queue type of %s = %s, want %s", m.ID, m.QueueType, dynamomq.QueueTypeDLQ)
+			}
+			test.AssertDeepEqual(t, m.Data, test.NewMessageData(m.ID), "ListDLQMessages() data")
+			got = append(got, m.ID)
+		}
+		if out.NextToken == "" {
+			break
+		}
+		token = out.NextToken
+	}
+	test.AssertDeepEqual(t, got, want, "ListDLQMessages() IDs")
+	if pages < 3 {
+		t.Errorf("ListDLQMessages() pages = %d, want at least 3", pages)
+	}
+}
+
+func TestDynamoMQClientListDLQMessagesQuery(t *testing.T) {
+	t.Parallel()
+	lastKey := map[string]types.AttributeValue{
+		dynamomq.AttributeNameID:        &types.AttributeValueMemberS{Value: "B-102"},
+		dynamomq.AttributeNameQueueType: &types.AttributeValueMemberS{Value: string(dynamomq.QueueTypeDLQ)},
+		dynamomq.AttributeNameSentAt:    &types.AttributeValueMemberS{Value: "2023-12-01T00:00:00Z"},
+	}
+	var queries []*dynamodb.QueryInput
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+				queries = append(queries, params)
+				if params.ExclusiveStartKey == nil {
+					return &dynamodb.QueryOutput{
+						Items: []map[string]types.AttributeValue{
+							dynamomqtest.MarshalMap(NewTestMessageItemAsDLQ("B-101", test.DefaultTestDate)),
+							dynamomqtest.MarshalMap(NewTestMessageItemAsDLQ("B-102", test.DefaultTestDate)),
+						},
+						LastEvaluatedKey: lastKey,
+					}, nil
+				}
+				return &dynamodb.QueryOutput{
+					Items: []map[string]types.AttributeValue{
+						dynamomqtest.MarshalMap(NewTestMessageItemAsDLQ("B-103", test.DefaultTestDate)),
+					},
+				}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	ctx := context.Background()
+	first, err := client.ListDLQMessages(ctx, &dynamomq.ListDLQMessagesInput{Size: 2})
+	if err != nil {
+		t.Fatalf("ListDLQMessages() error = %v", err)
+	}
+	if first.NextToken == "" {
+		t.Fatal("ListDLQMessages() NextToken is empty, want a token")
+	}
+	second, err := client.ListDLQMessages(ctx, &dynamomq.ListDLQMessagesInput{Size: 2, NextToken: first.NextToken})
+	if err != nil {
+		t.Fatalf("ListDLQMessages() error = %v", err)
+	}
+	if second.NextToken != "" {
+		t.Errorf("ListDLQMessages() NextToken = %s, want empty", second.NextToken)
+	}
+	var ids []string
+	for _, m := range append(first.Messages, second.Messages...) {
+		ids = append(ids, m.ID)
+	}
+	test.AssertDeepEqual(t, ids, []string{"B-101", "B-102", "B-103"}, "ListDLQMessages() IDs")
+	test.AssertDeepEqual(t, aws.ToInt32(queries[0].Limit), int32(2), "Query() Limit")
+	test.AssertDeepEqual(t, aws.ToBool(queries[0].ScanIndexForward), true, "Query() ScanIndexForward")
+	test.AssertDeepEqual(t, queries[0].ProjectionExpression, (*string)(nil), "Query() ProjectionExpression")
+	test.AssertDeepEqual(t, queries[1].ExclusiveStartKey, lastKey, "Query() ExclusiveStartKey")
+
+	_, err = client.ListDLQMessages(ctx, &dynamomq.ListDLQMessagesInput{NextToken: "not a token"})
+	if _, ok := err.(dynamomq.InvalidNextTokenError); !ok {
+		t.Errorf("ListDLQMessages() error = %v, want InvalidNextTokenError", err)
+	}
+}
@@ -0,0 +1,58 @@
+package dynamomq
+
+import (
+	"context"
+)
+
+// DLQNotification describes a message that has been moved to the DLQ.
+type DLQNotification struct {
+	// ID is the unique identifier of the message.
+	ID string `json:"id"`
+	// QueueType is the type of queue the message was moved from.
+	QueueType QueueType `json:"queue_type"`
+	// ReceiveCount is the number of times the message had been received when it was moved.
+	ReceiveCount int `json:"receive_count"`
+	// Reason describes why the message was moved, such as the error returned by the MessageProcessor.
+	Reason string `json:"reason"`
+	// MovedAt is the timestamp when the message was moved to the DLQ.
+	MovedAt string `json:"moved_at"`
+}
+
+// DLQNotifier is notified every time a message is moved to the DLQ, for example to page on-call.
+// Ready-made notifiers for Amazon SNS and Amazon EventBridge are provided by the notification package.
+type DLQNotifier interface {
+	// NotifyDLQ is called after a message has been moved to the DLQ. A returned error does not fail the move.
+	NotifyDLQ(ctx context.Context, n *DLQNotification) error
+}
+
+// DLQNotifierFunc is a functional type that implements the DLQNotifier interface.
+type DLQNotifierFunc func(ctx context.Context, n *DLQNotification) error
+
+// NotifyDLQ calls the DLQNotifierFunc itself.
+func (f DLQNotifierFunc) NotifyDLQ(ctx context.Context, n *DLQNotification) error {
+	return f(ctx, n)
+}
+
+// DLQNotificationFailures returns the number of DLQ notifications that have failed since the client was created.
+func (c *ClientImpl[T]) DLQNotificationFailures() int64 {
+	return c.dlqNotificationFailures.Load()
+}
+
+// notifyDLQ notifies the DLQNotifier that moved was moved to the DLQ. The move resets the queue type and the receive
+// count of the message, so the values it had before the move are passed as from and receiveCount.
+func (c *ClientImpl[T]) notifyDLQ(ctx context.Context, moved *Message[T], from QueueType, receiveCount int, reason string) {
+	if c.dlqNotifier == nil || moved == nil {
+		return
+	}
+	err := c.dlqNotifier.NotifyDLQ(ctx, &DLQNotification{
+		ID:           moved.ID,
+		QueueType:    from,
+		ReceiveCount: receiveCount,
+		Reason:       reason,
+		MovedAt:      moved.UpdatedAt,
+	})
+	if err != nil {
+		c.dlqNotificationFailures.Add(1)
+		c.logf("DynamoMQ: Failed to notify that message %s was moved to DLQ. %s", moved.ID, err)
+	}
+}
@@ -0,0 +1,76 @@
+package dynamomq_test
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+// newPutRequestWithRetriedItem returns the put of A-101 in processing after 3 receives.
+func newPutRequestWithRetriedItem() *types.PutRequest {
+	return dynamomqtest.NewPutRequest(dynamomqtest.NewProcessingMessage("A-101", test.NewMessageData("A-101"),
+		test.DefaultTestDate, dynamomqtest.WithReceiveCount(3)))
+}
+
+func TestDynamoMQClientMoveMessageToDLQNotifies(t *testing.T) {
+	t.Parallel()
+	var got []*dynamomq.DLQNotification
+	client, clean := prepareTestClient(context.Background(), t, NewSetupFunc(newPutRequestWithRetriedItem()),
+		mock.Clock{T: test.DefaultTestDate.Add(time.Minute)}, false, nil, nil, nil,
+		dynamomq.WithDLQNotifier(dynamomq.DLQNotifierFunc(func(ctx context.Context, n *dynamomq.DLQNotification) error {
+			got = append(got, n)
+			return nil
+		})))
+	defer clean()
+	_, err := client.MoveMessageToDLQ(context.Background(), &dynamomq.MoveMessageToDLQInput{
+		ID:     "A-101",
+		Reason: "invalid order",
+	})
+	if err != nil {
+		t.Fatalf("MoveMessageToDLQ() error = %v", err)
+	}
+	want := []*dynamomq.DLQNotification{
+		{
+			ID:           "A-101",
+			QueueType:    dynamomq.QueueTypeStandard,
+			ReceiveCount: 3,
+			Reason:       "invalid order",
+			MovedAt:      clock.FormatRFC3339Nano(test.DefaultTestDate.Add(time.Minute)),
+		},
+	}
+	test.AssertDeepEqual(t, got, want, "NotifyDLQ()")
+}
+
+func TestDynamoMQClientMoveMessageToDLQIgnoresNotificationFailures(t *testing.T) {
+	t.Parallel()
+	var logged bytes.Buffer
+	client, clean := prepareTestClient(context.Background(), t, NewSetupFunc(newPutRequestWithRetriedItem()),
+		mock.Clock{T: test.DefaultTestDate.Add(time.Minute)}, false, nil, nil, nil,
+		dynamomq.WithDLQNotifier(dynamomq.DLQNotifierFunc(func(ctx context.Context, n *dynamomq.DLQNotification) error {
+			return test.ErrTest
+		})), dynamomq.WithClientErrorLog(log.New(&logged, "", 0)))
+	defer clean()
+	got, err := client.MoveMessageToDLQ(context.Background(), &dynamomq.MoveMessageToDLQInput{ID: "A-101"})
+	if err != nil {
+		t.Fatalf("MoveMessageToDLQ() error = %v", err)
+	}
+	if got.MovedMessage.QueueType != dynamomq.QueueTypeDLQ {
+		t.Errorf("MoveMessageToDLQ() queue type = %v, want %v", got.MovedMessage.QueueType, dynamomq.QueueTypeDLQ)
+	}
+	if failures := client.(*dynamomq.ClientImpl[test.MessageData]).DLQNotificationFailures(); failures != 1 {
+		t.Errorf("DLQNotificationFailures() = %d, want 1", failures)
+	}
+	if !strings.Contains(logged.String(), "Failed to notify that message A-101 was moved to DLQ.") {
+		t.Errorf("error log = %q, want the notification failure", logged.String())
+	}
+}
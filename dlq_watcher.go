@@ -0,0 +1,278 @@
+package dynamomq
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/vvatanabe/dynamomq/internal/clock"
+)
+
+const defaultDLQWatchInterval = time.Minute
+
+// ErrDLQWatcherClosed is an error that indicates the DLQWatcher has been stopped.
+var ErrDLQWatcherClosed = errors.New("DynamoMQ: DLQWatcher closed")
+
+// DLQAlertKind is the condition that raised a DLQAlert.
+type DLQAlertKind string
+
+const (
+	// DLQAlertThreshold is raised when the number of messages in the DLQ reaches the Threshold.
+	DLQAlertThreshold DLQAlertKind = "THRESHOLD"
+	// DLQAlertGrowth is raised when the DLQ grows by GrowthThreshold messages or more within the GrowthWindow.
+	DLQAlertGrowth DLQAlertKind = "GROWTH"
+)
+
+// DLQAlert reports that the DLQ has crossed one of the thresholds of a DLQWatcher.
+type DLQAlert struct {
+	// Kind is the condition that raised the alert.
+	Kind DLQAlertKind
+	// TotalMessagesInDLQ is the number of messages in the DLQ when the alert was raised.
+	TotalMessagesInDLQ int
+	// Growth is the number of messages added to the DLQ within the GrowthWindow, over the lowest count seen in it.
+	Growth int
+	// At is the time the alert was raised.
+	At time.Time
+}
+
+// DLQWatcherOptions contains configuration options for a DLQWatcher instance.
+type DLQWatcherOptions struct {
+	// Interval is the time interval between two checks of the DLQ.
+	Interval time.Duration
+	// Threshold raises a DLQAlertThreshold when the DLQ holds this many messages or more. Zero disables it.
+	Threshold int
+	// GrowthThreshold raises a DLQAlertGrowth when the DLQ grows by this many messages or more within
+	// GrowthWindow. Zero disables it.
+	GrowthThreshold int
+	// GrowthWindow is the period over which the growth of the DLQ is measured.
+	GrowthWindow time.Duration
+	// OnAlert is called with each alert, on the goroutine of the DLQWatcher.
+	OnAlert func(alert DLQAlert)
+	// Alerts receives each alert. The DLQWatcher blocks until the alert is received or it is stopped.
+	Alerts chan<- DLQAlert
+	// ErrorLog is an optional logger for errors. If nil, the standard logger is used.
+	ErrorLog *log.Logger
+	// Clock is an abstraction of time operations, allowing control over time during tests.
+	Clock clock.Clock
+}
+
+// WithDLQWatcherInterval sets the time interval between two checks of the DLQ.
+func WithDLQWatcherInterval(interval time.Duration) func(o *DLQWatcherOptions) {
+	return func(o *DLQWatcherOptions) {
+		o.Interval = interval
+	}
+}
+
+// WithDLQWatcherThreshold sets the number of messages in the DLQ from which an alert is raised.
+func WithDLQWatcherThreshold(threshold int) func(o *DLQWatcherOptions) {
+	return func(o *DLQWatcherOptions) {
+		o.Threshold = threshold
+	}
+}
+
+// WithDLQWatcherGrowthThreshold sets the growth of the DLQ within the window from which an alert is raised.
+func WithDLQWatcherGrowthThreshold(growth int, window time.Duration) func(o *DLQWatcherOptions) {
+	return func(o *DLQWatcherOptions) {
+		o.GrowthThreshold = growth
+		o.GrowthWindow = window
+	}
+}
+
+// WithDLQWatcherOnAlert sets the function called with each alert.
+func WithDLQWatcherOnAlert(onAlert func(alert DLQAlert)) func(o *DLQWatcherOptions) {
+	return func(o *DLQWatcherOptions) {
+		o.OnAlert = onAlert
+	}
+}
+
+// WithDLQWatcherAlerts sets the channel receiving each alert.
+func WithDLQWatcherAlerts(alerts chan<- DLQAlert) func(o *DLQWatcherOptions) {
+	return func(o *DLQWatcherOptions) {
+		o.Alerts = alerts
+	}
+}
+
+// WithDLQWatcherErrorLog sets a custom logger for the DLQWatcher.
+func WithDLQWatcherErrorLog(errorLog *log.Logger) func(o *DLQWatcherOptions) {
+	return func(o *DLQWatcherOptions) {
+		o.ErrorLog = errorLog
+	}
+}
+
+// NewDLQWatcher creates a new DLQWatcher that checks the DLQ of the client's queue with GetDLQStats.
+func NewDLQWatcher[T any](client Client[T], opts ...func(o *DLQWatcherOptions)) *DLQWatcher[T] {
+	o := &DLQWatcherOptions{
+		Interval: defaultDLQWatchInterval,
+		Clock:    &clock.RealClock{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &DLQWatcher[T]{
+		client:          client,
+		interval:        o.Interval,
+		threshold:       o.Threshold,
+		growthThreshold: o.GrowthThreshold,
+		growthWindow:    o.GrowthWindow,
+		onAlert:         o.OnAlert,
+		alerts:          o.Alerts,
+		errorLog:        o.ErrorLog,
+		clock:           o.Clock,
+		active:          make(map[DLQAlertKind]bool),
+		doneChan:        make(chan struct{}),
+	}
+}
+
+// dlqSample is the number of messages in the DLQ at a point in time.
+type dlqSample struct {
+	at    time.Time
+	total int
+}
+
+// DLQWatcher periodically checks the number of messages in the DLQ and raises an alert when it crosses a threshold,
+// so that a service can page without separate monitoring. An alert is raised once when its condition becomes true,
+// and not again until the condition has cleared.
+// Note: To create a new instance of DLQWatcher, it is necessary to use the NewDLQWatcher function.
+type DLQWatcher[T any] struct {
+	client          Client[T]
+	interval        time.Duration
+	threshold       int
+	growthThreshold int
+	growthWindow    time.Duration
+	onAlert         func(alert DLQAlert)
+	alerts          chan<- DLQAlert
+	errorLog        *log.Logger
+	clock           clock.Clock
+
+	checkMu sync.Mutex
+	samples []dlqSample
+	active  map[DLQAlertKind]bool
+
+	mu       sync.Mutex
+	runWG    sync.WaitGroup
+	doneChan chan struct{}
+}
+
+// Start checks the DLQ every Interval until the context is done or Stop is called.
+// It returns ErrDLQWatcherClosed after Stop, or the error of the context.
+func (w *DLQWatcher[T]) Start(ctx context.Context) error {
+	w.runWG.Add(1)
+	defer w.runWG.Done()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-w.doneChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	for {
+		if _, err := w.Check(ctx); err != nil && ctx.Err() == nil {
+			w.logf("DynamoMQ: Failed to check the DLQ. %s", err)
+		}
+		if !sleepContext(ctx, w.interval) {
+			select {
+			case <-w.doneChan:
+				return ErrDLQWatcherClosed
+			default:
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// Stop stops the DLQWatcher and waits until the running check has finished.
+func (w *DLQWatcher[T]) Stop() {
+	w.mu.Lock()
+	select {
+	case <-w.doneChan:
+	default:
+		close(w.doneChan)
+	}
+	w.mu.Unlock()
+	w.runWG.Wait()
+}
+
+// Check gets the stats of the DLQ once, and raises and returns the alerts whose condition has become true.
+// Start calls it every Interval.
+func (w *DLQWatcher[T]) Check(ctx context.Context) ([]DLQAlert, error) {
+	out, err := w.client.GetDLQStats(ctx, &GetDLQStatsInput{})
+	if err != nil {
+		return nil, err
+	}
+	w.checkMu.Lock()
+	alerts := w.evaluate(w.clock.Now(), out.TotalMessagesInDLQ)
+	w.checkMu.Unlock()
+	for _, alert := range alerts {
+		if err := w.raise(ctx, alert); err != nil {
+			return alerts, err
+		}
+	}
+	return alerts, nil
+}
+
+func (w *DLQWatcher[T]) evaluate(now time.Time, total int) []DLQAlert {
+	var alerts []DLQAlert
+	if w.threshold > 0 && w.transition(DLQAlertThreshold, total >= w.threshold) {
+		alerts = append(alerts, DLQAlert{Kind: DLQAlertThreshold, TotalMessagesInDLQ: total, At: now})
+	}
+	if w.growthThreshold > 0 {
+		growth := w.growth(now, total)
+		if w.transition(DLQAlertGrowth, growth >= w.growthThreshold) {
+			alerts = append(alerts, DLQAlert{Kind: DLQAlertGrowth, TotalMessagesInDLQ: total, Growth: growth, At: now})
+		}
+	}
+	return alerts
+}
+
+// transition records whether the condition of kind holds, and reports whether it has just become true.
+func (w *DLQWatcher[T]) transition(kind DLQAlertKind, holds bool) bool {
+	raised := holds && !w.active[kind]
+	w.active[kind] = holds
+	return raised
+}
+
+// growth records the sample and returns its growth over the lowest count seen within the window.
+func (w *DLQWatcher[T]) growth(now time.Time, total int) int {
+	start := now.Add(-w.growthWindow)
+	kept := w.samples[:0]
+	for _, s := range w.samples {
+		if !s.at.Before(start) {
+			kept = append(kept, s)
+		}
+	}
+	w.samples = append(kept, dlqSample{at: now, total: total})
+	lowest := total
+	for _, s := range w.samples {
+		if s.total < lowest {
+			lowest = s.total
+		}
+	}
+	return total - lowest
+}
+
+func (w *DLQWatcher[T]) raise(ctx context.Context, alert DLQAlert) error {
+	if w.onAlert != nil {
+		w.onAlert(alert)
+	}
+	if w.alerts == nil {
+		return nil
+	}
+	select {
+	case w.alerts <- alert:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *DLQWatcher[T]) logf(format string, v ...any) {
+	if w.errorLog != nil {
+		w.errorLog.Printf(format, v...)
+	} else {
+		log.Printf(format, v...)
+	}
+}
@@ -0,0 +1,139 @@
+package dynamomq_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+// newDLQStatsClient returns a client whose DLQ holds the given numbers of messages in turn, repeating the last one.
+func newDLQStatsClient(totals ...int) *mock.Client[test.MessageData] {
+	var calls atomic.Int32
+	return &mock.Client[test.MessageData]{
+		GetDLQStatsFunc: func(ctx context.Context, params *dynamomq.GetDLQStatsInput) (*dynamomq.GetDLQStatsOutput, error) {
+			i := min(int(calls.Add(1))-1, len(totals)-1)
+			return &dynamomq.GetDLQStatsOutput{TotalMessagesInDLQ: totals[i]}, nil
+		},
+	}
+}
+
+func TestDLQWatcherCheck(t *testing.T) {
+	t.Parallel()
+	type check struct {
+		advance time.Duration
+		want    []dynamomq.DLQAlert
+	}
+	tests := []struct {
+		name   string
+		totals []int
+		opts   []func(*dynamomq.DLQWatcherOptions)
+		checks []check
+	}{
+		{
+			name:   "should alert once each time the threshold is crossed",
+			totals: []int{1, 5, 6, 2, 5},
+			opts:   []func(*dynamomq.DLQWatcherOptions){dynamomq.WithDLQWatcherThreshold(5)},
+			checks: []check{
+				{},
+				{advance: time.Minute, want: []dynamomq.DLQAlert{
+					{Kind: dynamomq.DLQAlertThreshold, TotalMessagesInDLQ: 5, At: test.DefaultTestDate.Add(time.Minute)},
+				}},
+				{advance: time.Minute},
+				{advance: time.Minute},
+				{advance: time.Minute, want: []dynamomq.DLQAlert{
+					{Kind: dynamomq.DLQAlertThreshold, TotalMessagesInDLQ: 5, At: test.DefaultTestDate.Add(4 * time.Minute)},
+				}},
+			},
+		},
+		{
+			name:   "should alert once each time the DLQ grows too fast within the window",
+			totals: []int{0, 2, 4, 5, 5, 9},
+			opts: []func(*dynamomq.DLQWatcherOptions){
+				dynamomq.WithDLQWatcherGrowthThreshold(3, 10*time.Minute),
+			},
+			checks: []check{
+				{},
+				{advance: 5 * time.Minute},
+				{advance: 5 * time.Minute, want: []dynamomq.DLQAlert{
+					{Kind: dynamomq.DLQAlertGrowth, TotalMessagesInDLQ: 4, Growth: 4, At: test.DefaultTestDate.Add(10 * time.Minute)},
+				}},
+				// The growth over the window is still 3, so the alert is not raised again.
+				{advance: 5 * time.Minute},
+				// The samples showing the growth have left the window, which clears the alert.
+				{advance: 15 * time.Minute},
+				{advance: 5 * time.Minute, want: []dynamomq.DLQAlert{
+					{Kind: dynamomq.DLQAlertGrowth, TotalMessagesInDLQ: 9, Growth: 4, At: test.DefaultTestDate.Add(35 * time.Minute)},
+				}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			now := &steppingClock{now: test.DefaultTestDate}
+			var raised []dynamomq.DLQAlert
+			opts := append([]func(*dynamomq.DLQWatcherOptions){
+				mock.WithDLQWatcherClock(now),
+				dynamomq.WithDLQWatcherOnAlert(func(alert dynamomq.DLQAlert) {
+					raised = append(raised, alert)
+				}),
+			}, tt.opts...)
+			watcher := dynamomq.NewDLQWatcher[test.MessageData](newDLQStatsClient(tt.totals...), opts...)
+			var want []dynamomq.DLQAlert
+			for i, c := range tt.checks {
+				now.Advance(c.advance)
+				got, err := watcher.Check(context.Background())
+				if err != nil {
+					t.Fatalf("Check() #%d error = %v", i, err)
+				}
+				test.AssertDeepEqual(t, got, c.want, "Check()")
+				want = append(want, c.want...)
+			}
+			test.AssertDeepEqual(t, raised, want, "OnAlert()")
+		})
+	}
+}
+
+func TestDLQWatcherCheckShouldReturnError(t *testing.T) {
+	t.Parallel()
+	watcher := dynamomq.NewDLQWatcher[test.MessageData](&mock.Client[test.MessageData]{
+		GetDLQStatsFunc: func(ctx context.Context, params *dynamomq.GetDLQStatsInput) (*dynamomq.GetDLQStatsOutput, error) {
+			return nil, test.ErrTest
+		},
+	}, dynamomq.WithDLQWatcherThreshold(1))
+	if _, err := watcher.Check(context.Background()); !errors.Is(err, test.ErrTest) {
+		t.Errorf("Check() error = %v, want %v", err, test.ErrTest)
+	}
+}
+
+func TestDLQWatcherStartAndStop(t *testing.T) {
+	t.Parallel()
+	alerts := make(chan dynamomq.DLQAlert)
+	watcher := dynamomq.NewDLQWatcher[test.MessageData](newDLQStatsClient(0, 3),
+		dynamomq.WithDLQWatcherInterval(time.Millisecond),
+		dynamomq.WithDLQWatcherThreshold(3),
+		dynamomq.WithDLQWatcherAlerts(alerts))
+	done := make(chan error, 1)
+	go func() {
+		done <- watcher.Start(context.Background())
+	}()
+	select {
+	case alert := <-alerts:
+		if alert.Kind != dynamomq.DLQAlertThreshold || alert.TotalMessagesInDLQ != 3 {
+			t.Errorf("alert = %+v, want a threshold alert with 3 messages", alert)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the DLQWatcher raised no alert")
+	}
+	watcher.Stop()
+	if err := <-done; !errors.Is(err, dynamomq.ErrDLQWatcherClosed) {
+		t.Errorf("Start() error = %v, want %v", err, dynamomq.ErrDLQWatcherClosed)
+	}
+}
@@ -0,0 +1,257 @@
+package dynamomq
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+const (
+	// restoreBatchSize is the maximum number of items DynamoDB accepts in one BatchWriteItem call.
+	restoreBatchSize = 25
+	// batchMaxUnprocessedAttempts and batchUnprocessedBackoff bound the retries of the items or keys
+	// left unprocessed by a batch operation.
+	batchMaxUnprocessedAttempts = 8
+	batchUnprocessedBackoff     = 50 * time.Millisecond
+)
+
+// DumpMessagesInput represents the input parameters for writing the messages of a queue to an io.Writer.
+type DumpMessagesInput struct {
+	// QueueType is the type of queue (STANDARD or DLQ) to dump. If it is empty, the messages of every queue type are dumped.
+	QueueType QueueType
+	// IncludeData is a boolean indicating if the payload of the messages is dumped. A dump without the payload
+	// is meant for inspection, as the messages restored from it have the zero value of T as their data.
+	IncludeData bool
+}
+
+// DumpMessagesOutput represents the result of the operation to dump the messages of a queue.
+type DumpMessagesOutput struct {
+	// Dumped is the number of messages written.
+	Dumped int
+}
+
+// DumpMessages writes the messages of the queue to w as newline-delimited JSON, one message per line,
+// in the JSON representation of Message. It reads the queue one page at a time and flushes what it has written
+// after every page, so that the memory used does not depend on the size of the queue.
+// With a QueueType, the messages are written from the oldest; otherwise the whole table is scanned in no particular order.
+// The messages are read without a lock, so a message updated during the dump is written as it was when its page was read.
+func (c *ClientImpl[T]) DumpMessages(ctx context.Context, w io.Writer, params *DumpMessagesInput) (*DumpMessagesOutput, error) {
+	if params == nil {
+		params = &DumpMessagesInput{}
+	}
+	readPage, err := c.dumpPages(params)
+	if err != nil {
+		return &DumpMessagesOutput{}, err
+	}
+	bw := bufio.NewWriter(w)
+	out := &DumpMessagesOutput{}
+	var exclusiveStartKey map[string]types.AttributeValue
+	for {
+		if err := ctx.Err(); err != nil {
+			return out, OperationCanceledError{Cause: err}
+		}
+		items, lastEvaluatedKey, err := readPage(ctx, exclusiveStartKey)
+		if err != nil {
+			return out, handleDynamoDBError(err)
+		}
+		for _, item := range items {
+			message := Message[T]{}
+			if err := c.unmarshalItem(item, &message); err != nil {
+				if err = c.handleCorruptMessage(item, err); err != nil {
+					return out, err
+				}
+				continue
+			}
+			line, err := MarshalMessageJSON(&message, params.IncludeData)
+			if err != nil {
+				return out, err
+			}
+			if _, err := bw.Write(append(line, '\n')); err != nil {
+				return out, err
+			}
+			out.Dumped++
+		}
+		if err := bw.Flush(); err != nil {
+			return out, err
+		}
+		exclusiveStartKey = lastEvaluatedKey
+		if exclusiveStartKey == nil {
+			return out, nil
+		}
+	}
+}
+
+type dumpPageReader func(ctx context.Context, exclusiveStartKey map[string]types.AttributeValue) (
+	items []map[string]types.AttributeValue, lastEvaluatedKey map[string]types.AttributeValue, err error)
+
+// dumpPages returns a function reading the pages of the messages to dump,
+// from the queueing index for a queue type and from the table otherwise.
+func (c *ClientImpl[T]) dumpPages(params *DumpMessagesInput) (dumpPageReader, error) {
+	if params.QueueType == "" {
+		expr := c.static.listMessages
+		if !params.IncludeData {
+			expr = c.static.listMessagesOmitData
+		}
+		return func(ctx context.Context, exclusiveStartKey map[string]types.AttributeValue) (
+			[]map[string]types.AttributeValue, map[string]types.AttributeValue, error) {
+			out, err := c.dynamoDB.Scan(ctx, &dynamodb.ScanInput{
+				TableName:                aws.String(c.tableName),
+				FilterExpression:         expr.Filter(),
+				ProjectionExpression:     expr.Projection(),
+				ExpressionAttributeNames: expr.Names(),
+				Limit:                    aws.Int32(defaultQueryLimit),
+				ExclusiveStartKey:        exclusiveStartKey,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			return out.Items, out.LastEvaluatedKey, nil
+		}, nil
+	}
+	builder := expression.NewBuilder().
+		WithKeyCondition(expression.Key(c.schema.QueueTypeAttribute).Equal(expression.Value(params.QueueType)))
+	if !params.IncludeData {
+		builder = builder.WithProjection(c.systemAttributeProjection())
+	}
+	expr, err := c.buildExpression(builder)
+	if err != nil {
+		return nil, BuildingExpressionError{Cause: err}
+	}
+	return func(ctx context.Context, exclusiveStartKey map[string]types.AttributeValue) (
+		[]map[string]types.AttributeValue, map[string]types.AttributeValue, error) {
+		out, err := c.dynamoDB.Query(ctx, &dynamodb.QueryInput{
+			IndexName:                 aws.String(c.schema.QueueingIndexName),
+			TableName:                 aws.String(c.tableName),
+			KeyConditionExpression:    expr.KeyCondition(),
+			ProjectionExpression:      expr.Projection(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			ScanIndexForward:          aws.Bool(true),
+			Limit:                     aws.Int32(defaultQueryLimit),
+			ExclusiveStartKey:         exclusiveStartKey,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return out.Items, out.LastEvaluatedKey, nil
+	}, nil
+}
+
+// RestoreMode determines the IDs of the messages written by RestoreMessages.
+type RestoreMode int
+
+const (
+	// RestoreModePreserveID writes every message with its ID, replacing the message with the same ID if there is one.
+	RestoreModePreserveID RestoreMode = iota
+	// RestoreModeRegenerateID writes every message with a new random ID, so that no message of the queue is replaced.
+	RestoreModeRegenerateID
+)
+
+// RestoreMessagesInput represents the input parameters for writing back messages dumped with DumpMessages.
+type RestoreMessagesInput struct {
+	// Mode determines the IDs of the restored messages. By default, the IDs are preserved.
+	Mode RestoreMode
+}
+
+// RestoreMessagesOutput represents the result of the operation to restore dumped messages.
+type RestoreMessagesOutput struct {
+	// Restored is the number of messages written.
+	Restored int
+}
+
+// RestoreMessages reads messages dumped with DumpMessages from r and writes them to the queue with BatchWriteItem,
+// 25 at a time. The messages keep their queue type, receive count, version and timestamps, so that a message
+// that was being processed becomes visible again when its visibility timeout has expired. They do not hold
+// a slot of the in-flight limit.
+// A message that cannot be decoded stops the restore with an InvalidDumpError; the messages before it have been written.
+func (c *ClientImpl[T]) RestoreMessages(ctx context.Context, r io.Reader, params *RestoreMessagesInput) (*RestoreMessagesOutput, error) {
+	if params == nil {
+		params = &RestoreMessagesInput{}
+	}
+	jsonDecoder := json.NewDecoder(r)
+	out := &RestoreMessagesOutput{}
+	batch := make([]types.WriteRequest, 0, restoreBatchSize)
+	ids := make(map[string]struct{}, restoreBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := c.batchWriteItems(ctx, batch); err != nil {
+			return err
+		}
+		out.Restored += len(batch)
+		batch = batch[:0]
+		clear(ids)
+		return nil
+	}
+	for record := 1; ; record++ {
+		if err := ctx.Err(); err != nil {
+			return out, OperationCanceledError{Cause: err}
+		}
+		message := Message[T]{}
+		if err := jsonDecoder.Decode(&message); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return out, InvalidDumpError{Record: record, Cause: err}
+		}
+		if message.ID == "" {
+			return out, InvalidDumpError{Record: record, Cause: &IDNotProvidedError{}}
+		}
+		if params.Mode == RestoreModeRegenerateID {
+			message.ID = uuid.NewString()
+		}
+		message.InFlightSlot = false
+		item, err := c.marshalItem(&message)
+		if err != nil {
+			return out, InvalidDumpError{Record: record, Cause: MarshalingAttributeError{Cause: err}}
+		}
+		// DynamoDB rejects a batch writing the same item twice, so a repeated ID starts a new batch.
+		if _, ok := ids[message.ID]; ok || len(batch) == restoreBatchSize {
+			if err := flush(); err != nil {
+				return out, err
+			}
+		}
+		batch = append(batch, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+		ids[message.ID] = struct{}{}
+	}
+	if err := flush(); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// batchWriteItems writes the requests with BatchWriteItem, and writes again the items DynamoDB left unprocessed
+// with an exponential backoff.
+func (c *ClientImpl[T]) batchWriteItems(ctx context.Context, requests []types.WriteRequest) error {
+	backoff := batchUnprocessedBackoff
+	for attempt := 1; ; attempt++ {
+		out, err := c.dynamoDB.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]types.WriteRequest{c.tableName: requests},
+		})
+		if err != nil {
+			return handleDynamoDBError(err)
+		}
+		requests = out.UnprocessedItems[c.tableName]
+		if len(requests) == 0 {
+			return nil
+		}
+		if attempt == batchMaxUnprocessedAttempts {
+			return ThrottledError{Cause: fmt.Errorf("%d items left unprocessed by BatchWriteItem", len(requests))}
+		}
+		if !sleepContext(ctx, backoff) {
+			return OperationCanceledError{Cause: ctx.Err()}
+		}
+		backoff *= 2
+	}
+}
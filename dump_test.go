@@ -0,0 +1,195 @@
+package dynamomq_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestDynamoMQClientDumpAndRestoreMessages(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	want := []*dynamomq.Message[test.MessageData]{
+		NewTestMessageItemAsReady("A-101", test.DefaultTestDate),
+		NewTestMessageItemAsProcessing("A-102", test.DefaultTestDate),
+		NewTestMessageItemAsDLQ("B-101", test.DefaultTestDate),
+	}
+	newClient := func(puts ...*types.PutRequest) (*dynamomq.ClientImpl[test.MessageData], func()) {
+		client, clean := prepareTestClient(context.Background(), t, func(t *testing.T) (string, *dynamodb.Client, func()) {
+			return SetupDynamoDB(t, puts...)
+		}, mock.Clock{T: now}, false, nil, nil, nil)
+		return client.(*dynamomq.ClientImpl[test.MessageData]), clean
+	}
+	var puts []*types.PutRequest
+	for _, m := range want {
+		puts = append(puts, dynamomqtest.NewPutRequest(m))
+	}
+	source, cleanSource := newClient(puts...)
+	defer cleanSource()
+	ctx := context.Background()
+
+	var dump bytes.Buffer
+	dumped, err := source.DumpMessages(ctx, &dump, &dynamomq.DumpMessagesInput{IncludeData: true})
+	if err != nil {
+		t.Fatalf("DumpMessages() error = %v", err)
+	}
+	if dumped.Dumped != len(want) || strings.Count(dump.String(), "\n") != len(want) {
+		t.Fatalf("DumpMessages() dumped = %d messages in %q, want %d lines", dumped.Dumped, dump.String(), len(want))
+	}
+
+	target, cleanTarget := newClient()
+	defer cleanTarget()
+	restored, err := target.RestoreMessages(ctx, bytes.NewReader(dump.Bytes()), &dynamomq.RestoreMessagesInput{})
+	if err != nil {
+		t.Fatalf("RestoreMessages() error = %v", err)
+	}
+	if restored.Restored != len(want) {
+		t.Errorf("RestoreMessages() restored = %d, want %d", restored.Restored, len(want))
+	}
+	for _, m := range want {
+		got, err := target.GetMessage(ctx, &dynamomq.GetMessageInput{ID: m.ID})
+		if err != nil {
+			t.Fatalf("GetMessage() error = %v", err)
+		}
+		test.AssertDeepEqual(t, got.Message, m, "GetMessage() of "+m.ID)
+	}
+
+	restored, err = target.RestoreMessages(ctx, bytes.NewReader(dump.Bytes()), &dynamomq.RestoreMessagesInput{
+		Mode: dynamomq.RestoreModeRegenerateID,
+	})
+	if err != nil {
+		t.Fatalf("RestoreMessages() error = %v", err)
+	}
+	if restored.Restored != len(want) {
+		t.Errorf("RestoreMessages() restored = %d, want %d", restored.Restored, len(want))
+	}
+	var redump bytes.Buffer
+	redumped, err := target.DumpMessages(ctx, &redump, &dynamomq.DumpMessagesInput{QueueType: dynamomq.QueueTypeStandard})
+	if err != nil {
+		t.Fatalf("DumpMessages() error = %v", err)
+	}
+	if redumped.Dumped != 4 {
+		t.Errorf("DumpMessages() of the STANDARD queue dumped = %d, want 4", redumped.Dumped)
+	}
+}
+
+func TestDynamoMQClientDumpMessagesWithoutData(t *testing.T) {
+	t.Parallel()
+	pages := [][]map[string]types.AttributeValue{
+		{dynamomqtest.MarshalMap(NewTestMessageItemAsReady("A-101", test.DefaultTestDate))},
+		{dynamomqtest.MarshalMap(NewTestMessageItemAsReady("A-102", test.DefaultTestDate))},
+	}
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+				if params.ProjectionExpression == nil {
+					t.Error("Query() does not project away the data")
+				}
+				if params.ExclusiveStartKey == nil {
+					return &dynamodb.QueryOutput{Items: pages[0], LastEvaluatedKey: pages[0][0]}, nil
+				}
+				return &dynamodb.QueryOutput{Items: pages[1]}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	var dump bytes.Buffer
+	out, err := client.(*dynamomq.ClientImpl[test.MessageData]).DumpMessages(context.Background(), &dump,
+		&dynamomq.DumpMessagesInput{QueueType: dynamomq.QueueTypeStandard})
+	if err != nil {
+		t.Fatalf("DumpMessages() error = %v", err)
+	}
+	if out.Dumped != 2 {
+		t.Errorf("DumpMessages() dumped = %d, want 2", out.Dumped)
+	}
+	var ids []string
+	scanner := bufio.NewScanner(&dump)
+	for scanner.Scan() {
+		var line map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("line %q is not JSON: %v", scanner.Text(), err)
+		}
+		if _, ok := line["data"]; ok {
+			t.Errorf("line %q has data", scanner.Text())
+		}
+		ids = append(ids, line["id"].(string))
+	}
+	test.AssertDeepEqual(t, ids, []string{"A-101", "A-102"}, "DumpMessages() IDs")
+}
+
+func TestDynamoMQClientRestoreMessagesInBatches(t *testing.T) {
+	t.Parallel()
+	var dump bytes.Buffer
+	encoder := json.NewEncoder(&dump)
+	for i := 0; i < 30; i++ {
+		id := fmt.Sprintf("A-%d", 101+i)
+		_ = encoder.Encode(NewTestMessageItemAsReady(id, test.DefaultTestDate))
+	}
+	// A message repeated within a batch is written in the next one.
+	_ = encoder.Encode(NewTestMessageItemAsReady("A-130", test.DefaultTestDate))
+	var batches []int
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			BatchWriteItemFunc: func(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+				for table, requests := range params.RequestItems {
+					batches = append(batches, len(requests))
+					// The first call leaves its last two items unprocessed.
+					if len(batches) == 1 {
+						return &dynamodb.BatchWriteItemOutput{
+							UnprocessedItems: map[string][]types.WriteRequest{table: requests[len(requests)-2:]},
+						}, nil
+					}
+				}
+				return &dynamodb.BatchWriteItemOutput{}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	out, err := client.(*dynamomq.ClientImpl[test.MessageData]).RestoreMessages(context.Background(), &dump, nil)
+	if err != nil {
+		t.Fatalf("RestoreMessages() error = %v", err)
+	}
+	if out.Restored != 31 {
+		t.Errorf("RestoreMessages() restored = %d, want 31", out.Restored)
+	}
+	test.AssertDeepEqual(t, batches, []int{25, 2, 5, 1}, "BatchWriteItem() sizes")
+}
+
+func TestDynamoMQClientRestoreMessagesShouldReturnInvalidDumpError(t *testing.T) {
+	t.Parallel()
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	impl := client.(*dynamomq.ClientImpl[test.MessageData])
+	for _, tt := range []struct {
+		name string
+		dump string
+	}{
+		{name: "malformed JSON", dump: `{"id":"A-101"}` + "\n" + `{"id":`},
+		{name: "missing ID", dump: `{"id":"A-101"}` + "\n" + `{"receive_count":1}`},
+	} {
+		_, err := impl.RestoreMessages(context.Background(), strings.NewReader(tt.dump), nil)
+		var invalid dynamomq.InvalidDumpError
+		if !errors.As(err, &invalid) || invalid.Record != 2 {
+			t.Errorf("%s: RestoreMessages() error = %v, want an InvalidDumpError for record 2", tt.name, err)
+		}
+	}
+}
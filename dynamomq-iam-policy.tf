@@ -19,6 +19,7 @@ resource "aws_iam_policy" "dynamomq_policy" {
           "dynamodb:BatchWriteItem",
           "dynamodb:TransactWriteItems",
           "dynamodb:DescribeTable",
+          "dynamodb:DescribeTimeToLive",
           "dynamodb:CreateTable"
         ]
         Resource = [
@@ -1,7 +1,7 @@
 resource "aws_dynamodb_table" "dynamo_mq_table" {
-  name           = "dynamo-mq-table"
-  billing_mode   = "PAY_PER_REQUEST"
-  hash_key       = "id"
+  name                        = "dynamo-mq-table"
+  billing_mode                = "PAY_PER_REQUEST"
+  hash_key                    = "id"
   deletion_protection_enabled = true
 
   attribute {
@@ -20,9 +20,9 @@ resource "aws_dynamodb_table" "dynamo_mq_table" {
   }
 
   global_secondary_index {
-    name               = "dynamo-mq-index-queue_type-sent_at"
-    hash_key           = "queue_type"
-    range_key          = "sent_at"
-    projection_type    = "ALL"
+    name            = "dynamo-mq-index-queue_type-sent_at"
+    hash_key        = "queue_type"
+    range_key       = "sent_at"
+    projection_type = "ALL"
   }
 }
@@ -0,0 +1,158 @@
+// Package dynamomqtest provides factories of messages in each state of a DynamoMQ queue,
+// to build the expectations of tests and to seed tables with the items DynamoMQ would have written.
+package dynamomqtest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+	"github.com/vvatanabe/dynamomq/internal/constant"
+)
+
+// MessageOptions are the attributes of a message built by a factory that differ from the defaults of its state.
+type MessageOptions struct {
+	// SentAt is the time the message was sent to the queue. By default, it is the time given to the factory.
+	SentAt time.Time
+	// ReceiveCount is the number of times the message has been received. By default, it is zero.
+	ReceiveCount int
+	// QueueType is the queue type of a ready or processing message. By default, it is STANDARD.
+	QueueType dynamomq.QueueType
+	// Version is the version of the message. By default, it is one.
+	Version int
+	// VisibilityTimeout is the visibility timeout of a processing message. By default, it is 30 seconds.
+	VisibilityTimeout time.Duration
+}
+
+// WithSentAt is an option function to set the time the message was sent to the queue.
+func WithSentAt(sentAt time.Time) func(*MessageOptions) {
+	return func(o *MessageOptions) {
+		o.SentAt = sentAt
+	}
+}
+
+// WithReceiveCount is an option function to set the number of times the message has been received.
+func WithReceiveCount(receiveCount int) func(*MessageOptions) {
+	return func(o *MessageOptions) {
+		o.ReceiveCount = receiveCount
+	}
+}
+
+// WithQueueType is an option function to set the queue type of a ready or processing message.
+func WithQueueType(queueType dynamomq.QueueType) func(*MessageOptions) {
+	return func(o *MessageOptions) {
+		o.QueueType = queueType
+	}
+}
+
+// WithVersion is an option function to set the version of the message.
+func WithVersion(version int) func(*MessageOptions) {
+	return func(o *MessageOptions) {
+		o.Version = version
+	}
+}
+
+// WithVisibilityTimeout is an option function to set the visibility timeout of a processing message.
+func WithVisibilityTimeout(visibilityTimeout time.Duration) func(*MessageOptions) {
+	return func(o *MessageOptions) {
+		o.VisibilityTimeout = visibilityTimeout
+	}
+}
+
+func newMessage[T any](id string, data T, now time.Time, optFns []func(*MessageOptions)) (*dynamomq.Message[T], *MessageOptions) {
+	o := &MessageOptions{
+		QueueType:         dynamomq.QueueTypeStandard,
+		Version:           1,
+		VisibilityTimeout: constant.DefaultVisibilityTimeout,
+	}
+	for _, opt := range optFns {
+		opt(o)
+	}
+	m := dynamomq.NewMessage(id, data, now)
+	if !o.SentAt.IsZero() {
+		m.SentAt = clock.FormatRFC3339Nano(o.SentAt)
+	}
+	m.ReceiveCount = o.ReceiveCount
+	m.QueueType = o.QueueType
+	m.Version = o.Version
+	return m, o
+}
+
+// NewReadyMessage returns a message sent at now and ready to be received.
+func NewReadyMessage[T any](id string, data T, now time.Time, opts ...func(*MessageOptions)) *dynamomq.Message[T] {
+	m, _ := newMessage(id, data, now, opts)
+	return m
+}
+
+// NewProcessingMessage returns a message sent at now and received at the same time,
+// invisible for its visibility timeout.
+func NewProcessingMessage[T any](id string, data T, now time.Time, opts ...func(*MessageOptions)) *dynamomq.Message[T] {
+	m, o := newMessage(id, data, now, opts)
+	markAsProcessing(m, now, o.VisibilityTimeout)
+	return m
+}
+
+// NewDLQMessage returns a message moved to the DLQ at now.
+// The QueueType option is ignored, and the receive count is reset like MoveMessageToDLQ does unless it is set.
+func NewDLQMessage[T any](id string, data T, now time.Time, opts ...func(*MessageOptions)) *dynamomq.Message[T] {
+	m, o := newMessage(id, data, now, opts)
+	MarkAsMovedToDLQ(m, now)
+	m.ReceiveCount = o.ReceiveCount
+	return m
+}
+
+// MarkAsProcessing changes the message as if it was received at now with the default visibility timeout.
+// Unlike ReceiveMessage, it changes neither the version nor the receive count.
+func MarkAsProcessing[T any](m *dynamomq.Message[T], now time.Time) {
+	markAsProcessing(m, now, constant.DefaultVisibilityTimeout)
+}
+
+func markAsProcessing[T any](m *dynamomq.Message[T], now time.Time, visibilityTimeout time.Duration) {
+	ts := clock.FormatRFC3339Nano(now)
+	m.UpdatedAt = ts
+	m.ReceivedAt = ts
+	m.InvisibleUntilAt = clock.FormatRFC3339Nano(now.Add(visibilityTimeout))
+}
+
+// MarkAsMovedToDLQ changes the message as if it was moved to the DLQ at now.
+// Unlike MoveMessageToDLQ, it does not change the version.
+func MarkAsMovedToDLQ[T any](m *dynamomq.Message[T], now time.Time) {
+	ts := clock.FormatRFC3339Nano(now)
+	m.QueueType = dynamomq.QueueTypeDLQ
+	m.ReceiveCount = 0
+	m.UpdatedAt = ts
+	m.SentAt = ts
+	m.ReceivedAt = ""
+	m.InvisibleUntilAt = ""
+}
+
+// MarkAsRestoredFromDLQ changes the message as if it was redriven from the DLQ at now.
+// Unlike RedriveMessage, it does not change the version.
+func MarkAsRestoredFromDLQ[T any](m *dynamomq.Message[T], now time.Time) {
+	ts := clock.FormatRFC3339Nano(now)
+	m.QueueType = dynamomq.QueueTypeStandard
+	m.ReceiveCount = 0
+	m.UpdatedAt = ts
+	m.SentAt = ts
+	m.ReceivedAt = ""
+	m.InvisibleUntilAt = ""
+}
+
+// MarshalMap returns the item DynamoMQ stores for the message. It panics if the message cannot be marshaled,
+// which only happens when its data cannot be represented in DynamoDB.
+func MarshalMap[T any](m *dynamomq.Message[T]) map[string]types.AttributeValue {
+	item, err := m.MarshalMap()
+	if err != nil {
+		panic(fmt.Sprintf("dynamomqtest: failed to marshal message %s: %v", m.ID, err))
+	}
+	return item
+}
+
+// NewPutRequest returns a request writing the message, to seed a table with BatchWriteItem.
+func NewPutRequest[T any](m *dynamomq.Message[T]) *types.PutRequest {
+	return &types.PutRequest{
+		Item: MarshalMap(m),
+	}
+}
@@ -0,0 +1,99 @@
+package dynamomqtest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestFactories(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate
+	ts := clock.FormatRFC3339Nano(now)
+	data := test.NewMessageData("A-101")
+	tests := []struct {
+		name       string
+		got        *dynamomq.Message[test.MessageData]
+		want       *dynamomq.Message[test.MessageData]
+		wantStatus dynamomq.Status
+	}{
+		{
+			name: "ready",
+			got:  dynamomqtest.NewReadyMessage("A-101", data, now),
+			want: dynamomq.NewMessage("A-101", data, now),
+		},
+		{
+			name: "ready with options",
+			got: dynamomqtest.NewReadyMessage("A-101", data, now,
+				dynamomqtest.WithSentAt(now.Add(time.Minute)),
+				dynamomqtest.WithReceiveCount(2),
+				dynamomqtest.WithQueueType("PRIORITY"),
+				dynamomqtest.WithVersion(3)),
+			want: &dynamomq.Message[test.MessageData]{
+				ID:            "A-101",
+				Data:          data,
+				ReceiveCount:  2,
+				QueueType:     "PRIORITY",
+				Version:       3,
+				CreatedAt:     ts,
+				UpdatedAt:     ts,
+				SentAt:        clock.FormatRFC3339Nano(now.Add(time.Minute)),
+				FormatVersion: dynamomq.FormatVersion,
+			},
+		},
+		{
+			name: "processing",
+			got:  dynamomqtest.NewProcessingMessage("A-101", data, now, dynamomqtest.WithVisibilityTimeout(time.Minute)),
+			want: &dynamomq.Message[test.MessageData]{
+				ID:               "A-101",
+				Data:             data,
+				QueueType:        dynamomq.QueueTypeStandard,
+				Version:          1,
+				CreatedAt:        ts,
+				UpdatedAt:        ts,
+				SentAt:           ts,
+				ReceivedAt:       ts,
+				InvisibleUntilAt: clock.FormatRFC3339Nano(now.Add(time.Minute)),
+				FormatVersion:    dynamomq.FormatVersion,
+			},
+			wantStatus: dynamomq.StatusProcessing,
+		},
+		{
+			name: "DLQ",
+			got:  dynamomqtest.NewDLQMessage("A-101", data, now, dynamomqtest.WithQueueType("PRIORITY")),
+			want: &dynamomq.Message[test.MessageData]{
+				ID:            "A-101",
+				Data:          data,
+				QueueType:     dynamomq.QueueTypeDLQ,
+				Version:       1,
+				CreatedAt:     ts,
+				UpdatedAt:     ts,
+				SentAt:        ts,
+				FormatVersion: dynamomq.FormatVersion,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			test.AssertDeepEqual(t, tt.got, tt.want, "message")
+			wantStatus := tt.wantStatus
+			if wantStatus == "" {
+				wantStatus = dynamomq.StatusReady
+			}
+			if status := tt.got.GetStatus(now); status != wantStatus {
+				t.Errorf("GetStatus() = %s, want %s", status, wantStatus)
+			}
+			unmarshaled, err := dynamomq.UnmarshalMessage[test.MessageData](dynamomqtest.NewPutRequest(tt.got).Item)
+			if err != nil {
+				t.Fatalf("UnmarshalMessage() error = %v", err)
+			}
+			test.AssertDeepEqual(t, unmarshaled, tt.want, "UnmarshalMessage()")
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package dynamomq_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/constant"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestDynamoMQClientReceiveMessageShouldHintAtNextVisibleMessage(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	tests := []struct {
+		name string
+		opts []func(*dynamomq.ClientOptions)
+		// receivedAt are the times the messages of the queue were received, in the order of the queue.
+		receivedAt []time.Time
+		want       *dynamomq.EmptyQueueError
+	}{
+		{
+			name: "should not hint when the queue holds no message",
+			want: &dynamomq.EmptyQueueError{},
+		},
+		{
+			name: "should hint at the soonest message across pages",
+			receivedAt: func() []time.Time {
+				times := make([]time.Time, 15)
+				for i := range times {
+					times[i] = now.Add(-time.Duration(i) * time.Second)
+				}
+				return times
+			}(),
+			want: &dynamomq.EmptyQueueError{
+				NextVisibleAt: now.Add(-14 * time.Second).Add(constant.DefaultVisibilityTimeout),
+				RetryAfter:    constant.DefaultVisibilityTimeout - 14*time.Second,
+			},
+		},
+		{
+			name:       "should hint at the first message in a FIFO queue",
+			opts:       []func(*dynamomq.ClientOptions){dynamomq.WithUseFIFO(true)},
+			receivedAt: []time.Time{now.Add(-5 * time.Second), now.Add(-20 * time.Second)},
+			want: &dynamomq.EmptyQueueError{
+				NextVisibleAt: now.Add(-5 * time.Second).Add(constant.DefaultVisibilityTimeout),
+				RetryAfter:    constant.DefaultVisibilityTimeout - 5*time.Second,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			queue := &pagedQueue{}
+			for i, receivedAt := range tt.receivedAt {
+				queue.items = append(queue.items, dynamomqtest.MarshalMap(NewTestMessageItemAsProcessing(fmt.Sprintf("B-%03d", i), receivedAt)))
+			}
+			client := newPagedQueueClient(t, queue, now, tt.opts...)
+			_, err := client.ReceiveMessage(context.Background(), &dynamomq.ReceiveMessageInput{})
+			var got *dynamomq.EmptyQueueError
+			if !errors.As(err, &got) {
+				t.Fatalf("ReceiveMessage() error = %v, want %v", err, tt.want)
+			}
+			test.AssertDeepEqual(t, got, tt.want, "ReceiveMessage() error")
+			if !errors.Is(err, &dynamomq.EmptyQueueError{}) {
+				t.Errorf("errors.Is(%v, &EmptyQueueError{}) = false, want true", err)
+			}
+		})
+	}
+}
+
+func TestConsumerShouldPollAtNextVisibleMessage(t *testing.T) {
+	t.Parallel()
+	msg := NewTestMessageItemAsReady("A-101", test.DefaultTestDate)
+	var polls atomic.Int32
+	client := &mock.Client[test.MessageData]{
+		ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+			switch polls.Add(1) {
+			case 1:
+				return nil, &dynamomq.EmptyQueueError{
+					NextVisibleAt: test.DefaultTestDate.Add(10 * time.Millisecond),
+					RetryAfter:    10 * time.Millisecond,
+				}
+			case 2:
+				return &dynamomq.ReceiveMessageOutput[test.MessageData]{ReceivedMessage: msg}, nil
+			}
+			return nil, &dynamomq.EmptyQueueError{}
+		},
+		DeleteMessageFunc: func(ctx context.Context, params *dynamomq.DeleteMessageInput) (*dynamomq.DeleteMessageOutput, error) {
+			return &dynamomq.DeleteMessageOutput{}, nil
+		},
+	}
+	processed := make(chan struct{}, 1)
+	consumer := dynamomq.NewConsumer[test.MessageData](client,
+		dynamomq.MessageProcessorFunc[test.MessageData](func(msg *dynamomq.Message[test.MessageData]) error {
+			processed <- struct{}{}
+			return nil
+		}),
+		dynamomq.WithPollingInterval(time.Hour),
+		dynamomq.WithErrorLog(log.New(io.Discard, "", 0)))
+	go func() {
+		_ = consumer.StartConsuming()
+	}()
+	select {
+	case <-processed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the message was not received after the hint of the EmptyQueueError")
+	}
+	if err := consumer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+}
+
+func TestDynamoMQClientReceiveMessageWithEmptyReceiveAsNil(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	tests := []struct {
+		name       string
+		processing int
+		ready      int
+		emptyAsNil bool
+		wantNil    bool
+		wantErr    error
+	}{
+		{
+			name:    "should return EmptyQueueError by default",
+			wantErr: dynamomq.ErrEmptyQueue,
+		},
+		{
+			name:       "should return nil when the queue holds no message",
+			emptyAsNil: true,
+			wantNil:    true,
+		},
+		{
+			name:       "should return nil when every message is being processed",
+			processing: 3,
+			emptyAsNil: true,
+			wantNil:    true,
+		},
+		{
+			name:       "should return the message when there is one",
+			processing: 3,
+			ready:      1,
+			emptyAsNil: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			queue := &pagedQueue{}
+			for i := 0; i < tt.processing; i++ {
+				queue.items = append(queue.items, dynamomqtest.MarshalMap(NewTestMessageItemAsProcessing(fmt.Sprintf("B-%03d", i), now)))
+			}
+			for i := 0; i < tt.ready; i++ {
+				queue.items = append(queue.items, dynamomqtest.MarshalMap(NewTestMessageItemAsReady(fmt.Sprintf("A-%03d", i), test.DefaultTestDate)))
+			}
+			client := newPagedQueueClient(t, queue, now, dynamomq.WithEmptyReceiveAsNil(tt.emptyAsNil))
+			out, err := client.ReceiveMessage(context.Background(), &dynamomq.ReceiveMessageInput{})
+			test.AssertError(t, err, tt.wantErr, "ReceiveMessage()")
+			if tt.wantErr != nil {
+				return
+			}
+			if got := out == nil; got != tt.wantNil {
+				t.Fatalf("ReceiveMessage() output = %+v, want nil %v", out, tt.wantNil)
+			}
+			if !tt.wantNil && out.ReceivedMessage.ID != "A-000" {
+				t.Errorf("ReceiveMessage() id = %s, want A-000", out.ReceivedMessage.ID)
+			}
+		})
+	}
+}
+
+func TestConsumerWithEmptyReceiveAsNil(t *testing.T) {
+	t.Parallel()
+	msg := NewTestMessageItemAsReady("A-101", test.DefaultTestDate)
+	var polls atomic.Int32
+	client := &mock.Client[test.MessageData]{
+		ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+			if polls.Add(1) == 2 {
+				return &dynamomq.ReceiveMessageOutput[test.MessageData]{ReceivedMessage: msg}, nil
+			}
+			return nil, nil
+		},
+		DeleteMessageFunc: func(ctx context.Context, params *dynamomq.DeleteMessageInput) (*dynamomq.DeleteMessageOutput, error) {
+			return &dynamomq.DeleteMessageOutput{}, nil
+		},
+	}
+	processed := make(chan string, 1)
+	consumer := dynamomq.NewConsumer[test.MessageData](client,
+		dynamomq.MessageProcessorFunc[test.MessageData](func(msg *dynamomq.Message[test.MessageData]) error {
+			processed <- msg.ID
+			return nil
+		}),
+		dynamomq.WithPollingInterval(10*time.Millisecond),
+		dynamomq.WithErrorLog(log.New(io.Discard, "", 0)))
+	returned := make(chan error, 1)
+	go func() {
+		returned <- consumer.StartConsuming()
+	}()
+	select {
+	case id := <-processed:
+		if id != "A-101" {
+			t.Errorf("processed message id = %s, want A-101", id)
+		}
+	case err := <-returned:
+		t.Fatalf("StartConsuming() returned early with %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the message was not processed after an empty receive")
+	}
+	if err := consumer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	select {
+	case err := <-returned:
+		if !errors.Is(err, dynamomq.ErrConsumerClosed) {
+			t.Errorf("StartConsuming() error = %v, want %v", err, dynamomq.ErrConsumerClosed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("StartConsuming() did not return after Shutdown")
+	}
+}
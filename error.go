@@ -1,6 +1,34 @@
 package dynamomq
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// The sentinel errors below are matched by the error types of the same kind, so that
+// errors.Is(err, ErrIDNotFound) reports whether err is an IDNotFoundError, whatever its fields.
+// Match an error with errors.Is and its sentinel, and use errors.As with the error type only to read its fields:
+//
+//	if errors.Is(err, dynamomq.ErrEmptyQueue) {
+//		var empty *dynamomq.EmptyQueueError
+//		if errors.As(err, &empty) {
+//			time.Sleep(empty.RetryAfter)
+//		}
+//	}
+var (
+	// ErrEmptyQueue is matched by EmptyQueueError.
+	ErrEmptyQueue = errors.New("DynamoMQ: Queue is empty")
+	// ErrIDNotProvided is matched by IDNotProvidedError.
+	ErrIDNotProvided = errors.New("DynamoMQ: ID not provided")
+	// ErrIDNotFound is matched by IDNotFoundError.
+	ErrIDNotFound = errors.New("DynamoMQ: ID not found")
+	// ErrIDDuplicated is matched by IDDuplicatedError.
+	ErrIDDuplicated = errors.New("DynamoMQ: ID duplicated")
+	// ErrVersionConflict is matched by VersionConflictError, returned when the message does not have the expected version.
+	ErrVersionConflict = errors.New("DynamoMQ: Version conflict")
+)
 
 // IDNotProvidedError represents an error when an ID is not provided where it is required.
 type IDNotProvidedError struct{}
@@ -10,6 +38,11 @@ func (e IDNotProvidedError) Error() string {
 	return "ID was not provided."
 }
 
+// Is reports whether target is ErrIDNotProvided.
+func (e IDNotProvidedError) Is(target error) bool {
+	return target == ErrIDNotProvided
+}
+
 // IDNotFoundError represents an error when a provided ID is not found in DynamoDB.
 type IDNotFoundError struct{}
 
@@ -18,6 +51,11 @@ func (e IDNotFoundError) Error() string {
 	return "Provided ID was not found in the Dynamo DB."
 }
 
+// Is reports whether target is ErrIDNotFound.
+func (e IDNotFoundError) Is(target error) bool {
+	return target == ErrIDNotFound
+}
+
 // IDDuplicatedError represents an error when a provided ID is duplicated in the system.
 type IDDuplicatedError struct{}
 
@@ -26,6 +64,11 @@ func (e IDDuplicatedError) Error() string {
 	return "Provided ID was duplicated."
 }
 
+// Is reports whether target is ErrIDDuplicated.
+func (e IDDuplicatedError) Is(target error) bool {
+	return target == ErrIDDuplicated
+}
+
 // ConditionalCheckFailedError represents an error when a condition check on the 'version' attribute fails.
 type ConditionalCheckFailedError struct {
 	Cause error
@@ -36,6 +79,25 @@ func (e ConditionalCheckFailedError) Error() string {
 	return fmt.Sprintf("Condition on the 'version' attribute has failed: %v.", e.Cause)
 }
 
+// Is reports whether target is a ConditionalCheckFailedError with the same cause.
+// A ConditionalCheckFailedError without a cause matches any ConditionalCheckFailedError.
+// It does not match ErrVersionConflict, which is matched by VersionConflictError only.
+func (e ConditionalCheckFailedError) Is(target error) bool {
+	var t ConditionalCheckFailedError
+	switch v := target.(type) {
+	case *ConditionalCheckFailedError:
+		if v == nil {
+			return false
+		}
+		t = *v
+	case ConditionalCheckFailedError:
+		t = v
+	default:
+		return false
+	}
+	return t.Cause == nil || t.Cause == e.Cause
+}
+
 // BuildingExpressionError represents an error during the building of a DynamoDB expression.
 type BuildingExpressionError struct {
 	Cause error
@@ -56,6 +118,147 @@ func (e DynamoDBAPIError) Error() string {
 	return fmt.Sprintf("Failed DynamoDB API: %v.", e.Cause)
 }
 
+// Retryable reports true, since a DynamoDB API failure that is not classified otherwise is assumed to be transient.
+func (e DynamoDBAPIError) Retryable() bool {
+	return true
+}
+
+// RetryableError is implemented by errors that know whether the failed operation may succeed if it is retried.
+type RetryableError interface {
+	error
+	// Retryable reports whether the failed operation may succeed if it is retried later.
+	Retryable() bool
+}
+
+// ThrottledError represents an error when a DynamoDB request was throttled
+// because the provisioned throughput or an account level limit was exceeded.
+type ThrottledError struct {
+	Cause error
+}
+
+// Error returns a detailed error message including the underlying cause for ThrottledError.
+func (e ThrottledError) Error() string {
+	return fmt.Sprintf("DynamoDB request was throttled: %v.", e.Cause)
+}
+
+// Unwrap returns the underlying cause of the ThrottledError.
+func (e ThrottledError) Unwrap() error {
+	return e.Cause
+}
+
+// Retryable reports true, since a throttled request is expected to succeed once the load decreases.
+func (e ThrottledError) Retryable() bool {
+	return true
+}
+
+// ResourceNotFoundError represents an error when the DynamoDB table or its index does not exist.
+type ResourceNotFoundError struct {
+	Cause error
+}
+
+// Error returns a detailed error message including the underlying cause and a hint on how to create the table.
+func (e ResourceNotFoundError) Error() string {
+	return fmt.Sprintf("DynamoDB table or index was not found, "+
+		"create it with the table definition in dynamomq-table.json or dynamomq-table.tf: %v.", e.Cause)
+}
+
+// Unwrap returns the underlying cause of the ResourceNotFoundError.
+func (e ResourceNotFoundError) Unwrap() error {
+	return e.Cause
+}
+
+// Retryable reports false, since the request cannot succeed until the table is created.
+func (e ResourceNotFoundError) Retryable() bool {
+	return false
+}
+
+// SchemaMismatchError represents an error when the DynamoDB table does not match the schema expected by DynamoMQ.
+// Problems lists every difference that was found, such as a missing index or a wrong key.
+type SchemaMismatchError struct {
+	TableName string
+	Problems  []string
+}
+
+// Error returns a detailed error message listing every problem found in the table schema.
+func (e SchemaMismatchError) Error() string {
+	return fmt.Sprintf("Table '%s' does not match the schema expected by DynamoMQ: %s.",
+		e.TableName, strings.Join(e.Problems, "; "))
+}
+
+// InvalidTableSchemaError represents an error when a TableSchema cannot be used to store messages.
+type InvalidTableSchemaError struct {
+	Reason string
+}
+
+// Error returns a detailed error message explaining why the TableSchema is invalid.
+func (e InvalidTableSchemaError) Error() string {
+	return fmt.Sprintf("Invalid table schema: %s.", e.Reason)
+}
+
+// DeletionNotConfirmedError represents an error when a queue table is about to be deleted without an explicit confirmation.
+type DeletionNotConfirmedError struct {
+	TableName string
+}
+
+// Error returns a detailed error message including the name of the table that was not deleted.
+func (e DeletionNotConfirmedError) Error() string {
+	return fmt.Sprintf("Deletion of table '%s' was not confirmed.", e.TableName)
+}
+
+// VersionConflictError represents an error when a message does not have the expected version,
+// either because it was updated by another receiver or because it no longer exists.
+type VersionConflictError struct {
+	ID      string
+	Version int
+}
+
+// Error returns a detailed error message including the ID and the expected version of the message.
+func (e VersionConflictError) Error() string {
+	return fmt.Sprintf("Message '%s' is not at version %d.", e.ID, e.Version)
+}
+
+// Is reports whether target is ErrVersionConflict.
+func (e VersionConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
+// ValidationError represents an error when DynamoDB rejected a request as invalid.
+// It usually indicates a bug or a misconfiguration rather than a transient failure.
+type ValidationError struct {
+	Cause error
+}
+
+// Error returns a detailed error message including the underlying cause for ValidationError.
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("DynamoDB rejected the request as invalid: %v.", e.Cause)
+}
+
+// Unwrap returns the underlying cause of the ValidationError.
+func (e ValidationError) Unwrap() error {
+	return e.Cause
+}
+
+// Retryable reports false, since an invalid request fails the same way every time.
+func (e ValidationError) Retryable() bool {
+	return false
+}
+
+// OperationCanceledError represents an error when an operation spanning several DynamoDB calls is stopped
+// because its context was canceled or its deadline was exceeded between two calls.
+type OperationCanceledError struct {
+	Cause error
+}
+
+// Error returns a detailed error message including the underlying cause for OperationCanceledError.
+func (e OperationCanceledError) Error() string {
+	return fmt.Sprintf("Operation canceled: %v.", e.Cause)
+}
+
+// Unwrap returns the underlying context error, so that errors.Is(err, context.Canceled) holds.
+func (e OperationCanceledError) Unwrap() error {
+	return e.Cause
+}
+
 // UnmarshalingAttributeError represents an error during the unmarshaling of DynamoDB attributes.
 type UnmarshalingAttributeError struct {
 	Cause error
@@ -66,6 +269,28 @@ func (e UnmarshalingAttributeError) Error() string {
 	return fmt.Sprintf("Failed to unmarshal: %v.", e.Cause)
 }
 
+// Unwrap returns the underlying cause of the UnmarshalingAttributeError.
+func (e UnmarshalingAttributeError) Unwrap() error {
+	return e.Cause
+}
+
+// CorruptMessageError represents an error when an item in the queue cannot be unmarshaled into a message.
+// It wraps the underlying UnmarshalingAttributeError and carries the ID of the offending item, if it could be read.
+type CorruptMessageError struct {
+	ID    string
+	Cause error
+}
+
+// Error returns a detailed error message including the ID of the corrupt item and the underlying cause.
+func (e CorruptMessageError) Error() string {
+	return fmt.Sprintf("Corrupt message '%s': %v", e.ID, e.Cause)
+}
+
+// Unwrap returns the underlying cause of the CorruptMessageError.
+func (e CorruptMessageError) Unwrap() error {
+	return e.Cause
+}
+
 // MarshalingAttributeError represents an error during the marshaling of DynamoDB attributes.
 type MarshalingAttributeError struct {
 	Cause error
@@ -77,21 +302,330 @@ func (e MarshalingAttributeError) Error() string {
 }
 
 // EmptyQueueError represents an error when an operation cannot proceed due to an empty queue.
-type EmptyQueueError struct{}
+// When the queue only holds messages being processed by other consumers, NextVisibleAt is the time
+// the first of them becomes visible again, and RetryAfter the time left until then.
+// Both are zero when the queue holds no message at all.
+type EmptyQueueError struct {
+	NextVisibleAt time.Time
+	RetryAfter    time.Duration
+}
 
 // Error returns a standard error message for EmptyQueueError.
 func (e EmptyQueueError) Error() string {
-	return "Cannot proceed, queue is empty."
+	if e.NextVisibleAt.IsZero() {
+		return "Cannot proceed, queue is empty."
+	}
+	return fmt.Sprintf("Cannot proceed, queue is empty, retry after %s.", e.RetryAfter)
+}
+
+// Is reports whether target is ErrEmptyQueue or an EmptyQueueError with the same hint.
+// An EmptyQueueError without a hint matches any EmptyQueueError.
+func (e EmptyQueueError) Is(target error) bool {
+	if target == ErrEmptyQueue {
+		return true
+	}
+	var t EmptyQueueError
+	switch v := target.(type) {
+	case *EmptyQueueError:
+		if v == nil {
+			return false
+		}
+		t = *v
+	case EmptyQueueError:
+		t = v
+	default:
+		return false
+	}
+	return t.NextVisibleAt.IsZero() || (t.NextVisibleAt.Equal(e.NextVisibleAt) && t.RetryAfter == e.RetryAfter)
+}
+
+// newEmptyQueueError returns an EmptyQueueError hinting at nextVisibleAt, if it is not zero.
+func newEmptyQueueError(now, nextVisibleAt time.Time) *EmptyQueueError {
+	if nextVisibleAt.IsZero() {
+		return &EmptyQueueError{}
+	}
+	retryAfter := nextVisibleAt.Sub(now)
+	if retryAfter < 0 {
+		retryAfter = 0
+	}
+	return &EmptyQueueError{NextVisibleAt: nextVisibleAt, RetryAfter: retryAfter}
+}
+
+// QueuePausedError represents an error when a message cannot be received because the queue is paused with SetQueueEnabled.
+type QueuePausedError struct{}
+
+// Error returns a standard error message for QueuePausedError.
+func (e QueuePausedError) Error() string {
+	return "Cannot proceed, queue is paused."
+}
+
+// InFlightLimitExceededError represents an error when a message cannot be received because the number of messages
+// being processed has reached the limit set with WithMaxInFlight.
+type InFlightLimitExceededError struct {
+	Limit int
+}
+
+// Error returns a detailed error message including the limit for InFlightLimitExceededError.
+func (e InFlightLimitExceededError) Error() string {
+	return fmt.Sprintf("Cannot proceed, %d messages are already in flight.", e.Limit)
+}
+
+// MessageHeldError represents an error when a message cannot be held, redriven or otherwise changed
+// because it is held with HoldMessage.
+type MessageHeldError struct {
+	ID     string
+	Reason string
+}
+
+// Error returns a detailed error message including the ID of the message and the reason it is held for.
+func (e MessageHeldError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("Message '%s' is held.", e.ID)
+	}
+	return fmt.Sprintf("Message '%s' is held: %s.", e.ID, e.Reason)
+}
+
+// MessageNotHeldError represents an error when a message cannot be released with ReleaseMessage because it is not held.
+type MessageNotHeldError struct {
+	ID string
+}
+
+// Error returns a detailed error message including the ID of the message.
+func (e MessageNotHeldError) Error() string {
+	return fmt.Sprintf("Message '%s' is not held.", e.ID)
+}
+
+// InvalidNextTokenError represents an error when a pagination token cannot be decoded.
+type InvalidNextTokenError struct {
+	Reason string
+}
+
+// Error returns a detailed error message including the reason the token is invalid.
+func (e InvalidNextTokenError) Error() string {
+	return fmt.Sprintf("Invalid next token: %s.", e.Reason)
 }
 
 // InvalidStateTransitionError represents an error for invalid state transitions during operations.
+// Current and Requested are statuses of the state machine of CanTransition.
 type InvalidStateTransitionError struct {
 	Msg       string
 	Operation string
 	Current   Status
+	Requested Status
 }
 
 // Error returns a detailed error message explaining the invalid state transition.
 func (e InvalidStateTransitionError) Error() string {
-	return fmt.Sprintf("operation %s failed for status %s: %s.", e.Operation, e.Current, e.Msg)
+	if e.Requested == "" {
+		return fmt.Sprintf("operation %s failed for status %s: %s.", e.Operation, e.Current, e.Msg)
+	}
+	return fmt.Sprintf("operation %s failed for status %s to %s: %s.", e.Operation, e.Current, e.Requested, e.Msg)
+}
+
+// InvalidTimestampError represents an error when a timestamp attribute stored on a message cannot be parsed.
+type InvalidTimestampError struct {
+	Attribute string
+	Value     string
+	Cause     error
+}
+
+// Error returns a detailed error message including the attribute name and the malformed value.
+func (e InvalidTimestampError) Error() string {
+	return fmt.Sprintf("Invalid timestamp in '%s' attribute %q: %v.", e.Attribute, e.Value, e.Cause)
+}
+
+// InvalidDumpError represents an error when a message read by RestoreMessages cannot be decoded or restored.
+type InvalidDumpError struct {
+	Record int
+	Cause  error
+}
+
+// Error returns a detailed error message including the position of the message in the dump.
+func (e InvalidDumpError) Error() string {
+	return fmt.Sprintf("Invalid message #%d in dump: %v.", e.Record, e.Cause)
+}
+
+// Unwrap returns the underlying cause of the InvalidDumpError.
+func (e InvalidDumpError) Unwrap() error {
+	return e.Cause
+}
+
+// ArchiveError represents an error when a message cannot be archived before its deletion. The message is not deleted.
+type ArchiveError struct {
+	ID    string
+	Cause error
+}
+
+// Error returns a detailed error message including the ID of the message and the cause.
+func (e ArchiveError) Error() string {
+	return fmt.Sprintf("Failed to archive message '%s', it was not deleted: %v.", e.ID, e.Cause)
+}
+
+// Unwrap returns the underlying cause of the ArchiveError.
+func (e ArchiveError) Unwrap() error {
+	return e.Cause
+}
+
+// InvalidArchiverError represents an error when the Archiver set with WithArchiver does not archive
+// the type of message of the client.
+type InvalidArchiverError struct {
+	Archiver    string
+	MessageType string
+}
+
+// Error returns a detailed error message including the type of the archiver and the type of message.
+func (e InvalidArchiverError) Error() string {
+	return fmt.Sprintf("Archiver %s cannot archive messages of type %s.", e.Archiver, e.MessageType)
+}
+
+// InvalidHooksError represents an error when the Hooks set with WithHooks are not for the type of message of the client.
+type InvalidHooksError struct {
+	Hooks       string
+	MessageType string
+}
+
+// Error returns a detailed error message including the type of the hooks and the type of message.
+func (e InvalidHooksError) Error() string {
+	return fmt.Sprintf("Hooks %s cannot observe messages of type %s.", e.Hooks, e.MessageType)
+}
+
+// InvalidSamplerError represents an error when the sink set with WithSampler does not take messages
+// of the type of message of the client.
+type InvalidSamplerError struct {
+	Sampler     string
+	MessageType string
+}
+
+// Error returns a detailed error message including the type of the sampler and the type of message.
+func (e InvalidSamplerError) Error() string {
+	return fmt.Sprintf("Sampler %s cannot sample messages of type %s.", e.Sampler, e.MessageType)
+}
+
+// InvalidPayloadUpgraderError represents an error when the PayloadUpgrader set with WithPayloadUpgrader
+// does not produce the type of message of the client.
+type InvalidPayloadUpgraderError struct {
+	Upgrader    string
+	MessageType string
+}
+
+// Error returns a detailed error message including the type of the upgrader and the type of message.
+func (e InvalidPayloadUpgraderError) Error() string {
+	return fmt.Sprintf("Payload upgrader %s cannot upgrade messages of type %s.", e.Upgrader, e.MessageType)
+}
+
+// PayloadUpgradeError represents an error when the PayloadUpgrader fails to convert the payload of a message
+// written with an older version of its schema.
+type PayloadUpgradeError struct {
+	ID      string
+	Version int
+	Cause   error
+}
+
+// Error returns a detailed error message including the ID of the message, the version of its payload
+// and the underlying cause.
+func (e PayloadUpgradeError) Error() string {
+	return fmt.Sprintf("Failed to upgrade the payload of message '%s' from version %d: %v", e.ID, e.Version, e.Cause)
+}
+
+// Unwrap returns the underlying cause of the PayloadUpgradeError.
+func (e PayloadUpgradeError) Unwrap() error {
+	return e.Cause
+}
+
+// InvalidValidatorError represents an error when the Validator set with WithValidator or WithProducerValidator
+// does not validate messages of the type of the client or the Producer.
+type InvalidValidatorError struct {
+	Validator   string
+	MessageType string
+}
+
+// Error returns a detailed error message including the type of the validator and the type of message.
+func (e InvalidValidatorError) Error() string {
+	return fmt.Sprintf("Validator %s cannot validate messages of type %s.", e.Validator, e.MessageType)
+}
+
+// ValidationFailedError represents an error when the Validator rejects the payload of a message,
+// which is then not written to the table.
+type ValidationFailedError struct {
+	ID        string
+	Operation string
+	Cause     error
+}
+
+// Error returns a detailed error message including the ID of the message, the operation and the underlying cause.
+func (e ValidationFailedError) Error() string {
+	return fmt.Sprintf("%s rejected invalid message '%s': %v", e.Operation, e.ID, e.Cause)
+}
+
+// Unwrap returns the underlying cause of the ValidationFailedError.
+func (e ValidationFailedError) Unwrap() error {
+	return e.Cause
+}
+
+// CanaryTimeoutError represents an error when a canary is not deleted within the timeout of CheckCanary.
+type CanaryTimeoutError struct {
+	ID      string
+	Timeout time.Duration
+}
+
+// Error returns a detailed error message including the ID of the canary and the timeout.
+func (e CanaryTimeoutError) Error() string {
+	return fmt.Sprintf("Canary '%s' was not deleted within %s.", e.ID, e.Timeout)
+}
+
+// InvalidTemplateFormatError represents an error when GenerateTableTemplate is asked for a format it does not support.
+type InvalidTemplateFormatError struct {
+	Format TemplateFormat
+}
+
+// Error returns a detailed error message including the unsupported format and the supported ones.
+func (e InvalidTemplateFormatError) Error() string {
+	return fmt.Sprintf("Invalid template format '%s', want '%s' or '%s'.", e.Format, TemplateFormatCloudFormation, TemplateFormatTerraform)
+}
+
+// OperationTimeoutError represents an error when a DynamoDB API call does not complete within the timeout
+// set with WithOperationTimeout.
+type OperationTimeoutError struct {
+	Operation string
+	Timeout   time.Duration
+	Cause     error
+}
+
+// Error returns a detailed error message including the DynamoDB operation and the timeout.
+func (e OperationTimeoutError) Error() string {
+	return fmt.Sprintf("DynamoDB %s did not complete within %s: %v.", e.Operation, e.Timeout, e.Cause)
+}
+
+// Unwrap returns the underlying cause of the OperationTimeoutError.
+func (e OperationTimeoutError) Unwrap() error {
+	return e.Cause
+}
+
+// Retryable reports true, since the call may complete in time if it is retried.
+func (e OperationTimeoutError) Retryable() bool {
+	return true
+}
+
+// DefaultOperationTimeoutError represents an error when an operation of the client does not complete within
+// the timeout set with WithDefaultOperationTimeout. Step is the DynamoDB API call that was cut short.
+type DefaultOperationTimeoutError struct {
+	Operation string
+	Step      string
+	Timeout   time.Duration
+	Cause     error
+}
+
+// Error returns a detailed error message including the operation, the timeout and the step that timed out.
+func (e DefaultOperationTimeoutError) Error() string {
+	return fmt.Sprintf("%s did not complete within %s, timed out in DynamoDB %s: %v.", e.Operation, e.Timeout, e.Step, e.Cause)
+}
+
+// Unwrap returns the underlying cause of the DefaultOperationTimeoutError.
+func (e DefaultOperationTimeoutError) Unwrap() error {
+	return e.Cause
+}
+
+// Retryable reports true, since the operation may complete in time if it is retried.
+func (e DefaultOperationTimeoutError) Retryable() bool {
+	return true
 }
@@ -1,8 +1,11 @@
 package dynamomq_test
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/vvatanabe/dynamomq"
 )
@@ -17,12 +20,43 @@ func TestErrors(t *testing.T) {
 		{dynamomq.IDNotFoundError{}, "Provided ID was not found in the Dynamo DB."},
 		{dynamomq.IDDuplicatedError{}, "Provided ID was duplicated."},
 		{dynamomq.ConditionalCheckFailedError{Cause: errors.New("sample cause")}, "Condition on the 'version' attribute has failed: sample cause."},
+		{dynamomq.VersionConflictError{ID: "A-101", Version: 2}, "Message 'A-101' is not at version 2."},
 		{dynamomq.BuildingExpressionError{Cause: errors.New("sample cause")}, "Failed to build expression: sample cause."},
 		{dynamomq.DynamoDBAPIError{Cause: errors.New("sample cause")}, "Failed DynamoDB API: sample cause."},
+		{dynamomq.ThrottledError{Cause: errors.New("sample cause")}, "DynamoDB request was throttled: sample cause."},
+		{dynamomq.ResourceNotFoundError{Cause: errors.New("sample cause")}, "DynamoDB table or index was not found, create it with the table definition in dynamomq-table.json or dynamomq-table.tf: sample cause."},
+		{dynamomq.SchemaMismatchError{TableName: "sample table", Problems: []string{"problem 1", "problem 2"}}, "Table 'sample table' does not match the schema expected by DynamoMQ: problem 1; problem 2."},
+		{dynamomq.InvalidTableSchemaError{Reason: "sample reason"}, "Invalid table schema: sample reason."},
+		{dynamomq.DeletionNotConfirmedError{TableName: "sample table"}, "Deletion of table 'sample table' was not confirmed."},
+		{dynamomq.ValidationError{Cause: errors.New("sample cause")}, "DynamoDB rejected the request as invalid: sample cause."},
+		{dynamomq.OperationCanceledError{Cause: errors.New("sample cause")}, "Operation canceled: sample cause."},
 		{dynamomq.UnmarshalingAttributeError{Cause: errors.New("sample cause")}, "Failed to unmarshal: sample cause."},
+		{dynamomq.CorruptMessageError{ID: "A-101", Cause: errors.New("sample cause")}, "Corrupt message 'A-101': sample cause"},
 		{dynamomq.MarshalingAttributeError{Cause: errors.New("sample cause")}, "Failed to marshal: sample cause."},
 		{dynamomq.EmptyQueueError{}, "Cannot proceed, queue is empty."},
+		{dynamomq.EmptyQueueError{NextVisibleAt: time.Date(2023, 12, 1, 0, 0, 3, 0, time.UTC), RetryAfter: 3 * time.Second}, "Cannot proceed, queue is empty, retry after 3s."},
+		{dynamomq.QueuePausedError{}, "Cannot proceed, queue is paused."},
+		{dynamomq.InFlightLimitExceededError{Limit: 200}, "Cannot proceed, 200 messages are already in flight."},
+		{dynamomq.MessageHeldError{ID: "A-101"}, "Message 'A-101' is held."},
+		{dynamomq.MessageHeldError{ID: "A-101", Reason: "sample reason"}, "Message 'A-101' is held: sample reason."},
+		{dynamomq.MessageNotHeldError{ID: "A-101"}, "Message 'A-101' is not held."},
+		{dynamomq.InvalidNextTokenError{Reason: "sample reason"}, "Invalid next token: sample reason."},
 		{dynamomq.InvalidStateTransitionError{Msg: "sample message", Operation: "sample operation", Current: "sample status"}, "operation sample operation failed for status sample status: sample message."},
+		{dynamomq.InvalidStateTransitionError{Msg: "sample message", Operation: "sample operation", Current: "READY", Requested: "DLQ_PROCESSING"}, "operation sample operation failed for status READY to DLQ_PROCESSING: sample message."},
+		{dynamomq.InvalidTimestampError{Attribute: "sent_at", Value: "sample value", Cause: errors.New("sample cause")}, "Invalid timestamp in 'sent_at' attribute \"sample value\": sample cause."},
+		{dynamomq.InvalidDumpError{Record: 3, Cause: errors.New("sample cause")}, "Invalid message #3 in dump: sample cause."},
+		{dynamomq.ArchiveError{ID: "A-101", Cause: errors.New("sample cause")}, "Failed to archive message 'A-101', it was not deleted: sample cause."},
+		{dynamomq.InvalidArchiverError{Archiver: "sample archiver", MessageType: "sample type"}, "Archiver sample archiver cannot archive messages of type sample type."},
+		{dynamomq.InvalidHooksError{Hooks: "sample hooks", MessageType: "sample type"}, "Hooks sample hooks cannot observe messages of type sample type."},
+		{dynamomq.InvalidSamplerError{Sampler: "sample sampler", MessageType: "sample type"}, "Sampler sample sampler cannot sample messages of type sample type."},
+		{dynamomq.InvalidPayloadUpgraderError{Upgrader: "sample upgrader", MessageType: "sample type"}, "Payload upgrader sample upgrader cannot upgrade messages of type sample type."},
+		{dynamomq.PayloadUpgradeError{ID: "A-101", Version: 1, Cause: errors.New("sample cause")}, "Failed to upgrade the payload of message 'A-101' from version 1: sample cause"},
+		{dynamomq.InvalidValidatorError{Validator: "sample validator", MessageType: "sample type"}, "Validator sample validator cannot validate messages of type sample type."},
+		{dynamomq.ValidationFailedError{ID: "A-101", Operation: "SendMessage", Cause: errors.New("sample cause")}, "SendMessage rejected invalid message 'A-101': sample cause"},
+		{dynamomq.CanaryTimeoutError{ID: "canary-1", Timeout: 30 * time.Second}, "Canary 'canary-1' was not deleted within 30s."},
+		{dynamomq.InvalidTemplateFormatError{Format: "sample format"}, "Invalid template format 'sample format', want 'cloudformation' or 'terraform'."},
+		{dynamomq.OperationTimeoutError{Operation: "Query", Timeout: time.Second, Cause: context.DeadlineExceeded}, "DynamoDB Query did not complete within 1s: context deadline exceeded."},
+		{dynamomq.DefaultOperationTimeoutError{Operation: "ReceiveMessage", Step: "UpdateItem", Timeout: time.Second, Cause: context.DeadlineExceeded}, "ReceiveMessage did not complete within 1s, timed out in DynamoDB UpdateItem: context deadline exceeded."},
 	}
 	for _, tc := range tests {
 		if tc.err.Error() != tc.expected {
@@ -30,3 +64,78 @@ func TestErrors(t *testing.T) {
 		}
 	}
 }
+
+func TestRetryableErrors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		err  dynamomq.RetryableError
+		want bool
+	}{
+		{dynamomq.DynamoDBAPIError{}, true},
+		{dynamomq.ThrottledError{}, true},
+		{dynamomq.ResourceNotFoundError{}, false},
+		{dynamomq.ValidationError{}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Retryable(); got != tt.want {
+			t.Errorf("%T.Retryable() = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestSentinelErrors(t *testing.T) {
+	t.Parallel()
+	sentinels := []error{
+		dynamomq.ErrEmptyQueue,
+		dynamomq.ErrIDNotProvided,
+		dynamomq.ErrIDNotFound,
+		dynamomq.ErrIDDuplicated,
+		dynamomq.ErrVersionConflict,
+	}
+	tests := []struct {
+		err  error
+		want error
+	}{
+		{&dynamomq.EmptyQueueError{}, dynamomq.ErrEmptyQueue},
+		{&dynamomq.EmptyQueueError{NextVisibleAt: time.Date(2023, 12, 1, 0, 0, 3, 0, time.UTC), RetryAfter: 3 * time.Second}, dynamomq.ErrEmptyQueue},
+		{&dynamomq.IDNotProvidedError{}, dynamomq.ErrIDNotProvided},
+		{dynamomq.IDNotFoundError{}, dynamomq.ErrIDNotFound},
+		{&dynamomq.IDNotFoundError{}, dynamomq.ErrIDNotFound},
+		{&dynamomq.IDDuplicatedError{}, dynamomq.ErrIDDuplicated},
+		{&dynamomq.ConditionalCheckFailedError{Cause: errors.New("sample cause")}, nil},
+		{dynamomq.ConditionalCheckFailedError{}, nil},
+		{dynamomq.VersionConflictError{ID: "A-101", Version: 2}, dynamomq.ErrVersionConflict},
+		{&dynamomq.VersionConflictError{ID: "A-101", Version: 2}, dynamomq.ErrVersionConflict},
+		{fmt.Errorf("wrapped: %w", &dynamomq.IDNotFoundError{}), dynamomq.ErrIDNotFound},
+		{dynamomq.InvalidDumpError{Record: 3, Cause: &dynamomq.IDNotProvidedError{}}, dynamomq.ErrIDNotProvided},
+		{&dynamomq.QueuePausedError{}, nil},
+	}
+	for _, tt := range tests {
+		for _, sentinel := range sentinels {
+			if got := errors.Is(tt.err, sentinel); got != (sentinel == tt.want) {
+				t.Errorf("errors.Is(%#v, %v) = %v, want %v", tt.err, sentinel, got, !got)
+			}
+		}
+	}
+}
+
+func TestConditionalCheckFailedErrorIs(t *testing.T) {
+	t.Parallel()
+	cause := errors.New("sample cause")
+	err := fmt.Errorf("wrapped: %w", &dynamomq.ConditionalCheckFailedError{Cause: cause})
+	tests := []struct {
+		target error
+		want   bool
+	}{
+		{&dynamomq.ConditionalCheckFailedError{}, true},
+		{dynamomq.ConditionalCheckFailedError{Cause: cause}, true},
+		{&dynamomq.ConditionalCheckFailedError{Cause: errors.New("another cause")}, false},
+		{dynamomq.VersionConflictError{}, false},
+		{dynamomq.ErrVersionConflict, false},
+	}
+	for _, tt := range tests {
+		if got := errors.Is(err, tt.target); got != tt.want {
+			t.Errorf("errors.Is(%v, %#v) = %v, want %v", err, tt.target, got, tt.want)
+		}
+	}
+}
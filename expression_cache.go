@@ -0,0 +1,72 @@
+package dynamomq
+
+import (
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+)
+
+// staticExpressions holds the expressions that are identical for every call, built once when the client is created.
+type staticExpressions struct {
+	// idExists is the condition that the message exists.
+	idExists expression.Expression
+	// keyNotExists is the condition that no item exists with the key of a new message.
+	keyNotExists expression.Expression
+	// listMessages filters out the items that are not messages.
+	listMessages expression.Expression
+	// listMessagesOmitData filters out the items that are not messages and projects every attribute but the payload.
+	listMessagesOmitData expression.Expression
+	// omitData projects every attribute of a message but the payload.
+	omitData expression.Expression
+}
+
+func (c *ClientImpl[T]) buildStaticExpressions() (err error) {
+	build := func(b expression.Builder) expression.Expression {
+		if err != nil {
+			return expression.Expression{}
+		}
+		var expr expression.Expression
+		expr, err = b.Build()
+		return expr
+	}
+	// Lock items stored in the table have no queue type and are not listed.
+	isMessage := expression.AttributeExists(expression.Name(c.schema.QueueTypeAttribute))
+	c.static = staticExpressions{
+		idExists: build(expression.NewBuilder().
+			WithCondition(expression.AttributeExists(expression.Name(c.schema.IDAttribute)))),
+		keyNotExists: build(expression.NewBuilder().
+			WithCondition(expression.AttributeNotExists(expression.Name(c.schema.PartitionKeyAttribute)))),
+		listMessages: build(expression.NewBuilder().
+			WithFilter(isMessage)),
+		listMessagesOmitData: build(expression.NewBuilder().
+			WithFilter(isMessage).
+			WithProjection(c.systemAttributeProjection())),
+		omitData: build(expression.NewBuilder().
+			WithProjection(c.systemAttributeProjection())),
+	}
+	if err != nil {
+		return BuildingExpressionError{Cause: err}
+	}
+	return nil
+}
+
+// queueTypeKeyCondition returns the key condition selecting the messages of a queue type in the queueing index.
+// It is built on the first use for each queue type and reused afterwards; the expression is only read by the calls sharing it.
+func (c *ClientImpl[T]) queueTypeKeyCondition(queueType QueueType) (expression.Expression, error) {
+	c.keyConditionsMu.RLock()
+	expr, ok := c.keyConditions[queueType]
+	c.keyConditionsMu.RUnlock()
+	if ok {
+		return expr, nil
+	}
+	expr, err := c.buildExpression(expression.NewBuilder().
+		WithKeyCondition(expression.Key(c.schema.QueueTypeAttribute).Equal(expression.Value(queueType))))
+	if err != nil {
+		return expression.Expression{}, err
+	}
+	c.keyConditionsMu.Lock()
+	if c.keyConditions == nil {
+		c.keyConditions = make(map[QueueType]expression.Expression)
+	}
+	c.keyConditions[queueType] = expr
+	c.keyConditionsMu.Unlock()
+	return expr, nil
+}
@@ -0,0 +1,82 @@
+package dynamomq
+
+import "sync"
+
+// maxRecentTenants is the number of tenants a client configured with WithTenantFairness remembers having served.
+const maxRecentTenants = 64
+
+// tenantLRU remembers the tenants a client served last, least recently served first.
+type tenantLRU struct {
+	mu    sync.Mutex
+	order []string
+	size  int
+}
+
+func newTenantLRU(size int) *tenantLRU {
+	return &tenantLRU{size: size}
+}
+
+// ranks returns the position of each remembered tenant, from zero for the least recently served one.
+func (l *tenantLRU) ranks() map[string]int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ranks := make(map[string]int, len(l.order))
+	for i, tenant := range l.order {
+		ranks[tenant] = i
+	}
+	return ranks
+}
+
+// served records that a message of the tenant was received, making it the most recently served tenant.
+func (l *tenantLRU) served(tenant string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, t := range l.order {
+		if t == tenant {
+			l.order = append(l.order[:i], l.order[i+1:]...)
+			break
+		}
+	}
+	l.order = append(l.order, tenant)
+	if len(l.order) > l.size {
+		l.order = l.order[len(l.order)-l.size:]
+	}
+}
+
+// fairSelection picks, among the messages that can be received at the head of the queue, the oldest one
+// of the tenant served least recently.
+type fairSelection[T any] struct {
+	ranks      map[string]int
+	window     int
+	considered int
+	best       *Message[T]
+	bestRank   int
+}
+
+// newFairSelection returns the selection of the next message to receive, or nil when the client receives
+// the messages in order.
+func (c *ClientImpl[T]) newFairSelection() *fairSelection[T] {
+	if c.tenantFairnessWindow <= 0 || c.useFIFO {
+		return nil
+	}
+	return &fairSelection[T]{
+		ranks:  c.tenants.ranks(),
+		window: c.tenantFairnessWindow,
+	}
+}
+
+// consider offers a message that can be received, in the order of the queue, and reports whether the selection is done:
+// when the message is of a tenant not served recently, which no later message can better, or when the window is full.
+func (s *fairSelection[T]) consider(message *Message[T]) bool {
+	rank, ok := s.ranks[message.TenantID]
+	if !ok {
+		rank = -1
+	}
+	if s.best == nil || rank < s.bestRank {
+		m := *message
+		s.best = &m
+		s.bestRank = rank
+	}
+	s.considered++
+	return rank < 0 || s.considered >= s.window
+}
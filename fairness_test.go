@@ -0,0 +1,129 @@
+package dynamomq_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestDynamoMQClientReceiveMessageWithTenantFairness(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Hour)
+	tests := []struct {
+		name   string
+		window int
+		fifo   bool
+		// wantQuiet is the number of messages of the quiet tenant among the first 20 received.
+		wantQuiet int
+		// wantMaxGap is the largest number of messages received in a row without one of the quiet tenant,
+		// once the first one is received.
+		wantMaxGap int
+	}{
+		{
+			name:       "should receive in order without fairness",
+			wantQuiet:  1,
+			wantMaxGap: 9,
+		},
+		{
+			name:       "should alternate the tenants within the window",
+			window:     110,
+			wantQuiet:  10,
+			wantMaxGap: 1,
+		},
+		{
+			// The messages of the quiet tenant are received ahead of their turn while they are within the window.
+			name:       "should serve the quiet tenant ahead of its turn with a small window",
+			window:     20,
+			wantQuiet:  3,
+			wantMaxGap: 9,
+		},
+		{
+			name:       "should receive in order in a FIFO queue",
+			window:     20,
+			fifo:       true,
+			wantQuiet:  1,
+			wantMaxGap: 9,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// The noisy tenant sends ten messages for each message of the quiet tenant.
+			queue := &pagedQueue{}
+			for i := 0; i < 110; i++ {
+				id, tenant := fmt.Sprintf("N-%03d", i), "noisy"
+				if i%11 == 10 {
+					id, tenant = fmt.Sprintf("Q-%03d", i), "quiet"
+				}
+				m := NewTestMessageItemAsReady(id, test.DefaultTestDate.Add(time.Duration(i)*time.Second))
+				m.TenantID = tenant
+				queue.items = append(queue.items, dynamomqtest.MarshalMap(m))
+			}
+			client := newPagedQueueClient(t, queue, now, dynamomq.WithTenantFairness(tt.window), dynamomq.WithUseFIFO(tt.fifo))
+			quiet, gap, maxGap := 0, 0, 0
+			for i := 0; i < 20; i++ {
+				out, err := client.ReceiveMessage(context.Background(), &dynamomq.ReceiveMessageInput{})
+				if err != nil {
+					t.Fatalf("ReceiveMessage() error = %v", err)
+				}
+				// In a FIFO queue, the next message is received only once the previous one is deleted.
+				if tt.fifo {
+					queue.items = queue.items[1:]
+				}
+				if strings.HasPrefix(out.ReceivedMessage.ID, "Q-") {
+					quiet++
+					gap = 0
+					continue
+				}
+				if gap++; quiet > 0 && gap > maxGap {
+					maxGap = gap
+				}
+			}
+			if quiet != tt.wantQuiet {
+				t.Errorf("messages of the quiet tenant = %d, want %d", quiet, tt.wantQuiet)
+			}
+			if maxGap != tt.wantMaxGap {
+				t.Errorf("largest run without the quiet tenant = %d, want %d", maxGap, tt.wantMaxGap)
+			}
+		})
+	}
+}
+
+func TestDynamoMQClientSendMessageWithTenantID(t *testing.T) {
+	t.Parallel()
+	var put map[string]types.AttributeValue
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+				return &dynamodb.GetItemOutput{}, nil
+			},
+			PutItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+				put = params.Item
+				return &dynamodb.PutItemOutput{}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	_, err = dynamomq.NewProducer[test.MessageData](client).Produce(context.Background(), &dynamomq.ProduceInput[test.MessageData]{
+		ID:       "A-101",
+		Data:     test.NewMessageData("A-101"),
+		TenantID: "tenant-1",
+	})
+	if err != nil {
+		t.Fatalf("Produce() error = %v", err)
+	}
+	test.AssertDeepEqual(t, put[dynamomq.AttributeNameTenantID], types.AttributeValue(&types.AttributeValueMemberS{Value: "tenant-1"}), "tenant_id")
+}
@@ -0,0 +1,254 @@
+// Package faultinject provides a DynamoDBAPI that injects errors into the calls of another one,
+// to test how consumers and producers behave when DynamoDB throttles, rejects conditions or times out.
+//
+// Set it on a client with dynamomq.WithDynamoDBAPI:
+//
+//	api := faultinject.New(dynamodb.NewFromConfig(cfg), 1,
+//		faultinject.Rule{Operation: faultinject.OperationDeleteItem, Sequence: []faultinject.Fault{faultinject.FaultTimeout}},
+//		faultinject.Rule{Probability: 0.1, Faults: []faultinject.Fault{faultinject.FaultThrottle}},
+//	)
+//	client, err := dynamomq.NewFromConfig[ExampleData](cfg, dynamomq.WithDynamoDBAPI(api))
+package faultinject
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/vvatanabe/dynamomq"
+)
+
+// Fault is an error returned in place of calling DynamoDB.
+type Fault int
+
+const (
+	// FaultNone lets the call through. It leaves calls untouched in a Sequence.
+	FaultNone Fault = iota
+	// FaultThrottle fails the call with a ProvisionedThroughputExceededException.
+	FaultThrottle
+	// FaultConditionalCheckFailed fails the call with a ConditionalCheckFailedException.
+	FaultConditionalCheckFailed
+	// FaultTimeout fails the call with a network timeout, as if the request could not be sent.
+	FaultTimeout
+)
+
+// String returns the name of the fault.
+func (f Fault) String() string {
+	switch f {
+	case FaultNone:
+		return "none"
+	case FaultThrottle:
+		return "throttle"
+	case FaultConditionalCheckFailed:
+		return "conditional check failed"
+	case FaultTimeout:
+		return "timeout"
+	}
+	return fmt.Sprintf("Fault(%d)", int(f))
+}
+
+// err returns the error of the fault, wrapped like the AWS SDK wraps the errors of an operation.
+func (f Fault) err(op Operation) error {
+	var cause error
+	switch f {
+	case FaultThrottle:
+		cause = &types.ProvisionedThroughputExceededException{Message: aws.String("injected by faultinject")}
+	case FaultConditionalCheckFailed:
+		cause = &types.ConditionalCheckFailedException{Message: aws.String("injected by faultinject")}
+	case FaultTimeout:
+		cause = &smithyhttp.RequestSendError{Err: timeoutError{}}
+	default:
+		return nil
+	}
+	return &smithy.OperationError{
+		ServiceID:     "DynamoDB",
+		OperationName: string(op),
+		Err:           cause,
+	}
+}
+
+// timeoutError is a network error reporting a timeout, as returned by a dialer or a connection.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout (injected by faultinject)" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// Operation is the name of an operation of DynamoDBAPI.
+type Operation string
+
+const (
+	OperationGetItem            Operation = "GetItem"
+	OperationPutItem            Operation = "PutItem"
+	OperationUpdateItem         Operation = "UpdateItem"
+	OperationDeleteItem         Operation = "DeleteItem"
+	OperationQuery              Operation = "Query"
+	OperationScan               Operation = "Scan"
+	OperationDescribeTable      Operation = "DescribeTable"
+	OperationDescribeTimeToLive Operation = "DescribeTimeToLive"
+	OperationTransactWriteItems Operation = "TransactWriteItems"
+	OperationBatchWriteItem     Operation = "BatchWriteItem"
+	OperationBatchGetItem       Operation = "BatchGetItem"
+)
+
+// Rule describes the faults injected into the calls of an operation.
+type Rule struct {
+	// Operation is the operation the rule applies to. An empty Operation applies the rule to every operation.
+	Operation Operation
+	// Sequence is injected into the first calls the rule applies to, one fault per call.
+	Sequence []Fault
+	// Probability is the probability that a call after the Sequence fails with one of Faults.
+	Probability float64
+	// Faults are the faults injected with Probability, chosen at random.
+	Faults []Fault
+}
+
+// DynamoDB is a DynamoDBAPI injecting faults into the calls of another DynamoDBAPI.
+// A failed call is not forwarded. The rules are evaluated in order, and the first one injecting a fault wins.
+// The random faults are drawn from a source seeded by New, so a sequential caller sees the same faults
+// on every run; concurrent callers share the source in the order of their calls.
+type DynamoDB struct {
+	api   dynamomq.DynamoDBAPI
+	rules []Rule
+
+	mu       sync.Mutex
+	rand     *rand.Rand
+	calls    []int
+	injected map[Operation]map[Fault]int
+}
+
+var _ dynamomq.DynamoDBAPI = (*DynamoDB)(nil)
+
+// New returns a DynamoDBAPI forwarding the calls to api, except those failed by the rules.
+func New(api dynamomq.DynamoDBAPI, seed int64, rules ...Rule) *DynamoDB {
+	return &DynamoDB{
+		api:      api,
+		rules:    rules,
+		rand:     rand.New(rand.NewSource(seed)),
+		calls:    make([]int, len(rules)),
+		injected: make(map[Operation]map[Fault]int),
+	}
+}
+
+// Injected returns the number of times the fault has been injected into the operation.
+func (d *DynamoDB) Injected(op Operation, fault Fault) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.injected[op][fault]
+}
+
+func (d *DynamoDB) inject(op Operation) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i, rule := range d.rules {
+		if rule.Operation != "" && rule.Operation != op {
+			continue
+		}
+		fault := d.draw(i, rule)
+		if fault == FaultNone {
+			continue
+		}
+		if d.injected[op] == nil {
+			d.injected[op] = make(map[Fault]int)
+		}
+		d.injected[op][fault]++
+		return fault.err(op)
+	}
+	return nil
+}
+
+// draw returns the fault the rule injects into its next call.
+func (d *DynamoDB) draw(i int, rule Rule) Fault {
+	call := d.calls[i]
+	d.calls[i]++
+	if call < len(rule.Sequence) {
+		return rule.Sequence[call]
+	}
+	if len(rule.Faults) == 0 || d.rand.Float64() >= rule.Probability {
+		return FaultNone
+	}
+	return rule.Faults[d.rand.Intn(len(rule.Faults))]
+}
+
+func (d *DynamoDB) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	if err := d.inject(OperationGetItem); err != nil {
+		return nil, err
+	}
+	return d.api.GetItem(ctx, params, optFns...)
+}
+
+func (d *DynamoDB) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	if err := d.inject(OperationPutItem); err != nil {
+		return nil, err
+	}
+	return d.api.PutItem(ctx, params, optFns...)
+}
+
+func (d *DynamoDB) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	if err := d.inject(OperationUpdateItem); err != nil {
+		return nil, err
+	}
+	return d.api.UpdateItem(ctx, params, optFns...)
+}
+
+func (d *DynamoDB) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	if err := d.inject(OperationDeleteItem); err != nil {
+		return nil, err
+	}
+	return d.api.DeleteItem(ctx, params, optFns...)
+}
+
+func (d *DynamoDB) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	if err := d.inject(OperationQuery); err != nil {
+		return nil, err
+	}
+	return d.api.Query(ctx, params, optFns...)
+}
+
+func (d *DynamoDB) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	if err := d.inject(OperationScan); err != nil {
+		return nil, err
+	}
+	return d.api.Scan(ctx, params, optFns...)
+}
+
+func (d *DynamoDB) DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	if err := d.inject(OperationDescribeTable); err != nil {
+		return nil, err
+	}
+	return d.api.DescribeTable(ctx, params, optFns...)
+}
+
+func (d *DynamoDB) DescribeTimeToLive(ctx context.Context, params *dynamodb.DescribeTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error) {
+	if err := d.inject(OperationDescribeTimeToLive); err != nil {
+		return nil, err
+	}
+	return d.api.DescribeTimeToLive(ctx, params, optFns...)
+}
+
+func (d *DynamoDB) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	if err := d.inject(OperationTransactWriteItems); err != nil {
+		return nil, err
+	}
+	return d.api.TransactWriteItems(ctx, params, optFns...)
+}
+
+func (d *DynamoDB) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	if err := d.inject(OperationBatchWriteItem); err != nil {
+		return nil, err
+	}
+	return d.api.BatchWriteItem(ctx, params, optFns...)
+}
+
+func (d *DynamoDB) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	if err := d.inject(OperationBatchGetItem); err != nil {
+		return nil, err
+	}
+	return d.api.BatchGetItem(ctx, params, optFns...)
+}
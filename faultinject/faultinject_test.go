@@ -0,0 +1,130 @@
+package faultinject_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/faultinject"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func newAPI() *mock.DynamoDB {
+	return &mock.DynamoDB{
+		GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+			return &dynamodb.GetItemOutput{}, nil
+		},
+		DeleteItemFunc: func(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+			return &dynamodb.DeleteItemOutput{}, nil
+		},
+	}
+}
+
+func TestDynamoDBSequence(t *testing.T) {
+	t.Parallel()
+	api := faultinject.New(newAPI(), 1, faultinject.Rule{
+		Operation: faultinject.OperationDeleteItem,
+		Sequence:  []faultinject.Fault{faultinject.FaultThrottle, faultinject.FaultNone, faultinject.FaultTimeout},
+	})
+	ctx := context.Background()
+	var got []bool
+	for i := 0; i < 4; i++ {
+		_, err := api.DeleteItem(ctx, &dynamodb.DeleteItemInput{})
+		got = append(got, err != nil)
+		if _, err = api.GetItem(ctx, &dynamodb.GetItemInput{}); err != nil {
+			t.Errorf("GetItem() error = %v, want nil", err)
+		}
+	}
+	test.AssertDeepEqual(t, got, []bool{true, false, true, false}, "DeleteItem() failures")
+	if n := api.Injected(faultinject.OperationDeleteItem, faultinject.FaultThrottle); n != 1 {
+		t.Errorf("Injected(DeleteItem, throttle) = %d, want 1", n)
+	}
+	if n := api.Injected(faultinject.OperationDeleteItem, faultinject.FaultTimeout); n != 1 {
+		t.Errorf("Injected(DeleteItem, timeout) = %d, want 1", n)
+	}
+}
+
+func TestDynamoDBProbabilityIsSeeded(t *testing.T) {
+	t.Parallel()
+	run := func(seed int64) []bool {
+		api := faultinject.New(newAPI(), seed, faultinject.Rule{
+			Probability: 0.5,
+			Faults:      []faultinject.Fault{faultinject.FaultThrottle, faultinject.FaultConditionalCheckFailed},
+		})
+		failures := make([]bool, 0, 100)
+		for i := 0; i < 100; i++ {
+			_, err := api.GetItem(context.Background(), &dynamodb.GetItemInput{})
+			failures = append(failures, err != nil)
+		}
+		return failures
+	}
+	first := run(42)
+	test.AssertDeepEqual(t, run(42), first, "failures with the same seed")
+	n := 0
+	for _, failed := range first {
+		if failed {
+			n++
+		}
+	}
+	if n < 30 || n > 70 {
+		t.Errorf("failures = %d of 100, want about 50", n)
+	}
+}
+
+func TestDynamoDBFaultErrors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		fault faultinject.Fault
+		want  func(err error) bool
+	}{
+		{
+			fault: faultinject.FaultThrottle,
+			want: func(err error) bool {
+				return errors.As(err, &dynamomq.ThrottledError{})
+			},
+		},
+		{
+			fault: faultinject.FaultConditionalCheckFailed,
+			want: func(err error) bool {
+				var target *dynamomq.ConditionalCheckFailedError
+				return errors.As(err, &target)
+			},
+		},
+		{
+			fault: faultinject.FaultTimeout,
+			want: func(err error) bool {
+				return errors.As(err, &dynamomq.DynamoDBAPIError{})
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.fault.String(), func(t *testing.T) {
+			t.Parallel()
+			api := faultinject.New(newAPI(), 1, faultinject.Rule{Sequence: []faultinject.Fault{tt.fault}})
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{}, dynamomq.WithDynamoDBAPI(api))
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			_, err = client.GetMessage(context.Background(), &dynamomq.GetMessageInput{ID: "A-101"})
+			if !tt.want(err) {
+				t.Errorf("GetMessage() error = %v (%T)", err, err)
+			}
+		})
+	}
+}
+
+func TestDynamoDBTimeoutIsNetworkTimeout(t *testing.T) {
+	t.Parallel()
+	api := faultinject.New(newAPI(), 1, faultinject.Rule{Sequence: []faultinject.Fault{faultinject.FaultTimeout}})
+	_, err := api.GetItem(context.Background(), &dynamodb.GetItemInput{})
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("GetItem() error = %v, want a network timeout", err)
+	}
+}
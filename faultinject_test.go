@@ -0,0 +1,132 @@
+package dynamomq_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/faultinject"
+	"github.com/vvatanabe/dynamomq/internal/constant"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+// deleteCounter counts the deletions of each message that reached DynamoDB and succeeded.
+type deleteCounter struct {
+	dynamomq.DynamoDBAPI
+	mu      sync.Mutex
+	deleted map[string]int
+}
+
+func (d *deleteCounter) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	out, err := d.DynamoDBAPI.DeleteItem(ctx, params, optFns...)
+	if err == nil && len(out.Attributes) > 0 {
+		d.mu.Lock()
+		d.deleted[params.Key[dynamomq.AttributeNameID].(*types.AttributeValueMemberS).Value]++
+		d.mu.Unlock()
+	}
+	return out, err
+}
+
+func (d *deleteCounter) count() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.deleted)
+}
+
+func TestConsumerWithInjectedFaults(t *testing.T) {
+	t.Parallel()
+	const messages = 20
+	tableName, raw, clean := SetupDynamoDB(t)
+	defer clean()
+	ctx := context.Background()
+	newClient := func(api dynamomq.DynamoDBAPI) dynamomq.Client[test.MessageData] {
+		client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+			dynamomq.WithTableName(tableName),
+			dynamomq.WithQueueingIndexName(constant.DefaultQueueingIndexName),
+			dynamomq.WithDynamoDBAPI(api))
+		if err != nil {
+			t.Fatalf("NewFromConfig() error = %v", err)
+		}
+		return client
+	}
+
+	producer := newClient(raw)
+	for i := 0; i < messages; i++ {
+		id := fmt.Sprintf("A-%03d", i)
+		if _, err := producer.SendMessage(ctx, &dynamomq.SendMessageInput[test.MessageData]{
+			ID:   id,
+			Data: test.NewMessageData(id),
+		}); err != nil {
+			t.Fatalf("SendMessage() error = %v", err)
+		}
+	}
+
+	counter := &deleteCounter{DynamoDBAPI: raw, deleted: make(map[string]int)}
+	faults := faultinject.New(counter, 1, faultinject.Rule{
+		Probability: 0.2,
+		Faults:      []faultinject.Fault{faultinject.FaultThrottle, faultinject.FaultConditionalCheckFailed, faultinject.FaultTimeout},
+	})
+	var (
+		mu        sync.Mutex
+		processed = make(map[string]int)
+	)
+	consumer := dynamomq.NewConsumer[test.MessageData](newClient(faults),
+		dynamomq.MessageProcessorFunc[test.MessageData](func(msg *dynamomq.Message[test.MessageData]) error {
+			mu.Lock()
+			processed[msg.ID]++
+			mu.Unlock()
+			return nil
+		}),
+		dynamomq.WithPollingInterval(10*time.Millisecond),
+		dynamomq.WithConcurrency(4),
+		dynamomq.WithVisibilityTimeout(1),
+		dynamomq.WithErrorLog(log.New(io.Discard, "", 0)))
+	go func() {
+		_ = consumer.StartConsuming()
+	}()
+	deadline := time.Now().Add(30 * time.Second)
+	for counter.count() < messages && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if err := consumer.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	counter.mu.Lock()
+	defer counter.mu.Unlock()
+	for i := 0; i < messages; i++ {
+		id := fmt.Sprintf("A-%03d", i)
+		if processed[id] == 0 {
+			t.Errorf("message %s was lost", id)
+		}
+		if n := counter.deleted[id]; n != 1 {
+			t.Errorf("message %s was deleted %d times, want 1", id, n)
+		}
+	}
+	stats, err := producer.GetQueueStats(ctx, &dynamomq.GetQueueStatsInput{})
+	if err != nil {
+		t.Fatalf("GetQueueStats() error = %v", err)
+	}
+	if stats.TotalMessagesInQueue != 0 {
+		t.Errorf("messages left in the queue = %d, want 0", stats.TotalMessagesInQueue)
+	}
+	injected := 0
+	for _, op := range []faultinject.Operation{faultinject.OperationQuery, faultinject.OperationUpdateItem, faultinject.OperationDeleteItem, faultinject.OperationGetItem} {
+		for _, fault := range []faultinject.Fault{faultinject.FaultThrottle, faultinject.FaultConditionalCheckFailed, faultinject.FaultTimeout} {
+			injected += faults.Injected(op, fault)
+		}
+	}
+	if injected == 0 {
+		t.Error("no fault was injected")
+	}
+}
@@ -0,0 +1,132 @@
+package dynamomq
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+)
+
+// HoldMessageInput represents the input parameters for holding a specific message.
+type HoldMessageInput struct {
+	// ID is the unique identifier of the message to hold.
+	ID string
+	// Reason describes why the message is held, for example the ticket of the investigation. It is optional.
+	Reason string
+}
+
+// HoldMessageOutput represents the result of the operation to hold a message.
+type HoldMessageOutput[T any] struct {
+	// HeldMessage is a pointer to the Message type containing information about the held message.
+	HeldMessage *Message[T]
+}
+
+// HoldMessage freezes a specific message in place, typically a poison message an engineer needs to investigate
+// without deleting it. A held message is skipped by ReceiveMessage, refused by RedriveMessage and left as it is by
+// the sweeper until it is released with ReleaseMessage. Holding a message being processed does not interrupt the
+// consumer processing it, but the message is not received again once its visibility timeout expires.
+// In FIFO mode, a held message at the head of the queue blocks the messages behind it.
+// A MessageHeldError is returned if the message is already held. The update is conditional on the version
+// of the message, which is incremented.
+func (c *ClientImpl[T]) HoldMessage(ctx context.Context, params *HoldMessageInput) (*HoldMessageOutput[T], error) {
+	ctx, cancel := c.withDefaultTimeout(ctx, "HoldMessage")
+	defer cancel()
+	if params == nil {
+		params = &HoldMessageInput{}
+	}
+	retrieved, err := c.GetMessage(ctx, &GetMessageInput{
+		ID: params.ID,
+	})
+	if err != nil {
+		return &HoldMessageOutput[T]{}, err
+	}
+	if retrieved.Message == nil {
+		return &HoldMessageOutput[T]{}, &IDNotFoundError{}
+	}
+	message := retrieved.Message
+	if message.Held {
+		return &HoldMessageOutput[T]{}, MessageHeldError{ID: message.ID, Reason: message.HoldReason}
+	}
+	now := c.clock.Now()
+	c.recordTransition(message, TransitionHeld, now)
+	update := expression.
+		Add(expression.Name(c.schema.VersionAttribute), expression.Value(1)).
+		Set(expression.Name(c.schema.UpdatedAtAttribute), expression.Value(clock.FormatRFC3339Nano(now))).
+		Set(expression.Name(c.schema.HeldAttribute), expression.Value(true))
+	if params.Reason == "" {
+		update = update.Remove(expression.Name(c.schema.HoldReasonAttribute))
+	} else {
+		update = update.Set(expression.Name(c.schema.HoldReasonAttribute), expression.Value(params.Reason))
+	}
+	builder := expression.NewBuilder().
+		WithUpdate(c.setHistory(update, message)).
+		WithCondition(expression.Name(c.schema.VersionAttribute).Equal(expression.Value(message.Version)))
+	expr, err := c.buildExpression(builder)
+	if err != nil {
+		return &HoldMessageOutput[T]{}, BuildingExpressionError{Cause: err}
+	}
+	updated, err := c.updateDynamoDBItem(ctx, params.ID, &expr)
+	if err != nil {
+		return &HoldMessageOutput[T]{}, err
+	}
+	return &HoldMessageOutput[T]{
+		HeldMessage: updated,
+	}, nil
+}
+
+// ReleaseMessageInput represents the input parameters for releasing a specific held message.
+type ReleaseMessageInput struct {
+	// ID is the unique identifier of the message to release.
+	ID string
+}
+
+// ReleaseMessageOutput represents the result of the operation to release a held message.
+type ReleaseMessageOutput[T any] struct {
+	// ReleasedMessage is a pointer to the Message type containing information about the released message.
+	ReleasedMessage *Message[T]
+}
+
+// ReleaseMessage releases a message held with HoldMessage, so that it is received and redriven again.
+// The message keeps its status: a message held while ready is received again right away.
+// A MessageNotHeldError is returned if the message is not held. The update is conditional on the version
+// of the message, which is incremented.
+func (c *ClientImpl[T]) ReleaseMessage(ctx context.Context, params *ReleaseMessageInput) (*ReleaseMessageOutput[T], error) {
+	ctx, cancel := c.withDefaultTimeout(ctx, "ReleaseMessage")
+	defer cancel()
+	if params == nil {
+		params = &ReleaseMessageInput{}
+	}
+	retrieved, err := c.GetMessage(ctx, &GetMessageInput{
+		ID: params.ID,
+	})
+	if err != nil {
+		return &ReleaseMessageOutput[T]{}, err
+	}
+	if retrieved.Message == nil {
+		return &ReleaseMessageOutput[T]{}, &IDNotFoundError{}
+	}
+	message := retrieved.Message
+	if !message.Held {
+		return &ReleaseMessageOutput[T]{}, MessageNotHeldError{ID: message.ID}
+	}
+	now := c.clock.Now()
+	c.recordTransition(message, TransitionReleased, now)
+	builder := expression.NewBuilder().
+		WithUpdate(c.setHistory(expression.
+			Add(expression.Name(c.schema.VersionAttribute), expression.Value(1)).
+			Set(expression.Name(c.schema.UpdatedAtAttribute), expression.Value(clock.FormatRFC3339Nano(now))).
+			Remove(expression.Name(c.schema.HeldAttribute)).
+			Remove(expression.Name(c.schema.HoldReasonAttribute)), message)).
+		WithCondition(expression.Name(c.schema.VersionAttribute).Equal(expression.Value(message.Version)))
+	expr, err := c.buildExpression(builder)
+	if err != nil {
+		return &ReleaseMessageOutput[T]{}, BuildingExpressionError{Cause: err}
+	}
+	updated, err := c.updateDynamoDBItem(ctx, params.ID, &expr)
+	if err != nil {
+		return &ReleaseMessageOutput[T]{}, err
+	}
+	return &ReleaseMessageOutput[T]{
+		ReleasedMessage: updated,
+	}, nil
+}
@@ -0,0 +1,275 @@
+package dynamomq_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func newHeldTestMessage(id string) *dynamomq.Message[test.MessageData] {
+	message := NewTestMessageItemAsReady(id, test.DefaultTestDate)
+	message.Held = true
+	message.HoldReason = "INC-42"
+	return message
+}
+
+// newHoldDynamoDB returns a table reading the message with GetItem, or no message if it is nil.
+// UpdateItem fails with updateErr, or returns the updated message and records the update into update.
+func newHoldDynamoDB(message, updated *dynamomq.Message[test.MessageData], updateErr error,
+	update **dynamodb.UpdateItemInput) *mock.DynamoDB {
+	return &mock.DynamoDB{
+		GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+			if message == nil {
+				return &dynamodb.GetItemOutput{}, nil
+			}
+			return &dynamodb.GetItemOutput{Item: dynamomqtest.MarshalMap(message)}, nil
+		},
+		UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+			*update = params
+			if updateErr != nil {
+				return nil, updateErr
+			}
+			return &dynamodb.UpdateItemOutput{Attributes: dynamomqtest.MarshalMap(updated)}, nil
+		},
+	}
+}
+
+func TestDynamoMQClientHoldMessage(t *testing.T) {
+	t.Parallel()
+	held := newHeldTestMessage("A-101")
+	held.Version = 2
+	tests := []struct {
+		name       string
+		id         string
+		message    *dynamomq.Message[test.MessageData]
+		updateErr  error
+		want       *dynamomq.Message[test.MessageData]
+		wantErr    error
+		wantUpdate bool
+	}{
+		{
+			name:       "should hold a ready message",
+			id:         "A-101",
+			message:    NewTestMessageItemAsReady("A-101", test.DefaultTestDate),
+			want:       held,
+			wantUpdate: true,
+		},
+		{
+			name:    "should not hold a message without an ID",
+			wantErr: dynamomq.ErrIDNotProvided,
+		},
+		{
+			name:    "should not hold a deleted message",
+			id:      "A-101",
+			wantErr: dynamomq.ErrIDNotFound,
+		},
+		{
+			name:    "should not hold a message already held",
+			id:      "A-101",
+			message: newHeldTestMessage("A-101"),
+			wantErr: dynamomq.MessageHeldError{ID: "A-101", Reason: "INC-42"},
+		},
+		{
+			name:       "should return a conflict when the message is updated concurrently",
+			id:         "A-101",
+			message:    NewTestMessageItemAsReady("A-101", test.DefaultTestDate),
+			updateErr:  &types.ConditionalCheckFailedException{},
+			wantErr:    &dynamomq.ConditionalCheckFailedError{},
+			wantUpdate: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var update *dynamodb.UpdateItemInput
+			client := newTestClient[test.MessageData](t, newHoldDynamoDB(tt.message, tt.want, tt.updateErr, &update),
+				mock.WithClock(mock.Clock{T: test.DefaultTestDate}))
+			got, err := client.HoldMessage(context.Background(), &dynamomq.HoldMessageInput{
+				ID:     tt.id,
+				Reason: "INC-42",
+			})
+			test.AssertError(t, err, tt.wantErr, "HoldMessage()")
+			if (update != nil) != tt.wantUpdate {
+				t.Fatalf("HoldMessage() updated = %v, want %v", update != nil, tt.wantUpdate)
+			}
+			if update != nil && update.ConditionExpression == nil {
+				t.Error("HoldMessage() update is not conditioned on the version")
+			}
+			if tt.wantErr == nil {
+				test.AssertDeepEqual(t, got.HeldMessage, tt.want, "HoldMessage()")
+			}
+		})
+	}
+}
+
+func TestDynamoMQClientReleaseMessage(t *testing.T) {
+	t.Parallel()
+	released := NewTestMessageItemAsReady("A-101", test.DefaultTestDate)
+	released.Version = 2
+	tests := []struct {
+		name       string
+		message    *dynamomq.Message[test.MessageData]
+		want       *dynamomq.Message[test.MessageData]
+		wantErr    error
+		wantUpdate bool
+	}{
+		{
+			name:       "should release a held message",
+			message:    newHeldTestMessage("A-101"),
+			want:       released,
+			wantUpdate: true,
+		},
+		{
+			name:    "should not release a deleted message",
+			wantErr: dynamomq.ErrIDNotFound,
+		},
+		{
+			name:    "should not release a message not held",
+			message: NewTestMessageItemAsReady("A-101", test.DefaultTestDate),
+			wantErr: dynamomq.MessageNotHeldError{ID: "A-101"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var update *dynamodb.UpdateItemInput
+			client := newTestClient[test.MessageData](t, newHoldDynamoDB(tt.message, tt.want, nil, &update),
+				mock.WithClock(mock.Clock{T: test.DefaultTestDate}))
+			got, err := client.ReleaseMessage(context.Background(), &dynamomq.ReleaseMessageInput{ID: "A-101"})
+			test.AssertError(t, err, tt.wantErr, "ReleaseMessage()")
+			if (update != nil) != tt.wantUpdate {
+				t.Fatalf("ReleaseMessage() updated = %v, want %v", update != nil, tt.wantUpdate)
+			}
+			if tt.wantErr == nil {
+				test.AssertDeepEqual(t, got.ReleasedMessage, tt.want, "ReleaseMessage()")
+			}
+		})
+	}
+}
+
+func TestDynamoMQClientHoldMessageOnTable(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	client, clean := prepareTestClient(ctx, t, NewSetupFunc(
+		newPutRequestWithReadyItem("A-101", test.DefaultTestDate),
+		newPutRequestWithReadyItem("A-102", test.DefaultTestDate.Add(time.Second)),
+	), mock.Clock{T: test.DefaultTestDate.Add(time.Minute)}, false, nil, nil, nil)
+	defer clean()
+	if _, err := client.HoldMessage(ctx, &dynamomq.HoldMessageInput{ID: "A-101", Reason: "INC-42"}); err != nil {
+		t.Fatalf("HoldMessage() error = %v", err)
+	}
+	got, err := client.GetMessage(ctx, &dynamomq.GetMessageInput{ID: "A-101"})
+	if err != nil {
+		t.Fatalf("GetMessage() error = %v", err)
+	}
+	if !got.Message.Held || got.Message.HoldReason != "INC-42" {
+		t.Errorf("GetMessage() held = %v, reason = %q, want held for INC-42", got.Message.Held, got.Message.HoldReason)
+	}
+	received, err := client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{})
+	if err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	if received.ReceivedMessage.ID != "A-102" {
+		t.Errorf("ReceiveMessage() ID = %s, want A-102", received.ReceivedMessage.ID)
+	}
+	if _, err = client.ReleaseMessage(ctx, &dynamomq.ReleaseMessageInput{ID: "A-101"}); err != nil {
+		t.Fatalf("ReleaseMessage() error = %v", err)
+	}
+	got, err = client.GetMessage(ctx, &dynamomq.GetMessageInput{ID: "A-101"})
+	if err != nil {
+		t.Fatalf("GetMessage() error = %v", err)
+	}
+	if got.Message.Held || got.Message.HoldReason != "" {
+		t.Errorf("GetMessage() held = %v, reason = %q, want released", got.Message.Held, got.Message.HoldReason)
+	}
+	received, err = client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{})
+	if err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	if received.ReceivedMessage.ID != "A-101" {
+		t.Errorf("ReceiveMessage() ID = %s, want A-101", received.ReceivedMessage.ID)
+	}
+}
+
+func TestDynamoMQClientRedriveHeldMessage(t *testing.T) {
+	t.Parallel()
+	message := NewTestMessageItemAsDLQ("B-101", test.DefaultTestDate)
+	message.Held = true
+	var update *dynamodb.UpdateItemInput
+	client := newTestClient[test.MessageData](t, newHoldDynamoDB(message, nil, nil, &update),
+		mock.WithClock(mock.Clock{T: test.DefaultTestDate}))
+	_, err := client.RedriveMessage(context.Background(), &dynamomq.RedriveMessageInput{ID: "B-101"})
+	test.AssertError(t, err, dynamomq.MessageHeldError{ID: "B-101"}, "RedriveMessage()")
+	if update != nil {
+		t.Error("RedriveMessage() updated a held message")
+	}
+}
+
+func TestDynamoMQClientReceiveMessageSkipsHeldMessages(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	tests := []struct {
+		name    string
+		fifo    bool
+		want    string
+		wantErr error
+	}{
+		{
+			name: "should receive the message behind a held message",
+			want: "A-102",
+		},
+		{
+			name:    "should be blocked by a held message at the head of the queue in FIFO mode",
+			fifo:    true,
+			wantErr: dynamomq.ErrEmptyQueue,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			queue := &pagedQueue{items: []map[string]types.AttributeValue{
+				dynamomqtest.MarshalMap(newHeldTestMessage("A-101")),
+				dynamomqtest.MarshalMap(NewTestMessageItemAsReady("A-102", test.DefaultTestDate)),
+			}}
+			client := newPagedQueueClient(t, queue, now, dynamomq.WithUseFIFO(tt.fifo))
+			got, err := client.ReceiveMessage(context.Background(), &dynamomq.ReceiveMessageInput{})
+			test.AssertError(t, err, tt.wantErr, "ReceiveMessage()")
+			if tt.wantErr == nil && got.ReceivedMessage.ID != tt.want {
+				t.Errorf("ReceiveMessage() ID = %s, want %s", got.ReceivedMessage.ID, tt.want)
+			}
+		})
+	}
+}
+
+func TestDynamoMQClientGetQueueStatsCountsHeldMessages(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	queue := &pagedQueue{items: []map[string]types.AttributeValue{
+		dynamomqtest.MarshalMap(newHeldTestMessage("A-101")),
+		dynamomqtest.MarshalMap(NewTestMessageItemAsReady("A-102", test.DefaultTestDate)),
+		dynamomqtest.MarshalMap(NewTestMessageItemAsProcessing("A-103", now)),
+	}}
+	client := newPagedQueueClient(t, queue, now)
+	got, err := client.GetQueueStats(context.Background(), &dynamomq.GetQueueStatsInput{})
+	if err != nil {
+		t.Fatalf("GetQueueStats() error = %v", err)
+	}
+	test.AssertDeepEqual(t, got, &dynamomq.GetQueueStatsOutput{
+		First100IDsInQueue:             []string{"A-101", "A-102", "A-103"},
+		First100IDsInQueueProcessing:   []string{"A-103"},
+		TotalMessagesInQueue:           3,
+		TotalMessagesInQueueProcessing: 1,
+		TotalMessagesInQueueReady:      1,
+		TotalMessagesInQueueHeld:       1,
+	}, "GetQueueStats()")
+}
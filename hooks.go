@@ -0,0 +1,123 @@
+package dynamomq
+
+import (
+	"context"
+	"log"
+)
+
+// Hooks are callbacks invoked after the operations of the client that change the state of a message.
+// Each callback receives the input and the output of a successful call, which hold the message where the operation
+// returns it, and must not modify them. Callbacks are not invoked when the operation fails, and they run synchronously
+// on the goroutine of the call, so a slow callback slows the operation down.
+// A panic in a callback is recovered and logged to the logger set with WithClientErrorLog, and does not affect
+// the result of the operation.
+// Operations that only edit a message in place, UpdateMessageData, ResetReceiveCount, HoldMessage and ReleaseMessage,
+// have no callback and invoke none.
+type Hooks[T any] struct {
+	// OnSent is invoked after SendMessage, for each message sent by SendMessagesInTransaction, for the next
+	// message sent by ChainMessage, and for the message written by ReplaceMessage.
+	OnSent func(ctx context.Context, params *SendMessageInput[T], out *SendMessageOutput[T])
+	// OnReceived is invoked after ReceiveMessage.
+	OnReceived func(ctx context.Context, params *ReceiveMessageInput, out *ReceiveMessageOutput[T])
+	// OnVisibilityChanged is invoked after ChangeMessageVisibility, and for each message reclaimed by
+	// ReclaimExpiredMessages, with a zero VisibilityTimeout.
+	OnVisibilityChanged func(ctx context.Context, params *ChangeMessageVisibilityInput, out *ChangeMessageVisibilityOutput[T])
+	// OnDeleted is invoked after DeleteMessage, including the deletion of a message that did not exist
+	// when StrictExistenceCheck is false, for the message deleted by ChainMessage, the source deleted
+	// by MoveMessage, the message replaced by ReplaceMessage, and each message deleted by DeleteExpiredMessages.
+	OnDeleted func(ctx context.Context, params *DeleteMessageInput, out *DeleteMessageOutput)
+	// OnMovedToDLQ is invoked after MoveMessageToDLQ has moved a message, but not for a message already in the DLQ.
+	OnMovedToDLQ func(ctx context.Context, params *MoveMessageToDLQInput, out *MoveMessageToDLQOutput[T])
+	// OnRedriven is invoked after RedriveMessage.
+	OnRedriven func(ctx context.Context, params *RedriveMessageInput, out *RedriveMessageOutput[T])
+	// OnCanaryChecked is invoked after CheckCanary has found the canary deleted, with the measured latencies.
+	OnCanaryChecked func(ctx context.Context, params *CheckCanaryInput, out *CheckCanaryOutput)
+
+	errorLog *log.Logger
+}
+
+// WithHooks is an option function to set callbacks invoked after each operation that changes the state of a message.
+func WithHooks[T any](hooks *Hooks[T]) func(*ClientOptions) {
+	return func(s *ClientOptions) {
+		s.Hooks = hooks
+	}
+}
+
+func hooksOf[T any](hooks any, errorLog *log.Logger) (*Hooks[T], error) {
+	if hooks == nil {
+		return nil, nil
+	}
+	h, ok := hooks.(*Hooks[T])
+	if !ok {
+		return nil, InvalidHooksError{Hooks: typeName(hooks), MessageType: typeNameOf[T]()}
+	}
+	if h == nil {
+		return nil, nil
+	}
+	// The hooks are copied so that the logger of the client does not leak into the Hooks of the caller.
+	copied := *h
+	copied.errorLog = errorLog
+	return &copied, nil
+}
+
+func (h *Hooks[T]) sent(ctx context.Context, params *SendMessageInput[T], out *SendMessageOutput[T]) {
+	if h == nil || h.OnSent == nil {
+		return
+	}
+	defer h.recoverPanic("OnSent")
+	h.OnSent(ctx, params, out)
+}
+
+func (h *Hooks[T]) received(ctx context.Context, params *ReceiveMessageInput, out *ReceiveMessageOutput[T]) {
+	if h == nil || h.OnReceived == nil {
+		return
+	}
+	defer h.recoverPanic("OnReceived")
+	h.OnReceived(ctx, params, out)
+}
+
+func (h *Hooks[T]) visibilityChanged(ctx context.Context, params *ChangeMessageVisibilityInput, out *ChangeMessageVisibilityOutput[T]) {
+	if h == nil || h.OnVisibilityChanged == nil {
+		return
+	}
+	defer h.recoverPanic("OnVisibilityChanged")
+	h.OnVisibilityChanged(ctx, params, out)
+}
+
+func (h *Hooks[T]) deleted(ctx context.Context, params *DeleteMessageInput, out *DeleteMessageOutput) {
+	if h == nil || h.OnDeleted == nil {
+		return
+	}
+	defer h.recoverPanic("OnDeleted")
+	h.OnDeleted(ctx, params, out)
+}
+
+func (h *Hooks[T]) movedToDLQ(ctx context.Context, params *MoveMessageToDLQInput, out *MoveMessageToDLQOutput[T]) {
+	if h == nil || h.OnMovedToDLQ == nil {
+		return
+	}
+	defer h.recoverPanic("OnMovedToDLQ")
+	h.OnMovedToDLQ(ctx, params, out)
+}
+
+func (h *Hooks[T]) redriven(ctx context.Context, params *RedriveMessageInput, out *RedriveMessageOutput[T]) {
+	if h == nil || h.OnRedriven == nil {
+		return
+	}
+	defer h.recoverPanic("OnRedriven")
+	h.OnRedriven(ctx, params, out)
+}
+
+func (h *Hooks[T]) canaryChecked(ctx context.Context, params *CheckCanaryInput, out *CheckCanaryOutput) {
+	if h == nil || h.OnCanaryChecked == nil {
+		return
+	}
+	defer h.recoverPanic("OnCanaryChecked")
+	h.OnCanaryChecked(ctx, params, out)
+}
+
+func (h *Hooks[T]) recoverPanic(name string) {
+	if r := recover(); r != nil {
+		logTo(h.errorLog, "DynamoMQ: Recovered from a panic in the %s hook. %v", name, r)
+	}
+}
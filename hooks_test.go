@@ -0,0 +1,350 @@
+package dynamomq_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+// newRecordingHooks returns hooks that append each call to calls, with the ID and the version of the message.
+func newRecordingHooks(calls *[]string) *dynamomq.Hooks[test.MessageData] {
+	record := func(hook, id string, version int) {
+		*calls = append(*calls, fmt.Sprintf("%s %s v%d", hook, id, version))
+	}
+	return &dynamomq.Hooks[test.MessageData]{
+		OnSent: func(ctx context.Context, params *dynamomq.SendMessageInput[test.MessageData], out *dynamomq.SendMessageOutput[test.MessageData]) {
+			record("sent", params.ID, out.SentMessage.Version)
+		},
+		OnReceived: func(ctx context.Context, params *dynamomq.ReceiveMessageInput, out *dynamomq.ReceiveMessageOutput[test.MessageData]) {
+			record("received", out.ReceivedMessage.ID, out.ReceivedMessage.Version)
+		},
+		OnVisibilityChanged: func(ctx context.Context, params *dynamomq.ChangeMessageVisibilityInput, out *dynamomq.ChangeMessageVisibilityOutput[test.MessageData]) {
+			record("visibility changed", params.ID, out.ChangedMessage.Version)
+		},
+		OnDeleted: func(ctx context.Context, params *dynamomq.DeleteMessageInput, out *dynamomq.DeleteMessageOutput) {
+			record("deleted", params.ID, 0)
+		},
+		OnMovedToDLQ: func(ctx context.Context, params *dynamomq.MoveMessageToDLQInput, out *dynamomq.MoveMessageToDLQOutput[test.MessageData]) {
+			record("moved to DLQ", params.ID, out.MovedMessage.Version)
+		},
+		OnRedriven: func(ctx context.Context, params *dynamomq.RedriveMessageInput, out *dynamomq.RedriveMessageOutput[test.MessageData]) {
+			record("redriven", params.ID, out.RedroveMessage.Version)
+		},
+	}
+}
+
+func TestDynamoMQClientHooks(t *testing.T) {
+	t.Parallel()
+	var calls []string
+	hooks := newRecordingHooks(&calls)
+	clock := &steppingClock{now: test.DefaultTestDate}
+	client, clean := prepareTestClient(context.Background(), t, func(t *testing.T) (string, *dynamodb.Client, func()) {
+		return SetupDynamoDB(t)
+	}, clock, false, nil, nil, nil, dynamomq.WithHooks(hooks))
+	defer clean()
+	ctx := context.Background()
+	steps := []struct {
+		name string
+		call func() error
+	}{
+		{"SendMessage", func() error {
+			_, err := client.SendMessage(ctx, &dynamomq.SendMessageInput[test.MessageData]{ID: "A-101", Data: test.NewMessageData("A-101")})
+			return err
+		}},
+		{"ReceiveMessage", func() error {
+			_, err := client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{})
+			return err
+		}},
+		{"ChangeMessageVisibility", func() error {
+			_, err := client.ChangeMessageVisibility(ctx, &dynamomq.ChangeMessageVisibilityInput{ID: "A-101", VisibilityTimeout: 10})
+			return err
+		}},
+		{"MoveMessageToDLQ", func() error {
+			_, err := client.MoveMessageToDLQ(ctx, &dynamomq.MoveMessageToDLQInput{ID: "A-101"})
+			return err
+		}},
+		{"MoveMessageToDLQ of a message in the DLQ", func() error {
+			_, err := client.MoveMessageToDLQ(ctx, &dynamomq.MoveMessageToDLQInput{ID: "A-101"})
+			return err
+		}},
+		{"RedriveMessage", func() error {
+			_, err := client.RedriveMessage(ctx, &dynamomq.RedriveMessageInput{ID: "A-101"})
+			return err
+		}},
+		{"DeleteMessage", func() error {
+			_, err := client.DeleteMessage(ctx, &dynamomq.DeleteMessageInput{ID: "A-101"})
+			return err
+		}},
+	}
+	for _, step := range steps {
+		clock.Advance(time.Second)
+		if err := step.call(); err != nil {
+			t.Fatalf("%s error = %v", step.name, err)
+		}
+	}
+	// Failed operations do not invoke hooks.
+	if _, err := client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{}); err == nil {
+		t.Fatal("ReceiveMessage() of an empty queue succeeded")
+	}
+	test.AssertDeepEqual(t, calls, []string{
+		"sent A-101 v1",
+		"received A-101 v2",
+		"visibility changed A-101 v3",
+		"moved to DLQ A-101 v4",
+		"redriven A-101 v5",
+		"deleted A-101 v0",
+	}, "hook calls")
+}
+
+func TestDynamoMQClientHooksOnMaintenanceOperations(t *testing.T) {
+	t.Parallel()
+	var calls []string
+	hooks := newRecordingHooks(&calls)
+	clock := &steppingClock{now: test.DefaultTestDate}
+	client, clean := prepareTestClient(context.Background(), t, func(t *testing.T) (string, *dynamodb.Client, func()) {
+		return SetupDynamoDB(t)
+	}, clock, false, nil, nil, nil, dynamomq.WithHooks(hooks))
+	defer clean()
+	ctx := context.Background()
+	steps := []struct {
+		name string
+		call func() error
+	}{
+		{"SendMessage", func() error {
+			_, err := client.SendMessage(ctx, &dynamomq.SendMessageInput[test.MessageData]{ID: "A-101", Data: test.NewMessageData("A-101")})
+			return err
+		}},
+		{"ReceiveMessage", func() error {
+			_, err := client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{VisibilityTimeout: 1})
+			return err
+		}},
+		{"ReclaimExpiredMessages", func() error {
+			clock.Advance(5 * time.Second)
+			_, err := client.ReclaimExpiredMessages(ctx, &dynamomq.ReclaimExpiredMessagesInput{})
+			return err
+		}},
+		{"UpdateMessageData", func() error {
+			_, err := client.UpdateMessageData(ctx, &dynamomq.UpdateMessageDataInput[test.MessageData]{ID: "A-101", Data: test.NewMessageData("A-101")})
+			return err
+		}},
+		{"ResetReceiveCount", func() error {
+			_, err := client.ResetReceiveCount(ctx, &dynamomq.ResetReceiveCountInput{ID: "A-101"})
+			return err
+		}},
+		{"HoldMessage", func() error {
+			_, err := client.HoldMessage(ctx, &dynamomq.HoldMessageInput{ID: "A-101", Reason: "INC-42"})
+			return err
+		}},
+		{"ReleaseMessage", func() error {
+			_, err := client.ReleaseMessage(ctx, &dynamomq.ReleaseMessageInput{ID: "A-101"})
+			return err
+		}},
+		{"ReplaceMessage", func() error {
+			retrieved, err := client.GetMessage(ctx, &dynamomq.GetMessageInput{ID: "A-101"})
+			if err != nil {
+				return err
+			}
+			_, err = client.ReplaceMessage(ctx, &dynamomq.ReplaceMessageInput[test.MessageData]{Message: retrieved.Message})
+			return err
+		}},
+	}
+	for _, step := range steps {
+		clock.Advance(time.Second)
+		if err := step.call(); err != nil {
+			t.Fatalf("%s error = %v", step.name, err)
+		}
+	}
+	// UpdateMessageData, ResetReceiveCount, HoldMessage and ReleaseMessage invoke no hook.
+	test.AssertDeepEqual(t, calls, []string{
+		"sent A-101 v1",
+		"received A-101 v2",
+		"visibility changed A-101 v3",
+		"deleted A-101 v0",
+		"sent A-101 v7",
+	}, "hook calls")
+}
+
+func TestDynamoMQClientHooksOnTransactions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		transactOK bool
+		call       func(ctx context.Context, client dynamomq.Client[test.MessageData]) error
+		want       []string
+	}{
+		{
+			name:       "SendMessagesInTransaction should invoke OnSent for each message in order",
+			transactOK: true,
+			call: func(ctx context.Context, client dynamomq.Client[test.MessageData]) error {
+				_, err := client.SendMessagesInTransaction(ctx, &dynamomq.SendMessagesInTransactionInput[test.MessageData]{
+					Messages: []*dynamomq.SendMessageInput[test.MessageData]{
+						{ID: "B-101", Data: test.NewMessageData("B-101")},
+						{ID: "B-102", Data: test.NewMessageData("B-102")},
+					},
+				})
+				return err
+			},
+			want: []string{"sent B-101 B-101", "sent B-102 B-102"},
+		},
+		{
+			name:       "ChainMessage should invoke OnDeleted and then OnSent",
+			transactOK: true,
+			call: func(ctx context.Context, client dynamomq.Client[test.MessageData]) error {
+				_, err := client.ChainMessage(ctx, &dynamomq.ChainMessageInput[test.MessageData]{
+					DeleteID:      "A-101",
+					DeleteVersion: 2,
+					Next:          &dynamomq.SendMessageInput[test.MessageData]{ID: "B-101", Data: test.NewMessageData("B-101")},
+				})
+				return err
+			},
+			want: []string{"deleted A-101", "sent B-101 B-101"},
+		},
+		{
+			name: "ChainMessage should not invoke hooks when the transaction fails",
+			call: func(ctx context.Context, client dynamomq.Client[test.MessageData]) error {
+				_, err := client.ChainMessage(ctx, &dynamomq.ChainMessageInput[test.MessageData]{
+					DeleteID:      "A-101",
+					DeleteVersion: 2,
+					Next:          &dynamomq.SendMessageInput[test.MessageData]{ID: "B-101", Data: test.NewMessageData("B-101")},
+				})
+				if err == nil {
+					return fmt.Errorf("ChainMessage() error = nil, want the transaction error")
+				}
+				return nil
+			},
+		},
+		{
+			name: "MoveMessage should invoke OnDeleted for the source",
+			call: func(ctx context.Context, client dynamomq.Client[test.MessageData]) error {
+				_, err := client.MoveMessage(ctx, &dynamomq.MoveMessageInput{ID: "A-101", TargetTableName: "other"})
+				return err
+			},
+			want: []string{"deleted A-101"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var calls []string
+			hooks := &dynamomq.Hooks[test.MessageData]{
+				OnSent: func(ctx context.Context, params *dynamomq.SendMessageInput[test.MessageData], out *dynamomq.SendMessageOutput[test.MessageData]) {
+					calls = append(calls, fmt.Sprintf("sent %s %s", params.ID, out.SentMessage.ID))
+				},
+				OnDeleted: func(ctx context.Context, params *dynamomq.DeleteMessageInput, out *dynamomq.DeleteMessageOutput) {
+					calls = append(calls, "deleted "+params.ID)
+				},
+			}
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+				mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+				dynamomq.WithHooks(hooks),
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+						message := NewTestMessageItemAsProcessing("A-101", test.DefaultTestDate)
+						return &dynamodb.GetItemOutput{Item: dynamomqtest.MarshalMap(message)}, nil
+					},
+					PutItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+						return &dynamodb.PutItemOutput{}, nil
+					},
+					DeleteItemFunc: func(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+						return &dynamodb.DeleteItemOutput{}, nil
+					},
+					TransactWriteItemsFunc: func(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+						if !tt.transactOK {
+							return nil, test.ErrTest
+						}
+						return &dynamodb.TransactWriteItemsOutput{}, nil
+					},
+				}))
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			if err := tt.call(context.Background(), client); err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			test.AssertDeepEqual(t, calls, tt.want, "hook calls")
+		})
+	}
+}
+
+func TestDynamoMQClientHooksShouldRecoverPanics(t *testing.T) {
+	t.Parallel()
+	var (
+		puts   int
+		logged bytes.Buffer
+	)
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithHooks(&dynamomq.Hooks[test.MessageData]{
+			OnSent: func(ctx context.Context, params *dynamomq.SendMessageInput[test.MessageData], out *dynamomq.SendMessageOutput[test.MessageData]) {
+				panic("hook failure")
+			},
+		}),
+		dynamomq.WithClientErrorLog(log.New(&logged, "", 0)),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+				return &dynamodb.GetItemOutput{}, nil
+			},
+			PutItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+				puts++
+				return &dynamodb.PutItemOutput{}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	out, err := client.SendMessage(context.Background(), &dynamomq.SendMessageInput[test.MessageData]{ID: "A-101", Data: test.NewMessageData("A-101")})
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if out.SentMessage == nil || out.SentMessage.ID != "A-101" || puts != 1 {
+		t.Errorf("SendMessage() = %+v with %d puts, want the sent message", out, puts)
+	}
+	if !strings.Contains(logged.String(), "OnSent hook. hook failure") {
+		t.Errorf("error log = %q, want the recovered panic", logged.String())
+	}
+}
+
+func TestDynamoMQClientHooksShouldNotRunOnFailure(t *testing.T) {
+	t.Parallel()
+	var called bool
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithHooks(&dynamomq.Hooks[test.MessageData]{
+			OnDeleted: func(ctx context.Context, params *dynamomq.DeleteMessageInput, out *dynamomq.DeleteMessageOutput) {
+				called = true
+			},
+		}),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			DeleteItemFunc: func(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+				return nil, test.ErrTest
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	if _, err := client.DeleteMessage(context.Background(), &dynamomq.DeleteMessageInput{ID: "A-101"}); err == nil {
+		t.Fatal("DeleteMessage() error = nil, want an error")
+	}
+	if called {
+		t.Error("OnDeleted was called after a failed DeleteMessage")
+	}
+}
+
+func TestNewFromConfigShouldReturnInvalidHooksError(t *testing.T) {
+	t.Parallel()
+	_, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{}, dynamomq.WithHooks(&dynamomq.Hooks[string]{}))
+	test.AssertError(t, err, dynamomq.InvalidHooksError{
+		Hooks:       "*dynamomq.Hooks[string]",
+		MessageType: "test.MessageData",
+	}, "NewFromConfig()")
+}
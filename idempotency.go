@@ -0,0 +1,151 @@
+package dynamomq
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+)
+
+const (
+	processedIDPrefix         = "dynamomq-processed#"
+	defaultIdempotencyTTL     = 7 * 24 * time.Hour
+	defaultIdempotencyKeyName = AttributeNameID
+)
+
+// IdempotencyStore records the IDs of the messages that have been processed, so that a Consumer configured
+// with WithEnsureExactlyOnce skips a message delivered again after it was processed.
+// Implementations must be safe for concurrent use.
+type IdempotencyStore interface {
+	// IsProcessed reports whether the message with the ID has been marked as processed.
+	IsProcessed(ctx context.Context, id string) (bool, error)
+	// MarkProcessed marks the message with the ID as processed. Marking a message already marked is not an error.
+	MarkProcessed(ctx context.Context, id string) error
+}
+
+// DynamoDBIdempotencyStoreOptions contains configuration options for a DynamoDBIdempotencyStore.
+type DynamoDBIdempotencyStoreOptions struct {
+	// TTL is the time a marker is kept. It must be longer than the time a message can be delivered again,
+	// which is bounded by the retention of the queue. By default, it is seven days.
+	TTL time.Duration
+	// KeyAttribute is the partition key of the table. By default, it is "id", the partition key of a queue table.
+	KeyAttribute string
+	// ExpiresAtAttribute holds the expiry of a marker in Unix seconds. By default, it is AttributeNameLockExpiresAt,
+	// so that a queue table has a single attribute to enable as its TTL attribute for both the locks and the markers.
+	ExpiresAtAttribute string
+	// Clock is an abstraction of time operations, allowing control over time during tests.
+	Clock clock.Clock
+}
+
+// WithIdempotencyTTL sets the time a marker is kept.
+func WithIdempotencyTTL(ttl time.Duration) func(o *DynamoDBIdempotencyStoreOptions) {
+	return func(o *DynamoDBIdempotencyStoreOptions) {
+		o.TTL = ttl
+	}
+}
+
+// WithIdempotencyAttributes sets the partition key of the table and the attribute holding the expiry of a marker.
+func WithIdempotencyAttributes(keyAttribute, expiresAtAttribute string) func(o *DynamoDBIdempotencyStoreOptions) {
+	return func(o *DynamoDBIdempotencyStoreOptions) {
+		o.KeyAttribute = keyAttribute
+		o.ExpiresAtAttribute = expiresAtAttribute
+	}
+}
+
+// NewDynamoDBIdempotencyStore creates an IdempotencyStore keeping a marker item per processed message in a DynamoDB table.
+// The table can be the queue table when its partition key is its only key, as in the default table definition;
+// marker items have no queue type, so they are never received as messages.
+func NewDynamoDBIdempotencyStore(api DynamoDBAPI, tableName string, opts ...func(o *DynamoDBIdempotencyStoreOptions)) *DynamoDBIdempotencyStore {
+	o := &DynamoDBIdempotencyStoreOptions{
+		TTL:                defaultIdempotencyTTL,
+		KeyAttribute:       defaultIdempotencyKeyName,
+		ExpiresAtAttribute: AttributeNameLockExpiresAt,
+		Clock:              &clock.RealClock{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &DynamoDBIdempotencyStore{
+		dynamoDB:           api,
+		tableName:          tableName,
+		ttl:                o.TTL,
+		keyAttribute:       o.KeyAttribute,
+		expiresAtAttribute: o.ExpiresAtAttribute,
+		clock:              o.Clock,
+	}
+}
+
+// DynamoDBIdempotencyStore is an IdempotencyStore writing a marker item with an expiry for each processed message.
+// A marker past its expiry is ignored even before DynamoDB deletes it with its TTL.
+// Note: To create a new instance of DynamoDBIdempotencyStore, it is necessary to use the NewDynamoDBIdempotencyStore function.
+type DynamoDBIdempotencyStore struct {
+	dynamoDB           DynamoDBAPI
+	tableName          string
+	ttl                time.Duration
+	keyAttribute       string
+	expiresAtAttribute string
+	clock              clock.Clock
+}
+
+// IsProcessed reports whether an unexpired marker exists for the message with the ID.
+func (s *DynamoDBIdempotencyStore) IsProcessed(ctx context.Context, id string) (bool, error) {
+	out, err := s.dynamoDB.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.tableName),
+		Key:            s.key(id),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return false, handleDynamoDBError(err)
+	}
+	v, ok := out.Item[s.expiresAtAttribute].(*types.AttributeValueMemberN)
+	if !ok {
+		return false, nil
+	}
+	expiresAt, err := strconv.ParseInt(v.Value, 10, 64)
+	if err != nil {
+		return false, UnmarshalingAttributeError{Cause: err}
+	}
+	return expiresAt > s.clock.Now().Unix(), nil
+}
+
+// MarkProcessed writes the marker of the message with the ID, with a put conditional on the absence
+// of an unexpired marker, so that the expiry of the first marker is kept.
+func (s *DynamoDBIdempotencyStore) MarkProcessed(ctx context.Context, id string) error {
+	now := s.clock.Now()
+	item := s.key(id)
+	item[s.expiresAtAttribute] = &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Add(s.ttl).Unix(), 10)}
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.AttributeNotExists(expression.Name(s.keyAttribute)).
+			Or(expression.Name(s.expiresAtAttribute).LessThanEqual(expression.Value(now.Unix())))).
+		Build()
+	if err != nil {
+		return BuildingExpressionError{Cause: err}
+	}
+	_, err = s.dynamoDB.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(s.tableName),
+		Item:                      item,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		var cause *types.ConditionalCheckFailedException
+		if errors.As(err, &cause) {
+			return nil
+		}
+		return handleDynamoDBError(err)
+	}
+	return nil
+}
+
+func (s *DynamoDBIdempotencyStore) key(id string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		s.keyAttribute: &types.AttributeValueMemberS{Value: processedIDPrefix + id},
+	}
+}
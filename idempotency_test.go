@@ -0,0 +1,219 @@
+package dynamomq_test
+
+import (
+	"context"
+	"io"
+	"log"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+// memoryIdempotencyStore is an IdempotencyStore keeping the processed IDs in memory.
+type memoryIdempotencyStore struct {
+	mu        sync.Mutex
+	processed map[string]bool
+	checkErr  error
+}
+
+func (s *memoryIdempotencyStore) IsProcessed(ctx context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.processed[id], s.checkErr
+}
+
+func (s *memoryIdempotencyStore) MarkProcessed(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.processed[id] = true
+	return nil
+}
+
+func (s *memoryIdempotencyStore) isProcessed(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.processed[id]
+}
+
+func TestConsumerWithEnsureExactlyOnce(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		// deliveries are the IDs of the messages received, in order.
+		deliveries []string
+		// failDeletes is the number of deletes that fail before one succeeds, as if the Consumer crashed before deleting.
+		failDeletes   int
+		failProcessed map[string]bool
+		checkErr      error
+		wantProcessed []string
+		wantMarked    []string
+		wantDeleted   []string
+	}{
+		{
+			name:          "should skip a message redelivered after it was marked",
+			deliveries:    []string{"A-101", "A-101"},
+			failDeletes:   1,
+			wantProcessed: []string{"A-101"},
+			wantMarked:    []string{"A-101"},
+			wantDeleted:   []string{"A-101", "A-101"},
+		},
+		{
+			name:          "should not mark a message whose processing failed",
+			deliveries:    []string{"A-101", "A-101"},
+			failProcessed: map[string]bool{"A-101": true},
+			wantProcessed: []string{"A-101", "A-101"},
+		},
+		{
+			name:       "should not process a message while the store cannot be read",
+			deliveries: []string{"A-101"},
+			checkErr:   test.ErrTest,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := &memoryIdempotencyStore{processed: map[string]bool{}, checkErr: tt.checkErr}
+			var (
+				mu        sync.Mutex
+				polls     atomic.Int32
+				deletes   int
+				deleted   []string
+				processed []string
+			)
+			done := make(chan struct{})
+			client := &mock.Client[test.MessageData]{
+				ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+					i := int(polls.Add(1)) - 1
+					if i < len(tt.deliveries) {
+						return &dynamomq.ReceiveMessageOutput[test.MessageData]{
+							ReceivedMessage: NewTestMessageItemAsProcessing(tt.deliveries[i], test.DefaultTestDate),
+						}, nil
+					}
+					if i == len(tt.deliveries)+1 {
+						close(done)
+					}
+					return nil, &dynamomq.EmptyQueueError{}
+				},
+				DeleteMessageFunc: func(ctx context.Context, params *dynamomq.DeleteMessageInput) (*dynamomq.DeleteMessageOutput, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					deleted = append(deleted, params.ID)
+					if deletes++; deletes <= tt.failDeletes {
+						return nil, test.ErrTest
+					}
+					return &dynamomq.DeleteMessageOutput{}, nil
+				},
+				ChangeMessageVisibilityFunc: func(ctx context.Context, params *dynamomq.ChangeMessageVisibilityInput) (*dynamomq.ChangeMessageVisibilityOutput[test.MessageData], error) {
+					return &dynamomq.ChangeMessageVisibilityOutput[test.MessageData]{}, nil
+				},
+			}
+			consumer := dynamomq.NewConsumer[test.MessageData](client,
+				dynamomq.MessageProcessorFunc[test.MessageData](func(msg *dynamomq.Message[test.MessageData]) error {
+					mu.Lock()
+					defer mu.Unlock()
+					processed = append(processed, msg.ID)
+					if tt.failProcessed[msg.ID] {
+						return test.ErrTest
+					}
+					return nil
+				}),
+				dynamomq.WithEnsureExactlyOnce(store),
+				dynamomq.WithConcurrency(1),
+				dynamomq.WithMaximumReceives(0),
+				dynamomq.WithPollingInterval(time.Millisecond),
+				dynamomq.WithErrorLog(log.New(io.Discard, "", 0)))
+			go func() {
+				_ = consumer.StartConsuming()
+			}()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("the deliveries were not consumed")
+			}
+			if err := consumer.Shutdown(context.Background()); err != nil {
+				t.Fatalf("Shutdown() error = %v", err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			test.AssertDeepEqual(t, processed, tt.wantProcessed, "processed")
+			test.AssertDeepEqual(t, deleted, tt.wantDeleted, "deleted")
+			var marked []string
+			for _, id := range []string{"A-101"} {
+				if store.isProcessed(id) {
+					marked = append(marked, id)
+				}
+			}
+			test.AssertDeepEqual(t, marked, tt.wantMarked, "marked")
+		})
+	}
+}
+
+func TestDynamoDBIdempotencyStore(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate
+	clk := &mock.Clock{T: now}
+	var stored map[string]types.AttributeValue
+	api := &mock.DynamoDB{
+		GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+			if stored == nil || params.Key[dynamomq.AttributeNameID].(*types.AttributeValueMemberS).Value != stored[dynamomq.AttributeNameID].(*types.AttributeValueMemberS).Value {
+				return &dynamodb.GetItemOutput{}, nil
+			}
+			return &dynamodb.GetItemOutput{Item: stored}, nil
+		},
+		PutItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+			if stored != nil {
+				return nil, &types.ConditionalCheckFailedException{}
+			}
+			stored = params.Item
+			return &dynamodb.PutItemOutput{}, nil
+		},
+	}
+	store := dynamomq.NewDynamoDBIdempotencyStore(api, "table", dynamomq.WithIdempotencyTTL(time.Hour),
+		func(o *dynamomq.DynamoDBIdempotencyStoreOptions) {
+			o.Clock = clk
+		})
+	ctx := context.Background()
+	isProcessed := func(id string) bool {
+		t.Helper()
+		processed, err := store.IsProcessed(ctx, id)
+		if err != nil {
+			t.Fatalf("IsProcessed() error = %v", err)
+		}
+		return processed
+	}
+	if isProcessed("A-101") {
+		t.Error("IsProcessed() of an unmarked message = true, want false")
+	}
+	for i := 0; i < 2; i++ {
+		if err := store.MarkProcessed(ctx, "A-101"); err != nil {
+			t.Fatalf("MarkProcessed() error = %v", err)
+		}
+	}
+	if _, ok := stored[dynamomq.AttributeNameQueueType]; ok {
+		t.Errorf("MarkProcessed() item has a queue type: %v", stored)
+	}
+	if !isProcessed("A-101") {
+		t.Error("IsProcessed() of a marked message = false, want true")
+	}
+	if isProcessed("A-102") {
+		t.Error("IsProcessed() of another message = true, want false")
+	}
+	clk.T = now.Add(time.Hour)
+	if isProcessed("A-101") {
+		t.Error("IsProcessed() of an expired marker = true, want false")
+	}
+	api.PutItemFunc = func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+		return nil, test.ErrTest
+	}
+	if err := store.MarkProcessed(ctx, "A-102"); err == nil {
+		t.Error("MarkProcessed() error = nil, want the error of DynamoDB")
+	}
+}
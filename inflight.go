@@ -0,0 +1,206 @@
+package dynamomq
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+)
+
+const defaultInFlightLimit = 100
+
+// ListInFlightMessagesInput represents the input parameters for listing the messages being processed.
+type ListInFlightMessagesInput struct {
+	// QueueType is the type of queue (STANDARD or DLQ) to list. By default, it is STANDARD.
+	QueueType QueueType
+	// Limit is the maximum number of messages returned in one page. By default, it is 100.
+	Limit int32
+	// NextToken is the token returned by the previous page, or empty for the first page.
+	NextToken string
+}
+
+// InFlightMessage describes a message being processed.
+type InFlightMessage struct {
+	// ID is the unique identifier of the message.
+	ID string `json:"id"`
+	// ReceiveCount is the number of times the message has been received.
+	ReceiveCount int `json:"receive_count"`
+	// ConsumerID identifies the consumer processing the message. It is empty if it was not recorded.
+	ConsumerID string `json:"consumer_id,omitempty"`
+	// ReceivedAt is the time the message was last received.
+	ReceivedAt time.Time `json:"received_at"`
+	// VisibleAt is the time the visibility timeout of the message expires.
+	VisibleAt time.Time `json:"visible_at"`
+}
+
+// ListInFlightMessagesOutput represents the result of the operation to list the messages being processed.
+type ListInFlightMessagesOutput struct {
+	// Messages are the messages being processed, sorted by the soonest expiry of their visibility timeout within the page.
+	Messages []InFlightMessage `json:"messages"`
+	// NextToken is set when more messages may follow. Pass it in the next input to get the next page.
+	NextToken string `json:"next_token,omitempty"`
+}
+
+// ListInFlightMessages lists the messages that are being processed, with the time each becomes visible again.
+// It queries the queueing index with a filter on the visibility timeout instead of scanning the table.
+// Messages are sorted by the soonest expiry within each page; use a Limit large enough to hold every message
+// being processed to get a global order.
+func (c *ClientImpl[T]) ListInFlightMessages(ctx context.Context, params *ListInFlightMessagesInput) (*ListInFlightMessagesOutput, error) {
+	if params == nil {
+		params = &ListInFlightMessagesInput{}
+	}
+	if params.QueueType == "" {
+		params.QueueType = QueueTypeStandard
+	}
+	if params.Limit <= 0 {
+		params.Limit = defaultInFlightLimit
+	}
+	exclusiveStartKey, err := decodeNextToken(params.NextToken)
+	if err != nil {
+		return &ListInFlightMessagesOutput{}, err
+	}
+	now := c.clock.Now()
+	// Timestamps with trailing zeros trimmed do not compare lexically within a second,
+	// so the filter keeps a second of margin and the status is checked again below.
+	cutoff := clock.FormatRFC3339Nano(now.Truncate(time.Second).Add(-time.Second))
+	builder := expression.NewBuilder().
+		WithKeyCondition(expression.Key(c.schema.QueueTypeAttribute).Equal(expression.Value(params.QueueType))).
+		WithFilter(expression.Name(c.schema.InvisibleUntilAtAttribute).GreaterThan(expression.Value(cutoff)))
+	expr, err := c.buildExpression(builder)
+	if err != nil {
+		return &ListInFlightMessagesOutput{}, BuildingExpressionError{Cause: err}
+	}
+	out := &ListInFlightMessagesOutput{Messages: make([]InFlightMessage, 0)}
+	for {
+		if err := ctx.Err(); err != nil {
+			return &ListInFlightMessagesOutput{}, OperationCanceledError{Cause: err}
+		}
+		queryOutput, err := c.dynamoDB.Query(ctx, &dynamodb.QueryInput{
+			IndexName:                 aws.String(c.schema.QueueingIndexName),
+			TableName:                 aws.String(c.tableName),
+			KeyConditionExpression:    expr.KeyCondition(),
+			FilterExpression:          expr.Filter(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			ScanIndexForward:          aws.Bool(true),
+			Limit:                     aws.Int32(defaultQueryLimit),
+			ExclusiveStartKey:         exclusiveStartKey,
+		})
+		if err != nil {
+			return &ListInFlightMessagesOutput{}, handleDynamoDBError(err)
+		}
+		for _, item := range queryOutput.Items {
+			message := Message[T]{}
+			if err := c.unmarshalItem(item, &message); err != nil {
+				if err = c.handleCorruptMessage(item, err); err != nil {
+					return &ListInFlightMessagesOutput{}, err
+				}
+				continue
+			}
+			if !message.IsProcessing(now) {
+				continue
+			}
+			inFlight, err := newInFlightMessage(&message)
+			if err != nil {
+				return &ListInFlightMessagesOutput{}, err
+			}
+			out.Messages = append(out.Messages, inFlight)
+			if int32(len(out.Messages)) == params.Limit {
+				out.NextToken, err = encodeNextToken(c.indexKey(item))
+				if err != nil {
+					return &ListInFlightMessagesOutput{}, err
+				}
+				sortInFlightMessages(out.Messages)
+				return out, nil
+			}
+		}
+		exclusiveStartKey = queryOutput.LastEvaluatedKey
+		if exclusiveStartKey == nil {
+			sortInFlightMessages(out.Messages)
+			return out, nil
+		}
+	}
+}
+
+func newInFlightMessage[T any](message *Message[T]) (InFlightMessage, error) {
+	receivedAt, err := message.ParsedReceivedAt()
+	if err != nil {
+		return InFlightMessage{}, err
+	}
+	visibleAt, err := message.VisibleAt()
+	if err != nil {
+		return InFlightMessage{}, err
+	}
+	return InFlightMessage{
+		ID:           message.ID,
+		ReceiveCount: message.ReceiveCount,
+		ConsumerID:   message.ConsumerID,
+		ReceivedAt:   receivedAt,
+		VisibleAt:    visibleAt,
+	}, nil
+}
+
+func sortInFlightMessages(messages []InFlightMessage) {
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].VisibleAt.Before(messages[j].VisibleAt)
+	})
+}
+
+// indexKey returns the attributes of an item that make up a position in the queueing index.
+func (c *ClientImpl[T]) indexKey(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	key := make(map[string]types.AttributeValue)
+	for _, name := range []string{
+		c.schema.PartitionKeyAttribute,
+		c.schema.SortKeyAttribute,
+		c.schema.QueueTypeAttribute,
+		c.schema.SentAtAttribute,
+	} {
+		if v, ok := item[name]; ok && name != "" {
+			key[name] = v
+		}
+	}
+	return key
+}
+
+// encodeNextToken encodes a key made of string attributes into an opaque pagination token.
+func encodeNextToken(key map[string]types.AttributeValue) (string, error) {
+	values := make(map[string]string, len(key))
+	for name, v := range key {
+		s, ok := v.(*types.AttributeValueMemberS)
+		if !ok {
+			return "", InvalidNextTokenError{Reason: "attribute " + name + " of the key is not a string"}
+		}
+		values[name] = s.Value
+	}
+	b, err := json.Marshal(values)
+	if err != nil {
+		return "", MarshalingAttributeError{Cause: err}
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeNextToken(token string) (map[string]types.AttributeValue, error) {
+	if token == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, InvalidNextTokenError{Reason: err.Error()}
+	}
+	var values map[string]string
+	if err := json.Unmarshal(b, &values); err != nil {
+		return nil, InvalidNextTokenError{Reason: err.Error()}
+	}
+	key := make(map[string]types.AttributeValue, len(values))
+	for name, v := range values {
+		key[name] = &types.AttributeValueMemberS{Value: v}
+	}
+	return key, nil
+}
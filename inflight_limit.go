@@ -0,0 +1,220 @@
+package dynamomq
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	// AttributeNameInFlightCount holds the number of messages holding an in-flight slot in the counter item.
+	AttributeNameInFlightCount = "inflight_count"
+	// AttributeNameInFlightSlot marks a message holding a slot of the in-flight limit set with WithMaxInFlight.
+	AttributeNameInFlightSlot = "inflight_slot"
+
+	inFlightCounterItemID = "dynamomq-inflight#queue"
+)
+
+// acquireInFlightSlot increments the counter item unless it has reached the in-flight limit.
+func (c *ClientImpl[T]) acquireInFlightSlot(ctx context.Context) error {
+	count := expression.Name(AttributeNameInFlightCount)
+	expr, err := expression.NewBuilder().
+		WithUpdate(expression.Add(count, expression.Value(1))).
+		WithCondition(expression.Or(
+			expression.AttributeNotExists(count),
+			count.LessThan(expression.Value(c.maxInFlight)))).
+		Build()
+	if err != nil {
+		return BuildingExpressionError{Cause: err}
+	}
+	_, err = c.dynamoDB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(c.tableName),
+		Key:                       c.itemKey(inFlightCounterItemID),
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		var cause *types.ConditionalCheckFailedException
+		if errors.As(err, &cause) {
+			return InFlightLimitExceededError{Limit: c.maxInFlight}
+		}
+		return handleDynamoDBError(err)
+	}
+	return nil
+}
+
+// releaseInFlightSlot decrements the counter item without letting it go below zero.
+// A failure is logged rather than returned, because the message has already been released;
+// the drift it leaves is corrected by ReconcileInFlightCount.
+func (c *ClientImpl[T]) releaseInFlightSlot(ctx context.Context, id string) {
+	count := expression.Name(AttributeNameInFlightCount)
+	expr, err := expression.NewBuilder().
+		WithUpdate(expression.Add(count, expression.Value(-1))).
+		WithCondition(count.GreaterThan(expression.Value(0))).
+		Build()
+	if err == nil {
+		_, err = c.dynamoDB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName:                 aws.String(c.tableName),
+			Key:                       c.itemKey(inFlightCounterItemID),
+			UpdateExpression:          expr.Update(),
+			ConditionExpression:       expr.Condition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+		})
+	}
+	var cause *types.ConditionalCheckFailedException
+	if err != nil && !errors.As(err, &cause) {
+		c.logf("DynamoMQ: Failed to release the in-flight slot of message %s. %s", id, err)
+	}
+}
+
+// setInFlightSlot marks the message as holding an in-flight slot in the update when it holds one.
+func (c *ClientImpl[T]) setInFlightSlot(update expression.UpdateBuilder, message *Message[T]) expression.UpdateBuilder {
+	if !message.InFlightSlot {
+		return update
+	}
+	return update.Set(expression.Name(c.schema.InFlightSlotAttribute), expression.Value(true))
+}
+
+// removeInFlightSlot removes the in-flight slot from the message in the update when it is released.
+func (c *ClientImpl[T]) removeInFlightSlot(update expression.UpdateBuilder, release bool) expression.UpdateBuilder {
+	if !release {
+		return update
+	}
+	return update.Remove(expression.Name(c.schema.InFlightSlotAttribute))
+}
+
+// ReconcileInFlightCountInput represents the input parameters for reconciling the in-flight counter.
+type ReconcileInFlightCountInput struct {
+	// QueueTypes are the queue types whose messages are counted. By default, they are STANDARD, DLQ
+	// and the queue types received in rotation.
+	QueueTypes []QueueType
+}
+
+// ReconcileInFlightCountOutput represents the result of the operation to reconcile the in-flight counter.
+type ReconcileInFlightCountOutput struct {
+	// Previous is the value of the counter before the reconciliation.
+	Previous int
+	// Count is the number of messages holding an in-flight slot, which the counter is set to.
+	Count int
+}
+
+// ReconcileInFlightCount sets the counter of the in-flight limit to the number of messages holding a slot.
+// The counter drifts when a client crashes between changing a message and updating the counter;
+// run this function after such a crash, or periodically. The counter is set only if it has not changed
+// while the messages were counted, otherwise a ConditionalCheckFailedError is returned and the call can be retried.
+func (c *ClientImpl[T]) ReconcileInFlightCount(ctx context.Context, params *ReconcileInFlightCountInput) (*ReconcileInFlightCountOutput, error) {
+	if params == nil {
+		params = &ReconcileInFlightCountInput{}
+	}
+	queueTypes := params.QueueTypes
+	if len(queueTypes) == 0 {
+		queueTypes = append([]QueueType{QueueTypeStandard, QueueTypeDLQ}, c.receiveSchedule...)
+	}
+	previous, err := c.readInFlightCount(ctx)
+	if err != nil {
+		return &ReconcileInFlightCountOutput{}, err
+	}
+	count := 0
+	seen := make(map[QueueType]bool)
+	for _, queueType := range queueTypes {
+		if seen[queueType] {
+			continue
+		}
+		seen[queueType] = true
+		n, err := c.countInFlightSlots(ctx, queueType)
+		if err != nil {
+			return &ReconcileInFlightCountOutput{}, err
+		}
+		count += n
+	}
+	condition := expression.AttributeNotExists(expression.Name(AttributeNameInFlightCount))
+	if previous != nil {
+		condition = expression.Name(AttributeNameInFlightCount).Equal(expression.Value(*previous))
+	}
+	expr, err := expression.NewBuilder().
+		WithUpdate(expression.Set(expression.Name(AttributeNameInFlightCount), expression.Value(count))).
+		WithCondition(condition).
+		Build()
+	if err != nil {
+		return &ReconcileInFlightCountOutput{}, BuildingExpressionError{Cause: err}
+	}
+	_, err = c.dynamoDB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(c.tableName),
+		Key:                       c.itemKey(inFlightCounterItemID),
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		return &ReconcileInFlightCountOutput{}, handleDynamoDBError(err)
+	}
+	out := &ReconcileInFlightCountOutput{Count: count}
+	if previous != nil {
+		out.Previous = *previous
+	}
+	return out, nil
+}
+
+// readInFlightCount returns the value of the counter item, or nil if it does not exist.
+func (c *ClientImpl[T]) readInFlightCount(ctx context.Context) (*int, error) {
+	out, err := c.dynamoDB.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(c.tableName),
+		Key:            c.itemKey(inFlightCounterItemID),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, handleDynamoDBError(err)
+	}
+	v, ok := out.Item[AttributeNameInFlightCount].(*types.AttributeValueMemberN)
+	if !ok {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(v.Value)
+	if err != nil {
+		return nil, UnmarshalingAttributeError{Cause: err}
+	}
+	return &n, nil
+}
+
+func (c *ClientImpl[T]) countInFlightSlots(ctx context.Context, queueType QueueType) (int, error) {
+	expr, err := expression.NewBuilder().
+		WithKeyCondition(expression.Key(c.schema.QueueTypeAttribute).Equal(expression.Value(queueType))).
+		WithFilter(expression.AttributeExists(expression.Name(c.schema.InFlightSlotAttribute))).
+		Build()
+	if err != nil {
+		return 0, BuildingExpressionError{Cause: err}
+	}
+	var (
+		count             int
+		exclusiveStartKey map[string]types.AttributeValue
+	)
+	for {
+		out, err := c.dynamoDB.Query(ctx, &dynamodb.QueryInput{
+			IndexName:                 aws.String(c.schema.QueueingIndexName),
+			TableName:                 aws.String(c.tableName),
+			KeyConditionExpression:    expr.KeyCondition(),
+			FilterExpression:          expr.Filter(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			Select:                    types.SelectCount,
+			ExclusiveStartKey:         exclusiveStartKey,
+		})
+		if err != nil {
+			return 0, handleDynamoDBError(err)
+		}
+		count += int(out.Count)
+		exclusiveStartKey = out.LastEvaluatedKey
+		if exclusiveStartKey == nil {
+			return count, nil
+		}
+	}
+}
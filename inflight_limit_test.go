@@ -0,0 +1,219 @@
+package dynamomq_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestDynamoMQClientMaxInFlight(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	client, clean := prepareTestClient(context.Background(), t, NewSetupFunc(
+		newPutRequestWithReadyItem("A-101", test.DefaultTestDate),
+		newPutRequestWithReadyItem("A-102", test.DefaultTestDate.Add(time.Second)),
+	), mock.Clock{T: now}, false, nil, nil, nil, dynamomq.WithMaxInFlight(1))
+	defer clean()
+	ctx := context.Background()
+	received, err := client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{})
+	if err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	if !received.ReceivedMessage.InFlightSlot {
+		t.Error("ReceiveMessage() message does not hold an in-flight slot")
+	}
+	_, err = client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{})
+	test.AssertError(t, err, dynamomq.InFlightLimitExceededError{Limit: 1}, "ReceiveMessage()")
+
+	changed, err := client.ChangeMessageVisibility(ctx, &dynamomq.ChangeMessageVisibilityInput{ID: "A-101"})
+	if err != nil {
+		t.Fatalf("ChangeMessageVisibility() error = %v", err)
+	}
+	if changed.ChangedMessage.InFlightSlot {
+		t.Error("ChangeMessageVisibility() message still holds an in-flight slot")
+	}
+	received, err = client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{})
+	if err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	if _, err = client.DeleteMessage(ctx, &dynamomq.DeleteMessageInput{ID: received.ReceivedMessage.ID}); err != nil {
+		t.Fatalf("DeleteMessage() error = %v", err)
+	}
+	if _, err = client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{}); err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	reconciled, err := client.ReconcileInFlightCount(ctx, nil)
+	if err != nil {
+		t.Fatalf("ReconcileInFlightCount() error = %v", err)
+	}
+	test.AssertDeepEqual(t, reconciled, &dynamomq.ReconcileInFlightCountOutput{Previous: 1, Count: 1}, "ReconcileInFlightCount()")
+}
+
+func TestDynamoMQClientReplaceMessageShouldReleaseInFlightSlot(t *testing.T) {
+	t.Parallel()
+	client, clean := prepareTestClient(context.Background(), t, NewSetupFunc(
+		newPutRequestWithReadyItem("A-101", test.DefaultTestDate),
+	), mock.Clock{T: test.DefaultTestDate.Add(time.Minute)}, false, nil, nil, nil, dynamomq.WithMaxInFlight(2))
+	defer clean()
+	ctx := context.Background()
+	if _, err := client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{}); err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	_, err := client.ReplaceMessage(ctx, &dynamomq.ReplaceMessageInput[test.MessageData]{
+		Message: NewTestMessageItemAsReady("A-101", test.DefaultTestDate),
+	})
+	if err != nil {
+		t.Fatalf("ReplaceMessage() error = %v", err)
+	}
+	reconciled, err := client.ReconcileInFlightCount(ctx, nil)
+	if err != nil {
+		t.Fatalf("ReconcileInFlightCount() error = %v", err)
+	}
+	test.AssertDeepEqual(t, reconciled, &dynamomq.ReconcileInFlightCountOutput{Previous: 0, Count: 0}, "ReconcileInFlightCount()")
+}
+
+// inFlightCounter is a fake counter item that evaluates the conditions of the in-flight limit.
+type inFlightCounter struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (f *inFlightCounter) update(params *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var delta, bound int
+	for _, v := range params.ExpressionAttributeValues {
+		n, _ := strconv.Atoi(v.(*types.AttributeValueMemberN).Value)
+		if n == 1 || n == -1 {
+			delta = n
+		} else {
+			bound = n
+		}
+	}
+	if (delta > 0 && f.count >= bound) || (delta < 0 && f.count <= 0) {
+		return nil, &types.ConditionalCheckFailedException{}
+	}
+	f.count += delta
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+func TestDynamoMQClientMaxInFlightCounter(t *testing.T) {
+	t.Parallel()
+	counter := &inFlightCounter{}
+	items := make(map[string]map[string]types.AttributeValue)
+	for _, id := range []string{"A-101", "A-102", "A-103"} {
+		items[id] = dynamomqtest.MarshalMap(NewTestMessageItemAsReady(id, test.DefaultTestDate))
+	}
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithMaxInFlight(2),
+		mock.WithClock(mock.Clock{T: test.DefaultTestDate.Add(time.Minute)}),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+				out := &dynamodb.QueryOutput{}
+				for _, id := range []string{"A-101", "A-102", "A-103"} {
+					if item, ok := items[id]; ok {
+						out.Items = append(out.Items, item)
+					}
+				}
+				return out, nil
+			},
+			UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+				id := params.Key[dynamomq.AttributeNameID].(*types.AttributeValueMemberS).Value
+				if _, ok := items[id]; ok {
+					received := dynamomqtest.MarshalMap(NewTestMessageItemAsProcessing(id, test.DefaultTestDate.Add(time.Minute)))
+					received[dynamomq.AttributeNameInFlightSlot] = &types.AttributeValueMemberBOOL{Value: true}
+					delete(items, id)
+					return &dynamodb.UpdateItemOutput{Attributes: received}, nil
+				}
+				return counter.update(params)
+			},
+			DeleteItemFunc: func(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+				if params.ReturnValues != types.ReturnValueAllOld {
+					t.Errorf("DeleteItem() return values = %v, want %v", params.ReturnValues, types.ReturnValueAllOld)
+				}
+				return &dynamodb.DeleteItemOutput{Attributes: map[string]types.AttributeValue{
+					dynamomq.AttributeNameInFlightSlot: &types.AttributeValueMemberBOOL{Value: true},
+				}}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	ctx := context.Background()
+	for _, want := range []string{"A-101", "A-102"} {
+		out, err := client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{})
+		if err != nil {
+			t.Fatalf("ReceiveMessage() error = %v", err)
+		}
+		if out.ReceivedMessage.ID != want {
+			t.Errorf("ReceiveMessage() id = %s, want %s", out.ReceivedMessage.ID, want)
+		}
+	}
+	_, err = client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{})
+	test.AssertError(t, err, dynamomq.InFlightLimitExceededError{Limit: 2}, "ReceiveMessage()")
+	if counter.count != 2 {
+		t.Errorf("counter = %d, want 2", counter.count)
+	}
+	if _, err = client.DeleteMessage(ctx, &dynamomq.DeleteMessageInput{ID: "A-101"}); err != nil {
+		t.Fatalf("DeleteMessage() error = %v", err)
+	}
+	if counter.count != 1 {
+		t.Errorf("counter = %d, want 1", counter.count)
+	}
+	if _, err = client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{}); err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	if counter.count != 2 {
+		t.Errorf("counter = %d, want 2", counter.count)
+	}
+}
+
+func TestDynamoMQClientReconcileInFlightCount(t *testing.T) {
+	t.Parallel()
+	var condition map[string]types.AttributeValue
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+				return &dynamodb.GetItemOutput{Item: map[string]types.AttributeValue{
+					dynamomq.AttributeNameInFlightCount: &types.AttributeValueMemberN{Value: "5"},
+				}}, nil
+			},
+			QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+				if params.Select != types.SelectCount {
+					t.Errorf("Query() select = %v, want %v", params.Select, types.SelectCount)
+				}
+				for _, v := range params.ExpressionAttributeValues {
+					if v.(*types.AttributeValueMemberS).Value == string(dynamomq.QueueTypeStandard) {
+						return &dynamodb.QueryOutput{Count: 2}, nil
+					}
+				}
+				return &dynamodb.QueryOutput{Count: 1}, nil
+			},
+			UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+				condition = params.ExpressionAttributeValues
+				return &dynamodb.UpdateItemOutput{}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	out, err := client.ReconcileInFlightCount(context.Background(), &dynamomq.ReconcileInFlightCountInput{})
+	if err != nil {
+		t.Fatalf("ReconcileInFlightCount() error = %v", err)
+	}
+	test.AssertDeepEqual(t, out, &dynamomq.ReconcileInFlightCountOutput{Previous: 5, Count: 3}, "ReconcileInFlightCount()")
+	test.AssertDeepEqual(t, condition, map[string]types.AttributeValue{
+		":0": &types.AttributeValueMemberN{Value: "5"},
+		":1": &types.AttributeValueMemberN{Value: "3"},
+	}, "UpdateItem() values")
+}
@@ -0,0 +1,104 @@
+package dynamomq_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func newInFlightItem(id string, sentAt, receivedAt time.Time, visibilityTimeout time.Duration) *types.PutRequest {
+	m := NewTestMessageItemAsReady(id, sentAt)
+	dynamomqtest.MarkAsProcessing(m, receivedAt)
+	m.InvisibleUntilAt = clock.FormatRFC3339Nano(receivedAt.Add(visibilityTimeout))
+	m.ReceiveCount = 1
+	m.ConsumerID = testConsumerID
+	return &types.PutRequest{Item: dynamomqtest.MarshalMap(m)}
+}
+
+func TestDynamoMQClientListInFlightMessages(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	client, clean := prepareTestClient(context.Background(), t, NewSetupFunc(
+		newInFlightItem("A-101", test.DefaultTestDate, now, 90*time.Second),
+		newInFlightItem("A-102", test.DefaultTestDate.Add(time.Second), now, 30*time.Second),
+		newPutRequestWithReadyItem("A-103", test.DefaultTestDate.Add(2*time.Second)),
+		newInFlightItem("A-104", test.DefaultTestDate.Add(3*time.Second), now.Add(-time.Minute), 30*time.Second),
+		newInFlightItem("A-105", test.DefaultTestDate.Add(4*time.Second), now, 60*time.Second),
+		newPutRequestWithDLQItem("B-101", test.DefaultTestDate),
+	), mock.Clock{T: now}, false, nil, nil, nil)
+	defer clean()
+	ctx := context.Background()
+	first, err := client.ListInFlightMessages(ctx, &dynamomq.ListInFlightMessagesInput{Limit: 2})
+	if err != nil {
+		t.Fatalf("ListInFlightMessages() error = %v", err)
+	}
+	test.AssertDeepEqual(t, first.Messages, []dynamomq.InFlightMessage{
+		{ID: "A-102", ReceiveCount: 1, ConsumerID: testConsumerID, ReceivedAt: now, VisibleAt: now.Add(30 * time.Second)},
+		{ID: "A-101", ReceiveCount: 1, ConsumerID: testConsumerID, ReceivedAt: now, VisibleAt: now.Add(90 * time.Second)},
+	}, "ListInFlightMessages() first page")
+	if first.NextToken == "" {
+		t.Fatal("ListInFlightMessages() next token is empty")
+	}
+	second, err := client.ListInFlightMessages(ctx, &dynamomq.ListInFlightMessagesInput{Limit: 2, NextToken: first.NextToken})
+	if err != nil {
+		t.Fatalf("ListInFlightMessages() error = %v", err)
+	}
+	test.AssertDeepEqual(t, second, &dynamomq.ListInFlightMessagesOutput{
+		Messages: []dynamomq.InFlightMessage{
+			{ID: "A-105", ReceiveCount: 1, ConsumerID: testConsumerID, ReceivedAt: now, VisibleAt: now.Add(time.Minute)},
+		},
+	}, "ListInFlightMessages() second page")
+}
+
+func TestDynamoMQClientListInFlightMessagesPagination(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	var items []map[string]types.AttributeValue
+	for _, id := range []string{"A-101", "A-102", "A-103"} {
+		items = append(items, newInFlightItem(id, test.DefaultTestDate, now, 30*time.Second).Item)
+	}
+	var startKeys []map[string]types.AttributeValue
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		mock.WithClock(mock.Clock{T: now}),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+				startKeys = append(startKeys, params.ExclusiveStartKey)
+				if params.FilterExpression == nil {
+					t.Error("Query() has no filter expression")
+				}
+				return &dynamodb.QueryOutput{Items: items}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	ctx := context.Background()
+	first, err := client.ListInFlightMessages(ctx, &dynamomq.ListInFlightMessagesInput{Limit: 2})
+	if err != nil {
+		t.Fatalf("ListInFlightMessages() error = %v", err)
+	}
+	if len(first.Messages) != 2 || first.NextToken == "" {
+		t.Fatalf("ListInFlightMessages() = %+v, want two messages and a next token", first)
+	}
+	if _, err := client.ListInFlightMessages(ctx, &dynamomq.ListInFlightMessagesInput{NextToken: first.NextToken}); err != nil {
+		t.Fatalf("ListInFlightMessages() error = %v", err)
+	}
+	test.AssertDeepEqual(t, startKeys[1], map[string]types.AttributeValue{
+		dynamomq.AttributeNameID:        &types.AttributeValueMemberS{Value: "A-102"},
+		dynamomq.AttributeNameQueueType: &types.AttributeValueMemberS{Value: string(dynamomq.QueueTypeStandard)},
+		dynamomq.AttributeNameSentAt:    &types.AttributeValueMemberS{Value: clock.FormatRFC3339Nano(test.DefaultTestDate)},
+	}, "Query() exclusive start key")
+	_, err = client.ListInFlightMessages(ctx, &dynamomq.ListInFlightMessagesInput{NextToken: "not a token"})
+	if _, ok := err.(dynamomq.InvalidNextTokenError); !ok {
+		t.Errorf("ListInFlightMessages() error = %v, want InvalidNextTokenError", err)
+	}
+}
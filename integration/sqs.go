@@ -0,0 +1,311 @@
+// Package integration provides bridges between DynamoMQ and other AWS messaging services.
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/vvatanabe/dynamomq"
+)
+
+const (
+	defaultWaitTimeSeconds   = 20
+	defaultSQSBatchSize      = 10
+	defaultFIFOMessageGroup  = "dynamomq"
+	messageAttributeIDName   = "dynamomq-id"
+	messageAttributeDataType = "String"
+)
+
+// SQSAPI is the subset of the Amazon SQS API used by ImportFromSQS and ExportToSQS.
+// *sqs.Client satisfies this interface.
+type SQSAPI interface {
+	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+}
+
+// SQSMessage is an SQS message as seen by the import.
+// It can also be used as the data type of a queue to keep the body and the attributes of imported messages as they are,
+// see DecodeEnvelope.
+type SQSMessage struct {
+	// MessageID is the ID SQS assigned to the message.
+	MessageID string `json:"message_id" dynamodbav:"message_id"`
+	// Body is the body of the message.
+	Body string `json:"body" dynamodbav:"body"`
+	// Metadata holds the string message attributes and the system attributes of the message.
+	Metadata map[string]string `json:"metadata" dynamodbav:"metadata"`
+}
+
+// DecodeEnvelope is a decoder for ImportFromSQS that keeps the whole SQS message, including its attributes.
+func DecodeEnvelope(msg *SQSMessage) (SQSMessage, error) {
+	return *msg, nil
+}
+
+// Progress reports how far an import or an export has gone.
+type Progress struct {
+	// Received is the number of messages received from the source queue.
+	Received int
+	// Transferred is the number of messages sent to the destination queue and deleted from the source queue.
+	Transferred int
+	// Skipped is the number of messages that already existed in the destination queue and were deleted from the source queue.
+	Skipped int
+}
+
+// TransferOptions contains configuration options shared by ImportFromSQS and ExportToSQS.
+type TransferOptions struct {
+	// MaxMessagesPerSecond limits the throughput of the transfer. Zero means unlimited.
+	MaxMessagesPerSecond float64
+	// MaxMessages stops the transfer after that many messages have been transferred or skipped. Zero means unlimited.
+	MaxMessages int
+	// OnProgress is called after each message that was transferred or skipped.
+	OnProgress func(p Progress)
+}
+
+// ImportOptions contains configuration options for ImportFromSQS.
+type ImportOptions[T any] struct {
+	TransferOptions
+	// Decode converts an SQS message into the data of a DynamoMQ message. By default, the body is unmarshaled as JSON.
+	Decode func(msg *SQSMessage) (T, error)
+	// IDMapper returns the ID of the DynamoMQ message for an SQS message. By default, the producer generates the ID.
+	// Mapping to a stable ID, such as the SQS message ID, makes an interrupted import safe to resume:
+	// messages that were sent but not deleted from SQS are found as duplicates and only deleted.
+	IDMapper func(msg *SQSMessage) string
+	// WaitTimeSeconds is the long polling time of SQS. The import stops when a receive returns no messages.
+	// By default, it is 20 seconds.
+	WaitTimeSeconds int32
+}
+
+// ExportOptions contains configuration options for ExportToSQS.
+type ExportOptions[T any] struct {
+	TransferOptions
+	// Encode converts the data of a DynamoMQ message into the body of an SQS message. By default, it is marshaled as JSON.
+	Encode func(msg *dynamomq.Message[T]) (string, error)
+	// QueueType is the type of DynamoMQ queue to export. By default, it is STANDARD.
+	QueueType dynamomq.QueueType
+	// MessageGroupID is the message group of the messages sent to a FIFO queue. By default, it is "dynamomq".
+	MessageGroupID string
+}
+
+// ImportFromSQS drains the SQS queue at queueURL into DynamoMQ through the producer.
+// Each SQS message is produced and is deleted from SQS only after it was sent successfully, so that an import
+// that stops on an error can be run again without losing messages. It returns when a long poll returns
+// no messages, when MaxMessages is reached, or on the first error, along with the progress made so far.
+func ImportFromSQS[T any](ctx context.Context, api SQSAPI, queueURL string,
+	producer *dynamomq.Producer[T], opts ...func(o *ImportOptions[T])) (Progress, error) {
+	o := &ImportOptions[T]{
+		Decode:          decodeJSON[T],
+		WaitTimeSeconds: defaultWaitTimeSeconds,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	var progress Progress
+	limiter := newLimiter(o.MaxMessagesPerSecond)
+	for !o.done(progress) {
+		out, err := api.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:              aws.String(queueURL),
+			MaxNumberOfMessages:   defaultSQSBatchSize,
+			WaitTimeSeconds:       o.WaitTimeSeconds,
+			MessageAttributeNames: []string{"All"},
+			MessageSystemAttributeNames: []sqstypes.MessageSystemAttributeName{
+				sqstypes.MessageSystemAttributeNameAll,
+			},
+		})
+		if err != nil {
+			return progress, fmt.Errorf("failed to receive messages from SQS: %w", err)
+		}
+		if len(out.Messages) == 0 {
+			return progress, nil
+		}
+		for i := range out.Messages {
+			if o.done(progress) {
+				// The remaining messages become visible again after their visibility timeout.
+				return progress, nil
+			}
+			if err := limiter.wait(ctx); err != nil {
+				return progress, err
+			}
+			progress.Received++
+			skipped, err := importMessage(ctx, api, queueURL, producer, o, &out.Messages[i])
+			if err != nil {
+				return progress, err
+			}
+			if skipped {
+				progress.Skipped++
+			} else {
+				progress.Transferred++
+			}
+			o.progress(progress)
+		}
+	}
+	return progress, nil
+}
+
+func importMessage[T any](ctx context.Context, api SQSAPI, queueURL string,
+	producer *dynamomq.Producer[T], o *ImportOptions[T], m *sqstypes.Message) (skipped bool, err error) {
+	msg := newSQSMessage(m)
+	data, err := o.Decode(msg)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode SQS message %s: %w", msg.MessageID, err)
+	}
+	in := &dynamomq.ProduceInput[T]{Data: data}
+	if o.IDMapper != nil {
+		in.ID = o.IDMapper(msg)
+	}
+	if _, err := producer.Produce(ctx, in); err != nil {
+		if !errors.Is(err, dynamomq.ErrIDDuplicated) {
+			return false, fmt.Errorf("failed to produce SQS message %s: %w", msg.MessageID, err)
+		}
+		skipped = true
+	}
+	if _, err := api.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(queueURL),
+		ReceiptHandle: m.ReceiptHandle,
+	}); err != nil {
+		return skipped, fmt.Errorf("failed to delete SQS message %s: %w", msg.MessageID, err)
+	}
+	return skipped, nil
+}
+
+// ExportToSQS drains the DynamoMQ queue of the client into the SQS queue at queueURL.
+// Each message is deleted from DynamoMQ only after it was sent to SQS successfully. If the export stops on an error,
+// the message being exported becomes visible again after its visibility timeout and is exported by the next run,
+// so a message may be sent to SQS twice; FIFO queues deduplicate it by the DynamoMQ message ID.
+// It returns when the DynamoMQ queue is empty, when MaxMessages is reached, or on the first error,
+// along with the progress made so far.
+func ExportToSQS[T any](ctx context.Context, client dynamomq.Client[T], api SQSAPI, queueURL string,
+	opts ...func(o *ExportOptions[T])) (Progress, error) {
+	o := &ExportOptions[T]{
+		Encode:         encodeJSON[T],
+		QueueType:      dynamomq.QueueTypeStandard,
+		MessageGroupID: defaultFIFOMessageGroup,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	var progress Progress
+	limiter := newLimiter(o.MaxMessagesPerSecond)
+	fifo := strings.HasSuffix(queueURL, ".fifo")
+	for !o.done(progress) {
+		if err := limiter.wait(ctx); err != nil {
+			return progress, err
+		}
+		r, err := client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{QueueType: o.QueueType})
+		if err != nil {
+			if errors.Is(err, dynamomq.ErrEmptyQueue) {
+				return progress, nil
+			}
+			return progress, fmt.Errorf("failed to receive a message from DynamoMQ: %w", err)
+		}
+		if r == nil || r.ReceivedMessage == nil {
+			return progress, nil
+		}
+		progress.Received++
+		msg := r.ReceivedMessage
+		body, err := o.Encode(msg)
+		if err != nil {
+			return progress, fmt.Errorf("failed to encode message %s: %w", msg.ID, err)
+		}
+		in := &sqs.SendMessageInput{
+			QueueUrl:    aws.String(queueURL),
+			MessageBody: aws.String(body),
+			MessageAttributes: map[string]sqstypes.MessageAttributeValue{
+				messageAttributeIDName: {
+					DataType:    aws.String(messageAttributeDataType),
+					StringValue: aws.String(msg.ID),
+				},
+			},
+		}
+		if fifo {
+			in.MessageDeduplicationId = aws.String(msg.ID)
+			in.MessageGroupId = aws.String(o.MessageGroupID)
+		}
+		if _, err := api.SendMessage(ctx, in); err != nil {
+			return progress, fmt.Errorf("failed to send message %s to SQS: %w", msg.ID, err)
+		}
+		if _, err := client.DeleteMessage(ctx, &dynamomq.DeleteMessageInput{ID: msg.ID}); err != nil {
+			return progress, fmt.Errorf("failed to delete message %s: %w", msg.ID, err)
+		}
+		progress.Transferred++
+		o.progress(progress)
+	}
+	return progress, nil
+}
+
+func (o *TransferOptions) done(p Progress) bool {
+	return o.MaxMessages > 0 && p.Transferred+p.Skipped >= o.MaxMessages
+}
+
+func (o *TransferOptions) progress(p Progress) {
+	if o.OnProgress != nil {
+		o.OnProgress(p)
+	}
+}
+
+func newSQSMessage(m *sqstypes.Message) *SQSMessage {
+	msg := &SQSMessage{
+		MessageID: aws.ToString(m.MessageId),
+		Body:      aws.ToString(m.Body),
+		Metadata:  make(map[string]string, len(m.Attributes)+len(m.MessageAttributes)),
+	}
+	for name, value := range m.Attributes {
+		msg.Metadata[name] = value
+	}
+	for name, value := range m.MessageAttributes {
+		if value.StringValue != nil {
+			msg.Metadata[name] = *value.StringValue
+		}
+	}
+	return msg
+}
+
+func decodeJSON[T any](msg *SQSMessage) (T, error) {
+	var data T
+	err := json.Unmarshal([]byte(msg.Body), &data)
+	return data, err
+}
+
+func encodeJSON[T any](msg *dynamomq.Message[T]) (string, error) {
+	body, err := json.Marshal(msg.Data)
+	return string(body), err
+}
+
+// limiter spaces out operations so that at most perSecond of them are started per second.
+type limiter struct {
+	interval time.Duration
+	next     time.Time
+}
+
+func newLimiter(perSecond float64) *limiter {
+	l := &limiter{}
+	if perSecond > 0 {
+		l.interval = time.Duration(float64(time.Second) / perSecond)
+	}
+	return l
+}
+
+func (l *limiter) wait(ctx context.Context) error {
+	if l.interval == 0 {
+		return ctx.Err()
+	}
+	now := time.Now()
+	if l.next.After(now) {
+		timer := time.NewTimer(l.next.Sub(now))
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+		now = l.next
+	}
+	l.next = now.Add(l.interval)
+	return nil
+}
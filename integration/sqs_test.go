@@ -0,0 +1,187 @@
+package integration_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/integration"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+type fakeSQS struct {
+	messages []sqstypes.Message
+	deleted  []string
+	sent     []*sqs.SendMessageInput
+	sendErr  error
+}
+
+func (f *fakeSQS) ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	n := int(params.MaxNumberOfMessages)
+	if n > len(f.messages) {
+		n = len(f.messages)
+	}
+	out := &sqs.ReceiveMessageOutput{Messages: f.messages[:n]}
+	f.messages = f.messages[n:]
+	return out, nil
+}
+
+func (f *fakeSQS) DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
+	f.deleted = append(f.deleted, aws.ToString(params.ReceiptHandle))
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+func (f *fakeSQS) SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	if f.sendErr != nil {
+		return nil, f.sendErr
+	}
+	f.sent = append(f.sent, params)
+	return &sqs.SendMessageOutput{}, nil
+}
+
+func newSQSMessage(id, body string) sqstypes.Message {
+	return sqstypes.Message{
+		MessageId:     aws.String(id),
+		ReceiptHandle: aws.String("rh-" + id),
+		Body:          aws.String(body),
+		Attributes:    map[string]string{"SentTimestamp": "1701388800000"},
+		MessageAttributes: map[string]sqstypes.MessageAttributeValue{
+			"tenant": {DataType: aws.String("String"), StringValue: aws.String("acme")},
+		},
+	}
+}
+
+func TestImportFromSQS(t *testing.T) {
+	t.Parallel()
+	api := &fakeSQS{
+		messages: []sqstypes.Message{
+			newSQSMessage("m-1", "one"),
+			newSQSMessage("m-2", "two"),
+			newSQSMessage("m-3", "three"),
+		},
+	}
+	sent := map[string]integration.SQSMessage{}
+	client := &mock.Client[integration.SQSMessage]{
+		SendMessageFunc: func(ctx context.Context, params *dynamomq.SendMessageInput[integration.SQSMessage]) (*dynamomq.SendMessageOutput[integration.SQSMessage], error) {
+			if params.ID == "m-2" {
+				// m-2 was sent by an earlier run that stopped before deleting it from SQS.
+				return nil, &dynamomq.IDDuplicatedError{}
+			}
+			sent[params.ID] = params.Data
+			return &dynamomq.SendMessageOutput[integration.SQSMessage]{
+				SentMessage: &dynamomq.Message[integration.SQSMessage]{ID: params.ID, Data: params.Data},
+			}, nil
+		},
+	}
+	var reports []integration.Progress
+	progress, err := integration.ImportFromSQS(context.Background(), api, "https://sqs/queue",
+		dynamomq.NewProducer[integration.SQSMessage](client),
+		func(o *integration.ImportOptions[integration.SQSMessage]) {
+			o.Decode = integration.DecodeEnvelope
+			o.IDMapper = func(msg *integration.SQSMessage) string { return msg.MessageID }
+			o.OnProgress = func(p integration.Progress) { reports = append(reports, p) }
+		})
+	if err != nil {
+		t.Fatalf("ImportFromSQS() error = %v", err)
+	}
+	test.AssertDeepEqual(t, progress, integration.Progress{Received: 3, Transferred: 2, Skipped: 1}, "ImportFromSQS()")
+	test.AssertDeepEqual(t, api.deleted, []string{"rh-m-1", "rh-m-2", "rh-m-3"}, "deleted")
+	if len(reports) != 3 {
+		t.Errorf("OnProgress() calls = %d, want 3", len(reports))
+	}
+	want := integration.SQSMessage{
+		MessageID: "m-1",
+		Body:      "one",
+		Metadata:  map[string]string{"SentTimestamp": "1701388800000", "tenant": "acme"},
+	}
+	test.AssertDeepEqual(t, sent["m-1"], want, "imported message")
+}
+
+func TestImportFromSQSKeepsMessageOnError(t *testing.T) {
+	t.Parallel()
+	api := &fakeSQS{
+		messages: []sqstypes.Message{newSQSMessage("m-1", `{"id":"A-101"}`)},
+	}
+	client := &mock.Client[test.MessageData]{
+		SendMessageFunc: func(ctx context.Context, params *dynamomq.SendMessageInput[test.MessageData]) (*dynamomq.SendMessageOutput[test.MessageData], error) {
+			return nil, test.ErrTest
+		},
+	}
+	progress, err := integration.ImportFromSQS(context.Background(), api, "https://sqs/queue",
+		dynamomq.NewProducer[test.MessageData](client))
+	if !errors.Is(err, test.ErrTest) {
+		t.Errorf("ImportFromSQS() error = %v, want %v", err, test.ErrTest)
+	}
+	test.AssertDeepEqual(t, progress, integration.Progress{Received: 1}, "ImportFromSQS()")
+	if len(api.deleted) != 0 {
+		t.Errorf("deleted = %v, want none", api.deleted)
+	}
+}
+
+func TestExportToSQS(t *testing.T) {
+	t.Parallel()
+	queue := []*dynamomq.Message[test.MessageData]{
+		dynamomq.NewMessage("A-101", test.NewMessageData("A-101"), test.DefaultTestDate),
+		dynamomq.NewMessage("A-102", test.NewMessageData("A-102"), test.DefaultTestDate),
+	}
+	var deleted []string
+	client := &mock.Client[test.MessageData]{
+		ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+			if len(queue) == 0 {
+				return nil, &dynamomq.EmptyQueueError{}
+			}
+			msg := queue[0]
+			queue = queue[1:]
+			return &dynamomq.ReceiveMessageOutput[test.MessageData]{ReceivedMessage: msg}, nil
+		},
+		DeleteMessageFunc: func(ctx context.Context, params *dynamomq.DeleteMessageInput) (*dynamomq.DeleteMessageOutput, error) {
+			deleted = append(deleted, params.ID)
+			return &dynamomq.DeleteMessageOutput{}, nil
+		},
+	}
+	api := &fakeSQS{}
+	progress, err := integration.ExportToSQS[test.MessageData](context.Background(), client, api, "https://sqs/queue.fifo",
+		func(o *integration.ExportOptions[test.MessageData]) {
+			o.MaxMessagesPerSecond = 1000
+		})
+	if err != nil {
+		t.Fatalf("ExportToSQS() error = %v", err)
+	}
+	test.AssertDeepEqual(t, progress, integration.Progress{Received: 2, Transferred: 2}, "ExportToSQS()")
+	test.AssertDeepEqual(t, deleted, []string{"A-101", "A-102"}, "deleted")
+	if len(api.sent) != 2 {
+		t.Fatalf("SendMessage() calls = %d, want 2", len(api.sent))
+	}
+	if got := aws.ToString(api.sent[0].MessageDeduplicationId); got != "A-101" {
+		t.Errorf("MessageDeduplicationId = %v, want A-101", got)
+	}
+	if got := aws.ToString(api.sent[0].MessageAttributes["dynamomq-id"].StringValue); got != "A-101" {
+		t.Errorf("dynamomq-id attribute = %v, want A-101", got)
+	}
+}
+
+func TestExportToSQSKeepsMessageOnError(t *testing.T) {
+	t.Parallel()
+	client := &mock.Client[test.MessageData]{
+		ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+			return &dynamomq.ReceiveMessageOutput[test.MessageData]{
+				ReceivedMessage: dynamomq.NewMessage("A-101", test.NewMessageData("A-101"), test.DefaultTestDate),
+			}, nil
+		},
+		DeleteMessageFunc: func(ctx context.Context, params *dynamomq.DeleteMessageInput) (*dynamomq.DeleteMessageOutput, error) {
+			t.Error("DeleteMessage() was called for a message that was not sent")
+			return &dynamomq.DeleteMessageOutput{}, nil
+		},
+	}
+	progress, err := integration.ExportToSQS[test.MessageData](context.Background(), client,
+		&fakeSQS{sendErr: test.ErrTest}, "https://sqs/queue")
+	if !errors.Is(err, test.ErrTest) {
+		t.Errorf("ExportToSQS() error = %v, want %v", err, test.ErrTest)
+	}
+	test.AssertDeepEqual(t, progress, integration.Progress{Received: 1}, "ExportToSQS()")
+}
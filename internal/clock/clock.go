@@ -20,6 +20,10 @@ func RFC3339NanoToTime(rfc3339NanoDate string) time.Time {
 	return t
 }
 
+func ParseRFC3339Nano(rfc3339NanoDate string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, rfc3339NanoDate)
+}
+
 type Clock interface {
 	Now() time.Time
 }
@@ -42,3 +42,17 @@ func TestRFC3339NanoToUnixMilli(t *testing.T) {
 		t.Errorf("RFC3339NanoToUnixMilli() did not convert to Unix milliseconds correctly")
 	}
 }
+
+func TestParseRFC3339Nano(t *testing.T) {
+	now := time.Now().UTC()
+	parsed, err := clock.ParseRFC3339Nano(clock.FormatRFC3339Nano(now))
+	if err != nil {
+		t.Errorf("ParseRFC3339Nano() error = %v", err)
+	}
+	if !parsed.Equal(now) {
+		t.Errorf("ParseRFC3339Nano() = %v, want %v", parsed, now)
+	}
+	if _, err := clock.ParseRFC3339Nano("2023-12-01 00:00:00"); err == nil {
+		t.Errorf("ParseRFC3339Nano() did not return an error for a malformed date")
+	}
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vvatanabe/dynamomq"
+)
+
+// completionListSize is the number of messages listed to complete a message ID, kept small
+// so that pressing tab costs a single small read of the table.
+const completionListSize = 20
+
+// completeMessageIDs completes the message ID arguments of a command taking up to maxArgs of them,
+// or any number of them if maxArgs is zero, with the IDs of the messages in the queue.
+func (f CommandFactory) completeMessageIDs(flgs *Flags, maxArgs int) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if maxArgs > 0 && len(args) >= maxArgs {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return f.listMessageIDs(flgs, toComplete, args)
+	}
+}
+
+// completeIDFlag completes the value of --id with the IDs of the messages in the queue.
+func (f CommandFactory) completeIDFlag(flgs *Flags) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return f.listMessageIDs(flgs, toComplete, args)
+	}
+}
+
+// listMessageIDs lists the IDs of the messages in the queue that start with prefix, other than the ones in exclude.
+func (f CommandFactory) listMessageIDs(flgs *Flags, prefix string, exclude []string) ([]string, cobra.ShellCompDirective) {
+	ctx := context.Background()
+	client, _, err := f.CreateDynamoMQClient(ctx, flgs)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	out, err := client.ListMessages(ctx, &dynamomq.ListMessagesInput{Size: completionListSize, OmitData: true})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	ids := make([]string, 0, len(out.Messages))
+	for _, m := range out.Messages {
+		if strings.HasPrefix(m.ID, prefix) && !slices.Contains(exclude, m.ID) && !slices.Contains(ids, m.ID) {
+			ids = append(ids, m.ID)
+		}
+	}
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
+
+// addIDFlag adds --id to the command, completed with the IDs of the messages in the queue.
+func addIDFlag(c *cobra.Command, flgs *Flags) {
+	c.Flags().StringVar(&flgs.ID, flagMap.ID.Name, flagMap.ID.Value, flagMap.ID.Usage)
+	_ = c.RegisterFlagCompletionFunc(flagMap.ID.Name, defaultCommandFactory.completeIDFlag(flgs))
+}
+
+// addOutputFlag adds --output to the command, completed with the output formats.
+func addOutputFlag(c *cobra.Command, flgs *Flags) {
+	c.Flags().StringVar(&flgs.Output, flagMap.Output.Name, flagMap.Output.Value, flagMap.Output.Usage)
+	_ = c.RegisterFlagCompletionFunc(flagMap.Output.Name,
+		cobra.FixedCompletions([]string{outputText, outputJSON}, cobra.ShellCompDirectiveNoFileComp))
+}
@@ -0,0 +1,106 @@
+package cmd_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/spf13/cobra"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/cmd"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+// newCompletionClient returns a client listing the messages of ids, which records the inputs of the listings
+// into lists, or which fails to list them with listErr.
+func newCompletionClient(lists *[]*dynamomq.ListMessagesInput, listErr error, ids ...string) cmd.CommandFactory {
+	return cmd.CommandFactory{
+		CreateDynamoMQClient: func(ctx context.Context, flags *cmd.Flags) (dynamomq.Client[any], aws.Config, error) {
+			return mock.Client[any]{
+				ListMessagesFunc: func(ctx context.Context, params *dynamomq.ListMessagesInput) (*dynamomq.ListMessagesOutput[any], error) {
+					*lists = append(*lists, params)
+					if listErr != nil {
+						return nil, listErr
+					}
+					out := &dynamomq.ListMessagesOutput[any]{DataOmitted: true}
+					for _, id := range ids {
+						out.Messages = append(out.Messages, dynamomq.NewMessage[any](id, nil, test.DefaultTestDate))
+					}
+					return out, nil
+				},
+			}, aws.Config{}, nil
+		},
+	}
+}
+
+func TestCompleteMessageIDs(t *testing.T) {
+	ids := []string{"A-101", "A-202", "B-101"}
+	tests := []struct {
+		name          string
+		create        func(f cmd.CommandFactory) *cobra.Command
+		args          []string
+		toComplete    string
+		listErr       error
+		want          []string
+		wantDirective cobra.ShellCompDirective
+		noList        bool
+	}{
+		{
+			name:          "should complete the IDs starting with the prefix",
+			create:        func(f cmd.CommandFactory) *cobra.Command { return f.CreateHoldCommand(&cmd.Flags{}) },
+			toComplete:    "A-",
+			want:          []string{"A-101", "A-202"},
+			wantDirective: cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			name:          "should complete every ID without a prefix",
+			create:        func(f cmd.CommandFactory) *cobra.Command { return f.CreateRedriveCommand(&cmd.Flags{}) },
+			want:          ids,
+			wantDirective: cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			name:          "should not complete the IDs already given to a command taking several",
+			create:        func(f cmd.CommandFactory) *cobra.Command { return f.CreateGetCommand(&cmd.Flags{}) },
+			args:          []string{"A-101"},
+			want:          []string{"A-202", "B-101"},
+			wantDirective: cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			name:          "should not complete a second ID of a command taking one",
+			create:        func(f cmd.CommandFactory) *cobra.Command { return f.CreateEditCommand(&cmd.Flags{}) },
+			args:          []string{"A-101"},
+			wantDirective: cobra.ShellCompDirectiveNoFileComp,
+			noList:        true,
+		},
+		{
+			name:          "should report an error when the messages cannot be listed",
+			create:        func(f cmd.CommandFactory) *cobra.Command { return f.CreateReleaseCommand(&cmd.Flags{}) },
+			listErr:       test.ErrTest,
+			wantDirective: cobra.ShellCompDirectiveError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lists []*dynamomq.ListMessagesInput
+			c := tt.create(newCompletionClient(&lists, tt.listErr, ids...))
+			got, directive := c.ValidArgsFunction(c, tt.args, tt.toComplete)
+			test.AssertDeepEqual(t, got, tt.want, "ValidArgsFunction()")
+			if directive != tt.wantDirective {
+				t.Errorf("ValidArgsFunction() directive = %v, want %v", directive, tt.wantDirective)
+			}
+			if tt.noList {
+				if len(lists) != 0 {
+					t.Errorf("ListMessages() calls = %d, want none", len(lists))
+				}
+				return
+			}
+			if len(lists) != 1 {
+				t.Fatalf("ListMessages() calls = %d, want 1", len(lists))
+			}
+			if lists[0].Size <= 0 || lists[0].Size > 100 || !lists[0].OmitData {
+				t.Errorf("ListMessages() input = %+v, want a small listing without the data", lists[0])
+			}
+		})
+	}
+}
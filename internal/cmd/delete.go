@@ -32,6 +32,6 @@ func (f CommandFactory) CreateDeleteCommand(flgs *Flags) *cobra.Command {
 func init() {
 	c := defaultCommandFactory.CreateDeleteCommand(flgs)
 	setDefaultFlags(c, flgs)
-	c.Flags().StringVar(&flgs.ID, flagMap.ID.Name, flagMap.ID.Value, flagMap.ID.Usage)
+	addIDFlag(c, flgs)
 	root.AddCommand(c)
 }
@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/spf13/cobra"
+	"github.com/vvatanabe/dynamomq"
+)
+
+func (f CommandFactory) CreateEditCommand(flgs *Flags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit [id]",
+		Short: "Edit the payload of a message in place",
+		Long: `Edit the payload of a message in place.
+The payload is opened as JSON in $VISUAL or $EDITOR, unless it is given with --data-file or changed with --set.
+The message is written back only if it has not been changed in the meantime; otherwise the changes made
+to its payload since it was read are printed, and nothing is written.`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: f.completeMessageIDs(flgs, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			client, _, err := f.CreateDynamoMQClient(ctx, flgs)
+			if err != nil {
+				return err
+			}
+			id := flgs.ID
+			if len(args) == 1 {
+				id = args[0]
+			}
+			return f.editMessage(ctx, client, flgs, id)
+		},
+	}
+}
+
+func (f CommandFactory) editMessage(ctx context.Context, client dynamomq.Client[any], flgs *Flags, id string) error {
+	retrieved, err := client.GetMessage(ctx, &dynamomq.GetMessageInput{
+		ID: id,
+	})
+	if err != nil {
+		return err
+	}
+	if retrieved.Message == nil {
+		return errorWithID(&dynamomq.IDNotFoundError{}, id)
+	}
+	message := retrieved.Message
+	original, err := marshalIndent(message.Data)
+	if err != nil {
+		return err
+	}
+	var edited []byte
+	switch {
+	case flgs.DataFile != "":
+		edited, err = f.readDataFile(flgs.DataFile)
+	case len(flgs.Set) == 0:
+		edited, err = editInEditor(id, original)
+	default:
+		edited = original
+	}
+	if err != nil {
+		return err
+	}
+	data, err := decodePayload(edited)
+	if err != nil {
+		return err
+	}
+	if len(flgs.Set) > 0 {
+		if data, err = setPayloadFields(data, flgs.Set); err != nil {
+			return err
+		}
+	}
+	if samePayload(message.Data, data) {
+		fmt.Printf("Message '%s' is unchanged.\n", id)
+		return nil
+	}
+	updated, err := client.UpdateMessageData(ctx, &dynamomq.UpdateMessageDataInput[any]{
+		ID:              id,
+		Data:            toAttributeValues(data),
+		ExpectedVersion: message.Version,
+	})
+	var conflict dynamomq.VersionConflictError
+	if errors.As(err, &conflict) {
+		return conflictWithDiff(ctx, client, conflict, original)
+	}
+	if err != nil {
+		return err
+	}
+	version := message.Version + 1
+	if updated.UpdatedMessage != nil {
+		version = updated.UpdatedMessage.Version
+	}
+	fmt.Printf("Message '%s' is updated to version %d.\n", id, version)
+	return nil
+}
+
+// readDataFile reads the payload from the file, or from the standard input if the path is "-".
+func (f CommandFactory) readDataFile(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(f.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// editInEditor opens the payload in the editor of the user and returns it once the editor exits.
+// The file is kept when the edited payload cannot be read back, so that the changes are not lost.
+func editInEditor(id string, payload []byte) ([]byte, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	file, err := os.CreateTemp("", "dynamomq-edit-*.json")
+	if err != nil {
+		return nil, err
+	}
+	path := file.Name()
+	_, err = file.Write(append(payload, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	fields := strings.Fields(editor)
+	c := exec.Command(fields[0], append(fields[1:], path)...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("editor %s failed, message '%s' is not updated: %w", editor, id, err)
+	}
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(edited) {
+		return nil, fmt.Errorf("the edited payload is not valid JSON, message '%s' is not updated; it is kept in %s", id, path)
+	}
+	os.Remove(path)
+	return edited, nil
+}
+
+// decodePayload decodes a JSON payload, keeping its numbers as they are written.
+func decodePayload(payload []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var data any
+	if err := decoder.Decode(&data); err != nil {
+		return nil, fmt.Errorf("the payload is not valid JSON: %w", err)
+	}
+	if decoder.More() {
+		return nil, errors.New("the payload is not valid JSON: more than one value")
+	}
+	return data, nil
+}
+
+// setPayloadFields sets the fields of the payload given as key=value, where the key is a path of fields
+// separated by dots, and the value is JSON, or a string if it is not valid JSON.
+func setPayloadFields(data any, fields []string) (any, error) {
+	if data == nil {
+		data = map[string]any{}
+	}
+	for _, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --set %q, want key=value", field)
+		}
+		v, err := decodePayload([]byte(value))
+		if err != nil {
+			v = value
+		}
+		object, ok := data.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("cannot set %q: the payload is not a JSON object", key)
+		}
+		path := strings.Split(key, ".")
+		for _, name := range path[:len(path)-1] {
+			child, ok := object[name].(map[string]any)
+			if !ok {
+				if object[name] != nil {
+					return nil, fmt.Errorf("cannot set %q: %q is not a JSON object", key, name)
+				}
+				child = map[string]any{}
+				object[name] = child
+			}
+			object = child
+		}
+		object[path[len(path)-1]] = v
+	}
+	return data, nil
+}
+
+// samePayload reports whether the payload read from the table and the edited one have the same JSON form.
+func samePayload(stored, edited any) bool {
+	a, err := json.Marshal(stored)
+	if err != nil {
+		return false
+	}
+	b, err := json.Marshal(edited)
+	if err != nil {
+		return false
+	}
+	normalized, err := decodePayload(a)
+	if err != nil {
+		return false
+	}
+	a, err = json.Marshal(normalized)
+	return err == nil && bytes.Equal(a, b)
+}
+
+// toAttributeValues replaces the JSON numbers of the payload with attributevalue numbers,
+// so that they are stored as DynamoDB numbers without losing precision.
+func toAttributeValues(data any) any {
+	switch v := data.(type) {
+	case json.Number:
+		return attributevalue.Number(v)
+	case map[string]any:
+		for k, e := range v {
+			v[k] = toAttributeValues(e)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = toAttributeValues(e)
+		}
+	}
+	return data
+}
+
+// conflictWithDiff explains a version conflict with the changes made to the payload since it was read.
+func conflictWithDiff(ctx context.Context, client dynamomq.Client[any], conflict dynamomq.VersionConflictError,
+	original []byte) error {
+	hint := "Run edit again to apply your changes to the current message."
+	retrieved, err := client.GetMessage(ctx, &dynamomq.GetMessageInput{
+		ID: conflict.ID,
+	})
+	if err != nil || retrieved.Message == nil {
+		return fmt.Errorf("%w The message was changed or deleted while it was edited, and is not updated. %s", conflict, hint)
+	}
+	current, err := marshalIndent(retrieved.Message.Data)
+	if err != nil {
+		return err
+	}
+	diff := diffLines(string(original), string(current))
+	if diff == "" {
+		return fmt.Errorf("%w It is now at version %d; its state changed while it was edited, but not its payload, and it is not updated. %s",
+			conflict, retrieved.Message.Version, hint)
+	}
+	return fmt.Errorf("%w It is now at version %d, and is not updated. Its payload was changed while it was edited:\n%s%s",
+		conflict, retrieved.Message.Version, diff, hint)
+}
+
+// diffLines returns the lines removed from a, prefixed with "-", and added to b, prefixed with "+",
+// between the unchanged lines, prefixed with a space, or an empty string if a and b are the same.
+func diffLines(a, b string) string {
+	if a == b {
+		return ""
+	}
+	x, y := strings.Split(a, "\n"), strings.Split(b, "\n")
+	// lcs[i][j] is the length of the longest common subsequence of x[i:] and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var sb strings.Builder
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			fmt.Fprintf(&sb, "  %s\n", x[i])
+			i++
+			j++
+		case j < len(y) && (i == len(x) || lcs[i][j+1] >= lcs[i+1][j]):
+			fmt.Fprintf(&sb, "+ %s\n", y[j])
+			j++
+		default:
+			fmt.Fprintf(&sb, "- %s\n", x[i])
+			i++
+		}
+	}
+	return sb.String()
+}
+
+func init() {
+	c := defaultCommandFactory.CreateEditCommand(flgs)
+	setDefaultFlags(c, flgs)
+	addIDFlag(c, flgs)
+	c.Flags().StringVar(&flgs.DataFile, flagMap.DataFile.Name, flagMap.DataFile.Value, flagMap.DataFile.Usage)
+	c.Flags().StringArrayVar(&flgs.Set, flagMap.Set.Name, flagMap.Set.Value, flagMap.Set.Usage)
+	root.AddCommand(c)
+}
@@ -0,0 +1,249 @@
+package cmd_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/spf13/cobra"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/cmd"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+// newEditedMessage returns a message at version 2 as the CLI reads it from the table.
+func newEditedMessage(data any) *dynamomq.Message[any] {
+	message := dynamomq.NewMessage[any]("A-101", data, test.DefaultTestDate)
+	message.Version = 2
+	return message
+}
+
+func newStoredPayload() map[string]any {
+	return map[string]any{"status": "failed", "count": float64(1)}
+}
+
+// newEditClient returns a client reading the messages in turn, the last one repeatedly, which records the updates
+// of the payload into updates and fails them with updateErr.
+func newEditClient(updates *[]*dynamomq.UpdateMessageDataInput[any], updateErr error, messages ...*dynamomq.Message[any]) cmd.CommandFactory {
+	return cmd.CommandFactory{
+		CreateDynamoMQClient: func(ctx context.Context, flags *cmd.Flags) (dynamomq.Client[any], aws.Config, error) {
+			return mock.Client[any]{
+				GetMessageFunc: func(ctx context.Context, params *dynamomq.GetMessageInput) (*dynamomq.GetMessageOutput[any], error) {
+					message := messages[0]
+					if len(messages) > 1 {
+						messages = messages[1:]
+					}
+					return &dynamomq.GetMessageOutput[any]{Message: message}, nil
+				},
+				UpdateMessageDataFunc: func(ctx context.Context, params *dynamomq.UpdateMessageDataInput[any]) (*dynamomq.UpdateMessageDataOutput[any], error) {
+					*updates = append(*updates, params)
+					if updateErr != nil {
+						return nil, updateErr
+					}
+					updated := newEditedMessage(params.Data)
+					updated.Version = params.ExpectedVersion + 1
+					return &dynamomq.UpdateMessageDataOutput[any]{UpdatedMessage: updated}, nil
+				},
+			}, aws.Config{}, nil
+		},
+		Stdin: strings.NewReader(`{"status": "retried"}`),
+	}
+}
+
+func writeDataFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "data.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCommandFactoryCreateEditCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		flgs     *cmd.Flags
+		args     []string
+		stored   any
+		want     any
+		wantErr  bool
+		noUpdate bool
+	}{
+		{
+			name:   "should set fields of the payload",
+			flgs:   &cmd.Flags{Set: []string{"status=fixed", "count=2", "retry.at=\"now\""}},
+			args:   []string{"A-101"},
+			stored: newStoredPayload(),
+			want: map[string]any{
+				"status": "fixed",
+				"count":  attributevalue.Number("2"),
+				"retry":  map[string]any{"at": "now"},
+			},
+		},
+		{
+			name:   "should replace the payload with the data file",
+			flgs:   &cmd.Flags{ID: "A-101", DataFile: writeDataFile(t, `{"status": "fixed", "amount": 12345678901234567890}`)},
+			stored: newStoredPayload(),
+			want:   map[string]any{"status": "fixed", "amount": attributevalue.Number("12345678901234567890")},
+		},
+		{
+			name:   "should read the data file from the standard input",
+			flgs:   &cmd.Flags{ID: "A-101", DataFile: "-", Set: []string{"count=3"}},
+			stored: newStoredPayload(),
+			want:   map[string]any{"status": "retried", "count": attributevalue.Number("3")},
+		},
+		{
+			name:     "should not update an unchanged payload",
+			flgs:     &cmd.Flags{ID: "A-101", Set: []string{"status=failed", "count=1"}},
+			stored:   newStoredPayload(),
+			noUpdate: true,
+		},
+		{
+			name:     "should reject a data file that is not JSON",
+			flgs:     &cmd.Flags{ID: "A-101", DataFile: writeDataFile(t, `{"status": `)},
+			stored:   newStoredPayload(),
+			wantErr:  true,
+			noUpdate: true,
+		},
+		{
+			name:     "should reject a field set on a payload that is not an object",
+			flgs:     &cmd.Flags{ID: "A-101", Set: []string{"status=fixed"}},
+			stored:   []any{"failed"},
+			wantErr:  true,
+			noUpdate: true,
+		},
+		{
+			name:     "should reject a field without a value",
+			flgs:     &cmd.Flags{ID: "A-101", Set: []string{"status"}},
+			stored:   newStoredPayload(),
+			wantErr:  true,
+			noUpdate: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updates []*dynamomq.UpdateMessageDataInput[any]
+			f := newEditClient(&updates, nil, newEditedMessage(tt.stored))
+			err := f.CreateEditCommand(tt.flgs).RunE(&cobra.Command{}, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RunE() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.noUpdate {
+				if len(updates) != 0 {
+					t.Errorf("UpdateMessageData() calls = %d, want none", len(updates))
+				}
+				return
+			}
+			if len(updates) != 1 {
+				t.Fatalf("UpdateMessageData() calls = %d, want 1", len(updates))
+			}
+			test.AssertDeepEqual(t, updates[0], &dynamomq.UpdateMessageDataInput[any]{
+				ID:              "A-101",
+				Data:            tt.want,
+				ExpectedVersion: 2,
+			}, "UpdateMessageData()")
+		})
+	}
+}
+
+func TestCommandFactoryCreateEditCommandShouldReportConflict(t *testing.T) {
+	var updates []*dynamomq.UpdateMessageDataInput[any]
+	current := newEditedMessage(map[string]any{"status": "processed", "count": float64(1)})
+	current.Version = 3
+	f := newEditClient(&updates, dynamomq.VersionConflictError{ID: "A-101", Version: 2},
+		newEditedMessage(newStoredPayload()), current)
+	err := f.CreateEditCommand(&cmd.Flags{ID: "A-101", Set: []string{"status=fixed"}}).RunE(&cobra.Command{}, nil)
+	if !errors.As(err, &dynamomq.VersionConflictError{}) {
+		t.Fatalf("RunE() error = %v, want a VersionConflictError", err)
+	}
+	for _, want := range []string{"version 3", `-   "status": "failed"`, `+   "status": "processed"`, `    "count": 1`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("RunE() error = %v, want it to contain %q", err, want)
+		}
+	}
+	if len(updates) != 1 {
+		t.Errorf("UpdateMessageData() calls = %d, want 1", len(updates))
+	}
+}
+
+func TestCommandFactoryCreateEditCommandShouldReturnNotFound(t *testing.T) {
+	var updates []*dynamomq.UpdateMessageDataInput[any]
+	f := newEditClient(&updates, nil, nil)
+	err := f.CreateEditCommand(&cmd.Flags{ID: "A-101", Set: []string{"status=fixed"}}).RunE(&cobra.Command{}, nil)
+	test.AssertError(t, err, dynamomq.ErrIDNotFound, "RunE()")
+}
+
+// writeStubEditor writes an editor that replaces the file it is given with payload, or fails if payload is empty.
+func writeStubEditor(t *testing.T, payload string) string {
+	t.Helper()
+	script := "#!/bin/sh\nexit 1\n"
+	if payload != "" {
+		script = "#!/bin/sh\ncat > \"$1\" <<'EOF'\n" + payload + "\nEOF\n"
+	}
+	path := filepath.Join(t.TempDir(), "editor.sh")
+	if err := os.WriteFile(path, []byte(script), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCommandFactoryCreateEditCommandWithEditor(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		want     any
+		wantErr  bool
+		noUpdate bool
+	}{
+		{
+			name:    "should update the payload edited in the editor",
+			payload: `{"status": "fixed", "count": 1}`,
+			want:    map[string]any{"status": "fixed", "count": attributevalue.Number("1")},
+		},
+		{
+			name:     "should not update a payload left unchanged in the editor",
+			payload:  `{"count": 1, "status": "failed"}`,
+			noUpdate: true,
+		},
+		{
+			name:     "should not update the payload when the editor fails",
+			wantErr:  true,
+			noUpdate: true,
+		},
+		{
+			name:     "should not update a payload edited into invalid JSON",
+			payload:  `{"status": fixed}`,
+			wantErr:  true,
+			noUpdate: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TMPDIR", t.TempDir())
+			t.Setenv("VISUAL", "")
+			t.Setenv("EDITOR", writeStubEditor(t, tt.payload))
+			var updates []*dynamomq.UpdateMessageDataInput[any]
+			f := newEditClient(&updates, nil, newEditedMessage(newStoredPayload()))
+			err := f.CreateEditCommand(&cmd.Flags{}).RunE(&cobra.Command{}, []string{"A-101"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RunE() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.noUpdate {
+				if len(updates) != 0 {
+					t.Errorf("UpdateMessageData() calls = %d, want none", len(updates))
+				}
+				return
+			}
+			if len(updates) != 1 {
+				t.Fatalf("UpdateMessageData() calls = %d, want 1", len(updates))
+			}
+			test.AssertDeepEqual(t, updates[0].Data, tt.want, "UpdateMessageData() data")
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Keys read by the LineEditor from a terminal in raw mode.
+const (
+	keyCtrlA     = 1
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlU     = 21
+	keyBackspace = 8
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// LineEditor reads the lines typed in the Interactive mode from a terminal in raw mode, with the editing keys
+// of readline: the left and right arrows, Home and End or Ctrl-A and Ctrl-E move the cursor, Backspace and Delete
+// remove a character, Ctrl-U clears the line, and the up and down arrows walk the history.
+// Ctrl-C discards the line, and Ctrl-D on an empty line ends the input.
+type LineEditor struct {
+	in      *bufio.Reader
+	out     io.Writer
+	history *History
+}
+
+// NewLineEditor returns a LineEditor reading the keys from in, echoing the line to out,
+// and recalling the lines of history, if it is not nil.
+func NewLineEditor(in io.Reader, out io.Writer, history *History) *LineEditor {
+	return &LineEditor{in: bufio.NewReader(in), out: out, history: history}
+}
+
+// ReadLine prints the prompt and returns the line typed after it, or io.EOF when the input ends.
+func (e *LineEditor) ReadLine(prompt string) (string, error) {
+	var entries []string
+	if e.history != nil {
+		entries = e.history.Entries()
+	}
+	var (
+		line []rune
+		pos  int
+		// recalled is the index of the history entry shown, or len(entries) for the line being typed, kept in draft.
+		recalled = len(entries)
+		draft    []rune
+	)
+	show := func(l []rune) {
+		line = append([]rune(nil), l...)
+		pos = len(line)
+	}
+	fmt.Fprint(e.out, prompt)
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			if err == io.EOF && len(line) > 0 {
+				break
+			}
+			return "", err
+		}
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\r\n")
+			return string(line), nil
+		case keyCtrlC:
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", nil
+		case keyCtrlD:
+			if len(line) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			if pos < len(line) {
+				line = append(line[:pos], line[pos+1:]...)
+			}
+		case keyCtrlA:
+			pos = 0
+		case keyCtrlE:
+			pos = len(line)
+		case keyCtrlU:
+			line, pos = line[:0], 0
+		case keyBackspace, keyDelete:
+			if pos > 0 {
+				line = append(line[:pos-1], line[pos:]...)
+				pos--
+			}
+		case keyEscape:
+			switch e.readEscape() {
+			case 'A':
+				if recalled > 0 {
+					if recalled == len(entries) {
+						draft = append([]rune(nil), line...)
+					}
+					recalled--
+					show([]rune(entries[recalled]))
+				}
+			case 'B':
+				if recalled < len(entries) {
+					recalled++
+					if recalled == len(entries) {
+						show(draft)
+					} else {
+						show([]rune(entries[recalled]))
+					}
+				}
+			case 'C':
+				if pos < len(line) {
+					pos++
+				}
+			case 'D':
+				if pos > 0 {
+					pos--
+				}
+			case 'H':
+				pos = 0
+			case 'F':
+				pos = len(line)
+			case '~':
+				if pos < len(line) {
+					line = append(line[:pos], line[pos+1:]...)
+				}
+			}
+		default:
+			if unicode.IsPrint(r) || r == '\t' {
+				line = append(line[:pos], append([]rune{r}, line[pos:]...)...)
+				pos++
+			}
+		}
+		e.refresh(prompt, line, pos)
+	}
+	fmt.Fprint(e.out, "\r\n")
+	return string(line), nil
+}
+
+// readEscape reads the rest of an escape sequence and returns its final character: A, B, C and D for the arrows,
+// H and F for Home and End, and ~ for Delete. Any other sequence is returned as 0 and ignored.
+func (e *LineEditor) readEscape() rune {
+	r, _, err := e.in.ReadRune()
+	if err != nil || (r != '[' && r != 'O') {
+		return 0
+	}
+	r, _, err = e.in.ReadRune()
+	if err != nil {
+		return 0
+	}
+	switch r {
+	case 'A', 'B', 'C', 'D', 'H', 'F':
+		return r
+	}
+	// The sequences ending with ~ carry a number, such as 3 for Delete, 1 and 7 for Home, and 4 and 8 for End.
+	if next, _, err := e.in.ReadRune(); err != nil || next != '~' {
+		return 0
+	}
+	switch r {
+	case '3':
+		return '~'
+	case '1', '7':
+		return 'H'
+	case '4', '8':
+		return 'F'
+	}
+	return 0
+}
+
+// refresh redraws the line after the prompt and puts the cursor back at pos.
+func (e *LineEditor) refresh(prompt string, line []rune, pos int) {
+	var sb strings.Builder
+	sb.WriteString("\r")
+	sb.WriteString(prompt)
+	sb.WriteString(string(line))
+	sb.WriteString("\x1b[K")
+	if back := len(line) - pos; back > 0 {
+		fmt.Fprintf(&sb, "\x1b[%dD", back)
+	}
+	fmt.Fprint(e.out, sb.String())
+}
+
+// lineReader reads the lines typed in the Interactive mode.
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+// scannerLineReader reads lines from an input that is not a terminal, such as a pipe, without editing them.
+type scannerLineReader struct {
+	scanner *bufio.Scanner
+}
+
+func (r *scannerLineReader) ReadLine(prompt string) (string, error) {
+	fmt.Print(prompt)
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.scanner.Text(), nil
+}
+
+// terminalLineReader edits the lines typed in a terminal, which is in raw mode only while a line is read,
+// so that the output of the commands is printed as usual.
+type terminalLineReader struct {
+	fd     int
+	editor *LineEditor
+}
+
+func (r *terminalLineReader) ReadLine(prompt string) (string, error) {
+	restore, err := makeRaw(r.fd)
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+	return r.editor.ReadLine(prompt)
+}
+
+// newLineReader returns a line editor if in is a terminal, or else a reader of the lines of in.
+func newLineReader(in io.Reader, history *History) lineReader {
+	if file, ok := in.(*os.File); ok && isTerminal(int(file.Fd())) {
+		return &terminalLineReader{fd: int(file.Fd()), editor: NewLineEditor(file, os.Stdout, history)}
+	}
+	return &scannerLineReader{scanner: bufio.NewScanner(in)}
+}
@@ -0,0 +1,165 @@
+package cmd_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/cmd"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func newTestHistory(t *testing.T, lines ...string) *cmd.History {
+	t.Helper()
+	history, err := cmd.LoadHistory("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range lines {
+		if err := history.Add(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return history
+}
+
+func TestLineEditorReadLine(t *testing.T) {
+	const (
+		up        = "\x1b[A"
+		down      = "\x1b[B"
+		left      = "\x1b[D"
+		right     = "\x1b[C"
+		home      = "\x1b[H"
+		del       = "\x1b[3~"
+		backspace = "\x7f"
+	)
+	tests := []struct {
+		name    string
+		keys    string
+		want    string
+		wantErr error
+	}{
+		{name: "should read the typed line", keys: "ls\r", want: "ls"},
+		{name: "should recall the previous line", keys: up + "\r", want: "id A-202"},
+		{name: "should recall the lines before", keys: up + up + up + up + "\r", want: "qstat"},
+		{name: "should come back to the typed line", keys: "re" + up + up + down + down + "\r", want: "re"},
+		{name: "should edit a recalled line", keys: up + backspace + "1" + "\r", want: "id A-201"},
+		{name: "should insert at the cursor", keys: "hld" + left + left + "o" + right + right + "\r", want: "hold"},
+		{name: "should delete at the cursor", keys: "xls" + home + del + "\r", want: "ls"},
+		{name: "should clear the line", keys: "purge\x15ls\r", want: "ls"},
+		{name: "should discard the line on Ctrl-C", keys: "purge\x03", want: ""},
+		{name: "should end the input on Ctrl-D", keys: "\x04", wantErr: io.EOF},
+		{name: "should return the line at the end of the input", keys: "ls", want: "ls"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			history := newTestHistory(t, "qstat", "receive", "id A-202")
+			editor := cmd.NewLineEditor(strings.NewReader(tt.keys), &out, history)
+			got, err := editor.ReadLine(">> ")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReadLine() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ReadLine() = %q, want %q", got, tt.want)
+			}
+			if !strings.HasPrefix(out.String(), ">> ") {
+				t.Errorf("ReadLine() printed %q, want the prompt first", out.String())
+			}
+		})
+	}
+}
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".dynamomq_history")
+	history, err := cmd.LoadHistory(path)
+	if err != nil {
+		t.Fatalf("LoadHistory() error = %v", err)
+	}
+	for _, line := range []string{"qstat", " ", "receive", "receive", "hold 'a b'"} {
+		if err := history.Add(line); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+	want := []string{"qstat", "receive", "hold 'a b'"}
+	test.AssertDeepEqual(t, history.Entries(), want, "Entries()")
+	reloaded, err := cmd.LoadHistory(path)
+	if err != nil {
+		t.Fatalf("LoadHistory() error = %v", err)
+	}
+	test.AssertDeepEqual(t, reloaded.Entries(), want, "Entries() of the reloaded history")
+}
+
+func TestInteractiveStartHereDoc(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		want     any
+		wantSent bool
+		history  []string
+	}{
+		{
+			name:     "should send the payload entered in the here-doc",
+			input:    "send A-101 --data <<EOF\n{\n  \"id\": \"A-101\",\n  \"note\": \"a b\"\n}\nEOF\nquit\n",
+			want:     map[string]any{"id": "A-101", "note": "a b"},
+			wantSent: true,
+			history:  []string{"send A-101 --data <<EOF", "quit"},
+		},
+		{
+			name:     "should send the payload given in quotes",
+			input:    "send A-101 --data '{\"amount\": 12345678901234567890}'\n",
+			want:     map[string]any{"amount": attributevalue.Number("12345678901234567890")},
+			wantSent: true,
+			history:  []string{"send A-101 --data '{\"amount\": 12345678901234567890}'"},
+		},
+		{
+			name:    "should not send a payload whose here-doc is not ended",
+			input:   "send A-101 --data <<END\n{}\nEOF\n",
+			history: []string{"send A-101 --data <<END"},
+		},
+		{
+			name:    "should not send a line with an unterminated quote",
+			input:   "send A-101 --data '{}\n",
+			history: []string{"send A-101 --data '{}"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent []*dynamomq.SendMessageInput[any]
+			c := &cmd.Interactive{
+				Client: mock.Client[any]{
+					SendMessageFunc: func(ctx context.Context, params *dynamomq.SendMessageInput[any]) (*dynamomq.SendMessageOutput[any], error) {
+						sent = append(sent, params)
+						return &dynamomq.SendMessageOutput[any]{
+							SentMessage: dynamomq.NewMessage(params.ID, params.Data, test.DefaultTestDate),
+						}, nil
+					},
+				},
+				History: newTestHistory(t),
+			}
+			if err := c.Start(strings.NewReader(tt.input)); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			test.AssertDeepEqual(t, c.History.Entries(), tt.history, "History")
+			if !tt.wantSent {
+				if len(sent) != 0 {
+					t.Errorf("SendMessage() calls = %d, want none", len(sent))
+				}
+				return
+			}
+			if len(sent) != 1 {
+				t.Fatalf("SendMessage() calls = %d, want 1", len(sent))
+			}
+			test.AssertDeepEqual(t, sent[0], &dynamomq.SendMessageInput[any]{ID: "A-101", Data: tt.want}, "SendMessage()")
+			if c.Message == nil || c.Message.ID != "A-101" {
+				t.Errorf("Start() current message = %v, want A-101", c.Message)
+			}
+		})
+	}
+}
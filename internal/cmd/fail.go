@@ -41,6 +41,6 @@ func (f CommandFactory) CreateFailCommand(flgs *Flags) *cobra.Command {
 func init() {
 	c := defaultCommandFactory.CreateFailCommand(flgs)
 	setDefaultFlags(c, flgs)
-	c.Flags().StringVar(&flgs.ID, flagMap.ID.Name, flagMap.ID.Value, flagMap.ID.Usage)
+	addIDFlag(c, flgs)
 	root.AddCommand(c)
 }
@@ -1,17 +1,44 @@
 package cmd
 
 import (
+	"github.com/vvatanabe/dynamomq"
 	"github.com/vvatanabe/dynamomq/internal/constant"
 )
 
 var flgs = &Flags{}
 
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
 type Flags struct {
 	TableName   string
 	IndexName   string
 	EndpointURL string
 
-	ID string
+	ID     string
+	Reason string
+	NoData bool
+
+	DataFile string
+	Set      []string
+
+	Delay             int
+	ResetReceiveCount bool
+
+	Limit int
+	Sort  string
+	Tag   string
+
+	Output string
+
+	SampleSize int
+
+	Format             string
+	TTLAttribute       string
+	ShardTableNames    []string
+	DeletionProtection bool
 }
 
 var flagMap = FlagMap{
@@ -35,6 +62,81 @@ var flagMap = FlagMap{
 		Usage: "Message ID in queue.",
 		Value: "",
 	},
+	Reason: FlagSet[string]{
+		Name:  "reason",
+		Usage: "The reason the message is held for.",
+		Value: "",
+	},
+	NoData: FlagSet[bool]{
+		Name:  "no-data",
+		Usage: "Get only the system attributes of the message, without reading its payload.",
+		Value: false,
+	},
+	DataFile: FlagSet[string]{
+		Name:  "data-file",
+		Usage: "The file holding the new payload as JSON, or - to read it from the standard input.",
+		Value: "",
+	},
+	Set: FlagSet[[]string]{
+		Name:  "set",
+		Usage: "Set a field of the payload as key=value, where key is a dotted path and value is JSON or a string. Can be repeated.",
+		Value: nil,
+	},
+	Delay: FlagSet[int]{
+		Name:  "delay",
+		Usage: "The delay in seconds before the redriven message can be received.",
+		Value: 0,
+	},
+	ResetReceiveCount: FlagSet[bool]{
+		Name:  "reset-receive-count",
+		Usage: "Zero the receive count of the redriven message.",
+		Value: false,
+	},
+	Limit: FlagSet[int]{
+		Name:  "limit",
+		Usage: "The maximum number of messages to process. Zero means unlimited.",
+		Value: 0,
+	},
+	Tag: FlagSet[string]{
+		Name:  "tag",
+		Usage: "Select only the messages sent with the tag. The tag is matched with a filter expression, so every message read is charged, tagged or not.",
+		Value: "",
+	},
+	Sort: FlagSet[string]{
+		Name:  "sort",
+		Usage: "The order of the messages: soonest, by the time they become visible again, or oldest, by the time they were received.",
+		Value: sortSoonest,
+	},
+	Output: FlagSet[string]{
+		Name:  "output",
+		Usage: "The output format: text or json.",
+		Value: outputText,
+	},
+	SampleSize: FlagSet[int]{
+		Name:  "sample-size",
+		Usage: "The maximum number of items read to compute the approximate figures, which bounds the cost of the report.",
+		Value: defaultReportSampleSize,
+	},
+	Format: FlagSet[string]{
+		Name:  "format",
+		Usage: "The format of the template: cloudformation or terraform.",
+		Value: string(dynamomq.TemplateFormatCloudFormation),
+	},
+	TTLAttribute: FlagSet[string]{
+		Name:  "ttl-attribute",
+		Usage: "The attribute to enable Time to Live on.",
+		Value: "",
+	},
+	ShardTableNames: FlagSet[[]string]{
+		Name:  "shard-table-names",
+		Usage: "The names of the other tables of a queue sharded across tables.",
+		Value: nil,
+	},
+	DeletionProtection: FlagSet[bool]{
+		Name:  "deletion-protection",
+		Usage: "Enable deletion protection on the table.",
+		Value: false,
+	},
 }
 
 type FlagSet[T any] struct {
@@ -48,4 +150,25 @@ type FlagMap struct {
 	IndexName   FlagSet[string]
 	EndpointURL FlagSet[string]
 	ID          FlagSet[string]
+	Reason      FlagSet[string]
+	NoData      FlagSet[bool]
+
+	DataFile FlagSet[string]
+	Set      FlagSet[[]string]
+
+	Delay             FlagSet[int]
+	ResetReceiveCount FlagSet[bool]
+
+	Limit FlagSet[int]
+	Sort  FlagSet[string]
+	Tag   FlagSet[string]
+
+	Output FlagSet[string]
+
+	SampleSize FlagSet[int]
+
+	Format             FlagSet[string]
+	TTLAttribute       FlagSet[string]
+	ShardTableNames    FlagSet[[]string]
+	DeletionProtection FlagSet[bool]
 }
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 
 	"github.com/spf13/cobra"
 	"github.com/vvatanabe/dynamomq"
@@ -9,18 +10,42 @@ import (
 
 func (f CommandFactory) CreateGetCommand(flgs *Flags) *cobra.Command {
 	return &cobra.Command{
-		Use:   "get",
+		Use:   "get [id...]",
 		Short: "Get a message the application object from DynamoDB by app domain ID",
-		Long:  `Get a message the application object from DynamoDB by app domain ID.`,
+		Long: `Get a message the application object from DynamoDB by app domain ID.
+Several IDs can be given as arguments, in addition to --id, to get their messages in batches.
+With --no-data, only the system attributes of a single message are read, leaving its payload out.`,
+		ValidArgsFunction: f.completeMessageIDs(flgs, 0),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
 			client, _, err := f.CreateDynamoMQClient(ctx, flgs)
 			if err != nil {
 				return err
 			}
+			ids := args
+			if flgs.ID != "" {
+				ids = append([]string{flgs.ID}, args...)
+			}
+			if len(ids) > 1 {
+				if flgs.NoData {
+					return errors.New("--no-data cannot be used with several IDs")
+				}
+				out, err := client.GetMessageBatch(ctx, &dynamomq.GetMessageBatchInput{
+					IDs: ids,
+				})
+				if err != nil {
+					return err
+				}
+				printMessageWithData("", out)
+				return nil
+			}
 			id := flgs.ID
+			if len(args) == 1 {
+				id = args[0]
+			}
 			retrieved, err := client.GetMessage(ctx, &dynamomq.GetMessageInput{
-				ID: id,
+				ID:       id,
+				OmitData: flgs.NoData,
 			})
 			if err != nil {
 				return err
@@ -34,6 +59,7 @@ func (f CommandFactory) CreateGetCommand(flgs *Flags) *cobra.Command {
 func init() {
 	c := defaultCommandFactory.CreateGetCommand(flgs)
 	setDefaultFlags(c, flgs)
-	c.Flags().StringVar(&flgs.ID, flagMap.ID.Name, flagMap.ID.Value, flagMap.ID.Usage)
+	addIDFlag(c, flgs)
+	c.Flags().BoolVar(&flgs.NoData, flagMap.NoData.Name, flagMap.NoData.Value, flagMap.NoData.Usage)
 	root.AddCommand(c)
 }
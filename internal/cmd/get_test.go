@@ -0,0 +1,90 @@
+package cmd_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/spf13/cobra"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/cmd"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestCommandFactoryCreateGetCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		flgs      *cmd.Flags
+		args      []string
+		wantGet   string
+		wantBatch []string
+		wantOmit  bool
+		wantErr   bool
+	}{
+		{
+			name:    "should get the message of the flag",
+			flgs:    &cmd.Flags{ID: "A-101"},
+			wantGet: "A-101",
+		},
+		{
+			name:    "should get the message of the argument",
+			flgs:    &cmd.Flags{},
+			args:    []string{"A-202"},
+			wantGet: "A-202",
+		},
+		{
+			name:      "should get the messages of several IDs in a batch",
+			flgs:      &cmd.Flags{ID: "A-101"},
+			args:      []string{"A-202", "A-303"},
+			wantBatch: []string{"A-101", "A-202", "A-303"},
+		},
+		{
+			name:     "should get the message without its payload",
+			flgs:     &cmd.Flags{ID: "A-101", NoData: true},
+			wantGet:  "A-101",
+			wantOmit: true,
+		},
+		{
+			name:    "should refuse to get several messages without their payloads",
+			flgs:    &cmd.Flags{ID: "A-101", NoData: true},
+			args:    []string{"A-202"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				gotGet   string
+				gotOmit  bool
+				gotBatch []string
+			)
+			f := cmd.CommandFactory{
+				CreateDynamoMQClient: func(ctx context.Context, flags *cmd.Flags) (dynamomq.Client[any], aws.Config, error) {
+					return mock.Client[any]{
+						GetMessageFunc: func(ctx context.Context, params *dynamomq.GetMessageInput) (*dynamomq.GetMessageOutput[any], error) {
+							gotGet = params.ID
+							gotOmit = params.OmitData
+							return &dynamomq.GetMessageOutput[any]{}, nil
+						},
+						GetMessageBatchFunc: func(ctx context.Context, params *dynamomq.GetMessageBatchInput) (*dynamomq.GetMessageBatchOutput[any], error) {
+							gotBatch = params.IDs
+							return &dynamomq.GetMessageBatchOutput[any]{}, nil
+						},
+					}, aws.Config{}, nil
+				},
+			}
+			err := f.CreateGetCommand(tt.flgs).RunE(&cobra.Command{}, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RunE() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotGet != tt.wantGet {
+				t.Errorf("GetMessage() id = %q, want %q", gotGet, tt.wantGet)
+			}
+			if gotOmit != tt.wantOmit {
+				t.Errorf("GetMessage() OmitData = %v, want %v", gotOmit, tt.wantOmit)
+			}
+			test.AssertDeepEqual(t, gotBatch, tt.wantBatch, "GetMessageBatch() IDs")
+		})
+	}
+}
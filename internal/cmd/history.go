@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	historyFileName = ".dynamomq_history"
+	// historySize is the number of lines kept in the history.
+	historySize = 1000
+)
+
+// History is the list of the lines entered in the Interactive mode, oldest first,
+// which is appended to a file so that it is kept across sessions.
+type History struct {
+	path    string
+	entries []string
+}
+
+// DefaultHistoryPath returns the path of the history file in the home directory of the user.
+func DefaultHistoryPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, historyFileName), nil
+}
+
+// LoadHistory reads the history from the file at path, if it exists, and appends the lines added later to it.
+// An empty path keeps the history in memory only.
+func LoadHistory(path string) (*History, error) {
+	h := &History{path: path}
+	if path == "" {
+		return h, nil
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(h.entries) > historySize {
+		// The file is rewritten with the lines kept, so that it does not grow without bound.
+		h.entries = h.entries[len(h.entries)-historySize:]
+		if err := os.WriteFile(path, []byte(strings.Join(h.entries, "\n")+"\n"), 0o600); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// Entries returns the lines of the history, oldest first.
+func (h *History) Entries() []string {
+	return h.entries
+}
+
+// Add appends the line to the history, unless it is blank or the same as the previous one.
+func (h *History) Add(line string) error {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.ContainsAny(line, "\r\n") {
+		return nil
+	}
+	if len(h.entries) > 0 && h.entries[len(h.entries)-1] == line {
+		return nil
+	}
+	h.entries = append(h.entries, line)
+	if len(h.entries) > historySize {
+		h.entries = h.entries[1:]
+	}
+	if h.path == "" {
+		return nil
+	}
+	file, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = file.WriteString(line + "\n")
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/vvatanabe/dynamomq"
+)
+
+func (f CommandFactory) CreateHoldCommand(flgs *Flags) *cobra.Command {
+	return &cobra.Command{
+		Use:               "hold [id]",
+		Short:             "Hold a message so that it is neither received nor redriven",
+		Long:              `Hold a message so that it is neither received nor redriven until it is released.`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: f.completeMessageIDs(flgs, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			asJSON, err := outputAsJSON(flgs.Output)
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+			client, _, err := f.CreateDynamoMQClient(ctx, flgs)
+			if err != nil {
+				return err
+			}
+			id := idOf(flgs, args)
+			result, err := client.HoldMessage(ctx, &dynamomq.HoldMessageInput{
+				ID:     id,
+				Reason: flgs.Reason,
+			})
+			if err != nil {
+				return notFoundWithID(err, id)
+			}
+			return printHeld(cmd.OutOrStdout(), asJSON, id, result.HeldMessage)
+		},
+	}
+}
+
+func (f CommandFactory) CreateReleaseCommand(flgs *Flags) *cobra.Command {
+	return &cobra.Command{
+		Use:               "release [id]",
+		Short:             "Release a held message",
+		Long:              `Release a held message so that it is received and redriven again.`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: f.completeMessageIDs(flgs, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			asJSON, err := outputAsJSON(flgs.Output)
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+			client, _, err := f.CreateDynamoMQClient(ctx, flgs)
+			if err != nil {
+				return err
+			}
+			id := idOf(flgs, args)
+			result, err := client.ReleaseMessage(ctx, &dynamomq.ReleaseMessageInput{
+				ID: id,
+			})
+			if err != nil {
+				return notFoundWithID(err, id)
+			}
+			return printHeld(cmd.OutOrStdout(), asJSON, id, result.ReleasedMessage)
+		},
+	}
+}
+
+// idOf returns the message ID given as the argument of a command, or else with --id.
+func idOf(flgs *Flags, args []string) string {
+	if len(args) == 1 {
+		return args[0]
+	}
+	return flgs.ID
+}
+
+// notFoundWithID names the message in the error if it is not found.
+func notFoundWithID(err error, id string) error {
+	if errors.Is(err, dynamomq.ErrIDNotFound) {
+		return errorWithID(err, id)
+	}
+	return err
+}
+
+// printHeld prints whether the message is held after hold or release, with the reason it is held for.
+func printHeld(w io.Writer, asJSON bool, id string, message *dynamomq.Message[any]) error {
+	if asJSON {
+		if message == nil {
+			return printJSON(w, &SystemInfo{ID: id})
+		}
+		return printJSON(w, GetSystemInfo(message))
+	}
+	if message == nil {
+		_, err := fmt.Fprintf(w, "Message '%s' is updated.\n", id)
+		return err
+	}
+	if !message.Held {
+		_, err := fmt.Fprintf(w, "Message '%s' is released at version %d; its status is %s.\n",
+			id, message.Version, GetSystemInfo(message).Status)
+		return err
+	}
+	reason := message.HoldReason
+	if reason == "" {
+		reason = "no reason given"
+	}
+	_, err := fmt.Fprintf(w, "Message '%s' is held at version %d: %s.\n", id, message.Version, reason)
+	return err
+}
+
+func init() {
+	c := defaultCommandFactory.CreateHoldCommand(flgs)
+	setDefaultFlags(c, flgs)
+	addIDFlag(c, flgs)
+	c.Flags().StringVar(&flgs.Reason, flagMap.Reason.Name, flagMap.Reason.Value, flagMap.Reason.Usage)
+	addOutputFlag(c, flgs)
+	root.AddCommand(c)
+
+	c = defaultCommandFactory.CreateReleaseCommand(flgs)
+	setDefaultFlags(c, flgs)
+	addIDFlag(c, flgs)
+	addOutputFlag(c, flgs)
+	root.AddCommand(c)
+}
@@ -0,0 +1,199 @@
+package cmd_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/google/uuid"
+	"github.com/upsidr/dynamotest"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/cmd"
+	"github.com/vvatanabe/dynamomq/internal/constant"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+// newHoldClient returns a client holding and releasing the messages found in ids.
+func newHoldClient(ids ...string) cmd.CommandFactory {
+	find := func(id string) (*dynamomq.Message[any], error) {
+		for _, v := range ids {
+			if v == id {
+				message := dynamomq.NewMessage[any](id, test.NewMessageData(id), test.DefaultTestDate)
+				message.Version = 2
+				return message, nil
+			}
+		}
+		return nil, &dynamomq.IDNotFoundError{}
+	}
+	return cmd.CommandFactory{
+		CreateDynamoMQClient: func(ctx context.Context, flags *cmd.Flags) (dynamomq.Client[any], aws.Config, error) {
+			return mock.Client[any]{
+				HoldMessageFunc: func(ctx context.Context, params *dynamomq.HoldMessageInput) (*dynamomq.HoldMessageOutput[any], error) {
+					message, err := find(params.ID)
+					if err != nil {
+						return &dynamomq.HoldMessageOutput[any]{}, err
+					}
+					message.Held = true
+					message.HoldReason = params.Reason
+					return &dynamomq.HoldMessageOutput[any]{HeldMessage: message}, nil
+				},
+				ReleaseMessageFunc: func(ctx context.Context, params *dynamomq.ReleaseMessageInput) (*dynamomq.ReleaseMessageOutput[any], error) {
+					message, err := find(params.ID)
+					if err != nil {
+						return &dynamomq.ReleaseMessageOutput[any]{}, err
+					}
+					return &dynamomq.ReleaseMessageOutput[any]{ReleasedMessage: message}, nil
+				},
+			}, aws.Config{}, nil
+		},
+	}
+}
+
+func TestCommandFactoryCreateHoldCommand(t *testing.T) {
+	f := newHoldClient("A-101")
+	tests := []struct {
+		name    string
+		command func(flgs *cmd.Flags) (string, error)
+		flgs    *cmd.Flags
+		want    string
+		wantErr error
+	}{
+		{
+			name: "should hold the message of the argument",
+			command: func(flgs *cmd.Flags) (string, error) {
+				return runCommand(t, f.CreateHoldCommand(flgs), "A-101")
+			},
+			flgs: &cmd.Flags{Reason: "INC-42"},
+			want: "Message 'A-101' is held at version 2: INC-42.\n",
+		},
+		{
+			name: "should hold the message of the id flag without a reason",
+			command: func(flgs *cmd.Flags) (string, error) {
+				return runCommand(t, f.CreateHoldCommand(flgs))
+			},
+			flgs: &cmd.Flags{ID: "A-101"},
+			want: "Message 'A-101' is held at version 2: no reason given.\n",
+		},
+		{
+			name: "should release the message of the argument",
+			command: func(flgs *cmd.Flags) (string, error) {
+				return runCommand(t, f.CreateReleaseCommand(flgs), "A-101")
+			},
+			flgs: &cmd.Flags{},
+			want: "Message 'A-101' is released at version 2; its status is READY.\n",
+		},
+		{
+			name: "should return IDNotFoundError when the held message is not found",
+			command: func(flgs *cmd.Flags) (string, error) {
+				return runCommand(t, f.CreateHoldCommand(flgs), "B-101")
+			},
+			flgs:    &cmd.Flags{Reason: "INC-42"},
+			wantErr: dynamomq.ErrIDNotFound,
+		},
+		{
+			name: "should return IDNotFoundError when the released message is not found",
+			command: func(flgs *cmd.Flags) (string, error) {
+				return runCommand(t, f.CreateReleaseCommand(flgs), "B-101")
+			},
+			flgs:    &cmd.Flags{},
+			wantErr: dynamomq.ErrIDNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.command(tt.flgs)
+			test.AssertError(t, err, tt.wantErr, "RunE()")
+			if got != tt.want {
+				t.Errorf("RunE() printed %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandFactoryCreateHoldCommandAsJSON(t *testing.T) {
+	f := newHoldClient("A-101")
+	got, err := runCommand(t, f.CreateHoldCommand(&cmd.Flags{Reason: "INC-42", Output: "json"}), "A-101")
+	if err != nil {
+		t.Fatalf("RunE() error = %v", err)
+	}
+	var info cmd.SystemInfo
+	if err := json.Unmarshal([]byte(got), &info); err != nil {
+		t.Fatalf("RunE() printed %s, which is not JSON: %v", got, err)
+	}
+	if info.ID != "A-101" || !info.Held || info.HoldReason != "INC-42" || info.Version != 2 {
+		t.Errorf("RunE() printed %+v, want A-101 held for INC-42 at version 2", info)
+	}
+}
+
+func TestOperationCommandsAgainstDynamoDB(t *testing.T) {
+	raw, clean := dynamotest.NewDynamoDB(t)
+	defer clean()
+	tableName := constant.DefaultTableName + "-" + uuid.NewString()
+	dynamotest.PrepTable(t, raw, dynamotest.InitialTableSetup{
+		Table: dynamomq.NewCreateTableInput(&dynamomq.CreateQueueTableInput{
+			TableName: tableName,
+		}),
+	})
+	client, err := dynamomq.NewFromConfig[any](aws.Config{},
+		dynamomq.WithDynamoDBAPI(raw),
+		dynamomq.WithTableName(tableName),
+		dynamomq.WithConsumerID("worker-1"))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	ctx := context.Background()
+	for _, id := range []string{"A-101", "A-202", "A-303"} {
+		if _, err := client.SendMessage(ctx, &dynamomq.SendMessageInput[any]{ID: id, Data: test.NewMessageData(id)}); err != nil {
+			t.Fatalf("SendMessage() error = %v", err)
+		}
+	}
+	// A-101 and A-202 are being processed, and A-303 is left ready.
+	for i := 0; i < 2; i++ {
+		if _, err := client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{}); err != nil {
+			t.Fatalf("ReceiveMessage() error = %v", err)
+		}
+	}
+	f := cmd.CommandFactory{
+		CreateDynamoMQClient: func(ctx context.Context, flags *cmd.Flags) (dynamomq.Client[any], aws.Config, error) {
+			return client, aws.Config{}, nil
+		},
+	}
+
+	got, err := runCommand(t, f.CreateInFlightCommand(&cmd.Flags{Sort: "oldest"}))
+	if err != nil {
+		t.Fatalf("inflight error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(got), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "A-101 ") || !strings.HasPrefix(lines[2], "A-202 ") ||
+		!strings.Contains(got, "worker-1") {
+		t.Errorf("inflight printed\n%s\nwant A-101 and A-202 processed by worker-1", got)
+	}
+
+	got, err = runCommand(t, f.CreateHoldCommand(&cmd.Flags{Reason: "INC-42"}), "A-303")
+	if err != nil {
+		t.Fatalf("hold error = %v", err)
+	}
+	if want := "Message 'A-303' is held at version 2: INC-42.\n"; got != want {
+		t.Errorf("hold printed %q, want %q", got, want)
+	}
+	if _, err := client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{}); err == nil {
+		t.Error("ReceiveMessage() received the held message")
+	}
+
+	got, err = runCommand(t, f.CreateReleaseCommand(&cmd.Flags{Output: "json"}), "A-303")
+	if err != nil {
+		t.Fatalf("release error = %v", err)
+	}
+	var info cmd.SystemInfo
+	if err := json.Unmarshal([]byte(got), &info); err != nil {
+		t.Fatalf("release printed %s, which is not JSON: %v", got, err)
+	}
+	if info.Held || info.Version != 3 || info.Status != dynamomq.StatusReady {
+		t.Errorf("release printed %+v, want a ready message at version 3", info)
+	}
+	_, err = runCommand(t, f.CreateReleaseCommand(&cmd.Flags{}), "B-101")
+	test.AssertError(t, err, dynamomq.ErrIDNotFound, "release")
+}
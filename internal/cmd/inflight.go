@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+)
+
+const (
+	sortSoonest = "soonest"
+	sortOldest  = "oldest"
+)
+
+func (f CommandFactory) CreateInFlightCommand(flgs *Flags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "inflight",
+		Short: "List the messages being processed and when each becomes visible again",
+		Long: `List the messages being processed and when each becomes visible again.
+With --sort soonest, the default, the messages whose visibility timeout expires first are listed first;
+with --sort oldest, the messages received first are.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			asJSON, err := outputAsJSON(flgs.Output)
+			if err != nil {
+				return err
+			}
+			less, err := inFlightOrder(flgs.Sort)
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+			client, _, err := f.CreateDynamoMQClient(ctx, flgs)
+			if err != nil {
+				return err
+			}
+			messages, err := listInFlightMessages(ctx, client)
+			if err != nil {
+				return err
+			}
+			sort.SliceStable(messages, func(i, j int) bool {
+				return less(messages[i], messages[j])
+			})
+			if flgs.Limit > 0 && len(messages) > flgs.Limit {
+				messages = messages[:flgs.Limit]
+			}
+			if asJSON {
+				return printJSON(cmd.OutOrStdout(), &dynamomq.ListInFlightMessagesOutput{Messages: messages})
+			}
+			return printInFlightMessages(cmd.OutOrStdout(), messages)
+		},
+	}
+}
+
+// listInFlightMessages lists the messages being processed across every page, so that they can be sorted as a whole.
+func listInFlightMessages(ctx context.Context, client dynamomq.Client[any]) ([]dynamomq.InFlightMessage, error) {
+	messages := make([]dynamomq.InFlightMessage, 0)
+	var nextToken string
+	for {
+		out, err := client.ListInFlightMessages(ctx, &dynamomq.ListInFlightMessagesInput{
+			NextToken: nextToken,
+		})
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, out.Messages...)
+		if out.NextToken == "" {
+			return messages, nil
+		}
+		nextToken = out.NextToken
+	}
+}
+
+func inFlightOrder(order string) (func(a, b dynamomq.InFlightMessage) bool, error) {
+	switch order {
+	case "", sortSoonest:
+		return func(a, b dynamomq.InFlightMessage) bool {
+			return a.VisibleAt.Before(b.VisibleAt)
+		}, nil
+	case sortOldest:
+		return func(a, b dynamomq.InFlightMessage) bool {
+			return a.ReceivedAt.Before(b.ReceivedAt)
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid --sort %q, want %s or %s", order, sortSoonest, sortOldest)
+	}
+}
+
+func printInFlightMessages(w io.Writer, messages []dynamomq.InFlightMessage) error {
+	if len(messages) == 0 {
+		_, err := fmt.Fprintln(w, "No message is being processed.")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tRECEIVE COUNT\tCONSUMER ID\tRECEIVED AT\tVISIBLE AT")
+	for _, m := range messages {
+		consumerID := m.ConsumerID
+		if consumerID == "" {
+			consumerID = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\n", m.ID, m.ReceiveCount, consumerID,
+			clock.FormatRFC3339Nano(m.ReceivedAt), clock.FormatRFC3339Nano(m.VisibleAt))
+	}
+	return tw.Flush()
+}
+
+func init() {
+	c := defaultCommandFactory.CreateInFlightCommand(flgs)
+	setDefaultFlags(c, flgs)
+	c.Flags().StringVar(&flgs.IndexName, flagMap.IndexName.Name, flagMap.IndexName.Value, flagMap.IndexName.Usage)
+	c.Flags().StringVar(&flgs.Sort, flagMap.Sort.Name, flagMap.Sort.Value, flagMap.Sort.Usage)
+	_ = c.RegisterFlagCompletionFunc(flagMap.Sort.Name,
+		cobra.FixedCompletions([]string{sortSoonest, sortOldest}, cobra.ShellCompDirectiveNoFileComp))
+	c.Flags().IntVar(&flgs.Limit, flagMap.Limit.Name, flagMap.Limit.Value, "The maximum number of messages to list. Zero means unlimited.")
+	addOutputFlag(c, flgs)
+	root.AddCommand(c)
+}
@@ -0,0 +1,147 @@
+package cmd_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/spf13/cobra"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/cmd"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+// newInFlightMessage returns a message received receivedAgo before the test date which becomes visible visibleIn after it.
+func newInFlightMessage(id string, receivedAgo, visibleIn time.Duration) dynamomq.InFlightMessage {
+	return dynamomq.InFlightMessage{
+		ID:           id,
+		ReceiveCount: 1,
+		ConsumerID:   "worker-1",
+		ReceivedAt:   test.DefaultTestDate.Add(-receivedAgo),
+		VisibleAt:    test.DefaultTestDate.Add(visibleIn),
+	}
+}
+
+// newInFlightClient returns a client listing the messages being processed in pages of two.
+func newInFlightClient(messages ...dynamomq.InFlightMessage) cmd.CommandFactory {
+	return cmd.CommandFactory{
+		CreateDynamoMQClient: func(ctx context.Context, flags *cmd.Flags) (dynamomq.Client[any], aws.Config, error) {
+			return mock.Client[any]{
+				ListInFlightMessagesFunc: func(ctx context.Context, params *dynamomq.ListInFlightMessagesInput) (*dynamomq.ListInFlightMessagesOutput, error) {
+					start := 0
+					if params.NextToken != "" {
+						start = int(params.NextToken[0] - '0')
+					}
+					end := min(start+2, len(messages))
+					out := &dynamomq.ListInFlightMessagesOutput{Messages: messages[start:end]}
+					if end < len(messages) {
+						out.NextToken = string(rune('0' + end))
+					}
+					return out, nil
+				},
+			}, aws.Config{}, nil
+		},
+	}
+}
+
+// runCommand runs the command and returns what it printed.
+func runCommand(t *testing.T, c *cobra.Command, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	runner := &cobra.Command{}
+	runner.SetOut(&out)
+	err := c.RunE(runner, args)
+	return out.String(), err
+}
+
+func TestCommandFactoryCreateInFlightCommand(t *testing.T) {
+	messages := []dynamomq.InFlightMessage{
+		newInFlightMessage("A-101", time.Minute, 30*time.Second),
+		newInFlightMessage("A-202", 3*time.Minute, 10*time.Second),
+		newInFlightMessage("A-303", 2*time.Minute, 50*time.Second),
+	}
+	messages[1].ConsumerID = ""
+	tests := []struct {
+		name     string
+		flgs     *cmd.Flags
+		messages []dynamomq.InFlightMessage
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "should list the messages whose visibility timeout expires first",
+			flgs:     &cmd.Flags{},
+			messages: messages,
+			want: `ID     RECEIVE COUNT  CONSUMER ID  RECEIVED AT           VISIBLE AT
+A-202  1              -            2023-11-30T23:57:00Z  2023-12-01T00:00:10Z
+A-101  1              worker-1     2023-11-30T23:59:00Z  2023-12-01T00:00:30Z
+A-303  1              worker-1     2023-11-30T23:58:00Z  2023-12-01T00:00:50Z
+`,
+		},
+		{
+			name:     "should list the messages received first up to the limit",
+			flgs:     &cmd.Flags{Sort: "oldest", Limit: 2},
+			messages: messages,
+			want: `ID     RECEIVE COUNT  CONSUMER ID  RECEIVED AT           VISIBLE AT
+A-202  1              -            2023-11-30T23:57:00Z  2023-12-01T00:00:10Z
+A-303  1              worker-1     2023-11-30T23:58:00Z  2023-12-01T00:00:50Z
+`,
+		},
+		{
+			name: "should tell that no message is being processed",
+			flgs: &cmd.Flags{},
+			want: "No message is being processed.\n",
+		},
+		{
+			name:     "should reject an unknown order",
+			flgs:     &cmd.Flags{Sort: "newest"},
+			messages: messages,
+			wantErr:  true,
+		},
+		{
+			name:     "should reject an unknown output format",
+			flgs:     &cmd.Flags{Output: "yaml"},
+			messages: messages,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runCommand(t, newInFlightClient(tt.messages...).CreateInFlightCommand(tt.flgs))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RunE() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RunE() printed\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandFactoryCreateInFlightCommandAsJSON(t *testing.T) {
+	messages := []dynamomq.InFlightMessage{
+		newInFlightMessage("A-101", time.Minute, 30*time.Second),
+		newInFlightMessage("A-202", 3*time.Minute, 10*time.Second),
+		newInFlightMessage("A-303", 2*time.Minute, 50*time.Second),
+	}
+	f := newInFlightClient(messages...)
+	got, err := runCommand(t, f.CreateInFlightCommand(&cmd.Flags{Output: "json", Limit: 2}))
+	if err != nil {
+		t.Fatalf("RunE() error = %v", err)
+	}
+	var out dynamomq.ListInFlightMessagesOutput
+	if err := json.Unmarshal([]byte(got), &out); err != nil {
+		t.Fatalf("RunE() printed %s, which is not JSON: %v", got, err)
+	}
+	test.AssertDeepEqual(t, out, dynamomq.ListInFlightMessagesOutput{
+		Messages: []dynamomq.InFlightMessage{messages[1], messages[0]},
+	}, "RunE()")
+	if !strings.Contains(got, `"visible_at": "2023-12-01T00:00:10Z"`) {
+		t.Errorf("RunE() printed %s, want the visible at times", got)
+	}
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// argument is a word of a line typed in the Interactive mode.
+type argument struct {
+	value string
+	// quoted is true if any part of the word was quoted or escaped, so that it is taken literally.
+	quoted bool
+}
+
+// splitArguments splits a line into words as a shell does: words are separated by spaces, unless the spaces are
+// quoted. Single quotes keep everything up to the next single quote as it is. Double quotes do the same,
+// except that a backslash escapes a double quote or a backslash. Out of quotes, a backslash escapes any character.
+func splitArguments(line string) ([]argument, error) {
+	var (
+		args    []argument
+		current strings.Builder
+		inWord  bool
+		quoted  bool
+	)
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			if inWord {
+				args = append(args, argument{value: current.String(), quoted: quoted})
+				current.Reset()
+				inWord, quoted = false, false
+			}
+		case r == '\'':
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return nil, errors.New("unterminated single quote")
+			}
+			current.WriteString(string(runes[i+1 : end]))
+			i = end
+			inWord, quoted = true, true
+		case r == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+					i++
+				}
+				current.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, errors.New("unterminated double quote")
+			}
+			inWord, quoted = true, true
+		case r == '\\' && i+1 < len(runes):
+			i++
+			current.WriteRune(runes[i])
+			inWord, quoted = true, true
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		args = append(args, argument{value: current.String(), quoted: quoted})
+	}
+	return args, nil
+}
+
+func indexRune(runes []rune, from int, r rune) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// hereDocDelimiter returns the delimiter of the here-doc the line ends with, as in `send A-101 --data <<EOF`,
+// and the arguments before it. A quoted `<<EOF` is an ordinary argument.
+func hereDocDelimiter(args []argument) ([]argument, string, bool) {
+	if len(args) == 0 {
+		return args, "", false
+	}
+	last := args[len(args)-1]
+	if last.quoted || !strings.HasPrefix(last.value, "<<") {
+		return args, "", false
+	}
+	delimiter := strings.Trim(strings.TrimPrefix(last.value, "<<"), `'"`)
+	if delimiter == "" {
+		return args, "", false
+	}
+	return args[:len(args)-1], delimiter, true
+}
+
+// ParseInput parses a line typed in the Interactive mode into its command, lowercased, and the parameters
+// of the command, which can be quoted to hold spaces.
+func ParseInput(input string) (command string, params []string, err error) {
+	args, err := splitArguments(input)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid command %q: %w", input, err)
+	}
+	return commandOf(args)
+}
+
+func commandOf(args []argument) (command string, params []string, err error) {
+	if len(args) == 0 {
+		return "", nil, nil
+	}
+	command = strings.ToLower(args[0].value)
+	if len(args) > 1 {
+		params = make([]string, len(args)-1)
+		for i, arg := range args[1:] {
+			params[i] = arg.value
+		}
+	}
+	return command, params, nil
+}
@@ -1,12 +1,15 @@
 package cmd
 
 import (
-	"bufio"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"slices"
+	"strings"
 
+	"github.com/spf13/pflag"
 	"github.com/vvatanabe/dynamomq"
 	"github.com/vvatanabe/dynamomq/internal/clock"
 	"github.com/vvatanabe/dynamomq/internal/constant"
@@ -16,20 +19,37 @@ import (
 type Interactive struct {
 	Client  dynamomq.Client[any]
 	Message *dynamomq.Message[any]
+	// History records the commands entered, which are recalled with the up arrow when the input is a terminal.
+	// It is not recorded if it is nil.
+	History *History
 }
 
 func (c *Interactive) Start(in io.Reader) error {
-	scanner := bufio.NewScanner(in)
+	reader := newLineReader(in, c.History)
 	for {
+		fmt.Println()
+		prompt := ">> Enter command: "
 		if c.Message != nil {
-			fmt.Printf("\nID <%s> >> Enter command: ", c.Message.ID)
-		} else {
-			fmt.Print("\n>> Enter command: ")
+			prompt = fmt.Sprintf("ID <%s> >> Enter command: ", c.Message.ID)
 		}
-		command, params, quit := c.scanAndParseCommand(scanner)
-		if quit {
+		line, err := reader.ReadLine(prompt)
+		if errors.Is(err, io.EOF) {
 			break
 		}
+		if err != nil {
+			return err
+		}
+		command, params, err := c.parseCommand(reader, line)
+		if err != nil {
+			printError(err)
+			continue
+		}
+		if command == "quit" || command == "q" {
+			break
+		}
+		if command == "" {
+			continue
+		}
 		if err := c.Run(context.Background(), command, params); err != nil {
 			printError(err)
 		}
@@ -37,43 +57,112 @@ func (c *Interactive) Start(in io.Reader) error {
 	return nil
 }
 
-func (c *Interactive) scanAndParseCommand(scanner *bufio.Scanner) (command string, params []string, quit bool) {
-	scanned := scanner.Scan()
-	if !scanned {
-		quit = true
-		return
+// parseCommand parses the line into its command and parameters. A line ending with `<<EOF` is followed by
+// a here-doc: the lines read up to the one holding only EOF are joined into the last parameter, so that
+// a payload spanning several lines can be entered, as in `send A-101 --data <<EOF`.
+func (c *Interactive) parseCommand(reader lineReader, line string) (command string, params []string, err error) {
+	if c.History != nil {
+		if err := c.History.Add(line); err != nil {
+			printError(fmt.Errorf("failed to save the history: %w", err))
+		}
 	}
+	args, err := splitArguments(line)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid command %q: %w", line, err)
+	}
+	args, delimiter, ok := hereDocDelimiter(args)
+	if ok {
+		body, err := readHereDoc(reader, delimiter)
+		if err != nil {
+			return "", nil, err
+		}
+		args = append(args, argument{value: body, quoted: true})
+	}
+	return commandOf(args)
+}
 
-	input := scanner.Text()
-	if input == "" {
-		quit = false
-		return
+// readHereDoc reads the lines up to the one holding only the delimiter, and returns them joined.
+func readHereDoc(reader lineReader, delimiter string) (string, error) {
+	var lines []string
+	for {
+		line, err := reader.ReadLine("> ")
+		if errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("the here-doc is not ended with %s", delimiter)
+		}
+		if err != nil {
+			return "", err
+		}
+		if line == delimiter {
+			return strings.Join(lines, "\n"), nil
+		}
+		lines = append(lines, line)
 	}
+}
 
-	command, params = ParseInput(input)
-	switch command {
-	case "quit", "q":
-		quit = true
-	default:
-		quit = false
+// interactiveCommands are the commands of the Interactive mode, which can be abbreviated to any unambiguous prefix.
+var interactiveCommands = []string{
+	"help", "qstat", "dlq", "inflight", "reclaim", "enqueue-test", "purge", "ls", "send", "receive", "id",
+	"system", "data", "info", "reset", "redrive", "hold", "release", "delete", "fail", "invalid",
+}
+
+// interactiveAliases are the short names of the commands of the Interactive mode, including the ones
+// whose prefix is shared with another command.
+var interactiveAliases = map[string]string{
+	"h":      "help",
+	"?":      "help",
+	"qstats": "qstat",
+	"et":     "enqueue-test",
+	"rec":    "receive",
+}
+
+// expandCommand returns the command of the Interactive mode named by an alias or an unambiguous prefix,
+// or the command as it is if it names none.
+func expandCommand(command string) (string, error) {
+	if expanded, ok := interactiveAliases[command]; ok {
+		return expanded, nil
+	}
+	if command == "" || slices.Contains(interactiveCommands, command) {
+		return command, nil
+	}
+	var matches []string
+	for _, name := range interactiveCommands {
+		if strings.HasPrefix(name, command) {
+			matches = append(matches, name)
+		}
 	}
-	return
+	if len(matches) > 1 {
+		return "", fmt.Errorf("ambiguous command %q, could be %s", command, strings.Join(matches, ", "))
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	return command, nil
 }
 
 func (c *Interactive) Run(ctx context.Context, command string, params []string) (err error) {
+	command, err = expandCommand(command)
+	if err != nil {
+		return err
+	}
 	switch command {
-	case "h", "?", "help":
+	case "help":
 		err = c.help(ctx, params)
 	case "qstat":
 		err = c.qstat(ctx, params)
 	case "dlq":
 		err = c.dlq(ctx, params)
+	case "inflight":
+		err = c.inflight(ctx, params)
+	case "reclaim":
+		err = c.reclaim(ctx, params)
 	case "enqueue-test":
 		err = c.enqueueTest(ctx, params)
 	case "purge":
 		err = c.purge(ctx, params)
 	case "ls":
 		err = c.ls(ctx, params)
+	case "send":
+		err = c.send(ctx, params)
 	case "receive":
 		err = c.receive(ctx, params)
 	case "id":
@@ -88,6 +177,10 @@ func (c *Interactive) Run(ctx context.Context, command string, params []string)
 		err = c.reset(ctx, params)
 	case "redrive":
 		err = c.redrive(ctx, params)
+	case "hold":
+		err = c.hold(ctx, params)
+	case "release":
+		err = c.release(ctx, params)
 	case "delete":
 		err = c.delete(ctx, params)
 	case "fail":
@@ -102,18 +195,27 @@ func (c *Interactive) Run(ctx context.Context, command string, params []string)
 
 func (c *Interactive) help(_ context.Context, _ []string) error {
 	fmt.Println(`... this is Interactive HELP!
+  Commands can be abbreviated to any unambiguous prefix, such as 'infl' for inflight; 'rec' is short for receive.
   > qstat                                         [Retrieves the queue statistics]
   > dlq                                           [Retrieves the Dead Letter Queue (DLQ) statistics]
+  > inflight                                      [List the messages being processed and when each becomes visible again]
+  > reclaim                                       [Make the messages whose visibility timeout has expired visible again]
   > enqueue-test                                  [Send test messages in DynamoDB table: A-101, A-202, A-303 and A-404; if already exists, it will overwrite it]
   > purge                                         [It will remove all message from DynamoMQ table]
-  > ls                                            [List all message IDs ... max 10 elements]
+  > ls                                            [List the first 10 messages of the queue and the DLQ in queue order]
+  > send <id> [--data <json>] [--tag <tag>...]    [Send a message with the JSON payload, which can be quoted or entered after --data <<EOF up to a line holding EOF, and the tags]
   > receive                                       [Receive a message from the queue .. it will replace the current ID with the peeked one]
+  > redrive <id> [--delay <seconds>] [--reset-receive-count]
+                                                  [Redrive the message <id> to STANDARD from DLQ, after the delay if given]
   > id <id>                                       [Get a message the application object from DynamoDB by app domain ID; Interactive is in the app mode, from that point on]
     > system                                      [Show system info data in a JSON format]
     > data                                        [Print the data as JSON for the current message record]
     > info                                        [Print all info regarding Message record: system_info and data as JSON]
     > reset                                       [Reset the system info of the message]
-    > redrive                                     [Redrive a message to STANDARD from DLQ]
+    > redrive [--delay <seconds>] [--reset-receive-count]
+                                                  [Redrive a message to STANDARD from DLQ]
+    > hold <reason>                               [Hold the message so that it is neither received nor redriven]
+    > release                                     [Release the held message]
     > delete                                      [Delete a message by ID]
     > fail                                        [Simulate failed message's processing ... put back to the queue; needs to be receive again]
     > invalid                                     [Remove a message from the standard queue to dead letter queue (DLQ) for manual fix]
@@ -146,23 +248,15 @@ func (c *Interactive) system(_ context.Context, _ []string) error {
 }
 
 func (c *Interactive) ls(ctx context.Context, _ []string) error {
-	out, err := c.Client.ListMessages(ctx, &dynamomq.ListMessagesInput{Size: constant.DefaultMaxListMessages})
+	result, err := listSummaries(ctx, c.Client, constant.DefaultMaxListMessages, "")
 	if err != nil {
 		return err
 	}
-	if len(out.Messages) == 0 {
-		fmt.Println("Queue is empty!")
-		return nil
-	}
-	fmt.Println("List messages of first 10 IDs:")
-	for _, m := range out.Messages {
-		fmt.Printf("* ID: %s, status: %s", m.ID, m.GetStatus(clock.Now()))
-	}
-	return nil
+	return printSummaries(os.Stdout, result.Messages)
 }
 
 func (c *Interactive) purge(ctx context.Context, _ []string) error {
-	out, err := c.Client.ListMessages(ctx, &dynamomq.ListMessagesInput{Size: constant.DefaultMaxListMessages})
+	out, err := c.Client.ListMessages(ctx, &dynamomq.ListMessagesInput{Size: constant.DefaultMaxListMessages, OmitData: true})
 	if err != nil {
 		return err
 	}
@@ -205,6 +299,24 @@ func (c *Interactive) enqueueTest(ctx context.Context, _ []string) error {
 	return nil
 }
 
+func (c *Interactive) inflight(ctx context.Context, _ []string) error {
+	out, err := c.Client.ListInFlightMessages(ctx, &dynamomq.ListInFlightMessagesInput{})
+	if err != nil {
+		return err
+	}
+	printMessageWithData("In-flight messages:\n", out)
+	return nil
+}
+
+func (c *Interactive) reclaim(ctx context.Context, _ []string) error {
+	out, err := c.Client.ReclaimExpiredMessages(ctx, &dynamomq.ReclaimExpiredMessagesInput{})
+	if err != nil {
+		return err
+	}
+	printMessageWithData("Reclaimed messages:\n", out)
+	return nil
+}
+
 func (c *Interactive) qstat(ctx context.Context, _ []string) error {
 	stats, err := c.Client.GetQueueStats(ctx, &dynamomq.GetQueueStatsInput{})
 	if err != nil {
@@ -223,6 +335,42 @@ func (c *Interactive) dlq(ctx context.Context, _ []string) error {
 	return nil
 }
 
+func (c *Interactive) send(ctx context.Context, params []string) error {
+	fs := pflag.NewFlagSet("send", pflag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	payload := fs.String("data", "", "The payload of the message as JSON.")
+	tags := fs.StringArray(flagMap.Tag.Name, nil, "A tag stored on the message. Can be repeated.")
+	if err := fs.Parse(params); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("send needs the ID of the message: send <id> [--data <json>] [--tag <tag>...]")
+	}
+	var data any
+	if *payload != "" {
+		decoded, err := decodePayload([]byte(*payload))
+		if err != nil {
+			return err
+		}
+		data = toAttributeValues(decoded)
+	}
+	result, err := c.Client.SendMessage(ctx, &dynamomq.SendMessageInput[any]{
+		ID:   fs.Arg(0),
+		Data: data,
+		Tags: *tags,
+	})
+	if err != nil {
+		return err
+	}
+	if result.SentMessage == nil {
+		printMessageWithData("", result)
+		return nil
+	}
+	c.Message = result.SentMessage
+	printMessageWithData("Sent system info:\n", GetSystemInfo(c.Message))
+	return nil
+}
+
 func (c *Interactive) receive(ctx context.Context, _ []string) error {
 	rr, err := c.Client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{})
 	if err != nil {
@@ -276,17 +424,61 @@ func (c *Interactive) reset(ctx context.Context, _ []string) error {
 	return nil
 }
 
-func (c *Interactive) redrive(ctx context.Context, _ []string) error {
+func (c *Interactive) redrive(ctx context.Context, params []string) error {
+	id, input, err := parseRedriveParams(params)
+	if err != nil {
+		return err
+	}
+	if id == "" {
+		if c.Message == nil {
+			return errorCLIModeRestriction("`redrive`")
+		}
+		id = c.Message.ID
+	}
+	input.ID = id
+	result, err := redriveMessage(ctx, c.Client, input)
+	if err != nil {
+		return err
+	}
+	if result.RedroveMessage != nil {
+		c.Message = result.RedroveMessage
+	}
+	printRedriven(result)
+	return nil
+}
+
+func (c *Interactive) hold(ctx context.Context, params []string) error {
+	if c.Message == nil {
+		return errorCLIModeRestriction("`hold`")
+	}
+	result, err := c.Client.HoldMessage(ctx, &dynamomq.HoldMessageInput{
+		ID:     c.Message.ID,
+		Reason: strings.Join(params, " "),
+	})
+	if err != nil {
+		return err
+	}
+	if result.HeldMessage != nil {
+		c.Message = result.HeldMessage
+	}
+	printMessageWithData("Held system info:\n", GetSystemInfo(c.Message))
+	return nil
+}
+
+func (c *Interactive) release(ctx context.Context, _ []string) error {
 	if c.Message == nil {
-		return errorCLIModeRestriction("`redrive`")
+		return errorCLIModeRestriction("`release`")
 	}
-	result, err := c.Client.RedriveMessage(ctx, &dynamomq.RedriveMessageInput{
+	result, err := c.Client.ReleaseMessage(ctx, &dynamomq.ReleaseMessageInput{
 		ID: c.Message.ID,
 	})
 	if err != nil {
 		return err
 	}
-	printMessageWithData("Ready system info:\n", result)
+	if result.ReleasedMessage != nil {
+		c.Message = result.ReleasedMessage
+	}
+	printMessageWithData("Released system info:\n", GetSystemInfo(c.Message))
 	return nil
 }
 
@@ -340,12 +532,15 @@ func (c *Interactive) invalid(ctx context.Context, _ []string) error {
 	if c.Message == nil {
 		return errorCLIModeRestriction("`invalid`")
 	}
-	_, err := c.Client.MoveMessageToDLQ(ctx, &dynamomq.MoveMessageToDLQInput{
+	result, err := c.Client.MoveMessageToDLQ(ctx, &dynamomq.MoveMessageToDLQInput{
 		ID: c.Message.ID,
 	})
 	if err != nil {
 		return err
 	}
+	if result.MovedMessage != nil {
+		c.Message = result.MovedMessage
+	}
 	fmt.Printf("Processing for ID [%s] has failed .. invalid data! Send record to DLQ!\n", c.Message.ID)
 	stats, err := c.Client.GetQueueStats(ctx, &dynamomq.GetQueueStatsInput{})
 	if err != nil {
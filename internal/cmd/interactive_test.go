@@ -40,6 +40,14 @@ func testRunInteractiveAll(t *testing.T, client dynamomq.Client[any], wantErr bo
 			name:    "run dlq",
 			command: "dlq",
 		},
+		{
+			name:    "run inflight",
+			command: "inflight",
+		},
+		{
+			name:    "run reclaim",
+			command: "reclaim",
+		},
 		{
 			name:    "run receive",
 			command: "receive",
@@ -59,6 +67,17 @@ func testRunInteractiveAll(t *testing.T, client dynamomq.Client[any], wantErr bo
 			command: "redrive",
 			message: defaultTestMessage,
 		},
+		{
+			name:    "run hold",
+			command: "hold",
+			params:  []string{"investigating", "INC-42"},
+			message: defaultTestMessage,
+		},
+		{
+			name:    "run release",
+			command: "release",
+			message: defaultTestMessage,
+		},
 		{
 			name:    "run delete",
 			command: "delete",
@@ -151,6 +170,16 @@ func TestRunInteractiveSelectedMessageID(t *testing.T) {
 			command: "redrive",
 			wantErr: true,
 		},
+		{
+			name:    "run hold should return error when message is nil",
+			command: "hold",
+			wantErr: true,
+		},
+		{
+			name:    "run release should return error when message is nil",
+			command: "release",
+			wantErr: true,
+		},
 		{
 			name:    "run delete should return error when message is nil",
 			command: "delete",
@@ -210,9 +239,9 @@ var defaultTestMessages = []*dynamomq.Message[any]{
 func TestRunInteractiveLS(t *testing.T) {
 	c := &cmd.Interactive{
 		Client: mock.Client[any]{
-			ListMessagesFunc: func(ctx context.Context, params *dynamomq.ListMessagesInput) (*dynamomq.ListMessagesOutput[any], error) {
-				return &dynamomq.ListMessagesOutput[any]{
-					Messages: defaultTestMessages,
+			ListMessageSummariesFunc: func(ctx context.Context, params *dynamomq.ListMessageSummariesInput) (*dynamomq.ListMessageSummariesOutput, error) {
+				return &dynamomq.ListMessageSummariesOutput{
+					Summaries: []dynamomq.MessageSummary{{ID: "A-101", Position: 1, QueueType: params.QueueType}},
 				}, nil
 			},
 		},
@@ -444,3 +473,67 @@ func TestRunInteractiveInvalidShouldReturnError(t *testing.T) {
 		t.Error("Run() error is not nil")
 	}
 }
+
+func TestRunInteractiveInvalidShouldKeepMovedMessage(t *testing.T) {
+	moved := dynamomq.NewMessage[any]("A-101", test.NewMessageData("A-101"), clock.Now())
+	moved.QueueType = dynamomq.QueueTypeDLQ
+	c := &cmd.Interactive{
+		Client: mock.Client[any]{
+			MoveMessageToDLQFunc: func(ctx context.Context, params *dynamomq.MoveMessageToDLQInput) (*dynamomq.MoveMessageToDLQOutput[any], error) {
+				return &dynamomq.MoveMessageToDLQOutput[any]{MovedMessage: moved}, nil
+			},
+			GetQueueStatsFunc: func(ctx context.Context, params *dynamomq.GetQueueStatsInput) (*dynamomq.GetQueueStatsOutput, error) {
+				return &dynamomq.GetQueueStatsOutput{}, nil
+			},
+		},
+		Message: dynamomq.NewMessage[any]("A-101", test.NewMessageData("A-101"), clock.Now()),
+	}
+	if err := c.Run(context.Background(), "invalid", nil); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if c.Message != moved {
+		t.Errorf("Message = %v, want the moved message %v", c.Message, moved)
+	}
+}
+
+func TestRunInteractiveAbbreviatedCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		wantErr bool
+	}{
+		{
+			name:    "should run the command of an unambiguous prefix",
+			command: "infl",
+		},
+		{
+			name:    "should run receive for rec",
+			command: "rec",
+		},
+		{
+			name:    "should run the command of a full name which is the prefix of none other",
+			command: "qstat",
+		},
+		{
+			name:    "should return error for an ambiguous prefix",
+			command: "re",
+			wantErr: true,
+		},
+		{
+			name:    "should return error for an unknown command",
+			command: "unknown",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &cmd.Interactive{
+				Client: mock.SuccessfulMockClient,
+			}
+			err := c.Run(context.Background(), tt.command, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -19,12 +19,13 @@ func (f CommandFactory) CreateInvalidCommand(flgs *Flags) *cobra.Command {
 				return err
 			}
 			id := flgs.ID
-			_, err = client.MoveMessageToDLQ(ctx, &dynamomq.MoveMessageToDLQInput{
+			result, err := client.MoveMessageToDLQ(ctx, &dynamomq.MoveMessageToDLQInput{
 				ID: id,
 			})
 			if err != nil {
 				return err
 			}
+			printMessageWithData("", result)
 			return nil
 		},
 	}
@@ -33,6 +34,6 @@ func (f CommandFactory) CreateInvalidCommand(flgs *Flags) *cobra.Command {
 func init() {
 	c := defaultCommandFactory.CreateInvalidCommand(flgs)
 	setDefaultFlags(c, flgs)
-	c.Flags().StringVar(&flgs.ID, flagMap.ID.Name, flagMap.ID.Value, flagMap.ID.Usage)
+	addIDFlag(c, flgs)
 	root.AddCommand(c)
 }
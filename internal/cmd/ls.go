@@ -2,54 +2,96 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	"github.com/vvatanabe/dynamomq"
-	"github.com/vvatanabe/dynamomq/internal/clock"
 	"github.com/vvatanabe/dynamomq/internal/constant"
 )
 
 func (f CommandFactory) CreateLSCommand(flgs *Flags) *cobra.Command {
 	return &cobra.Command{
 		Use:   "ls",
-		Short: "List all message IDs ... max 10 elements",
-		Long:  `List all message IDs ... max 10 elements.`,
+		Short: "List the first messages of the queue and the DLQ in queue order",
+		Long: `List the first messages of the queue and the DLQ in the order they are received,
+with their position, status, receive count, version and the time they were last updated.
+--limit caps the number of messages listed from each, 10 by default.
+With --tag, only the messages sent with the tag are listed, and their position counts the tagged messages only.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			asJSON, err := outputAsJSON(flgs.Output)
+			if err != nil {
+				return err
+			}
 			ctx := context.Background()
 			client, _, err := f.CreateDynamoMQClient(ctx, flgs)
 			if err != nil {
 				return err
 			}
-			out, err := client.ListMessages(ctx, &dynamomq.ListMessagesInput{Size: constant.DefaultMaxListMessages})
+			result, err := listSummaries(ctx, client, flgs.Limit, flgs.Tag)
 			if err != nil {
 				return err
 			}
-			var result LSResult
-			for _, m := range out.Messages {
-				result.Statuses = append(result.Statuses, Status{
-					ID:        m.ID,
-					Status:    m.GetStatus(clock.Now()),
-					QueueType: m.QueueType,
-				})
+			if asJSON {
+				return printJSON(cmd.OutOrStdout(), result)
 			}
-			printMessageWithData("", result)
-			return nil
+			return printSummaries(cmd.OutOrStdout(), result.Messages)
 		},
 	}
 }
 
 type LSResult struct {
-	Statuses []Status `json:"statuses"`
+	Messages []dynamomq.MessageSummary `json:"messages"`
 }
 
-type Status struct {
-	ID        string             `json:"id"`
-	Status    dynamomq.Status    `json:"status"`
-	QueueType dynamomq.QueueType `json:"queue_type"`
+// listSummaries lists the first messages of the STANDARD queue type followed by the first messages of the DLQ,
+// only those sent with the tag unless it is empty.
+func listSummaries(ctx context.Context, client dynamomq.Client[any], limit int, tag string) (*LSResult, error) {
+	if limit <= 0 {
+		limit = constant.DefaultMaxListMessages
+	}
+	result := &LSResult{Messages: make([]dynamomq.MessageSummary, 0)}
+	for _, queueType := range []dynamomq.QueueType{dynamomq.QueueTypeStandard, dynamomq.QueueTypeDLQ} {
+		out, err := client.ListMessageSummaries(ctx, &dynamomq.ListMessageSummariesInput{
+			QueueType: queueType,
+			Limit:     int32(limit),
+			Tag:       tag,
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.Messages = append(result.Messages, out.Summaries...)
+	}
+	return result, nil
+}
+
+func printSummaries(w io.Writer, summaries []dynamomq.MessageSummary) error {
+	if len(summaries) == 0 {
+		_, err := fmt.Fprintln(w, "Queue is empty!")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "QUEUE TYPE\tPOSITION\tID\tSTATUS\tQUEUED\tDLQ\tRECEIVE COUNT\tVERSION\tLAST UPDATED")
+	for _, s := range summaries {
+		status := string(s.Status)
+		if s.Held {
+			status += " (held)"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%t\t%t\t%d\t%d\t%s\n", s.QueueType, s.Position, s.ID, status,
+			s.Queued, s.DLQ, s.ReceiveCount, s.Version, s.LastUpdatedTimestamp)
+	}
+	return tw.Flush()
 }
 
 func init() {
 	c := defaultCommandFactory.CreateLSCommand(flgs)
 	setDefaultFlags(c, flgs)
+	c.Flags().StringVar(&flgs.IndexName, flagMap.IndexName.Name, flagMap.IndexName.Value, flagMap.IndexName.Usage)
+	// The flag shares its variable with the other commands, so its default is kept at zero, which lists 10 messages.
+	c.Flags().IntVar(&flgs.Limit, flagMap.Limit.Name, flagMap.Limit.Value,
+		"The maximum number of messages to list from the queue and from the DLQ. Zero means 10.")
+	c.Flags().StringVar(&flgs.Tag, flagMap.Tag.Name, flagMap.Tag.Value, flagMap.Tag.Usage)
+	addOutputFlag(c, flgs)
 	root.AddCommand(c)
 }
@@ -0,0 +1,108 @@
+package cmd_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/cmd"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestCommandFactoryCreateLSCommand(t *testing.T) {
+	summaries := map[dynamomq.QueueType][]dynamomq.MessageSummary{
+		dynamomq.QueueTypeStandard: {
+			{
+				ID: "A-101", Position: 1, QueueType: dynamomq.QueueTypeStandard, Status: dynamomq.StatusReady, Queued: true,
+				Version: 1, LastUpdatedTimestamp: "2023-12-01T00:00:00Z",
+			},
+			{
+				ID: "A-202", Position: 2, QueueType: dynamomq.QueueTypeStandard, Status: dynamomq.StatusReady, Held: true,
+				ReceiveCount: 1, Version: 3, LastUpdatedTimestamp: "2023-12-01T00:00:01Z",
+			},
+		},
+		dynamomq.QueueTypeDLQ: {
+			{
+				ID: "B-101", Position: 1, QueueType: dynamomq.QueueTypeDLQ, Status: dynamomq.StatusReady, DLQ: true,
+				ReceiveCount: 5, Version: 7, LastUpdatedTimestamp: "2023-11-30T00:00:00Z",
+			},
+		},
+	}
+	tests := []struct {
+		name      string
+		flgs      *cmd.Flags
+		summaries map[dynamomq.QueueType][]dynamomq.MessageSummary
+		listErr   error
+		want      string
+		wantLimit int32
+		wantTag   string
+		wantErr   bool
+	}{
+		{
+			name:      "should list the queue and the DLQ in queue order",
+			flgs:      &cmd.Flags{},
+			summaries: summaries,
+			want: `QUEUE TYPE  POSITION  ID     STATUS        QUEUED  DLQ    RECEIVE COUNT  VERSION  LAST UPDATED
+STANDARD    1         A-101  READY         true    false  0              1        2023-12-01T00:00:00Z
+STANDARD    2         A-202  READY (held)  false   false  1              3        2023-12-01T00:00:01Z
+DLQ         1         B-101  READY         false   true   5              7        2023-11-30T00:00:00Z
+`,
+			wantLimit: 10,
+		},
+		{
+			name:      "should print that the queue is empty",
+			flgs:      &cmd.Flags{Limit: 3},
+			want:      "Queue is empty!\n",
+			wantLimit: 3,
+		},
+		{
+			name:      "should list only the messages with the tag",
+			flgs:      &cmd.Flags{Tag: "backfill"},
+			want:      "Queue is empty!\n",
+			wantLimit: 10,
+			wantTag:   "backfill",
+		},
+		{
+			name:    "should fail when the messages cannot be listed",
+			flgs:    &cmd.Flags{},
+			listErr: test.ErrTest,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				limits []int32
+				tags   []string
+			)
+			f := cmd.CommandFactory{
+				CreateDynamoMQClient: func(ctx context.Context, flags *cmd.Flags) (dynamomq.Client[any], aws.Config, error) {
+					return mock.Client[any]{
+						ListMessageSummariesFunc: func(ctx context.Context, params *dynamomq.ListMessageSummariesInput) (*dynamomq.ListMessageSummariesOutput, error) {
+							limits = append(limits, params.Limit)
+							tags = append(tags, params.Tag)
+							if tt.listErr != nil {
+								return nil, tt.listErr
+							}
+							return &dynamomq.ListMessageSummariesOutput{Summaries: tt.summaries[params.QueueType]}, nil
+						},
+					}, aws.Config{}, nil
+				},
+			}
+			got, err := runCommand(t, f.CreateLSCommand(tt.flgs))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RunE() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RunE() printed\n%s\nwant\n%s", got, tt.want)
+			}
+			if tt.wantErr {
+				return
+			}
+			test.AssertDeepEqual(t, limits, []int32{tt.wantLimit, tt.wantLimit}, "ListMessageSummaries() limits")
+			test.AssertDeepEqual(t, tags, []string{tt.wantTag, tt.wantTag}, "ListMessageSummaries() tags")
+		})
+	}
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 
 	"github.com/vvatanabe/dynamomq"
 )
@@ -24,6 +25,28 @@ func marshalIndent(v any) ([]byte, error) {
 	return dump, nil
 }
 
+// printJSON writes v to w as indented JSON.
+func printJSON(w io.Writer, v any) error {
+	dump, err := marshalIndent(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", dump)
+	return err
+}
+
+// outputAsJSON reports whether the output format given with --output is JSON.
+func outputAsJSON(output string) (bool, error) {
+	switch output {
+	case "", outputText:
+		return false, nil
+	case outputJSON:
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid --output %q, want %s or %s", output, outputText, outputJSON)
+	}
+}
+
 func printError(err any) {
 	fmt.Printf("ERROR: %v\n", err)
 }
@@ -12,34 +12,45 @@ func (f CommandFactory) CreatePurgeCommand(flgs *Flags) *cobra.Command {
 	return &cobra.Command{
 		Use:   "purge",
 		Short: "It will remove all message from DynamoMQ table",
-		Long:  `It will remove all message from DynamoMQ table.`,
+		Long: `It will remove all message from DynamoMQ table.
+With --tag, only the messages sent with the tag are removed, and the others are left intact.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
 			client, _, err := f.CreateDynamoMQClient(ctx, flgs)
 			if err != nil {
 				return err
 			}
-			out, err := client.ListMessages(ctx, &dynamomq.ListMessagesInput{Size: constant.DefaultMaxListMessages})
-			if err != nil {
-				return err
-			}
-			var result PurgeResult
-			if len(out.Messages) == 0 {
-				printMessageWithData("", result)
-				return nil
-			}
-			for _, m := range out.Messages {
-				_, delErr := client.DeleteMessage(ctx, &dynamomq.DeleteMessageInput{
-					ID: m.ID,
+			var (
+				result    PurgeResult
+				nextToken string
+			)
+			for {
+				out, err := client.ListMessages(ctx, &dynamomq.ListMessagesInput{
+					Size:      constant.DefaultMaxListMessages,
+					OmitData:  true,
+					Tag:       flgs.Tag,
+					NextToken: nextToken,
 				})
-				if delErr != nil {
-					result.Failures = append(result.Failures, Failure{
-						ID:    m.ID,
-						Error: delErr,
+				if err != nil {
+					return err
+				}
+				for _, m := range out.Messages {
+					_, delErr := client.DeleteMessage(ctx, &dynamomq.DeleteMessageInput{
+						ID: m.ID,
 					})
-					continue
+					if delErr != nil {
+						result.Failures = append(result.Failures, Failure{
+							ID:    m.ID,
+							Error: delErr,
+						})
+						continue
+					}
+					result.Successes = append(result.Successes, m.ID)
+				}
+				if out.NextToken == "" {
+					break
 				}
-				result.Successes = append(result.Successes, m.ID)
+				nextToken = out.NextToken
 			}
 			printMessageWithData("", result)
 			return nil
@@ -60,5 +71,6 @@ type Failure struct {
 func init() {
 	c := defaultCommandFactory.CreatePurgeCommand(flgs)
 	setDefaultFlags(c, flgs)
+	c.Flags().StringVar(&flgs.Tag, flagMap.Tag.Name, flagMap.Tag.Value, flagMap.Tag.Usage)
 	root.AddCommand(c)
 }
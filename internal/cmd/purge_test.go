@@ -2,13 +2,17 @@ package cmd_test
 
 import (
 	"context"
+	"slices"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/google/uuid"
 	"github.com/spf13/cobra"
+	"github.com/upsidr/dynamotest"
 	"github.com/vvatanabe/dynamomq"
 	"github.com/vvatanabe/dynamomq/internal/clock"
 	"github.com/vvatanabe/dynamomq/internal/cmd"
+	"github.com/vvatanabe/dynamomq/internal/constant"
 	"github.com/vvatanabe/dynamomq/internal/mock"
 	"github.com/vvatanabe/dynamomq/internal/test"
 )
@@ -69,3 +73,122 @@ func TestPurgeCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestPurgeCommandTag(t *testing.T) {
+	tagged := dynamomq.NewMessage[any]("A-101", nil, clock.Now())
+	tagged.Tags = []string{"backfill"}
+	untagged := dynamomq.NewMessage[any]("A-102", nil, clock.Now())
+	var (
+		listed  []*dynamomq.ListMessagesInput
+		deleted []string
+	)
+	f := cmd.CommandFactory{
+		CreateDynamoMQClient: func(ctx context.Context, flags *cmd.Flags) (dynamomq.Client[any], aws.Config, error) {
+			return mock.Client[any]{
+				ListMessagesFunc: func(ctx context.Context, params *dynamomq.ListMessagesInput) (*dynamomq.ListMessagesOutput[any], error) {
+					listed = append(listed, params)
+					out := &dynamomq.ListMessagesOutput[any]{DataOmitted: params.OmitData}
+					for _, m := range []*dynamomq.Message[any]{tagged, untagged} {
+						if params.Tag == "" || slices.Contains(m.Tags, params.Tag) {
+							out.Messages = append(out.Messages, m)
+						}
+					}
+					return out, nil
+				},
+				DeleteMessageFunc: func(ctx context.Context, params *dynamomq.DeleteMessageInput) (*dynamomq.DeleteMessageOutput, error) {
+					deleted = append(deleted, params.ID)
+					return &dynamomq.DeleteMessageOutput{}, nil
+				},
+			}, aws.Config{}, nil
+		},
+	}
+	if err := f.CreatePurgeCommand(&cmd.Flags{Tag: "backfill"}).RunE(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("RunE() error = %v", err)
+	}
+	if len(listed) != 1 || listed[0].Tag != "backfill" {
+		t.Fatalf("ListMessages() inputs = %+v, want one with the tag", listed)
+	}
+	test.AssertDeepEqual(t, deleted, []string{"A-101"}, "DeleteMessage() IDs")
+}
+
+func TestPurgeCommandPages(t *testing.T) {
+	pages := map[string]*dynamomq.ListMessagesOutput[any]{
+		"": {
+			Messages:  []*dynamomq.Message[any]{dynamomq.NewMessage[any]("A-101", nil, clock.Now())},
+			NextToken: "page-2",
+		},
+		"page-2": {NextToken: "page-3"},
+		"page-3": {
+			Messages: []*dynamomq.Message[any]{dynamomq.NewMessage[any]("A-102", nil, clock.Now())},
+		},
+	}
+	var (
+		tokens  []string
+		deleted []string
+	)
+	f := cmd.CommandFactory{
+		CreateDynamoMQClient: func(ctx context.Context, flags *cmd.Flags) (dynamomq.Client[any], aws.Config, error) {
+			return mock.Client[any]{
+				ListMessagesFunc: func(ctx context.Context, params *dynamomq.ListMessagesInput) (*dynamomq.ListMessagesOutput[any], error) {
+					tokens = append(tokens, params.NextToken)
+					return pages[params.NextToken], nil
+				},
+				DeleteMessageFunc: func(ctx context.Context, params *dynamomq.DeleteMessageInput) (*dynamomq.DeleteMessageOutput, error) {
+					deleted = append(deleted, params.ID)
+					return &dynamomq.DeleteMessageOutput{}, nil
+				},
+			}, aws.Config{}, nil
+		},
+	}
+	if err := f.CreatePurgeCommand(&cmd.Flags{}).RunE(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("RunE() error = %v", err)
+	}
+	test.AssertDeepEqual(t, tokens, []string{"", "page-2", "page-3"}, "ListMessages() tokens")
+	test.AssertDeepEqual(t, deleted, []string{"A-101", "A-102"}, "DeleteMessage() IDs")
+}
+
+func TestPurgeCommandTagAgainstDynamoDB(t *testing.T) {
+	raw, clean := dynamotest.NewDynamoDB(t)
+	defer clean()
+	tableName := constant.DefaultTableName + "-" + uuid.NewString()
+	dynamotest.PrepTable(t, raw, dynamotest.InitialTableSetup{
+		Table: dynamomq.NewCreateTableInput(&dynamomq.CreateQueueTableInput{
+			TableName: tableName,
+		}),
+	})
+	client, err := dynamomq.NewFromConfig[any](aws.Config{},
+		dynamomq.WithDynamoDBAPI(raw),
+		dynamomq.WithTableName(tableName))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	ctx := context.Background()
+	for id, tags := range map[string][]string{
+		"A-101": {"backfill-2024-06"},
+		"A-102": {"backfill-2024-06", "priority"},
+		"A-103": {"priority"},
+		"A-104": nil,
+	} {
+		if _, err := client.SendMessage(ctx, &dynamomq.SendMessageInput[any]{ID: id, Data: test.NewMessageData(id), Tags: tags}); err != nil {
+			t.Fatalf("SendMessage() error = %v", err)
+		}
+	}
+	f := cmd.CommandFactory{
+		CreateDynamoMQClient: func(ctx context.Context, flags *cmd.Flags) (dynamomq.Client[any], aws.Config, error) {
+			return client, aws.Config{}, nil
+		},
+	}
+	if err := f.CreatePurgeCommand(&cmd.Flags{Tag: "backfill-2024-06"}).RunE(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("RunE() error = %v", err)
+	}
+	out, err := client.ListMessages(ctx, &dynamomq.ListMessagesInput{OmitData: true})
+	if err != nil {
+		t.Fatalf("ListMessages() error = %v", err)
+	}
+	var left []string
+	for _, m := range out.Messages {
+		left = append(left, m.ID)
+	}
+	slices.Sort(left)
+	test.AssertDeepEqual(t, left, []string{"A-103", "A-104"}, "messages left after the purge")
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"github.com/vvatanabe/dynamomq"
+)
+
+func (f CommandFactory) CreateReclaimCommand(flgs *Flags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "reclaim",
+		Short: "Make the messages whose visibility timeout has expired visible again",
+		Long:  `Make the messages whose visibility timeout has expired visible again, and print their IDs.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return f.executeStatsCommand(flgs, func(ctx context.Context, client dynamomq.Client[any]) (any, error) {
+				return client.ReclaimExpiredMessages(ctx, &dynamomq.ReclaimExpiredMessagesInput{
+					Limit: flgs.Limit,
+				})
+			})
+		},
+	}
+}
+
+func init() {
+	c := defaultCommandFactory.CreateReclaimCommand(flgs)
+	setDefaultFlags(c, flgs)
+	c.Flags().StringVar(&flgs.IndexName, flagMap.IndexName.Name, flagMap.IndexName.Value, flagMap.IndexName.Usage)
+	c.Flags().IntVar(&flgs.Limit, flagMap.Limit.Name, flagMap.Limit.Value, flagMap.Limit.Usage)
+	root.AddCommand(c)
+}
@@ -2,37 +2,86 @@ package cmd
 
 import (
 	"context"
+	"errors"
+	"io"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/vvatanabe/dynamomq"
 )
 
 func (f CommandFactory) CreateRedriveCommand(flgs *Flags) *cobra.Command {
 	return &cobra.Command{
-		Use:   "redrive",
+		Use:   "redrive [id]",
 		Short: "Redrive a message to STANDARD from DLQ",
-		Long:  `Redrive a message to STANDARD from DLQ.`,
+		Long: `Redrive a message to STANDARD from DLQ.
+With --delay, the message cannot be received until the delay has passed.
+With --reset-receive-count, its receive count is zeroed so that it gets the full number of receives again.`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: f.completeMessageIDs(flgs, 1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
 			client, _, err := f.CreateDynamoMQClient(ctx, flgs)
 			if err != nil {
 				return err
 			}
-			result, err := client.RedriveMessage(ctx, &dynamomq.RedriveMessageInput{
-				ID: flgs.ID,
+			id := idOf(flgs, args)
+			result, err := redriveMessage(ctx, client, &dynamomq.RedriveMessageInput{
+				ID:                id,
+				DelaySeconds:      flgs.Delay,
+				ResetReceiveCount: flgs.ResetReceiveCount,
 			})
 			if err != nil {
 				return err
 			}
-			printMessageWithData("", result)
+			printRedriven(result)
 			return nil
 		},
 	}
 }
 
+// redriveMessage redrives the message, naming it in the error if it is not found.
+func redriveMessage(ctx context.Context, client dynamomq.Client[any],
+	params *dynamomq.RedriveMessageInput) (*dynamomq.RedriveMessageOutput[any], error) {
+	result, err := client.RedriveMessage(ctx, params)
+	if err != nil {
+		return nil, notFoundWithID(err, params.ID)
+	}
+	return result, nil
+}
+
+// printRedriven prints the status and the version of the redriven message.
+func printRedriven(result *dynamomq.RedriveMessageOutput[any]) {
+	if result.RedroveMessage == nil {
+		printMessageWithData("", result)
+		return
+	}
+	printMessageWithData("Redriven system info:\n", GetSystemInfo(result.RedroveMessage))
+}
+
+// parseRedriveParams parses the parameters of the redrive command of the Interactive mode, an optional message ID
+// followed by the flags of the redrive subcommand.
+func parseRedriveParams(params []string) (string, *dynamomq.RedriveMessageInput, error) {
+	fs := pflag.NewFlagSet("redrive", pflag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	input := &dynamomq.RedriveMessageInput{}
+	fs.IntVar(&input.DelaySeconds, flagMap.Delay.Name, flagMap.Delay.Value, flagMap.Delay.Usage)
+	fs.BoolVar(&input.ResetReceiveCount, flagMap.ResetReceiveCount.Name, flagMap.ResetReceiveCount.Value, flagMap.ResetReceiveCount.Usage)
+	if err := fs.Parse(params); err != nil {
+		return "", nil, err
+	}
+	if fs.NArg() > 1 {
+		return "", nil, errors.New("redrive accepts at most one message ID")
+	}
+	return fs.Arg(0), input, nil
+}
+
 func init() {
 	c := defaultCommandFactory.CreateRedriveCommand(flgs)
 	setDefaultFlags(c, flgs)
 	c.Flags().StringVar(&flgs.IndexName, flagMap.IndexName.Name, flagMap.IndexName.Value, flagMap.IndexName.Usage)
+	addIDFlag(c, flgs)
+	c.Flags().IntVar(&flgs.Delay, flagMap.Delay.Name, flagMap.Delay.Value, flagMap.Delay.Usage)
+	c.Flags().BoolVar(&flgs.ResetReceiveCount, flagMap.ResetReceiveCount.Name, flagMap.ResetReceiveCount.Value, flagMap.ResetReceiveCount.Usage)
 	root.AddCommand(c)
 }
@@ -0,0 +1,210 @@
+package cmd_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/upsidr/dynamotest"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+	"github.com/vvatanabe/dynamomq/internal/cmd"
+	"github.com/vvatanabe/dynamomq/internal/constant"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+// newRedriveClient returns a client recording the redrives into redrives and failing them with redriveErr.
+func newRedriveClient(redrives *[]*dynamomq.RedriveMessageInput, redriveErr error) dynamomq.Client[any] {
+	return mock.Client[any]{
+		RedriveMessageFunc: func(ctx context.Context, params *dynamomq.RedriveMessageInput) (*dynamomq.RedriveMessageOutput[any], error) {
+			*redrives = append(*redrives, params)
+			if redriveErr != nil {
+				return &dynamomq.RedriveMessageOutput[any]{}, redriveErr
+			}
+			message := dynamomq.NewMessage[any](params.ID, test.NewMessageData(params.ID), test.DefaultTestDate)
+			message.Version = 2
+			return &dynamomq.RedriveMessageOutput[any]{RedroveMessage: message}, nil
+		},
+	}
+}
+
+func TestCommandFactoryCreateRedriveCommand(t *testing.T) {
+	stateErr := dynamomq.InvalidStateTransitionError{
+		Msg:       "can only redrive messages from DLQ",
+		Operation: "mark as restored from DLQ",
+		Current:   dynamomq.StatusReady,
+		Requested: dynamomq.StatusReady,
+	}
+	tests := []struct {
+		name       string
+		flgs       *cmd.Flags
+		args       []string
+		redriveErr error
+		want       *dynamomq.RedriveMessageInput
+		wantErr    error
+	}{
+		{
+			name: "should redrive the message of the id flag",
+			flgs: &cmd.Flags{ID: "A-101"},
+			want: &dynamomq.RedriveMessageInput{ID: "A-101"},
+		},
+		{
+			name: "should redrive the message of the argument with a delay and a reset receive count",
+			flgs: &cmd.Flags{ID: "A-101", Delay: 60, ResetReceiveCount: true},
+			args: []string{"A-202"},
+			want: &dynamomq.RedriveMessageInput{ID: "A-202", DelaySeconds: 60, ResetReceiveCount: true},
+		},
+		{
+			name:       "should return IDNotFoundError when the message is not found",
+			flgs:       &cmd.Flags{},
+			args:       []string{"A-101"},
+			redriveErr: &dynamomq.IDNotFoundError{},
+			want:       &dynamomq.RedriveMessageInput{ID: "A-101"},
+			wantErr:    dynamomq.ErrIDNotFound,
+		},
+		{
+			name:       "should return InvalidStateTransitionError when the message is not in the DLQ",
+			flgs:       &cmd.Flags{},
+			args:       []string{"A-101"},
+			redriveErr: stateErr,
+			want:       &dynamomq.RedriveMessageInput{ID: "A-101"},
+			wantErr:    stateErr,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var redrives []*dynamomq.RedriveMessageInput
+			f := cmd.CommandFactory{
+				CreateDynamoMQClient: func(ctx context.Context, flags *cmd.Flags) (dynamomq.Client[any], aws.Config, error) {
+					return newRedriveClient(&redrives, tt.redriveErr), aws.Config{}, nil
+				},
+			}
+			err := f.CreateRedriveCommand(tt.flgs).RunE(&cobra.Command{}, tt.args)
+			test.AssertError(t, err, tt.wantErr, "RunE()")
+			if len(redrives) != 1 {
+				t.Fatalf("RedriveMessage() calls = %d, want 1", len(redrives))
+			}
+			test.AssertDeepEqual(t, redrives[0], tt.want, "RedriveMessage()")
+		})
+	}
+}
+
+func TestRunInteractiveRedrive(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  []string
+		message *dynamomq.Message[any]
+		want    *dynamomq.RedriveMessageInput
+		wantErr bool
+	}{
+		{
+			name:    "should redrive the current message",
+			params:  []string{"--delay", "60"},
+			message: dynamomq.NewMessage[any]("A-101", test.NewMessageData("A-101"), test.DefaultTestDate),
+			want:    &dynamomq.RedriveMessageInput{ID: "A-101", DelaySeconds: 60},
+		},
+		{
+			name:   "should redrive the message of the id without a current message",
+			params: []string{"A-202", "--reset-receive-count"},
+			want:   &dynamomq.RedriveMessageInput{ID: "A-202", ResetReceiveCount: true},
+		},
+		{
+			name:    "should return error when the delay is not a number",
+			params:  []string{"A-202", "--delay", "soon"},
+			wantErr: true,
+		},
+		{
+			name:    "should return error when several ids are given",
+			params:  []string{"A-202", "A-303"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var redrives []*dynamomq.RedriveMessageInput
+			c := &cmd.Interactive{
+				Client:  newRedriveClient(&redrives, nil),
+				Message: tt.message,
+			}
+			err := c.Run(context.Background(), "redrive", tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if len(redrives) != 0 {
+					t.Errorf("RedriveMessage() calls = %d, want none", len(redrives))
+				}
+				return
+			}
+			if len(redrives) != 1 {
+				t.Fatalf("RedriveMessage() calls = %d, want 1", len(redrives))
+			}
+			test.AssertDeepEqual(t, redrives[0], tt.want, "RedriveMessage()")
+			if c.Message == nil || c.Message.ID != tt.want.ID || c.Message.Version != 2 {
+				t.Errorf("Run() current message = %v, want the redriven message %s", c.Message, tt.want.ID)
+			}
+		})
+	}
+}
+
+func TestRedriveCommandAgainstDynamoDB(t *testing.T) {
+	raw, clean := dynamotest.NewDynamoDB(t)
+	defer clean()
+	tableName := constant.DefaultTableName + "-" + uuid.NewString()
+	dynamotest.PrepTable(t, raw, dynamotest.InitialTableSetup{
+		Table: dynamomq.NewCreateTableInput(&dynamomq.CreateQueueTableInput{
+			TableName: tableName,
+		}),
+	})
+	client, err := dynamomq.NewFromConfig[any](aws.Config{},
+		dynamomq.WithDynamoDBAPI(raw),
+		dynamomq.WithTableName(tableName))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	ctx := context.Background()
+	if _, err := client.SendMessage(ctx, &dynamomq.SendMessageInput[any]{ID: "A-101", Data: test.NewMessageData("A-101")}); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if _, err := client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{}); err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	if _, err := client.MoveMessageToDLQ(ctx, &dynamomq.MoveMessageToDLQInput{ID: "A-101"}); err != nil {
+		t.Fatalf("MoveMessageToDLQ() error = %v", err)
+	}
+	f := cmd.CommandFactory{
+		CreateDynamoMQClient: func(ctx context.Context, flags *cmd.Flags) (dynamomq.Client[any], aws.Config, error) {
+			return client, aws.Config{}, nil
+		},
+	}
+	redrive := func(args ...string) error {
+		return f.CreateRedriveCommand(&cmd.Flags{Delay: 60, ResetReceiveCount: true}).RunE(&cobra.Command{}, args)
+	}
+
+	before := time.Now()
+	if err := redrive("A-101"); err != nil {
+		t.Fatalf("RunE() error = %v", err)
+	}
+	got, err := client.GetMessage(ctx, &dynamomq.GetMessageInput{ID: "A-101"})
+	if err != nil {
+		t.Fatalf("GetMessage() error = %v", err)
+	}
+	if got.Message.QueueType != dynamomq.QueueTypeStandard || got.Message.ReceiveCount != 0 {
+		t.Errorf("redriven message queue type = %s, receive count = %d, want STANDARD and 0",
+			got.Message.QueueType, got.Message.ReceiveCount)
+	}
+	if sentAt := clock.RFC3339NanoToTime(got.Message.SentAt); sentAt.Before(before.Add(60 * time.Second)) {
+		t.Errorf("redriven message sent at %s, want it delayed by 60 seconds", got.Message.SentAt)
+	}
+
+	var stateErr dynamomq.InvalidStateTransitionError
+	if err := redrive("A-101"); !errors.As(err, &stateErr) {
+		t.Errorf("RunE() error = %v, want an InvalidStateTransitionError for a message not in the DLQ", err)
+	}
+	test.AssertError(t, redrive("B-101"), dynamomq.ErrIDNotFound, "RunE()")
+}
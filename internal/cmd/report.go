@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/spf13/cobra"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+)
+
+const (
+	defaultReportSampleSize = 100
+	maxReportSampleSize     = 1000
+)
+
+// report combines the statistics of a queue for capacity planning. The figures of sample are approximate,
+// as they are computed from the messages read by a single bounded scan; the other figures are exact.
+type report struct {
+	TableName   string       `json:"table_name"`
+	GeneratedAt string       `json:"generated_at"`
+	Queue       reportQueue  `json:"queue"`
+	DLQ         reportDLQ    `json:"dlq"`
+	Sample      reportSample `json:"sample"`
+}
+
+type reportQueue struct {
+	Messages   int `json:"messages"`
+	Ready      int `json:"ready"`
+	Processing int `json:"processing"`
+	Held       int `json:"held"`
+	// OldestReadyID is the ID of the message waiting the longest to be received, or empty if none is ready.
+	OldestReadyID         string `json:"oldest_ready_id,omitempty"`
+	OldestReadyAgeSeconds int64  `json:"oldest_ready_age_seconds"`
+}
+
+type reportDLQ struct {
+	Messages int `json:"messages"`
+	// OldestID is the ID of the message moved to the DLQ the longest ago, or empty if the DLQ is empty.
+	OldestID         string `json:"oldest_id,omitempty"`
+	OldestAgeSeconds int64  `json:"oldest_age_seconds"`
+}
+
+type reportSample struct {
+	Approximate bool `json:"approximate"`
+	// SampleSize is the maximum number of items read to take the sample.
+	SampleSize int `json:"sample_size"`
+	// Messages is the number of messages in the sample.
+	Messages      int                  `json:"messages"`
+	ReceiveCounts []receiveCountBucket `json:"receive_counts"`
+	// ItemSizeBytes are percentiles of the estimated size of the items, as DynamoDB counts it for the capacity.
+	ItemSizeBytes itemSizePercentiles `json:"item_size_bytes"`
+}
+
+type receiveCountBucket struct {
+	ReceiveCount int `json:"receive_count"`
+	Messages     int `json:"messages"`
+}
+
+type itemSizePercentiles struct {
+	P50 int `json:"p50"`
+	P90 int `json:"p90"`
+	P99 int `json:"p99"`
+	Max int `json:"max"`
+}
+
+func (f CommandFactory) CreateReportCommand(flgs *Flags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "report",
+		Short: "Print a summary of the queue for capacity planning",
+		Long: `Print a summary of the queue for capacity planning, which can be pasted into an incident document.
+The number of messages in the queue and the DLQ and the ages of their oldest messages are exact.
+The histogram of the receive counts and the percentiles of the item sizes are approximate: they are computed
+from a sample read by a single scan of at most --sample-size items, which bounds the cost of the report.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			asJSON, err := outputAsJSON(flgs.Output)
+			if err != nil {
+				return err
+			}
+			if flgs.SampleSize <= 0 || flgs.SampleSize > maxReportSampleSize {
+				return fmt.Errorf("invalid --sample-size %d, want 1 to %d", flgs.SampleSize, maxReportSampleSize)
+			}
+			ctx := context.Background()
+			client, _, err := f.CreateDynamoMQClient(ctx, flgs)
+			if err != nil {
+				return err
+			}
+			r, err := buildReport(ctx, client, flgs.TableName, flgs.SampleSize, f.now())
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				return printJSON(cmd.OutOrStdout(), r)
+			}
+			return printReport(cmd.OutOrStdout(), r)
+		},
+	}
+}
+
+func buildReport(ctx context.Context, client dynamomq.Client[any], tableName string, sampleSize int, now time.Time) (*report, error) {
+	r := &report{
+		TableName:   tableName,
+		GeneratedAt: clock.FormatRFC3339Nano(now),
+	}
+	stats, err := client.GetQueueStats(ctx, &dynamomq.GetQueueStatsInput{})
+	if err != nil {
+		return nil, err
+	}
+	r.Queue = reportQueue{
+		Messages:   stats.TotalMessagesInQueue,
+		Ready:      stats.TotalMessagesInQueueReady,
+		Processing: stats.TotalMessagesInQueueProcessing,
+		Held:       stats.TotalMessagesInQueueHeld,
+	}
+	// The IDs in the queue are listed in the order they are received, so the first one is the oldest.
+	if len(stats.First100IDsInQueue) > 0 {
+		id := stats.First100IDsInQueue[0]
+		got, err := client.GetMessage(ctx, &dynamomq.GetMessageInput{ID: id})
+		if err != nil {
+			return nil, err
+		}
+		if got.Message != nil {
+			if age, err := got.Message.Age(now); err == nil {
+				r.Queue.OldestReadyID, r.Queue.OldestReadyAgeSeconds = id, int64(age/time.Second)
+			}
+		}
+	}
+	dlq, err := client.GetDLQStats(ctx, &dynamomq.GetDLQStatsInput{})
+	if err != nil {
+		return nil, err
+	}
+	r.DLQ.Messages = dlq.TotalMessagesInDLQ
+	var oldest time.Time
+	for _, entry := range dlq.Entries {
+		movedAt, err := clock.ParseRFC3339Nano(entry.MovedToDLQAt)
+		if err != nil {
+			continue
+		}
+		if r.DLQ.OldestID == "" || movedAt.Before(oldest) {
+			r.DLQ.OldestID, oldest = entry.ID, movedAt
+		}
+	}
+	if r.DLQ.OldestID != "" {
+		r.DLQ.OldestAgeSeconds = int64(now.Sub(oldest) / time.Second)
+	}
+	listed, err := client.ListMessages(ctx, &dynamomq.ListMessagesInput{Size: int32(sampleSize)})
+	if err != nil {
+		return nil, err
+	}
+	r.Sample, err = sampleMessages(listed.Messages, sampleSize)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func sampleMessages(messages []*dynamomq.Message[any], sampleSize int) (reportSample, error) {
+	sample := reportSample{
+		Approximate:   true,
+		SampleSize:    sampleSize,
+		Messages:      len(messages),
+		ReceiveCounts: make([]receiveCountBucket, 0),
+	}
+	counts := make(map[int]int)
+	sizes := make([]int, 0, len(messages))
+	for _, m := range messages {
+		counts[m.ReceiveCount]++
+		item, err := m.MarshalMap()
+		if err != nil {
+			return sample, err
+		}
+		sizes = append(sizes, itemSize(item))
+	}
+	for receiveCount, n := range counts {
+		sample.ReceiveCounts = append(sample.ReceiveCounts, receiveCountBucket{ReceiveCount: receiveCount, Messages: n})
+	}
+	sort.Slice(sample.ReceiveCounts, func(i, j int) bool {
+		return sample.ReceiveCounts[i].ReceiveCount < sample.ReceiveCounts[j].ReceiveCount
+	})
+	sort.Ints(sizes)
+	sample.ItemSizeBytes = itemSizePercentiles{
+		P50: percentile(sizes, 50),
+		P90: percentile(sizes, 90),
+		P99: percentile(sizes, 99),
+		Max: percentile(sizes, 100),
+	}
+	return sample, nil
+}
+
+// percentile returns the nearest-rank percentile of the sorted values, or zero if there is none.
+func percentile(sorted []int, p int) int {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+// itemSize estimates the size of an item as DynamoDB counts it: the names of the attributes and their values,
+// with numbers taking about one byte per two digits, and three bytes of overhead for lists and maps,
+// plus one byte for each of their elements.
+func itemSize(item map[string]types.AttributeValue) int {
+	var size int
+	for name, value := range item {
+		size += len(name) + attributeValueSize(value)
+	}
+	return size
+}
+
+func attributeValueSize(value types.AttributeValue) int {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		return len(v.Value)
+	case *types.AttributeValueMemberN:
+		return numberSize(v.Value)
+	case *types.AttributeValueMemberB:
+		return len(v.Value)
+	case *types.AttributeValueMemberBOOL, *types.AttributeValueMemberNULL:
+		return 1
+	case *types.AttributeValueMemberSS:
+		var size int
+		for _, s := range v.Value {
+			size += len(s)
+		}
+		return size
+	case *types.AttributeValueMemberNS:
+		var size int
+		for _, n := range v.Value {
+			size += numberSize(n)
+		}
+		return size
+	case *types.AttributeValueMemberBS:
+		var size int
+		for _, b := range v.Value {
+			size += len(b)
+		}
+		return size
+	case *types.AttributeValueMemberL:
+		size := 3
+		for _, e := range v.Value {
+			size += 1 + attributeValueSize(e)
+		}
+		return size
+	case *types.AttributeValueMemberM:
+		size := 3
+		for name, e := range v.Value {
+			size += 1 + len(name) + attributeValueSize(e)
+		}
+		return size
+	default:
+		return 0
+	}
+}
+
+func numberSize(n string) int {
+	return (len(n)+1)/2 + 1
+}
+
+func printReport(w io.Writer, r *report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "DynamoMQ report of table '%s' at %s\n\n", r.TableName, r.GeneratedAt)
+	fmt.Fprintln(tw, "Queue")
+	fmt.Fprintf(tw, "  Messages in queue:\t%d\n", r.Queue.Messages)
+	fmt.Fprintf(tw, "    Ready:\t%d\n", r.Queue.Ready)
+	fmt.Fprintf(tw, "    Processing:\t%d\n", r.Queue.Processing)
+	fmt.Fprintf(tw, "    Held:\t%d\n", r.Queue.Held)
+	fmt.Fprintf(tw, "  Oldest ready message:\t%s\n", formatAge(r.Queue.OldestReadyID, r.Queue.OldestReadyAgeSeconds))
+	fmt.Fprintln(tw, "")
+	fmt.Fprintln(tw, "Dead Letter Queue")
+	fmt.Fprintf(tw, "  Messages in DLQ:\t%d\n", r.DLQ.Messages)
+	fmt.Fprintf(tw, "  Oldest message:\t%s\n", formatAge(r.DLQ.OldestID, r.DLQ.OldestAgeSeconds))
+	fmt.Fprintln(tw, "")
+	fmt.Fprintf(tw, "Sample (APPROXIMATE: %d messages read by a scan of at most %d items)\n",
+		r.Sample.Messages, r.Sample.SampleSize)
+	fmt.Fprintln(tw, "  Receive count\tMessages")
+	for _, bucket := range r.Sample.ReceiveCounts {
+		fmt.Fprintf(tw, "  %d\t%d\n", bucket.ReceiveCount, bucket.Messages)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	sizes := r.Sample.ItemSizeBytes
+	_, err := fmt.Fprintf(w, "  Item size (bytes): p50 %d, p90 %d, p99 %d, max %d\n",
+		sizes.P50, sizes.P90, sizes.P99, sizes.Max)
+	return err
+}
+
+func formatAge(id string, seconds int64) string {
+	if id == "" {
+		return "-"
+	}
+	return fmt.Sprintf("%s (%s)", time.Duration(seconds)*time.Second, id)
+}
+
+func init() {
+	c := defaultCommandFactory.CreateReportCommand(flgs)
+	setDefaultFlags(c, flgs)
+	c.Flags().StringVar(&flgs.IndexName, flagMap.IndexName.Name, flagMap.IndexName.Value, flagMap.IndexName.Usage)
+	c.Flags().IntVar(&flgs.SampleSize, flagMap.SampleSize.Name, flagMap.SampleSize.Value, flagMap.SampleSize.Usage)
+	addOutputFlag(c, flgs)
+	root.AddCommand(c)
+}
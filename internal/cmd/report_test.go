@@ -0,0 +1,179 @@
+package cmd_test
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+	"github.com/vvatanabe/dynamomq/internal/cmd"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// newReportMessage returns a message sent sentAgo before the test date, received receiveCount times.
+func newReportMessage(id string, sentAgo time.Duration, receiveCount int, note string) *dynamomq.Message[any] {
+	m := dynamomq.NewMessage[any](id, map[string]any{"note": note}, test.DefaultTestDate.Add(-sentAgo))
+	m.ReceiveCount = receiveCount
+	return m
+}
+
+// seedReportTable returns the messages of a table holding ready, processing, held and dead-lettered messages.
+func seedReportTable() []*dynamomq.Message[any] {
+	now := test.DefaultTestDate
+	ready := newReportMessage("A-101", time.Hour, 0, "ready")
+	retried := newReportMessage("A-202", 30*time.Minute, 1, strings.Repeat("retried ", 20))
+	processing := newReportMessage("A-303", 10*time.Minute, 2, "processing")
+	processing.ReceivedAt = clock.FormatRFC3339Nano(now.Add(-time.Minute))
+	processing.InvisibleUntilAt = clock.FormatRFC3339Nano(now.Add(time.Minute))
+	held := newReportMessage("A-404", 5*time.Minute, 1, "held")
+	held.Held = true
+	failed := newReportMessage("B-101", 2*time.Hour, 3, strings.Repeat("failed ", 100))
+	failed.QueueType = dynamomq.QueueTypeDLQ
+	poisoned := newReportMessage("B-202", 26*time.Hour, 5, "poisoned")
+	poisoned.QueueType = dynamomq.QueueTypeDLQ
+	return []*dynamomq.Message[any]{ready, retried, processing, held, failed, poisoned}
+}
+
+// newSeededClient returns a client answering the reads of the report from the messages of the table.
+func newSeededClient(messages []*dynamomq.Message[any]) cmd.CommandFactory {
+	sorted := append([]*dynamomq.Message[any](nil), messages...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].SentAt < sorted[j].SentAt })
+	return cmd.CommandFactory{
+		CreateDynamoMQClient: func(ctx context.Context, flags *cmd.Flags) (dynamomq.Client[any], aws.Config, error) {
+			return mock.Client[any]{
+				GetQueueStatsFunc: func(ctx context.Context, params *dynamomq.GetQueueStatsInput) (*dynamomq.GetQueueStatsOutput, error) {
+					stats := &dynamomq.GetQueueStatsOutput{}
+					for _, m := range sorted {
+						if m.QueueType != dynamomq.QueueTypeStandard {
+							continue
+						}
+						stats.TotalMessagesInQueue++
+						switch {
+						case m.Held:
+							stats.TotalMessagesInQueueHeld++
+						case m.IsProcessing(test.DefaultTestDate):
+							stats.TotalMessagesInQueueProcessing++
+						default:
+							stats.TotalMessagesInQueueReady++
+							stats.First100IDsInQueue = append(stats.First100IDsInQueue, m.ID)
+						}
+					}
+					return stats, nil
+				},
+				GetDLQStatsFunc: func(ctx context.Context, params *dynamomq.GetDLQStatsInput) (*dynamomq.GetDLQStatsOutput, error) {
+					stats := &dynamomq.GetDLQStatsOutput{}
+					for _, m := range sorted {
+						if m.QueueType != dynamomq.QueueTypeDLQ {
+							continue
+						}
+						stats.TotalMessagesInDLQ++
+						stats.Entries = append(stats.Entries, dynamomq.DLQStatsEntry{
+							ID:           m.ID,
+							Status:       dynamomq.StatusReady,
+							UpdatedAt:    m.UpdatedAt,
+							MovedToDLQAt: m.SentAt,
+							ReceiveCount: m.ReceiveCount,
+						})
+					}
+					return stats, nil
+				},
+				GetMessageFunc: func(ctx context.Context, params *dynamomq.GetMessageInput) (*dynamomq.GetMessageOutput[any], error) {
+					for _, m := range messages {
+						if m.ID == params.ID {
+							return &dynamomq.GetMessageOutput[any]{Message: m}, nil
+						}
+					}
+					return &dynamomq.GetMessageOutput[any]{}, nil
+				},
+				ListMessagesFunc: func(ctx context.Context, params *dynamomq.ListMessagesInput) (*dynamomq.ListMessagesOutput[any], error) {
+					return &dynamomq.ListMessagesOutput[any]{Messages: messages[:min(int(params.Size), len(messages))]}, nil
+				},
+			}, aws.Config{}, nil
+		},
+		Clock: mock.Clock{T: test.DefaultTestDate},
+	}
+}
+
+func TestCommandFactoryCreateReportCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		flgs     *cmd.Flags
+		messages []*dynamomq.Message[any]
+	}{
+		{
+			name:     "report.golden.txt",
+			flgs:     &cmd.Flags{TableName: "orders", SampleSize: 100},
+			messages: seedReportTable(),
+		},
+		{
+			name:     "report.golden.json",
+			flgs:     &cmd.Flags{TableName: "orders", SampleSize: 100, Output: "json"},
+			messages: seedReportTable(),
+		},
+		{
+			name:     "report_sampled.golden.txt",
+			flgs:     &cmd.Flags{TableName: "orders", SampleSize: 3},
+			messages: seedReportTable(),
+		},
+		{
+			name:     "report_empty.golden.txt",
+			flgs:     &cmd.Flags{TableName: "orders", SampleSize: 100},
+			messages: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runCommand(t, newSeededClient(tt.messages).CreateReportCommand(tt.flgs))
+			if err != nil {
+				t.Fatalf("RunE() error = %v", err)
+			}
+			golden := filepath.Join("testdata", tt.name)
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0o600); err != nil {
+					t.Fatalf("failed to update golden file: %v", err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("RunE() printed\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestCommandFactoryCreateReportCommandInvalidFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		flgs *cmd.Flags
+	}{
+		{name: "should reject a sample size of zero", flgs: &cmd.Flags{SampleSize: 0}},
+		{name: "should reject a sample size over the maximum", flgs: &cmd.Flags{SampleSize: 1001}},
+		{name: "should reject an unknown output format", flgs: &cmd.Flags{SampleSize: 10, Output: "yaml"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cmd.CommandFactory{
+				CreateDynamoMQClient: func(ctx context.Context, flags *cmd.Flags) (dynamomq.Client[any], aws.Config, error) {
+					t.Error("CreateDynamoMQClient() is called, want the flags rejected first")
+					return nil, aws.Config{}, test.ErrTest
+				},
+			}.CreateReportCommand(tt.flgs)
+			if _, err := runCommand(t, c); err == nil {
+				t.Error("RunE() error = nil, want an error")
+			}
+		})
+	}
+}
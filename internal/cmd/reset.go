@@ -47,6 +47,6 @@ func (f CommandFactory) CreateResetCommand(flgs *Flags) *cobra.Command {
 func init() {
 	c := defaultCommandFactory.CreateResetCommand(flgs)
 	setDefaultFlags(c, flgs)
-	c.Flags().StringVar(&flgs.ID, flagMap.ID.Name, flagMap.ID.Value, flagMap.ID.Usage)
+	addIDFlag(c, flgs)
 	root.AddCommand(c)
 }
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -18,11 +17,21 @@ import (
 type CommandFactory struct {
 	CreateDynamoMQClient func(ctx context.Context, flags *Flags) (dynamomq.Client[any], aws.Config, error)
 	Stdin                io.Reader
+	// Clock gives the time the ages of the messages are measured at. If it is nil, the current time is used.
+	Clock clock.Clock
 }
 
 var defaultCommandFactory = CommandFactory{
 	CreateDynamoMQClient: createDynamoMQClient[any],
 	Stdin:                os.Stdin,
+	Clock:                clock.RealClock{},
+}
+
+func (f CommandFactory) now() time.Time {
+	if f.Clock == nil {
+		return clock.Now()
+	}
+	return f.Clock.Now()
 }
 
 var root = defaultCommandFactory.CreateRootCommand(flgs)
@@ -56,9 +65,27 @@ func (f CommandFactory) runRootCommand(flgs *Flags) error {
 	printAWSConfig(cfg, flgs)
 
 	interactive := &Interactive{Client: client, Message: nil}
+	if file, ok := f.Stdin.(*os.File); ok && isTerminal(int(file.Fd())) {
+		interactive.History = loadDefaultHistory()
+	}
 	return interactive.Start(f.Stdin)
 }
 
+// loadDefaultHistory loads the history from the home directory of the user,
+// or keeps it in memory only if it cannot be read there.
+func loadDefaultHistory() *History {
+	path, err := DefaultHistoryPath()
+	if err == nil {
+		var history *History
+		if history, err = LoadHistory(path); err == nil {
+			return history
+		}
+	}
+	printError(fmt.Errorf("the history is not saved: %w", err))
+	history, _ := LoadHistory("")
+	return history
+}
+
 func printWelcomeMessage() {
 	fmt.Println("===========================================================")
 	fmt.Println(">> Welcome to DynamoMQ CLI! [INTERACTIVE MODE]")
@@ -91,25 +118,6 @@ func createDynamoMQClient[T any](ctx context.Context, flags *Flags) (dynamomq.Cl
 	return client, cfg, nil
 }
 
-func ParseInput(input string) (command string, params []string) {
-	input = strings.TrimSpace(input)
-	arr := strings.Fields(input)
-
-	if len(arr) == 0 {
-		return "", nil
-	}
-
-	command = strings.ToLower(arr[0])
-
-	if len(arr) > 1 {
-		params = make([]string, len(arr)-1)
-		for i := 1; i < len(arr); i++ {
-			params[i-1] = strings.TrimSpace(arr[i])
-		}
-	}
-	return command, params
-}
-
 func Execute() {
 	if err := root.Execute(); err != nil {
 		printError(err)
@@ -128,10 +136,14 @@ type SystemInfo struct {
 	SentAt           string             `json:"sent_at"`
 	ReceivedAt       string             `json:"received_at"`
 	InvisibleUntilAt string             `json:"invisible_until_at"`
+	VisibleAt        string             `json:"visible_at"`
+	Held             bool               `json:"held,omitempty"`
+	HoldReason       string             `json:"hold_reason,omitempty"`
+	Tags             []string           `json:"tags,omitempty"`
 }
 
 func GetSystemInfo[T any](m *dynamomq.Message[T]) *SystemInfo {
-	return &SystemInfo{
+	info := &SystemInfo{
 		ID:               m.ID,
 		Status:           m.GetStatus(clock.Now()),
 		ReceiveCount:     m.ReceiveCount,
@@ -142,7 +154,14 @@ func GetSystemInfo[T any](m *dynamomq.Message[T]) *SystemInfo {
 		SentAt:           m.SentAt,
 		ReceivedAt:       m.ReceivedAt,
 		InvisibleUntilAt: m.InvisibleUntilAt,
+		Held:             m.Held,
+		HoldReason:       m.HoldReason,
+		Tags:             m.Tags,
+	}
+	if visibleAt, err := m.VisibleAt(); err == nil {
+		info.VisibleAt = clock.FormatRFC3339Nano(visibleAt)
 	}
+	return info
 }
 
 func ResetSystemInfo[T any](m *dynamomq.Message[T], now time.Time) {
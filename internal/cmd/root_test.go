@@ -116,6 +116,10 @@ func testRunAllCommand(t *testing.T, f cmd.CommandFactory, wantErr error) {
 			name: "get command",
 			cmd:  f.CreateGetCommand(&cmd.Flags{}),
 		},
+		{
+			name: "inflight command",
+			cmd:  f.CreateInFlightCommand(&cmd.Flags{}),
+		},
 		{
 			name: "invalid command",
 			cmd:  f.CreateInvalidCommand(&cmd.Flags{}),
@@ -140,10 +144,22 @@ func testRunAllCommand(t *testing.T, f cmd.CommandFactory, wantErr error) {
 			name: "redrive command",
 			cmd:  f.CreateRedriveCommand(&cmd.Flags{}),
 		},
+		{
+			name: "reclaim command",
+			cmd:  f.CreateReclaimCommand(&cmd.Flags{}),
+		},
 		{
 			name: "reset command",
 			cmd:  f.CreateResetCommand(&cmd.Flags{}),
 		},
+		{
+			name: "hold command",
+			cmd:  f.CreateHoldCommand(&cmd.Flags{}),
+		},
+		{
+			name: "release command",
+			cmd:  f.CreateReleaseCommand(&cmd.Flags{}),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -184,16 +200,33 @@ func TestParseInput(t *testing.T) {
 		input           string
 		expectedCommand string
 		expectedParams  []string
+		wantErr         bool
 	}{
-		{"Empty Input", "", "", nil},
-		{"Single Command", "Command", "command", nil},
-		{"Command with Parameters", "Command param1 param2", "command", []string{"param1", "param2"}},
-		{"Extra Spaces", "  Command  param1  param2  ", "command", []string{"param1", "param2"}},
+		{"Empty Input", "", "", nil, false},
+		{"Single Command", "Command", "command", nil, false},
+		{"Command with Parameters", "Command param1 param2", "command", []string{"param1", "param2"}, false},
+		{"Extra Spaces", "  Command  param1  param2  ", "command", []string{"param1", "param2"}, false},
+		{"Tabs", "Command\tparam1", "command", []string{"param1"}, false},
+		{"Double Quotes", `hold "under investigation" INC-42`, "hold", []string{"under investigation", "INC-42"}, false},
+		{"Single Quotes", `send A-101 --data '{"id": "A-101", "note": "a b"}'`, "send",
+			[]string{"A-101", "--data", `{"id": "A-101", "note": "a b"}`}, false},
+		{"Escaped Double Quote", `hold "say \"hi\" \\ \n"`, "hold", []string{`say "hi" \ \n`}, false},
+		{"Backslash in Single Quotes", `hold 'a\"b'`, "hold", []string{`a\"b`}, false},
+		{"Escaped Space", `hold a\ b`, "hold", []string{"a b"}, false},
+		{"Empty Quotes", `hold "" ''`, "hold", []string{"", ""}, false},
+		{"Adjacent Quotes", `hold a"b c"'d'`, "hold", []string{"ab cd"}, false},
+		{"Quoted Command Is Lowercased", `"HOLD" A`, "hold", []string{"A"}, false},
+		{"Trailing Backslash", `hold a\`, "hold", []string{`a\`}, false},
+		{"Unterminated Double Quote", `hold "a b`, "", nil, true},
+		{"Unterminated Single Quote", `hold 'a b`, "", nil, true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			command, params := cmd.ParseInput(tt.input)
+			command, params, err := cmd.ParseInput(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseInput(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
 			if command != tt.expectedCommand {
 				t.Errorf("ParseInput(%q) got command %q, want %q", tt.input, command, tt.expectedCommand)
 			}
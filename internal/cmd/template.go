@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vvatanabe/dynamomq"
+)
+
+func (f CommandFactory) CreateGenerateTemplateCommand(flgs *Flags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "generate-template",
+		Short: "Generates the CloudFormation or Terraform definition of the table",
+		Long:  `Generates the CloudFormation or Terraform definition of the table, as the DynamoMQ client expects it.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out, err := dynamomq.GenerateTableTemplate(&dynamomq.GenerateTableTemplateInput{
+				Format: dynamomq.TemplateFormat(flgs.Format),
+				Table: dynamomq.CreateQueueTableInput{
+					TableName:          flgs.TableName,
+					QueueingIndexName:  flgs.IndexName,
+					EnableTTLAttribute: flgs.TTLAttribute,
+					DeletionProtection: flgs.DeletionProtection,
+				},
+				ShardTableNames: flgs.ShardTableNames,
+			})
+			if err != nil {
+				return err
+			}
+			_, err = cmd.OutOrStdout().Write(out.Template)
+			return err
+		},
+	}
+}
+
+func init() {
+	c := defaultCommandFactory.CreateGenerateTemplateCommand(flgs)
+	c.Flags().StringVar(&flgs.TableName, flagMap.TableName.Name, flagMap.TableName.Value, flagMap.TableName.Usage)
+	c.Flags().StringVar(&flgs.IndexName, flagMap.IndexName.Name, flagMap.IndexName.Value, flagMap.IndexName.Usage)
+	c.Flags().StringVar(&flgs.Format, flagMap.Format.Name, flagMap.Format.Value, flagMap.Format.Usage)
+	c.Flags().StringVar(&flgs.TTLAttribute, flagMap.TTLAttribute.Name, flagMap.TTLAttribute.Value, flagMap.TTLAttribute.Usage)
+	c.Flags().StringSliceVar(&flgs.ShardTableNames, flagMap.ShardTableNames.Name, flagMap.ShardTableNames.Value, flagMap.ShardTableNames.Usage)
+	c.Flags().BoolVar(&flgs.DeletionProtection, flagMap.DeletionProtection.Name, flagMap.DeletionProtection.Value, flagMap.DeletionProtection.Usage)
+	root.AddCommand(c)
+}
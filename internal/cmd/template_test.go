@@ -0,0 +1,79 @@
+package cmd_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/cmd"
+)
+
+func TestGenerateTemplateCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		flgs    *cmd.Flags
+		want    *dynamomq.GenerateTableTemplateInput
+		wantErr bool
+	}{
+		{
+			name: "should generate a terraform template",
+			flgs: &cmd.Flags{
+				Format:             "terraform",
+				TableName:          "orders-0",
+				TTLAttribute:       "expires_at",
+				ShardTableNames:    []string{"orders-1"},
+				DeletionProtection: true,
+			},
+			want: &dynamomq.GenerateTableTemplateInput{
+				Format: dynamomq.TemplateFormatTerraform,
+				Table: dynamomq.CreateQueueTableInput{
+					TableName:          "orders-0",
+					EnableTTLAttribute: "expires_at",
+					DeletionProtection: true,
+				},
+				ShardTableNames: []string{"orders-1"},
+			},
+		},
+		{
+			name: "should generate a cloudformation template",
+			flgs: &cmd.Flags{
+				Format:    "cloudformation",
+				TableName: "orders",
+				IndexName: "orders-index",
+			},
+			want: &dynamomq.GenerateTableTemplateInput{
+				Format: dynamomq.TemplateFormatCloudFormation,
+				Table: dynamomq.CreateQueueTableInput{
+					TableName:         "orders",
+					QueueingIndexName: "orders-index",
+				},
+			},
+		},
+		{
+			name:    "should return error when the format is invalid",
+			flgs:    &cmd.Flags{Format: "yaml"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cmd.CommandFactory{}.CreateGenerateTemplateCommand(tt.flgs)
+			var out bytes.Buffer
+			c.SetOut(&out)
+			err := c.RunE(c, []string{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RunE() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			want, err := dynamomq.GenerateTableTemplate(tt.want)
+			if err != nil {
+				t.Fatalf("GenerateTableTemplate() error = %v", err)
+			}
+			if out.String() != string(want.Template) {
+				t.Errorf("RunE() output = %s, want %s", out.String(), want.Template)
+			}
+		})
+	}
+}
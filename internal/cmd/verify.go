@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/vvatanabe/dynamomq"
+)
+
+func (f CommandFactory) CreateVerifyCommand(flgs *Flags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify",
+		Short: "Verify that the table is ready for use and the credentials are allowed to use it",
+		Long: `Verify that the table is ready for use and the credentials are allowed to use it.
+The table must exist and be active, and its primary key and queueing index must match what DynamoMQ expects.
+With --ttl-attribute, Time to Live must be enabled on that attribute.
+Query, GetItem, PutItem, UpdateItem and DeleteItem are called on a throwaway item, conditioned so that nothing is written.
+The command exits with a non-zero status if any check fails.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			asJSON, err := outputAsJSON(flgs.Output)
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+			client, _, err := f.CreateDynamoMQClient(ctx, flgs)
+			if err != nil {
+				return err
+			}
+			out, err := client.VerifyQueue(ctx, &dynamomq.VerifyQueueInput{
+				ExpectedTTLAttribute: flgs.TTLAttribute,
+			})
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				err = printJSON(cmd.OutOrStdout(), out)
+			} else {
+				err = printChecklist(cmd.OutOrStdout(), out)
+			}
+			if err != nil {
+				return err
+			}
+			if failed := countFailedChecks(out); failed > 0 {
+				return fmt.Errorf("%d of %d checks failed for table '%s'", failed, len(out.Checks), out.TableName)
+			}
+			return nil
+		},
+	}
+}
+
+func printChecklist(w io.Writer, out *dynamomq.VerifyQueueOutput) error {
+	if _, err := fmt.Fprintf(w, "Verifying table '%s':\n", out.TableName); err != nil {
+		return err
+	}
+	for _, check := range out.Checks {
+		var err error
+		if check.Passed {
+			_, err = fmt.Fprintf(w, "[PASS] %s\n", check.Name)
+		} else {
+			_, err = fmt.Fprintf(w, "[FAIL] %s: %s\n", check.Name, check.Detail)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func countFailedChecks(out *dynamomq.VerifyQueueOutput) int {
+	var failed int
+	for _, check := range out.Checks {
+		if !check.Passed {
+			failed++
+		}
+	}
+	return failed
+}
+
+func init() {
+	c := defaultCommandFactory.CreateVerifyCommand(flgs)
+	setDefaultFlags(c, flgs)
+	c.Flags().StringVar(&flgs.IndexName, flagMap.IndexName.Name, flagMap.IndexName.Value, flagMap.IndexName.Usage)
+	c.Flags().StringVar(&flgs.TTLAttribute, flagMap.TTLAttribute.Name, flagMap.TTLAttribute.Value,
+		"The attribute on which Time to Live must be enabled, when the retention of the messages relies on it.")
+	addOutputFlag(c, flgs)
+	root.AddCommand(c)
+}
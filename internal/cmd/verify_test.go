@@ -0,0 +1,113 @@
+package cmd_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/cmd"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestCommandFactoryCreateVerifyCommand(t *testing.T) {
+	checks := []dynamomq.VerificationCheck{
+		{Name: dynamomq.VerificationCheckTableExists, Passed: true},
+		{Name: dynamomq.VerificationCheckTimeToLive, Detail: "TTL is not enabled on 'expires_at'"},
+		{Name: dynamomq.VerificationCheckPutItemAllowed, Detail: "denied: api error AccessDeniedException: denied"},
+	}
+	tests := []struct {
+		name      string
+		flgs      *cmd.Flags
+		checks    []dynamomq.VerificationCheck
+		verifyErr error
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:   "should print the checklist of a ready queue",
+			flgs:   &cmd.Flags{},
+			checks: checks[:1],
+			want: `Verifying table 'dynamo-mq-table':
+[PASS] table exists
+`,
+		},
+		{
+			name:   "should print the failed checks and fail",
+			flgs:   &cmd.Flags{TTLAttribute: "expires_at"},
+			checks: checks,
+			want: `Verifying table 'dynamo-mq-table':
+[PASS] table exists
+[FAIL] time to live: TTL is not enabled on 'expires_at'
+[FAIL] PutItem allowed: denied: api error AccessDeniedException: denied
+`,
+			wantErr: true,
+		},
+		{
+			name:      "should fail when the queue cannot be verified",
+			flgs:      &cmd.Flags{},
+			verifyErr: test.ErrTest,
+			wantErr:   true,
+		},
+		{
+			name:    "should reject an unknown output format",
+			flgs:    &cmd.Flags{Output: "yaml"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var input *dynamomq.VerifyQueueInput
+			f := cmd.CommandFactory{
+				CreateDynamoMQClient: func(ctx context.Context, flags *cmd.Flags) (dynamomq.Client[any], aws.Config, error) {
+					return mock.Client[any]{
+						VerifyQueueFunc: func(ctx context.Context, params *dynamomq.VerifyQueueInput) (*dynamomq.VerifyQueueOutput, error) {
+							input = params
+							if tt.verifyErr != nil {
+								return nil, tt.verifyErr
+							}
+							return &dynamomq.VerifyQueueOutput{TableName: "dynamo-mq-table", Checks: tt.checks}, nil
+						},
+					}, aws.Config{}, nil
+				},
+			}
+			got, err := runCommand(t, f.CreateVerifyCommand(tt.flgs))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RunE() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RunE() printed %q, want %q", got, tt.want)
+			}
+			if input != nil && input.ExpectedTTLAttribute != tt.flgs.TTLAttribute {
+				t.Errorf("VerifyQueue() ExpectedTTLAttribute = %q, want %q", input.ExpectedTTLAttribute, tt.flgs.TTLAttribute)
+			}
+		})
+	}
+}
+
+func TestCommandFactoryCreateVerifyCommandJSON(t *testing.T) {
+	want := &dynamomq.VerifyQueueOutput{
+		TableName: "dynamo-mq-table",
+		Checks:    []dynamomq.VerificationCheck{{Name: dynamomq.VerificationCheckTableExists, Passed: true}},
+	}
+	f := cmd.CommandFactory{
+		CreateDynamoMQClient: func(ctx context.Context, flags *cmd.Flags) (dynamomq.Client[any], aws.Config, error) {
+			return mock.Client[any]{
+				VerifyQueueFunc: func(ctx context.Context, params *dynamomq.VerifyQueueInput) (*dynamomq.VerifyQueueOutput, error) {
+					return want, nil
+				},
+			}, aws.Config{}, nil
+		},
+	}
+	out, err := runCommand(t, f.CreateVerifyCommand(&cmd.Flags{Output: "json"}))
+	if err != nil {
+		t.Fatalf("RunE() error = %v", err)
+	}
+	got := &dynamomq.VerifyQueueOutput{}
+	if err := json.Unmarshal([]byte(out), got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v, output = %s", err, out)
+	}
+	test.AssertDeepEqual(t, got, want, "RunE()")
+}
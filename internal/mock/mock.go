@@ -5,24 +5,57 @@ import (
 	"errors"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
 	"github.com/vvatanabe/dynamomq"
 	"github.com/vvatanabe/dynamomq/internal/clock"
+	"github.com/vvatanabe/dynamomq/scaling"
 )
 
 var ErrNotImplemented = errors.New("not implemented")
 
 type Client[T any] struct {
-	SendMessageFunc             func(ctx context.Context, params *dynamomq.SendMessageInput[T]) (*dynamomq.SendMessageOutput[T], error)
-	ReceiveMessageFunc          func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[T], error)
-	ChangeMessageVisibilityFunc func(ctx context.Context, params *dynamomq.ChangeMessageVisibilityInput) (*dynamomq.ChangeMessageVisibilityOutput[T], error)
-	DeleteMessageFunc           func(ctx context.Context, params *dynamomq.DeleteMessageInput) (*dynamomq.DeleteMessageOutput, error)
-	MoveMessageToDLQFunc        func(ctx context.Context, params *dynamomq.MoveMessageToDLQInput) (*dynamomq.MoveMessageToDLQOutput[T], error)
-	RedriveMessageFunc          func(ctx context.Context, params *dynamomq.RedriveMessageInput) (*dynamomq.RedriveMessageOutput[T], error)
-	GetMessageFunc              func(ctx context.Context, params *dynamomq.GetMessageInput) (*dynamomq.GetMessageOutput[T], error)
-	GetQueueStatsFunc           func(ctx context.Context, params *dynamomq.GetQueueStatsInput) (*dynamomq.GetQueueStatsOutput, error)
-	GetDLQStatsFunc             func(ctx context.Context, params *dynamomq.GetDLQStatsInput) (*dynamomq.GetDLQStatsOutput, error)
-	ListMessagesFunc            func(ctx context.Context, params *dynamomq.ListMessagesInput) (*dynamomq.ListMessagesOutput[T], error)
-	ReplaceMessageFunc          func(ctx context.Context, params *dynamomq.ReplaceMessageInput[T]) (*dynamomq.ReplaceMessageOutput, error)
+	SendMessageFunc                  func(ctx context.Context, params *dynamomq.SendMessageInput[T]) (*dynamomq.SendMessageOutput[T], error)
+	ReceiveMessageFunc               func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[T], error)
+	ReceiveMessageBatchFunc          func(ctx context.Context, params *dynamomq.ReceiveMessageBatchInput) (*dynamomq.ReceiveMessageBatchOutput[T], error)
+	ChangeMessageVisibilityFunc      func(ctx context.Context, params *dynamomq.ChangeMessageVisibilityInput) (*dynamomq.ChangeMessageVisibilityOutput[T], error)
+	DeleteMessageFunc                func(ctx context.Context, params *dynamomq.DeleteMessageInput) (*dynamomq.DeleteMessageOutput, error)
+	MoveMessageToDLQFunc             func(ctx context.Context, params *dynamomq.MoveMessageToDLQInput) (*dynamomq.MoveMessageToDLQOutput[T], error)
+	RedriveMessageFunc               func(ctx context.Context, params *dynamomq.RedriveMessageInput) (*dynamomq.RedriveMessageOutput[T], error)
+	GetMessageFunc                   func(ctx context.Context, params *dynamomq.GetMessageInput) (*dynamomq.GetMessageOutput[T], error)
+	GetMessageBatchFunc              func(ctx context.Context, params *dynamomq.GetMessageBatchInput) (*dynamomq.GetMessageBatchOutput[T], error)
+	GetQueueStatsFunc                func(ctx context.Context, params *dynamomq.GetQueueStatsInput) (*dynamomq.GetQueueStatsOutput, error)
+	GetDLQStatsFunc                  func(ctx context.Context, params *dynamomq.GetDLQStatsInput) (*dynamomq.GetDLQStatsOutput, error)
+	ListDLQMessagesFunc              func(ctx context.Context, params *dynamomq.ListDLQMessagesInput) (*dynamomq.ListDLQMessagesOutput[T], error)
+	ListMessagesFunc                 func(ctx context.Context, params *dynamomq.ListMessagesInput) (*dynamomq.ListMessagesOutput[T], error)
+	ListMessageSummariesFunc         func(ctx context.Context, params *dynamomq.ListMessageSummariesInput) (*dynamomq.ListMessageSummariesOutput, error)
+	ReplaceMessageFunc               func(ctx context.Context, params *dynamomq.ReplaceMessageInput[T]) (*dynamomq.ReplaceMessageOutput, error)
+	DescribeQueueFunc                func(ctx context.Context, params *dynamomq.DescribeQueueInput) (*dynamomq.DescribeQueueOutput, error)
+	VerifyQueueFunc                  func(ctx context.Context, params *dynamomq.VerifyQueueInput) (*dynamomq.VerifyQueueOutput, error)
+	SendMessageTransactWriteItemFunc func(params *dynamomq.SendMessageInput[T]) (*types.TransactWriteItem, error)
+	SendMessagesInTransactionFunc    func(ctx context.Context, params *dynamomq.SendMessagesInTransactionInput[T]) (*dynamomq.SendMessagesInTransactionOutput[T], error)
+	ChainMessageFunc                 func(ctx context.Context, params *dynamomq.ChainMessageInput[T]) (*dynamomq.ChainMessageOutput[T], error)
+	DeleteExpiredMessagesFunc        func(ctx context.Context, params *dynamomq.DeleteExpiredMessagesInput) (*dynamomq.DeleteExpiredMessagesOutput, error)
+	AcquireLockFunc                  func(ctx context.Context, params *dynamomq.AcquireLockInput) (*dynamomq.AcquireLockOutput, error)
+	ReleaseLockFunc                  func(ctx context.Context, params *dynamomq.ReleaseLockInput) (*dynamomq.ReleaseLockOutput, error)
+	SetQueueEnabledFunc              func(ctx context.Context, params *dynamomq.SetQueueEnabledInput) (*dynamomq.SetQueueEnabledOutput, error)
+	SaveQueueConfigFunc              func(ctx context.Context, params *dynamomq.SaveQueueConfigInput) (*dynamomq.SaveQueueConfigOutput, error)
+	GetQueueConfigFunc               func(ctx context.Context, params *dynamomq.GetQueueConfigInput) (*dynamomq.GetQueueConfigOutput, error)
+	ListInFlightMessagesFunc         func(ctx context.Context, params *dynamomq.ListInFlightMessagesInput) (*dynamomq.ListInFlightMessagesOutput, error)
+	ResetReceiveCountFunc            func(ctx context.Context, params *dynamomq.ResetReceiveCountInput) (*dynamomq.ResetReceiveCountOutput[T], error)
+	UpdateMessageDataFunc            func(ctx context.Context, params *dynamomq.UpdateMessageDataInput[T]) (*dynamomq.UpdateMessageDataOutput[T], error)
+	CopyMessageFunc                  func(ctx context.Context, params *dynamomq.CopyMessageInput) (*dynamomq.CopyMessageOutput[T], error)
+	MoveMessageFunc                  func(ctx context.Context, params *dynamomq.MoveMessageInput) (*dynamomq.MoveMessageOutput[T], error)
+	ReconcileInFlightCountFunc       func(ctx context.Context, params *dynamomq.ReconcileInFlightCountInput) (*dynamomq.ReconcileInFlightCountOutput, error)
+	MoveMessagesToDLQFunc            func(ctx context.Context, params *dynamomq.MoveMessagesToDLQInput) (*dynamomq.MoveMessagesToDLQOutput[T], error)
+	MoveMessagesToDLQByFilterFunc    func(ctx context.Context, params *dynamomq.MoveMessagesToDLQByFilterInput) (*dynamomq.MoveMessagesToDLQOutput[T], error)
+	SendCanaryFunc                   func(ctx context.Context, params *dynamomq.SendCanaryInput) (*dynamomq.SendCanaryOutput[T], error)
+	CheckCanaryFunc                  func(ctx context.Context, params *dynamomq.CheckCanaryInput) (*dynamomq.CheckCanaryOutput, error)
+	PromoteScheduledMessagesFunc     func(ctx context.Context, params *dynamomq.PromoteScheduledMessagesInput) (*dynamomq.PromoteScheduledMessagesOutput, error)
+	ReclaimExpiredMessagesFunc       func(ctx context.Context, params *dynamomq.ReclaimExpiredMessagesInput) (*dynamomq.ReclaimExpiredMessagesOutput, error)
+	HoldMessageFunc                  func(ctx context.Context, params *dynamomq.HoldMessageInput) (*dynamomq.HoldMessageOutput[T], error)
+	ReleaseMessageFunc               func(ctx context.Context, params *dynamomq.ReleaseMessageInput) (*dynamomq.ReleaseMessageOutput[T], error)
 }
 
 func (m Client[T]) SendMessage(ctx context.Context, params *dynamomq.SendMessageInput[T]) (*dynamomq.SendMessageOutput[T], error) {
@@ -39,6 +72,13 @@ func (m Client[T]) ReceiveMessage(ctx context.Context, params *dynamomq.ReceiveM
 	return nil, ErrNotImplemented
 }
 
+func (m Client[T]) ReceiveMessageBatch(ctx context.Context, params *dynamomq.ReceiveMessageBatchInput) (*dynamomq.ReceiveMessageBatchOutput[T], error) {
+	if m.ReceiveMessageBatchFunc != nil {
+		return m.ReceiveMessageBatchFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
 func (m Client[T]) ChangeMessageVisibility(ctx context.Context, params *dynamomq.ChangeMessageVisibilityInput) (*dynamomq.ChangeMessageVisibilityOutput[T], error) {
 	if m.ChangeMessageVisibilityFunc != nil {
 		return m.ChangeMessageVisibilityFunc(ctx, params)
@@ -74,6 +114,13 @@ func (m Client[T]) GetMessage(ctx context.Context, params *dynamomq.GetMessageIn
 	return nil, ErrNotImplemented
 }
 
+func (m Client[T]) GetMessageBatch(ctx context.Context, params *dynamomq.GetMessageBatchInput) (*dynamomq.GetMessageBatchOutput[T], error) {
+	if m.GetMessageBatchFunc != nil {
+		return m.GetMessageBatchFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
 func (m Client[T]) GetQueueStats(ctx context.Context, params *dynamomq.GetQueueStatsInput) (*dynamomq.GetQueueStatsOutput, error) {
 	if m.GetQueueStatsFunc != nil {
 		return m.GetQueueStatsFunc(ctx, params)
@@ -88,6 +135,13 @@ func (m Client[T]) GetDLQStats(ctx context.Context, params *dynamomq.GetDLQStats
 	return nil, ErrNotImplemented
 }
 
+func (m Client[T]) ListDLQMessages(ctx context.Context, params *dynamomq.ListDLQMessagesInput) (*dynamomq.ListDLQMessagesOutput[T], error) {
+	if m.ListDLQMessagesFunc != nil {
+		return m.ListDLQMessagesFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
 func (m Client[T]) ListMessages(ctx context.Context, params *dynamomq.ListMessagesInput) (*dynamomq.ListMessagesOutput[T], error) {
 	if m.ListMessagesFunc != nil {
 		return m.ListMessagesFunc(ctx, params)
@@ -95,6 +149,13 @@ func (m Client[T]) ListMessages(ctx context.Context, params *dynamomq.ListMessag
 	return nil, ErrNotImplemented
 }
 
+func (m Client[T]) ListMessageSummaries(ctx context.Context, params *dynamomq.ListMessageSummariesInput) (*dynamomq.ListMessageSummariesOutput, error) {
+	if m.ListMessageSummariesFunc != nil {
+		return m.ListMessageSummariesFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
 func (m Client[T]) ReplaceMessage(ctx context.Context, params *dynamomq.ReplaceMessageInput[T]) (*dynamomq.ReplaceMessageOutput, error) {
 	if m.ReplaceMessageFunc != nil {
 		return m.ReplaceMessageFunc(ctx, params)
@@ -102,6 +163,181 @@ func (m Client[T]) ReplaceMessage(ctx context.Context, params *dynamomq.ReplaceM
 	return nil, ErrNotImplemented
 }
 
+func (m Client[T]) DescribeQueue(ctx context.Context, params *dynamomq.DescribeQueueInput) (*dynamomq.DescribeQueueOutput, error) {
+	if m.DescribeQueueFunc != nil {
+		return m.DescribeQueueFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m Client[T]) VerifyQueue(ctx context.Context, params *dynamomq.VerifyQueueInput) (*dynamomq.VerifyQueueOutput, error) {
+	if m.VerifyQueueFunc != nil {
+		return m.VerifyQueueFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m Client[T]) SendMessageTransactWriteItem(params *dynamomq.SendMessageInput[T]) (*types.TransactWriteItem, error) {
+	if m.SendMessageTransactWriteItemFunc != nil {
+		return m.SendMessageTransactWriteItemFunc(params)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m Client[T]) SendMessagesInTransaction(ctx context.Context, params *dynamomq.SendMessagesInTransactionInput[T]) (*dynamomq.SendMessagesInTransactionOutput[T], error) {
+	if m.SendMessagesInTransactionFunc != nil {
+		return m.SendMessagesInTransactionFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m Client[T]) ChainMessage(ctx context.Context, params *dynamomq.ChainMessageInput[T]) (*dynamomq.ChainMessageOutput[T], error) {
+	if m.ChainMessageFunc != nil {
+		return m.ChainMessageFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m Client[T]) DeleteExpiredMessages(ctx context.Context, params *dynamomq.DeleteExpiredMessagesInput) (*dynamomq.DeleteExpiredMessagesOutput, error) {
+	if m.DeleteExpiredMessagesFunc != nil {
+		return m.DeleteExpiredMessagesFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m Client[T]) AcquireLock(ctx context.Context, params *dynamomq.AcquireLockInput) (*dynamomq.AcquireLockOutput, error) {
+	if m.AcquireLockFunc != nil {
+		return m.AcquireLockFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m Client[T]) ReleaseLock(ctx context.Context, params *dynamomq.ReleaseLockInput) (*dynamomq.ReleaseLockOutput, error) {
+	if m.ReleaseLockFunc != nil {
+		return m.ReleaseLockFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m Client[T]) SetQueueEnabled(ctx context.Context, params *dynamomq.SetQueueEnabledInput) (*dynamomq.SetQueueEnabledOutput, error) {
+	if m.SetQueueEnabledFunc != nil {
+		return m.SetQueueEnabledFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m Client[T]) SaveQueueConfig(ctx context.Context, params *dynamomq.SaveQueueConfigInput) (*dynamomq.SaveQueueConfigOutput, error) {
+	if m.SaveQueueConfigFunc != nil {
+		return m.SaveQueueConfigFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m Client[T]) GetQueueConfig(ctx context.Context, params *dynamomq.GetQueueConfigInput) (*dynamomq.GetQueueConfigOutput, error) {
+	if m.GetQueueConfigFunc != nil {
+		return m.GetQueueConfigFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m Client[T]) ListInFlightMessages(ctx context.Context, params *dynamomq.ListInFlightMessagesInput) (*dynamomq.ListInFlightMessagesOutput, error) {
+	if m.ListInFlightMessagesFunc != nil {
+		return m.ListInFlightMessagesFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m Client[T]) ResetReceiveCount(ctx context.Context, params *dynamomq.ResetReceiveCountInput) (*dynamomq.ResetReceiveCountOutput[T], error) {
+	if m.ResetReceiveCountFunc != nil {
+		return m.ResetReceiveCountFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m Client[T]) UpdateMessageData(ctx context.Context, params *dynamomq.UpdateMessageDataInput[T]) (*dynamomq.UpdateMessageDataOutput[T], error) {
+	if m.UpdateMessageDataFunc != nil {
+		return m.UpdateMessageDataFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m Client[T]) CopyMessage(ctx context.Context, params *dynamomq.CopyMessageInput) (*dynamomq.CopyMessageOutput[T], error) {
+	if m.CopyMessageFunc != nil {
+		return m.CopyMessageFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m Client[T]) MoveMessage(ctx context.Context, params *dynamomq.MoveMessageInput) (*dynamomq.MoveMessageOutput[T], error) {
+	if m.MoveMessageFunc != nil {
+		return m.MoveMessageFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m Client[T]) ReconcileInFlightCount(ctx context.Context, params *dynamomq.ReconcileInFlightCountInput) (*dynamomq.ReconcileInFlightCountOutput, error) {
+	if m.ReconcileInFlightCountFunc != nil {
+		return m.ReconcileInFlightCountFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m Client[T]) MoveMessagesToDLQ(ctx context.Context, params *dynamomq.MoveMessagesToDLQInput) (*dynamomq.MoveMessagesToDLQOutput[T], error) {
+	if m.MoveMessagesToDLQFunc != nil {
+		return m.MoveMessagesToDLQFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m Client[T]) MoveMessagesToDLQByFilter(ctx context.Context, params *dynamomq.MoveMessagesToDLQByFilterInput) (*dynamomq.MoveMessagesToDLQOutput[T], error) {
+	if m.MoveMessagesToDLQByFilterFunc != nil {
+		return m.MoveMessagesToDLQByFilterFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m Client[T]) SendCanary(ctx context.Context, params *dynamomq.SendCanaryInput) (*dynamomq.SendCanaryOutput[T], error) {
+	if m.SendCanaryFunc != nil {
+		return m.SendCanaryFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m Client[T]) CheckCanary(ctx context.Context, params *dynamomq.CheckCanaryInput) (*dynamomq.CheckCanaryOutput, error) {
+	if m.CheckCanaryFunc != nil {
+		return m.CheckCanaryFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m Client[T]) PromoteScheduledMessages(ctx context.Context, params *dynamomq.PromoteScheduledMessagesInput) (*dynamomq.PromoteScheduledMessagesOutput, error) {
+	if m.PromoteScheduledMessagesFunc != nil {
+		return m.PromoteScheduledMessagesFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m Client[T]) ReclaimExpiredMessages(ctx context.Context, params *dynamomq.ReclaimExpiredMessagesInput) (*dynamomq.ReclaimExpiredMessagesOutput, error) {
+	if m.ReclaimExpiredMessagesFunc != nil {
+		return m.ReclaimExpiredMessagesFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m Client[T]) HoldMessage(ctx context.Context, params *dynamomq.HoldMessageInput) (*dynamomq.HoldMessageOutput[T], error) {
+	if m.HoldMessageFunc != nil {
+		return m.HoldMessageFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m Client[T]) ReleaseMessage(ctx context.Context, params *dynamomq.ReleaseMessageInput) (*dynamomq.ReleaseMessageOutput[T], error) {
+	if m.ReleaseMessageFunc != nil {
+		return m.ReleaseMessageFunc(ctx, params)
+	}
+	return nil, ErrNotImplemented
+}
+
 var SuccessfulMockClient = &Client[any]{
 	SendMessageFunc: func(ctx context.Context, params *dynamomq.SendMessageInput[any]) (*dynamomq.SendMessageOutput[any], error) {
 		return &dynamomq.SendMessageOutput[any]{
@@ -113,6 +349,11 @@ var SuccessfulMockClient = &Client[any]{
 			ReceivedMessage: &dynamomq.Message[any]{},
 		}, nil
 	},
+	ReceiveMessageBatchFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageBatchInput) (*dynamomq.ReceiveMessageBatchOutput[any], error) {
+		return &dynamomq.ReceiveMessageBatchOutput[any]{
+			ReceivedMessages: []*dynamomq.Message[any]{{}},
+		}, nil
+	},
 	ChangeMessageVisibilityFunc: func(ctx context.Context, params *dynamomq.ChangeMessageVisibilityInput) (*dynamomq.ChangeMessageVisibilityOutput[any], error) {
 		return &dynamomq.ChangeMessageVisibilityOutput[any]{
 			ChangedMessage: &dynamomq.Message[any]{},
@@ -132,18 +373,248 @@ var SuccessfulMockClient = &Client[any]{
 			Message: &dynamomq.Message[any]{},
 		}, nil
 	},
+	GetMessageBatchFunc: func(ctx context.Context, params *dynamomq.GetMessageBatchInput) (*dynamomq.GetMessageBatchOutput[any], error) {
+		return &dynamomq.GetMessageBatchOutput[any]{
+			Messages: map[string]*dynamomq.Message[any]{},
+		}, nil
+	},
 	GetQueueStatsFunc: func(ctx context.Context, params *dynamomq.GetQueueStatsInput) (*dynamomq.GetQueueStatsOutput, error) {
 		return &dynamomq.GetQueueStatsOutput{}, nil
 	},
 	GetDLQStatsFunc: func(ctx context.Context, params *dynamomq.GetDLQStatsInput) (*dynamomq.GetDLQStatsOutput, error) {
 		return &dynamomq.GetDLQStatsOutput{}, nil
 	},
+	ListDLQMessagesFunc: func(ctx context.Context, params *dynamomq.ListDLQMessagesInput) (*dynamomq.ListDLQMessagesOutput[any], error) {
+		return &dynamomq.ListDLQMessagesOutput[any]{
+			Messages: []*dynamomq.Message[any]{},
+		}, nil
+	},
 	ListMessagesFunc: func(ctx context.Context, params *dynamomq.ListMessagesInput) (*dynamomq.ListMessagesOutput[any], error) {
 		return &dynamomq.ListMessagesOutput[any]{}, nil
 	},
+	ListMessageSummariesFunc: func(ctx context.Context, params *dynamomq.ListMessageSummariesInput) (*dynamomq.ListMessageSummariesOutput, error) {
+		return &dynamomq.ListMessageSummariesOutput{}, nil
+	},
 	ReplaceMessageFunc: func(ctx context.Context, params *dynamomq.ReplaceMessageInput[any]) (*dynamomq.ReplaceMessageOutput, error) {
 		return &dynamomq.ReplaceMessageOutput{}, nil
 	},
+	DescribeQueueFunc: func(ctx context.Context, params *dynamomq.DescribeQueueInput) (*dynamomq.DescribeQueueOutput, error) {
+		return &dynamomq.DescribeQueueOutput{}, nil
+	},
+	VerifyQueueFunc: func(ctx context.Context, params *dynamomq.VerifyQueueInput) (*dynamomq.VerifyQueueOutput, error) {
+		return &dynamomq.VerifyQueueOutput{}, nil
+	},
+	SendMessageTransactWriteItemFunc: func(params *dynamomq.SendMessageInput[any]) (*types.TransactWriteItem, error) {
+		return &types.TransactWriteItem{}, nil
+	},
+	SendMessagesInTransactionFunc: func(ctx context.Context, params *dynamomq.SendMessagesInTransactionInput[any]) (*dynamomq.SendMessagesInTransactionOutput[any], error) {
+		return &dynamomq.SendMessagesInTransactionOutput[any]{}, nil
+	},
+	ChainMessageFunc: func(ctx context.Context, params *dynamomq.ChainMessageInput[any]) (*dynamomq.ChainMessageOutput[any], error) {
+		return &dynamomq.ChainMessageOutput[any]{}, nil
+	},
+	DeleteExpiredMessagesFunc: func(ctx context.Context, params *dynamomq.DeleteExpiredMessagesInput) (*dynamomq.DeleteExpiredMessagesOutput, error) {
+		return &dynamomq.DeleteExpiredMessagesOutput{}, nil
+	},
+	AcquireLockFunc: func(ctx context.Context, params *dynamomq.AcquireLockInput) (*dynamomq.AcquireLockOutput, error) {
+		return &dynamomq.AcquireLockOutput{}, nil
+	},
+	ReleaseLockFunc: func(ctx context.Context, params *dynamomq.ReleaseLockInput) (*dynamomq.ReleaseLockOutput, error) {
+		return &dynamomq.ReleaseLockOutput{}, nil
+	},
+	SetQueueEnabledFunc: func(ctx context.Context, params *dynamomq.SetQueueEnabledInput) (*dynamomq.SetQueueEnabledOutput, error) {
+		return &dynamomq.SetQueueEnabledOutput{}, nil
+	},
+	SaveQueueConfigFunc: func(ctx context.Context, params *dynamomq.SaveQueueConfigInput) (*dynamomq.SaveQueueConfigOutput, error) {
+		return &dynamomq.SaveQueueConfigOutput{}, nil
+	},
+	GetQueueConfigFunc: func(ctx context.Context, params *dynamomq.GetQueueConfigInput) (*dynamomq.GetQueueConfigOutput, error) {
+		return &dynamomq.GetQueueConfigOutput{}, nil
+	},
+	ListInFlightMessagesFunc: func(ctx context.Context, params *dynamomq.ListInFlightMessagesInput) (*dynamomq.ListInFlightMessagesOutput, error) {
+		return &dynamomq.ListInFlightMessagesOutput{}, nil
+	},
+	ResetReceiveCountFunc: func(ctx context.Context, params *dynamomq.ResetReceiveCountInput) (*dynamomq.ResetReceiveCountOutput[any], error) {
+		return &dynamomq.ResetReceiveCountOutput[any]{}, nil
+	},
+	UpdateMessageDataFunc: func(ctx context.Context, params *dynamomq.UpdateMessageDataInput[any]) (*dynamomq.UpdateMessageDataOutput[any], error) {
+		return &dynamomq.UpdateMessageDataOutput[any]{}, nil
+	},
+	CopyMessageFunc: func(ctx context.Context, params *dynamomq.CopyMessageInput) (*dynamomq.CopyMessageOutput[any], error) {
+		return &dynamomq.CopyMessageOutput[any]{}, nil
+	},
+	MoveMessageFunc: func(ctx context.Context, params *dynamomq.MoveMessageInput) (*dynamomq.MoveMessageOutput[any], error) {
+		return &dynamomq.MoveMessageOutput[any]{}, nil
+	},
+	ReconcileInFlightCountFunc: func(ctx context.Context, params *dynamomq.ReconcileInFlightCountInput) (*dynamomq.ReconcileInFlightCountOutput, error) {
+		return &dynamomq.ReconcileInFlightCountOutput{}, nil
+	},
+	MoveMessagesToDLQFunc: func(ctx context.Context, params *dynamomq.MoveMessagesToDLQInput) (*dynamomq.MoveMessagesToDLQOutput[any], error) {
+		return &dynamomq.MoveMessagesToDLQOutput[any]{}, nil
+	},
+	MoveMessagesToDLQByFilterFunc: func(ctx context.Context, params *dynamomq.MoveMessagesToDLQByFilterInput) (*dynamomq.MoveMessagesToDLQOutput[any], error) {
+		return &dynamomq.MoveMessagesToDLQOutput[any]{}, nil
+	},
+	SendCanaryFunc: func(ctx context.Context, params *dynamomq.SendCanaryInput) (*dynamomq.SendCanaryOutput[any], error) {
+		return &dynamomq.SendCanaryOutput[any]{}, nil
+	},
+	CheckCanaryFunc: func(ctx context.Context, params *dynamomq.CheckCanaryInput) (*dynamomq.CheckCanaryOutput, error) {
+		return &dynamomq.CheckCanaryOutput{}, nil
+	},
+	PromoteScheduledMessagesFunc: func(ctx context.Context, params *dynamomq.PromoteScheduledMessagesInput) (*dynamomq.PromoteScheduledMessagesOutput, error) {
+		return &dynamomq.PromoteScheduledMessagesOutput{}, nil
+	},
+	ReclaimExpiredMessagesFunc: func(ctx context.Context, params *dynamomq.ReclaimExpiredMessagesInput) (*dynamomq.ReclaimExpiredMessagesOutput, error) {
+		return &dynamomq.ReclaimExpiredMessagesOutput{}, nil
+	},
+	HoldMessageFunc: func(ctx context.Context, params *dynamomq.HoldMessageInput) (*dynamomq.HoldMessageOutput[any], error) {
+		return &dynamomq.HoldMessageOutput[any]{}, nil
+	},
+	ReleaseMessageFunc: func(ctx context.Context, params *dynamomq.ReleaseMessageInput) (*dynamomq.ReleaseMessageOutput[any], error) {
+		return &dynamomq.ReleaseMessageOutput[any]{}, nil
+	},
+}
+
+type DynamoDB struct {
+	GetItemFunc            func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItemFunc            func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	UpdateItemFunc         func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+	DeleteItemFunc         func(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	QueryFunc              func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	ScanFunc               func(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+	DescribeTableFunc      func(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+	CreateTableFunc        func(ctx context.Context, params *dynamodb.CreateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error)
+	DeleteTableFunc        func(ctx context.Context, params *dynamodb.DeleteTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteTableOutput, error)
+	DescribeTimeToLiveFunc func(ctx context.Context, params *dynamodb.DescribeTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error)
+	UpdateTimeToLiveFunc   func(ctx context.Context, params *dynamodb.UpdateTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateTimeToLiveOutput, error)
+	TransactWriteItemsFunc func(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error)
+	BatchWriteItemFunc     func(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+	BatchGetItemFunc       func(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error)
+}
+
+func (m DynamoDB) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	if m.GetItemFunc != nil {
+		return m.GetItemFunc(ctx, params, optFns...)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m DynamoDB) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	if m.PutItemFunc != nil {
+		return m.PutItemFunc(ctx, params, optFns...)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m DynamoDB) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	if m.UpdateItemFunc != nil {
+		return m.UpdateItemFunc(ctx, params, optFns...)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m DynamoDB) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	if m.DeleteItemFunc != nil {
+		return m.DeleteItemFunc(ctx, params, optFns...)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m DynamoDB) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	if m.QueryFunc != nil {
+		return m.QueryFunc(ctx, params, optFns...)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m DynamoDB) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	if m.ScanFunc != nil {
+		return m.ScanFunc(ctx, params, optFns...)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m DynamoDB) DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	if m.DescribeTableFunc != nil {
+		return m.DescribeTableFunc(ctx, params, optFns...)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m DynamoDB) CreateTable(ctx context.Context, params *dynamodb.CreateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error) {
+	if m.CreateTableFunc != nil {
+		return m.CreateTableFunc(ctx, params, optFns...)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m DynamoDB) DeleteTable(ctx context.Context, params *dynamodb.DeleteTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteTableOutput, error) {
+	if m.DeleteTableFunc != nil {
+		return m.DeleteTableFunc(ctx, params, optFns...)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m DynamoDB) DescribeTimeToLive(ctx context.Context, params *dynamodb.DescribeTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error) {
+	if m.DescribeTimeToLiveFunc != nil {
+		return m.DescribeTimeToLiveFunc(ctx, params, optFns...)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m DynamoDB) UpdateTimeToLive(ctx context.Context, params *dynamodb.UpdateTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateTimeToLiveOutput, error) {
+	if m.UpdateTimeToLiveFunc != nil {
+		return m.UpdateTimeToLiveFunc(ctx, params, optFns...)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m DynamoDB) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	if m.TransactWriteItemsFunc != nil {
+		return m.TransactWriteItemsFunc(ctx, params, optFns...)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m DynamoDB) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	if m.BatchWriteItemFunc != nil {
+		return m.BatchWriteItemFunc(ctx, params, optFns...)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m DynamoDB) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	if m.BatchGetItemFunc != nil {
+		return m.BatchGetItemFunc(ctx, params, optFns...)
+	}
+	return nil, ErrNotImplemented
+}
+
+type DynamoDBStreams struct {
+	DescribeStreamFunc   func(ctx context.Context, params *dynamodbstreams.DescribeStreamInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.DescribeStreamOutput, error)
+	GetShardIteratorFunc func(ctx context.Context, params *dynamodbstreams.GetShardIteratorInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetShardIteratorOutput, error)
+	GetRecordsFunc       func(ctx context.Context, params *dynamodbstreams.GetRecordsInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetRecordsOutput, error)
+}
+
+func (m DynamoDBStreams) DescribeStream(ctx context.Context, params *dynamodbstreams.DescribeStreamInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.DescribeStreamOutput, error) {
+	if m.DescribeStreamFunc != nil {
+		return m.DescribeStreamFunc(ctx, params, optFns...)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m DynamoDBStreams) GetShardIterator(ctx context.Context, params *dynamodbstreams.GetShardIteratorInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetShardIteratorOutput, error) {
+	if m.GetShardIteratorFunc != nil {
+		return m.GetShardIteratorFunc(ctx, params, optFns...)
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m DynamoDBStreams) GetRecords(ctx context.Context, params *dynamodbstreams.GetRecordsInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetRecordsOutput, error) {
+	if m.GetRecordsFunc != nil {
+		return m.GetRecordsFunc(ctx, params, optFns...)
+	}
+	return nil, ErrNotImplemented
 }
 
 type Clock struct {
@@ -161,3 +632,27 @@ func WithClock(clock clock.Clock) func(s *dynamomq.ClientOptions) {
 		}
 	}
 }
+
+func WithConsumerClock(clock clock.Clock) func(o *dynamomq.ConsumerOptions) {
+	return func(o *dynamomq.ConsumerOptions) {
+		if clock != nil {
+			o.Clock = clock
+		}
+	}
+}
+
+func WithDLQWatcherClock(clock clock.Clock) func(o *dynamomq.DLQWatcherOptions) {
+	return func(o *dynamomq.DLQWatcherOptions) {
+		if clock != nil {
+			o.Clock = clock
+		}
+	}
+}
+
+func WithReporterClock(clock clock.Clock) func(o *scaling.ReporterOptions) {
+	return func(o *scaling.ReporterOptions) {
+		if clock != nil {
+			o.Clock = clock
+		}
+	}
+}
@@ -60,6 +60,12 @@ func TestMockClient(t *testing.T) {
 				return client.GetMessage(ctx, nil)
 			},
 		},
+		{
+			name: "GetMessageBatch",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.GetMessageBatch(ctx, nil)
+			},
+		},
 		{
 			name: "GetQueueStats",
 			method: func(client *mock.Client[any]) (any, error) {
@@ -78,12 +84,168 @@ func TestMockClient(t *testing.T) {
 				return client.ListMessages(ctx, nil)
 			},
 		},
+		{
+			name: "ListMessageSummaries",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.ListMessageSummaries(ctx, nil)
+			},
+		},
 		{
 			name: "ReplaceMessage",
 			method: func(client *mock.Client[any]) (any, error) {
 				return client.ReplaceMessage(ctx, nil)
 			},
 		},
+		{
+			name: "DescribeQueue",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.DescribeQueue(ctx, nil)
+			},
+		},
+		{
+			name: "VerifyQueue",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.VerifyQueue(ctx, nil)
+			},
+		},
+		{
+			name: "SendMessageTransactWriteItem",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.SendMessageTransactWriteItem(nil)
+			},
+		},
+		{
+			name: "SendMessagesInTransaction",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.SendMessagesInTransaction(ctx, nil)
+			},
+		},
+		{
+			name: "ChainMessage",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.ChainMessage(ctx, nil)
+			},
+		},
+		{
+			name: "DeleteExpiredMessages",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.DeleteExpiredMessages(ctx, nil)
+			},
+		},
+		{
+			name: "AcquireLock",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.AcquireLock(ctx, nil)
+			},
+		},
+		{
+			name: "ReleaseLock",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.ReleaseLock(ctx, nil)
+			},
+		},
+		{
+			name: "SetQueueEnabled",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.SetQueueEnabled(ctx, nil)
+			},
+		},
+		{
+			name: "SaveQueueConfig",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.SaveQueueConfig(ctx, nil)
+			},
+		},
+		{
+			name: "GetQueueConfig",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.GetQueueConfig(ctx, nil)
+			},
+		},
+		{
+			name: "ListInFlightMessages",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.ListInFlightMessages(ctx, nil)
+			},
+		},
+		{
+			name: "ResetReceiveCount",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.ResetReceiveCount(ctx, nil)
+			},
+		},
+		{
+			name: "UpdateMessageData",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.UpdateMessageData(ctx, nil)
+			},
+		},
+		{
+			name: "CopyMessage",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.CopyMessage(ctx, nil)
+			},
+		},
+		{
+			name: "MoveMessage",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.MoveMessage(ctx, nil)
+			},
+		},
+		{
+			name: "ReconcileInFlightCount",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.ReconcileInFlightCount(ctx, nil)
+			},
+		},
+		{
+			name: "MoveMessagesToDLQ",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.MoveMessagesToDLQ(ctx, nil)
+			},
+		},
+		{
+			name: "MoveMessagesToDLQByFilter",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.MoveMessagesToDLQByFilter(ctx, nil)
+			},
+		},
+		{
+			name: "SendCanary",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.SendCanary(ctx, nil)
+			},
+		},
+		{
+			name: "CheckCanary",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.CheckCanary(ctx, nil)
+			},
+		},
+		{
+			name: "PromoteScheduledMessages",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.PromoteScheduledMessages(ctx, nil)
+			},
+		},
+		{
+			name: "ReclaimExpiredMessages",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.ReclaimExpiredMessages(ctx, nil)
+			},
+		},
+		{
+			name: "HoldMessage",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.HoldMessage(ctx, nil)
+			},
+		},
+		{
+			name: "ReleaseMessage",
+			method: func(client *mock.Client[any]) (any, error) {
+				return client.ReleaseMessage(ctx, nil)
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -100,6 +262,49 @@ func TestMockClient(t *testing.T) {
 	}
 }
 
+func TestMockDynamoDB(t *testing.T) {
+	ctx := context.Background()
+	m := &mock.DynamoDB{}
+	var _ dynamomq.DynamoDBAPI = m
+	operations := map[string]func() (any, error){
+		"GetItem":            func() (any, error) { return m.GetItem(ctx, nil) },
+		"PutItem":            func() (any, error) { return m.PutItem(ctx, nil) },
+		"UpdateItem":         func() (any, error) { return m.UpdateItem(ctx, nil) },
+		"DeleteItem":         func() (any, error) { return m.DeleteItem(ctx, nil) },
+		"Query":              func() (any, error) { return m.Query(ctx, nil) },
+		"Scan":               func() (any, error) { return m.Scan(ctx, nil) },
+		"DescribeTable":      func() (any, error) { return m.DescribeTable(ctx, nil) },
+		"CreateTable":        func() (any, error) { return m.CreateTable(ctx, nil) },
+		"DeleteTable":        func() (any, error) { return m.DeleteTable(ctx, nil) },
+		"DescribeTimeToLive": func() (any, error) { return m.DescribeTimeToLive(ctx, nil) },
+		"UpdateTimeToLive":   func() (any, error) { return m.UpdateTimeToLive(ctx, nil) },
+		"TransactWriteItems": func() (any, error) { return m.TransactWriteItems(ctx, nil) },
+		"BatchWriteItem":     func() (any, error) { return m.BatchWriteItem(ctx, nil) },
+		"BatchGetItem":       func() (any, error) { return m.BatchGetItem(ctx, nil) },
+	}
+	for name, operation := range operations {
+		if _, err := operation(); !errors.Is(err, mock.ErrNotImplemented) {
+			t.Errorf("%s: got error %v, want %v", name, err, mock.ErrNotImplemented)
+		}
+	}
+}
+
+func TestMockDynamoDBStreams(t *testing.T) {
+	ctx := context.Background()
+	m := &mock.DynamoDBStreams{}
+	var _ dynamomq.StreamsAPI = m
+	operations := map[string]func() (any, error){
+		"DescribeStream":   func() (any, error) { return m.DescribeStream(ctx, nil) },
+		"GetShardIterator": func() (any, error) { return m.GetShardIterator(ctx, nil) },
+		"GetRecords":       func() (any, error) { return m.GetRecords(ctx, nil) },
+	}
+	for name, operation := range operations {
+		if _, err := operation(); !errors.Is(err, mock.ErrNotImplemented) {
+			t.Errorf("%s: got error %v, want %v", name, err, mock.ErrNotImplemented)
+		}
+	}
+}
+
 func TestMockClockNow(t *testing.T) {
 	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
 	m := mock.Clock{
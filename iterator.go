@@ -0,0 +1,212 @@
+package dynamomq
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	defaultIteratorPollingInterval    = time.Second
+	defaultIteratorMaxPollingInterval = 30 * time.Second
+)
+
+// MessageIteratorOptions contains configuration options for a MessageIterator.
+type MessageIteratorOptions struct {
+	// PollingInterval is the initial wait time before polling again after the queue was found empty.
+	PollingInterval time.Duration
+	// MaxPollingInterval caps the exponential backoff applied while the queue stays empty.
+	MaxPollingInterval time.Duration
+}
+
+// WithIteratorPollingInterval sets the initial wait time before polling again after the queue was found empty.
+// The wait time doubles on each consecutive empty poll until it reaches the maximum polling interval.
+func WithIteratorPollingInterval(pollingInterval time.Duration) func(o *MessageIteratorOptions) {
+	return func(o *MessageIteratorOptions) {
+		o.PollingInterval = pollingInterval
+	}
+}
+
+// WithIteratorMaxPollingInterval sets the upper bound of the backoff applied while the queue stays empty.
+func WithIteratorMaxPollingInterval(maxPollingInterval time.Duration) func(o *MessageIteratorOptions) {
+	return func(o *MessageIteratorOptions) {
+		o.MaxPollingInterval = maxPollingInterval
+	}
+}
+
+// Messages returns a MessageIterator that receives messages from the queue until ctx is done.
+// It is a shorthand for NewMessageIterator bound to this client.
+func (c *ClientImpl[T]) Messages(ctx context.Context, params *ReceiveMessageInput,
+	opts ...func(o *MessageIteratorOptions)) *MessageIterator[T] {
+	return NewMessageIterator[T](ctx, c, params, opts...)
+}
+
+// NewMessageIterator starts a goroutine that repeatedly calls ReceiveMessage with the given parameters and
+// yields every received message through the channel returned by MessageIterator.C.
+// Empty queues and temporary errors are retried with an exponential backoff. The iteration ends when ctx is done
+// or when ReceiveMessage returns an error that is not temporary; in both cases the channel is closed and
+// MessageIterator.Err reports the reason.
+func NewMessageIterator[T any](ctx context.Context, client Client[T], params *ReceiveMessageInput,
+	opts ...func(o *MessageIteratorOptions)) *MessageIterator[T] {
+	o := &MessageIteratorOptions{
+		PollingInterval:    defaultIteratorPollingInterval,
+		MaxPollingInterval: defaultIteratorMaxPollingInterval,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if params == nil {
+		params = &ReceiveMessageInput{}
+	}
+	it := &MessageIterator[T]{
+		client:             client,
+		params:             *params,
+		pollingInterval:    o.PollingInterval,
+		maxPollingInterval: o.MaxPollingInterval,
+		ch:                 make(chan *ReceivedMessage[T]),
+		done:               make(chan struct{}),
+	}
+	go it.run(ctx)
+	return it
+}
+
+// MessageIterator is a pull-based receiver of messages from a DynamoDB-based queue.
+// Note: To create a new instance of MessageIterator, it is necessary to use the NewMessageIterator function.
+type MessageIterator[T any] struct {
+	client             Client[T]
+	params             ReceiveMessageInput
+	pollingInterval    time.Duration
+	maxPollingInterval time.Duration
+
+	ch   chan *ReceivedMessage[T]
+	done chan struct{}
+	err  error
+}
+
+// C returns the channel on which received messages are delivered.
+// The channel is closed when the iteration ends.
+func (it *MessageIterator[T]) C() <-chan *ReceivedMessage[T] {
+	return it.ch
+}
+
+// Done returns a channel that is closed once the iteration has ended and its goroutine has exited.
+func (it *MessageIterator[T]) Done() <-chan struct{} {
+	return it.done
+}
+
+// Err returns the error that ended the iteration. It returns nil while the iteration is still running.
+// When the iteration ended because ctx was done, the context's error is returned.
+func (it *MessageIterator[T]) Err() error {
+	select {
+	case <-it.done:
+		return it.err
+	default:
+		return nil
+	}
+}
+
+func (it *MessageIterator[T]) run(ctx context.Context) {
+	defer close(it.done)
+	defer close(it.ch)
+	wait := it.pollingInterval
+	for {
+		if err := ctx.Err(); err != nil {
+			it.err = err
+			return
+		}
+		params := it.params
+		out, err := it.client.ReceiveMessage(ctx, &params)
+		if err != nil || out == nil || out.ReceivedMessage == nil {
+			if ctx.Err() != nil {
+				it.err = ctx.Err()
+				return
+			}
+			if err != nil && !isTemporary(err) {
+				it.err = err
+				return
+			}
+			if !sleepContext(ctx, wait) {
+				it.err = ctx.Err()
+				return
+			}
+			wait = nextPollingInterval(wait, it.maxPollingInterval)
+			continue
+		}
+		wait = it.pollingInterval
+		msg := &ReceivedMessage[T]{
+			Message: out.ReceivedMessage,
+			client:  it.client,
+		}
+		select {
+		case it.ch <- msg:
+		case <-ctx.Done():
+			// Nobody will process this message, so make it visible again right away
+			// instead of letting it wait out the visibility timeout.
+			_ = msg.Nack(context.WithoutCancel(ctx))
+			it.err = ctx.Err()
+			return
+		}
+	}
+}
+
+func nextPollingInterval(current, maximum time.Duration) time.Duration {
+	next := current * 2
+	if next <= 0 {
+		next = defaultIteratorPollingInterval
+	}
+	if maximum > 0 && next > maximum {
+		return maximum
+	}
+	return next
+}
+
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// ReceivedMessage is a message delivered by a MessageIterator.
+// It embeds the received Message and carries helper methods bound to the client that received it.
+type ReceivedMessage[T any] struct {
+	*Message[T]
+	client Client[T]
+}
+
+// Delete deletes the message from the queue. It should be called once the message has been processed successfully.
+func (m *ReceivedMessage[T]) Delete(ctx context.Context) error {
+	_, err := m.client.DeleteMessage(ctx, &DeleteMessageInput{ID: m.ID})
+	return err
+}
+
+// Nack makes the message visible in the queue again immediately so that it can be received by another consumer.
+func (m *ReceivedMessage[T]) Nack(ctx context.Context) error {
+	return m.changeVisibility(ctx, 0)
+}
+
+// ExtendVisibility changes the visibility timeout of the message to the given number of seconds from now.
+// Use this function to keep the message invisible to other consumers while its processing takes longer than expected.
+func (m *ReceivedMessage[T]) ExtendVisibility(ctx context.Context, visibilityTimeout int) error {
+	return m.changeVisibility(ctx, visibilityTimeout)
+}
+
+func (m *ReceivedMessage[T]) changeVisibility(ctx context.Context, visibilityTimeout int) error {
+	out, err := m.client.ChangeMessageVisibility(ctx, &ChangeMessageVisibilityInput{
+		ID:                m.ID,
+		VisibilityTimeout: visibilityTimeout,
+	})
+	if err != nil {
+		return err
+	}
+	if out.ChangedMessage != nil {
+		m.Message = out.ChangedMessage
+	}
+	return nil
+}
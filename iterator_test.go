@@ -0,0 +1,171 @@
+package dynamomq_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestMessageIteratorYieldsMessages(t *testing.T) {
+	t.Parallel()
+	queue, _, _ := prepareQueueAndStore(3, 0)
+	client := &mock.Client[test.MessageData]{
+		ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+			select {
+			case msg := <-queue:
+				return &dynamomq.ReceiveMessageOutput[test.MessageData]{ReceivedMessage: msg}, nil
+			default:
+				return nil, &dynamomq.EmptyQueueError{}
+			}
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	it := dynamomq.NewMessageIterator[test.MessageData](ctx, client, nil,
+		dynamomq.WithIteratorPollingInterval(time.Millisecond),
+		dynamomq.WithIteratorMaxPollingInterval(2*time.Millisecond))
+	var ids []string
+	for msg := range it.C() {
+		ids = append(ids, msg.ID)
+		if len(ids) == 3 {
+			cancel()
+		}
+	}
+	test.AssertDeepEqual(t, ids, []string{"A-1", "A-2", "A-3"}, "C()")
+	test.AssertError(t, it.Err(), context.Canceled, "Err()")
+}
+
+func TestMessageIteratorStopsOnNonTemporaryError(t *testing.T) {
+	t.Parallel()
+	client := &mock.Client[test.MessageData]{
+		ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+			return nil, test.ErrTest
+		},
+	}
+	it := dynamomq.NewMessageIterator[test.MessageData](context.Background(), client, nil)
+	for range it.C() {
+		t.Error("C() yielded a message, want none")
+	}
+	<-it.Done()
+	test.AssertError(t, it.Err(), test.ErrTest, "Err()")
+}
+
+func TestMessageIteratorBacksOffOnEmptyQueue(t *testing.T) {
+	t.Parallel()
+	var (
+		mu    sync.Mutex
+		calls []time.Time
+	)
+	client := &mock.Client[test.MessageData]{
+		ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls = append(calls, time.Now())
+			return nil, &dynamomq.EmptyQueueError{}
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 350*time.Millisecond)
+	defer cancel()
+	it := dynamomq.NewMessageIterator[test.MessageData](ctx, client, nil,
+		dynamomq.WithIteratorPollingInterval(20*time.Millisecond),
+		dynamomq.WithIteratorMaxPollingInterval(time.Second))
+	<-it.Done()
+	test.AssertError(t, it.Err(), context.DeadlineExceeded, "Err()")
+	mu.Lock()
+	defer mu.Unlock()
+	// With 20ms, 40ms, 80ms, 160ms waits only five polls fit into 350ms.
+	if len(calls) < 3 || len(calls) > 5 {
+		t.Errorf("ReceiveMessage() calls = %d, want between 3 and 5", len(calls))
+	}
+}
+
+func TestMessageIteratorNacksUndeliveredMessageOnCancel(t *testing.T) {
+	t.Parallel()
+	var nacked atomic.Value
+	received := make(chan struct{})
+	client := &mock.Client[test.MessageData]{
+		ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+			defer close(received)
+			return &dynamomq.ReceiveMessageOutput[test.MessageData]{
+				ReceivedMessage: NewTestMessageItemAsProcessing("A-101", test.DefaultTestDate),
+			}, nil
+		},
+		ChangeMessageVisibilityFunc: func(ctx context.Context, params *dynamomq.ChangeMessageVisibilityInput) (*dynamomq.ChangeMessageVisibilityOutput[test.MessageData], error) {
+			nacked.Store(*params)
+			return &dynamomq.ChangeMessageVisibilityOutput[test.MessageData]{}, nil
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	it := dynamomq.NewMessageIterator[test.MessageData](ctx, client, nil)
+	<-received
+	cancel()
+	select {
+	case <-it.Done():
+	case <-time.After(time.Second):
+		t.Fatal("iterator goroutine did not exit after cancellation")
+	}
+	if _, ok := <-it.C(); ok {
+		t.Error("C() is not closed")
+	}
+	test.AssertDeepEqual(t, nacked.Load(), dynamomq.ChangeMessageVisibilityInput{ID: "A-101"}, "Nack")
+}
+
+func TestReceivedMessageHelpers(t *testing.T) {
+	t.Parallel()
+	var (
+		deleted    string
+		visibility []dynamomq.ChangeMessageVisibilityInput
+	)
+	extended := NewTestMessageItemAsProcessing("A-101", test.DefaultTestDate.Add(time.Minute))
+	var receives atomic.Int32
+	client := &mock.Client[test.MessageData]{
+		ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+			if receives.Add(1) > 1 {
+				return nil, &dynamomq.EmptyQueueError{}
+			}
+			return &dynamomq.ReceiveMessageOutput[test.MessageData]{
+				ReceivedMessage: NewTestMessageItemAsProcessing("A-101", test.DefaultTestDate),
+			}, nil
+		},
+		DeleteMessageFunc: func(ctx context.Context, params *dynamomq.DeleteMessageInput) (*dynamomq.DeleteMessageOutput, error) {
+			deleted = params.ID
+			return &dynamomq.DeleteMessageOutput{}, nil
+		},
+		ChangeMessageVisibilityFunc: func(ctx context.Context, params *dynamomq.ChangeMessageVisibilityInput) (*dynamomq.ChangeMessageVisibilityOutput[test.MessageData], error) {
+			visibility = append(visibility, *params)
+			if params.VisibilityTimeout == 0 {
+				return nil, test.ErrTest
+			}
+			return &dynamomq.ChangeMessageVisibilityOutput[test.MessageData]{ChangedMessage: extended}, nil
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	it := dynamomq.NewMessageIterator[test.MessageData](ctx, client, nil)
+	msg := <-it.C()
+	cancel()
+	<-it.Done()
+
+	if err := msg.ExtendVisibility(context.Background(), 60); err != nil {
+		t.Errorf("ExtendVisibility() error = %v", err)
+	}
+	test.AssertDeepEqual(t, msg.Message, extended, "ExtendVisibility()")
+	if err := msg.Nack(context.Background()); !errors.Is(err, test.ErrTest) {
+		t.Errorf("Nack() error = %v, want %v", err, test.ErrTest)
+	}
+	if err := msg.Delete(context.Background()); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if deleted != "A-101" {
+		t.Errorf("Delete() id = %v, want %v", deleted, "A-101")
+	}
+	if len(visibility) != 2 || visibility[0].VisibilityTimeout != 60 || visibility[1].VisibilityTimeout != 0 {
+		t.Errorf("ChangeMessageVisibility() calls = %v", visibility)
+	}
+}
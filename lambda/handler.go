@@ -0,0 +1,259 @@
+// Package lambda adapts DynamoMQ message processing to AWS Lambda.
+// The handler returned by NewHandler receives a batch of messages on each invocation, processes them within
+// the time left to the function, deletes the ones that succeeded, and retries or moves to the DLQ the ones that failed.
+// It has the signature expected by lambda.Start of github.com/aws/aws-lambda-go, so that it can be triggered
+// on a schedule or by any other event whose payload is ignored.
+package lambda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/constant"
+)
+
+const (
+	defaultMaxMessages            = 10
+	defaultRetryIntervalInSeconds = 1
+	defaultDeadlineMargin         = 2 * time.Second
+)
+
+// ProcessFunc processes a single message. The context is canceled when the deadline of the message is exceeded.
+type ProcessFunc[T any] func(ctx context.Context, msg *dynamomq.Message[T]) error
+
+// Options contains configuration options for a handler created by NewHandler.
+type Options struct {
+	// MaxMessages is the maximum number of messages received in one invocation.
+	MaxMessages int
+	// QueueType determines the type of queue (STANDARD or DLQ) the handler will operate on.
+	QueueType dynamomq.QueueType
+	// VisibilityTimeout sets the duration (in seconds) a message remains invisible in the queue after being received.
+	// A message is never given more time than its visibility timeout.
+	VisibilityTimeout int
+	// MaximumReceives defines the maximum number of times a message can be delivered before it is moved to the DLQ.
+	// Zero means unlimited.
+	MaximumReceives int
+	// RetryInterval defines the time interval (in seconds) before a failed message is retried.
+	RetryInterval int
+	// DeadlineMargin is the time kept before the end of the invocation to delete, retry or move the messages.
+	DeadlineMargin time.Duration
+	// ErrorLog is an optional logger for errors. If nil, the standard logger is used.
+	ErrorLog *log.Logger
+}
+
+// WithMaxMessages sets the maximum number of messages received in one invocation.
+func WithMaxMessages(maxMessages int) func(o *Options) {
+	return func(o *Options) {
+		o.MaxMessages = maxMessages
+	}
+}
+
+// WithQueueType sets the type of queue (STANDARD or DLQ) the handler will operate on.
+func WithQueueType(queueType dynamomq.QueueType) func(o *Options) {
+	return func(o *Options) {
+		o.QueueType = queueType
+	}
+}
+
+// WithVisibilityTimeout sets the visibility timeout in seconds for received messages.
+func WithVisibilityTimeout(sec int) func(o *Options) {
+	return func(o *Options) {
+		o.VisibilityTimeout = sec
+	}
+}
+
+// WithMaximumReceives sets the maximum number of times a message can be delivered before it is moved to the DLQ.
+func WithMaximumReceives(maximumReceives int) func(o *Options) {
+	return func(o *Options) {
+		o.MaximumReceives = maximumReceives
+	}
+}
+
+// WithRetryInterval sets the time interval (in seconds) before a failed message is retried.
+func WithRetryInterval(sec int) func(o *Options) {
+	return func(o *Options) {
+		o.RetryInterval = sec
+	}
+}
+
+// WithDeadlineMargin sets the time kept before the end of the invocation to settle the messages.
+func WithDeadlineMargin(margin time.Duration) func(o *Options) {
+	return func(o *Options) {
+		o.DeadlineMargin = margin
+	}
+}
+
+// WithErrorLog sets a custom logger for the handler.
+func WithErrorLog(errorLog *log.Logger) func(o *Options) {
+	return func(o *Options) {
+		o.ErrorLog = errorLog
+	}
+}
+
+// Response reports the outcome of an invocation, in the style of the batch item failures report of Lambda.
+type Response struct {
+	// Processed is the number of messages that were processed successfully and deleted.
+	Processed int `json:"processed"`
+	// BatchItemFailures lists the messages that could not be processed or deleted.
+	BatchItemFailures []BatchItemFailure `json:"batchItemFailures"`
+}
+
+// BatchItemFailure identifies a message that failed during an invocation.
+type BatchItemFailure struct {
+	// ItemIdentifier is the ID of the message.
+	ItemIdentifier string `json:"itemIdentifier"`
+	// Reason describes why the message failed.
+	Reason string `json:"reason"`
+	// MovedToDLQ is true if the message was moved to the DLQ instead of being retried.
+	MovedToDLQ bool `json:"movedToDLQ"`
+}
+
+// Handler is the function invoked by AWS Lambda.
+type Handler func(ctx context.Context) (*Response, error)
+
+// NewHandler creates a Lambda handler that receives up to MaxMessages messages from the client on each invocation
+// and processes them one by one with process.
+// Each message is given a deadline that ends DeadlineMargin before the deadline of the invocation, and no later than
+// its visibility timeout. No more messages are received once less than DeadlineMargin is left.
+// Messages that were processed successfully are deleted. Failed messages are retried after RetryInterval
+// until they have been received MaximumReceives times, and then moved to the DLQ, or deleted when the handler
+// operates on the DLQ, like Consumer does.
+// The handler returns an error only if messages cannot be received at all.
+func NewHandler[T any](client dynamomq.Client[T], process ProcessFunc[T], opts ...func(o *Options)) Handler {
+	o := &Options{
+		MaxMessages:       defaultMaxMessages,
+		QueueType:         dynamomq.QueueTypeStandard,
+		VisibilityTimeout: constant.DefaultVisibilityTimeoutInSeconds,
+		RetryInterval:     defaultRetryIntervalInSeconds,
+		DeadlineMargin:    defaultDeadlineMargin,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	h := &handler[T]{
+		client:  client,
+		process: process,
+		options: *o,
+	}
+	return h.handle
+}
+
+type handler[T any] struct {
+	client  dynamomq.Client[T]
+	process ProcessFunc[T]
+	options Options
+}
+
+func (h *handler[T]) handle(ctx context.Context) (*Response, error) {
+	res := &Response{
+		BatchItemFailures: []BatchItemFailure{},
+	}
+	for i := 0; i < h.options.MaxMessages && h.hasTimeLeft(ctx); i++ {
+		r, err := h.client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{
+			QueueType:         h.options.QueueType,
+			VisibilityTimeout: h.options.VisibilityTimeout,
+		})
+		if err != nil {
+			var (
+				queuePaused      *dynamomq.QueuePausedError
+				inFlightExceeded dynamomq.InFlightLimitExceededError
+			)
+			if errors.Is(err, dynamomq.ErrEmptyQueue) || errors.As(err, &queuePaused) || errors.As(err, &inFlightExceeded) {
+				break
+			}
+			if i == 0 {
+				return res, fmt.Errorf("DynamoMQ: Failed to receive a message: %w", err)
+			}
+			h.logf("DynamoMQ: Failed to receive a message. %s", err)
+			break
+		}
+		if r == nil || r.ReceivedMessage == nil {
+			break
+		}
+		h.handleMessage(ctx, r.ReceivedMessage, res)
+	}
+	return res, nil
+}
+
+func (h *handler[T]) handleMessage(ctx context.Context, msg *dynamomq.Message[T], res *Response) {
+	msgCtx, cancel := context.WithDeadline(ctx, h.messageDeadline(ctx))
+	err := h.process(msgCtx, msg)
+	cancel()
+	if err != nil {
+		res.BatchItemFailures = append(res.BatchItemFailures, h.handleError(ctx, msg, err))
+		return
+	}
+	if _, err := h.client.DeleteMessage(ctx, &dynamomq.DeleteMessageInput{ID: msg.ID}); err != nil {
+		h.logf("DynamoMQ: Failed to delete a message. %s", err)
+		res.BatchItemFailures = append(res.BatchItemFailures, BatchItemFailure{
+			ItemIdentifier: msg.ID,
+			Reason:         fmt.Sprintf("failed to delete the message: %v", err),
+		})
+		return
+	}
+	res.Processed++
+}
+
+func (h *handler[T]) handleError(ctx context.Context, msg *dynamomq.Message[T], err error) BatchItemFailure {
+	failure := BatchItemFailure{
+		ItemIdentifier: msg.ID,
+		Reason:         err.Error(),
+	}
+	if h.options.MaximumReceives == 0 || msg.ReceiveCount < h.options.MaximumReceives {
+		if _, err := h.client.ChangeMessageVisibility(ctx, &dynamomq.ChangeMessageVisibilityInput{
+			ID:                msg.ID,
+			VisibilityTimeout: h.options.RetryInterval,
+		}); err != nil {
+			h.logf("DynamoMQ: Failed to update a message as visible. %s", err)
+		}
+		return failure
+	}
+	switch h.options.QueueType {
+	case dynamomq.QueueTypeStandard:
+		if _, err := h.client.MoveMessageToDLQ(ctx, &dynamomq.MoveMessageToDLQInput{
+			ID:     msg.ID,
+			Reason: failure.Reason,
+		}); err != nil {
+			h.logf("DynamoMQ: Failed to move a message to DLQ. %s", err)
+			return failure
+		}
+		failure.MovedToDLQ = true
+	case dynamomq.QueueTypeDLQ:
+		if _, err := h.client.DeleteMessage(ctx, &dynamomq.DeleteMessageInput{ID: msg.ID}); err != nil {
+			h.logf("DynamoMQ: Failed to delete a message. %s", err)
+		}
+	}
+	return failure
+}
+
+// messageDeadline returns the deadline of a message received now: DeadlineMargin before the deadline of
+// the invocation, and no later than the end of the visibility timeout.
+func (h *handler[T]) messageDeadline(ctx context.Context) time.Time {
+	deadline := time.Now().Add(time.Duration(h.options.VisibilityTimeout) * time.Second)
+	if invocationDeadline, ok := ctx.Deadline(); ok {
+		if d := invocationDeadline.Add(-h.options.DeadlineMargin); d.Before(deadline) {
+			deadline = d
+		}
+	}
+	return deadline
+}
+
+func (h *handler[T]) hasTimeLeft(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Until(deadline) > h.options.DeadlineMargin
+}
+
+func (h *handler[T]) logf(format string, args ...any) {
+	if h.options.ErrorLog != nil {
+		h.options.ErrorLog.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
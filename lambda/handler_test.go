@@ -0,0 +1,209 @@
+package lambda_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+	"github.com/vvatanabe/dynamomq/lambda"
+)
+
+type fakeQueue struct {
+	messages []*dynamomq.Message[test.MessageData]
+	deleted  []string
+	retried  []string
+	moved    []string
+	// emptyAsNil makes the queue behave like a client created with dynamomq.WithEmptyReceiveAsNil.
+	emptyAsNil bool
+}
+
+func (q *fakeQueue) client() *mock.Client[test.MessageData] {
+	return &mock.Client[test.MessageData]{
+		ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+			if len(q.messages) == 0 {
+				if q.emptyAsNil {
+					return nil, nil
+				}
+				return nil, &dynamomq.EmptyQueueError{}
+			}
+			msg := q.messages[0]
+			q.messages = q.messages[1:]
+			return &dynamomq.ReceiveMessageOutput[test.MessageData]{ReceivedMessage: msg}, nil
+		},
+		DeleteMessageFunc: func(ctx context.Context, params *dynamomq.DeleteMessageInput) (*dynamomq.DeleteMessageOutput, error) {
+			q.deleted = append(q.deleted, params.ID)
+			return &dynamomq.DeleteMessageOutput{}, nil
+		},
+		ChangeMessageVisibilityFunc: func(ctx context.Context, params *dynamomq.ChangeMessageVisibilityInput) (*dynamomq.ChangeMessageVisibilityOutput[test.MessageData], error) {
+			q.retried = append(q.retried, params.ID)
+			return &dynamomq.ChangeMessageVisibilityOutput[test.MessageData]{}, nil
+		},
+		MoveMessageToDLQFunc: func(ctx context.Context, params *dynamomq.MoveMessageToDLQInput) (*dynamomq.MoveMessageToDLQOutput[test.MessageData], error) {
+			q.moved = append(q.moved, params.ID)
+			return &dynamomq.MoveMessageToDLQOutput[test.MessageData]{}, nil
+		},
+	}
+}
+
+func newReceivedMessage(id string, receiveCount int) *dynamomq.Message[test.MessageData] {
+	msg := dynamomq.NewMessage(id, test.NewMessageData(id), test.DefaultTestDate)
+	msg.ReceiveCount = receiveCount
+	return msg
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+	queue := &fakeQueue{
+		messages: []*dynamomq.Message[test.MessageData]{
+			newReceivedMessage("A-101", 1),
+			newReceivedMessage("A-102", 1),
+			newReceivedMessage("A-103", 3),
+		},
+	}
+	handler := lambda.NewHandler[test.MessageData](queue.client(),
+		func(ctx context.Context, msg *dynamomq.Message[test.MessageData]) error {
+			if msg.ID == "A-101" {
+				return nil
+			}
+			return test.ErrTest
+		},
+		lambda.WithMaximumReceives(3))
+	got, err := handler(context.Background())
+	if err != nil {
+		t.Fatalf("handler() error = %v", err)
+	}
+	want := &lambda.Response{
+		Processed: 1,
+		BatchItemFailures: []lambda.BatchItemFailure{
+			{ItemIdentifier: "A-102", Reason: test.ErrTest.Error()},
+			{ItemIdentifier: "A-103", Reason: test.ErrTest.Error(), MovedToDLQ: true},
+		},
+	}
+	test.AssertDeepEqual(t, got, want, "handler()")
+	test.AssertDeepEqual(t, queue.deleted, []string{"A-101"}, "deleted")
+	test.AssertDeepEqual(t, queue.retried, []string{"A-102"}, "retried")
+	test.AssertDeepEqual(t, queue.moved, []string{"A-103"}, "moved to DLQ")
+}
+
+func TestHandlerStopsAtEmptyReceiveAsNil(t *testing.T) {
+	t.Parallel()
+	queue := &fakeQueue{
+		messages:   []*dynamomq.Message[test.MessageData]{newReceivedMessage("A-101", 1)},
+		emptyAsNil: true,
+	}
+	handler := lambda.NewHandler[test.MessageData](queue.client(),
+		func(ctx context.Context, msg *dynamomq.Message[test.MessageData]) error { return nil })
+	got, err := handler(context.Background())
+	if err != nil {
+		t.Fatalf("handler() error = %v", err)
+	}
+	test.AssertDeepEqual(t, got, &lambda.Response{Processed: 1, BatchItemFailures: []lambda.BatchItemFailure{}}, "handler()")
+	test.AssertDeepEqual(t, queue.deleted, []string{"A-101"}, "deleted")
+}
+
+func TestHandlerStopsAtMaxMessages(t *testing.T) {
+	t.Parallel()
+	queue := &fakeQueue{
+		messages: []*dynamomq.Message[test.MessageData]{
+			newReceivedMessage("A-101", 1),
+			newReceivedMessage("A-102", 1),
+		},
+	}
+	handler := lambda.NewHandler[test.MessageData](queue.client(),
+		func(ctx context.Context, msg *dynamomq.Message[test.MessageData]) error { return nil },
+		lambda.WithMaxMessages(1))
+	got, err := handler(context.Background())
+	if err != nil {
+		t.Fatalf("handler() error = %v", err)
+	}
+	if got.Processed != 1 || len(queue.messages) != 1 {
+		t.Errorf("handler() processed = %d, left = %d, want 1 and 1", got.Processed, len(queue.messages))
+	}
+}
+
+func TestHandlerMessageDeadline(t *testing.T) {
+	t.Parallel()
+	queue := &fakeQueue{
+		messages: []*dynamomq.Message[test.MessageData]{newReceivedMessage("A-101", 1)},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	invocationDeadline, _ := ctx.Deadline()
+	handler := lambda.NewHandler[test.MessageData](queue.client(),
+		func(ctx context.Context, msg *dynamomq.Message[test.MessageData]) error {
+			deadline, ok := ctx.Deadline()
+			if !ok {
+				t.Fatal("message context has no deadline")
+			}
+			if want := invocationDeadline.Add(-2 * time.Second); !deadline.Equal(want) {
+				t.Errorf("message deadline = %v, want %v", deadline, want)
+			}
+			return nil
+		},
+		lambda.WithDeadlineMargin(2*time.Second))
+	if _, err := handler(ctx); err != nil {
+		t.Fatalf("handler() error = %v", err)
+	}
+}
+
+func TestHandlerDoesNotReceiveWithoutTimeLeft(t *testing.T) {
+	t.Parallel()
+	client := &mock.Client[test.MessageData]{
+		ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+			t.Error("ReceiveMessage() was called without time left")
+			return nil, &dynamomq.EmptyQueueError{}
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	handler := lambda.NewHandler[test.MessageData](client,
+		func(ctx context.Context, msg *dynamomq.Message[test.MessageData]) error { return nil },
+		lambda.WithDeadlineMargin(2*time.Second))
+	got, err := handler(ctx)
+	if err != nil {
+		t.Fatalf("handler() error = %v", err)
+	}
+	if got.Processed != 0 {
+		t.Errorf("handler() processed = %d, want 0", got.Processed)
+	}
+}
+
+func TestHandlerReceiveError(t *testing.T) {
+	t.Parallel()
+	client := &mock.Client[test.MessageData]{
+		ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+			return nil, test.ErrTest
+		},
+	}
+	handler := lambda.NewHandler[test.MessageData](client,
+		func(ctx context.Context, msg *dynamomq.Message[test.MessageData]) error { return nil },
+		lambda.WithErrorLog(log.New(io.Discard, "", 0)))
+	if _, err := handler(context.Background()); !errors.Is(err, test.ErrTest) {
+		t.Errorf("handler() error = %v, want %v", err, test.ErrTest)
+	}
+}
+
+func TestHandlerStopsAtInFlightLimit(t *testing.T) {
+	t.Parallel()
+	client := &mock.Client[test.MessageData]{
+		ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+			return nil, dynamomq.InFlightLimitExceededError{Limit: 10}
+		},
+	}
+	handler := lambda.NewHandler[test.MessageData](client,
+		func(ctx context.Context, msg *dynamomq.Message[test.MessageData]) error { return nil },
+		lambda.WithErrorLog(log.New(io.Discard, "", 0)))
+	res, err := handler(context.Background())
+	if err != nil {
+		t.Fatalf("handler() error = %v", err)
+	}
+	if res.Processed != 0 || len(res.BatchItemFailures) != 0 {
+		t.Errorf("handler() response = %+v, want an empty response", res)
+	}
+}
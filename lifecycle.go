@@ -0,0 +1,93 @@
+package dynamomq
+
+import (
+	"fmt"
+	"time"
+)
+
+// Statuses of the lifecycle of a message beyond StatusReady and StatusProcessing. They are reported by
+// LifecycleStatus, which tells the messages of the DLQ apart, while GetStatus reports them as READY or PROCESSING
+// like the messages of any queue.
+const (
+	// StatusDLQ indicates that a message is in the DLQ and not being processed.
+	StatusDLQ Status = "DLQ"
+	// StatusDLQProcessing indicates that a message is being processed from the DLQ.
+	StatusDLQProcessing Status = "DLQ_PROCESSING"
+	// StatusDeleted indicates that a message has been deleted. No transition leaves it.
+	StatusDeleted Status = "DELETED"
+)
+
+// transitions is the state machine of a message: the statuses each status may go to.
+// A change that keeps the status, such as extending the visibility timeout of a message being processed,
+// is not a transition.
+var transitions = map[Status][]Status{
+	// ReceiveMessage, MoveMessageToDLQ and DeleteMessage.
+	StatusReady: {StatusProcessing, StatusDLQ, StatusDeleted},
+	// ChangeMessageVisibility or the expiry of the visibility timeout, MoveMessageToDLQ and DeleteMessage.
+	StatusProcessing: {StatusReady, StatusDLQ, StatusDeleted},
+	// RedriveMessage, ReceiveMessage from the DLQ and DeleteMessage.
+	StatusDLQ: {StatusReady, StatusDLQProcessing, StatusDeleted},
+	// ChangeMessageVisibility or the expiry of the visibility timeout, and DeleteMessage.
+	StatusDLQProcessing: {StatusDLQ, StatusDeleted},
+	StatusDeleted:       nil,
+}
+
+// Statuses returns every status of the lifecycle of a message, in the order of the state machine.
+func Statuses() []Status {
+	return []Status{StatusReady, StatusProcessing, StatusDLQ, StatusDLQProcessing, StatusDeleted}
+}
+
+// CanTransition reports whether a message may go from the status from to the status to.
+// The legal transitions are READY to PROCESSING and back, READY and PROCESSING to DLQ, DLQ to READY,
+// DLQ to DLQ_PROCESSING and back, and any status but DELETED to DELETED.
+func CanTransition(from, to Status) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransition returns an InvalidStateTransitionError if the operation cannot move a message
+// from the status from to the status to.
+func ValidateTransition(operation string, from, to Status) error {
+	if CanTransition(from, to) {
+		return nil
+	}
+	return InvalidStateTransitionError{
+		Msg:       fmt.Sprintf("cannot go from %s to %s", from, to),
+		Operation: operation,
+		Current:   from,
+		Requested: to,
+	}
+}
+
+// LifecycleStatus returns the status of the message in the state machine of CanTransition at the provided time.
+func (m *Message[T]) LifecycleStatus(now time.Time) Status {
+	processing := m.IsProcessing(now)
+	switch {
+	case m.IsDLQ() && processing:
+		return StatusDLQProcessing
+	case m.IsDLQ():
+		return StatusDLQ
+	case processing:
+		return StatusProcessing
+	default:
+		return StatusReady
+	}
+}
+
+// visibilityStatus returns the status of the message once its visibility timeout is set to the provided duration.
+func (m *Message[T]) visibilityStatus(visibilityTimeout time.Duration) Status {
+	switch {
+	case m.IsDLQ() && visibilityTimeout > 0:
+		return StatusDLQProcessing
+	case m.IsDLQ():
+		return StatusDLQ
+	case visibilityTimeout > 0:
+		return StatusProcessing
+	default:
+		return StatusReady
+	}
+}
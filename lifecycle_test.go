@@ -0,0 +1,97 @@
+package dynamomq_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestCanTransition(t *testing.T) {
+	t.Parallel()
+	legal := map[[2]dynamomq.Status]bool{
+		{dynamomq.StatusReady, dynamomq.StatusProcessing}:      true,
+		{dynamomq.StatusReady, dynamomq.StatusDLQ}:             true,
+		{dynamomq.StatusReady, dynamomq.StatusDeleted}:         true,
+		{dynamomq.StatusProcessing, dynamomq.StatusReady}:      true,
+		{dynamomq.StatusProcessing, dynamomq.StatusDLQ}:        true,
+		{dynamomq.StatusProcessing, dynamomq.StatusDeleted}:    true,
+		{dynamomq.StatusDLQ, dynamomq.StatusReady}:             true,
+		{dynamomq.StatusDLQ, dynamomq.StatusDLQProcessing}:     true,
+		{dynamomq.StatusDLQ, dynamomq.StatusDeleted}:           true,
+		{dynamomq.StatusDLQProcessing, dynamomq.StatusDLQ}:     true,
+		{dynamomq.StatusDLQProcessing, dynamomq.StatusDeleted}: true,
+	}
+	for _, from := range dynamomq.Statuses() {
+		for _, to := range dynamomq.Statuses() {
+			want := legal[[2]dynamomq.Status{from, to}]
+			if got := dynamomq.CanTransition(from, to); got != want {
+				t.Errorf("CanTransition(%s, %s) = %v, want %v", from, to, got, want)
+			}
+			err := dynamomq.ValidateTransition("test", from, to)
+			if want {
+				if err != nil {
+					t.Errorf("ValidateTransition(%s, %s) error = %v, want nil", from, to, err)
+				}
+				continue
+			}
+			var invalid dynamomq.InvalidStateTransitionError
+			if !errors.As(err, &invalid) || invalid.Current != from || invalid.Requested != to {
+				t.Errorf("ValidateTransition(%s, %s) error = %v, want %T from %s to %s", from, to, err, invalid, from, to)
+			}
+		}
+	}
+}
+
+func TestMessageLifecycleStatus(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate
+	data := test.NewMessageData("A-101")
+	tests := []struct {
+		name    string
+		message *dynamomq.Message[test.MessageData]
+		want    dynamomq.Status
+	}{
+		{
+			name:    "ready",
+			message: dynamomqtest.NewReadyMessage("A-101", data, now),
+			want:    dynamomq.StatusReady,
+		},
+		{
+			name:    "processing",
+			message: dynamomqtest.NewProcessingMessage("A-101", data, now),
+			want:    dynamomq.StatusProcessing,
+		},
+		{
+			name:    "processing after the visibility timeout",
+			message: dynamomqtest.NewProcessingMessage("A-101", data, now.Add(-time.Hour)),
+			want:    dynamomq.StatusReady,
+		},
+		{
+			name:    "in the DLQ",
+			message: dynamomqtest.NewDLQMessage("A-101", data, now),
+			want:    dynamomq.StatusDLQ,
+		},
+		{
+			name: "processing from the DLQ",
+			message: func() *dynamomq.Message[test.MessageData] {
+				m := dynamomqtest.NewDLQMessage("A-101", data, now)
+				dynamomqtest.MarkAsProcessing(m, now)
+				return m
+			}(),
+			want: dynamomq.StatusDLQProcessing,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.message.LifecycleStatus(now); got != tt.want {
+				t.Errorf("LifecycleStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
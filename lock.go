@@ -0,0 +1,138 @@
+package dynamomq
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	// AttributeNameLockOwner holds the owner of a lock item.
+	AttributeNameLockOwner = "lock_owner"
+	// AttributeNameLockExpiresAt holds the expiry of a lock item in Unix seconds,
+	// so that it can also be used as the TTL attribute of the table.
+	AttributeNameLockExpiresAt = "lock_expires_at"
+
+	lockIDPrefix   = "dynamomq-lock#"
+	defaultLockTTL = time.Minute
+)
+
+// AcquireLockInput represents the input parameters for acquiring a lock item stored in the queue table.
+type AcquireLockInput struct {
+	// Name identifies the lock. Every instance contending for the same work must use the same name.
+	Name string
+	// Owner identifies the instance acquiring the lock.
+	Owner string
+	// TTL is the duration after which the lock expires unless it is acquired again by its owner.
+	// If zero, it is one minute.
+	TTL time.Duration
+}
+
+// AcquireLockOutput represents the result of the operation to acquire a lock.
+type AcquireLockOutput struct {
+	// Acquired is true if the caller holds the lock until ExpiresAt.
+	Acquired bool
+	// ExpiresAt is the time at which the lock expires if Acquired is true.
+	ExpiresAt time.Time
+}
+
+// AcquireLock acquires a lock item stored in the queue table, or extends it if the owner already holds it.
+// The lock is written with a conditional put that succeeds only if the lock does not exist, belongs to the owner,
+// or has expired, so that only one owner holds it at a time. Losing the race is not an error: Acquired is false.
+// Owners keep the lock by calling AcquireLock again before it expires.
+// Lock items have no queue type, so they are never received as messages.
+func (c *ClientImpl[T]) AcquireLock(ctx context.Context, params *AcquireLockInput) (*AcquireLockOutput, error) {
+	if params == nil {
+		params = &AcquireLockInput{}
+	}
+	if params.Name == "" || params.Owner == "" {
+		return &AcquireLockOutput{}, &IDNotProvidedError{}
+	}
+	ttl := params.TTL
+	if ttl <= 0 {
+		ttl = defaultLockTTL
+	}
+	now := c.clock.Now()
+	expiresAt := now.Add(ttl)
+	id := lockIDPrefix + params.Name
+	item := c.itemKey(id)
+	item[c.schema.IDAttribute] = &types.AttributeValueMemberS{Value: id}
+	item[AttributeNameLockOwner] = &types.AttributeValueMemberS{Value: params.Owner}
+	item[AttributeNameLockExpiresAt] = &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt.Unix(), 10)}
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.AttributeNotExists(expression.Name(c.schema.PartitionKeyAttribute)).
+			Or(expression.Name(AttributeNameLockOwner).Equal(expression.Value(params.Owner)),
+				expression.Name(AttributeNameLockExpiresAt).LessThanEqual(expression.Value(now.Unix())))).
+		Build()
+	if err != nil {
+		return &AcquireLockOutput{}, BuildingExpressionError{Cause: err}
+	}
+	_, err = c.dynamoDB.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(c.tableName),
+		Item:                      item,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		var cause *types.ConditionalCheckFailedException
+		if errors.As(err, &cause) {
+			return &AcquireLockOutput{}, nil
+		}
+		return &AcquireLockOutput{}, handleDynamoDBError(err)
+	}
+	return &AcquireLockOutput{
+		Acquired:  true,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// ReleaseLockInput represents the input parameters for releasing a lock item.
+type ReleaseLockInput struct {
+	// Name identifies the lock.
+	Name string
+	// Owner identifies the instance releasing the lock.
+	Owner string
+}
+
+// ReleaseLockOutput represents the result of the operation to release a lock.
+// This struct is empty as the release operation does not return any specific information.
+type ReleaseLockOutput struct{}
+
+// ReleaseLock deletes a lock item if it is held by the owner, so that another instance can acquire it
+// without waiting for it to expire. Releasing a lock held by another owner does nothing.
+func (c *ClientImpl[T]) ReleaseLock(ctx context.Context, params *ReleaseLockInput) (*ReleaseLockOutput, error) {
+	if params == nil {
+		params = &ReleaseLockInput{}
+	}
+	if params.Name == "" || params.Owner == "" {
+		return &ReleaseLockOutput{}, &IDNotProvidedError{}
+	}
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.Name(AttributeNameLockOwner).Equal(expression.Value(params.Owner))).
+		Build()
+	if err != nil {
+		return &ReleaseLockOutput{}, BuildingExpressionError{Cause: err}
+	}
+	_, err = c.dynamoDB.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:                 aws.String(c.tableName),
+		Key:                       c.itemKey(lockIDPrefix + params.Name),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		var cause *types.ConditionalCheckFailedException
+		if errors.As(err, &cause) {
+			return &ReleaseLockOutput{}, nil
+		}
+		return &ReleaseLockOutput{}, handleDynamoDBError(err)
+	}
+	return &ReleaseLockOutput{}, nil
+}
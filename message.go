@@ -3,6 +3,8 @@ package dynamomq
 import (
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/vvatanabe/dynamomq/internal/clock"
 )
 
@@ -26,8 +28,59 @@ const (
 	QueueTypeStandard QueueType = "STANDARD"
 	// QueueTypeDLQ represents a Dead Letter Queue, used for holding messages that failed to process.
 	QueueTypeDLQ QueueType = "DLQ"
+	// QueueTypeScheduled holds the delayed messages of a client configured with WithScheduledQueue
+	// until a Mover promotes them to the standard queue.
+	QueueTypeScheduled QueueType = "SCHEDULED"
 )
 
+// Default attribute names under which a Message is stored in DynamoDB.
+// A client configured with WithTableSchema stores messages under the names of its TableSchema instead,
+// so tools that read or seed the table directly must use the same TableSchema. Only AttributeNameFormatVersion
+// cannot be renamed.
+const (
+	// AttributeNameID is the partition key of the table.
+	AttributeNameID = "id"
+	// AttributeNameData holds the message payload.
+	AttributeNameData = "data"
+	// AttributeNameReceiveCount holds the number of times the message has been received.
+	AttributeNameReceiveCount = "receive_count"
+	// AttributeNameQueueType is the partition key of the queueing index.
+	AttributeNameQueueType = "queue_type"
+	// AttributeNameVersion holds the version number used for optimistic concurrency control.
+	AttributeNameVersion = "version"
+	// AttributeNameCreatedAt holds the creation timestamp in RFC 3339 format with nanoseconds.
+	AttributeNameCreatedAt = "created_at"
+	// AttributeNameUpdatedAt holds the last update timestamp in RFC 3339 format with nanoseconds.
+	AttributeNameUpdatedAt = "updated_at"
+	// AttributeNameSentAt is the sort key of the queueing index.
+	AttributeNameSentAt = "sent_at"
+	// AttributeNameReceivedAt holds the last receive timestamp in RFC 3339 format with nanoseconds.
+	AttributeNameReceivedAt = "received_at"
+	// AttributeNameInvisibleUntilAt holds the end of the visibility timeout in RFC 3339 format with nanoseconds.
+	AttributeNameInvisibleUntilAt = "invisible_until_at"
+	// AttributeNameConsumerID holds the identifier of the consumer processing the message.
+	AttributeNameConsumerID = "consumer_id"
+	// AttributeNameFormatVersion holds the FormatVersion the message was written with.
+	AttributeNameFormatVersion = "format_version"
+	// AttributeNameCorrelationID holds the correlation ID the message was sent with.
+	AttributeNameCorrelationID = "correlation_id"
+	// AttributeNameTenantID holds the tenant the message was sent for.
+	AttributeNameTenantID = "tenant_id"
+	// AttributeNameTags holds the tags the message was sent with, as a string set.
+	AttributeNameTags = "tags"
+	// AttributeNameHeld is set on the messages held with HoldMessage.
+	AttributeNameHeld = "held"
+	// AttributeNameHoldReason holds the reason the message was held for.
+	AttributeNameHoldReason = "hold_reason"
+	// AttributeNamePayloadSchemaVersion holds the version of the schema of the payload the message was sent with.
+	AttributeNamePayloadSchemaVersion = "payload_schema_version"
+)
+
+// FormatVersion is the version of the layout of the items written by DynamoMQ, stored on every new message.
+// It is incremented whenever the layout changes, so that a migration can find the items written with an older one.
+// Items written before the format version was introduced have none, and read as version zero.
+const FormatVersion = 1
+
 // NewMessage creates a new instance of a Message with the provided data and initializes its timestamps.
 // This function is a constructor for Message, setting initial values and preparing the message for use in the queue.
 func NewMessage[T any](id string, data T, now time.Time) *Message[T] {
@@ -43,6 +96,7 @@ func NewMessage[T any](id string, data T, now time.Time) *Message[T] {
 		SentAt:           ts,
 		ReceivedAt:       "",
 		InvisibleUntilAt: "",
+		FormatVersion:    FormatVersion,
 	}
 }
 
@@ -64,13 +118,73 @@ type Message[T any] struct {
 	CreatedAt string `json:"created_at" dynamodbav:"created_at"`
 	// UpdatedAt is the timestamp when the message was last updated.
 	UpdatedAt string `json:"updated_at" dynamodbav:"updated_at"`
-	// SentAt is the timestamp when the message was sent to the queue.
+	// SentAt is the timestamp when the message was sent to the queue. It is followed by a tie-breaker
+	// when the message was written by a client configured with WithSortKeyTieBreaker; use ParsedSentAt to read it.
 	SentAt string `json:"sent_at" dynamodbav:"sent_at"`
 	// ReceivedAt is the timestamp when the message was last received from the queue.
 	ReceivedAt string `json:"received_at" dynamodbav:"received_at"`
 	// InvisibleUntilAt: The deadline until which the message remains invisible in the queue.
 	// Until this timestamp, the message will not be visible to other consumers.
 	InvisibleUntilAt string `json:"invisible_until_at" dynamodbav:"invisible_until_at"`
+	// ConsumerID identifies the consumer that received the message, as set with WithConsumerID.
+	// It is cleared when the message is made visible again, moved to the DLQ or redriven.
+	ConsumerID string `json:"consumer_id,omitempty" dynamodbav:"consumer_id,omitempty"`
+	// ProcessingDeadline is the deadline in seconds after which the message is moved to the DLQ instead of being received,
+	// counted from SentAt. Zero means the deadline of the client set with WithProcessingDeadline, and a negative value
+	// exempts the message from any deadline.
+	ProcessingDeadline int `json:"processing_deadline,omitempty" dynamodbav:"processing_deadline,omitempty"`
+	// InFlightSlot reports whether the message holds a slot of the limit set with WithMaxInFlight.
+	// The slot is released when the message is deleted, moved to the DLQ or made visible again.
+	InFlightSlot bool `json:"inflight_slot,omitempty" dynamodbav:"inflight_slot,omitempty"`
+	// FormatVersion is the FormatVersion of the layout the message was written with, or zero for messages
+	// written before it was introduced.
+	FormatVersion int `json:"format_version,omitempty" dynamodbav:"format_version,omitempty"`
+	// PayloadSchemaVersion is the version of the schema of the payload the message was sent with,
+	// or zero for messages sent without one. See WithPayloadUpgrader.
+	PayloadSchemaVersion int `json:"payload_schema_version,omitempty" dynamodbav:"payload_schema_version,omitempty"`
+	// History is the audit trail of the message, oldest first. It is recorded only by clients
+	// configured with WithAuditTrail and keeps the last MaxHistoryLength transitions.
+	History []Transition `json:"history,omitempty" dynamodbav:"history,omitempty"`
+	// Canary reports whether the message is a canary sent by SendCanary to check the health of the queue.
+	Canary bool `json:"canary,omitempty" dynamodbav:"canary,omitempty"`
+	// CorrelationID identifies the request or the workflow the message belongs to, to stitch the logs
+	// of the services it goes through. It is set by SendMessage and kept for the life of the message.
+	CorrelationID string `json:"correlation_id,omitempty" dynamodbav:"correlation_id,omitempty"`
+	// TenantID identifies the tenant the message belongs to in a queue shared by several tenants.
+	// A client configured with WithTenantFairness uses it to share the receives among the tenants.
+	TenantID string `json:"tenant_id,omitempty" dynamodbav:"tenant_id,omitempty"`
+	// Tags mark the cohort of messages the message was sent in, such as a backfill, so that the cohort can be listed
+	// and purged later. They are set by SendMessage, stored as a string set, and their order is not kept.
+	Tags []string `json:"tags,omitempty" dynamodbav:"tags,stringset,omitempty"`
+	// Held reports whether the message is held with HoldMessage. A held message is neither received nor redriven
+	// until it is released with ReleaseMessage.
+	Held bool `json:"held,omitempty" dynamodbav:"held,omitempty"`
+	// HoldReason is the reason given to HoldMessage, kept until the message is released.
+	HoldReason string `json:"hold_reason,omitempty" dynamodbav:"hold_reason,omitempty"`
+	// Source is the name of the ConsumerSource the message was received from by a MultiSourceConsumer.
+	// It is set on the messages given to the MessageProcessor and is never stored.
+	Source string `json:"-" dynamodbav:"-"`
+}
+
+// MarshalMap converts the message into the map of DynamoDB attribute values that DynamoMQ stores in the table.
+// The attribute names are the AttributeName constants; the payload is marshaled under AttributeNameData.
+// Use this function to pre-seed a table or to build fixtures outside of the client.
+func (m *Message[T]) MarshalMap() (map[string]types.AttributeValue, error) {
+	item, err := marshalMessage(m, attributevalue.MarshalMap)
+	if err != nil {
+		return nil, MarshalingAttributeError{Cause: err}
+	}
+	return item, nil
+}
+
+// UnmarshalMessage converts a map of DynamoDB attribute values, as stored by DynamoMQ, into a Message.
+// It is the inverse of Message.MarshalMap.
+func UnmarshalMessage[T any](item map[string]types.AttributeValue) (*Message[T], error) {
+	message := Message[T]{}
+	if err := unmarshalMessage(item, &message, attributevalue.UnmarshalMap); err != nil {
+		return nil, UnmarshalingAttributeError{Cause: err}
+	}
+	return &message, nil
 }
 
 // GetStatus determines the current status of the message based on the provided time.
@@ -91,43 +205,118 @@ func (m *Message[T]) GetStatus(now time.Time) Status {
 	return StatusProcessing
 }
 
-func (m *Message[T]) isDLQ() bool {
+// IsDLQ reports whether the message currently belongs to the Dead Letter Queue (DLQ).
+func (m *Message[T]) IsDLQ() bool {
 	return m.QueueType == QueueTypeDLQ
 }
 
+// IsProcessing reports whether the message is being processed at the provided time,
+// that is, whether it is still invisible to other consumers.
+func (m *Message[T]) IsProcessing(now time.Time) bool {
+	return m.GetStatus(now) == StatusProcessing
+}
+
+// VisibleAt returns the time from which the message is visible to consumers.
+// For a message that has been received, it is the end of its visibility timeout ('InvisibleUntilAt');
+// otherwise it is the time it was sent to the queue ('SentAt'), which takes a delivery delay into account.
+// An error is returned if the stored timestamp is malformed.
+func (m *Message[T]) VisibleAt() (time.Time, error) {
+	if m.InvisibleUntilAt != "" {
+		return parseTimestamp(AttributeNameInvisibleUntilAt, m.InvisibleUntilAt)
+	}
+	return m.ParsedSentAt()
+}
+
+// VisibleAgainAt returns the time the visibility timeout of a received message expires, after which the message
+// may be received again by another consumer. Handlers can use it to checkpoint their work before that time.
+// It returns the zero time if the message is not being processed, and an error if 'InvisibleUntilAt' is malformed.
+func (m *Message[T]) VisibleAgainAt() (time.Time, error) {
+	if m.InvisibleUntilAt == "" {
+		return time.Time{}, nil
+	}
+	return m.VisibleAt()
+}
+
+// Age returns how long the message has existed at the provided time, measured from 'CreatedAt'.
+// An error is returned if the stored timestamp is malformed.
+func (m *Message[T]) Age(now time.Time) (time.Duration, error) {
+	createdAt, err := parseTimestamp(AttributeNameCreatedAt, m.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return now.Sub(createdAt), nil
+}
+
+// ParsedSentAt returns 'SentAt' as a time.Time, without the tie-breaker appended with WithSortKeyTieBreaker, if any.
+// An error is returned if the stored timestamp is malformed.
+func (m *Message[T]) ParsedSentAt() (time.Time, error) {
+	return parseTimestamp(AttributeNameSentAt, sentAtTimestamp(m.SentAt))
+}
+
+// ParsedReceivedAt returns 'ReceivedAt' as a time.Time.
+// It returns the zero time without an error if the message has never been received.
+// An error is returned if the stored timestamp is malformed.
+func (m *Message[T]) ParsedReceivedAt() (time.Time, error) {
+	if m.ReceivedAt == "" {
+		return time.Time{}, nil
+	}
+	return parseTimestamp(AttributeNameReceivedAt, m.ReceivedAt)
+}
+
+func parseTimestamp(attribute, value string) (time.Time, error) {
+	t, err := clock.ParseRFC3339Nano(value)
+	if err != nil {
+		return time.Time{}, InvalidTimestampError{Attribute: attribute, Value: value, Cause: err}
+	}
+	return t, nil
+}
+
 func (m *Message[T]) changeVisibility(now time.Time, visibilityTimeout time.Duration) {
 	ts := clock.FormatRFC3339Nano(now)
 	m.UpdatedAt = ts
 	m.InvisibleUntilAt = clock.FormatRFC3339Nano(now.Add(visibilityTimeout))
+	if visibilityTimeout <= 0 {
+		m.ConsumerID = ""
+		m.InFlightSlot = false
+	}
 }
 
 func (m *Message[T]) delayToSentAt(delay time.Duration) {
-	delayed := clock.RFC3339NanoToTime(m.SentAt).Add(delay)
+	delayed := clock.RFC3339NanoToTime(sentAtTimestamp(m.SentAt)).Add(delay)
 	m.SentAt = clock.FormatRFC3339Nano(delayed)
 }
 
-func (m *Message[T]) markAsProcessing(now time.Time, visibilityTimeout time.Duration) error {
-	status := m.GetStatus(now)
-	if status == StatusProcessing {
+func (m *Message[T]) markAsProcessing(now time.Time, visibilityTimeout time.Duration, consumerID string) error {
+	from := m.LifecycleStatus(now)
+	to := StatusProcessing
+	if m.IsDLQ() {
+		to = StatusDLQProcessing
+	}
+	if !CanTransition(from, to) {
 		return InvalidStateTransitionError{
 			Msg:       "message is currently being processed",
 			Operation: "mark as processing",
-			Current:   status,
+			Current:   from,
+			Requested: to,
 		}
 	}
 	ts := clock.FormatRFC3339Nano(now)
 	m.UpdatedAt = ts
 	m.ReceivedAt = ts
 	m.InvisibleUntilAt = clock.FormatRFC3339Nano(now.Add(visibilityTimeout))
+	m.ConsumerID = consumerID
 	return nil
 }
 
 func (m *Message[T]) markAsMovedToDLQ(now time.Time) error {
-	if m.isDLQ() {
+	from := m.LifecycleStatus(now)
+	// DLQ_PROCESSING may go to DLQ when its visibility timeout expires, but it is not moved to the DLQ again.
+	if m.IsDLQ() || !CanTransition(from, StatusDLQ) {
 		return InvalidStateTransitionError{
 			Msg:       "message is already in DLQ",
 			Operation: "mark as moved to DLQ",
-			Current:   m.GetStatus(now),
+			Current:   from,
+			Requested: StatusDLQ,
 		}
 	}
 	ts := clock.FormatRFC3339Nano(now)
@@ -137,23 +326,24 @@ func (m *Message[T]) markAsMovedToDLQ(now time.Time) error {
 	m.SentAt = ts
 	m.ReceivedAt = ""
 	m.InvisibleUntilAt = ""
+	m.ConsumerID = ""
+	m.InFlightSlot = false
 	return nil
 }
 
 func (m *Message[T]) markAsRestoredFromDLQ(now time.Time) error {
-	status := m.GetStatus(now)
-	if !m.isDLQ() {
-		return InvalidStateTransitionError{
-			Msg:       "can only redrive messages from DLQ",
-			Operation: "mark as restored from DLQ",
-			Current:   status,
+	from := m.LifecycleStatus(now)
+	// PROCESSING may go to READY when its visibility timeout expires, but only the DLQ is redriven.
+	if !m.IsDLQ() || !CanTransition(from, StatusReady) {
+		msg := "can only redrive messages from DLQ"
+		if m.IsDLQ() {
+			msg = "can only redrive messages from READY"
 		}
-	}
-	if status == StatusProcessing {
 		return InvalidStateTransitionError{
-			Msg:       "can only redrive messages from READY",
+			Msg:       msg,
 			Operation: "mark as restored from DLQ",
-			Current:   status,
+			Current:   from,
+			Requested: StatusReady,
 		}
 	}
 	ts := clock.FormatRFC3339Nano(now)
@@ -163,5 +353,6 @@ func (m *Message[T]) markAsRestoredFromDLQ(now time.Time) error {
 	m.SentAt = ts
 	m.ReceivedAt = ""
 	m.InvisibleUntilAt = ""
+	m.ConsumerID = ""
 	return nil
 }
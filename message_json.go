@@ -0,0 +1,109 @@
+package dynamomq
+
+import (
+	"encoding/json"
+	"sync/atomic"
+
+	"github.com/vvatanabe/dynamomq/internal/clock"
+)
+
+// excludeJSONData is set with SetJSONIncludeData.
+var excludeJSONData atomic.Bool
+
+// SetJSONIncludeData sets whether Message.MarshalJSON includes the payload of the message, for every message
+// of the process. Exclude it to log messages whose payload is large or sensitive.
+// DumpMessages and MarshalMessageJSON choose for themselves and are not affected.
+// By default, the payload is included.
+func SetJSONIncludeData(include bool) {
+	excludeJSONData.Store(!include)
+}
+
+// MarshalJSON encodes the message with its timestamps in RFC 3339 format in UTC, and with the derived
+// visible_at, the time from which the message is visible to consumers as returned by VisibleAt.
+// The attribute names are those of the JSON tags of Message, so the result decodes back into a Message.
+// The payload is included unless it is excluded with SetJSONIncludeData.
+func (m Message[T]) MarshalJSON() ([]byte, error) {
+	return MarshalMessageJSON(&m, !excludeJSONData.Load())
+}
+
+// MarshalMessageJSON encodes the message as Message.MarshalJSON does, including the payload when includeData is true
+// regardless of SetJSONIncludeData.
+func MarshalMessageJSON[T any](m *Message[T], includeData bool) ([]byte, error) {
+	v := messageJSON{
+		ID:                   m.ID,
+		ReceiveCount:         m.ReceiveCount,
+		QueueType:            m.QueueType,
+		Version:              m.Version,
+		CreatedAt:            normalizeTimestamp(m.CreatedAt),
+		UpdatedAt:            normalizeTimestamp(m.UpdatedAt),
+		SentAt:               normalizeTimestamp(m.SentAt),
+		ReceivedAt:           normalizeTimestamp(m.ReceivedAt),
+		InvisibleUntilAt:     normalizeTimestamp(m.InvisibleUntilAt),
+		ConsumerID:           m.ConsumerID,
+		ProcessingDeadline:   m.ProcessingDeadline,
+		InFlightSlot:         m.InFlightSlot,
+		FormatVersion:        m.FormatVersion,
+		PayloadSchemaVersion: m.PayloadSchemaVersion,
+		Canary:               m.Canary,
+		CorrelationID:        m.CorrelationID,
+		TenantID:             m.TenantID,
+		Tags:                 m.Tags,
+		Held:                 m.Held,
+		HoldReason:           m.HoldReason,
+	}
+	if visibleAt, err := m.VisibleAt(); err == nil {
+		v.VisibleAt = clock.FormatRFC3339Nano(visibleAt)
+	}
+	if m.History != nil {
+		v.History = make([]Transition, len(m.History))
+		for i, t := range m.History {
+			t.At = normalizeTimestamp(t.At)
+			v.History[i] = t
+		}
+	}
+	if includeData {
+		data, err := json.Marshal(m.Data)
+		if err != nil {
+			return nil, err
+		}
+		v.Data = data
+	}
+	return json.Marshal(v)
+}
+
+// messageJSON is the JSON representation of a Message written by MarshalMessageJSON.
+type messageJSON struct {
+	ID                   string          `json:"id"`
+	Data                 json.RawMessage `json:"data,omitempty"`
+	ReceiveCount         int             `json:"receive_count"`
+	QueueType            QueueType       `json:"queue_type"`
+	Version              int             `json:"version"`
+	CreatedAt            string          `json:"created_at"`
+	UpdatedAt            string          `json:"updated_at"`
+	SentAt               string          `json:"sent_at"`
+	ReceivedAt           string          `json:"received_at"`
+	InvisibleUntilAt     string          `json:"invisible_until_at"`
+	VisibleAt            string          `json:"visible_at"`
+	ConsumerID           string          `json:"consumer_id,omitempty"`
+	ProcessingDeadline   int             `json:"processing_deadline,omitempty"`
+	InFlightSlot         bool            `json:"inflight_slot,omitempty"`
+	FormatVersion        int             `json:"format_version,omitempty"`
+	PayloadSchemaVersion int             `json:"payload_schema_version,omitempty"`
+	History              []Transition    `json:"history,omitempty"`
+	Canary               bool            `json:"canary,omitempty"`
+	CorrelationID        string          `json:"correlation_id,omitempty"`
+	TenantID             string          `json:"tenant_id,omitempty"`
+	Tags                 []string        `json:"tags,omitempty"`
+	Held                 bool            `json:"held,omitempty"`
+	HoldReason           string          `json:"hold_reason,omitempty"`
+}
+
+// normalizeTimestamp returns the timestamp in RFC 3339 format in UTC, as DynamoMQ writes it.
+// A timestamp that cannot be parsed is returned as it is.
+func normalizeTimestamp(value string) string {
+	t, err := clock.ParseRFC3339Nano(value)
+	if err != nil {
+		return value
+	}
+	return clock.FormatRFC3339Nano(t)
+}
@@ -0,0 +1,125 @@
+package dynamomq_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestMessageMarshalJSONGolden(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		message *dynamomq.Message[test.MessageData]
+		// normalized reports whether the timestamps of the message change when they are normalized,
+		// in which case the JSON does not decode back into the same message.
+		normalized bool
+	}{
+		{
+			name:    "message_json_ready",
+			message: NewTestMessageItemAsReady("A-101", test.DefaultTestDate),
+		},
+		{
+			name:    "message_json_processing",
+			message: NewTestMessageItemAsProcessing("A-101", test.DefaultTestDate),
+		},
+		{
+			name:    "message_json_delayed",
+			message: newTestMessageItemAsDelayed("A-101", test.DefaultTestDate, 10*time.Second),
+		},
+		{
+			name:    "message_json_with_metadata",
+			message: newTestMessageItemWithMetadata("A-101", test.DefaultTestDate),
+		},
+		{
+			name: "message_json_mixed_timestamps",
+			message: func() *dynamomq.Message[test.MessageData] {
+				m := NewTestMessageItemAsProcessing("A-101", test.DefaultTestDate)
+				m.CreatedAt = test.DefaultTestDate.In(time.FixedZone("JST", 9*60*60)).Format(time.RFC3339)
+				m.ReceivedAt = "not a timestamp"
+				return m
+			}(),
+			normalized: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := json.MarshalIndent(tt.message, "", "  ")
+			if err != nil {
+				t.Fatalf("json.MarshalIndent() error = %v", err)
+			}
+			got = append(got, '\n')
+			golden := filepath.Join("testdata", tt.name+".golden.json")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o600); err != nil {
+					t.Fatalf("failed to update golden file: %v", err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			// A difference means that the JSON shape of messages has changed, which breaks the tools parsing
+			// the logs and the dumps, so it must be backward compatible before the golden files are updated with -update.
+			if string(got) != string(want) {
+				t.Errorf("MarshalJSON() = %s, want %s", got, want)
+			}
+			if tt.normalized {
+				return
+			}
+			var decoded dynamomq.Message[test.MessageData]
+			if err := json.Unmarshal(got, &decoded); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			test.AssertDeepEqual(t, &decoded, tt.message, "json.Unmarshal()")
+		})
+	}
+}
+
+func TestMarshalMessageJSONWithoutData(t *testing.T) {
+	t.Parallel()
+	message := NewTestMessageItemAsReady("A-101", test.DefaultTestDate)
+	got, err := dynamomq.MarshalMessageJSON(message, false)
+	if err != nil {
+		t.Fatalf("MarshalMessageJSON() error = %v", err)
+	}
+	want, err := json.Marshal(message)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if strings.Contains(string(got), `"data"`) {
+		t.Errorf("MarshalMessageJSON() = %s, want no data", got)
+	}
+	if !strings.Contains(string(want), `"data"`) {
+		t.Errorf("json.Marshal() = %s, want data", want)
+	}
+}
+
+// TestSetJSONIncludeData is not parallel, as the setting applies to every message of the process.
+func TestSetJSONIncludeData(t *testing.T) {
+	dynamomq.SetJSONIncludeData(false)
+	defer dynamomq.SetJSONIncludeData(true)
+	message := NewTestMessageItemAsReady("A-101", test.DefaultTestDate)
+	got, err := json.Marshal(&dynamomq.ReceiveMessageOutput[test.MessageData]{ReceivedMessage: message})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if strings.Contains(string(got), `"data"`) {
+		t.Errorf("json.Marshal() = %s, want no data", got)
+	}
+	withData, err := dynamomq.MarshalMessageJSON(message, true)
+	if err != nil {
+		t.Fatalf("MarshalMessageJSON() error = %v", err)
+	}
+	if !strings.Contains(string(withData), `"data"`) {
+		t.Errorf("MarshalMessageJSON() = %s, want data", withData)
+	}
+}
@@ -1,10 +1,19 @@
 package dynamomq_test
 
 import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
 	"github.com/vvatanabe/dynamomq/internal/clock"
 	"github.com/vvatanabe/dynamomq/internal/test"
 )
@@ -69,3 +78,238 @@ func TestMessageGetStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestMessagePredicates(t *testing.T) {
+	now := test.DefaultTestDate
+	ready := NewTestMessageItemAsReady("A-101", now)
+	processing := NewTestMessageItemAsProcessing("A-101", now)
+	dlq := NewTestMessageItemAsDLQ("A-101", now)
+	if ready.IsDLQ() || processing.IsDLQ() || !dlq.IsDLQ() {
+		t.Errorf("IsDLQ() = %v, %v, %v, want false, false, true", ready.IsDLQ(), processing.IsDLQ(), dlq.IsDLQ())
+	}
+	if ready.IsProcessing(now) || !processing.IsProcessing(now) {
+		t.Errorf("IsProcessing() = %v, %v, want false, true", ready.IsProcessing(now), processing.IsProcessing(now))
+	}
+}
+
+func TestMessageTimeAccessors(t *testing.T) {
+	now := test.DefaultTestDate
+	processing := NewTestMessageItemAsProcessing("A-101", now)
+	delayed := NewTestMessageItemAsReady("A-101", now)
+	delayed.SentAt = clock.FormatRFC3339Nano(now.Add(time.Minute))
+
+	tests := []struct {
+		name string
+		got  func() (time.Time, error)
+		want time.Time
+	}{
+		{"VisibleAt of a processing message", processing.VisibleAt, now.Add(30 * time.Second)},
+		{"VisibleAt of a delayed message", delayed.VisibleAt, now.Add(time.Minute)},
+		{"ParsedSentAt", delayed.ParsedSentAt, now.Add(time.Minute)},
+		{"ParsedReceivedAt of a processing message", processing.ParsedReceivedAt, now},
+		{"ParsedReceivedAt of a message never received", delayed.ParsedReceivedAt, time.Time{}},
+		{"VisibleAgainAt of a processing message", processing.VisibleAgainAt, now.Add(30 * time.Second)},
+		{"VisibleAgainAt of a message never received", delayed.VisibleAgainAt, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.got()
+			if err != nil {
+				t.Errorf("unexpected error = %v", err)
+				return
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	age, err := processing.Age(now.Add(time.Hour))
+	if err != nil || age != time.Hour {
+		t.Errorf("Age() = %v, %v, want %v", age, err, time.Hour)
+	}
+}
+
+func TestMessageTimeAccessorsWithMalformedTimestamps(t *testing.T) {
+	m := dynamomq.Message[any]{
+		CreatedAt:        "yesterday",
+		SentAt:           "2023-12-01",
+		ReceivedAt:       "2023/12/01 00:00:00",
+		InvisibleUntilAt: "soon",
+	}
+	tests := []struct {
+		name      string
+		operation func() error
+		attribute string
+	}{
+		{"VisibleAt", func() error { _, err := m.VisibleAt(); return err }, "invisible_until_at"},
+		{"VisibleAgainAt", func() error { _, err := m.VisibleAgainAt(); return err }, "invisible_until_at"},
+		{"Age", func() error { _, err := m.Age(test.DefaultTestDate); return err }, "created_at"},
+		{"ParsedSentAt", func() error { _, err := m.ParsedSentAt(); return err }, "sent_at"},
+		{"ParsedReceivedAt", func() error { _, err := m.ParsedReceivedAt(); return err }, "received_at"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var invalid dynamomq.InvalidTimestampError
+			if err := tt.operation(); !errors.As(err, &invalid) {
+				t.Errorf("error = %v, want InvalidTimestampError", err)
+				return
+			}
+			if invalid.Attribute != tt.attribute {
+				t.Errorf("attribute = %v, want %v", invalid.Attribute, tt.attribute)
+			}
+		})
+	}
+}
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestMessageMarshalMapGolden(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		message *dynamomq.Message[test.MessageData]
+	}{
+		{
+			name:    "message_ready",
+			message: NewTestMessageItemAsReady("A-101", test.DefaultTestDate),
+		},
+		{
+			name:    "message_processing",
+			message: NewTestMessageItemAsProcessing("A-101", test.DefaultTestDate),
+		},
+		{
+			name:    "message_dlq",
+			message: NewTestMessageItemAsDLQ("A-101", test.DefaultTestDate),
+		},
+		{
+			name:    "message_delayed",
+			message: newTestMessageItemAsDelayed("A-101", test.DefaultTestDate, 10*time.Second),
+		},
+		{
+			name:    "message_with_metadata",
+			message: newTestMessageItemWithMetadata("A-101", test.DefaultTestDate),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			item, err := tt.message.MarshalMap()
+			if err != nil {
+				t.Fatalf("MarshalMap() error = %v", err)
+			}
+			got, err := json.MarshalIndent(attributeValueMapToJSON(item), "", "  ")
+			if err != nil {
+				t.Fatalf("json.MarshalIndent() error = %v", err)
+			}
+			got = append(got, '\n')
+			golden := filepath.Join("testdata", tt.name+".golden.json")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o600); err != nil {
+					t.Fatalf("failed to update golden file: %v", err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			// A difference means that the storage format has changed. Items are shared by services running
+			// different versions of the library, so a change must be backward compatible and increment FormatVersion
+			// before the golden files are updated with -update.
+			if string(got) != string(want) {
+				t.Errorf("MarshalMap() = %s, want %s", got, want)
+			}
+			message, err := dynamomq.UnmarshalMessage[test.MessageData](item)
+			if err != nil {
+				t.Fatalf("UnmarshalMessage() error = %v", err)
+			}
+			test.AssertDeepEqual(t, message, tt.message, "UnmarshalMessage()")
+		})
+	}
+}
+
+func newTestMessageItemAsDelayed(id string, now time.Time, delay time.Duration) *dynamomq.Message[test.MessageData] {
+	return dynamomqtest.NewReadyMessage(id, test.NewMessageData(id), now, dynamomqtest.WithSentAt(now.Add(delay)))
+}
+
+func newTestMessageItemWithMetadata(id string, now time.Time) *dynamomq.Message[test.MessageData] {
+	m := dynamomqtest.NewProcessingMessage(id, test.NewMessageData(id), now,
+		dynamomqtest.WithVersion(2), dynamomqtest.WithReceiveCount(1))
+	m.ConsumerID = testConsumerID
+	m.ProcessingDeadline = 3600
+	m.InFlightSlot = true
+	m.History = []dynamomq.Transition{
+		{Status: dynamomq.TransitionSent, At: clock.FormatRFC3339Nano(now)},
+		{Status: dynamomq.TransitionReceived, At: clock.FormatRFC3339Nano(now), Actor: testConsumerID},
+	}
+	return m
+}
+
+// TestUnmarshalMessageWithoutFormatVersion makes sure that the items written before the format version
+// was introduced can still be read.
+func TestUnmarshalMessageWithoutFormatVersion(t *testing.T) {
+	t.Parallel()
+	item := dynamomqtest.MarshalMap(NewTestMessageItemAsReady("A-101", test.DefaultTestDate))
+	delete(item, dynamomq.AttributeNameFormatVersion)
+	message, err := dynamomq.UnmarshalMessage[test.MessageData](item)
+	if err != nil {
+		t.Fatalf("UnmarshalMessage() error = %v", err)
+	}
+	want := NewTestMessageItemAsReady("A-101", test.DefaultTestDate)
+	want.FormatVersion = 0
+	test.AssertDeepEqual(t, message, want, "UnmarshalMessage()")
+}
+
+func TestUnmarshalMessageError(t *testing.T) {
+	t.Parallel()
+	item := dynamomqtest.MarshalMap(NewTestMessageItemAsReady("A-101", test.DefaultTestDate))
+	item[dynamomq.AttributeNameReceiveCount] = &types.AttributeValueMemberS{Value: "not a number"}
+	_, err := dynamomq.UnmarshalMessage[test.MessageData](item)
+	if _, ok := assertErrorType[dynamomq.UnmarshalingAttributeError](err); !ok {
+		t.Errorf("UnmarshalMessage() error = %v, want UnmarshalingAttributeError", err)
+	}
+}
+
+// attributeValueMapToJSON converts an item into the DynamoDB JSON representation
+// so that golden files are readable and independent of the SDK's internal types.
+func attributeValueMapToJSON(item map[string]types.AttributeValue) map[string]any {
+	m := make(map[string]any, len(item))
+	for k, v := range item {
+		m[k] = attributeValueToJSON(v)
+	}
+	return m
+}
+
+func attributeValueToJSON(av types.AttributeValue) map[string]any {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return map[string]any{"S": v.Value}
+	case *types.AttributeValueMemberN:
+		return map[string]any{"N": v.Value}
+	case *types.AttributeValueMemberBOOL:
+		return map[string]any{"BOOL": v.Value}
+	case *types.AttributeValueMemberNULL:
+		return map[string]any{"NULL": v.Value}
+	case *types.AttributeValueMemberB:
+		return map[string]any{"B": v.Value}
+	case *types.AttributeValueMemberSS:
+		ss := append([]string(nil), v.Value...)
+		sort.Strings(ss)
+		return map[string]any{"SS": ss}
+	case *types.AttributeValueMemberNS:
+		ns := append([]string(nil), v.Value...)
+		sort.Strings(ns)
+		return map[string]any{"NS": ns}
+	case *types.AttributeValueMemberL:
+		l := make([]any, 0, len(v.Value))
+		for _, e := range v.Value {
+			l = append(l, attributeValueToJSON(e))
+		}
+		return map[string]any{"L": l}
+	case *types.AttributeValueMemberM:
+		return map[string]any{"M": attributeValueMapToJSON(v.Value)}
+	default:
+		return map[string]any{"unsupported": fmt.Sprintf("%T", av)}
+	}
+}
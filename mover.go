@@ -0,0 +1,343 @@
+package dynamomq
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+)
+
+const (
+	defaultMoverInterval          = time.Second
+	defaultMoverLockName          = "mover"
+	defaultMoverMaxMessagesPerRun = 1000
+)
+
+// ErrMoverClosed is an error that indicates the Mover has been stopped.
+var ErrMoverClosed = errors.New("DynamoMQ: Mover closed")
+
+// PromoteScheduledMessagesInput represents the input parameters for promoting the due scheduled messages.
+type PromoteScheduledMessagesInput struct {
+	// MaxMessages is the maximum number of messages to promote. Zero means unlimited.
+	MaxMessages int
+}
+
+// PromoteScheduledMessagesOutput represents the result of the operation to promote the due scheduled messages.
+type PromoteScheduledMessagesOutput struct {
+	// Promoted is the number of messages promoted.
+	Promoted int
+}
+
+// PromoteScheduledMessages moves the messages of the SCHEDULED queue type whose SentAt has passed to the STANDARD queue,
+// where they can be received. It walks the queueing index from the earliest due message and reads only the due ones.
+// Each message is promoted with an update conditional on its version and queue type, so that a message promoted
+// or updated concurrently is left as it is.
+func (c *ClientImpl[T]) PromoteScheduledMessages(ctx context.Context, params *PromoteScheduledMessagesInput) (*PromoteScheduledMessagesOutput, error) {
+	if params == nil {
+		params = &PromoteScheduledMessagesInput{}
+	}
+	now := c.clock.Now()
+	builder := expression.NewBuilder().
+		WithKeyCondition(expression.Key(c.schema.QueueTypeAttribute).Equal(expression.Value(QueueTypeScheduled)).
+			And(expression.Key(c.schema.SentAtAttribute).LessThanEqual(expression.Value(clock.FormatRFC3339Nano(now)))))
+	expr, err := c.buildExpression(builder)
+	if err != nil {
+		return &PromoteScheduledMessagesOutput{}, BuildingExpressionError{Cause: err}
+	}
+	out := &PromoteScheduledMessagesOutput{}
+	var exclusiveStartKey map[string]types.AttributeValue
+	for {
+		if err := ctx.Err(); err != nil {
+			return out, OperationCanceledError{Cause: err}
+		}
+		queryOutput, err := c.dynamoDB.Query(ctx, &dynamodb.QueryInput{
+			IndexName:                 aws.String(c.schema.QueueingIndexName),
+			TableName:                 aws.String(c.tableName),
+			KeyConditionExpression:    expr.KeyCondition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			ScanIndexForward:          aws.Bool(true),
+			Limit:                     aws.Int32(defaultQueryLimit),
+			ExclusiveStartKey:         exclusiveStartKey,
+		})
+		if err != nil {
+			return out, handleDynamoDBError(err)
+		}
+		for _, item := range queryOutput.Items {
+			if params.MaxMessages > 0 && out.Promoted >= params.MaxMessages {
+				return out, nil
+			}
+			promoted, err := c.promoteScheduledItem(ctx, item, now)
+			if err != nil {
+				return out, err
+			}
+			if promoted {
+				out.Promoted++
+			}
+		}
+		exclusiveStartKey = queryOutput.LastEvaluatedKey
+		if exclusiveStartKey == nil {
+			return out, nil
+		}
+	}
+}
+
+func (c *ClientImpl[T]) promoteScheduledItem(ctx context.Context, item map[string]types.AttributeValue, now time.Time) (bool, error) {
+	message := Message[T]{}
+	if err := c.unmarshalItem(item, &message); err != nil {
+		return false, c.handleCorruptMessage(item, err)
+	}
+	message.UpdatedAt = clock.FormatRFC3339Nano(now)
+	c.recordTransition(&message, TransitionPromoted, now)
+	builder := expression.NewBuilder().
+		WithUpdate(c.setHistory(expression.Add(
+			expression.Name(c.schema.VersionAttribute),
+			expression.Value(1),
+		).Set(
+			expression.Name(c.schema.QueueTypeAttribute),
+			expression.Value(QueueTypeStandard),
+		).Set(
+			expression.Name(c.schema.UpdatedAtAttribute),
+			expression.Value(message.UpdatedAt),
+		), &message)).
+		WithCondition(expression.Name(c.schema.VersionAttribute).Equal(expression.Value(message.Version)).
+			And(expression.Name(c.schema.QueueTypeAttribute).Equal(expression.Value(QueueTypeScheduled))))
+	expr, err := c.buildExpression(builder)
+	if err != nil {
+		return false, BuildingExpressionError{Cause: err}
+	}
+	_, err = c.updateDynamoDBItem(ctx, message.ID, &expr)
+	if err != nil {
+		var conflict *ConditionalCheckFailedError
+		if errors.As(err, &conflict) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// MoverOptions contains configuration options for a Mover instance.
+type MoverOptions struct {
+	// Interval is the time interval between two runs.
+	Interval time.Duration
+	// MaxMessagesPerRun is the maximum number of messages promoted in one run, which bounds the time a run holds the lock.
+	MaxMessagesPerRun int
+	// LockName identifies the lock item shared by the Movers of a fleet.
+	LockName string
+	// LockTTL is the duration after which the lock of a Mover that stopped without releasing it expires.
+	// It must be longer than Interval, otherwise the lock changes hands between runs. By default, it is three times Interval.
+	LockTTL time.Duration
+	// Owner identifies this Mover in the lock item. By default, it is a random UUID.
+	Owner string
+	// ErrorLog is an optional logger for errors. If nil, the standard logger is used.
+	ErrorLog *log.Logger
+}
+
+// WithMoverInterval sets the time interval between two runs.
+func WithMoverInterval(interval time.Duration) func(o *MoverOptions) {
+	return func(o *MoverOptions) {
+		o.Interval = interval
+	}
+}
+
+// WithMoverMaxMessagesPerRun sets the maximum number of messages promoted in one run.
+func WithMoverMaxMessagesPerRun(maxMessages int) func(o *MoverOptions) {
+	return func(o *MoverOptions) {
+		o.MaxMessagesPerRun = maxMessages
+	}
+}
+
+// WithMoverLock sets the name of the lock item and the duration after which it expires.
+func WithMoverLock(name string, ttl time.Duration) func(o *MoverOptions) {
+	return func(o *MoverOptions) {
+		o.LockName = name
+		o.LockTTL = ttl
+	}
+}
+
+// WithMoverOwner sets the identifier of the Mover in the lock item.
+func WithMoverOwner(owner string) func(o *MoverOptions) {
+	return func(o *MoverOptions) {
+		o.Owner = owner
+	}
+}
+
+// WithMoverErrorLog sets a custom logger for the Mover.
+func WithMoverErrorLog(errorLog *log.Logger) func(o *MoverOptions) {
+	return func(o *MoverOptions) {
+		o.ErrorLog = errorLog
+	}
+}
+
+// NewMover creates a new Mover that promotes the due scheduled messages of the client's queue to the standard queue.
+func NewMover[T any](client Client[T], opts ...func(o *MoverOptions)) *Mover[T] {
+	o := &MoverOptions{
+		Interval:          defaultMoverInterval,
+		MaxMessagesPerRun: defaultMoverMaxMessagesPerRun,
+		LockName:          defaultMoverLockName,
+		Owner:             uuid.NewString(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.LockTTL <= 0 {
+		o.LockTTL = 3 * o.Interval
+	}
+	return &Mover[T]{
+		client:            client,
+		interval:          o.Interval,
+		maxMessagesPerRun: o.MaxMessagesPerRun,
+		lockName:          o.LockName,
+		lockTTL:           o.LockTTL,
+		owner:             o.Owner,
+		errorLog:          o.ErrorLog,
+		doneChan:          make(chan struct{}),
+	}
+}
+
+// MoverStats reports the activity of a Mover since it was created.
+type MoverStats struct {
+	// Runs is the number of runs made while holding the lock.
+	Runs int64
+	// Skipped is the number of runs skipped because another Mover held the lock.
+	Skipped int64
+	// Promoted is the number of messages promoted.
+	Promoted int64
+	// Errors is the number of runs that failed.
+	Errors int64
+}
+
+// Mover periodically promotes the scheduled messages that are due to the standard queue, for clients
+// configured with WithScheduledQueue. Every instance of a fleet can run a Mover: they contend for a lock item
+// stored in the queue table, and only the holder promotes messages. The holder extends the lock on each run,
+// and the lock expires after LockTTL if the holder goes away, so that another instance takes over.
+// A message is delivered at most Interval after it is due, plus the time to promote the messages due before it.
+// Note: To create a new instance of Mover, it is necessary to use the NewMover function.
+type Mover[T any] struct {
+	client            Client[T]
+	interval          time.Duration
+	maxMessagesPerRun int
+	lockName          string
+	lockTTL           time.Duration
+	owner             string
+	errorLog          *log.Logger
+
+	runs     atomic.Int64
+	skipped  atomic.Int64
+	promoted atomic.Int64
+	failures atomic.Int64
+	mu       sync.Mutex
+	runWG    sync.WaitGroup
+	doneChan chan struct{}
+}
+
+// Start promotes the due messages every Interval until the context is done or Stop is called, and then releases the lock.
+// It returns ErrMoverClosed after Stop, or the error of the context.
+func (m *Mover[T]) Start(ctx context.Context) error {
+	m.runWG.Add(1)
+	defer m.runWG.Done()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-m.doneChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	defer m.releaseLock()
+	for {
+		m.run(ctx)
+		if !sleepContext(ctx, m.interval) {
+			select {
+			case <-m.doneChan:
+				return ErrMoverClosed
+			default:
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// Stop stops the Mover and waits until the running promotion has finished and the lock has been released.
+func (m *Mover[T]) Stop() {
+	m.mu.Lock()
+	select {
+	case <-m.doneChan:
+	default:
+		close(m.doneChan)
+	}
+	m.mu.Unlock()
+	m.runWG.Wait()
+}
+
+// Stats returns the activity of the Mover since it was created.
+func (m *Mover[T]) Stats() MoverStats {
+	return MoverStats{
+		Runs:     m.runs.Load(),
+		Skipped:  m.skipped.Load(),
+		Promoted: m.promoted.Load(),
+		Errors:   m.failures.Load(),
+	}
+}
+
+func (m *Mover[T]) run(ctx context.Context) {
+	acquired, err := m.client.AcquireLock(ctx, &AcquireLockInput{
+		Name:  m.lockName,
+		Owner: m.owner,
+		TTL:   m.lockTTL,
+	})
+	if err != nil {
+		m.fail(ctx, "DynamoMQ: Failed to acquire the mover lock. %s", err)
+		return
+	}
+	if !acquired.Acquired {
+		m.skipped.Add(1)
+		return
+	}
+	m.runs.Add(1)
+	out, err := m.client.PromoteScheduledMessages(ctx, &PromoteScheduledMessagesInput{
+		MaxMessages: m.maxMessagesPerRun,
+	})
+	if out != nil {
+		m.promoted.Add(int64(out.Promoted))
+	}
+	if err != nil {
+		m.fail(ctx, "DynamoMQ: Failed to promote scheduled messages. %s", err)
+	}
+}
+
+func (m *Mover[T]) fail(ctx context.Context, format string, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	m.failures.Add(1)
+	m.logf(format, err)
+}
+
+func (m *Mover[T]) releaseLock() {
+	if _, err := m.client.ReleaseLock(context.Background(), &ReleaseLockInput{
+		Name:  m.lockName,
+		Owner: m.owner,
+	}); err != nil {
+		m.logf("DynamoMQ: Failed to release the mover lock. %s", err)
+	}
+}
+
+func (m *Mover[T]) logf(format string, v ...any) {
+	if m.errorLog != nil {
+		m.errorLog.Printf(format, v...)
+	} else {
+		log.Printf(format, v...)
+	}
+}
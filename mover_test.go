@@ -0,0 +1,283 @@
+package dynamomq_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestDynamoMQClientPromoteScheduledMessages(t *testing.T) {
+	t.Parallel()
+	tableName, raw, clean := SetupDynamoDB(t)
+	defer clean()
+	newClient := func(now time.Time) dynamomq.Client[test.MessageData] {
+		client, _ := prepareTestClient(context.Background(), t, func(t *testing.T) (string, *dynamodb.Client, func()) {
+			return tableName, raw, func() {}
+		}, mock.Clock{T: now}, false, nil, nil, nil, dynamomq.WithScheduledQueue(true))
+		return client
+	}
+	ctx := context.Background()
+	sender := newClient(test.DefaultTestDate)
+	for id, delay := range map[string]int{"A-101": 60, "A-102": 120, "A-103": 0} {
+		if _, err := sender.SendMessage(ctx, &dynamomq.SendMessageInput[test.MessageData]{
+			ID:           id,
+			Data:         test.NewMessageData(id),
+			DelaySeconds: delay,
+		}); err != nil {
+			t.Fatalf("SendMessage() error = %v", err)
+		}
+	}
+	queueTypes := func(client dynamomq.Client[test.MessageData]) map[string]dynamomq.QueueType {
+		t.Helper()
+		got := map[string]dynamomq.QueueType{}
+		for _, id := range []string{"A-101", "A-102", "A-103"} {
+			out, err := client.GetMessage(ctx, &dynamomq.GetMessageInput{ID: id})
+			if err != nil {
+				t.Fatalf("GetMessage() error = %v", err)
+			}
+			got[id] = out.Message.QueueType
+		}
+		return got
+	}
+	test.AssertDeepEqual(t, queueTypes(sender), map[string]dynamomq.QueueType{
+		"A-101": dynamomq.QueueTypeScheduled,
+		"A-102": dynamomq.QueueTypeScheduled,
+		"A-103": dynamomq.QueueTypeStandard,
+	}, "queue types after SendMessage()")
+
+	tests := []struct {
+		name         string
+		now          time.Time
+		wantPromoted int
+		want         dynamomq.QueueType
+	}{
+		{
+			name: "should not promote a message before it is due",
+			now:  test.DefaultTestDate.Add(time.Minute - time.Nanosecond),
+			want: dynamomq.QueueTypeScheduled,
+		},
+		{
+			name:         "should promote a message when it is due",
+			now:          test.DefaultTestDate.Add(time.Minute),
+			wantPromoted: 1,
+			want:         dynamomq.QueueTypeStandard,
+		},
+		{
+			name: "should not promote a message again",
+			now:  test.DefaultTestDate.Add(time.Minute + time.Second),
+			want: dynamomq.QueueTypeStandard,
+		},
+	}
+	for _, tt := range tests {
+		client := newClient(tt.now)
+		out, err := client.PromoteScheduledMessages(ctx, &dynamomq.PromoteScheduledMessagesInput{})
+		if err != nil {
+			t.Fatalf("%s: PromoteScheduledMessages() error = %v", tt.name, err)
+		}
+		if out.Promoted != tt.wantPromoted {
+			t.Errorf("%s: PromoteScheduledMessages() promoted = %d, want %d", tt.name, out.Promoted, tt.wantPromoted)
+		}
+		got := queueTypes(client)
+		if got["A-101"] != tt.want || got["A-102"] != dynamomq.QueueTypeScheduled {
+			t.Errorf("%s: queue types = %v, want A-101 %s", tt.name, got, tt.want)
+		}
+	}
+
+	received, err := newClient(test.DefaultTestDate.Add(time.Minute)).ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{})
+	if err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	if received.ReceivedMessage.ID != "A-101" && received.ReceivedMessage.ID != "A-103" {
+		t.Errorf("ReceiveMessage() id = %s, want a promoted or an immediate message", received.ReceivedMessage.ID)
+	}
+}
+
+func TestDynamoMQClientPromoteScheduledMessagesBoundary(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	due := dynamomqtest.NewReadyMessage("A-101", test.NewMessageData("A-101"), test.DefaultTestDate,
+		dynamomqtest.WithQueueType(dynamomq.QueueTypeScheduled), dynamomqtest.WithSentAt(now))
+	var (
+		query   *dynamodb.QueryInput
+		updates []*dynamodb.UpdateItemInput
+	)
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		mock.WithClock(mock.Clock{T: now}),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+				query = params
+				return &dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{
+					dynamomqtest.MarshalMap(due),
+					dynamomqtest.MarshalMap(due),
+				}}, nil
+			},
+			UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+				updates = append(updates, params)
+				if len(updates) > 1 {
+					// The message was promoted concurrently.
+					return nil, &types.ConditionalCheckFailedException{}
+				}
+				promoted := *due
+				promoted.QueueType = dynamomq.QueueTypeStandard
+				return &dynamodb.UpdateItemOutput{Attributes: dynamomqtest.MarshalMap(&promoted)}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	out, err := client.PromoteScheduledMessages(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("PromoteScheduledMessages() error = %v", err)
+	}
+	if out.Promoted != 1 {
+		t.Errorf("PromoteScheduledMessages() promoted = %d, want 1", out.Promoted)
+	}
+	values := map[string]bool{}
+	for _, v := range query.ExpressionAttributeValues {
+		if s, ok := v.(*types.AttributeValueMemberS); ok {
+			values[s.Value] = true
+		}
+	}
+	// The key condition reads the messages due up to now included.
+	if !values[string(dynamomq.QueueTypeScheduled)] || !values[clock.FormatRFC3339Nano(now)] {
+		t.Errorf("Query() key condition %s with %v, want SCHEDULED messages due by %s",
+			aws.ToString(query.KeyConditionExpression), values, clock.FormatRFC3339Nano(now))
+	}
+	if len(updates) != 2 || aws.ToString(updates[0].ConditionExpression) == "" {
+		t.Errorf("UpdateItem() calls = %d, want 2 conditional updates", len(updates))
+	}
+}
+
+func TestDynamoMQClientSendMessageWithScheduledQueue(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		opts  []func(*dynamomq.ClientOptions)
+		input *dynamomq.SendMessageInput[test.MessageData]
+		want  dynamomq.QueueType
+	}{
+		{
+			name:  "should schedule a delayed message",
+			opts:  []func(*dynamomq.ClientOptions){dynamomq.WithScheduledQueue(true)},
+			input: &dynamomq.SendMessageInput[test.MessageData]{ID: "A-101", DelaySeconds: 10},
+			want:  dynamomq.QueueTypeScheduled,
+		},
+		{
+			name:  "should not schedule a message without a delay",
+			opts:  []func(*dynamomq.ClientOptions){dynamomq.WithScheduledQueue(true)},
+			input: &dynamomq.SendMessageInput[test.MessageData]{ID: "A-101"},
+			want:  dynamomq.QueueTypeStandard,
+		},
+		{
+			name:  "should not schedule a message of another queue type",
+			opts:  []func(*dynamomq.ClientOptions){dynamomq.WithScheduledQueue(true)},
+			input: &dynamomq.SendMessageInput[test.MessageData]{ID: "A-101", DelaySeconds: 10, QueueType: "orders"},
+			want:  "orders",
+		},
+		{
+			name:  "should not schedule a delayed message by default",
+			input: &dynamomq.SendMessageInput[test.MessageData]{ID: "A-101", DelaySeconds: 10},
+			want:  dynamomq.QueueTypeStandard,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{}, append(tt.opts,
+				mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+						return &dynamodb.GetItemOutput{}, nil
+					},
+					PutItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+						return &dynamodb.PutItemOutput{}, nil
+					},
+				}))...)
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			out, err := client.SendMessage(context.Background(), tt.input)
+			if err != nil {
+				t.Fatalf("SendMessage() error = %v", err)
+			}
+			if out.SentMessage.QueueType != tt.want {
+				t.Errorf("SendMessage() queue type = %s, want %s", out.SentMessage.QueueType, tt.want)
+			}
+		})
+	}
+}
+
+func TestMover(t *testing.T) {
+	t.Parallel()
+	var (
+		mu       sync.Mutex
+		held     = true
+		runs     int
+		released []string
+	)
+	client := &mock.Client[test.MessageData]{
+		AcquireLockFunc: func(ctx context.Context, params *dynamomq.AcquireLockInput) (*dynamomq.AcquireLockOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if params.Name != "scheduler" || params.Owner != "worker-1" || params.TTL != time.Minute {
+				t.Errorf("AcquireLock() params = %+v", params)
+			}
+			// Another instance holds the lock on the first run only.
+			acquired := !held
+			held = false
+			return &dynamomq.AcquireLockOutput{Acquired: acquired}, nil
+		},
+		PromoteScheduledMessagesFunc: func(ctx context.Context, params *dynamomq.PromoteScheduledMessagesInput) (*dynamomq.PromoteScheduledMessagesOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if params.MaxMessages != 5 {
+				t.Errorf("PromoteScheduledMessages() params = %+v", params)
+			}
+			runs++
+			return &dynamomq.PromoteScheduledMessagesOutput{Promoted: 3}, nil
+		},
+		ReleaseLockFunc: func(ctx context.Context, params *dynamomq.ReleaseLockInput) (*dynamomq.ReleaseLockOutput, error) {
+			released = append(released, params.Owner)
+			return &dynamomq.ReleaseLockOutput{}, nil
+		},
+	}
+	mover := dynamomq.NewMover[test.MessageData](client,
+		dynamomq.WithMoverInterval(time.Millisecond),
+		dynamomq.WithMoverMaxMessagesPerRun(5),
+		dynamomq.WithMoverLock("scheduler", time.Minute),
+		dynamomq.WithMoverOwner("worker-1"))
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- mover.Start(context.Background())
+	}()
+	for {
+		mu.Lock()
+		n := runs
+		mu.Unlock()
+		if n >= 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	mover.Stop()
+	if err := <-errCh; !errors.Is(err, dynamomq.ErrMoverClosed) {
+		t.Errorf("Start() error = %v, want %v", err, dynamomq.ErrMoverClosed)
+	}
+	stats := mover.Stats()
+	if stats.Skipped != 1 || stats.Runs != int64(runs) || stats.Promoted != 3*int64(runs) || stats.Errors != 0 {
+		t.Errorf("Stats() = %+v, runs = %d", stats, runs)
+	}
+	test.AssertDeepEqual(t, released, []string{"worker-1"}, "released")
+}
@@ -0,0 +1,128 @@
+// Package notification provides DLQ notifiers that publish to Amazon SNS and Amazon EventBridge.
+// Set one with dynamomq.WithDLQNotifier to be told when a message is moved to the DLQ.
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	ebtypes "github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	snstypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/vvatanabe/dynamomq"
+)
+
+const (
+	// DefaultEventSource is the source of the events published by EventBridgeNotifier.
+	DefaultEventSource = "dynamomq"
+	// DefaultDetailType is the detail type of the events published by EventBridgeNotifier.
+	DefaultDetailType = "DynamoMQ Message Moved To DLQ"
+
+	defaultSubject         = "DynamoMQ: message moved to DLQ"
+	messageAttributeType   = "String"
+	messageAttributeQueue  = "queue_type"
+	messageAttributeReason = "reason"
+)
+
+// SNSAPI is the subset of the Amazon SNS API used by SNSNotifier. *sns.Client satisfies this interface.
+type SNSAPI interface {
+	Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error)
+}
+
+// SNSNotifier publishes a JSON document of each dynamomq.DLQNotification to an SNS topic.
+type SNSNotifier struct {
+	api      SNSAPI
+	topicARN string
+}
+
+// NewSNSNotifier creates an SNSNotifier that publishes to the topic identified by topicARN.
+func NewSNSNotifier(api SNSAPI, topicARN string) *SNSNotifier {
+	return &SNSNotifier{
+		api:      api,
+		topicARN: topicARN,
+	}
+}
+
+// NotifyDLQ publishes n to the SNS topic. The queue type and the reason are also set as message attributes
+// so that subscriptions can filter on them.
+func (s *SNSNotifier) NotifyDLQ(ctx context.Context, n *dynamomq.DLQNotification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the DLQ notification: %w", err)
+	}
+	attrs := map[string]snstypes.MessageAttributeValue{
+		messageAttributeQueue: {
+			DataType:    aws.String(messageAttributeType),
+			StringValue: aws.String(string(n.QueueType)),
+		},
+	}
+	if n.Reason != "" {
+		attrs[messageAttributeReason] = snstypes.MessageAttributeValue{
+			DataType:    aws.String(messageAttributeType),
+			StringValue: aws.String(n.Reason),
+		}
+	}
+	_, err = s.api.Publish(ctx, &sns.PublishInput{
+		TopicArn:          aws.String(s.topicARN),
+		Subject:           aws.String(defaultSubject),
+		Message:           aws.String(string(body)),
+		MessageAttributes: attrs,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish the DLQ notification to SNS: %w", err)
+	}
+	return nil
+}
+
+// EventBridgeAPI is the subset of the Amazon EventBridge API used by EventBridgeNotifier.
+// *eventbridge.Client satisfies this interface.
+type EventBridgeAPI interface {
+	PutEvents(ctx context.Context, params *eventbridge.PutEventsInput, optFns ...func(*eventbridge.Options)) (*eventbridge.PutEventsOutput, error)
+}
+
+// EventBridgeNotifier puts an event for each dynamomq.DLQNotification to an EventBridge event bus.
+// The detail of the event is the JSON document of the notification.
+type EventBridgeNotifier struct {
+	api          EventBridgeAPI
+	eventBusName string
+}
+
+// NewEventBridgeNotifier creates an EventBridgeNotifier that puts events to the event bus named eventBusName
+// with DefaultEventSource and DefaultDetailType.
+func NewEventBridgeNotifier(api EventBridgeAPI, eventBusName string) *EventBridgeNotifier {
+	return &EventBridgeNotifier{
+		api:          api,
+		eventBusName: eventBusName,
+	}
+}
+
+// NotifyDLQ puts an event for n to the event bus. PutEvents reports a rejected entry in its output
+// rather than with an error, so a rejected event is returned as an error too.
+func (e *EventBridgeNotifier) NotifyDLQ(ctx context.Context, n *dynamomq.DLQNotification) error {
+	detail, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the DLQ notification: %w", err)
+	}
+	out, err := e.api.PutEvents(ctx, &eventbridge.PutEventsInput{
+		Entries: []ebtypes.PutEventsRequestEntry{{
+			EventBusName: aws.String(e.eventBusName),
+			Source:       aws.String(DefaultEventSource),
+			DetailType:   aws.String(DefaultDetailType),
+			Detail:       aws.String(string(detail)),
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put the DLQ notification to EventBridge: %w", err)
+	}
+	if out.FailedEntryCount > 0 {
+		var code, message string
+		if len(out.Entries) > 0 {
+			code, message = aws.ToString(out.Entries[0].ErrorCode), aws.ToString(out.Entries[0].ErrorMessage)
+		}
+		return fmt.Errorf("failed to put the DLQ notification to EventBridge: %s: %s", code, message)
+	}
+	return nil
+}
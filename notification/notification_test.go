@@ -0,0 +1,134 @@
+package notification_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	ebtypes "github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/test"
+	"github.com/vvatanabe/dynamomq/notification"
+)
+
+type fakeSNS struct {
+	published []*sns.PublishInput
+	err       error
+}
+
+func (f *fakeSNS) Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.published = append(f.published, params)
+	return &sns.PublishOutput{}, nil
+}
+
+func newNotification() *dynamomq.DLQNotification {
+	return &dynamomq.DLQNotification{
+		ID:           "A-101",
+		QueueType:    dynamomq.QueueTypeStandard,
+		ReceiveCount: 3,
+		Reason:       "invalid order",
+		MovedAt:      "2023-12-01T00:00:10Z",
+	}
+}
+
+func TestSNSNotifier(t *testing.T) {
+	t.Parallel()
+	api := &fakeSNS{}
+	notifier := notification.NewSNSNotifier(api, "arn:aws:sns:us-east-1:123456789012:dlq")
+	if err := notifier.NotifyDLQ(context.Background(), newNotification()); err != nil {
+		t.Fatalf("NotifyDLQ() error = %v", err)
+	}
+	if len(api.published) != 1 {
+		t.Fatalf("Publish() calls = %d, want 1", len(api.published))
+	}
+	in := api.published[0]
+	if got := aws.ToString(in.TopicArn); got != "arn:aws:sns:us-east-1:123456789012:dlq" {
+		t.Errorf("Publish() TopicArn = %v", got)
+	}
+	var got dynamomq.DLQNotification
+	if err := json.Unmarshal([]byte(aws.ToString(in.Message)), &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	test.AssertDeepEqual(t, &got, newNotification(), "Publish() Message")
+	if got := aws.ToString(in.MessageAttributes["reason"].StringValue); got != "invalid order" {
+		t.Errorf("Publish() reason attribute = %v, want invalid order", got)
+	}
+}
+
+func TestSNSNotifierError(t *testing.T) {
+	t.Parallel()
+	notifier := notification.NewSNSNotifier(&fakeSNS{err: test.ErrTest}, "arn")
+	if err := notifier.NotifyDLQ(context.Background(), newNotification()); !errors.Is(err, test.ErrTest) {
+		t.Errorf("NotifyDLQ() error = %v, want %v", err, test.ErrTest)
+	}
+}
+
+type fakeEventBridge struct {
+	put    []*eventbridge.PutEventsInput
+	output *eventbridge.PutEventsOutput
+	err    error
+}
+
+func (f *fakeEventBridge) PutEvents(ctx context.Context, params *eventbridge.PutEventsInput, optFns ...func(*eventbridge.Options)) (*eventbridge.PutEventsOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.put = append(f.put, params)
+	if f.output != nil {
+		return f.output, nil
+	}
+	return &eventbridge.PutEventsOutput{}, nil
+}
+
+func TestEventBridgeNotifier(t *testing.T) {
+	t.Parallel()
+	api := &fakeEventBridge{}
+	notifier := notification.NewEventBridgeNotifier(api, "ops")
+	if err := notifier.NotifyDLQ(context.Background(), newNotification()); err != nil {
+		t.Fatalf("NotifyDLQ() error = %v", err)
+	}
+	if len(api.put) != 1 || len(api.put[0].Entries) != 1 {
+		t.Fatalf("PutEvents() inputs = %+v, want a single entry", api.put)
+	}
+	e := api.put[0].Entries[0]
+	if aws.ToString(e.EventBusName) != "ops" || aws.ToString(e.Source) != notification.DefaultEventSource ||
+		aws.ToString(e.DetailType) != notification.DefaultDetailType {
+		t.Errorf("PutEvents() entry = %+v", e)
+	}
+	var got dynamomq.DLQNotification
+	if err := json.Unmarshal([]byte(aws.ToString(e.Detail)), &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	test.AssertDeepEqual(t, &got, newNotification(), "PutEvents() Detail")
+}
+
+func TestEventBridgeNotifierError(t *testing.T) {
+	t.Parallel()
+	notifier := notification.NewEventBridgeNotifier(&fakeEventBridge{err: test.ErrTest}, "ops")
+	if err := notifier.NotifyDLQ(context.Background(), newNotification()); !errors.Is(err, test.ErrTest) {
+		t.Errorf("NotifyDLQ() error = %v, want %v", err, test.ErrTest)
+	}
+}
+
+func TestEventBridgeNotifierFailedEntry(t *testing.T) {
+	t.Parallel()
+	notifier := notification.NewEventBridgeNotifier(&fakeEventBridge{output: &eventbridge.PutEventsOutput{
+		FailedEntryCount: 1,
+		Entries: []ebtypes.PutEventsResultEntry{{
+			ErrorCode:    aws.String("InternalFailure"),
+			ErrorMessage: aws.String("sample message"),
+		}},
+	}}, "ops")
+	err := notifier.NotifyDLQ(context.Background(), newNotification())
+	if err == nil || !strings.Contains(err.Error(), "InternalFailure") {
+		t.Errorf("NotifyDLQ() error = %v, want the rejected entry", err)
+	}
+}
@@ -0,0 +1,136 @@
+package dynamomq
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// timeoutDynamoDB is a DynamoDBAPI bounding every call with the timeout set with WithOperationTimeout.
+// The client calls it once for each page of a paginated operation, so the timeout applies per page.
+// It also reports the call cut short by the timeout set with WithDefaultOperationTimeout.
+type timeoutDynamoDB struct {
+	api     DynamoDBAPI
+	timeout time.Duration
+}
+
+func (d timeoutDynamoDB) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return callWithTimeout(ctx, d.timeout, "GetItem", func(ctx context.Context) (*dynamodb.GetItemOutput, error) {
+		return d.api.GetItem(ctx, params, optFns...)
+	})
+}
+
+func (d timeoutDynamoDB) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	return callWithTimeout(ctx, d.timeout, "PutItem", func(ctx context.Context) (*dynamodb.PutItemOutput, error) {
+		return d.api.PutItem(ctx, params, optFns...)
+	})
+}
+
+func (d timeoutDynamoDB) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	return callWithTimeout(ctx, d.timeout, "UpdateItem", func(ctx context.Context) (*dynamodb.UpdateItemOutput, error) {
+		return d.api.UpdateItem(ctx, params, optFns...)
+	})
+}
+
+func (d timeoutDynamoDB) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	return callWithTimeout(ctx, d.timeout, "DeleteItem", func(ctx context.Context) (*dynamodb.DeleteItemOutput, error) {
+		return d.api.DeleteItem(ctx, params, optFns...)
+	})
+}
+
+func (d timeoutDynamoDB) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	return callWithTimeout(ctx, d.timeout, "Query", func(ctx context.Context) (*dynamodb.QueryOutput, error) {
+		return d.api.Query(ctx, params, optFns...)
+	})
+}
+
+func (d timeoutDynamoDB) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	return callWithTimeout(ctx, d.timeout, "Scan", func(ctx context.Context) (*dynamodb.ScanOutput, error) {
+		return d.api.Scan(ctx, params, optFns...)
+	})
+}
+
+func (d timeoutDynamoDB) DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	return callWithTimeout(ctx, d.timeout, "DescribeTable", func(ctx context.Context) (*dynamodb.DescribeTableOutput, error) {
+		return d.api.DescribeTable(ctx, params, optFns...)
+	})
+}
+
+func (d timeoutDynamoDB) DescribeTimeToLive(ctx context.Context, params *dynamodb.DescribeTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error) {
+	return callWithTimeout(ctx, d.timeout, "DescribeTimeToLive", func(ctx context.Context) (*dynamodb.DescribeTimeToLiveOutput, error) {
+		return d.api.DescribeTimeToLive(ctx, params, optFns...)
+	})
+}
+
+func (d timeoutDynamoDB) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	return callWithTimeout(ctx, d.timeout, "TransactWriteItems", func(ctx context.Context) (*dynamodb.TransactWriteItemsOutput, error) {
+		return d.api.TransactWriteItems(ctx, params, optFns...)
+	})
+}
+
+func (d timeoutDynamoDB) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	return callWithTimeout(ctx, d.timeout, "BatchWriteItem", func(ctx context.Context) (*dynamodb.BatchWriteItemOutput, error) {
+		return d.api.BatchWriteItem(ctx, params, optFns...)
+	})
+}
+
+func (d timeoutDynamoDB) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	return callWithTimeout(ctx, d.timeout, "BatchGetItem", func(ctx context.Context) (*dynamodb.BatchGetItemOutput, error) {
+		return d.api.BatchGetItem(ctx, params, optFns...)
+	})
+}
+
+// callWithTimeout calls the operation with a context derived from ctx that expires after the timeout, if any.
+// It returns an OperationTimeoutError when the call fails because of the timeout, and not because ctx itself is done,
+// or a DefaultOperationTimeoutError when it fails because the timeout of the operation of the client expired.
+func callWithTimeout[T any](ctx context.Context, timeout time.Duration, operation string, call func(context.Context) (T, error)) (T, error) {
+	callCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	out, err := call(callCtx)
+	if err == nil {
+		return out, nil
+	}
+	var zero T
+	if ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		return zero, OperationTimeoutError{Operation: operation, Timeout: timeout, Cause: err}
+	}
+	if timeoutErr, ok := defaultTimeoutCause(ctx); ok {
+		timeoutErr.Step = operation
+		timeoutErr.Cause = err
+		return zero, timeoutErr
+	}
+	return out, err
+}
+
+// withDefaultTimeout returns a context bounding the operation with the timeout set with WithDefaultOperationTimeout,
+// unless ctx already has a deadline. The cause of the returned context tells that the timeout expired.
+func (c *ClientImpl[T]) withDefaultTimeout(ctx context.Context, operation string) (context.Context, context.CancelFunc) {
+	if c.defaultOperationTimeout <= 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, c.defaultOperationTimeout, DefaultOperationTimeoutError{
+		Operation: operation,
+		Timeout:   c.defaultOperationTimeout,
+		Cause:     context.DeadlineExceeded,
+	})
+}
+
+// defaultTimeoutCause returns the DefaultOperationTimeoutError set by withDefaultTimeout when ctx is done
+// because the timeout expired.
+func defaultTimeoutCause(ctx context.Context) (DefaultOperationTimeoutError, bool) {
+	var timeoutErr DefaultOperationTimeoutError
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return timeoutErr, false
+	}
+	ok := errors.As(context.Cause(ctx), &timeoutErr)
+	return timeoutErr, ok
+}
@@ -0,0 +1,226 @@
+package dynamomq_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+// newQueryDynamoDB returns a table whose Query calls query.
+func newQueryDynamoDB(query func(ctx context.Context, params *dynamodb.QueryInput) (*dynamodb.QueryOutput, error)) *mock.DynamoDB {
+	return &mock.DynamoDB{
+		QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+			return query(ctx, params)
+		},
+	}
+}
+
+func TestDynamoMQClientWithOperationTimeout(t *testing.T) {
+	t.Parallel()
+	client := newTestClient[test.MessageData](t, newQueryDynamoDB(func(ctx context.Context, params *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}), dynamomq.WithOperationTimeout(50*time.Millisecond), mock.WithClock(mock.Clock{T: test.DefaultTestDate.Add(time.Minute)}))
+	_, err := client.ReceiveMessage(context.Background(), &dynamomq.ReceiveMessageInput{})
+	var timeoutErr dynamomq.OperationTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("ReceiveMessage() error = %v, want OperationTimeoutError", err)
+	}
+	if timeoutErr.Operation != "Query" || timeoutErr.Timeout != 50*time.Millisecond {
+		t.Errorf("ReceiveMessage() error = %+v, want Query and 50ms", timeoutErr)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("errors.Is(%v, context.DeadlineExceeded) = false, want true", err)
+	}
+}
+
+func TestDynamoMQClientWithOperationTimeoutOnTable(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	client, clean := prepareTestClient(ctx, t, NewSetupFunc(), mock.Clock{T: test.DefaultTestDate}, false, nil, nil, nil,
+		dynamomq.WithOperationTimeout(10*time.Second), dynamomq.WithDefaultOperationTimeout(30*time.Second))
+	defer clean()
+	if _, err := client.SendMessage(ctx, &dynamomq.SendMessageInput[test.MessageData]{
+		ID:   "A-101",
+		Data: test.NewMessageData("A-101"),
+	}); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	received, err := client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{})
+	if err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	if _, err = client.DeleteMessage(ctx, &dynamomq.DeleteMessageInput{
+		ID:              "A-101",
+		ExpectedVersion: received.ReceivedMessage.Version,
+	}); err != nil {
+		t.Fatalf("DeleteMessage() error = %v", err)
+	}
+	got, err := client.GetMessage(ctx, &dynamomq.GetMessageInput{ID: "A-101"})
+	if err != nil {
+		t.Fatalf("GetMessage() error = %v", err)
+	}
+	if got.Message != nil {
+		t.Errorf("GetMessage() = %v, want the message deleted", got.Message)
+	}
+}
+
+func TestDynamoMQClientWithOperationTimeoutShouldApplyPerPage(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	queue := &pagedQueue{}
+	for i := 0; i < 70; i++ {
+		queue.items = append(queue.items, dynamomqtest.MarshalMap(NewTestMessageItemAsProcessing(fmt.Sprintf("B-%03d", i), now)))
+	}
+	// Each page takes less than the timeout, while the whole operation takes more.
+	client := newTestClient[test.MessageData](t, newQueryDynamoDB(func(ctx context.Context, params *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(30 * time.Millisecond):
+			return queue.query(params), nil
+		}
+	}), dynamomq.WithOperationTimeout(50*time.Millisecond), mock.WithClock(mock.Clock{T: test.DefaultTestDate.Add(time.Minute)}))
+	_, err := client.ReceiveMessage(context.Background(), &dynamomq.ReceiveMessageInput{})
+	test.AssertError(t, err, dynamomq.ErrEmptyQueue, "ReceiveMessage()")
+	if len(queue.limits) < 2 {
+		t.Errorf("Query() calls = %d, want several pages", len(queue.limits))
+	}
+}
+
+func TestDynamoMQClientWithOperationTimeoutShouldKeepCallerCancellation(t *testing.T) {
+	t.Parallel()
+	client := newTestClient[test.MessageData](t, newQueryDynamoDB(func(ctx context.Context, params *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}), dynamomq.WithOperationTimeout(50*time.Millisecond), mock.WithClock(mock.Clock{T: test.DefaultTestDate.Add(time.Minute)}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{})
+	if errors.As(err, &dynamomq.OperationTimeoutError{}) {
+		t.Errorf("ReceiveMessage() error = %v, want the cancellation of the caller", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("errors.Is(%v, context.Canceled) = false, want true", err)
+	}
+}
+
+func TestDynamoMQClientWithDefaultOperationTimeout(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	item := dynamomqtest.MarshalMap(NewTestMessageItemAsProcessing("A-101", now))
+	block := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	queue := &pagedQueue{}
+	for i := 0; i < 70; i++ {
+		queue.items = append(queue.items, dynamomqtest.MarshalMap(NewTestMessageItemAsProcessing(fmt.Sprintf("B-%03d", i), now)))
+	}
+	client := newTestClient[test.MessageData](t, &mock.DynamoDB{
+		GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+			return &dynamodb.GetItemOutput{Item: item}, nil
+		},
+		UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+			return nil, block(ctx)
+		},
+		TransactWriteItemsFunc: func(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+			return nil, block(ctx)
+		},
+		// Each page takes less than the timeout, while the whole operation takes more.
+		QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(30 * time.Millisecond):
+				return queue.query(params), nil
+			}
+		},
+	}, dynamomq.WithDefaultOperationTimeout(50*time.Millisecond), mock.WithClock(mock.Clock{T: now}))
+	tests := []struct {
+		name      string
+		operation func(ctx context.Context) error
+		wantOp    string
+		wantStep  string
+	}{
+		{
+			name: "should name the step of a composite operation that timed out",
+			operation: func(ctx context.Context) error {
+				_, err := client.ChangeMessageVisibility(ctx, &dynamomq.ChangeMessageVisibilityInput{ID: "A-101", VisibilityTimeout: 10})
+				return err
+			},
+			wantOp:   "ChangeMessageVisibility",
+			wantStep: "UpdateItem",
+		},
+		{
+			name: "should bound every page of an operation together",
+			operation: func(ctx context.Context) error {
+				_, err := client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{VisibilityTimeout: 30})
+				return err
+			},
+			wantOp:   "ReceiveMessage",
+			wantStep: "Query",
+		},
+		{
+			name: "should bound the transaction of ChainMessage",
+			operation: func(ctx context.Context) error {
+				_, err := client.ChainMessage(ctx, &dynamomq.ChainMessageInput[test.MessageData]{
+					DeleteID:      "A-101",
+					DeleteVersion: 2,
+					Next:          &dynamomq.SendMessageInput[test.MessageData]{ID: "C-101", Data: test.NewMessageData("C-101")},
+				})
+				return err
+			},
+			wantOp:   "ChainMessage",
+			wantStep: "TransactWriteItems",
+		},
+		{
+			name: "should bound the transaction of SendMessagesInTransaction",
+			operation: func(ctx context.Context) error {
+				_, err := client.SendMessagesInTransaction(ctx, &dynamomq.SendMessagesInTransactionInput[test.MessageData]{
+					Messages: []*dynamomq.SendMessageInput[test.MessageData]{{ID: "C-101", Data: test.NewMessageData("C-101")}},
+				})
+				return err
+			},
+			wantOp:   "SendMessagesInTransaction",
+			wantStep: "TransactWriteItems",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.operation(context.Background())
+			var timeoutErr dynamomq.DefaultOperationTimeoutError
+			if !errors.As(err, &timeoutErr) {
+				t.Fatalf("error = %v, want DefaultOperationTimeoutError", err)
+			}
+			if timeoutErr.Operation != tt.wantOp || timeoutErr.Step != tt.wantStep || timeoutErr.Timeout != 50*time.Millisecond {
+				t.Errorf("error = %+v, want %s, %s and 50ms", timeoutErr, tt.wantOp, tt.wantStep)
+			}
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("errors.Is(%v, context.DeadlineExceeded) = false, want true", err)
+			}
+		})
+	}
+	t.Run("should keep the deadline of the caller", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := client.ChangeMessageVisibility(ctx, &dynamomq.ChangeMessageVisibilityInput{ID: "A-101", VisibilityTimeout: 10})
+		if errors.As(err, &dynamomq.DefaultOperationTimeoutError{}) {
+			t.Errorf("ChangeMessageVisibility() error = %v, want the deadline of the caller", err)
+		}
+		if err == nil {
+			t.Error("ChangeMessageVisibility() error = nil, want the deadline of the caller")
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package dynamomq_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+// pagedQueue is a fake queueing index that serves its items in pages and counts the queries made on it.
+type pagedQueue struct {
+	items   []map[string]types.AttributeValue
+	limits  []int32
+	fetched int
+}
+
+func (f *pagedQueue) query(params *dynamodb.QueryInput) *dynamodb.QueryOutput {
+	f.limits = append(f.limits, *params.Limit)
+	offset := 0
+	if v, ok := params.ExclusiveStartKey["offset"].(*types.AttributeValueMemberN); ok {
+		offset, _ = strconv.Atoi(v.Value)
+	}
+	end := offset + int(*params.Limit)
+	if end > len(f.items) {
+		end = len(f.items)
+	}
+	out := &dynamodb.QueryOutput{Items: f.items[offset:end]}
+	if end < len(f.items) {
+		out.LastEvaluatedKey = map[string]types.AttributeValue{
+			"offset": &types.AttributeValueMemberN{Value: strconv.Itoa(end)},
+		}
+	}
+	f.fetched += len(out.Items)
+	return out
+}
+
+func TestDynamoMQClientReceivePageSize(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	tests := []struct {
+		name        string
+		opts        []func(*dynamomq.ClientOptions)
+		processing  int
+		wantLimits  []int32
+		wantFetched int
+	}{
+		{
+			name:        "should read a small page when the head is ready",
+			processing:  0,
+			wantLimits:  []int32{10},
+			wantFetched: 10,
+		},
+		{
+			name:        "should grow the page on pages without a candidate",
+			processing:  70,
+			wantLimits:  []int32{10, 20, 40, 80},
+			wantFetched: 150,
+		},
+		{
+			name:        "should keep the page within the configured bounds",
+			opts:        []func(*dynamomq.ClientOptions){dynamomq.WithReceivePageSize(5, 20)},
+			processing:  70,
+			wantLimits:  []int32{5, 10, 20, 20, 20},
+			wantFetched: 75,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			queue := &pagedQueue{}
+			for i := 0; i < tt.processing; i++ {
+				queue.items = append(queue.items, dynamomqtest.MarshalMap(NewTestMessageItemAsProcessing(fmt.Sprintf("B-%03d", i), now)))
+			}
+			for i := 0; i < 100; i++ {
+				queue.items = append(queue.items, dynamomqtest.MarshalMap(NewTestMessageItemAsReady(fmt.Sprintf("A-%03d", i), test.DefaultTestDate)))
+			}
+			client := newPagedQueueClient(t, queue, now, tt.opts...)
+			out, err := client.ReceiveMessage(context.Background(), &dynamomq.ReceiveMessageInput{})
+			if err != nil {
+				t.Fatalf("ReceiveMessage() error = %v", err)
+			}
+			if out.ReceivedMessage.ID != "A-000" {
+				t.Errorf("ReceiveMessage() id = %s, want A-000", out.ReceivedMessage.ID)
+			}
+			test.AssertDeepEqual(t, queue.limits, tt.wantLimits, "Query() limits")
+			if queue.fetched != tt.wantFetched {
+				t.Errorf("fetched items = %d, want %d", queue.fetched, tt.wantFetched)
+			}
+		})
+	}
+}
+
+func TestDynamoMQClientReceivePageSizeReset(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	queue := &pagedQueue{}
+	for i := 0; i < 70; i++ {
+		queue.items = append(queue.items, dynamomqtest.MarshalMap(NewTestMessageItemAsProcessing(fmt.Sprintf("B-%03d", i), now)))
+	}
+	queue.items = append(queue.items,
+		dynamomqtest.MarshalMap(NewTestMessageItemAsReady("A-101", test.DefaultTestDate)),
+		dynamomqtest.MarshalMap(NewTestMessageItemAsReady("A-102", test.DefaultTestDate)))
+	client := newPagedQueueClient(t, queue, now)
+	ctx := context.Background()
+	for _, want := range []string{"A-101", "A-102"} {
+		queue.limits = nil
+		out, err := client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{})
+		if err != nil {
+			t.Fatalf("ReceiveMessage() error = %v", err)
+		}
+		if out.ReceivedMessage.ID != want {
+			t.Errorf("ReceiveMessage() id = %s, want %s", out.ReceivedMessage.ID, want)
+		}
+		test.AssertDeepEqual(t, queue.limits, []int32{10, 20, 40, 80}, "Query() limits")
+	}
+}
+
+// newPagedQueueClient returns a client receiving from the queue, where a received message becomes invisible.
+func newPagedQueueClient(t *testing.T, queue *pagedQueue, now time.Time,
+	opts ...func(*dynamomq.ClientOptions)) dynamomq.Client[test.MessageData] {
+	t.Helper()
+	opts = append(opts,
+		mock.WithClock(mock.Clock{T: now}),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+				return queue.query(params), nil
+			},
+			UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+				id := params.Key[dynamomq.AttributeNameID].(*types.AttributeValueMemberS).Value
+				received := dynamomqtest.MarshalMap(NewTestMessageItemAsProcessing(id, now))
+				for i, item := range queue.items {
+					if item[dynamomq.AttributeNameID].(*types.AttributeValueMemberS).Value == id {
+						queue.items[i] = received
+					}
+				}
+				return &dynamodb.UpdateItemOutput{Attributes: received}, nil
+			},
+		}))
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{}, opts...)
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	return client
+}
@@ -0,0 +1,96 @@
+package dynamomq
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// rawPayload reports whether the messages of type T carry a raw payload, a json.RawMessage or a []byte,
+// which DynamoMQ stores as a single attribute instead of marshaling it with attributevalue.
+// A json.RawMessage is stored as a string, so that it stays readable in the table, and a []byte as a binary.
+func rawPayload[T any]() bool {
+	var zero T
+	switch any(zero).(type) {
+	case json.RawMessage, []byte:
+		return true
+	}
+	return false
+}
+
+// rawPayloadValue returns the attribute value storing a raw payload. An empty payload is stored as a null.
+func rawPayloadValue[T any](data T) types.AttributeValue {
+	switch d := any(data).(type) {
+	case json.RawMessage:
+		if len(d) == 0 {
+			return &types.AttributeValueMemberNULL{Value: true}
+		}
+		return &types.AttributeValueMemberS{Value: string(d)}
+	case []byte:
+		if len(d) == 0 {
+			return &types.AttributeValueMemberNULL{Value: true}
+		}
+		return &types.AttributeValueMemberB{Value: d}
+	}
+	panic(fmt.Sprintf("DynamoMQ: %s is not a raw payload", typeNameOf[T]()))
+}
+
+// rawPayloadOf returns the raw payload stored in an attribute value. Both a string and a binary are read into
+// either type, so that the payloads written as binaries before a json.RawMessage was stored as a string still read.
+func rawPayloadOf[T any](av types.AttributeValue) (T, error) {
+	var raw []byte
+	switch v := av.(type) {
+	case nil, *types.AttributeValueMemberNULL:
+	case *types.AttributeValueMemberS:
+		raw = []byte(v.Value)
+	case *types.AttributeValueMemberB:
+		raw = v.Value
+	default:
+		return *new(T), fmt.Errorf("cannot unmarshal %T into a raw payload of type %s", av, typeNameOf[T]())
+	}
+	if data, ok := any(json.RawMessage(raw)).(T); ok {
+		return data, nil
+	}
+	return any(raw).(T), nil
+}
+
+// marshalMessage marshals a message with marshal, storing its payload as a single attribute if it is raw,
+// without traversing it with marshal.
+func marshalMessage[T any](message *Message[T], marshal func(in interface{}) (map[string]types.AttributeValue, error)) (map[string]types.AttributeValue, error) {
+	if !rawPayload[T]() {
+		return marshal(message)
+	}
+	withoutData := *message
+	withoutData.Data = *new(T)
+	item, err := marshal(&withoutData)
+	if err != nil {
+		return nil, err
+	}
+	item[AttributeNameData] = rawPayloadValue(message.Data)
+	return item, nil
+}
+
+// unmarshalMessage unmarshals an item into out with unmarshal, reading its payload directly from its attribute
+// if it is raw, without traversing it with unmarshal.
+func unmarshalMessage[T any](item map[string]types.AttributeValue, out *Message[T], unmarshal func(m map[string]types.AttributeValue, out interface{}) error) error {
+	av, ok := item[AttributeNameData]
+	if !ok || !rawPayload[T]() {
+		return unmarshal(item, out)
+	}
+	withoutData := make(map[string]types.AttributeValue, len(item)-1)
+	for k, v := range item {
+		if k != AttributeNameData {
+			withoutData[k] = v
+		}
+	}
+	if err := unmarshal(withoutData, out); err != nil {
+		return err
+	}
+	data, err := rawPayloadOf[T](av)
+	if err != nil {
+		return err
+	}
+	out.Data = data
+	return nil
+}
@@ -0,0 +1,181 @@
+package dynamomq_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+// newRawPayloadDynamoDB returns a table holding the single item put into it.
+func newRawPayloadDynamoDB(table *map[string]types.AttributeValue) *mock.DynamoDB {
+	return &mock.DynamoDB{
+		GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+			return &dynamodb.GetItemOutput{Item: *table}, nil
+		},
+		PutItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+			*table = params.Item
+			return &dynamodb.PutItemOutput{}, nil
+		},
+	}
+}
+
+// roundTripRawPayload sends data, checks that it is stored as want, and returns the data read back.
+func roundTripRawPayload[T any](t *testing.T, data T, want types.AttributeValue) T {
+	t.Helper()
+	var table map[string]types.AttributeValue
+	client := newTestClient[T](t, newRawPayloadDynamoDB(&table), mock.WithClock(mock.Clock{T: test.DefaultTestDate}))
+	sent, err := client.SendMessage(context.Background(), &dynamomq.SendMessageInput[T]{ID: "A-101", Data: data})
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	test.AssertDeepEqual(t, table[dynamomq.AttributeNameData], want, "stored data")
+	got, err := client.GetMessage(context.Background(), &dynamomq.GetMessageInput{ID: "A-101"})
+	if err != nil {
+		t.Fatalf("GetMessage() error = %v", err)
+	}
+	test.AssertDeepEqual(t, got.Message, sent.SentMessage, "GetMessage()")
+	return got.Message.Data
+}
+
+func TestDynamoMQClientRawJSONPayload(t *testing.T) {
+	t.Parallel()
+	data := json.RawMessage(`{"order_id":"order-1","amount_cents":1234}`)
+	got := roundTripRawPayload(t, data, types.AttributeValue(&types.AttributeValueMemberS{Value: string(data)}))
+	if string(got) != string(data) {
+		t.Errorf("data = %s, want %s", got, data)
+	}
+}
+
+func TestDynamoMQClientBinaryPayload(t *testing.T) {
+	t.Parallel()
+	data := []byte{0x00, 0x01, 0xfe, 0xff}
+	got := roundTripRawPayload(t, data, types.AttributeValue(&types.AttributeValueMemberB{Value: data}))
+	test.AssertDeepEqual(t, got, data, "data")
+}
+
+func TestDynamoMQClientEmptyRawPayload(t *testing.T) {
+	t.Parallel()
+	got := roundTripRawPayload[json.RawMessage](t, nil, types.AttributeValue(&types.AttributeValueMemberNULL{Value: true}))
+	if len(got) != 0 {
+		t.Errorf("data = %s, want empty", got)
+	}
+}
+
+func TestUnmarshalRawJSONPayloadStoredAsBinary(t *testing.T) {
+	t.Parallel()
+	message := dynamomq.NewMessage("A-101", []byte(`{"id":"A-101"}`), test.DefaultTestDate)
+	item, err := message.MarshalMap()
+	if err != nil {
+		t.Fatalf("MarshalMap() error = %v", err)
+	}
+	got, err := dynamomq.UnmarshalMessage[json.RawMessage](item)
+	if err != nil {
+		t.Fatalf("UnmarshalMessage() error = %v", err)
+	}
+	if string(got.Data) != `{"id":"A-101"}` {
+		t.Errorf("UnmarshalMessage() data = %s, want %s", got.Data, `{"id":"A-101"}`)
+	}
+}
+
+func TestUnmarshalRawPayloadError(t *testing.T) {
+	t.Parallel()
+	message := dynamomq.NewMessage("A-101", json.RawMessage(`{}`), test.DefaultTestDate)
+	item, err := message.MarshalMap()
+	if err != nil {
+		t.Fatalf("MarshalMap() error = %v", err)
+	}
+	item[dynamomq.AttributeNameData] = &types.AttributeValueMemberN{Value: "1"}
+	if _, err := dynamomq.UnmarshalMessage[json.RawMessage](item); err == nil {
+		t.Error("UnmarshalMessage() error = nil, want an error for a number payload")
+	}
+}
+
+func TestDynamoMQClientUpdateRawJSONPayload(t *testing.T) {
+	t.Parallel()
+	var update *dynamodb.UpdateItemInput
+	updated := dynamomq.NewMessage("A-101", json.RawMessage(`{"v":2}`), test.DefaultTestDate)
+	item, err := updated.MarshalMap()
+	if err != nil {
+		t.Fatalf("MarshalMap() error = %v", err)
+	}
+	client := newTestClient[json.RawMessage](t, &mock.DynamoDB{
+		UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+			update = params
+			return &dynamodb.UpdateItemOutput{Attributes: item}, nil
+		},
+	})
+	got, err := client.UpdateMessageData(context.Background(), &dynamomq.UpdateMessageDataInput[json.RawMessage]{
+		ID:   "A-101",
+		Data: json.RawMessage(`{"v":2}`),
+	})
+	if err != nil {
+		t.Fatalf("UpdateMessageData() error = %v", err)
+	}
+	var stored bool
+	for _, v := range update.ExpressionAttributeValues {
+		if s, ok := v.(*types.AttributeValueMemberS); ok && s.Value == `{"v":2}` {
+			stored = true
+		}
+	}
+	if !stored {
+		t.Errorf("UpdateMessageData() values = %v, want the payload as a string", update.ExpressionAttributeValues)
+	}
+	if string(got.UpdatedMessage.Data) != `{"v":2}` {
+		t.Errorf("UpdateMessageData() data = %s, want %s", got.UpdatedMessage.Data, `{"v":2}`)
+	}
+}
+
+func TestDynamoMQClientRawPayloadOnTable(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tableName, raw, clean := SetupDynamoDB(t)
+	defer clean()
+	opts := []func(*dynamomq.ClientOptions){
+		dynamomq.WithTableName(tableName),
+		mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+	}
+	jsonClient := newTestClient[json.RawMessage](t, raw, opts...)
+	if _, err := jsonClient.SendMessage(ctx, &dynamomq.SendMessageInput[json.RawMessage]{
+		ID:   "A-101",
+		Data: json.RawMessage(`{"v":1}`),
+	}); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	received, err := jsonClient.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{})
+	if err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	if string(received.ReceivedMessage.Data) != `{"v":1}` {
+		t.Errorf("ReceiveMessage() data = %s, want %s", received.ReceivedMessage.Data, `{"v":1}`)
+	}
+	if _, err = jsonClient.UpdateMessageData(ctx, &dynamomq.UpdateMessageDataInput[json.RawMessage]{
+		ID:   "A-101",
+		Data: json.RawMessage(`{"v":2}`),
+	}); err != nil {
+		t.Fatalf("UpdateMessageData() error = %v", err)
+	}
+	got, err := jsonClient.GetMessage(ctx, &dynamomq.GetMessageInput{ID: "A-101"})
+	if err != nil {
+		t.Fatalf("GetMessage() error = %v", err)
+	}
+	if string(got.Message.Data) != `{"v":2}` {
+		t.Errorf("GetMessage() data = %s, want %s", got.Message.Data, `{"v":2}`)
+	}
+
+	binaryClient := newTestClient[[]byte](t, raw, opts...)
+	data := []byte{0x00, 0x01, 0xfe, 0xff}
+	if _, err = binaryClient.SendMessage(ctx, &dynamomq.SendMessageInput[[]byte]{ID: "B-101", Data: data}); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	gotBinary, err := binaryClient.GetMessage(ctx, &dynamomq.GetMessageInput{ID: "B-101"})
+	if err != nil {
+		t.Fatalf("GetMessage() error = %v", err)
+	}
+	test.AssertDeepEqual(t, gotBinary.Message.Data, data, "GetMessage() data")
+}
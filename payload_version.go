@@ -0,0 +1,101 @@
+package dynamomq
+
+import (
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// PayloadUpgrader converts the payload of a message written with an older version of its schema into the current
+// type of the payload. It receives the version the message was written with, zero for messages sent without one,
+// and the stored item, with the payload under AttributeNameData.
+type PayloadUpgrader[T any] func(version int, raw map[string]types.AttributeValue) (T, error)
+
+type payloadUpgrade[T any] struct {
+	version  int
+	upgrader PayloadUpgrader[T]
+}
+
+// WithPayloadUpgrader is an option function to read the messages whose payload was written with an older version of
+// its schema. The client stamps version as the PayloadSchemaVersion of the messages it sends, unless
+// the SendMessageInput sets another one, and the messages read with an older PayloadSchemaVersion have their payload
+// converted by upgrader instead of being unmarshaled into T. The other attributes of those messages are unmarshaled
+// as usual, and their PayloadSchemaVersion is set to version. The messages of the current version are unmarshaled
+// without calling upgrader. A failed upgrade is reported as a PayloadUpgradeError, in place of the unmarshaling error.
+// UpdateMessageData stamps version as well, since the payload it writes is of the current type.
+func WithPayloadUpgrader[T any](version int, upgrader PayloadUpgrader[T]) func(*ClientOptions) {
+	return func(s *ClientOptions) {
+		s.PayloadUpgrader = &payloadUpgrade[T]{version: version, upgrader: upgrader}
+	}
+}
+
+func payloadUpgradeOf[T any](upgrade any) (*payloadUpgrade[T], error) {
+	if upgrade == nil {
+		return nil, nil
+	}
+	u, ok := upgrade.(*payloadUpgrade[T])
+	if !ok {
+		return nil, InvalidPayloadUpgraderError{Upgrader: typeName(upgrade), MessageType: typeNameOf[T]()}
+	}
+	return u, nil
+}
+
+// payloadSchemaVersionOf returns the PayloadSchemaVersion of an item, or zero if it has none.
+func payloadSchemaVersionOf(item map[string]types.AttributeValue) int {
+	n, ok := item[AttributeNamePayloadSchemaVersion].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0
+	}
+	version, err := strconv.Atoi(n.Value)
+	if err != nil {
+		return 0
+	}
+	return version
+}
+
+// upgradePayload unmarshals an item whose payload was written with an older version of its schema into out,
+// converting the payload with the upgrader. It reports false, without touching out, for the items of the current
+// version and the items read without their payload, which are unmarshaled as usual.
+func (c *ClientImpl[T]) upgradePayload(item map[string]types.AttributeValue, out *Message[T]) (bool, error) {
+	if c.payloadUpgrade == nil {
+		return false, nil
+	}
+	if _, ok := item[AttributeNameData]; !ok {
+		return false, nil
+	}
+	version := payloadSchemaVersionOf(item)
+	if version >= c.payloadUpgrade.version {
+		return false, nil
+	}
+	data, err := c.payloadUpgrade.upgrader(version, item)
+	if err != nil {
+		id, _ := item[AttributeNameID].(*types.AttributeValueMemberS)
+		upgradeErr := PayloadUpgradeError{Version: version, Cause: err}
+		if id != nil {
+			upgradeErr.ID = id.Value
+		}
+		return true, upgradeErr
+	}
+	rest := make(map[string]types.AttributeValue, len(item)-1)
+	for k, v := range item {
+		if k != AttributeNameData {
+			rest[k] = v
+		}
+	}
+	if err := c.unmarshalMap(rest, out); err != nil {
+		return true, err
+	}
+	out.Data = data
+	out.PayloadSchemaVersion = c.payloadUpgrade.version
+	return true, nil
+}
+
+// setPayloadSchemaVersion adds the version of the payload upgrader to an update expression writing a payload,
+// so that the payload is not upgraded again when it is read.
+func (c *ClientImpl[T]) setPayloadSchemaVersion(update expression.UpdateBuilder) expression.UpdateBuilder {
+	if c.payloadUpgrade == nil {
+		return update
+	}
+	return update.Set(expression.Name(c.schema.PayloadSchemaVersionAttribute), expression.Value(c.payloadUpgrade.version))
+}
@@ -0,0 +1,238 @@
+package dynamomq_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+// orderV2 is the second version of the payload of an order, which moved from a float amount under "amount"
+// to an amount in cents under "amount_cents", and renamed "id" to "order_id".
+type orderV2 struct {
+	OrderID     string `dynamodbav:"order_id"`
+	AmountCents int    `dynamodbav:"amount_cents"`
+}
+
+// newOrderV1Item returns an order stored by a producer of the first version of the payload.
+func newOrderV1Item() map[string]types.AttributeValue {
+	item := dynamomqtest.MarshalMap(NewTestMessageItemAsReady("A-101", test.DefaultTestDate))
+	item[dynamomq.AttributeNameData] = &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		"id":     &types.AttributeValueMemberS{Value: "order-1"},
+		"amount": &types.AttributeValueMemberS{Value: "12.34"},
+	}}
+	item[dynamomq.AttributeNamePayloadSchemaVersion] = &types.AttributeValueMemberN{Value: "1"}
+	return item
+}
+
+func upgradeOrder(upgrades *atomic.Int32) dynamomq.PayloadUpgrader[orderV2] {
+	return func(version int, raw map[string]types.AttributeValue) (orderV2, error) {
+		upgrades.Add(1)
+		data, ok := raw[dynamomq.AttributeNameData].(*types.AttributeValueMemberM)
+		if !ok || version != 1 {
+			return orderV2{}, errors.New("unknown order")
+		}
+		id, _ := data.Value["id"].(*types.AttributeValueMemberS)
+		amount, _ := data.Value["amount"].(*types.AttributeValueMemberS)
+		if id == nil || amount == nil {
+			return orderV2{}, errors.New("incomplete order")
+		}
+		f, err := strconv.ParseFloat(amount.Value, 64)
+		if err != nil {
+			return orderV2{}, err
+		}
+		return orderV2{OrderID: id.Value, AmountCents: int(f*100 + 0.5)}, nil
+	}
+}
+
+// newItemDynamoDB returns a table whose GetItem returns item.
+func newItemDynamoDB(item map[string]types.AttributeValue) *mock.DynamoDB {
+	return &mock.DynamoDB{
+		GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+			return &dynamodb.GetItemOutput{Item: item}, nil
+		},
+	}
+}
+
+func TestDynamoMQClientPayloadUpgrader(t *testing.T) {
+	t.Parallel()
+	var upgrades atomic.Int32
+	client := newTestClient[orderV2](t, newItemDynamoDB(newOrderV1Item()), dynamomq.WithPayloadUpgrader(2, upgradeOrder(&upgrades)))
+	got, err := client.GetMessage(context.Background(), &dynamomq.GetMessageInput{ID: "A-101"})
+	if err != nil {
+		t.Fatalf("GetMessage() error = %v", err)
+	}
+	test.AssertDeepEqual(t, got.Message.Data, orderV2{OrderID: "order-1", AmountCents: 1234}, "GetMessage() data")
+	if got.Message.ID != "A-101" || got.Message.PayloadSchemaVersion != 2 {
+		t.Errorf("GetMessage() = %+v, want message A-101 with payload schema version 2", got.Message)
+	}
+	if n := upgrades.Load(); n != 1 {
+		t.Errorf("upgrades = %d, want 1", n)
+	}
+}
+
+func TestDynamoMQClientPayloadUpgraderSkipsCurrentVersion(t *testing.T) {
+	t.Parallel()
+	message := dynamomq.NewMessage("A-101", orderV2{OrderID: "order-1", AmountCents: 1234}, test.DefaultTestDate)
+	message.PayloadSchemaVersion = 2
+	var upgrades atomic.Int32
+	client := newTestClient[orderV2](t, newItemDynamoDB(dynamomqtest.MarshalMap(message)),
+		dynamomq.WithPayloadUpgrader(2, upgradeOrder(&upgrades)))
+	got, err := client.GetMessage(context.Background(), &dynamomq.GetMessageInput{ID: "A-101"})
+	if err != nil {
+		t.Fatalf("GetMessage() error = %v", err)
+	}
+	test.AssertDeepEqual(t, got.Message, message, "GetMessage()")
+	if n := upgrades.Load(); n != 0 {
+		t.Errorf("upgrades = %d, want 0", n)
+	}
+}
+
+func TestDynamoMQClientPayloadUpgraderError(t *testing.T) {
+	t.Parallel()
+	item := newOrderV1Item()
+	item[dynamomq.AttributeNameData] = &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{}}
+	var upgrades atomic.Int32
+	client := newTestClient[orderV2](t, newItemDynamoDB(item), dynamomq.WithPayloadUpgrader(2, upgradeOrder(&upgrades)))
+	_, err := client.GetMessage(context.Background(), &dynamomq.GetMessageInput{ID: "A-101"})
+	var upgradeErr dynamomq.PayloadUpgradeError
+	if !errors.As(err, &upgradeErr) || upgradeErr.ID != "A-101" || upgradeErr.Version != 1 {
+		t.Errorf("GetMessage() error = %v, want a PayloadUpgradeError of message A-101 from version 1", err)
+	}
+}
+
+func TestDynamoMQClientPayloadUpgraderOnTable(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tableName, raw, clean := SetupDynamoDB(t, &types.PutRequest{Item: newOrderV1Item()})
+	defer clean()
+	var upgrades atomic.Int32
+	client := newTestClient[orderV2](t, raw, dynamomq.WithTableName(tableName),
+		dynamomq.WithPayloadUpgrader(2, upgradeOrder(&upgrades)))
+	got, err := client.GetMessage(ctx, &dynamomq.GetMessageInput{ID: "A-101"})
+	if err != nil {
+		t.Fatalf("GetMessage() error = %v", err)
+	}
+	test.AssertDeepEqual(t, got.Message.Data, orderV2{OrderID: "order-1", AmountCents: 1234}, "GetMessage() data")
+	// The payload rewritten in the current type is stamped with the current version, so it is not upgraded again.
+	if _, err = client.UpdateMessageData(ctx, &dynamomq.UpdateMessageDataInput[orderV2]{
+		ID:   "A-101",
+		Data: orderV2{OrderID: "order-1", AmountCents: 1500},
+	}); err != nil {
+		t.Fatalf("UpdateMessageData() error = %v", err)
+	}
+	got, err = client.GetMessage(ctx, &dynamomq.GetMessageInput{ID: "A-101"})
+	if err != nil {
+		t.Fatalf("GetMessage() error = %v", err)
+	}
+	test.AssertDeepEqual(t, got.Message.Data, orderV2{OrderID: "order-1", AmountCents: 1500}, "GetMessage() data")
+	if n := upgrades.Load(); n != 1 {
+		t.Errorf("upgrades = %d, want 1", n)
+	}
+	if _, err = client.SendMessage(ctx, &dynamomq.SendMessageInput[orderV2]{
+		ID:   "A-102",
+		Data: orderV2{OrderID: "order-2", AmountCents: 100},
+	}); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	for _, id := range []string{"A-101", "A-102"} {
+		item, err := raw.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName: aws.String(tableName),
+			Key:       map[string]types.AttributeValue{dynamomq.AttributeNameID: &types.AttributeValueMemberS{Value: id}},
+		})
+		if err != nil {
+			t.Fatalf("GetItem() error = %v", err)
+		}
+		test.AssertDeepEqual(t, item.Item[dynamomq.AttributeNamePayloadSchemaVersion],
+			types.AttributeValue(&types.AttributeValueMemberN{Value: "2"}), "payload schema version of "+id)
+	}
+}
+
+func TestDynamoMQClientUpdateMessageDataStampsPayloadSchemaVersion(t *testing.T) {
+	t.Parallel()
+	var update *dynamodb.UpdateItemInput
+	client := newTestClient[orderV2](t, &mock.DynamoDB{
+		UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+			update = params
+			return &dynamodb.UpdateItemOutput{Attributes: newOrderV1Item()}, nil
+		},
+	}, dynamomq.WithPayloadUpgrader(2, upgradeOrder(&atomic.Int32{})))
+	if _, err := client.UpdateMessageData(context.Background(), &dynamomq.UpdateMessageDataInput[orderV2]{
+		ID:   "A-101",
+		Data: orderV2{OrderID: "order-1", AmountCents: 1500},
+	}); err != nil {
+		t.Fatalf("UpdateMessageData() error = %v", err)
+	}
+	if !containsValue(update.ExpressionAttributeNames, dynamomq.AttributeNamePayloadSchemaVersion) {
+		t.Errorf("UpdateMessageData() names = %v, want %s", update.ExpressionAttributeNames, dynamomq.AttributeNamePayloadSchemaVersion)
+	}
+	var stamped bool
+	for _, v := range update.ExpressionAttributeValues {
+		if n, ok := v.(*types.AttributeValueMemberN); ok && n.Value == "2" {
+			stamped = true
+		}
+	}
+	if !stamped {
+		t.Errorf("UpdateMessageData() values = %v, want the version 2", update.ExpressionAttributeValues)
+	}
+}
+
+func TestDynamoMQClientSendMessageStampsPayloadSchemaVersion(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		version int
+		want    int
+	}{
+		{
+			name: "should stamp the version of the upgrader",
+			want: 2,
+		},
+		{
+			name:    "should stamp the version of the input",
+			version: 3,
+			want:    3,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var put *dynamodb.PutItemInput
+			client := newTestClient[orderV2](t, &mock.DynamoDB{
+				GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+					return &dynamodb.GetItemOutput{}, nil
+				},
+				PutItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+					put = params
+					return &dynamodb.PutItemOutput{}, nil
+				},
+			}, dynamomq.WithPayloadUpgrader(2, upgradeOrder(&atomic.Int32{})))
+			producer := dynamomq.NewProducer[orderV2](client, dynamomq.WithPayloadSchemaVersion(tt.version))
+			if _, err := producer.Produce(context.Background(), &dynamomq.ProduceInput[orderV2]{ID: "A-101"}); err != nil {
+				t.Fatalf("Produce() error = %v", err)
+			}
+			test.AssertDeepEqual(t, put.Item[dynamomq.AttributeNamePayloadSchemaVersion],
+				types.AttributeValue(&types.AttributeValueMemberN{Value: strconv.Itoa(tt.want)}), "payload schema version")
+		})
+	}
+}
+
+func TestNewFromConfigWithPayloadUpgraderOfAnotherType(t *testing.T) {
+	t.Parallel()
+	_, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithPayloadUpgrader(2, upgradeOrder(&atomic.Int32{})))
+	var upgraderErr dynamomq.InvalidPayloadUpgraderError
+	if !errors.As(err, &upgraderErr) {
+		t.Errorf("NewFromConfig() error = %v, want an InvalidPayloadUpgraderError", err)
+	}
+}
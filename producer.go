@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/vvatanabe/dynamomq/retry"
 )
 
 // ProducerOptions holds configuration options for a Producer.
@@ -11,6 +12,15 @@ type ProducerOptions struct {
 	// IDGenerator is function that generates a unique identifier for each message produced by the Producer.
 	// The default ID generator is uuid.NewString.
 	IDGenerator func() string
+	// RetryPolicy retries the sending of a message failed with a retryable error, such as a throttled request.
+	// If it is nil, the message is sent once.
+	RetryPolicy *retry.Policy
+	// PayloadSchemaVersion is the version of the schema of the payloads produced by the Producer, stored on
+	// the messages. If it is zero, the version set on the client with WithPayloadUpgrader is used, if any.
+	PayloadSchemaVersion int
+	// Validator is the Validator[T] set with WithProducerValidator, which checks the payload of every message
+	// produced by the Producer.
+	Validator any
 }
 
 // WithIDGenerator is an option function to set a custom ID generator for the Producer.
@@ -22,6 +32,23 @@ func WithIDGenerator(idGenerator func() string) func(o *ProducerOptions) {
 	}
 }
 
+// WithProducerRetryPolicy is an option function to retry the sending of messages failed with a retryable error,
+// waiting between the attempts as the policy describes.
+func WithProducerRetryPolicy(policy *retry.Policy) func(o *ProducerOptions) {
+	return func(o *ProducerOptions) {
+		o.RetryPolicy = policy
+	}
+}
+
+// WithPayloadSchemaVersion is an option function to stamp the messages produced by the Producer with the version
+// of the schema of their payload, so that the consumers reading them with WithPayloadUpgrader can tell
+// the payloads written with an older version apart.
+func WithPayloadSchemaVersion(version int) func(o *ProducerOptions) {
+	return func(o *ProducerOptions) {
+		o.PayloadSchemaVersion = version
+	}
+}
+
 // NewProducer creates a new instance of a Producer, which is used to produce messages to a DynamoDB-based queue.
 // The Producer can be configured with various options, such as a custom ID generator.
 func NewProducer[T any](client Client[T], opts ...func(o *ProducerOptions)) *Producer[T] {
@@ -31,24 +58,42 @@ func NewProducer[T any](client Client[T], opts ...func(o *ProducerOptions)) *Pro
 	for _, opt := range opts {
 		opt(o)
 	}
+	validator, validatorErr := validatorOf[T](o.Validator)
 	return &Producer[T]{
-		client:      client,
-		idGenerator: o.IDGenerator,
+		client:               client,
+		validator:            validator,
+		validatorErr:         validatorErr,
+		idGenerator:          o.IDGenerator,
+		retryPolicy:          o.RetryPolicy,
+		payloadSchemaVersion: o.PayloadSchemaVersion,
 	}
 }
 
 // Producer is a generic struct responsible for producing messages of any type T to a DynamoDB-based queue.
 type Producer[T any] struct {
-	client      Client[T]
-	idGenerator func() string
+	client               Client[T]
+	idGenerator          func() string
+	retryPolicy          *retry.Policy
+	payloadSchemaVersion int
+	validator            Validator[T]
+	validatorErr         error
 }
 
 // ProduceInput represents the input parameters for producing a message.
 type ProduceInput[T any] struct {
+	// ID is the unique identifier of the message. If it is empty, the Producer's ID generator is used.
+	ID string
 	// Data is the content of the message to be produced. The type T allows for flexibility in the data type of the message payload.
 	Data T
 	// DelaySeconds is the delay time (in seconds) before the message is sent to the queue.
 	DelaySeconds int
+	// CorrelationID is the correlation ID stored on the message.
+	// If it is empty, the correlation ID carried by the context of Produce is used, if any.
+	CorrelationID string
+	// TenantID is the tenant the message belongs to, used by clients configured with WithTenantFairness.
+	TenantID string
+	// Tags are the tags stored on the message.
+	Tags []string
 }
 
 // ProduceOutput represents the result of the produce operation.
@@ -58,17 +103,47 @@ type ProduceOutput[T any] struct {
 }
 
 // Produce sends a message to the queue using the provided input parameters.
-// It generates a unique ID for the message using the Producer's ID generator and delegates to the Client's SendMessage method.
-// An error is returned if the SendMessage operation fails.
+// Unless an ID is given, it generates a unique ID for the message using the Producer's ID generator and delegates to the Client's SendMessage method.
+// An error is returned if the SendMessage operation fails, after the retries of the Producer's retry policy, if any.
+// A message rejected by the validator set with WithProducerValidator is not sent, and a ValidationFailedError is returned.
 func (c *Producer[T]) Produce(ctx context.Context, params *ProduceInput[T]) (*ProduceOutput[T], error) {
 	if params == nil {
 		params = &ProduceInput[T]{}
 	}
-	out, err := c.client.SendMessage(ctx, &SendMessageInput[T]{
-		ID:           c.idGenerator(),
-		Data:         params.Data,
-		DelaySeconds: params.DelaySeconds,
-	})
+	if c.validatorErr != nil {
+		return &ProduceOutput[T]{}, c.validatorErr
+	}
+	id := params.ID
+	if id == "" {
+		id = c.idGenerator()
+	}
+	if err := c.validator.validate(ctx, "Produce", id, params.Data); err != nil {
+		return &ProduceOutput[T]{}, err
+	}
+	correlationID := params.CorrelationID
+	if correlationID == "" {
+		correlationID, _ = CorrelationIDFromContext(ctx)
+	}
+	input := &SendMessageInput[T]{
+		ID:                   id,
+		Data:                 params.Data,
+		DelaySeconds:         params.DelaySeconds,
+		CorrelationID:        correlationID,
+		TenantID:             params.TenantID,
+		Tags:                 params.Tags,
+		PayloadSchemaVersion: c.payloadSchemaVersion,
+	}
+	var out *SendMessageOutput[T]
+	send := func(ctx context.Context) (err error) {
+		out, err = c.client.SendMessage(ctx, input)
+		return err
+	}
+	var err error
+	if c.retryPolicy != nil {
+		err = c.retryPolicy.Do(ctx, send)
+	} else {
+		err = send(ctx)
+	}
 	if err != nil {
 		return &ProduceOutput[T]{}, err
 	}
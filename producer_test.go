@@ -5,10 +5,12 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/vvatanabe/dynamomq"
 	"github.com/vvatanabe/dynamomq/internal/mock"
 	"github.com/vvatanabe/dynamomq/internal/test"
+	"github.com/vvatanabe/dynamomq/retry"
 )
 
 func TestProducerProduce(t *testing.T) {
@@ -54,6 +56,23 @@ func TestProducerProduce(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "should use the given ID instead of generating one",
+			c:    defaultTestProducer,
+			args: args[test.MessageData]{
+				params: &dynamomq.ProduceInput[test.MessageData]{
+					ID:   "B-202",
+					Data: test.NewMessageData("B-202"),
+				},
+			},
+			want: &dynamomq.ProduceOutput[test.MessageData]{
+				Message: &dynamomq.Message[test.MessageData]{
+					ID:   "B-202",
+					Data: test.NewMessageData("B-202"),
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name: "should success to produce a message when params is nil",
 			c:    defaultTestProducer,
@@ -95,3 +114,34 @@ func TestProducerProduce(t *testing.T) {
 		})
 	}
 }
+
+func TestProducerProduceShouldRetryWithRetryPolicy(t *testing.T) {
+	t.Parallel()
+	calls := 0
+	client := &mock.Client[test.MessageData]{
+		SendMessageFunc: func(ctx context.Context,
+			params *dynamomq.SendMessageInput[test.MessageData]) (*dynamomq.SendMessageOutput[test.MessageData], error) {
+			calls++
+			if calls < 3 {
+				return nil, dynamomq.ThrottledError{Cause: test.ErrTest}
+			}
+			return &dynamomq.SendMessageOutput[test.MessageData]{
+				SentMessage: &dynamomq.Message[test.MessageData]{ID: params.ID},
+			}, nil
+		},
+	}
+	producer := dynamomq.NewProducer[test.MessageData](client, dynamomq.WithProducerRetryPolicy(&retry.Policy{
+		InitialInterval: time.Millisecond,
+		MaxAttempts:     3,
+	}))
+	got, err := producer.Produce(context.Background(), &dynamomq.ProduceInput[test.MessageData]{ID: "A-101"})
+	if err != nil {
+		t.Fatalf("Produce() error = %v", err)
+	}
+	if got.Message.ID != "A-101" {
+		t.Errorf("Produce() ID = %v, want A-101", got.Message.ID)
+	}
+	if calls != 3 {
+		t.Errorf("SendMessage() calls = %d, want 3", calls)
+	}
+}
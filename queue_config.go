@@ -0,0 +1,134 @@
+package dynamomq
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+)
+
+const (
+	// AttributeNameConfigVisibilityTimeout holds the visibility timeout in seconds in the configuration item.
+	AttributeNameConfigVisibilityTimeout = "config_visibility_timeout"
+	// AttributeNameConfigMaximumReceives holds the maximum number of receives in the configuration item.
+	AttributeNameConfigMaximumReceives = "config_maximum_receives"
+	// AttributeNameConfigRetention holds the retention in seconds in the configuration item.
+	AttributeNameConfigRetention = "config_retention"
+
+	configItemID                      = "dynamomq-config#queue"
+	defaultQueueConfigRefreshInterval = time.Minute
+)
+
+// QueueConfig holds the defaults shared by every service using a queue. It is stored in a configuration item
+// of the queue table with SaveQueueConfig. A zero field is unset, and the library default applies.
+// An option set explicitly on a client, a Consumer or a Sweeper always takes precedence over the stored value.
+type QueueConfig struct {
+	// VisibilityTimeout is the visibility timeout in seconds used by ReceiveMessage when the input does not set one.
+	VisibilityTimeout int
+	// MaximumReceives is the maximum number of receives used by a Consumer created without WithMaximumReceives.
+	MaximumReceives int
+	// Retention is the retention used by a Sweeper created without WithSweeperRetention.
+	Retention time.Duration
+}
+
+// SaveQueueConfigInput represents the input parameters for saving the configuration of a queue.
+type SaveQueueConfigInput struct {
+	// Config is the configuration to store. It replaces the stored configuration as a whole.
+	Config QueueConfig
+}
+
+// SaveQueueConfigOutput represents the result of the operation to save the configuration of a queue.
+// This struct is empty as the operation does not return any specific information.
+type SaveQueueConfigOutput struct{}
+
+// SaveQueueConfig stores the configuration of the queue in a configuration item of the queue table.
+// Clients created with WithStoredQueueConfig use it from their next refresh.
+func (c *ClientImpl[T]) SaveQueueConfig(ctx context.Context, params *SaveQueueConfigInput) (*SaveQueueConfigOutput, error) {
+	if params == nil {
+		params = &SaveQueueConfigInput{}
+	}
+	now := c.clock.Now()
+	item := c.itemKey(configItemID)
+	item[c.schema.IDAttribute] = &types.AttributeValueMemberS{Value: configItemID}
+	item[c.schema.UpdatedAtAttribute] = &types.AttributeValueMemberS{Value: clock.FormatRFC3339Nano(now)}
+	for name, value := range map[string]int64{
+		AttributeNameConfigVisibilityTimeout: int64(params.Config.VisibilityTimeout),
+		AttributeNameConfigMaximumReceives:   int64(params.Config.MaximumReceives),
+		AttributeNameConfigRetention:         int64(params.Config.Retention / time.Second),
+	} {
+		if value > 0 {
+			item[name] = &types.AttributeValueMemberN{Value: strconv.FormatInt(value, 10)}
+		}
+	}
+	_, err := c.dynamoDB.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(c.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		return &SaveQueueConfigOutput{}, handleDynamoDBError(err)
+	}
+	if c.useQueueConfig {
+		c.configMu.Lock()
+		c.queueConfig = params.Config
+		c.queueConfigRefreshedAt = now
+		c.configMu.Unlock()
+	}
+	return &SaveQueueConfigOutput{}, nil
+}
+
+// GetQueueConfigInput represents the input parameters for getting the configuration of a queue.
+type GetQueueConfigInput struct{}
+
+// GetQueueConfigOutput represents the result of the operation to get the configuration of a queue.
+type GetQueueConfigOutput struct {
+	// Config is the stored configuration. Its fields are zero when they are not stored.
+	Config QueueConfig
+}
+
+// GetQueueConfig returns the configuration stored with SaveQueueConfig, as cached by the client.
+// The configuration item is read when the client is created and again once the refresh interval has passed.
+// For a client created without WithStoredQueueConfig, it returns an empty QueueConfig without reading the table.
+func (c *ClientImpl[T]) GetQueueConfig(ctx context.Context, _ *GetQueueConfigInput) (*GetQueueConfigOutput, error) {
+	if !c.useQueueConfig {
+		return &GetQueueConfigOutput{}, nil
+	}
+	c.configMu.Lock()
+	defer c.configMu.Unlock()
+	now := c.clock.Now()
+	if c.queueConfigRefreshedAt.IsZero() || now.Sub(c.queueConfigRefreshedAt) >= c.queueConfigRefreshInterval {
+		config, err := c.readQueueConfig(ctx)
+		if err != nil {
+			return &GetQueueConfigOutput{}, err
+		}
+		c.queueConfig = config
+		c.queueConfigRefreshedAt = now
+	}
+	return &GetQueueConfigOutput{Config: c.queueConfig}, nil
+}
+
+func (c *ClientImpl[T]) readQueueConfig(ctx context.Context) (QueueConfig, error) {
+	out, err := c.dynamoDB.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(c.tableName),
+		Key:            c.itemKey(configItemID),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return QueueConfig{}, handleDynamoDBError(err)
+	}
+	number := func(name string) int64 {
+		if v, ok := out.Item[name].(*types.AttributeValueMemberN); ok {
+			n, _ := strconv.ParseInt(v.Value, 10, 64)
+			return n
+		}
+		return 0
+	}
+	return QueueConfig{
+		VisibilityTimeout: int(number(AttributeNameConfigVisibilityTimeout)),
+		MaximumReceives:   int(number(AttributeNameConfigMaximumReceives)),
+		Retention:         time.Duration(number(AttributeNameConfigRetention)) * time.Second,
+	}, nil
+}
@@ -0,0 +1,245 @@
+package dynamomq_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func newQueueConfigItem(visibilityTimeout, maximumReceives string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		dynamomq.AttributeNameConfigVisibilityTimeout: &types.AttributeValueMemberN{Value: visibilityTimeout},
+		dynamomq.AttributeNameConfigMaximumReceives:   &types.AttributeValueMemberN{Value: maximumReceives},
+	}
+}
+
+func TestDynamoMQClientReceiveMessageVisibilityTimeoutPrecedence(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name              string
+		useStoredConfig   bool
+		visibilityTimeout int
+		want              time.Duration
+	}{
+		{
+			name:              "explicit visibility timeout takes precedence over the stored config",
+			useStoredConfig:   true,
+			visibilityTimeout: 10,
+			want:              10 * time.Second,
+		},
+		{
+			name:            "stored config takes precedence over the library default",
+			useStoredConfig: true,
+			want:            60 * time.Second,
+		},
+		{
+			name: "library default applies without the stored config",
+			want: 30 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			item, err := marshalMap(dynamomq.NewMessage("A-101", test.NewMessageData("A-101"), test.DefaultTestDate))
+			if err != nil {
+				t.Fatalf("marshalMap() error = %v", err)
+			}
+			var updated *dynamodb.UpdateItemInput
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+				dynamomq.WithStoredQueueConfig(tt.useStoredConfig),
+				mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+						return &dynamodb.GetItemOutput{Item: newQueueConfigItem("60", "3")}, nil
+					},
+					QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+						return &dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{item}}, nil
+					},
+					UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+						updated = params
+						return &dynamodb.UpdateItemOutput{Attributes: item}, nil
+					},
+				}))
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			_, err = client.ReceiveMessage(context.Background(), &dynamomq.ReceiveMessageInput{
+				VisibilityTimeout: tt.visibilityTimeout,
+			})
+			if err != nil {
+				t.Fatalf("ReceiveMessage() error = %v", err)
+			}
+			want := &types.AttributeValueMemberS{Value: clock.FormatRFC3339Nano(test.DefaultTestDate.Add(tt.want))}
+			if !containsAttributeValue(updated.ExpressionAttributeValues, want) {
+				t.Errorf("UpdateItem() values = %v, want invisible until %v", updated.ExpressionAttributeValues, want.Value)
+			}
+		})
+	}
+}
+
+func containsAttributeValue(values map[string]types.AttributeValue, want *types.AttributeValueMemberS) bool {
+	for _, v := range values {
+		if s, ok := v.(*types.AttributeValueMemberS); ok && s.Value == want.Value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDynamoMQClientGetQueueConfigRefresh(t *testing.T) {
+	t.Parallel()
+	reads := 0
+	visibilityTimeout := "60"
+	clk := &steppingClock{now: test.DefaultTestDate}
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithStoredQueueConfig(true),
+		dynamomq.WithQueueConfigRefreshInterval(time.Minute),
+		mock.WithClock(clk),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+				reads++
+				return &dynamodb.GetItemOutput{Item: newQueueConfigItem(visibilityTimeout, "3")}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	if reads != 1 {
+		t.Errorf("NewFromConfig() reads = %d, want 1", reads)
+	}
+	visibilityTimeout = "90"
+	clk.Advance(30 * time.Second)
+	got, err := client.GetQueueConfig(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("GetQueueConfig() error = %v", err)
+	}
+	test.AssertDeepEqual(t, got.Config, dynamomq.QueueConfig{VisibilityTimeout: 60, MaximumReceives: 3}, "GetQueueConfig() before refresh")
+	clk.Advance(30 * time.Second)
+	got, err = client.GetQueueConfig(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("GetQueueConfig() error = %v", err)
+	}
+	test.AssertDeepEqual(t, got.Config, dynamomq.QueueConfig{VisibilityTimeout: 90, MaximumReceives: 3}, "GetQueueConfig() after refresh")
+}
+
+func TestDynamoMQClientSaveQueueConfig(t *testing.T) {
+	t.Parallel()
+	var put *dynamodb.PutItemInput
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			PutItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+				put = params
+				return &dynamodb.PutItemOutput{}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	_, err = client.SaveQueueConfig(context.Background(), &dynamomq.SaveQueueConfigInput{
+		Config: dynamomq.QueueConfig{MaximumReceives: 5, Retention: 48 * time.Hour},
+	})
+	if err != nil {
+		t.Fatalf("SaveQueueConfig() error = %v", err)
+	}
+	test.AssertDeepEqual(t, put.Item[dynamomq.AttributeNameConfigMaximumReceives], &types.AttributeValueMemberN{Value: "5"}, "maximum receives")
+	test.AssertDeepEqual(t, put.Item[dynamomq.AttributeNameConfigRetention], &types.AttributeValueMemberN{Value: "172800"}, "retention")
+	if _, ok := put.Item[dynamomq.AttributeNameConfigVisibilityTimeout]; ok {
+		t.Errorf("SaveQueueConfig() stored an unset visibility timeout: %v", put.Item)
+	}
+}
+
+func TestConsumerMaximumReceivesPrecedence(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name            string
+		maximumReceives int
+		stored          int
+		wantMoved       bool
+	}{
+		{name: "explicit option takes precedence over the stored config", maximumReceives: 5, stored: 2, wantMoved: false},
+		{name: "stored config takes precedence over the library default", stored: 2, wantMoved: true},
+		{name: "library default retries without limit", wantMoved: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			msg := dynamomq.NewMessage("A-101", test.NewMessageData("A-101"), test.DefaultTestDate)
+			msg.ReceiveCount = 2
+			settled := make(chan bool, 1)
+			client := &mock.Client[test.MessageData]{
+				ReceiveMessageFunc: func(ctx context.Context, params *dynamomq.ReceiveMessageInput) (*dynamomq.ReceiveMessageOutput[test.MessageData], error) {
+					if msg == nil {
+						return nil, &dynamomq.EmptyQueueError{}
+					}
+					received := msg
+					msg = nil
+					return &dynamomq.ReceiveMessageOutput[test.MessageData]{ReceivedMessage: received}, nil
+				},
+				GetQueueConfigFunc: func(ctx context.Context, params *dynamomq.GetQueueConfigInput) (*dynamomq.GetQueueConfigOutput, error) {
+					return &dynamomq.GetQueueConfigOutput{Config: dynamomq.QueueConfig{MaximumReceives: tt.stored}}, nil
+				},
+				ChangeMessageVisibilityFunc: func(ctx context.Context, params *dynamomq.ChangeMessageVisibilityInput) (*dynamomq.ChangeMessageVisibilityOutput[test.MessageData], error) {
+					settled <- false
+					return &dynamomq.ChangeMessageVisibilityOutput[test.MessageData]{}, nil
+				},
+				MoveMessageToDLQFunc: func(ctx context.Context, params *dynamomq.MoveMessageToDLQInput) (*dynamomq.MoveMessageToDLQOutput[test.MessageData], error) {
+					settled <- true
+					return &dynamomq.MoveMessageToDLQOutput[test.MessageData]{}, nil
+				},
+			}
+			consumer := dynamomq.NewConsumer[test.MessageData](client,
+				&CountProcessor[test.MessageData]{SimulateProcessError: true},
+				dynamomq.WithPollingInterval(time.Millisecond),
+				dynamomq.WithMaximumReceives(tt.maximumReceives))
+			go func() {
+				_ = consumer.StartConsuming()
+			}()
+			if moved := <-settled; moved != tt.wantMoved {
+				t.Errorf("moved to DLQ = %v, want %v", moved, tt.wantMoved)
+			}
+			_ = consumer.Shutdown(context.Background())
+		})
+	}
+}
+
+func TestSweeperUsesStoredRetention(t *testing.T) {
+	t.Parallel()
+	retentions := make(chan time.Duration, 2)
+	client := &mock.Client[test.MessageData]{
+		AcquireLockFunc: func(ctx context.Context, params *dynamomq.AcquireLockInput) (*dynamomq.AcquireLockOutput, error) {
+			return &dynamomq.AcquireLockOutput{Acquired: true}, nil
+		},
+		GetQueueConfigFunc: func(ctx context.Context, params *dynamomq.GetQueueConfigInput) (*dynamomq.GetQueueConfigOutput, error) {
+			return &dynamomq.GetQueueConfigOutput{Config: dynamomq.QueueConfig{Retention: 48 * time.Hour}}, nil
+		},
+		DeleteExpiredMessagesFunc: func(ctx context.Context, params *dynamomq.DeleteExpiredMessagesInput) (*dynamomq.DeleteExpiredMessagesOutput, error) {
+			select {
+			case retentions <- params.Retention:
+			default:
+			}
+			return &dynamomq.DeleteExpiredMessagesOutput{}, nil
+		},
+		ReleaseLockFunc: func(ctx context.Context, params *dynamomq.ReleaseLockInput) (*dynamomq.ReleaseLockOutput, error) {
+			return &dynamomq.ReleaseLockOutput{}, nil
+		},
+	}
+	sweeper := dynamomq.NewSweeper[test.MessageData](client, dynamomq.WithSweeperInterval(time.Hour))
+	go func() {
+		_ = sweeper.Start(context.Background())
+	}()
+	if got := <-retentions; got != 48*time.Hour {
+		t.Errorf("DeleteExpiredMessages() retention = %v, want %v", got, 48*time.Hour)
+	}
+	sweeper.Stop()
+}
@@ -0,0 +1,98 @@
+package dynamomq
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vvatanabe/dynamomq/internal/constant"
+)
+
+// ReceiveMessageBatchInput represents the input parameters for receiving several messages in one call.
+type ReceiveMessageBatchInput struct {
+	// QueueType is the type of queue from which the messages are to be retrieved. If it is not set, STANDARD is used.
+	QueueType QueueType
+	// VisibilityTimeout is the timeout in seconds during which the messages become invisible to other receivers.
+	// If it is not set, the VisibilityTimeout of the stored QueueConfig is used, and then the default of 30 seconds.
+	VisibilityTimeout int
+	// MaxNumberOfMessages is the maximum number of messages to receive. If it is not set, a single message is received.
+	MaxNumberOfMessages int
+}
+
+// ReceiveMessageBatchOutput represents the result of the operation to receive several messages.
+type ReceiveMessageBatchOutput[T any] struct {
+	// ReceivedMessages are the messages received, in the order they were selected.
+	ReceivedMessages []*Message[T]
+}
+
+// ReceiveMessageBatch receives up to MaxNumberOfMessages messages in one call. Each message is selected and marked
+// as being processed as ReceiveMessage does, passing over the messages already received in the batch, so that
+// a message received by another client between its selection and its update is skipped instead of failing the batch.
+// Only the first message of a FIFO queue can be received, so a batch from a FIFO queue holds a single message.
+// The batch ends when the queue is empty or the limit set with WithMaxInFlight is reached, and the error is only
+// returned if no message was received, or not at all for an empty queue with WithEmptyReceiveAsNil.
+// Any other error is returned with the messages received before it.
+func (c *ClientImpl[T]) ReceiveMessageBatch(ctx context.Context, params *ReceiveMessageBatchInput) (*ReceiveMessageBatchOutput[T], error) {
+	ctx, cancel := c.withDefaultTimeout(ctx, "ReceiveMessageBatch")
+	defer cancel()
+	if params == nil {
+		params = &ReceiveMessageBatchInput{}
+	}
+	if err := c.checkQueueEnabled(ctx); err != nil {
+		return &ReceiveMessageBatchOutput[T]{}, err
+	}
+	receiveParams := &ReceiveMessageInput{
+		QueueType:         params.QueueType,
+		VisibilityTimeout: params.VisibilityTimeout,
+	}
+	if receiveParams.QueueType == "" {
+		receiveParams.QueueType = QueueTypeStandard
+	}
+	if receiveParams.VisibilityTimeout <= 0 {
+		config, err := c.GetQueueConfig(ctx, nil)
+		if err != nil {
+			return &ReceiveMessageBatchOutput[T]{}, err
+		}
+		receiveParams.VisibilityTimeout = config.Config.VisibilityTimeout
+	}
+	if receiveParams.VisibilityTimeout <= 0 {
+		receiveParams.VisibilityTimeout = constant.DefaultVisibilityTimeoutInSeconds
+	}
+	maxMessages := params.MaxNumberOfMessages
+	if maxMessages <= 0 {
+		maxMessages = 1
+	}
+
+	out := &ReceiveMessageBatchOutput[T]{
+		ReceivedMessages: make([]*Message[T], 0, maxMessages),
+	}
+	// passed are the IDs of the messages received in the batch and of those lost to other clients,
+	// as the index may still return them as ready.
+	var passed []string
+	for len(out.ReceivedMessages) < maxMessages {
+		var received *Message[T]
+		selected, err := c.selectReceivable(ctx, receiveParams, passed)
+		if err == nil {
+			received, err = c.receiveSelected(ctx, selected)
+			var conflict *ConditionalCheckFailedError
+			if errors.As(err, &conflict) {
+				passed = append(passed, selected.ID)
+				continue
+			}
+		}
+		if err != nil {
+			var limitErr InFlightLimitExceededError
+			if len(out.ReceivedMessages) > 0 && (errors.Is(err, ErrEmptyQueue) || errors.As(err, &limitErr)) {
+				break
+			}
+			if c.emptyReceiveAsNil && len(out.ReceivedMessages) == 0 && errors.Is(err, ErrEmptyQueue) {
+				return out, nil
+			}
+			return out, err
+		}
+		passed = append(passed, received.ID)
+		out.ReceivedMessages = append(out.ReceivedMessages, received)
+		c.hooks.received(ctx, receiveParams, &ReceiveMessageOutput[T]{ReceivedMessage: received})
+		c.sampler.sample(ctx, received)
+	}
+	return out, nil
+}
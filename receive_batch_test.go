@@ -0,0 +1,137 @@
+package dynamomq_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestDynamoMQClientReceiveMessageBatch(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	tests := []struct {
+		name    string
+		opts    []func(*dynamomq.ClientOptions)
+		max     int
+		taken   []string
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "should receive up to the maximum number of messages",
+			max:  2,
+			want: []string{"A-101", "A-102"},
+		},
+		{
+			name: "should receive a single message by default",
+			want: []string{"A-101"},
+		},
+		{
+			name: "should return the messages received before the queue is empty",
+			max:  10,
+			want: []string{"A-101", "A-102", "A-103"},
+		},
+		{
+			name:  "should skip the messages received by other clients",
+			max:   2,
+			taken: []string{"A-101", "A-103"},
+			want:  []string{"A-102"},
+		},
+		{
+			name:    "should return an empty queue when every message is received by other clients",
+			max:     2,
+			taken:   []string{"A-101", "A-102", "A-103"},
+			want:    []string{},
+			wantErr: dynamomq.ErrEmptyQueue,
+		},
+		{
+			name: "should receive the head of a FIFO queue only",
+			opts: []func(*dynamomq.ClientOptions){dynamomq.WithUseFIFO(true)},
+			max:  3,
+			want: []string{"A-101"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// The index keeps returning every message as ready, as a stale read of it would, while the messages
+			// taken by other clients fail the update conditional on their version.
+			var items []map[string]types.AttributeValue
+			for _, id := range []string{"A-101", "A-102", "A-103"} {
+				items = append(items, dynamomqtest.MarshalMap(NewTestMessageItemAsReady(id, test.DefaultTestDate)))
+			}
+			opts := append([]func(*dynamomq.ClientOptions){
+				mock.WithClock(mock.Clock{T: now}),
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+						return &dynamodb.QueryOutput{Items: items}, nil
+					},
+					UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+						id := params.Key[dynamomq.AttributeNameID].(*types.AttributeValueMemberS).Value
+						for _, taken := range tt.taken {
+							if id == taken {
+								return nil, &types.ConditionalCheckFailedException{Message: aws.String("version changed")}
+							}
+						}
+						return &dynamodb.UpdateItemOutput{
+							Attributes: dynamomqtest.MarshalMap(NewTestMessageItemAsProcessing(id, now)),
+						}, nil
+					},
+				}),
+			}, tt.opts...)
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{}, opts...)
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			got, err := client.ReceiveMessageBatch(context.Background(), &dynamomq.ReceiveMessageBatchInput{
+				MaxNumberOfMessages: tt.max,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReceiveMessageBatch() error = %v, want %v", err, tt.wantErr)
+			}
+			ids := make([]string, 0)
+			for _, m := range got.ReceivedMessages {
+				ids = append(ids, m.ID)
+			}
+			test.AssertDeepEqual(t, ids, tt.want, "ReceiveMessageBatch() IDs")
+		})
+	}
+}
+
+func TestDynamoMQClientReceiveMessageBatchStopsAtInFlightLimit(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	var puts []*types.PutRequest
+	for i := 0; i < 5; i++ {
+		puts = append(puts, newPutRequestWithReadyItem(fmt.Sprintf("A-%03d", i), test.DefaultTestDate.Add(time.Duration(i)*time.Millisecond)))
+	}
+	ctx := context.Background()
+	client, clean := prepareTestClient(ctx, t, NewSetupFunc(puts...), mock.Clock{T: now}, false, nil, nil, nil,
+		dynamomq.WithMaxInFlight(2))
+	defer clean()
+	got, err := client.ReceiveMessageBatch(ctx, &dynamomq.ReceiveMessageBatchInput{MaxNumberOfMessages: 4})
+	if err != nil {
+		t.Fatalf("ReceiveMessageBatch() error = %v", err)
+	}
+	var ids []string
+	for _, m := range got.ReceivedMessages {
+		if m.GetStatus(now) != dynamomq.StatusProcessing {
+			t.Errorf("ReceiveMessageBatch() status of %s = %s, want %s", m.ID, m.GetStatus(now), dynamomq.StatusProcessing)
+		}
+		ids = append(ids, m.ID)
+	}
+	test.AssertDeepEqual(t, ids, []string{"A-000", "A-001"}, "ReceiveMessageBatch() IDs")
+	_, err = client.ReceiveMessageBatch(ctx, &dynamomq.ReceiveMessageBatchInput{MaxNumberOfMessages: 4})
+	test.AssertError(t, err, dynamomq.InFlightLimitExceededError{Limit: 2}, "ReceiveMessageBatch()")
+}
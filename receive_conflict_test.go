@@ -0,0 +1,163 @@
+package dynamomq_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestDynamoMQClientReceiveMessageRetriesOnConflict(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	tests := []struct {
+		name        string
+		opts        []func(*dynamomq.ClientOptions)
+		taken       []string
+		want        string
+		wantUpdates []string
+		wantErr     error
+	}{
+		{
+			name:        "should receive the next message when the first one is received by another client",
+			taken:       []string{"A-101"},
+			want:        "A-102",
+			wantUpdates: []string{"A-101", "A-102"},
+		},
+		{
+			name:        "should find the queue empty when every message is received by other clients",
+			taken:       []string{"A-101", "A-102", "A-103"},
+			wantUpdates: []string{"A-101", "A-102", "A-103"},
+			wantErr:     dynamomq.ErrEmptyQueue,
+		},
+		{
+			name:        "should return the conflict once the retries are exhausted",
+			opts:        []func(*dynamomq.ClientOptions){dynamomq.WithReceiveConflictRetries(1)},
+			taken:       []string{"A-101", "A-102"},
+			wantUpdates: []string{"A-101", "A-102"},
+			wantErr:     &dynamomq.ConditionalCheckFailedError{},
+		},
+		{
+			name:        "should return the conflict without retrying when the retries are disabled",
+			opts:        []func(*dynamomq.ClientOptions){dynamomq.WithReceiveConflictRetries(0)},
+			taken:       []string{"A-101"},
+			wantUpdates: []string{"A-101"},
+			wantErr:     &dynamomq.ConditionalCheckFailedError{},
+		},
+		{
+			name:        "should not pass over the head of a FIFO queue received by another client",
+			opts:        []func(*dynamomq.ClientOptions){dynamomq.WithUseFIFO(true)},
+			taken:       []string{"A-101"},
+			wantUpdates: []string{"A-101"},
+			wantErr:     dynamomq.ErrEmptyQueue,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// The index keeps returning every message as ready, as a stale read of it would, while the messages
+			// taken by other clients fail the update conditional on their version.
+			var items []map[string]types.AttributeValue
+			for _, id := range []string{"A-101", "A-102", "A-103"} {
+				items = append(items, dynamomqtest.MarshalMap(NewTestMessageItemAsReady(id, test.DefaultTestDate)))
+			}
+			var updates []string
+			opts := append([]func(*dynamomq.ClientOptions){
+				mock.WithClock(mock.Clock{T: now}),
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+						return &dynamodb.QueryOutput{Items: items}, nil
+					},
+					UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+						id := params.Key[dynamomq.AttributeNameID].(*types.AttributeValueMemberS).Value
+						updates = append(updates, id)
+						for _, taken := range tt.taken {
+							if id == taken {
+								return nil, &types.ConditionalCheckFailedException{Message: aws.String("version changed")}
+							}
+						}
+						return &dynamodb.UpdateItemOutput{
+							Attributes: dynamomqtest.MarshalMap(NewTestMessageItemAsProcessing(id, now)),
+						}, nil
+					},
+				}),
+			}, tt.opts...)
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{}, opts...)
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			got, err := client.ReceiveMessage(context.Background(), &dynamomq.ReceiveMessageInput{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReceiveMessage() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got.ReceivedMessage.ID != tt.want {
+				t.Errorf("ReceiveMessage() ID = %s, want %s", got.ReceivedMessage.ID, tt.want)
+			}
+			test.AssertDeepEqual(t, updates, tt.wantUpdates, "UpdateItem() IDs")
+		})
+	}
+}
+
+func TestDynamoMQClientReceiveMessageConcurrentWorkers(t *testing.T) {
+	t.Parallel()
+	const (
+		workers  = 4
+		messages = 20
+	)
+	now := test.DefaultTestDate.Add(time.Minute)
+	var puts []*types.PutRequest
+	for i := 0; i < messages; i++ {
+		puts = append(puts, newPutRequestWithReadyItem(fmt.Sprintf("A-%03d", i), test.DefaultTestDate.Add(time.Duration(i)*time.Millisecond)))
+	}
+	tableName, raw, clean := SetupDynamoDB(t, puts...)
+	defer clean()
+	var (
+		mu       sync.Mutex
+		received = make(map[string]int)
+		wg       sync.WaitGroup
+	)
+	for i := 0; i < workers; i++ {
+		// Each conflict is lost to a worker receiving another message, so a worker retrying as many times
+		// as there are messages never returns a conflict.
+		client, _ := prepareTestClient(context.Background(), t, func(t *testing.T) (string, *dynamodb.Client, func()) {
+			return tableName, raw, func() {}
+		}, mock.Clock{T: now}, false, nil, nil, nil, dynamomq.WithReceiveConflictRetries(messages))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				got, err := client.ReceiveMessage(context.Background(), &dynamomq.ReceiveMessageInput{})
+				if errors.Is(err, dynamomq.ErrEmptyQueue) {
+					return
+				}
+				if err != nil {
+					t.Errorf("ReceiveMessage() error = %v", err)
+					return
+				}
+				mu.Lock()
+				received[got.ReceivedMessage.ID]++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(received) != messages {
+		t.Errorf("ReceiveMessage() received %d messages, want %d", len(received), messages)
+	}
+	for id, n := range received {
+		if n != 1 {
+			t.Errorf("ReceiveMessage() received %s %d times, want once", id, n)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package dynamomq
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+)
+
+// ReclaimExpiredMessagesInput represents the input parameters for reclaiming the messages whose visibility timeout has expired.
+type ReclaimExpiredMessagesInput struct {
+	// QueueType is the type of queue (STANDARD or DLQ) to reclaim messages from. By default, it is STANDARD.
+	QueueType QueueType
+	// Limit is the maximum number of messages to reclaim. Zero means unlimited.
+	Limit int
+}
+
+// ReclaimExpiredMessagesOutput represents the result of the operation to reclaim the messages whose visibility timeout has expired.
+type ReclaimExpiredMessagesOutput struct {
+	// ReclaimedIDs are the IDs of the messages reclaimed, in the order of the queue.
+	ReclaimedIDs []string `json:"reclaimed_ids"`
+}
+
+// ReclaimExpiredMessages makes the messages whose visibility timeout has expired visible again explicitly.
+// Such messages are already received again by ReceiveMessage, which evaluates the visibility timeout when it reads them,
+// but until then they keep their consumer ID and in-flight slot. Each of them is updated to READY, or to DLQ for the DLQ,
+// as ChangeMessageVisibility with a zero timeout would: the version is incremented, the consumer ID is removed and the
+// in-flight slot is released. The receive count is left as it is and is incremented when the message is received again.
+// Each message is updated only if its version has not changed since it was read, so that a message received or updated
+// concurrently is left as it is. The OnVisibilityChanged hook is invoked for each message reclaimed.
+func (c *ClientImpl[T]) ReclaimExpiredMessages(ctx context.Context, params *ReclaimExpiredMessagesInput) (*ReclaimExpiredMessagesOutput, error) {
+	if params == nil {
+		params = &ReclaimExpiredMessagesInput{}
+	}
+	if params.QueueType == "" {
+		params.QueueType = QueueTypeStandard
+	}
+	now := c.clock.Now()
+	// Timestamps with trailing zeros trimmed do not compare lexically within a second,
+	// so the filter keeps a second of margin and the expiry is checked again below.
+	cutoff := clock.FormatRFC3339Nano(now.Truncate(time.Second).Add(time.Second))
+	builder := expression.NewBuilder().
+		WithKeyCondition(expression.Key(c.schema.QueueTypeAttribute).Equal(expression.Value(params.QueueType))).
+		WithFilter(expression.Name(c.schema.InvisibleUntilAtAttribute).LessThan(expression.Value(cutoff)))
+	expr, err := c.buildExpression(builder)
+	if err != nil {
+		return &ReclaimExpiredMessagesOutput{}, BuildingExpressionError{Cause: err}
+	}
+	out := &ReclaimExpiredMessagesOutput{ReclaimedIDs: make([]string, 0)}
+	var exclusiveStartKey map[string]types.AttributeValue
+	for {
+		if err := ctx.Err(); err != nil {
+			return out, OperationCanceledError{Cause: err}
+		}
+		queryOutput, err := c.dynamoDB.Query(ctx, &dynamodb.QueryInput{
+			IndexName:                 aws.String(c.schema.QueueingIndexName),
+			TableName:                 aws.String(c.tableName),
+			KeyConditionExpression:    expr.KeyCondition(),
+			FilterExpression:          expr.Filter(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			ScanIndexForward:          aws.Bool(true),
+			Limit:                     aws.Int32(defaultQueryLimit),
+			ExclusiveStartKey:         exclusiveStartKey,
+		})
+		if err != nil {
+			return out, handleDynamoDBError(err)
+		}
+		for _, item := range queryOutput.Items {
+			if params.Limit > 0 && len(out.ReclaimedIDs) >= params.Limit {
+				return out, nil
+			}
+			id, err := c.reclaimExpiredItem(ctx, item, now)
+			if err != nil {
+				return out, err
+			}
+			if id != "" {
+				out.ReclaimedIDs = append(out.ReclaimedIDs, id)
+			}
+		}
+		exclusiveStartKey = queryOutput.LastEvaluatedKey
+		if exclusiveStartKey == nil {
+			return out, nil
+		}
+	}
+}
+
+// reclaimExpiredItem returns the ID of the message if it has been reclaimed, or an empty string if it has been left as it is.
+func (c *ClientImpl[T]) reclaimExpiredItem(ctx context.Context, item map[string]types.AttributeValue, now time.Time) (string, error) {
+	message := Message[T]{}
+	if err := c.unmarshalItem(item, &message); err != nil {
+		return "", c.handleCorruptMessage(item, err)
+	}
+	if message.InvisibleUntilAt == "" || message.IsProcessing(now) {
+		return "", nil
+	}
+	release := message.InFlightSlot
+	message.UpdatedAt = clock.FormatRFC3339Nano(now)
+	message.InvisibleUntilAt = ""
+	message.ConsumerID = ""
+	message.InFlightSlot = false
+	c.recordTransition(&message, TransitionReclaimed, now)
+	builder := expression.NewBuilder().
+		WithUpdate(c.setHistory(c.removeInFlightSlot(c.setConsumerID(expression.
+			Add(expression.Name(c.schema.VersionAttribute), expression.Value(1)).
+			Set(expression.Name(c.schema.UpdatedAtAttribute), expression.Value(message.UpdatedAt)).
+			Set(expression.Name(c.schema.InvisibleUntilAtAttribute), expression.Value(message.InvisibleUntilAt)), &message), release), &message)).
+		WithCondition(expression.Name(c.schema.VersionAttribute).Equal(expression.Value(message.Version)))
+	expr, err := c.buildExpression(builder)
+	if err != nil {
+		return "", BuildingExpressionError{Cause: err}
+	}
+	reclaimed, err := c.updateDynamoDBItem(ctx, message.ID, &expr)
+	if err != nil {
+		var conflict *ConditionalCheckFailedError
+		if errors.As(err, &conflict) {
+			return "", nil
+		}
+		return "", err
+	}
+	if release {
+		c.releaseInFlightSlot(ctx, message.ID)
+	}
+	c.hooks.visibilityChanged(ctx, &ChangeMessageVisibilityInput{ID: message.ID}, &ChangeMessageVisibilityOutput[T]{ChangedMessage: reclaimed})
+	return message.ID, nil
+}
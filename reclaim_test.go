@@ -0,0 +1,96 @@
+package dynamomq_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestDynamoMQClientReclaimExpiredMessages(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Hour)
+	expired := NewTestMessageItemAsProcessing("A-101", test.DefaultTestDate)
+	expired.ConsumerID = testConsumerID
+	expired.InFlightSlot = true
+	tests := []struct {
+		name         string
+		limit        int
+		want         []string
+		wantReleased int
+	}{
+		{
+			name:         "should reclaim every expired message",
+			want:         []string{"A-101", "A-104"},
+			wantReleased: 1,
+		},
+		{
+			name:         "should reclaim up to the limit",
+			limit:        1,
+			want:         []string{"A-101"},
+			wantReleased: 1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			items := []map[string]types.AttributeValue{
+				dynamomqtest.MarshalMap(expired),
+				// The visibility timeout has not expired yet.
+				dynamomqtest.MarshalMap(NewTestMessageItemAsProcessing("A-102", now)),
+				// The message has never been received.
+				dynamomqtest.MarshalMap(NewTestMessageItemAsReady("A-103", test.DefaultTestDate)),
+				dynamomqtest.MarshalMap(NewTestMessageItemAsProcessing("A-104", test.DefaultTestDate)),
+				// The message is received concurrently.
+				dynamomqtest.MarshalMap(NewTestMessageItemAsProcessing("A-105", test.DefaultTestDate)),
+			}
+			var (
+				updated  []string
+				released int
+			)
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+				mock.WithClock(mock.Clock{T: now}),
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+						return &dynamodb.QueryOutput{Items: items}, nil
+					},
+					UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+						id := params.Key[dynamomq.AttributeNameID].(*types.AttributeValueMemberS).Value
+						switch id {
+						case "dynamomq-inflight#queue":
+							released++
+							return &dynamodb.UpdateItemOutput{}, nil
+						case "A-105":
+							return nil, &types.ConditionalCheckFailedException{}
+						}
+						updated = append(updated, id)
+						return &dynamodb.UpdateItemOutput{
+							Attributes: dynamomqtest.MarshalMap(NewTestMessageItemAsReady(id, test.DefaultTestDate)),
+						}, nil
+					},
+				}))
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			out, err := client.ReclaimExpiredMessages(context.Background(), &dynamomq.ReclaimExpiredMessagesInput{
+				Limit: tt.limit,
+			})
+			if err != nil {
+				t.Fatalf("ReclaimExpiredMessages() error = %v", err)
+			}
+			test.AssertDeepEqual(t, out.ReclaimedIDs, tt.want, "ReclaimExpiredMessages() reclaimed IDs")
+			test.AssertDeepEqual(t, updated, tt.want, "updated messages")
+			if released != tt.wantReleased {
+				t.Errorf("released in-flight slots = %d, want %d", released, tt.wantReleased)
+			}
+		})
+	}
+}
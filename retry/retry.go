@@ -0,0 +1,96 @@
+// Package retry provides the exponential backoff with jitter shared by the producer and the consumer of DynamoMQ.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	// DefaultInitialInterval is the delay before the first retry when InitialInterval is not set.
+	DefaultInitialInterval = 100 * time.Millisecond
+	// DefaultMaxInterval is the upper bound of the delays when MaxInterval is not set.
+	DefaultMaxInterval = 20 * time.Second
+	// DefaultMultiplier is the factor applied to the delay after each attempt when Multiplier is not set.
+	DefaultMultiplier = 2.0
+)
+
+// Policy describes an exponential backoff, capped and optionally jittered.
+// The zero value retries forever, from DefaultInitialInterval up to DefaultMaxInterval, doubling the delay each time.
+// A Policy is safe for concurrent use as long as its Rand is.
+type Policy struct {
+	// InitialInterval is the delay before the first retry.
+	InitialInterval time.Duration
+	// MaxInterval caps the delay between two attempts, before the jitter is applied.
+	MaxInterval time.Duration
+	// Multiplier is the factor applied to the delay after each attempt. It must be at least 1.
+	Multiplier float64
+	// Jitter is the fraction of the delay that is randomized, between 0 and 1. A delay d becomes a random delay
+	// between (1-Jitter)*d and d, so 0 keeps the delays fixed and 1 is the full jitter.
+	Jitter float64
+	// MaxAttempts is the maximum number of attempts, including the first one. Zero means unlimited.
+	MaxAttempts int
+	// Rand returns a random number in [0, 1) to apply the jitter. If it is nil, the source of math/rand is used.
+	// Set it to the Float64 method of a seeded *rand.Rand to get a deterministic sequence of delays.
+	Rand func() float64
+}
+
+// NextDelay returns the delay before the given retry, where 1 is the delay after the first attempt has failed.
+func (p *Policy) NextDelay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	initial, maxInterval, multiplier := p.InitialInterval, p.MaxInterval, p.Multiplier
+	if initial <= 0 {
+		initial = DefaultInitialInterval
+	}
+	if maxInterval <= 0 {
+		maxInterval = DefaultMaxInterval
+	}
+	if multiplier < 1 {
+		multiplier = DefaultMultiplier
+	}
+	delay := math.Min(float64(initial)*math.Pow(multiplier, float64(attempt-1)), float64(maxInterval))
+	if jitter := math.Min(math.Max(p.Jitter, 0), 1); jitter > 0 {
+		random := rand.Float64
+		if p.Rand != nil {
+			random = p.Rand
+		}
+		delay -= jitter * delay * random()
+	}
+	return time.Duration(delay)
+}
+
+// Exhausted reports whether no attempt may follow the given number of attempts.
+func (p *Policy) Exhausted(attempts int) bool {
+	return p.MaxAttempts > 0 && attempts >= p.MaxAttempts
+}
+
+// Do calls fn until it succeeds, returns an error that is not retryable, or MaxAttempts is reached,
+// waiting NextDelay between two attempts. It returns the error of the last attempt, or the error of ctx
+// if ctx is done while waiting.
+func (p *Policy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil || !Retryable(err) || p.Exhausted(attempt) {
+			return err
+		}
+		timer := time.NewTimer(p.NextDelay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Retryable reports whether err, or an error it wraps, has a Retryable method reporting true,
+// like the errors of DynamoMQ for throttled and failed DynamoDB requests.
+func Retryable(err error) bool {
+	var retryable interface{ Retryable() bool }
+	return errors.As(err, &retryable) && retryable.Retryable()
+}
@@ -0,0 +1,170 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/retry"
+)
+
+var errTest = errors.New("test")
+
+func TestPolicyNextDelay(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		policy retry.Policy
+		want   []time.Duration
+	}{
+		{
+			name:   "should double the default initial interval",
+			policy: retry.Policy{},
+			want:   []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond},
+		},
+		{
+			name: "should cap the delays at the max interval",
+			policy: retry.Policy{
+				InitialInterval: time.Second,
+				MaxInterval:     5 * time.Second,
+				Multiplier:      3,
+			},
+			want: []time.Duration{time.Second, 3 * time.Second, 5 * time.Second, 5 * time.Second},
+		},
+		{
+			name: "should draw the delays with full jitter from the seeded source",
+			policy: retry.Policy{
+				InitialInterval: time.Second,
+				MaxInterval:     4 * time.Second,
+				Jitter:          1,
+				Rand:            rand.New(rand.NewSource(1)).Float64,
+			},
+			want: jittered(rand.New(rand.NewSource(1)).Float64, 1, time.Second, 2*time.Second, 4*time.Second, 4*time.Second),
+		},
+		{
+			name: "should randomize only the jitter fraction of the delays",
+			policy: retry.Policy{
+				InitialInterval: time.Second,
+				Jitter:          0.5,
+				Rand:            rand.New(rand.NewSource(42)).Float64,
+			},
+			want: jittered(rand.New(rand.NewSource(42)).Float64, 0.5, time.Second, 2*time.Second, 4*time.Second, 8*time.Second),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := make([]time.Duration, len(tt.want))
+			for i := range got {
+				got[i] = tt.policy.NextDelay(i + 1)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NextDelay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// jittered returns the delays randomized with the same sequence as NextDelay.
+func jittered(random func() float64, jitter float64, delays ...time.Duration) []time.Duration {
+	got := make([]time.Duration, len(delays))
+	for i, d := range delays {
+		got[i] = time.Duration(float64(d) - jitter*float64(d)*random())
+	}
+	return got
+}
+
+func TestPolicyNextDelayShouldStayWithinBounds(t *testing.T) {
+	t.Parallel()
+	p := retry.Policy{
+		InitialInterval: 10 * time.Millisecond,
+		MaxInterval:     time.Second,
+		Jitter:          0.2,
+		Rand:            rand.New(rand.NewSource(7)).Float64,
+	}
+	for attempt := 1; attempt <= 20; attempt++ {
+		ceiling := min(10*time.Millisecond<<(attempt-1), time.Second)
+		if got := p.NextDelay(attempt); got > ceiling || got < ceiling*8/10 {
+			t.Errorf("NextDelay(%d) = %v, want between %v and %v", attempt, got, ceiling*8/10, ceiling)
+		}
+	}
+}
+
+func TestPolicyDo(t *testing.T) {
+	t.Parallel()
+	throttled := dynamomq.ThrottledError{Cause: errTest}
+	tests := []struct {
+		name      string
+		policy    retry.Policy
+		errs      []error
+		wantCalls int
+		wantErr   error
+	}{
+		{
+			name:      "should retry retryable errors until success",
+			errs:      []error{throttled, throttled, nil},
+			wantCalls: 3,
+		},
+		{
+			name:      "should not retry an error that is not retryable",
+			errs:      []error{throttled, errTest},
+			wantCalls: 2,
+			wantErr:   errTest,
+		},
+		{
+			name:      "should not retry an error reporting that it is not retryable",
+			errs:      []error{dynamomq.ValidationError{Cause: errTest}},
+			wantCalls: 1,
+			wantErr:   dynamomq.ValidationError{Cause: errTest},
+		},
+		{
+			name:      "should return the last error after max attempts",
+			policy:    retry.Policy{MaxAttempts: 2},
+			errs:      []error{throttled, throttled, nil},
+			wantCalls: 2,
+			wantErr:   throttled,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tt.policy.InitialInterval = time.Millisecond
+			calls := 0
+			err := tt.policy.Do(context.Background(), func(ctx context.Context) error {
+				err := tt.errs[calls]
+				calls++
+				return err
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Do() error = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("Do() calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestPolicyDoShouldStopWaitingWhenContextIsDone(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	p := retry.Policy{InitialInterval: time.Hour}
+	calls := 0
+	err := p.Do(ctx, func(ctx context.Context) error {
+		calls++
+		cancel()
+		return dynamomq.ThrottledError{Cause: errTest}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Do() error = %v, want %v", err, context.Canceled)
+	}
+	if calls != 1 {
+		t.Errorf("Do() calls = %d, want 1", calls)
+	}
+}
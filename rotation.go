@@ -0,0 +1,101 @@
+package dynamomq
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const defaultEmptyQueueCooldown = 5 * time.Second
+
+// QueueTypeWeight assigns a weight to a queue type received in rotation.
+type QueueTypeWeight struct {
+	// QueueType is the type of queue to receive from.
+	QueueType QueueType
+	// Weight is the share of the receives that start with the queue type. A weight below one counts as one.
+	Weight int
+}
+
+// receiveSchedule spreads the queue types over a cycle in proportion to their weights,
+// interleaving them rather than grouping them, as a smooth weighted round-robin does.
+func receiveSchedule(weights []QueueTypeWeight) []QueueType {
+	weight := func(w QueueTypeWeight) int {
+		if w.Weight < 1 {
+			return 1
+		}
+		return w.Weight
+	}
+	total := 0
+	for _, w := range weights {
+		total += weight(w)
+	}
+	current := make([]int, len(weights))
+	schedule := make([]QueueType, 0, total)
+	for i := 0; i < total; i++ {
+		best := 0
+		for j, w := range weights {
+			current[j] += weight(w)
+			if current[j] > current[best] {
+				best = j
+			}
+		}
+		current[best] -= total
+		schedule = append(schedule, weights[best].QueueType)
+	}
+	return schedule
+}
+
+// rotationCandidates returns the queue types to try for one receive, starting at the next position of the schedule
+// and skipping the queue types found empty within the cooldown. Each call moves the start by one position.
+func (c *ClientImpl[T]) rotationCandidates(now time.Time) []QueueType {
+	c.rotationMu.Lock()
+	defer c.rotationMu.Unlock()
+	start := c.rotationNext
+	c.rotationNext = (c.rotationNext + 1) % len(c.receiveSchedule)
+	seen := make(map[QueueType]bool)
+	candidates := make([]QueueType, 0, len(c.receiveSchedule))
+	for i := range c.receiveSchedule {
+		queueType := c.receiveSchedule[(start+i)%len(c.receiveSchedule)]
+		if seen[queueType] {
+			continue
+		}
+		seen[queueType] = true
+		if now.Before(c.emptyUntil[queueType]) {
+			continue
+		}
+		candidates = append(candidates, queueType)
+	}
+	return candidates
+}
+
+func (c *ClientImpl[T]) markQueueEmpty(queueType QueueType, now time.Time) {
+	c.rotationMu.Lock()
+	defer c.rotationMu.Unlock()
+	if c.emptyUntil == nil {
+		c.emptyUntil = make(map[QueueType]time.Time)
+	}
+	c.emptyUntil[queueType] = now.Add(c.emptyQueueCooldown)
+}
+
+// receiveInRotation receives a message from the first queue type of the rotation that is not empty.
+// When all of them are empty, the EmptyQueueError hints at the soonest time a message of one of them becomes visible again.
+func (c *ClientImpl[T]) receiveInRotation(ctx context.Context, params *ReceiveMessageInput) (*Message[T], error) {
+	var nextVisibleAt time.Time
+	for _, queueType := range c.rotationCandidates(c.clock.Now()) {
+		input := *params
+		input.QueueType = queueType
+		received, err := c.receive(ctx, &input)
+		if err == nil {
+			return received, nil
+		}
+		var emptyQueueErr *EmptyQueueError
+		if !errors.As(err, &emptyQueueErr) {
+			return nil, err
+		}
+		c.markQueueEmpty(queueType, c.clock.Now())
+		if at := emptyQueueErr.NextVisibleAt; !at.IsZero() && (nextVisibleAt.IsZero() || at.Before(nextVisibleAt)) {
+			nextVisibleAt = at
+		}
+	}
+	return nil, newEmptyQueueError(c.clock.Now(), nextVisibleAt)
+}
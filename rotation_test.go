@@ -0,0 +1,168 @@
+package dynamomq_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+const (
+	queueTypeBusy  dynamomq.QueueType = "BUSY"
+	queueTypeQuiet dynamomq.QueueType = "QUIET"
+)
+
+// rotationTable is a fake table holding ready messages per queue type. A received message is removed,
+// except in the busy queue type, which never runs out of messages.
+type rotationTable struct {
+	mu      sync.Mutex
+	items   map[dynamomq.QueueType][]map[string]types.AttributeValue
+	queries map[dynamomq.QueueType]int
+}
+
+func (r *rotationTable) add(queueType dynamomq.QueueType, id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := NewTestMessageItemAsReady(id, test.DefaultTestDate)
+	m.QueueType = queueType
+	r.items[queueType] = append(r.items[queueType], dynamomqtest.MarshalMap(m))
+}
+
+func (r *rotationTable) api() *mock.DynamoDB {
+	return &mock.DynamoDB{
+		QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			for _, v := range params.ExpressionAttributeValues {
+				if s, ok := v.(*types.AttributeValueMemberS); ok {
+					queueType := dynamomq.QueueType(s.Value)
+					r.queries[queueType]++
+					return &dynamodb.QueryOutput{Items: r.items[queueType]}, nil
+				}
+			}
+			return &dynamodb.QueryOutput{}, nil
+		},
+		UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			id := params.Key[dynamomq.AttributeNameID].(*types.AttributeValueMemberS).Value
+			for queueType, items := range r.items {
+				for i, item := range items {
+					if item[dynamomq.AttributeNameID].(*types.AttributeValueMemberS).Value != id {
+						continue
+					}
+					if queueType != queueTypeBusy {
+						r.items[queueType] = append(items[:i:i], items[i+1:]...)
+					}
+					return &dynamodb.UpdateItemOutput{Attributes: item}, nil
+				}
+			}
+			return nil, &types.ConditionalCheckFailedException{}
+		},
+	}
+}
+
+func TestDynamoMQClientReceiveInRotation(t *testing.T) {
+	t.Parallel()
+	table := &rotationTable{
+		items:   make(map[dynamomq.QueueType][]map[string]types.AttributeValue),
+		queries: make(map[dynamomq.QueueType]int),
+	}
+	table.add(queueTypeBusy, "A-101")
+	table.add(queueTypeQuiet, "B-101")
+	clk := &steppingClock{now: test.DefaultTestDate.Add(time.Minute)}
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithReceiveQueueTypes(queueTypeBusy, queueTypeQuiet),
+		dynamomq.WithEmptyQueueCooldown(10*time.Second),
+		mock.WithClock(clk),
+		dynamomq.WithDynamoDBAPI(table.api()))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	ctx := context.Background()
+	receive := func() dynamomq.QueueType {
+		t.Helper()
+		out, err := client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{})
+		if err != nil {
+			t.Fatalf("ReceiveMessage() error = %v", err)
+		}
+		return out.ReceivedMessage.QueueType
+	}
+	var got []dynamomq.QueueType
+	for i := 0; i < 6; i++ {
+		got = append(got, receive())
+	}
+	test.AssertDeepEqual(t, got, []dynamomq.QueueType{
+		queueTypeBusy, queueTypeQuiet, queueTypeBusy, queueTypeBusy, queueTypeBusy, queueTypeBusy,
+	}, "ReceiveMessage() queue types")
+	if table.queries[queueTypeQuiet] != 2 {
+		t.Errorf("Query() on the quiet queue type = %d times, want 2 as it is skipped during the cooldown", table.queries[queueTypeQuiet])
+	}
+
+	clk.Advance(10 * time.Second)
+	table.add(queueTypeQuiet, "B-102")
+	got = got[:0]
+	for i := 0; i < 2; i++ {
+		got = append(got, receive())
+	}
+	test.AssertDeepEqual(t, got, []dynamomq.QueueType{queueTypeBusy, queueTypeQuiet}, "ReceiveMessage() queue types after the cooldown")
+}
+
+func TestDynamoMQClientReceiveInRotationWeighted(t *testing.T) {
+	t.Parallel()
+	table := &rotationTable{
+		items:   make(map[dynamomq.QueueType][]map[string]types.AttributeValue),
+		queries: make(map[dynamomq.QueueType]int),
+	}
+	table.add(queueTypeBusy, "A-101")
+	for _, id := range []string{"B-101", "B-102", "B-103"} {
+		table.add(queueTypeQuiet, id)
+	}
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithWeightedReceiveQueueTypes(
+			dynamomq.QueueTypeWeight{QueueType: queueTypeBusy, Weight: 3},
+			dynamomq.QueueTypeWeight{QueueType: queueTypeQuiet, Weight: 1},
+		),
+		mock.WithClock(mock.Clock{T: test.DefaultTestDate.Add(time.Minute)}),
+		dynamomq.WithDynamoDBAPI(table.api()))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	counts := make(map[dynamomq.QueueType]int)
+	for i := 0; i < 8; i++ {
+		out, err := client.ReceiveMessage(context.Background(), &dynamomq.ReceiveMessageInput{})
+		if err != nil {
+			t.Fatalf("ReceiveMessage() error = %v", err)
+		}
+		counts[out.ReceivedMessage.QueueType]++
+	}
+	test.AssertDeepEqual(t, counts, map[dynamomq.QueueType]int{queueTypeBusy: 6, queueTypeQuiet: 2}, "ReceiveMessage() counts")
+}
+
+func TestDynamoMQClientReceiveInRotationEmpty(t *testing.T) {
+	t.Parallel()
+	table := &rotationTable{
+		items:   make(map[dynamomq.QueueType][]map[string]types.AttributeValue),
+		queries: make(map[dynamomq.QueueType]int),
+	}
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithReceiveQueueTypes(queueTypeBusy, queueTypeQuiet),
+		mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+		dynamomq.WithDynamoDBAPI(table.api()))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		_, err = client.ReceiveMessage(context.Background(), &dynamomq.ReceiveMessageInput{})
+		test.AssertError(t, err, dynamomq.ErrEmptyQueue, "ReceiveMessage()")
+	}
+	test.AssertDeepEqual(t, table.queries, map[dynamomq.QueueType]int{queueTypeBusy: 1, queueTypeQuiet: 1}, "Query() counts")
+}
@@ -0,0 +1,82 @@
+package dynamomq
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"math"
+)
+
+// sampler passes a fraction of the received messages to a sink.
+type sampler[T any] struct {
+	rate float64
+	sink func(ctx context.Context, message *Message[T])
+
+	errorLog *log.Logger
+}
+
+// WithSampler is an option function to pass a fraction of the received messages to sink, for example to feed
+// an offline analysis of the payloads without changing the consumers. A message is sampled depending on a hash of
+// its ID, so every client of the queue samples the same messages, and it is passed to sink on its first receive only,
+// so a message received again after a failure is not sampled twice. The rate is between 0 and 1, where 0.01 samples
+// about 1% of the messages.
+// The sink runs synchronously on the goroutine of ReceiveMessage after a successful receive and must not modify
+// the message. A panic in the sink is recovered and logged, and does not affect the received message.
+func WithSampler[T any](rate float64, sink func(ctx context.Context, message *Message[T])) func(*ClientOptions) {
+	return func(o *ClientOptions) {
+		o.Sampler = &sampler[T]{
+			rate: rate,
+			sink: sink,
+		}
+	}
+}
+
+func samplerOf[T any](s any, errorLog *log.Logger) (*sampler[T], error) {
+	if s == nil {
+		return nil, nil
+	}
+	typed, ok := s.(*sampler[T])
+	if !ok {
+		return nil, InvalidSamplerError{Sampler: typeName(s), MessageType: typeNameOf[T]()}
+	}
+	if typed == nil {
+		return nil, nil
+	}
+	copied := *typed
+	copied.errorLog = errorLog
+	return &copied, nil
+}
+
+// IsSampled reports whether the message with the ID is sampled at the rate by a client created with WithSampler.
+// The result only depends on the ID and the rate, and a message sampled at a rate is sampled at every higher rate.
+func IsSampled(id string, rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	if rate >= 1 {
+		return true
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(id))
+	// The high bits of FNV-1a hardly depend on the last bytes of the ID, so IDs differing only by a suffix
+	// are mixed with the finalizer of MurmurHash3 before they are compared to the rate.
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return float64(x) < rate*math.MaxUint64
+}
+
+func (s *sampler[T]) sample(ctx context.Context, message *Message[T]) {
+	if s == nil || s.sink == nil || message == nil || message.ReceiveCount != 1 || !IsSampled(message.ID, s.rate) {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			logTo(s.errorLog, "DynamoMQ: Recovered from a panic in the sampler of message %s. %v", message.ID, r)
+		}
+	}()
+	s.sink(ctx, message)
+}
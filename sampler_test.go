@@ -0,0 +1,178 @@
+package dynamomq_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestIsSampled(t *testing.T) {
+	t.Parallel()
+	const n = 100000
+	idSets := map[string][]string{
+		"sequential IDs": make([]string, n),
+		"random IDs":     make([]string, n),
+	}
+	for i := 0; i < n; i++ {
+		idSets["sequential IDs"][i] = fmt.Sprintf("A-%d", i)
+		idSets["random IDs"][i] = uuid.NewString()
+	}
+	for name, ids := range idSets {
+		for _, rate := range []float64{0.001, 0.01, 0.1, 0.5} {
+			name, ids, rate := name, ids, rate
+			t.Run(fmt.Sprintf("should sample about %v of %s", rate, name), func(t *testing.T) {
+				t.Parallel()
+				var sampled int
+				for _, id := range ids {
+					if dynamomq.IsSampled(id, rate) {
+						sampled++
+						if !dynamomq.IsSampled(id, math.Min(rate*2, 1)) {
+							t.Fatalf("IsSampled(%q, %v) = false, want an ID sampled at a rate sampled at every higher rate", id, rate*2)
+						}
+					}
+				}
+				// The number of sampled IDs follows a binomial distribution, which is within 5 standard deviations
+				// of its mean but for a chance of about one in a million.
+				mean := rate * n
+				tolerance := 5 * math.Sqrt(n*rate*(1-rate))
+				if math.Abs(float64(sampled)-mean) > tolerance {
+					t.Errorf("IsSampled() sampled %d of %d IDs, want %v ± %.0f", sampled, n, mean, tolerance)
+				}
+			})
+		}
+	}
+	t.Run("should never sample at a rate of zero and always at a rate of one", func(t *testing.T) {
+		t.Parallel()
+		for _, id := range idSets["sequential IDs"][:1000] {
+			if dynamomq.IsSampled(id, 0) || !dynamomq.IsSampled(id, 1) {
+				t.Fatalf("IsSampled(%q) is not false at 0 and true at 1", id)
+			}
+		}
+	})
+}
+
+// newSamplingDynamoDB returns a queue whose Query returns the message with the ID given by next, received
+// receiveCount times before, and whose UpdateItem returns it as received at now.
+func newSamplingDynamoDB(next func() (string, int), now time.Time) *mock.DynamoDB {
+	var (
+		id           string
+		receiveCount int
+	)
+	return &mock.DynamoDB{
+		QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+			id, receiveCount = next()
+			ready := dynamomqtest.NewReadyMessage(id, test.NewMessageData(id), test.DefaultTestDate,
+				dynamomqtest.WithReceiveCount(receiveCount))
+			return &dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{dynamomqtest.MarshalMap(ready)}}, nil
+		},
+		UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+			received := dynamomqtest.NewProcessingMessage(id, test.NewMessageData(id), now,
+				dynamomqtest.WithReceiveCount(receiveCount+1))
+			return &dynamodb.UpdateItemOutput{Attributes: dynamomqtest.MarshalMap(received)}, nil
+		},
+	}
+}
+
+func TestDynamoMQClientReceiveMessageSampler(t *testing.T) {
+	t.Parallel()
+	const (
+		n    = 2000
+		rate = 0.05
+	)
+	// Every message is received twice, the second time as if its processing had failed.
+	var calls int
+	next := func() (string, int) {
+		defer func() { calls++ }()
+		return fmt.Sprintf("A-%d", calls/2), calls % 2
+	}
+	var sampled []string
+	now := test.DefaultTestDate.Add(time.Minute)
+	client := newTestClient[test.MessageData](t, newSamplingDynamoDB(next, now), mock.WithClock(mock.Clock{T: now}),
+		dynamomq.WithSampler(rate, func(ctx context.Context, message *dynamomq.Message[test.MessageData]) {
+			sampled = append(sampled, message.ID)
+		}))
+	var want []string
+	for i := 0; i < n; i++ {
+		if _, err := client.ReceiveMessage(context.Background(), &dynamomq.ReceiveMessageInput{}); err != nil {
+			t.Fatalf("ReceiveMessage() error = %v", err)
+		}
+		if id := fmt.Sprintf("A-%d", i/2); i%2 == 0 && dynamomq.IsSampled(id, rate) {
+			want = append(want, id)
+		}
+	}
+	if len(want) == 0 {
+		t.Fatal("no message is sampled, want some")
+	}
+	test.AssertDeepEqual(t, sampled, want, "sampled IDs")
+}
+
+func TestDynamoMQClientReceiveMessageSamplerShouldNotAffectDelivery(t *testing.T) {
+	t.Parallel()
+	var logged bytes.Buffer
+	now := test.DefaultTestDate.Add(time.Minute)
+	client := newTestClient[test.MessageData](t, newSamplingDynamoDB(func() (string, int) { return "A-101", 0 }, now),
+		mock.WithClock(mock.Clock{T: now}),
+		dynamomq.WithSampler(1, func(ctx context.Context, message *dynamomq.Message[test.MessageData]) {
+			panic("sink failure")
+		}),
+		dynamomq.WithClientErrorLog(log.New(&logged, "", 0)))
+	got, err := client.ReceiveMessage(context.Background(), &dynamomq.ReceiveMessageInput{})
+	if err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	if got.ReceivedMessage.ID != "A-101" {
+		t.Errorf("ReceiveMessage() ID = %s, want A-101", got.ReceivedMessage.ID)
+	}
+	if !strings.Contains(logged.String(), "sampler of message A-101. sink failure") {
+		t.Errorf("error log = %q, want the recovered panic", logged.String())
+	}
+}
+
+func TestDynamoMQClientReceiveMessageSamplerOnTable(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tableName, raw, clean := SetupDynamoDB(t, newPutRequestWithReadyItem("A-101", test.DefaultTestDate))
+	defer clean()
+	var sampled []string
+	sink := func(ctx context.Context, message *dynamomq.Message[test.MessageData]) {
+		sampled = append(sampled, message.ID)
+	}
+	// The second client receives the message again once the visibility timeout of the first receive has expired.
+	for i, now := range []time.Time{test.DefaultTestDate.Add(time.Minute), test.DefaultTestDate.Add(time.Hour)} {
+		client, _ := prepareTestClient(ctx, t, func(t *testing.T) (string, *dynamodb.Client, func()) {
+			return tableName, raw, func() {}
+		}, mock.Clock{T: now}, false, nil, nil, nil, dynamomq.WithSampler(1, sink))
+		got, err := client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{})
+		if err != nil {
+			t.Fatalf("ReceiveMessage() error = %v", err)
+		}
+		if got.ReceivedMessage.ReceiveCount != i+1 {
+			t.Errorf("ReceiveMessage() receive count = %d, want %d", got.ReceivedMessage.ReceiveCount, i+1)
+		}
+	}
+	test.AssertDeepEqual(t, sampled, []string{"A-101"}, "sampled IDs")
+}
+
+func TestNewFromConfigShouldReturnInvalidSamplerError(t *testing.T) {
+	t.Parallel()
+	_, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithSampler(0.01, func(ctx context.Context, message *dynamomq.Message[string]) {}))
+	test.AssertError(t, err, dynamomq.InvalidSamplerError{
+		Sampler:     "*dynamomq.sampler[string]",
+		MessageType: "test.MessageData",
+	}, "NewFromConfig()")
+}
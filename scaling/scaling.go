@@ -0,0 +1,263 @@
+// Package scaling computes from the backlog of a DynamoMQ queue the number of workers it needs, and reports it
+// periodically so that it can drive an autoscaler, such as an external metric of a Kubernetes
+// HorizontalPodAutoscaler or a target tracking policy of an EC2 Auto Scaling group.
+package scaling
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+)
+
+// DefaultInterval is the time interval between two reports when Interval is not set.
+const DefaultInterval = 30 * time.Second
+
+// ErrReporterClosed is an error that indicates the Reporter has been stopped.
+var ErrReporterClosed = errors.New("DynamoMQ: Reporter closed")
+
+// Backlog returns the number of messages of the stats that workers have to process: the ready messages and the
+// messages being processed. Held messages are not counted, as no worker can receive them until they are released.
+func Backlog(stats *dynamomq.GetQueueStatsOutput) int {
+	if stats == nil {
+		return 0
+	}
+	return stats.TotalMessagesInQueueReady + stats.TotalMessagesInQueueProcessing
+}
+
+// DesiredConcurrency returns the number of workers needed for each of them to have at most targetPerWorker messages
+// of the Backlog of the stats, bounded by minWorkers and maxWorkers. A targetPerWorker below 1 is taken as 1,
+// and a maxWorkers of zero or less means no upper bound. When maxWorkers is below minWorkers, minWorkers wins.
+func DesiredConcurrency(stats *dynamomq.GetQueueStatsOutput, targetPerWorker, minWorkers, maxWorkers int) int {
+	if targetPerWorker < 1 {
+		targetPerWorker = 1
+	}
+	backlog := Backlog(stats)
+	desired := backlog / targetPerWorker
+	if backlog%targetPerWorker != 0 {
+		desired++
+	}
+	if maxWorkers > 0 && desired > maxWorkers {
+		desired = maxWorkers
+	}
+	if desired < minWorkers {
+		desired = minWorkers
+	}
+	return desired
+}
+
+// QueueStatsAPI is the subset of the DynamoMQ client used by a Reporter. Every dynamomq.Client satisfies this interface.
+type QueueStatsAPI interface {
+	GetQueueStats(ctx context.Context, params *dynamomq.GetQueueStatsInput) (*dynamomq.GetQueueStatsOutput, error)
+}
+
+// Signal is the scaling signal of a queue at a point in time.
+type Signal struct {
+	// QueueType is the type of queue the signal is computed for.
+	QueueType dynamomq.QueueType `json:"queue_type"`
+	// Ready is the number of messages ready to be received.
+	Ready int `json:"ready"`
+	// Processing is the number of messages being processed.
+	Processing int `json:"processing"`
+	// Held is the number of messages held with HoldMessage, which are not part of the backlog.
+	Held int `json:"held"`
+	// Backlog is the number of messages workers have to process, as returned by Backlog.
+	Backlog int `json:"backlog"`
+	// DesiredConcurrency is the number of workers needed for the backlog, as returned by DesiredConcurrency.
+	DesiredConcurrency int `json:"desired_concurrency"`
+	// At is the time the signal was computed.
+	At time.Time `json:"at"`
+}
+
+// ReporterOptions contains configuration options for a Reporter instance.
+type ReporterOptions struct {
+	// Interval is the time interval between two reports.
+	Interval time.Duration
+	// QueueType is the type of queue whose backlog is reported. By default, it is STANDARD.
+	QueueType dynamomq.QueueType
+	// TargetPerWorker is the number of messages of the backlog each worker is expected to handle.
+	TargetPerWorker int
+	// MinWorkers is the lower bound of the desired concurrency.
+	MinWorkers int
+	// MaxWorkers is the upper bound of the desired concurrency. Zero means no upper bound.
+	MaxWorkers int
+	// OnSignal is called with each signal, on the goroutine of the Reporter, for example to publish it as a metric.
+	OnSignal func(signal Signal)
+	// ErrorLog is an optional logger for errors. If nil, the standard logger is used.
+	ErrorLog *log.Logger
+	// Clock is an abstraction of time operations, allowing control over time during tests.
+	Clock clock.Clock
+}
+
+// WithInterval sets the time interval between two reports.
+func WithInterval(interval time.Duration) func(o *ReporterOptions) {
+	return func(o *ReporterOptions) {
+		o.Interval = interval
+	}
+}
+
+// WithQueueType sets the type of queue whose backlog is reported.
+func WithQueueType(queueType dynamomq.QueueType) func(o *ReporterOptions) {
+	return func(o *ReporterOptions) {
+		o.QueueType = queueType
+	}
+}
+
+// WithTargetPerWorker sets the number of messages of the backlog each worker is expected to handle.
+func WithTargetPerWorker(targetPerWorker int) func(o *ReporterOptions) {
+	return func(o *ReporterOptions) {
+		o.TargetPerWorker = targetPerWorker
+	}
+}
+
+// WithBounds sets the lower and upper bounds of the desired concurrency. A maxWorkers of zero means no upper bound.
+func WithBounds(minWorkers, maxWorkers int) func(o *ReporterOptions) {
+	return func(o *ReporterOptions) {
+		o.MinWorkers = minWorkers
+		o.MaxWorkers = maxWorkers
+	}
+}
+
+// WithOnSignal sets the function called with each signal.
+func WithOnSignal(onSignal func(signal Signal)) func(o *ReporterOptions) {
+	return func(o *ReporterOptions) {
+		o.OnSignal = onSignal
+	}
+}
+
+// WithErrorLog sets a custom logger for the Reporter.
+func WithErrorLog(errorLog *log.Logger) func(o *ReporterOptions) {
+	return func(o *ReporterOptions) {
+		o.ErrorLog = errorLog
+	}
+}
+
+// NewReporter creates a new Reporter that reads the backlog of the queue with GetQueueStats.
+func NewReporter(api QueueStatsAPI, opts ...func(o *ReporterOptions)) *Reporter {
+	o := &ReporterOptions{
+		Interval:        DefaultInterval,
+		QueueType:       dynamomq.QueueTypeStandard,
+		TargetPerWorker: 1,
+		Clock:           &clock.RealClock{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &Reporter{
+		api:             api,
+		interval:        o.Interval,
+		queueType:       o.QueueType,
+		targetPerWorker: o.TargetPerWorker,
+		minWorkers:      o.MinWorkers,
+		maxWorkers:      o.MaxWorkers,
+		onSignal:        o.OnSignal,
+		errorLog:        o.ErrorLog,
+		clock:           o.Clock,
+		doneChan:        make(chan struct{}),
+	}
+}
+
+// Reporter periodically computes the desired concurrency of a queue from its backlog and passes it to a callback,
+// so that every service scaling on its backlog does not have to write the same polling loop.
+// Note: To create a new instance of Reporter, it is necessary to use the NewReporter function.
+type Reporter struct {
+	api             QueueStatsAPI
+	interval        time.Duration
+	queueType       dynamomq.QueueType
+	targetPerWorker int
+	minWorkers      int
+	maxWorkers      int
+	onSignal        func(signal Signal)
+	errorLog        *log.Logger
+	clock           clock.Clock
+
+	mu       sync.Mutex
+	runWG    sync.WaitGroup
+	doneChan chan struct{}
+}
+
+// Start reports the signal every Interval until the context is done or Stop is called.
+// It returns ErrReporterClosed after Stop, or the error of the context.
+func (r *Reporter) Start(ctx context.Context) error {
+	r.runWG.Add(1)
+	defer r.runWG.Done()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-r.doneChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	for {
+		if _, err := r.Report(ctx); err != nil && ctx.Err() == nil {
+			r.logf("DynamoMQ: Failed to report the scaling signal. %s", err)
+		}
+		if !sleepContext(ctx, r.interval) {
+			select {
+			case <-r.doneChan:
+				return ErrReporterClosed
+			default:
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// Stop stops the Reporter and waits until the running report has finished.
+func (r *Reporter) Stop() {
+	r.mu.Lock()
+	select {
+	case <-r.doneChan:
+	default:
+		close(r.doneChan)
+	}
+	r.mu.Unlock()
+	r.runWG.Wait()
+}
+
+// Report gets the stats of the queue once, and passes the signal computed from them to OnSignal and returns it.
+// Start calls it every Interval.
+func (r *Reporter) Report(ctx context.Context) (Signal, error) {
+	stats, err := r.api.GetQueueStats(ctx, &dynamomq.GetQueueStatsInput{QueueType: r.queueType})
+	if err != nil {
+		return Signal{}, err
+	}
+	signal := Signal{
+		QueueType:          r.queueType,
+		Ready:              stats.TotalMessagesInQueueReady,
+		Processing:         stats.TotalMessagesInQueueProcessing,
+		Held:               stats.TotalMessagesInQueueHeld,
+		Backlog:            Backlog(stats),
+		DesiredConcurrency: DesiredConcurrency(stats, r.targetPerWorker, r.minWorkers, r.maxWorkers),
+		At:                 r.clock.Now(),
+	}
+	if r.onSignal != nil {
+		r.onSignal(signal)
+	}
+	return signal, nil
+}
+
+func (r *Reporter) logf(format string, v ...any) {
+	if r.errorLog != nil {
+		r.errorLog.Printf(format, v...)
+	} else {
+		log.Printf(format, v...)
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,184 @@
+package scaling_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+	"github.com/vvatanabe/dynamomq/scaling"
+)
+
+// tickingClock is a clock that moves forward by step every time it is read.
+type tickingClock struct {
+	mu   sync.Mutex
+	now  time.Time
+	step time.Duration
+}
+
+func (c *tickingClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now
+	c.now = c.now.Add(c.step)
+	return now
+}
+
+func newStats(ready, processing, held int) *dynamomq.GetQueueStatsOutput {
+	return &dynamomq.GetQueueStatsOutput{
+		TotalMessagesInQueue:           ready + processing + held,
+		TotalMessagesInQueueReady:      ready,
+		TotalMessagesInQueueProcessing: processing,
+		TotalMessagesInQueueHeld:       held,
+	}
+}
+
+// newStatsClient returns a client whose queue holds the given stats in turn, repeating the last one.
+func newStatsClient(stats ...*dynamomq.GetQueueStatsOutput) *mock.Client[any] {
+	var calls atomic.Int32
+	return &mock.Client[any]{
+		GetQueueStatsFunc: func(ctx context.Context, params *dynamomq.GetQueueStatsInput) (*dynamomq.GetQueueStatsOutput, error) {
+			i := min(int(calls.Add(1))-1, len(stats)-1)
+			return stats[i], nil
+		},
+	}
+}
+
+func TestDesiredConcurrency(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name            string
+		stats           *dynamomq.GetQueueStatsOutput
+		targetPerWorker int
+		minWorkers      int
+		maxWorkers      int
+		want            int
+	}{
+		{name: "should need no worker for an empty queue", stats: newStats(0, 0, 0), targetPerWorker: 10, want: 0},
+		{name: "should divide the backlog by the target", stats: newStats(30, 10, 0), targetPerWorker: 10, want: 4},
+		{name: "should round a partial worker up", stats: newStats(41, 0, 0), targetPerWorker: 10, want: 5},
+		{name: "should not count held messages", stats: newStats(5, 5, 100), targetPerWorker: 10, want: 1},
+		{name: "should keep the lower bound", stats: newStats(1, 0, 0), targetPerWorker: 10, minWorkers: 2, maxWorkers: 8, want: 2},
+		{name: "should keep the upper bound", stats: newStats(1000, 0, 0), targetPerWorker: 10, minWorkers: 2, maxWorkers: 8, want: 8},
+		{name: "should have no upper bound when the maximum is zero", stats: newStats(1000, 0, 0), targetPerWorker: 10, want: 100},
+		{name: "should prefer the lower bound over an upper bound below it", stats: newStats(1000, 0, 0), targetPerWorker: 10, minWorkers: 5, maxWorkers: 3, want: 5},
+		{name: "should take a target below one as one", stats: newStats(7, 0, 0), targetPerWorker: 0, want: 7},
+		{name: "should return the lower bound without stats", stats: nil, targetPerWorker: 10, minWorkers: 1, want: 1},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := scaling.DesiredConcurrency(tt.stats, tt.targetPerWorker, tt.minWorkers, tt.maxWorkers)
+			if got != tt.want {
+				t.Errorf("DesiredConcurrency() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReporterReport(t *testing.T) {
+	t.Parallel()
+	var queueTypes []dynamomq.QueueType
+	client := newStatsClient(newStats(0, 0, 0), newStats(25, 5, 1), newStats(500, 20, 0))
+	getQueueStats := client.GetQueueStatsFunc
+	client.GetQueueStatsFunc = func(ctx context.Context, params *dynamomq.GetQueueStatsInput) (*dynamomq.GetQueueStatsOutput, error) {
+		queueTypes = append(queueTypes, params.QueueType)
+		return getQueueStats(ctx, params)
+	}
+	var signals []scaling.Signal
+	reporter := scaling.NewReporter(client,
+		mock.WithReporterClock(&tickingClock{now: test.DefaultTestDate, step: time.Minute}),
+		scaling.WithQueueType(dynamomq.QueueTypeDLQ),
+		scaling.WithTargetPerWorker(10),
+		scaling.WithBounds(1, 20),
+		scaling.WithOnSignal(func(signal scaling.Signal) {
+			signals = append(signals, signal)
+		}))
+	want := []scaling.Signal{
+		{QueueType: dynamomq.QueueTypeDLQ, DesiredConcurrency: 1, At: test.DefaultTestDate},
+		{QueueType: dynamomq.QueueTypeDLQ, Ready: 25, Processing: 5, Held: 1, Backlog: 30, DesiredConcurrency: 3,
+			At: test.DefaultTestDate.Add(time.Minute)},
+		{QueueType: dynamomq.QueueTypeDLQ, Ready: 500, Processing: 20, Backlog: 520, DesiredConcurrency: 20,
+			At: test.DefaultTestDate.Add(2 * time.Minute)},
+	}
+	for i, w := range want {
+		got, err := reporter.Report(context.Background())
+		if err != nil {
+			t.Fatalf("Report() #%d error = %v", i, err)
+		}
+		test.AssertDeepEqual(t, got, w, "Report()")
+	}
+	test.AssertDeepEqual(t, signals, want, "OnSignal()")
+	test.AssertDeepEqual(t, queueTypes, []dynamomq.QueueType{dynamomq.QueueTypeDLQ, dynamomq.QueueTypeDLQ, dynamomq.QueueTypeDLQ},
+		"GetQueueStats() QueueType")
+}
+
+func TestReporterReportShouldReturnError(t *testing.T) {
+	t.Parallel()
+	reporter := scaling.NewReporter(&mock.Client[any]{
+		GetQueueStatsFunc: func(ctx context.Context, params *dynamomq.GetQueueStatsInput) (*dynamomq.GetQueueStatsOutput, error) {
+			return nil, test.ErrTest
+		},
+	}, scaling.WithOnSignal(func(signal scaling.Signal) {
+		t.Errorf("OnSignal() is called with %+v, want no signal", signal)
+	}))
+	if _, err := reporter.Report(context.Background()); !errors.Is(err, test.ErrTest) {
+		t.Errorf("Report() error = %v, want %v", err, test.ErrTest)
+	}
+}
+
+func TestReporterStartAndStop(t *testing.T) {
+	t.Parallel()
+	signals := make(chan scaling.Signal, 10)
+	reporter := scaling.NewReporter(newStatsClient(newStats(10, 0, 0), newStats(40, 0, 0)),
+		mock.WithReporterClock(&tickingClock{now: test.DefaultTestDate, step: time.Millisecond}),
+		scaling.WithInterval(time.Millisecond),
+		scaling.WithTargetPerWorker(10),
+		scaling.WithOnSignal(func(signal scaling.Signal) {
+			select {
+			case signals <- signal:
+			default:
+			}
+		}))
+	done := make(chan error, 1)
+	go func() {
+		done <- reporter.Start(context.Background())
+	}()
+	var got []scaling.Signal
+	for len(got) < 2 {
+		select {
+		case signal := <-signals:
+			got = append(got, signal)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("the Reporter reported %d signals, want 2", len(got))
+		}
+	}
+	reporter.Stop()
+	if err := <-done; !errors.Is(err, scaling.ErrReporterClosed) {
+		t.Errorf("Start() error = %v, want %v", err, scaling.ErrReporterClosed)
+	}
+	test.AssertDeepEqual(t, got, []scaling.Signal{
+		{QueueType: dynamomq.QueueTypeStandard, Ready: 10, Backlog: 10, DesiredConcurrency: 1, At: test.DefaultTestDate},
+		{QueueType: dynamomq.QueueTypeStandard, Ready: 40, Backlog: 40, DesiredConcurrency: 4,
+			At: test.DefaultTestDate.Add(time.Millisecond)},
+	}, "OnSignal()")
+}
+
+func TestReporterStartShouldStopWithContext(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	reporter := scaling.NewReporter(newStatsClient(newStats(1, 0, 0)),
+		scaling.WithInterval(time.Hour),
+		scaling.WithOnSignal(func(signal scaling.Signal) {
+			cancel()
+		}))
+	if err := reporter.Start(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Start() error = %v, want %v", err, context.Canceled)
+	}
+}
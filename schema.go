@@ -0,0 +1,383 @@
+package dynamomq
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq/internal/constant"
+)
+
+// TableSchema defines the attribute names and the queueing index name under which messages are stored.
+// It allows the client to work with tables that follow other naming conventions.
+// Fields left empty fall back to the default names, which are the AttributeName constants.
+type TableSchema struct {
+	// IDAttribute is the name of the partition key of the table.
+	IDAttribute string
+	// DataAttribute is the name of the attribute holding the message payload.
+	DataAttribute string
+	// ReceiveCountAttribute is the name of the attribute holding the receive count.
+	ReceiveCountAttribute string
+	// QueueTypeAttribute is the name of the partition key of the queueing index.
+	QueueTypeAttribute string
+	// VersionAttribute is the name of the attribute used for optimistic concurrency control.
+	VersionAttribute string
+	// CreatedAtAttribute is the name of the attribute holding the creation timestamp.
+	CreatedAtAttribute string
+	// UpdatedAtAttribute is the name of the attribute holding the last update timestamp.
+	UpdatedAtAttribute string
+	// SentAtAttribute is the name of the sort key of the queueing index.
+	SentAtAttribute string
+	// ReceivedAtAttribute is the name of the attribute holding the last receive timestamp.
+	ReceivedAtAttribute string
+	// InvisibleUntilAtAttribute is the name of the attribute holding the end of the visibility timeout.
+	InvisibleUntilAtAttribute string
+	// ConsumerIDAttribute is the name of the attribute holding the identifier of the consumer processing the message.
+	ConsumerIDAttribute string
+	// InFlightSlotAttribute is the name of the attribute marking a message holding a slot of the in-flight limit.
+	InFlightSlotAttribute string
+	// CorrelationIDAttribute is the name of the attribute holding the correlation ID the message was sent with.
+	CorrelationIDAttribute string
+	// TenantIDAttribute is the name of the attribute holding the tenant the message was sent for.
+	TenantIDAttribute string
+	// TagsAttribute is the name of the string set holding the tags the message was sent with.
+	TagsAttribute string
+	// HeldAttribute is the name of the attribute set on the messages held with HoldMessage.
+	HeldAttribute string
+	// HoldReasonAttribute is the name of the attribute holding the reason the message was held for.
+	HoldReasonAttribute string
+	// HistoryAttribute is the name of the attribute holding the audit trail of the message.
+	HistoryAttribute string
+	// ProcessingDeadlineAttribute is the name of the attribute holding the processing deadline of the message.
+	ProcessingDeadlineAttribute string
+	// PayloadSchemaVersionAttribute is the name of the attribute holding the version of the schema of the payload.
+	PayloadSchemaVersionAttribute string
+	// CanaryAttribute is the name of the attribute marking the canary messages sent by SendCanary.
+	CanaryAttribute string
+	// QueueingIndexName is the name of the global secondary index used for queueing.
+	// When it is empty, the name set with WithQueueingIndexName is used.
+	QueueingIndexName string
+
+	// PartitionKeyAttribute is the name of the partition key of the table. By default, it is IDAttribute.
+	// Set it together with SortKeyAttribute to store messages in a table with a composite primary key,
+	// such as an existing single-table design.
+	PartitionKeyAttribute string
+	// PartitionKeyTemplate is the value of the partition key. KeyTemplateID is replaced by the message ID,
+	// so "QUEUE#orders" gives all messages the same partition key and "MSG#{id}" gives each message its own.
+	// By default, it is KeyTemplateID.
+	PartitionKeyTemplate string
+	// SortKeyAttribute is the name of the sort key of the table. It is empty for a table with a simple primary key.
+	SortKeyAttribute string
+	// SortKeyTemplate is the value of the sort key, in the same form as PartitionKeyTemplate.
+	// By default, it is KeyTemplateID.
+	SortKeyTemplate string
+}
+
+// KeyTemplateID is the placeholder replaced by the message ID in PartitionKeyTemplate and SortKeyTemplate.
+const KeyTemplateID = "{id}"
+
+// DefaultTableSchema returns the TableSchema used by DynamoMQ unless configured otherwise.
+func DefaultTableSchema() TableSchema {
+	return TableSchema{
+		IDAttribute:                   AttributeNameID,
+		DataAttribute:                 AttributeNameData,
+		ReceiveCountAttribute:         AttributeNameReceiveCount,
+		QueueTypeAttribute:            AttributeNameQueueType,
+		VersionAttribute:              AttributeNameVersion,
+		CreatedAtAttribute:            AttributeNameCreatedAt,
+		UpdatedAtAttribute:            AttributeNameUpdatedAt,
+		SentAtAttribute:               AttributeNameSentAt,
+		ReceivedAtAttribute:           AttributeNameReceivedAt,
+		InvisibleUntilAtAttribute:     AttributeNameInvisibleUntilAt,
+		ConsumerIDAttribute:           AttributeNameConsumerID,
+		InFlightSlotAttribute:         AttributeNameInFlightSlot,
+		CorrelationIDAttribute:        AttributeNameCorrelationID,
+		TenantIDAttribute:             AttributeNameTenantID,
+		TagsAttribute:                 AttributeNameTags,
+		HeldAttribute:                 AttributeNameHeld,
+		HoldReasonAttribute:           AttributeNameHoldReason,
+		HistoryAttribute:              AttributeNameHistory,
+		ProcessingDeadlineAttribute:   AttributeNameProcessingDeadline,
+		PayloadSchemaVersionAttribute: AttributeNamePayloadSchemaVersion,
+		CanaryAttribute:               AttributeNameCanary,
+		QueueingIndexName:             constant.DefaultQueueingIndexName,
+		PartitionKeyAttribute:         AttributeNameID,
+		PartitionKeyTemplate:          KeyTemplateID,
+	}
+}
+
+func (s TableSchema) withDefaults() TableSchema {
+	d := DefaultTableSchema()
+	for _, f := range []struct {
+		value *string
+		def   string
+	}{
+		{&s.IDAttribute, d.IDAttribute},
+		{&s.DataAttribute, d.DataAttribute},
+		{&s.ReceiveCountAttribute, d.ReceiveCountAttribute},
+		{&s.QueueTypeAttribute, d.QueueTypeAttribute},
+		{&s.VersionAttribute, d.VersionAttribute},
+		{&s.CreatedAtAttribute, d.CreatedAtAttribute},
+		{&s.UpdatedAtAttribute, d.UpdatedAtAttribute},
+		{&s.SentAtAttribute, d.SentAtAttribute},
+		{&s.ReceivedAtAttribute, d.ReceivedAtAttribute},
+		{&s.InvisibleUntilAtAttribute, d.InvisibleUntilAtAttribute},
+		{&s.ConsumerIDAttribute, d.ConsumerIDAttribute},
+		{&s.InFlightSlotAttribute, d.InFlightSlotAttribute},
+		{&s.CorrelationIDAttribute, d.CorrelationIDAttribute},
+		{&s.TenantIDAttribute, d.TenantIDAttribute},
+		{&s.TagsAttribute, d.TagsAttribute},
+		{&s.HeldAttribute, d.HeldAttribute},
+		{&s.HoldReasonAttribute, d.HoldReasonAttribute},
+		{&s.HistoryAttribute, d.HistoryAttribute},
+		{&s.ProcessingDeadlineAttribute, d.ProcessingDeadlineAttribute},
+		{&s.PayloadSchemaVersionAttribute, d.PayloadSchemaVersionAttribute},
+		{&s.CanaryAttribute, d.CanaryAttribute},
+		{&s.QueueingIndexName, d.QueueingIndexName},
+		{&s.PartitionKeyTemplate, KeyTemplateID},
+	} {
+		if *f.value == "" {
+			*f.value = f.def
+		}
+	}
+	if s.PartitionKeyAttribute == "" {
+		s.PartitionKeyAttribute = s.IDAttribute
+	}
+	if s.SortKeyAttribute != "" && s.SortKeyTemplate == "" {
+		s.SortKeyTemplate = KeyTemplateID
+	}
+	return s
+}
+
+// validate reports a TableSchema whose primary key or renamed attributes would overwrite the attributes of a message.
+// It expects a TableSchema with the defaults applied.
+func (s TableSchema) validate() error {
+	seen := map[string]bool{AttributeNameFormatVersion: true}
+	for _, name := range s.messageAttributes() {
+		if seen[name] {
+			return InvalidTableSchemaError{Reason: fmt.Sprintf("attribute '%s' is used for more than one message attribute", name)}
+		}
+		seen[name] = true
+	}
+	if s.SortKeyAttribute != "" && s.SortKeyAttribute == s.PartitionKeyAttribute {
+		return InvalidTableSchemaError{Reason: "partition key and sort key must be different attributes"}
+	}
+	if !strings.Contains(s.PartitionKeyTemplate+s.SortKeyTemplate, KeyTemplateID) {
+		return InvalidTableSchemaError{Reason: fmt.Sprintf("partition key or sort key template must contain %s", KeyTemplateID)}
+	}
+	keys := []struct {
+		attribute, template string
+	}{
+		{s.PartitionKeyAttribute, s.PartitionKeyTemplate},
+		{s.SortKeyAttribute, s.SortKeyTemplate},
+	}
+	for _, key := range keys {
+		if key.attribute == "" {
+			continue
+		}
+		if key.attribute == s.IDAttribute && key.template != KeyTemplateID {
+			return InvalidTableSchemaError{
+				Reason: fmt.Sprintf("key '%s' holds the message ID, so its template must be %s", key.attribute, KeyTemplateID),
+			}
+		}
+		for _, name := range s.messageAttributes() {
+			if key.attribute == name && name != s.IDAttribute {
+				return InvalidTableSchemaError{Reason: fmt.Sprintf("key '%s' collides with a message attribute", key.attribute)}
+			}
+		}
+	}
+	return nil
+}
+
+// messageAttributes returns the names of the attributes of a message that can be renamed.
+func (s TableSchema) messageAttributes() []string {
+	return []string{
+		s.IDAttribute, s.DataAttribute, s.ReceiveCountAttribute, s.QueueTypeAttribute, s.VersionAttribute,
+		s.CreatedAtAttribute, s.UpdatedAtAttribute, s.SentAtAttribute, s.ReceivedAtAttribute, s.InvisibleUntilAtAttribute,
+		s.ConsumerIDAttribute, s.InFlightSlotAttribute, s.CorrelationIDAttribute, s.TenantIDAttribute, s.TagsAttribute,
+		s.HeldAttribute, s.HoldReasonAttribute, s.HistoryAttribute, s.ProcessingDeadlineAttribute,
+		s.PayloadSchemaVersionAttribute, s.CanaryAttribute,
+	}
+}
+
+// key builds the primary key of the item that stores the message with the given ID.
+func (s TableSchema) key(id string) map[string]types.AttributeValue {
+	key := map[string]types.AttributeValue{
+		s.PartitionKeyAttribute: &types.AttributeValueMemberS{
+			Value: strings.ReplaceAll(s.PartitionKeyTemplate, KeyTemplateID, id),
+		},
+	}
+	if s.SortKeyAttribute != "" {
+		key[s.SortKeyAttribute] = &types.AttributeValueMemberS{
+			Value: strings.ReplaceAll(s.SortKeyTemplate, KeyTemplateID, id),
+		}
+	}
+	return key
+}
+
+// renames returns the attribute names that differ from the default names, keyed by the default name.
+func (s TableSchema) renames() map[string]string {
+	d := DefaultTableSchema()
+	pairs := [][2]string{
+		{d.IDAttribute, s.IDAttribute},
+		{d.DataAttribute, s.DataAttribute},
+		{d.ReceiveCountAttribute, s.ReceiveCountAttribute},
+		{d.QueueTypeAttribute, s.QueueTypeAttribute},
+		{d.VersionAttribute, s.VersionAttribute},
+		{d.CreatedAtAttribute, s.CreatedAtAttribute},
+		{d.UpdatedAtAttribute, s.UpdatedAtAttribute},
+		{d.SentAtAttribute, s.SentAtAttribute},
+		{d.ReceivedAtAttribute, s.ReceivedAtAttribute},
+		{d.InvisibleUntilAtAttribute, s.InvisibleUntilAtAttribute},
+		{d.ConsumerIDAttribute, s.ConsumerIDAttribute},
+		{d.InFlightSlotAttribute, s.InFlightSlotAttribute},
+		{d.CorrelationIDAttribute, s.CorrelationIDAttribute},
+		{d.TenantIDAttribute, s.TenantIDAttribute},
+		{d.TagsAttribute, s.TagsAttribute},
+		{d.HeldAttribute, s.HeldAttribute},
+		{d.HoldReasonAttribute, s.HoldReasonAttribute},
+		{d.HistoryAttribute, s.HistoryAttribute},
+		{d.ProcessingDeadlineAttribute, s.ProcessingDeadlineAttribute},
+		{d.PayloadSchemaVersionAttribute, s.PayloadSchemaVersionAttribute},
+		{d.CanaryAttribute, s.CanaryAttribute},
+	}
+	renames := make(map[string]string)
+	for _, p := range pairs {
+		if p[0] != p[1] {
+			renames[p[0]] = p[1]
+		}
+	}
+	return renames
+}
+
+// renameAttributes returns a copy of item whose top-level attributes are renamed according to renames.
+// The item itself is returned when there is nothing to rename.
+func renameAttributes(item map[string]types.AttributeValue, renames map[string]string) map[string]types.AttributeValue {
+	if len(renames) == 0 || item == nil {
+		return item
+	}
+	renamed := make(map[string]types.AttributeValue, len(item))
+	for k, v := range item {
+		if to, ok := renames[k]; ok {
+			k = to
+		}
+		renamed[k] = v
+	}
+	return renamed
+}
+
+func invertRenames(renames map[string]string) map[string]string {
+	inverted := make(map[string]string, len(renames))
+	for from, to := range renames {
+		inverted[to] = from
+	}
+	return inverted
+}
+
+// ValidateSchema describes the table used by the client and verifies that its key schema and its queueing index
+// match what DynamoMQ expects. It returns a SchemaMismatchError listing every difference that was found.
+// A successful validation is cached, so subsequent calls do not call DescribeTable again.
+//
+// The validation requires the dynamodb:DescribeTable permission. It is performed automatically by NewFromConfig
+// when WithSchemaValidation is enabled.
+func (c *ClientImpl[T]) ValidateSchema(ctx context.Context) error {
+	c.schemaMu.Lock()
+	defer c.schemaMu.Unlock()
+	if c.tableDescription != nil {
+		return nil
+	}
+	out, err := c.dynamoDB.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+		TableName: &c.tableName,
+	})
+	if err != nil {
+		return handleDynamoDBError(err)
+	}
+	if problems := checkTableSchema(out.Table, c.schema); len(problems) > 0 {
+		return SchemaMismatchError{
+			TableName: c.tableName,
+			Problems:  problems,
+		}
+	}
+	c.tableDescription = out.Table
+	return nil
+}
+
+func checkTableSchema(table *types.TableDescription, schema TableSchema) []string {
+	if table == nil {
+		return []string{"table description is empty"}
+	}
+	return append(checkTableKey(table, schema), checkQueueingIndex(table, schema)...)
+}
+
+// checkTableKey reports the differences between the primary key of the table and the one of the schema.
+func checkTableKey(table *types.TableDescription, schema TableSchema) []string {
+	return checkKeySchema("table", table.KeySchema, attributeTypesOf(table),
+		schema.PartitionKeyAttribute, schema.SortKeyAttribute)
+}
+
+// checkQueueingIndex reports the differences between the queueing index of the table and the one of the schema.
+func checkQueueingIndex(table *types.TableDescription, schema TableSchema) []string {
+	var index *types.GlobalSecondaryIndexDescription
+	for i := range table.GlobalSecondaryIndexes {
+		gsi := &table.GlobalSecondaryIndexes[i]
+		if gsi.IndexName != nil && *gsi.IndexName == schema.QueueingIndexName {
+			index = gsi
+			break
+		}
+	}
+	if index == nil {
+		return []string{fmt.Sprintf("global secondary index '%s' is missing", schema.QueueingIndexName)}
+	}
+	where := fmt.Sprintf("index '%s'", schema.QueueingIndexName)
+	problems := checkKeySchema(where, index.KeySchema, attributeTypesOf(table),
+		schema.QueueTypeAttribute, schema.SentAtAttribute)
+	if index.Projection == nil || index.Projection.ProjectionType != types.ProjectionTypeAll {
+		problems = append(problems, fmt.Sprintf("%s must project all attributes", where))
+	}
+	return problems
+}
+
+func attributeTypesOf(table *types.TableDescription) map[string]types.ScalarAttributeType {
+	attributeTypes := make(map[string]types.ScalarAttributeType, len(table.AttributeDefinitions))
+	for _, def := range table.AttributeDefinitions {
+		if def.AttributeName != nil {
+			attributeTypes[*def.AttributeName] = def.AttributeType
+		}
+	}
+	return attributeTypes
+}
+
+func checkKeySchema(where string, keySchema []types.KeySchemaElement,
+	attributeTypes map[string]types.ScalarAttributeType, hashKey, rangeKey string) []string {
+	keys := make(map[types.KeyType]string, len(keySchema))
+	for _, key := range keySchema {
+		if key.AttributeName != nil {
+			keys[key.KeyType] = *key.AttributeName
+		}
+	}
+	var problems []string
+	expected := []struct {
+		keyType types.KeyType
+		name    string
+	}{
+		{types.KeyTypeHash, hashKey},
+		{types.KeyTypeRange, rangeKey},
+	}
+	for _, e := range expected {
+		got, ok := keys[e.keyType]
+		switch {
+		case e.name == "" && ok:
+			problems = append(problems, fmt.Sprintf("%s must not have a %s key, found '%s'", where, e.keyType, got))
+		case e.name == "":
+		case !ok:
+			problems = append(problems, fmt.Sprintf("%s is missing the %s key '%s'", where, e.keyType, e.name))
+		case got != e.name:
+			problems = append(problems, fmt.Sprintf("%s has the %s key '%s', want '%s'", where, e.keyType, got, e.name))
+		case attributeTypes[got] != types.ScalarAttributeTypeS:
+			problems = append(problems, fmt.Sprintf("%s key '%s' must be of type S", where, got))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,521 @@
+package dynamomq_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/constant"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func newExpectedTableDescription() *types.TableDescription {
+	return &types.TableDescription{
+		TableName: aws.String(constant.DefaultTableName),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("id"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("queue_type"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("sent_at"), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("id"), KeyType: types.KeyTypeHash},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndexDescription{
+			{
+				IndexName: aws.String(constant.DefaultQueueingIndexName),
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String("queue_type"), KeyType: types.KeyTypeHash},
+					{AttributeName: aws.String("sent_at"), KeyType: types.KeyTypeRange},
+				},
+				Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+			},
+		},
+	}
+}
+
+func TestNewFromConfigWithSchemaValidation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		modify       func(table *types.TableDescription)
+		wantProblems []string
+	}{
+		{
+			name:   "should succeed when the schema matches",
+			modify: func(table *types.TableDescription) {},
+		},
+		{
+			name: "should report a missing index",
+			modify: func(table *types.TableDescription) {
+				table.GlobalSecondaryIndexes = nil
+			},
+			wantProblems: []string{
+				"global secondary index 'dynamo-mq-index-queue_type-sent_at' is missing",
+			},
+		},
+		{
+			name: "should report wrong keys and projection of the index",
+			modify: func(table *types.TableDescription) {
+				table.GlobalSecondaryIndexes[0].KeySchema = []types.KeySchemaElement{
+					{AttributeName: aws.String("queue_type"), KeyType: types.KeyTypeHash},
+					{AttributeName: aws.String("created_at"), KeyType: types.KeyTypeRange},
+				}
+				table.GlobalSecondaryIndexes[0].Projection = &types.Projection{ProjectionType: types.ProjectionTypeKeysOnly}
+			},
+			wantProblems: []string{
+				"index 'dynamo-mq-index-queue_type-sent_at' has the RANGE key 'created_at', want 'sent_at'",
+				"index 'dynamo-mq-index-queue_type-sent_at' must project all attributes",
+			},
+		},
+		{
+			name: "should report a sort key and a wrong key type of the table",
+			modify: func(table *types.TableDescription) {
+				table.AttributeDefinitions[0].AttributeType = types.ScalarAttributeTypeN
+				table.KeySchema = append(table.KeySchema,
+					types.KeySchemaElement{AttributeName: aws.String("created_at"), KeyType: types.KeyTypeRange})
+			},
+			wantProblems: []string{
+				"table key 'id' must be of type S",
+				"table must not have a RANGE key, found 'created_at'",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			table := newExpectedTableDescription()
+			tt.modify(table)
+			_, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+				dynamomq.WithSchemaValidation(true),
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					DescribeTableFunc: func(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+						return &dynamodb.DescribeTableOutput{Table: table}, nil
+					},
+				}))
+			if tt.wantProblems == nil {
+				test.AssertError(t, err, nil, "NewFromConfig()")
+				return
+			}
+			var mismatch dynamomq.SchemaMismatchError
+			if !errors.As(err, &mismatch) {
+				t.Fatalf("NewFromConfig() error = %v, want SchemaMismatchError", err)
+			}
+			test.AssertDeepEqual(t, mismatch.Problems, tt.wantProblems, "SchemaMismatchError.Problems")
+		})
+	}
+}
+
+func TestNewFromConfigSkipsSchemaValidationByDefault(t *testing.T) {
+	t.Parallel()
+	_, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{}))
+	test.AssertError(t, err, nil, "NewFromConfig()")
+}
+
+func TestClientValidateSchemaCachesResult(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithSchemaValidation(true),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			DescribeTableFunc: func(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+				calls.Add(1)
+				return &dynamodb.DescribeTableOutput{Table: newExpectedTableDescription()}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	impl, ok := client.(*dynamomq.ClientImpl[test.MessageData])
+	if !ok {
+		t.Fatalf("NewFromConfig() = %T, want *ClientImpl", client)
+	}
+	if err := impl.ValidateSchema(context.Background()); err != nil {
+		t.Errorf("ValidateSchema() error = %v", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("DescribeTable() calls = %d, want 1", got)
+	}
+}
+
+func TestNewFromConfigSchemaValidationMissingTable(t *testing.T) {
+	t.Parallel()
+	_, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithSchemaValidation(true),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			DescribeTableFunc: func(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+				return nil, &types.ResourceNotFoundException{}
+			},
+		}))
+	var notFound dynamomq.ResourceNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("NewFromConfig() error = %v, want ResourceNotFoundError", err)
+	}
+}
+
+func TestDynamoMQClientWithTableSchema(t *testing.T) {
+	t.Parallel()
+	schema := dynamomq.TableSchema{
+		IDAttribute:        "PK",
+		QueueTypeAttribute: "queueType",
+		SentAtAttribute:    "queueAddTimestamp",
+		VersionAttribute:   "version_number",
+		QueueingIndexName:  "GSI1",
+	}
+	var stored map[string]types.AttributeValue
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithTableSchema(schema),
+		mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+				if _, ok := params.Key["PK"]; !ok {
+					t.Errorf("GetItem() key = %v, want PK", params.Key)
+				}
+				return &dynamodb.GetItemOutput{Item: stored}, nil
+			},
+			PutItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+				stored = params.Item
+				return &dynamodb.PutItemOutput{}, nil
+			},
+			QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+				if got := aws.ToString(params.IndexName); got != "GSI1" {
+					t.Errorf("Query() index = %v, want GSI1", got)
+				}
+				if !containsValue(params.ExpressionAttributeNames, "queueType") {
+					t.Errorf("Query() names = %v, want queueType", params.ExpressionAttributeNames)
+				}
+				return &dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{stored}}, nil
+			},
+			UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+				if !containsValue(params.ExpressionAttributeNames, "version_number") {
+					t.Errorf("UpdateItem() names = %v, want version_number", params.ExpressionAttributeNames)
+				}
+				return &dynamodb.UpdateItemOutput{Attributes: stored}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	ctx := context.Background()
+	sent, err := client.SendMessage(ctx, &dynamomq.SendMessageInput[test.MessageData]{
+		ID:   "A-101",
+		Data: test.NewMessageData("A-101"),
+	})
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	for _, name := range []string{"PK", "queueType", "queueAddTimestamp", "version_number", "receive_count"} {
+		if _, ok := stored[name]; !ok {
+			t.Errorf("PutItem() item has no attribute %s: %v", name, stored)
+		}
+	}
+	for _, name := range []string{"id", "queue_type", "sent_at", "version"} {
+		if _, ok := stored[name]; ok {
+			t.Errorf("PutItem() item has the default attribute %s", name)
+		}
+	}
+	got, err := client.GetMessage(ctx, &dynamomq.GetMessageInput{ID: "A-101"})
+	if err != nil {
+		t.Fatalf("GetMessage() error = %v", err)
+	}
+	test.AssertDeepEqual(t, got.Message, sent.SentMessage, "GetMessage()")
+	received, err := client.ReceiveMessage(ctx, nil)
+	if err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	if received.ReceivedMessage.ID != "A-101" {
+		t.Errorf("ReceiveMessage() id = %v, want A-101", received.ReceivedMessage.ID)
+	}
+}
+
+func TestDynamoMQClientWithTableSchemaSystemAttributes(t *testing.T) {
+	t.Parallel()
+	schema := dynamomq.TableSchema{
+		ConsumerIDAttribute:   "owner",
+		InFlightSlotAttribute: "slot",
+	}
+	counter := &inFlightCounter{}
+	var updated *dynamodb.UpdateItemInput
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithTableSchema(schema),
+		dynamomq.WithMaxInFlight(2),
+		dynamomq.WithConsumerID("worker-1"),
+		mock.WithClock(mock.Clock{T: test.DefaultTestDate.Add(time.Minute)}),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+				return &dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{
+					dynamomqtest.MarshalMap(NewTestMessageItemAsReady("A-101", test.DefaultTestDate)),
+				}}, nil
+			},
+			UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+				if params.Key[dynamomq.AttributeNameID].(*types.AttributeValueMemberS).Value != "A-101" {
+					return counter.update(params)
+				}
+				updated = params
+				received := dynamomqtest.MarshalMap(NewTestMessageItemAsProcessing("A-101", test.DefaultTestDate.Add(time.Minute)))
+				received["owner"] = &types.AttributeValueMemberS{Value: "worker-1"}
+				received["slot"] = &types.AttributeValueMemberBOOL{Value: true}
+				return &dynamodb.UpdateItemOutput{Attributes: received}, nil
+			},
+			DeleteItemFunc: func(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+				return &dynamodb.DeleteItemOutput{Attributes: map[string]types.AttributeValue{
+					"slot": &types.AttributeValueMemberBOOL{Value: true},
+				}}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	ctx := context.Background()
+	out, err := client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{})
+	if err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	if !out.ReceivedMessage.InFlightSlot || out.ReceivedMessage.ConsumerID != "worker-1" {
+		t.Errorf("ReceiveMessage() = %+v, want the slot and the consumer ID read from the renamed attributes", out.ReceivedMessage)
+	}
+	for _, name := range []string{"owner", "slot"} {
+		if !containsValue(updated.ExpressionAttributeNames, name) {
+			t.Errorf("UpdateItem() names = %v, want %s", updated.ExpressionAttributeNames, name)
+		}
+	}
+	for _, name := range []string{dynamomq.AttributeNameConsumerID, dynamomq.AttributeNameInFlightSlot} {
+		if containsValue(updated.ExpressionAttributeNames, name) {
+			t.Errorf("UpdateItem() names = %v, want no default attribute %s", updated.ExpressionAttributeNames, name)
+		}
+	}
+	if _, err := client.DeleteMessage(ctx, &dynamomq.DeleteMessageInput{ID: "A-101"}); err != nil {
+		t.Fatalf("DeleteMessage() error = %v", err)
+	}
+	if counter.count != 0 {
+		t.Errorf("counter = %d, want the slot released", counter.count)
+	}
+}
+
+func TestDynamoMQClientWithTableSchemaMessageAttributes(t *testing.T) {
+	t.Parallel()
+	schema := dynamomq.TableSchema{
+		CorrelationIDAttribute:        "correlationId",
+		TenantIDAttribute:             "tenantId",
+		TagsAttribute:                 "labels",
+		HeldAttribute:                 "onHold",
+		HoldReasonAttribute:           "onHoldReason",
+		HistoryAttribute:              "auditTrail",
+		ProcessingDeadlineAttribute:   "deadlineSeconds",
+		PayloadSchemaVersionAttribute: "payloadVersion",
+	}
+	var (
+		stored  map[string]types.AttributeValue
+		updated *dynamodb.UpdateItemInput
+		scanned *dynamodb.ScanInput
+	)
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithTableSchema(schema),
+		dynamomq.WithAuditTrail(true),
+		mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+				return &dynamodb.GetItemOutput{Item: stored}, nil
+			},
+			PutItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+				stored = params.Item
+				return &dynamodb.PutItemOutput{}, nil
+			},
+			UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+				updated = params
+				return &dynamodb.UpdateItemOutput{Attributes: stored}, nil
+			},
+			ScanFunc: func(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+				scanned = params
+				return &dynamodb.ScanOutput{}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	ctx := context.Background()
+	sent, err := client.SendMessage(ctx, &dynamomq.SendMessageInput[test.MessageData]{
+		ID:                   "A-101",
+		Data:                 test.NewMessageData("A-101"),
+		CorrelationID:        "order-1",
+		TenantID:             "acme",
+		Tags:                 []string{"backfill"},
+		ProcessingDeadline:   time.Minute,
+		PayloadSchemaVersion: 2,
+	})
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	for _, name := range []string{"correlationId", "tenantId", "labels", "auditTrail", "deadlineSeconds", "payloadVersion"} {
+		if _, ok := stored[name]; !ok {
+			t.Errorf("PutItem() item has no attribute %s: %v", name, stored)
+		}
+	}
+	for _, name := range []string{
+		dynamomq.AttributeNameCorrelationID, dynamomq.AttributeNameTenantID, dynamomq.AttributeNameTags,
+		dynamomq.AttributeNameHistory, dynamomq.AttributeNameProcessingDeadline, dynamomq.AttributeNamePayloadSchemaVersion,
+	} {
+		if _, ok := stored[name]; ok {
+			t.Errorf("PutItem() item has the default attribute %s", name)
+		}
+	}
+	got, err := client.GetMessage(ctx, &dynamomq.GetMessageInput{ID: "A-101"})
+	if err != nil {
+		t.Fatalf("GetMessage() error = %v", err)
+	}
+	test.AssertDeepEqual(t, got.Message, sent.SentMessage, "GetMessage()")
+	if _, err := client.HoldMessage(ctx, &dynamomq.HoldMessageInput{ID: "A-101", Reason: "investigating"}); err != nil {
+		t.Fatalf("HoldMessage() error = %v", err)
+	}
+	for _, name := range []string{"onHold", "onHoldReason", "auditTrail"} {
+		if !containsValue(updated.ExpressionAttributeNames, name) {
+			t.Errorf("UpdateItem() names = %v, want %s", updated.ExpressionAttributeNames, name)
+		}
+	}
+	if _, err := client.ListMessages(ctx, &dynamomq.ListMessagesInput{Tag: "backfill"}); err != nil {
+		t.Fatalf("ListMessages() error = %v", err)
+	}
+	if !containsValue(scanned.ExpressionAttributeNames, "labels") || containsValue(scanned.ExpressionAttributeNames, dynamomq.AttributeNameTags) {
+		t.Errorf("Scan() names = %v, want the tags filtered on labels", scanned.ExpressionAttributeNames)
+	}
+}
+
+func containsValue(m map[string]string, value string) bool {
+	for _, v := range m {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDynamoMQClientWithCompositePrimaryKey(t *testing.T) {
+	t.Parallel()
+	schema := dynamomq.TableSchema{
+		PartitionKeyAttribute: "PK",
+		PartitionKeyTemplate:  "QUEUE#orders",
+		SortKeyAttribute:      "SK",
+		SortKeyTemplate:       "MSG#{id}",
+	}
+	wantKey := map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: "QUEUE#orders"},
+		"SK": &types.AttributeValueMemberS{Value: "MSG#A-101"},
+	}
+	var (
+		stored map[string]types.AttributeValue
+		keys   []map[string]types.AttributeValue
+	)
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithTableSchema(schema),
+		mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+				keys = append(keys, params.Key)
+				return &dynamodb.GetItemOutput{Item: stored}, nil
+			},
+			PutItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+				stored = params.Item
+				return &dynamodb.PutItemOutput{}, nil
+			},
+			UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+				keys = append(keys, params.Key)
+				return &dynamodb.UpdateItemOutput{Attributes: stored}, nil
+			},
+			DeleteItemFunc: func(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+				keys = append(keys, params.Key)
+				return &dynamodb.DeleteItemOutput{}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	ctx := context.Background()
+	if _, err := client.SendMessage(ctx, &dynamomq.SendMessageInput[test.MessageData]{
+		ID:   "A-101",
+		Data: test.NewMessageData("A-101"),
+	}); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	test.AssertDeepEqual(t, stored["PK"], wantKey["PK"], "PutItem() PK")
+	test.AssertDeepEqual(t, stored["SK"], wantKey["SK"], "PutItem() SK")
+	test.AssertDeepEqual(t, stored["id"], &types.AttributeValueMemberS{Value: "A-101"}, "PutItem() id")
+	got, err := client.GetMessage(ctx, &dynamomq.GetMessageInput{ID: "A-101"})
+	if err != nil {
+		t.Fatalf("GetMessage() error = %v", err)
+	}
+	if got.Message.ID != "A-101" {
+		t.Errorf("GetMessage() id = %v, want A-101", got.Message.ID)
+	}
+	if _, err := client.ChangeMessageVisibility(ctx, &dynamomq.ChangeMessageVisibilityInput{ID: "A-101"}); err != nil {
+		t.Fatalf("ChangeMessageVisibility() error = %v", err)
+	}
+	if _, err := client.DeleteMessage(ctx, &dynamomq.DeleteMessageInput{ID: "A-101"}); err != nil {
+		t.Fatalf("DeleteMessage() error = %v", err)
+	}
+	for i, key := range keys {
+		test.AssertDeepEqual(t, key, wantKey, fmt.Sprintf("key of call %d", i))
+	}
+}
+
+func TestNewFromConfigRejectsInvalidTableSchema(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		schema dynamomq.TableSchema
+	}{
+		{
+			name:   "template of the ID attribute",
+			schema: dynamomq.TableSchema{PartitionKeyTemplate: "MSG#{id}"},
+		},
+		{
+			name:   "same partition key and sort key",
+			schema: dynamomq.TableSchema{PartitionKeyAttribute: "PK", SortKeyAttribute: "PK"},
+		},
+		{
+			name:   "key colliding with a message attribute",
+			schema: dynamomq.TableSchema{PartitionKeyAttribute: "PK", SortKeyAttribute: "sent_at"},
+		},
+		{
+			name:   "key colliding with a system attribute",
+			schema: dynamomq.TableSchema{PartitionKeyAttribute: "PK", SortKeyAttribute: "consumer_id"},
+		},
+		{
+			name:   "renamed attribute colliding with another message attribute",
+			schema: dynamomq.TableSchema{TagsAttribute: "data"},
+		},
+		{
+			name:   "renamed attribute colliding with the format version",
+			schema: dynamomq.TableSchema{HeldAttribute: dynamomq.AttributeNameFormatVersion},
+		},
+		{
+			name: "templates without the message ID",
+			schema: dynamomq.TableSchema{
+				PartitionKeyAttribute: "PK", PartitionKeyTemplate: "QUEUE",
+				SortKeyAttribute: "SK", SortKeyTemplate: "MSG",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+				dynamomq.WithTableSchema(tt.schema),
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{}))
+			var invalid dynamomq.InvalidTableSchemaError
+			if !errors.As(err, &invalid) {
+				t.Errorf("NewFromConfig() error = %v, want InvalidTableSchemaError", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package dynamomq_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestDynamoMQClientReceiveMessageWithSkip(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	tests := []struct {
+		name string
+		opts []func(*dynamomq.ClientOptions)
+		// skips are the Skip of the receives made in turn.
+		skips   []int
+		wantIDs []string
+		wantErr error
+	}{
+		{
+			name:    "should receive the first message without a skip",
+			skips:   []int{0},
+			wantIDs: []string{"A-000"},
+		},
+		{
+			name:    "should pass over the messages that could be received",
+			skips:   []int{2},
+			wantIDs: []string{"A-003"},
+		},
+		{
+			name:    "should pass over the messages across pages",
+			skips:   []int{7},
+			wantIDs: []string{"A-011"},
+		},
+		{
+			name:    "should leave the skipped messages to later receives",
+			skips:   []int{1, 1, 0},
+			wantIDs: []string{"A-002", "A-003", "A-000"},
+		},
+		{
+			name:    "should receive the first message with a negative skip",
+			skips:   []int{-1},
+			wantIDs: []string{"A-000"},
+		},
+		{
+			name:    "should return EmptyQueueError when the skip is out of range",
+			skips:   []int{10},
+			wantErr: dynamomq.ErrEmptyQueue,
+		},
+		{
+			name:    "should return EmptyQueueError when skipping in a FIFO queue",
+			opts:    []func(*dynamomq.ClientOptions){dynamomq.WithUseFIFO(true)},
+			skips:   []int{1},
+			wantErr: dynamomq.ErrEmptyQueue,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// Every third message is being processed, so the ten others can be received, in the order of their IDs.
+			queue := &pagedQueue{}
+			for i := 0; i < 15; i++ {
+				id := fmt.Sprintf("A-%03d", i)
+				m := NewTestMessageItemAsReady(id, test.DefaultTestDate.Add(time.Duration(i)*time.Second))
+				if i%3 == 1 {
+					m = NewTestMessageItemAsProcessing(id, now)
+				}
+				queue.items = append(queue.items, dynamomqtest.MarshalMap(m))
+			}
+			client := newPagedQueueClient(t, queue, now, tt.opts...)
+			var ids []string
+			for _, skip := range tt.skips {
+				out, err := client.ReceiveMessage(context.Background(), &dynamomq.ReceiveMessageInput{Skip: skip})
+				test.AssertError(t, err, tt.wantErr, "ReceiveMessage()")
+				if err != nil {
+					return
+				}
+				ids = append(ids, out.ReceivedMessage.ID)
+			}
+			test.AssertDeepEqual(t, ids, tt.wantIDs, "ReceiveMessage() IDs")
+		})
+	}
+}
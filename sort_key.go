@@ -0,0 +1,60 @@
+package dynamomq
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// sentAtTieBreakerSeparator separates the timestamp of SentAt from the tie-breaker appended with WithSortKeyTieBreaker.
+// It sorts after the digits of the timestamp, so the messages sent at the same time sort by their tie-breaker.
+const sentAtTieBreakerSeparator = "#"
+
+// sortKeyTimestampLayout formats the timestamps of SentAt with a tie-breaker. Unlike time.RFC3339Nano, it keeps
+// the trailing zeros of the fractional second, so that the timestamps of a second sort lexically in time order.
+const sortKeyTimestampLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+var (
+	// tieBreakerSequence is incremented for each tie-breaker, so that the messages sent by the process at the same
+	// time sort in the order they were sent.
+	tieBreakerSequence atomic.Uint64
+	// tieBreakerNode tells apart the tie-breakers of the processes sending messages at the same time.
+	tieBreakerNode = newTieBreakerNode()
+)
+
+func newTieBreakerNode() uint32 {
+	var b [4]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return uint32(time.Now().UnixNano())
+	}
+	return binary.BigEndian.Uint32(b[:])
+}
+
+// sentAtWithTieBreaker returns the timestamp formatted for the sort key of the queueing index, followed by
+// a tie-breaker made of a sequence number of the process and an identifier of the process.
+func sentAtWithTieBreaker(t time.Time) string {
+	return fmt.Sprintf("%s%s%016x%08x", t.UTC().Format(sortKeyTimestampLayout), sentAtTieBreakerSeparator,
+		tieBreakerSequence.Add(1), tieBreakerNode)
+}
+
+// sentAtTimestamp returns the timestamp of SentAt without its tie-breaker, if any.
+func sentAtTimestamp(sentAt string) string {
+	timestamp, _, _ := strings.Cut(sentAt, sentAtTieBreakerSeparator)
+	return timestamp
+}
+
+// breakSentAtTie appends a tie-breaker to the SentAt of the message when the client is configured
+// with WithSortKeyTieBreaker.
+func (c *ClientImpl[T]) breakSentAtTie(message *Message[T]) {
+	if !c.sortKeyTieBreaker {
+		return
+	}
+	sentAt, err := message.ParsedSentAt()
+	if err != nil {
+		return
+	}
+	message.SentAt = sentAtWithTieBreaker(sentAt)
+}
@@ -0,0 +1,88 @@
+package dynamomq_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+// sendBurst sends size messages at the same time and returns the SentAt written for each of them, in send order.
+func sendBurst(t *testing.T, size int, opts ...func(*dynamomq.ClientOptions)) []string {
+	t.Helper()
+	var sentAts []string
+	opts = append(opts,
+		mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+				return &dynamodb.GetItemOutput{}, nil
+			},
+			PutItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+				sentAts = append(sentAts, params.Item[dynamomq.AttributeNameSentAt].(*types.AttributeValueMemberS).Value)
+				return &dynamodb.PutItemOutput{}, nil
+			},
+		}))
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{}, opts...)
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	for i := 0; i < size; i++ {
+		id := fmt.Sprintf("A-%03d", i)
+		if _, err := client.SendMessage(context.Background(), &dynamomq.SendMessageInput[test.MessageData]{
+			ID:   id,
+			Data: test.NewMessageData(id),
+		}); err != nil {
+			t.Fatalf("SendMessage() error = %v", err)
+		}
+	}
+	return sentAts
+}
+
+func TestDynamoMQClientSortKeyTieBreaker(t *testing.T) {
+	t.Parallel()
+	sentAts := sendBurst(t, 100, dynamomq.WithSortKeyTieBreaker(true))
+	for i := 1; i < len(sentAts); i++ {
+		if sentAts[i-1] >= sentAts[i] {
+			t.Fatalf("SentAt of message %d = %s, want after %s", i, sentAts[i], sentAts[i-1])
+		}
+	}
+	message := NewTestMessageItemAsReady("A-000", test.DefaultTestDate)
+	message.SentAt = sentAts[0]
+	sentAt, err := message.ParsedSentAt()
+	if err != nil || !sentAt.Equal(test.DefaultTestDate) {
+		t.Errorf("ParsedSentAt() = %v, %v, want %v", sentAt, err, test.DefaultTestDate)
+	}
+	visibleAt, err := message.VisibleAt()
+	if err != nil || !visibleAt.Equal(test.DefaultTestDate) {
+		t.Errorf("VisibleAt() = %v, %v, want %v", visibleAt, err, test.DefaultTestDate)
+	}
+}
+
+func TestDynamoMQClientSortKeyTieBreakerDisabled(t *testing.T) {
+	t.Parallel()
+	for _, sentAt := range sendBurst(t, 2) {
+		if want := clock.FormatRFC3339Nano(test.DefaultTestDate); sentAt != want {
+			t.Errorf("SentAt = %s, want %s", sentAt, want)
+		}
+	}
+}
+
+func TestSortKeyTieBreakerSortsAmongMessagesWithout(t *testing.T) {
+	t.Parallel()
+	before := clock.FormatRFC3339Nano(test.DefaultTestDate.Add(-time.Second))
+	after := clock.FormatRFC3339Nano(test.DefaultTestDate.Add(time.Second))
+	sentAts := append(sendBurst(t, 2, dynamomq.WithSortKeyTieBreaker(true)), after, before)
+	sort.Strings(sentAts)
+	if sentAts[0] != before || sentAts[3] != after {
+		t.Errorf("sorted SentAt = %v, want %s first and %s last", sentAts, before, after)
+	}
+}
@@ -0,0 +1,61 @@
+package dynamomq
+
+import (
+	"time"
+)
+
+// cachedQueueStats is the statistics of a queue type read by GetQueueStats, kept until they expire.
+type cachedQueueStats struct {
+	stats     *GetQueueStatsOutput
+	expiresAt time.Time
+}
+
+// cachedQueueStats returns a copy of the cached statistics of the queue type unless they have expired.
+func (c *ClientImpl[T]) cachedQueueStats(queueType QueueType) (*GetQueueStatsOutput, bool) {
+	if c.statsCacheTTL <= 0 {
+		return nil, false
+	}
+	c.statsCacheMu.Lock()
+	defer c.statsCacheMu.Unlock()
+	cached, ok := c.statsCache[queueType]
+	if !ok || !c.clock.Now().Before(cached.expiresAt) {
+		return nil, false
+	}
+	return copyQueueStats(cached.stats), true
+}
+
+// cacheQueueStats keeps a copy of the statistics of the queue type, so that callers modifying
+// the statistics they were returned do not change the ones returned to the next callers.
+func (c *ClientImpl[T]) cacheQueueStats(queueType QueueType, stats *GetQueueStatsOutput) {
+	if c.statsCacheTTL <= 0 {
+		return
+	}
+	c.statsCacheMu.Lock()
+	defer c.statsCacheMu.Unlock()
+	if c.statsCache == nil {
+		c.statsCache = make(map[QueueType]cachedQueueStats)
+	}
+	c.statsCache[queueType] = cachedQueueStats{
+		stats:     copyQueueStats(stats),
+		expiresAt: c.clock.Now().Add(c.statsCacheTTL),
+	}
+}
+
+func copyQueueStats(stats *GetQueueStatsOutput) *GetQueueStatsOutput {
+	copied := *stats
+	copied.First100IDsInQueue = append([]string{}, stats.First100IDsInQueue...)
+	copied.First100IDsInQueueProcessing = append([]string{}, stats.First100IDsInQueueProcessing...)
+	if stats.ConsumerIDsInQueueProcessing != nil {
+		copied.ConsumerIDsInQueueProcessing = make(map[string]string, len(stats.ConsumerIDsInQueueProcessing))
+		for id, consumerID := range stats.ConsumerIDsInQueueProcessing {
+			copied.ConsumerIDsInQueueProcessing[id] = consumerID
+		}
+	}
+	if stats.TotalMessagesByStatus != nil {
+		copied.TotalMessagesByStatus = make(map[Status]int, len(stats.TotalMessagesByStatus))
+		for status, n := range stats.TotalMessagesByStatus {
+			copied.TotalMessagesByStatus[status] = n
+		}
+	}
+	return &copied
+}
@@ -0,0 +1,102 @@
+package dynamomq_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestDynamoMQClientStatsCache(t *testing.T) {
+	t.Parallel()
+	clock := &steppingClock{now: test.DefaultTestDate}
+	var queries atomic.Int32
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithStatsCache(10*time.Second),
+		mock.WithClock(clock),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+				queries.Add(1)
+				return &dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{
+					dynamomqtest.MarshalMap(NewTestMessageItemAsReady("A-101", test.DefaultTestDate)),
+				}}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	ctx := context.Background()
+	getStats := func(params *dynamomq.GetQueueStatsInput, wantQueries int32) *dynamomq.GetQueueStatsOutput {
+		t.Helper()
+		out, err := client.GetQueueStats(ctx, params)
+		if err != nil {
+			t.Fatalf("GetQueueStats() error = %v", err)
+		}
+		if got := queries.Load(); got != wantQueries {
+			t.Errorf("queries = %d, want %d", got, wantQueries)
+		}
+		return out
+	}
+
+	first := getStats(&dynamomq.GetQueueStatsInput{}, 1)
+	first.First100IDsInQueue[0] = "modified"
+	clock.Advance(10*time.Second - time.Nanosecond)
+	cached := getStats(&dynamomq.GetQueueStatsInput{}, 1)
+	test.AssertDeepEqual(t, cached.First100IDsInQueue, []string{"A-101"}, "cached IDs")
+
+	getStats(&dynamomq.GetQueueStatsInput{QueueType: dynamomq.QueueTypeDLQ}, 2)
+	getStats(&dynamomq.GetQueueStatsInput{ForceRefresh: true}, 3)
+	clock.Advance(10*time.Second - time.Nanosecond)
+	getStats(&dynamomq.GetQueueStatsInput{}, 3)
+	clock.Advance(time.Nanosecond)
+	getStats(&dynamomq.GetQueueStatsInput{}, 4)
+	getStats(&dynamomq.GetQueueStatsInput{QueueType: dynamomq.QueueTypeDLQ}, 5)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.GetQueueStats(ctx, &dynamomq.GetQueueStatsInput{}); err != nil {
+				t.Errorf("GetQueueStats() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := queries.Load(); got != 5 {
+		t.Errorf("queries = %d, want 5", got)
+	}
+}
+
+func TestDynamoMQClientStatsCacheDisabled(t *testing.T) {
+	t.Parallel()
+	var queries atomic.Int32
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+				queries.Add(1)
+				return &dynamodb.QueryOutput{}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetQueueStats(context.Background(), &dynamomq.GetQueueStatsInput{}); err != nil {
+			t.Fatalf("GetQueueStats() error = %v", err)
+		}
+	}
+	if got := queries.Load(); got != 2 {
+		t.Errorf("queries = %d, want 2", got)
+	}
+}
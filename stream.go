@@ -0,0 +1,381 @@
+package dynamomq
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	streamtypes "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+)
+
+const (
+	defaultStreamPollingInterval  = 500 * time.Millisecond
+	defaultShardDiscoveryInterval = time.Minute
+	defaultStreamRetryInterval    = 5 * time.Second
+)
+
+// ErrStreamNotifierClosed is an error that indicates the StreamNotifier has been closed.
+var ErrStreamNotifierClosed = errors.New("DynamoMQ: StreamNotifier closed")
+
+// StreamsAPI is the subset of the Amazon DynamoDB Streams API used by StreamNotifier.
+// *dynamodbstreams.Client satisfies this interface.
+type StreamsAPI interface {
+	DescribeStream(ctx context.Context, params *dynamodbstreams.DescribeStreamInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.DescribeStreamOutput, error)
+	GetShardIterator(ctx context.Context, params *dynamodbstreams.GetShardIteratorInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetShardIteratorOutput, error)
+	GetRecords(ctx context.Context, params *dynamodbstreams.GetRecordsInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetRecordsOutput, error)
+}
+
+// Waker is implemented by components that can be told to poll the queue immediately. Consumer implements it.
+type Waker interface {
+	Wake()
+}
+
+// StreamNotifierOptions contains configuration options for a StreamNotifier instance.
+type StreamNotifierOptions struct {
+	// PollingInterval is the time to wait before reading a shard again after it returned no records.
+	PollingInterval time.Duration
+	// ShardDiscoveryInterval is the time interval at which the stream is described to find new shards.
+	ShardDiscoveryInterval time.Duration
+	// RetryInterval is the time to wait before retrying after the stream could not be read.
+	RetryInterval time.Duration
+	// QueueType is the type of queue whose new messages wake the Consumer.
+	QueueType QueueType
+	// TableSchema defines the attribute names of the table. It must match the TableSchema given to the client.
+	TableSchema TableSchema
+	// ErrorLog is an optional logger for errors. If nil, the standard logger is used.
+	ErrorLog *log.Logger
+}
+
+// WithStreamPollingInterval sets the time to wait before reading a shard again after it returned no records.
+func WithStreamPollingInterval(pollingInterval time.Duration) func(o *StreamNotifierOptions) {
+	return func(o *StreamNotifierOptions) {
+		o.PollingInterval = pollingInterval
+	}
+}
+
+// WithShardDiscoveryInterval sets the time interval at which the stream is described to find new shards.
+func WithShardDiscoveryInterval(interval time.Duration) func(o *StreamNotifierOptions) {
+	return func(o *StreamNotifierOptions) {
+		o.ShardDiscoveryInterval = interval
+	}
+}
+
+// WithStreamRetryInterval sets the time to wait before retrying after the stream could not be read.
+func WithStreamRetryInterval(retryInterval time.Duration) func(o *StreamNotifierOptions) {
+	return func(o *StreamNotifierOptions) {
+		o.RetryInterval = retryInterval
+	}
+}
+
+// WithStreamQueueType sets the type of queue whose new messages wake the Consumer.
+func WithStreamQueueType(queueType QueueType) func(o *StreamNotifierOptions) {
+	return func(o *StreamNotifierOptions) {
+		o.QueueType = queueType
+	}
+}
+
+// WithStreamTableSchema sets the attribute names used to recognize messages in stream records.
+func WithStreamTableSchema(schema TableSchema) func(o *StreamNotifierOptions) {
+	return func(o *StreamNotifierOptions) {
+		o.TableSchema = schema
+	}
+}
+
+// WithStreamErrorLog sets a custom logger for the StreamNotifier.
+func WithStreamErrorLog(errorLog *log.Logger) func(o *StreamNotifierOptions) {
+	return func(o *StreamNotifierOptions) {
+		o.ErrorLog = errorLog
+	}
+}
+
+// NewStreamNotifier creates a new StreamNotifier that reads the DynamoDB Stream identified by streamARN
+// and wakes the given Waker, usually a Consumer, whenever a READY message is written to the queue.
+// The stream must be enabled on the table with the NEW_IMAGE or NEW_AND_OLD_IMAGES view type;
+// with KEYS_ONLY, every inserted item wakes the Waker.
+func NewStreamNotifier(api StreamsAPI, streamARN string, waker Waker, opts ...func(o *StreamNotifierOptions)) *StreamNotifier {
+	o := &StreamNotifierOptions{
+		PollingInterval:        defaultStreamPollingInterval,
+		ShardDiscoveryInterval: defaultShardDiscoveryInterval,
+		RetryInterval:          defaultStreamRetryInterval,
+		QueueType:              defaultQueueType,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &StreamNotifier{
+		api:                    api,
+		streamARN:              streamARN,
+		waker:                  waker,
+		pollingInterval:        o.PollingInterval,
+		shardDiscoveryInterval: o.ShardDiscoveryInterval,
+		retryInterval:          o.RetryInterval,
+		queueType:              o.QueueType,
+		schema:                 o.TableSchema.withDefaults(),
+		errorLog:               o.ErrorLog,
+		shards:                 make(map[string]shardState),
+		checkpoints:            make(map[string]string),
+		doneChan:               make(chan struct{}),
+	}
+}
+
+type shardState int
+
+const (
+	shardReading shardState = iota + 1
+	shardClosed
+)
+
+// StreamNotifier reads the DynamoDB Stream of a queue table and wakes a Consumer as soon as a new message is ready,
+// which cuts the pickup latency below the polling interval without spending read capacity on empty queues.
+// It is an optimization only: the Consumer keeps polling at its normal interval, so messages are still received
+// when the stream is unavailable, and while the StreamNotifier retries it only logs errors.
+// Each shard is read by its own goroutine, and the last sequence number read from each shard is checkpointed
+// in memory so that a shard whose iterator expired is resumed where it stopped.
+// Note: To create a new instance of StreamNotifier, it is necessary to use the NewStreamNotifier function.
+type StreamNotifier struct {
+	api                    StreamsAPI
+	streamARN              string
+	waker                  Waker
+	pollingInterval        time.Duration
+	shardDiscoveryInterval time.Duration
+	retryInterval          time.Duration
+	queueType              QueueType
+	schema                 TableSchema
+	errorLog               *log.Logger
+
+	inShutdown  int32
+	mu          sync.Mutex
+	shards      map[string]shardState
+	checkpoints map[string]string
+	readersWG   sync.WaitGroup
+	doneChan    chan struct{}
+}
+
+// StartNotifying starts reading the stream and blocks until the StreamNotifier is shut down,
+// at which point it returns ErrStreamNotifierClosed. Shards that are open when it starts are read from their latest
+// record, and shards created afterwards are read from their beginning.
+func (n *StreamNotifier) StartNotifying() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-n.doneChan
+		cancel()
+	}()
+	initial := true
+	for {
+		interval := n.shardDiscoveryInterval
+		if err := n.discoverShards(ctx, initial); err != nil {
+			if n.shuttingDown() {
+				return ErrStreamNotifierClosed
+			}
+			n.logf("DynamoMQ: Failed to describe the stream, falling back to polling. %s", err)
+			interval = n.retryInterval
+		} else {
+			initial = false
+		}
+		if !sleepContext(ctx, interval) {
+			return ErrStreamNotifierClosed
+		}
+	}
+}
+
+// Checkpoints returns the last sequence number read from each shard, keyed by shard ID.
+func (n *StreamNotifier) Checkpoints() map[string]string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	checkpoints := make(map[string]string, len(n.checkpoints))
+	for shardID, sequenceNumber := range n.checkpoints {
+		checkpoints[shardID] = sequenceNumber
+	}
+	return checkpoints
+}
+
+// Shutdown stops reading the stream and waits until every shard reader has returned or the context is done.
+func (n *StreamNotifier) Shutdown(ctx context.Context) error {
+	atomic.StoreInt32(&n.inShutdown, 1)
+
+	n.mu.Lock()
+	select {
+	case <-n.doneChan:
+	default:
+		close(n.doneChan)
+	}
+	n.mu.Unlock()
+
+	finished := make(chan struct{}, 1)
+	go func() {
+		n.readersWG.Wait()
+		finished <- struct{}{}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-finished:
+		return nil
+	}
+}
+
+func (n *StreamNotifier) discoverShards(ctx context.Context, initial bool) error {
+	var exclusiveStartShardID *string
+	for {
+		out, err := n.api.DescribeStream(ctx, &dynamodbstreams.DescribeStreamInput{
+			StreamArn:             aws.String(n.streamARN),
+			ExclusiveStartShardId: exclusiveStartShardID,
+		})
+		if err != nil {
+			return err
+		}
+		if out.StreamDescription == nil {
+			return nil
+		}
+		for _, shard := range out.StreamDescription.Shards {
+			n.startShardReader(ctx, shard, initial)
+		}
+		exclusiveStartShardID = out.StreamDescription.LastEvaluatedShardId
+		if exclusiveStartShardID == nil {
+			return nil
+		}
+	}
+}
+
+func (n *StreamNotifier) startShardReader(ctx context.Context, shard streamtypes.Shard, initial bool) {
+	shardID := aws.ToString(shard.ShardId)
+	closed := shard.SequenceNumberRange != nil && shard.SequenceNumberRange.EndingSequenceNumber != nil
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.shuttingDown() {
+		return
+	}
+	if _, ok := n.shards[shardID]; ok {
+		return
+	}
+	_, checkpointed := n.checkpoints[shardID]
+	if initial && closed && !checkpointed {
+		// Records of shards that were closed before the notifier started are too old to be worth a wake.
+		n.shards[shardID] = shardClosed
+		return
+	}
+	iteratorType := streamtypes.ShardIteratorTypeTrimHorizon
+	if initial {
+		iteratorType = streamtypes.ShardIteratorTypeLatest
+	}
+	n.shards[shardID] = shardReading
+	n.readersWG.Add(1)
+	go n.readShard(ctx, shardID, iteratorType)
+}
+
+func (n *StreamNotifier) readShard(ctx context.Context, shardID string, iteratorType streamtypes.ShardIteratorType) {
+	defer n.readersWG.Done()
+	iterator, err := n.shardIterator(ctx, shardID, iteratorType)
+	for err == nil && iterator != nil {
+		var out *dynamodbstreams.GetRecordsOutput
+		out, err = n.api.GetRecords(ctx, &dynamodbstreams.GetRecordsInput{
+			ShardIterator: iterator,
+		})
+		if err != nil {
+			var expired *streamtypes.ExpiredIteratorException
+			if errors.As(err, &expired) {
+				iterator, err = n.shardIterator(ctx, shardID, iteratorType)
+			}
+			continue
+		}
+		n.handleRecords(shardID, out.Records)
+		iterator = out.NextShardIterator
+		if len(out.Records) == 0 && !sleepContext(ctx, n.pollingInterval) {
+			break
+		}
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	switch {
+	case iterator == nil && err == nil:
+		n.shards[shardID] = shardClosed
+	default:
+		// Forget the shard so that the next discovery starts a new reader from the checkpoint.
+		delete(n.shards, shardID)
+		if err != nil && !n.shuttingDown() {
+			n.logf("DynamoMQ: Failed to read shard %s of the stream. %s", shardID, err)
+		}
+	}
+}
+
+func (n *StreamNotifier) shardIterator(ctx context.Context,
+	shardID string, iteratorType streamtypes.ShardIteratorType) (*string, error) {
+	in := &dynamodbstreams.GetShardIteratorInput{
+		StreamArn:         aws.String(n.streamARN),
+		ShardId:           aws.String(shardID),
+		ShardIteratorType: iteratorType,
+	}
+	n.mu.Lock()
+	if sequenceNumber, ok := n.checkpoints[shardID]; ok {
+		in.ShardIteratorType = streamtypes.ShardIteratorTypeAfterSequenceNumber
+		in.SequenceNumber = aws.String(sequenceNumber)
+	}
+	n.mu.Unlock()
+	out, err := n.api.GetShardIterator(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out.ShardIterator, nil
+}
+
+func (n *StreamNotifier) handleRecords(shardID string, records []streamtypes.Record) {
+	if len(records) == 0 {
+		return
+	}
+	wake := false
+	for _, record := range records {
+		if n.isReadyMessage(record) {
+			wake = true
+		}
+	}
+	if last := records[len(records)-1].Dynamodb; last != nil && last.SequenceNumber != nil {
+		n.mu.Lock()
+		n.checkpoints[shardID] = *last.SequenceNumber
+		n.mu.Unlock()
+	}
+	if wake {
+		n.waker.Wake()
+	}
+}
+
+// isReadyMessage reports whether the record writes a message that is ready to be received from the queue.
+func (n *StreamNotifier) isReadyMessage(record streamtypes.Record) bool {
+	if record.EventName != streamtypes.OperationTypeInsert && record.EventName != streamtypes.OperationTypeModify {
+		return false
+	}
+	if record.Dynamodb == nil || record.Dynamodb.NewImage == nil {
+		return record.EventName == streamtypes.OperationTypeInsert
+	}
+	image := record.Dynamodb.NewImage
+	queueType, ok := image[n.schema.QueueTypeAttribute].(*streamtypes.AttributeValueMemberS)
+	if !ok || QueueType(queueType.Value) != n.queueType {
+		return false
+	}
+	switch invisibleUntilAt := image[n.schema.InvisibleUntilAtAttribute].(type) {
+	case nil, *streamtypes.AttributeValueMemberNULL:
+		return true
+	case *streamtypes.AttributeValueMemberS:
+		return invisibleUntilAt.Value == ""
+	default:
+		return false
+	}
+}
+
+func (n *StreamNotifier) shuttingDown() bool {
+	return atomic.LoadInt32(&n.inShutdown) != 0
+}
+
+func (n *StreamNotifier) logf(format string, args ...any) {
+	if n.errorLog != nil {
+		n.errorLog.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
@@ -0,0 +1,202 @@
+package dynamomq_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	streamtypes "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+type countWaker struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (w *countWaker) Wake() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.count++
+}
+
+func (w *countWaker) Count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.count
+}
+
+func newStreamRecord(event streamtypes.OperationType, sequenceNumber string,
+	queueType dynamomq.QueueType, invisibleUntilAt string) streamtypes.Record {
+	return streamtypes.Record{
+		EventName: event,
+		Dynamodb: &streamtypes.StreamRecord{
+			SequenceNumber: aws.String(sequenceNumber),
+			NewImage: map[string]streamtypes.AttributeValue{
+				"id":                 &streamtypes.AttributeValueMemberS{Value: "A-101"},
+				"queue_type":         &streamtypes.AttributeValueMemberS{Value: string(queueType)},
+				"invisible_until_at": &streamtypes.AttributeValueMemberS{Value: invisibleUntilAt},
+			},
+		},
+	}
+}
+
+// newStreamsAPI returns a stream with a single open shard whose GetRecords responses are taken
+// from pages, keyed by shard iterator. done is closed once the shard has been read to its end.
+func newStreamsAPI(pages map[string]func() (*dynamodbstreams.GetRecordsOutput, error),
+	iterators chan<- *dynamodbstreams.GetShardIteratorInput, done chan<- struct{}) *mock.DynamoDBStreams {
+	return &mock.DynamoDBStreams{
+		DescribeStreamFunc: func(ctx context.Context, params *dynamodbstreams.DescribeStreamInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.DescribeStreamOutput, error) {
+			return &dynamodbstreams.DescribeStreamOutput{
+				StreamDescription: &streamtypes.StreamDescription{
+					Shards: []streamtypes.Shard{
+						{
+							ShardId:             aws.String("shard-1"),
+							SequenceNumberRange: &streamtypes.SequenceNumberRange{StartingSequenceNumber: aws.String("0")},
+						},
+					},
+				},
+			}, nil
+		},
+		GetShardIteratorFunc: func(ctx context.Context, params *dynamodbstreams.GetShardIteratorInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetShardIteratorOutput, error) {
+			iterators <- params
+			iterator := "it-0"
+			if params.SequenceNumber != nil {
+				iterator = "it-" + *params.SequenceNumber
+			}
+			return &dynamodbstreams.GetShardIteratorOutput{ShardIterator: aws.String(iterator)}, nil
+		},
+		GetRecordsFunc: func(ctx context.Context, params *dynamodbstreams.GetRecordsInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetRecordsOutput, error) {
+			page, ok := pages[aws.ToString(params.ShardIterator)]
+			if !ok {
+				close(done)
+				return &dynamodbstreams.GetRecordsOutput{}, nil
+			}
+			return page()
+		},
+	}
+}
+
+func TestStreamNotifierWakesOnReadyMessages(t *testing.T) {
+	t.Parallel()
+	pages := map[string]func() (*dynamodbstreams.GetRecordsOutput, error){
+		"it-0": func() (*dynamodbstreams.GetRecordsOutput, error) {
+			return &dynamodbstreams.GetRecordsOutput{
+				Records: []streamtypes.Record{
+					newStreamRecord(streamtypes.OperationTypeInsert, "1", dynamomq.QueueTypeStandard, ""),
+					newStreamRecord(streamtypes.OperationTypeModify, "2", dynamomq.QueueTypeStandard, "2023-12-01T00:00:30Z"),
+				},
+				NextShardIterator: aws.String("it-a"),
+			}, nil
+		},
+		"it-a": func() (*dynamodbstreams.GetRecordsOutput, error) {
+			return &dynamodbstreams.GetRecordsOutput{
+				Records: []streamtypes.Record{
+					newStreamRecord(streamtypes.OperationTypeInsert, "3", dynamomq.QueueTypeDLQ, ""),
+					newStreamRecord(streamtypes.OperationTypeRemove, "4", dynamomq.QueueTypeStandard, ""),
+				},
+				NextShardIterator: aws.String("it-end"),
+			}, nil
+		},
+	}
+	iterators := make(chan *dynamodbstreams.GetShardIteratorInput, 10)
+	done := make(chan struct{})
+	waker := &countWaker{}
+	notifier := dynamomq.NewStreamNotifier(newStreamsAPI(pages, iterators, done), "arn:stream", waker,
+		dynamomq.WithStreamPollingInterval(time.Millisecond))
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- notifier.StartNotifying()
+	}()
+	waitFor(t, done, "reading the shard")
+	if got := (<-iterators).ShardIteratorType; got != streamtypes.ShardIteratorTypeLatest {
+		t.Errorf("GetShardIterator() type = %v, want %v", got, streamtypes.ShardIteratorTypeLatest)
+	}
+	if got := waker.Count(); got != 1 {
+		t.Errorf("Wake() calls = %d, want 1", got)
+	}
+	test.AssertDeepEqual(t, notifier.Checkpoints(), map[string]string{"shard-1": "4"}, "Checkpoints()")
+	if err := notifier.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	if err := <-errCh; !errors.Is(err, dynamomq.ErrStreamNotifierClosed) {
+		t.Errorf("StartNotifying() error = %v, want %v", err, dynamomq.ErrStreamNotifierClosed)
+	}
+}
+
+func TestStreamNotifierResumesFromCheckpointAfterExpiredIterator(t *testing.T) {
+	t.Parallel()
+	pages := map[string]func() (*dynamodbstreams.GetRecordsOutput, error){
+		"it-0": func() (*dynamodbstreams.GetRecordsOutput, error) {
+			return &dynamodbstreams.GetRecordsOutput{
+				Records: []streamtypes.Record{
+					newStreamRecord(streamtypes.OperationTypeInsert, "7", dynamomq.QueueTypeStandard, ""),
+				},
+				NextShardIterator: aws.String("it-expired"),
+			}, nil
+		},
+		"it-expired": func() (*dynamodbstreams.GetRecordsOutput, error) {
+			return nil, &streamtypes.ExpiredIteratorException{}
+		},
+	}
+	iterators := make(chan *dynamodbstreams.GetShardIteratorInput, 10)
+	done := make(chan struct{})
+	notifier := dynamomq.NewStreamNotifier(newStreamsAPI(pages, iterators, done), "arn:stream", &countWaker{},
+		dynamomq.WithStreamPollingInterval(time.Millisecond))
+	go func() {
+		_ = notifier.StartNotifying()
+	}()
+	waitFor(t, done, "reading the shard")
+	<-iterators
+	resumed := <-iterators
+	if resumed.ShardIteratorType != streamtypes.ShardIteratorTypeAfterSequenceNumber || aws.ToString(resumed.SequenceNumber) != "7" {
+		t.Errorf("GetShardIterator() = %v %v, want %v 7",
+			resumed.ShardIteratorType, aws.ToString(resumed.SequenceNumber), streamtypes.ShardIteratorTypeAfterSequenceNumber)
+	}
+	if err := notifier.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+}
+
+func TestStreamNotifierRetriesWhenStreamIsUnavailable(t *testing.T) {
+	t.Parallel()
+	described := make(chan struct{}, 10)
+	api := &mock.DynamoDBStreams{
+		DescribeStreamFunc: func(ctx context.Context, params *dynamodbstreams.DescribeStreamInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.DescribeStreamOutput, error) {
+			described <- struct{}{}
+			return nil, &streamtypes.ResourceNotFoundException{}
+		},
+	}
+	notifier := dynamomq.NewStreamNotifier(api, "arn:stream", &countWaker{},
+		dynamomq.WithStreamRetryInterval(time.Millisecond),
+		dynamomq.WithStreamErrorLog(log.New(io.Discard, "", 0)))
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- notifier.StartNotifying()
+	}()
+	<-described
+	<-described
+	if err := notifier.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	if err := <-errCh; !errors.Is(err, dynamomq.ErrStreamNotifierClosed) {
+		t.Errorf("StartNotifying() error = %v, want %v", err, dynamomq.ErrStreamNotifierClosed)
+	}
+}
+
+func waitFor(t *testing.T, done <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+	}
+}
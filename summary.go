@@ -0,0 +1,140 @@
+package dynamomq
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/vvatanabe/dynamomq/internal/constant"
+)
+
+// ListMessageSummariesInput represents the input parameters for listing the summaries of the messages of a queue.
+type ListMessageSummariesInput struct {
+	// QueueType is the type of queue (STANDARD, DLQ or SCHEDULED) to list. By default, it is STANDARD.
+	QueueType QueueType
+	// Limit is the maximum number of messages listed from the head of the queue. By default, it is 10.
+	Limit int32
+	// Tag lists only the messages sent with the tag. The tag is matched with a filter expression, so the query goes on
+	// until Limit messages match or the whole queue type is read, and every item read is charged, matching or not.
+	Tag string
+}
+
+// MessageSummary describes a message and its position in the queue, without its payload.
+type MessageSummary struct {
+	// ID is the unique identifier of the message.
+	ID string `json:"id"`
+	// Position is the position of the message in its queue type, starting at 1 for the message received next.
+	// With a Tag, only the messages sent with the tag are counted.
+	Position int `json:"position"`
+	// QueueType is the type of queue the message is in.
+	QueueType QueueType `json:"queue_type"`
+	// Status is the status of the message at the time of the call.
+	Status Status `json:"status"`
+	// Queued is true if the message is waiting to be received from the STANDARD or SCHEDULED queue type:
+	// it is ready, and it is neither held nor in the DLQ.
+	Queued bool `json:"queued"`
+	// DLQ is true if the message is in the DLQ.
+	DLQ bool `json:"dlq"`
+	// Held is true if the message is held with HoldMessage.
+	Held bool `json:"held,omitempty"`
+	// ReceiveCount is the number of times the message has been received.
+	ReceiveCount int `json:"receive_count"`
+	// Version is the version of the message, incremented by every update.
+	Version int `json:"version"`
+	// SentAt is the timestamp the message is ordered by in the queue.
+	SentAt string `json:"sent_at"`
+	// LastUpdatedTimestamp is the timestamp when the message was last updated.
+	LastUpdatedTimestamp string `json:"last_updated_timestamp"`
+}
+
+// ListMessageSummariesOutput represents the result of the operation to list the summaries of the messages of a queue.
+type ListMessageSummariesOutput struct {
+	// Summaries are the summaries of the messages in the order they are received.
+	Summaries []MessageSummary `json:"summaries"`
+}
+
+// ListMessageSummaries lists the first messages of a queue type with their status, version and timestamps,
+// in the order they are received. Unlike ListMessages, which scans the table, it queries the queueing index,
+// so the order of the summaries is the position of the messages in the queue. The payloads are not read.
+func (c *ClientImpl[T]) ListMessageSummaries(ctx context.Context, params *ListMessageSummariesInput) (*ListMessageSummariesOutput, error) {
+	if params == nil {
+		params = &ListMessageSummariesInput{}
+	}
+	if params.QueueType == "" {
+		params.QueueType = QueueTypeStandard
+	}
+	if params.Limit <= 0 {
+		params.Limit = constant.DefaultMaxListMessages
+	}
+	builder := expression.NewBuilder().
+		WithKeyCondition(expression.Key(c.schema.QueueTypeAttribute).Equal(expression.Value(params.QueueType))).
+		WithProjection(c.systemAttributeProjection())
+	if params.Tag != "" {
+		builder = builder.WithFilter(c.hasTag(params.Tag))
+	}
+	expr, err := c.buildExpression(builder)
+	if err != nil {
+		return &ListMessageSummariesOutput{}, BuildingExpressionError{Cause: err}
+	}
+	now := c.clock.Now()
+	out := &ListMessageSummariesOutput{Summaries: make([]MessageSummary, 0)}
+	var position int
+	queryInput := &dynamodb.QueryInput{
+		IndexName:                 aws.String(c.schema.QueueingIndexName),
+		TableName:                 aws.String(c.tableName),
+		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
+		ProjectionExpression:      expr.Projection(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ScanIndexForward:          aws.Bool(true),
+		Limit:                     aws.Int32(params.Limit),
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return &ListMessageSummariesOutput{}, OperationCanceledError{Cause: err}
+		}
+		queryOutput, err := c.dynamoDB.Query(ctx, queryInput)
+		if err != nil {
+			return &ListMessageSummariesOutput{}, handleDynamoDBError(err)
+		}
+		for _, item := range queryOutput.Items {
+			position++
+			message := Message[T]{}
+			if err := c.unmarshalItem(item, &message); err != nil {
+				if err = c.handleCorruptMessage(item, err); err != nil {
+					return &ListMessageSummariesOutput{}, err
+				}
+				continue
+			}
+			out.Summaries = append(out.Summaries, newMessageSummary(&message, position, now))
+			if int32(len(out.Summaries)) == params.Limit {
+				return out, nil
+			}
+		}
+		if queryOutput.LastEvaluatedKey == nil {
+			return out, nil
+		}
+		queryInput.ExclusiveStartKey = queryOutput.LastEvaluatedKey
+	}
+}
+
+func newMessageSummary[T any](message *Message[T], position int, now time.Time) MessageSummary {
+	status := message.GetStatus(now)
+	dlq := message.IsDLQ()
+	return MessageSummary{
+		ID:                   message.ID,
+		Position:             position,
+		QueueType:            message.QueueType,
+		Status:               status,
+		Queued:               !dlq && !message.Held && status == StatusReady,
+		DLQ:                  dlq,
+		Held:                 message.Held,
+		ReceiveCount:         message.ReceiveCount,
+		Version:              message.Version,
+		SentAt:               sentAtTimestamp(message.SentAt),
+		LastUpdatedTimestamp: message.UpdatedAt,
+	}
+}
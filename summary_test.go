@@ -0,0 +1,135 @@
+package dynamomq_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestDynamoMQClientListMessageSummaries(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate
+	held := NewTestMessageItemAsReady("A-303", now.Add(-time.Minute))
+	held.Held = true
+	pages := map[dynamomq.QueueType][][]*dynamomq.Message[test.MessageData]{
+		dynamomq.QueueTypeStandard: {
+			{NewTestMessageItemAsReady("A-101", now.Add(-3*time.Minute)), NewTestMessageItemAsProcessing("A-202", now)},
+			{held, NewTestMessageItemAsReady("A-404", now)},
+		},
+		dynamomq.QueueTypeDLQ: {
+			{NewTestMessageItemAsDLQ("B-101", now.Add(-time.Hour))},
+		},
+	}
+	summary := func(m *dynamomq.Message[test.MessageData], position int, status dynamomq.Status, queued bool) dynamomq.MessageSummary {
+		return dynamomq.MessageSummary{
+			ID:                   m.ID,
+			Position:             position,
+			QueueType:            m.QueueType,
+			Status:               status,
+			Queued:               queued,
+			DLQ:                  m.QueueType == dynamomq.QueueTypeDLQ,
+			Held:                 m.Held,
+			ReceiveCount:         m.ReceiveCount,
+			Version:              m.Version,
+			SentAt:               m.SentAt,
+			LastUpdatedTimestamp: m.UpdatedAt,
+		}
+	}
+	standard := pages[dynamomq.QueueTypeStandard]
+	tests := []struct {
+		name      string
+		input     *dynamomq.ListMessageSummariesInput
+		want      []dynamomq.MessageSummary
+		wantPages int
+	}{
+		{
+			name:  "should list the head of the queue in order across pages",
+			input: &dynamomq.ListMessageSummariesInput{Limit: 3},
+			want: []dynamomq.MessageSummary{
+				summary(standard[0][0], 1, dynamomq.StatusReady, true),
+				summary(standard[0][1], 2, dynamomq.StatusProcessing, false),
+				summary(standard[1][0], 3, dynamomq.StatusReady, false),
+			},
+			wantPages: 2,
+		},
+		{
+			name: "should list every message of the STANDARD queue type by default",
+			want: []dynamomq.MessageSummary{
+				summary(standard[0][0], 1, dynamomq.StatusReady, true),
+				summary(standard[0][1], 2, dynamomq.StatusProcessing, false),
+				summary(standard[1][0], 3, dynamomq.StatusReady, false),
+				summary(standard[1][1], 4, dynamomq.StatusReady, true),
+			},
+			wantPages: 2,
+		},
+		{
+			name:  "should list the DLQ",
+			input: &dynamomq.ListMessageSummariesInput{QueueType: dynamomq.QueueTypeDLQ},
+			want: []dynamomq.MessageSummary{
+				summary(pages[dynamomq.QueueTypeDLQ][0][0], 1, dynamomq.StatusReady, false),
+			},
+			wantPages: 1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var queries int
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+				mock.WithClock(mock.Clock{T: now}),
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+						if aws.ToString(params.IndexName) != "dynamo-mq-index-queue_type-sent_at" || !aws.ToBool(params.ScanIndexForward) {
+							t.Errorf("Query() is not in the order of the queueing index: %+v", params)
+						}
+						var queueType dynamomq.QueueType
+						for _, v := range params.ExpressionAttributeValues {
+							queueType = dynamomq.QueueType(v.(*types.AttributeValueMemberS).Value)
+						}
+						page := 0
+						if params.ExclusiveStartKey != nil {
+							page = 1
+						}
+						queries++
+						out := &dynamodb.QueryOutput{}
+						for _, m := range pages[queueType][page] {
+							item, err := m.MarshalMap()
+							if err != nil {
+								t.Fatal(err)
+							}
+							out.Items = append(out.Items, item)
+						}
+						if page+1 < len(pages[queueType]) {
+							out.LastEvaluatedKey = map[string]types.AttributeValue{
+								"id": &types.AttributeValueMemberS{Value: "next"},
+							}
+						}
+						return out, nil
+					},
+				}))
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			got, err := client.ListMessageSummaries(context.Background(), tt.input)
+			if err != nil {
+				t.Fatalf("ListMessageSummaries() error = %v", err)
+			}
+			test.AssertDeepEqual(t, got.Summaries, tt.want, "ListMessageSummaries()")
+			if queries != tt.wantPages {
+				t.Errorf("Query() calls = %d, want %d", queries, tt.wantPages)
+			}
+			if _, err := clock.ParseRFC3339Nano(got.Summaries[0].LastUpdatedTimestamp); err != nil {
+				t.Errorf("LastUpdatedTimestamp = %q, want a timestamp", got.Summaries[0].LastUpdatedTimestamp)
+			}
+		})
+	}
+}
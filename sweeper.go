@@ -0,0 +1,411 @@
+package dynamomq
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+	"github.com/vvatanabe/dynamomq/internal/clock"
+)
+
+const (
+	defaultSweepRetention           = 4 * 24 * time.Hour
+	defaultSweepInterval            = time.Minute
+	defaultSweepLockName            = "sweeper"
+	defaultSweepMaxMessagesPerSweep = 1000
+)
+
+// ErrSweeperClosed is an error that indicates the Sweeper has been stopped.
+var ErrSweeperClosed = errors.New("DynamoMQ: Sweeper closed")
+
+// DeleteExpiredMessagesInput represents the input parameters for deleting messages older than a retention period.
+type DeleteExpiredMessagesInput struct {
+	// QueueType is the type of queue (STANDARD or DLQ) to delete expired messages from. By default, it is STANDARD.
+	QueueType QueueType
+	// Retention is the age from which a message is expired, measured from the time it was sent.
+	// If it is zero or negative, the default of four days is used.
+	Retention time.Duration
+	// MaxMessages is the maximum number of messages to delete. Zero means unlimited.
+	MaxMessages int
+}
+
+// DeleteExpiredMessagesOutput represents the result of the operation to delete expired messages.
+type DeleteExpiredMessagesOutput struct {
+	// Deleted is the number of messages deleted.
+	Deleted int
+}
+
+// DeleteExpiredMessages deletes the messages of a queue that were sent more than Retention ago.
+// It walks the queueing index from the oldest message. Messages that are being processed or held with HoldMessage are kept,
+// and each message is deleted only if it has not been updated since it was read, so that a message
+// received or moved concurrently is left to the next run. The in-flight slot of a deleted message is released,
+// and the OnDeleted hook is called for it.
+func (c *ClientImpl[T]) DeleteExpiredMessages(ctx context.Context, params *DeleteExpiredMessagesInput) (*DeleteExpiredMessagesOutput, error) {
+	if params == nil {
+		params = &DeleteExpiredMessagesInput{}
+	}
+	if params.QueueType == "" {
+		params.QueueType = QueueTypeStandard
+	}
+	retention := params.Retention
+	if retention <= 0 {
+		retention = defaultSweepRetention
+	}
+	now := c.clock.Now()
+	cutoff := clock.FormatRFC3339Nano(now.Add(-retention))
+	builder := expression.NewBuilder().
+		WithKeyCondition(expression.Key(c.schema.QueueTypeAttribute).Equal(expression.Value(params.QueueType)).
+			And(expression.Key(c.schema.SentAtAttribute).LessThan(expression.Value(cutoff))))
+	expr, err := c.buildExpression(builder)
+	if err != nil {
+		return &DeleteExpiredMessagesOutput{}, BuildingExpressionError{Cause: err}
+	}
+	out := &DeleteExpiredMessagesOutput{}
+	var exclusiveStartKey map[string]types.AttributeValue
+	for {
+		if err := ctx.Err(); err != nil {
+			return out, OperationCanceledError{Cause: err}
+		}
+		queryOutput, err := c.dynamoDB.Query(ctx, &dynamodb.QueryInput{
+			IndexName:                 aws.String(c.schema.QueueingIndexName),
+			TableName:                 aws.String(c.tableName),
+			KeyConditionExpression:    expr.KeyCondition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			ScanIndexForward:          aws.Bool(true),
+			Limit:                     aws.Int32(defaultQueryLimit),
+			ExclusiveStartKey:         exclusiveStartKey,
+		})
+		if err != nil {
+			return out, handleDynamoDBError(err)
+		}
+		for _, item := range queryOutput.Items {
+			if params.MaxMessages > 0 && out.Deleted >= params.MaxMessages {
+				return out, nil
+			}
+			deleted, err := c.deleteExpiredItem(ctx, item, now)
+			if err != nil {
+				return out, err
+			}
+			if deleted {
+				out.Deleted++
+			}
+		}
+		exclusiveStartKey = queryOutput.LastEvaluatedKey
+		if exclusiveStartKey == nil {
+			return out, nil
+		}
+	}
+}
+
+func (c *ClientImpl[T]) deleteExpiredItem(ctx context.Context, item map[string]types.AttributeValue, now time.Time) (bool, error) {
+	message := Message[T]{}
+	if err := c.unmarshalItem(item, &message); err != nil {
+		return false, c.handleCorruptMessage(item, err)
+	}
+	if message.Held || message.IsProcessing(now) {
+		return false, nil
+	}
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.Name(c.schema.VersionAttribute).Equal(expression.Value(message.Version))).
+		Build()
+	if err != nil {
+		return false, BuildingExpressionError{Cause: err}
+	}
+	_, err = c.dynamoDB.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:                 aws.String(c.tableName),
+		Key:                       c.itemKey(message.ID),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		var cause *types.ConditionalCheckFailedException
+		if errors.As(err, &cause) {
+			return false, nil
+		}
+		return false, handleDynamoDBError(err)
+	}
+	if message.InFlightSlot {
+		c.releaseInFlightSlot(ctx, message.ID)
+	}
+	c.hooks.deleted(ctx, &DeleteMessageInput{ID: message.ID, StrictExistenceCheck: true}, &DeleteMessageOutput{})
+	return true, nil
+}
+
+// SweeperOptions contains configuration options for a Sweeper instance.
+type SweeperOptions struct {
+	// Retention is the age from which a message is deleted, measured from the time it was sent.
+	// If it is zero, the Retention of the stored QueueConfig is used, and then the default of four days.
+	Retention time.Duration
+	// Interval is the time interval between two sweeps.
+	Interval time.Duration
+	// QueueTypes are the types of queue to sweep. By default, both STANDARD and DLQ are swept.
+	QueueTypes []QueueType
+	// MaxMessagesPerSweep is the maximum number of messages deleted from each queue type in one sweep,
+	// which bounds the time a sweep holds the lock.
+	MaxMessagesPerSweep int
+	// LockName identifies the lock item shared by the Sweepers of a fleet.
+	LockName string
+	// LockTTL is the duration after which the lock of a Sweeper that stopped without releasing it expires.
+	// It must be longer than Interval, otherwise the lock changes hands between sweeps. By default, it is three times Interval.
+	LockTTL time.Duration
+	// Owner identifies this Sweeper in the lock item. By default, it is a random UUID.
+	Owner string
+	// ErrorLog is an optional logger for errors. If nil, the standard logger is used.
+	ErrorLog *log.Logger
+}
+
+// WithSweeperRetention sets the age from which a message is deleted.
+func WithSweeperRetention(retention time.Duration) func(o *SweeperOptions) {
+	return func(o *SweeperOptions) {
+		o.Retention = retention
+	}
+}
+
+// WithSweeperInterval sets the time interval between two sweeps.
+func WithSweeperInterval(interval time.Duration) func(o *SweeperOptions) {
+	return func(o *SweeperOptions) {
+		o.Interval = interval
+	}
+}
+
+// WithSweeperQueueTypes sets the types of queue to sweep.
+func WithSweeperQueueTypes(queueTypes ...QueueType) func(o *SweeperOptions) {
+	return func(o *SweeperOptions) {
+		o.QueueTypes = queueTypes
+	}
+}
+
+// WithSweeperMaxMessagesPerSweep sets the maximum number of messages deleted from each queue type in one sweep.
+func WithSweeperMaxMessagesPerSweep(maxMessages int) func(o *SweeperOptions) {
+	return func(o *SweeperOptions) {
+		o.MaxMessagesPerSweep = maxMessages
+	}
+}
+
+// WithSweeperLock sets the name of the lock item and the duration after which it expires.
+func WithSweeperLock(name string, ttl time.Duration) func(o *SweeperOptions) {
+	return func(o *SweeperOptions) {
+		o.LockName = name
+		o.LockTTL = ttl
+	}
+}
+
+// WithSweeperOwner sets the identifier of the Sweeper in the lock item.
+func WithSweeperOwner(owner string) func(o *SweeperOptions) {
+	return func(o *SweeperOptions) {
+		o.Owner = owner
+	}
+}
+
+// WithSweeperErrorLog sets a custom logger for the Sweeper.
+func WithSweeperErrorLog(errorLog *log.Logger) func(o *SweeperOptions) {
+	return func(o *SweeperOptions) {
+		o.ErrorLog = errorLog
+	}
+}
+
+// NewSweeper creates a new Sweeper that deletes the messages of the client's queue that are older than the retention.
+func NewSweeper[T any](client Client[T], opts ...func(o *SweeperOptions)) *Sweeper[T] {
+	o := &SweeperOptions{
+		Interval:            defaultSweepInterval,
+		QueueTypes:          []QueueType{QueueTypeStandard, QueueTypeDLQ},
+		MaxMessagesPerSweep: defaultSweepMaxMessagesPerSweep,
+		LockName:            defaultSweepLockName,
+		Owner:               uuid.NewString(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.LockTTL <= 0 {
+		o.LockTTL = 3 * o.Interval
+	}
+	return &Sweeper[T]{
+		client:              client,
+		retention:           o.Retention,
+		interval:            o.Interval,
+		queueTypes:          o.QueueTypes,
+		maxMessagesPerSweep: o.MaxMessagesPerSweep,
+		lockName:            o.LockName,
+		lockTTL:             o.LockTTL,
+		owner:               o.Owner,
+		errorLog:            o.ErrorLog,
+		doneChan:            make(chan struct{}),
+	}
+}
+
+// SweeperStats reports the activity of a Sweeper since it was created.
+type SweeperStats struct {
+	// Sweeps is the number of sweeps run while holding the lock.
+	Sweeps int64
+	// Skipped is the number of sweeps skipped because another Sweeper held the lock.
+	Skipped int64
+	// Deleted is the number of messages deleted.
+	Deleted int64
+	// Errors is the number of sweeps that failed.
+	Errors int64
+}
+
+// Sweeper periodically deletes the messages that are older than a retention period.
+// Every instance of a fleet can run a Sweeper: they contend for a lock item stored in the queue table,
+// and only the holder sweeps. The holder extends the lock on each sweep, and the lock expires after LockTTL
+// if the holder goes away, so that another instance takes over.
+// Note: To create a new instance of Sweeper, it is necessary to use the NewSweeper function.
+type Sweeper[T any] struct {
+	client              Client[T]
+	retention           time.Duration
+	interval            time.Duration
+	queueTypes          []QueueType
+	maxMessagesPerSweep int
+	lockName            string
+	lockTTL             time.Duration
+	owner               string
+	errorLog            *log.Logger
+
+	sweeps   atomic.Int64
+	skipped  atomic.Int64
+	deleted  atomic.Int64
+	failures atomic.Int64
+	mu       sync.Mutex
+	runWG    sync.WaitGroup
+	doneChan chan struct{}
+}
+
+// Start sweeps every Interval until the context is done or Stop is called, and then releases the lock.
+// It returns ErrSweeperClosed after Stop, or the error of the context.
+func (s *Sweeper[T]) Start(ctx context.Context) error {
+	s.runWG.Add(1)
+	defer s.runWG.Done()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-s.doneChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	defer s.releaseLock()
+	for {
+		s.sweep(ctx)
+		if !sleepContext(ctx, s.interval) {
+			select {
+			case <-s.doneChan:
+				return ErrSweeperClosed
+			default:
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// Stop stops the Sweeper and waits until the running sweep has finished and the lock has been released.
+func (s *Sweeper[T]) Stop() {
+	s.mu.Lock()
+	select {
+	case <-s.doneChan:
+	default:
+		close(s.doneChan)
+	}
+	s.mu.Unlock()
+	s.runWG.Wait()
+}
+
+// Stats returns the activity of the Sweeper since it was created.
+func (s *Sweeper[T]) Stats() SweeperStats {
+	return SweeperStats{
+		Sweeps:  s.sweeps.Load(),
+		Skipped: s.skipped.Load(),
+		Deleted: s.deleted.Load(),
+		Errors:  s.failures.Load(),
+	}
+}
+
+func (s *Sweeper[T]) sweep(ctx context.Context) {
+	for i, queueType := range s.queueTypes {
+		// Acquiring the lock before each queue type extends it while a long sweep is running.
+		acquired, err := s.client.AcquireLock(ctx, &AcquireLockInput{
+			Name:  s.lockName,
+			Owner: s.owner,
+			TTL:   s.lockTTL,
+		})
+		if err != nil {
+			s.fail(ctx, "DynamoMQ: Failed to acquire the sweeper lock. %s", err)
+			return
+		}
+		if !acquired.Acquired {
+			if i == 0 {
+				s.skipped.Add(1)
+			}
+			return
+		}
+		if i == 0 {
+			s.sweeps.Add(1)
+		}
+		retention, err := s.effectiveRetention(ctx)
+		if err != nil {
+			s.fail(ctx, "DynamoMQ: Failed to get the queue configuration. %s", err)
+			return
+		}
+		out, err := s.client.DeleteExpiredMessages(ctx, &DeleteExpiredMessagesInput{
+			QueueType:   queueType,
+			Retention:   retention,
+			MaxMessages: s.maxMessagesPerSweep,
+		})
+		if out != nil {
+			s.deleted.Add(int64(out.Deleted))
+		}
+		if err != nil {
+			s.fail(ctx, "DynamoMQ: Failed to delete expired messages. %s", err)
+			return
+		}
+	}
+}
+
+func (s *Sweeper[T]) effectiveRetention(ctx context.Context) (time.Duration, error) {
+	if s.retention > 0 {
+		return s.retention, nil
+	}
+	out, err := s.client.GetQueueConfig(ctx, &GetQueueConfigInput{})
+	if err != nil {
+		return 0, err
+	}
+	if out.Config.Retention > 0 {
+		return out.Config.Retention, nil
+	}
+	return defaultSweepRetention, nil
+}
+
+func (s *Sweeper[T]) fail(ctx context.Context, format string, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	s.failures.Add(1)
+	s.logf(format, err)
+}
+
+func (s *Sweeper[T]) releaseLock() {
+	if _, err := s.client.ReleaseLock(context.Background(), &ReleaseLockInput{
+		Name:  s.lockName,
+		Owner: s.owner,
+	}); err != nil {
+		s.logf("DynamoMQ: Failed to release the sweeper lock. %s", err)
+	}
+}
+
+func (s *Sweeper[T]) logf(format string, v ...any) {
+	if s.errorLog != nil {
+		s.errorLog.Printf(format, v...)
+	} else {
+		log.Printf(format, v...)
+	}
+}
@@ -0,0 +1,291 @@
+package dynamomq_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestDynamoMQClientDeleteExpiredMessages(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(10 * 24 * time.Hour)
+	processing := NewTestMessageItemAsReady("A-102", test.DefaultTestDate)
+	dynamomqtest.MarkAsProcessing(processing, now)
+	client, clean := prepareTestClient(context.Background(), t, func(t *testing.T) (string, *dynamodb.Client, func()) {
+		return SetupDynamoDB(t,
+			newPutRequestWithReadyItem("A-101", test.DefaultTestDate),
+			&types.PutRequest{Item: dynamomqtest.MarshalMap(processing)},
+			newPutRequestWithReadyItem("A-103", now.Add(-time.Hour)),
+			newPutRequestWithDLQItem("B-101", test.DefaultTestDate),
+		)
+	}, mock.Clock{T: now}, false, nil, nil, nil)
+	defer clean()
+	out, err := client.DeleteExpiredMessages(context.Background(), &dynamomq.DeleteExpiredMessagesInput{
+		Retention: 24 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("DeleteExpiredMessages() error = %v", err)
+	}
+	test.AssertDeepEqual(t, out, &dynamomq.DeleteExpiredMessagesOutput{Deleted: 1}, "DeleteExpiredMessages()")
+	for id, want := range map[string]bool{"A-101": false, "A-102": true, "A-103": true, "B-101": true} {
+		got, err := client.GetMessage(context.Background(), &dynamomq.GetMessageInput{ID: id})
+		if err != nil {
+			t.Fatalf("GetMessage() error = %v", err)
+		}
+		if exists := got.Message != nil; exists != want {
+			t.Errorf("message %s exists = %v, want %v", id, exists, want)
+		}
+	}
+}
+
+func TestDynamoMQClientDeleteExpiredMessagesShouldReleaseSlotsAndCallHooks(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(10 * 24 * time.Hour)
+	expired := NewTestMessageItemAsReady("A-101", test.DefaultTestDate)
+	expired.InFlightSlot = true
+	var deleted []string
+	client, clean := prepareTestClient(context.Background(), t, NewSetupFunc(
+		&types.PutRequest{Item: dynamomqtest.MarshalMap(expired)},
+		newPutRequestWithReadyItem("A-102", now.Add(-time.Hour)),
+		&types.PutRequest{Item: map[string]types.AttributeValue{
+			dynamomq.AttributeNameID:            &types.AttributeValueMemberS{Value: "dynamomq-inflight#queue"},
+			dynamomq.AttributeNameInFlightCount: &types.AttributeValueMemberN{Value: "1"},
+		}},
+	), mock.Clock{T: now}, false, nil, nil, nil,
+		dynamomq.WithMaxInFlight(2),
+		dynamomq.WithHooks(&dynamomq.Hooks[test.MessageData]{
+			OnDeleted: func(ctx context.Context, params *dynamomq.DeleteMessageInput, out *dynamomq.DeleteMessageOutput) {
+				deleted = append(deleted, params.ID)
+			},
+		}))
+	defer clean()
+	ctx := context.Background()
+	// A zero retention falls back to the default of four days instead of deleting every message.
+	out, err := client.DeleteExpiredMessages(ctx, &dynamomq.DeleteExpiredMessagesInput{})
+	if err != nil {
+		t.Fatalf("DeleteExpiredMessages() error = %v", err)
+	}
+	test.AssertDeepEqual(t, out, &dynamomq.DeleteExpiredMessagesOutput{Deleted: 1}, "DeleteExpiredMessages()")
+	test.AssertDeepEqual(t, deleted, []string{"A-101"}, "OnDeleted calls")
+	reconciled, err := client.ReconcileInFlightCount(ctx, nil)
+	if err != nil {
+		t.Fatalf("ReconcileInFlightCount() error = %v", err)
+	}
+	test.AssertDeepEqual(t, reconciled, &dynamomq.ReconcileInFlightCountOutput{Previous: 0, Count: 0}, "ReconcileInFlightCount()")
+}
+
+func TestDynamoMQClientAcquireLock(t *testing.T) {
+	t.Parallel()
+	tableName, raw, clean := SetupDynamoDB(t)
+	defer clean()
+	newClient := func(now time.Time) dynamomq.Client[test.MessageData] {
+		client, _ := prepareTestClient(context.Background(), t, func(t *testing.T) (string, *dynamodb.Client, func()) {
+			return tableName, raw, func() {}
+		}, mock.Clock{T: now}, false, nil, nil, nil)
+		return client
+	}
+	ctx := context.Background()
+	first := newClient(test.DefaultTestDate)
+	acquire := func(client dynamomq.Client[test.MessageData], owner string) bool {
+		t.Helper()
+		out, err := client.AcquireLock(ctx, &dynamomq.AcquireLockInput{Name: "sweeper", Owner: owner, TTL: time.Minute})
+		if err != nil {
+			t.Fatalf("AcquireLock() error = %v", err)
+		}
+		return out.Acquired
+	}
+	if !acquire(first, "a") {
+		t.Fatal("AcquireLock() of a free lock was not acquired")
+	}
+	if acquire(first, "b") {
+		t.Error("AcquireLock() of a held lock was acquired")
+	}
+	if !acquire(first, "a") {
+		t.Error("AcquireLock() by the owner was not extended")
+	}
+	if !acquire(newClient(test.DefaultTestDate.Add(2*time.Minute)), "b") {
+		t.Error("AcquireLock() of an expired lock was not acquired")
+	}
+	if _, err := first.ReleaseLock(ctx, &dynamomq.ReleaseLockInput{Name: "sweeper", Owner: "a"}); err != nil {
+		t.Fatalf("ReleaseLock() error = %v", err)
+	}
+	if acquire(first, "a") {
+		t.Error("ReleaseLock() by a former owner released the lock")
+	}
+	list, err := first.ListMessages(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListMessages() error = %v", err)
+	}
+	if len(list.Messages) != 0 {
+		t.Errorf("ListMessages() = %v, want no lock items", list.Messages)
+	}
+}
+
+func TestDynamoMQClientAcquireLockHeldByAnotherOwner(t *testing.T) {
+	t.Parallel()
+	var input *dynamodb.PutItemInput
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			PutItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+				input = params
+				return nil, &types.ConditionalCheckFailedException{}
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	got, err := client.AcquireLock(context.Background(), &dynamomq.AcquireLockInput{Name: "sweeper", Owner: "a"})
+	if err != nil {
+		t.Fatalf("AcquireLock() error = %v", err)
+	}
+	if got.Acquired {
+		t.Error("AcquireLock() acquired = true, want false")
+	}
+	if _, ok := input.Item[dynamomq.AttributeNameQueueType]; ok {
+		t.Errorf("AcquireLock() item has a queue type: %v", input.Item)
+	}
+	_, err = client.AcquireLock(context.Background(), &dynamomq.AcquireLockInput{Name: "sweeper"})
+	test.AssertError(t, err, dynamomq.ErrIDNotProvided, "AcquireLock()")
+}
+
+func TestSweepersContendForLock(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(10 * 24 * time.Hour)
+	var puts []*types.PutRequest
+	for _, id := range []string{"A-101", "A-102", "A-103"} {
+		puts = append(puts, newPutRequestWithReadyItem(id, test.DefaultTestDate))
+	}
+	tableName, raw, clean := SetupDynamoDB(t, puts...)
+	defer clean()
+	sweepers := make([]*dynamomq.Sweeper[test.MessageData], 2)
+	for i := range sweepers {
+		client, _ := prepareTestClient(context.Background(), t, func(t *testing.T) (string, *dynamodb.Client, func()) {
+			return tableName, raw, func() {}
+		}, mock.Clock{T: now}, false, nil, nil, nil)
+		sweepers[i] = dynamomq.NewSweeper[test.MessageData](client,
+			dynamomq.WithSweeperRetention(24*time.Hour),
+			dynamomq.WithSweeperInterval(10*time.Millisecond),
+			dynamomq.WithSweeperLock("sweeper", time.Minute))
+	}
+	var wg sync.WaitGroup
+	for _, s := range sweepers {
+		wg.Add(1)
+		go func(s *dynamomq.Sweeper[test.MessageData]) {
+			defer wg.Done()
+			_ = s.Start(context.Background())
+		}(s)
+	}
+	time.Sleep(200 * time.Millisecond)
+	for _, s := range sweepers {
+		s.Stop()
+	}
+	wg.Wait()
+	first, second := sweepers[0].Stats(), sweepers[1].Stats()
+	if (first.Sweeps == 0) == (second.Sweeps == 0) {
+		t.Errorf("Stats() sweeps = %d and %d, want exactly one sweeper to sweep", first.Sweeps, second.Sweeps)
+	}
+	if deleted := first.Deleted + second.Deleted; deleted != 3 {
+		t.Errorf("Stats() deleted = %d, want 3", deleted)
+	}
+}
+
+func TestSweeper(t *testing.T) {
+	t.Parallel()
+	var (
+		mu       sync.Mutex
+		held     = true
+		released []string
+		swept    []dynamomq.QueueType
+	)
+	client := &mock.Client[test.MessageData]{
+		AcquireLockFunc: func(ctx context.Context, params *dynamomq.AcquireLockInput) (*dynamomq.AcquireLockOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			// Another instance holds the lock on the first sweep only.
+			acquired := !held
+			held = false
+			return &dynamomq.AcquireLockOutput{Acquired: acquired}, nil
+		},
+		DeleteExpiredMessagesFunc: func(ctx context.Context, params *dynamomq.DeleteExpiredMessagesInput) (*dynamomq.DeleteExpiredMessagesOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if params.Retention != time.Hour || params.MaxMessages != 5 {
+				t.Errorf("DeleteExpiredMessages() params = %+v", params)
+			}
+			swept = append(swept, params.QueueType)
+			return &dynamomq.DeleteExpiredMessagesOutput{Deleted: 2}, nil
+		},
+		ReleaseLockFunc: func(ctx context.Context, params *dynamomq.ReleaseLockInput) (*dynamomq.ReleaseLockOutput, error) {
+			released = append(released, params.Owner)
+			return &dynamomq.ReleaseLockOutput{}, nil
+		},
+	}
+	sweeper := dynamomq.NewSweeper[test.MessageData](client,
+		dynamomq.WithSweeperRetention(time.Hour),
+		dynamomq.WithSweeperInterval(time.Millisecond),
+		dynamomq.WithSweeperMaxMessagesPerSweep(5),
+		dynamomq.WithSweeperOwner("worker-1"))
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- sweeper.Start(context.Background())
+	}()
+	for {
+		mu.Lock()
+		n := len(swept)
+		mu.Unlock()
+		if n >= 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	sweeper.Stop()
+	if err := <-errCh; !errors.Is(err, dynamomq.ErrSweeperClosed) {
+		t.Errorf("Start() error = %v, want %v", err, dynamomq.ErrSweeperClosed)
+	}
+	stats := sweeper.Stats()
+	if stats.Skipped != 1 || stats.Sweeps == 0 || stats.Deleted != 2*int64(len(swept)) || stats.Errors != 0 {
+		t.Errorf("Stats() = %+v, swept = %v", stats, swept)
+	}
+	test.AssertDeepEqual(t, swept[:2], []dynamomq.QueueType{dynamomq.QueueTypeStandard, dynamomq.QueueTypeDLQ}, "swept queue types")
+	test.AssertDeepEqual(t, released, []string{"worker-1"}, "released")
+}
+
+func TestSweeperCountsErrors(t *testing.T) {
+	t.Parallel()
+	client := &mock.Client[test.MessageData]{
+		AcquireLockFunc: func(ctx context.Context, params *dynamomq.AcquireLockInput) (*dynamomq.AcquireLockOutput, error) {
+			return nil, test.ErrTest
+		},
+		ReleaseLockFunc: func(ctx context.Context, params *dynamomq.ReleaseLockInput) (*dynamomq.ReleaseLockOutput, error) {
+			return &dynamomq.ReleaseLockOutput{}, nil
+		},
+	}
+	sweeper := dynamomq.NewSweeper[test.MessageData](client,
+		dynamomq.WithSweeperInterval(time.Hour),
+		dynamomq.WithSweeperErrorLog(log.New(io.Discard, "", 0)))
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- sweeper.Start(ctx)
+	}()
+	for sweeper.Stats().Errors == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-errCh; !errors.Is(err, context.Canceled) {
+		t.Errorf("Start() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,268 @@
+package dynamomq
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq/internal/constant"
+)
+
+const defaultTableWaitDuration = 5 * time.Minute
+
+// QueueTableAPI is the subset of the Amazon DynamoDB API used to provision the table of a queue.
+// *dynamodb.Client satisfies this interface.
+type QueueTableAPI interface {
+	CreateTable(ctx context.Context, params *dynamodb.CreateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error)
+	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+	DeleteTable(ctx context.Context, params *dynamodb.DeleteTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteTableOutput, error)
+	DescribeTimeToLive(ctx context.Context, params *dynamodb.DescribeTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error)
+	UpdateTimeToLive(ctx context.Context, params *dynamodb.UpdateTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateTimeToLiveOutput, error)
+}
+
+// CreateQueueTableInput represents the input parameters for creating the DynamoDB table of a queue.
+type CreateQueueTableInput struct {
+	// TableName is the name of the table. By default, it is "dynamo-mq-table".
+	TableName string
+	// QueueingIndexName is the name of the global secondary index used for queueing.
+	// By default, it is "dynamo-mq-index-queue_type-sent_at".
+	QueueingIndexName string
+	// TableSchema defines the attribute names of the keys. It must match the TableSchema given to the client.
+	TableSchema TableSchema
+	// BillingMode is the billing mode of the table. By default, it is PAY_PER_REQUEST.
+	BillingMode types.BillingMode
+	// ReadCapacityUnits is the provisioned read capacity of the table and the index. It is only used with PROVISIONED.
+	ReadCapacityUnits int64
+	// WriteCapacityUnits is the provisioned write capacity of the table and the index. It is only used with PROVISIONED.
+	WriteCapacityUnits int64
+	// EnableTTLAttribute is the name of the attribute to enable Time to Live on. TTL is left disabled when it is empty.
+	EnableTTLAttribute string
+	// DeletionProtection is a boolean indicating if deletion protection should be enabled on the table.
+	DeletionProtection bool
+	// MaxWaitDuration is the maximum time to wait for the table to become ACTIVE. By default, it is 5 minutes.
+	MaxWaitDuration time.Duration
+}
+
+// CreateQueueTableOutput represents the result of the create queue table operation.
+type CreateQueueTableOutput struct {
+	// Table is the description of the table once it is ACTIVE.
+	Table *types.TableDescription
+	// Created is false if the table already existed.
+	Created bool
+}
+
+// NewCreateTableInput returns the CreateTable request that defines the table and the queueing index exactly as
+// the DynamoMQ client expects them. It is the single definition of the table schema used by CreateQueueTable.
+func NewCreateTableInput(params *CreateQueueTableInput) *dynamodb.CreateTableInput {
+	p := withCreateQueueTableDefaults(params)
+	schema := p.TableSchema
+	in := &dynamodb.CreateTableInput{
+		TableName: aws.String(p.TableName),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{
+				AttributeName: aws.String(schema.PartitionKeyAttribute),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+			{
+				AttributeName: aws.String(schema.QueueTypeAttribute),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+			{
+				AttributeName: aws.String(schema.SentAtAttribute),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String(schema.PartitionKeyAttribute),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+			{
+				IndexName: aws.String(schema.QueueingIndexName),
+				KeySchema: []types.KeySchemaElement{
+					{
+						AttributeName: aws.String(schema.QueueTypeAttribute),
+						KeyType:       types.KeyTypeHash,
+					},
+					{
+						AttributeName: aws.String(schema.SentAtAttribute),
+						KeyType:       types.KeyTypeRange,
+					},
+				},
+				Projection: &types.Projection{
+					ProjectionType: types.ProjectionTypeAll,
+				},
+			},
+		},
+		BillingMode:               p.BillingMode,
+		DeletionProtectionEnabled: aws.Bool(p.DeletionProtection),
+	}
+	if schema.SortKeyAttribute != "" {
+		in.AttributeDefinitions = append(in.AttributeDefinitions, types.AttributeDefinition{
+			AttributeName: aws.String(schema.SortKeyAttribute),
+			AttributeType: types.ScalarAttributeTypeS,
+		})
+		in.KeySchema = append(in.KeySchema, types.KeySchemaElement{
+			AttributeName: aws.String(schema.SortKeyAttribute),
+			KeyType:       types.KeyTypeRange,
+		})
+	}
+	if p.BillingMode == types.BillingModeProvisioned {
+		throughput := &types.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(p.ReadCapacityUnits),
+			WriteCapacityUnits: aws.Int64(p.WriteCapacityUnits),
+		}
+		in.ProvisionedThroughput = throughput
+		in.GlobalSecondaryIndexes[0].ProvisionedThroughput = throughput
+	}
+	return in
+}
+
+// CreateQueueTable creates the DynamoDB table of a queue with the schema expected by the DynamoMQ client and
+// waits until it is ACTIVE. It is idempotent: if the table already exists, its schema is validated
+// and a SchemaMismatchError is returned when it differs.
+// When EnableTTLAttribute is set, Time to Live is enabled on that attribute unless it is already enabled.
+func CreateQueueTable(ctx context.Context, api QueueTableAPI, params *CreateQueueTableInput) (*CreateQueueTableOutput, error) {
+	p := withCreateQueueTableDefaults(params)
+	out := &CreateQueueTableOutput{Created: true}
+	if err := p.TableSchema.validate(); err != nil {
+		return out, err
+	}
+	_, err := api.CreateTable(ctx, NewCreateTableInput(&p))
+	if err != nil {
+		var inUse *types.ResourceInUseException
+		if !errors.As(err, &inUse) {
+			return out, handleDynamoDBError(err)
+		}
+		out.Created = false
+	}
+	table, err := waitForTableActive(ctx, api, p.TableName, p.MaxWaitDuration)
+	if err != nil {
+		return out, err
+	}
+	out.Table = table
+	if !out.Created {
+		if problems := checkTableSchema(table, p.TableSchema); len(problems) > 0 {
+			return out, SchemaMismatchError{TableName: p.TableName, Problems: problems}
+		}
+	}
+	if p.EnableTTLAttribute != "" {
+		if err := enableTimeToLive(ctx, api, p.TableName, p.EnableTTLAttribute); err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+// DeleteQueueTableInput represents the input parameters for deleting the DynamoDB table of a queue.
+type DeleteQueueTableInput struct {
+	// TableName is the name of the table. By default, it is "dynamo-mq-table".
+	TableName string
+	// Confirm must be true for the table to be deleted. It guards against deleting a queue with all its messages by accident.
+	Confirm bool
+	// MaxWaitDuration is the maximum time to wait for the table to be deleted. By default, it is 5 minutes.
+	MaxWaitDuration time.Duration
+}
+
+// DeleteQueueTableOutput represents the result of the delete queue table operation.
+type DeleteQueueTableOutput struct{}
+
+// DeleteQueueTable deletes the DynamoDB table of a queue and waits until it no longer exists.
+// It returns a DeletionNotConfirmedError unless Confirm is set. Deleting a table that does not exist succeeds.
+func DeleteQueueTable(ctx context.Context, api QueueTableAPI, params *DeleteQueueTableInput) (*DeleteQueueTableOutput, error) {
+	if params == nil {
+		params = &DeleteQueueTableInput{}
+	}
+	tableName := params.TableName
+	if tableName == "" {
+		tableName = constant.DefaultTableName
+	}
+	maxWait := params.MaxWaitDuration
+	if maxWait <= 0 {
+		maxWait = defaultTableWaitDuration
+	}
+	out := &DeleteQueueTableOutput{}
+	if !params.Confirm {
+		return out, DeletionNotConfirmedError{TableName: tableName}
+	}
+	_, err := api.DeleteTable(ctx, &dynamodb.DeleteTableInput{
+		TableName: aws.String(tableName),
+	})
+	if err != nil {
+		var notFound *types.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return out, nil
+		}
+		return out, handleDynamoDBError(err)
+	}
+	err = dynamodb.NewTableNotExistsWaiter(api).Wait(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(tableName),
+	}, maxWait)
+	if err != nil {
+		return out, handleDynamoDBError(err)
+	}
+	return out, nil
+}
+
+func withCreateQueueTableDefaults(params *CreateQueueTableInput) CreateQueueTableInput {
+	var p CreateQueueTableInput
+	if params != nil {
+		p = *params
+	}
+	if p.TableName == "" {
+		p.TableName = constant.DefaultTableName
+	}
+	if p.TableSchema.QueueingIndexName == "" {
+		p.TableSchema.QueueingIndexName = p.QueueingIndexName
+	}
+	p.TableSchema = p.TableSchema.withDefaults()
+	p.QueueingIndexName = p.TableSchema.QueueingIndexName
+	if p.BillingMode == "" {
+		p.BillingMode = types.BillingModePayPerRequest
+	}
+	if p.MaxWaitDuration <= 0 {
+		p.MaxWaitDuration = defaultTableWaitDuration
+	}
+	return p
+}
+
+func waitForTableActive(ctx context.Context, api QueueTableAPI,
+	tableName string, maxWait time.Duration) (*types.TableDescription, error) {
+	out, err := dynamodb.NewTableExistsWaiter(api).WaitForOutput(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(tableName),
+	}, maxWait)
+	if err != nil {
+		return nil, handleDynamoDBError(err)
+	}
+	return out.Table, nil
+}
+
+func enableTimeToLive(ctx context.Context, api QueueTableAPI, tableName, attributeName string) error {
+	ttl, err := api.DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{
+		TableName: aws.String(tableName),
+	})
+	if err != nil {
+		return handleDynamoDBError(err)
+	}
+	if desc := ttl.TimeToLiveDescription; desc != nil &&
+		desc.AttributeName != nil && *desc.AttributeName == attributeName &&
+		(desc.TimeToLiveStatus == types.TimeToLiveStatusEnabled || desc.TimeToLiveStatus == types.TimeToLiveStatusEnabling) {
+		return nil
+	}
+	_, err = api.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(tableName),
+		TimeToLiveSpecification: &types.TimeToLiveSpecification{
+			AttributeName: aws.String(attributeName),
+			Enabled:       aws.Bool(true),
+		},
+	})
+	if err != nil {
+		return handleDynamoDBError(err)
+	}
+	return nil
+}
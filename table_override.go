@@ -0,0 +1,15 @@
+package dynamomq
+
+// WithTable returns a client targeting the table tableName, sharing the DynamoDB client, the options and
+// the clock of c, to serve queues sharded across several tables without building a client per table.
+// The state kept per table, such as the cached queue configuration, stats and table description, starts empty.
+// The returned client and c can be used concurrently.
+func (c *ClientImpl[T]) WithTable(tableName string) Client[T] {
+	d := &ClientImpl[T]{
+		clientSettings: c.clientSettings,
+		tableName:      tableName,
+		tenants:        newTenantLRU(maxRecentTenants),
+	}
+	d.receivePageSize.Store(d.minReceivePageSize)
+	return d
+}
@@ -0,0 +1,179 @@
+package dynamomq_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+	"github.com/upsidr/dynamotest"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/constant"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestClientWithTableShouldTargetTheGivenTable(t *testing.T) {
+	t.Parallel()
+	var (
+		mu     sync.Mutex
+		tables []string
+	)
+	record := func(tableName *string) {
+		mu.Lock()
+		defer mu.Unlock()
+		tables = append(tables, aws.ToString(tableName))
+	}
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithTableName("tenant-a"),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+				record(params.TableName)
+				return &dynamodb.GetItemOutput{}, nil
+			},
+			PutItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+				record(params.TableName)
+				return &dynamodb.PutItemOutput{}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	derived := client.(*dynamomq.ClientImpl[test.MessageData]).WithTable("tenant-b")
+	if _, err := derived.SendMessage(context.Background(), &dynamomq.SendMessageInput[test.MessageData]{
+		ID:   "A-101",
+		Data: test.NewMessageData("A-101"),
+	}); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if len(tables) == 0 {
+		t.Fatal("SendMessage() made no request")
+	}
+	for _, table := range tables {
+		if table != "tenant-b" {
+			t.Errorf("SendMessage() table = %v, want tenant-b", table)
+		}
+	}
+}
+
+func TestClientWithTableShouldRunCycleOnTwoTablesConcurrently(t *testing.T) {
+	t.Parallel()
+	raw, clean := dynamotest.NewDynamoDB(t)
+	defer clean()
+	tableNames := []string{
+		constant.DefaultTableName + "-" + uuid.NewString(),
+		constant.DefaultTableName + "-" + uuid.NewString(),
+	}
+	for _, tableName := range tableNames {
+		dynamotest.PrepTable(t, raw, dynamotest.InitialTableSetup{
+			Table: dynamomq.NewCreateTableInput(&dynamomq.CreateQueueTableInput{
+				TableName: tableName,
+			}),
+		})
+	}
+	base, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithTableName(tableNames[0]),
+		dynamomq.WithAWSDynamoDBClient(raw),
+		mock.WithClock(mock.Clock{T: test.DefaultTestDate}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	clients := []dynamomq.Client[test.MessageData]{
+		base,
+		base.(*dynamomq.ClientImpl[test.MessageData]).WithTable(tableNames[1]),
+	}
+	var wg sync.WaitGroup
+	for i, client := range clients {
+		wg.Add(1)
+		go func(client dynamomq.Client[test.MessageData], tableName string) {
+			defer wg.Done()
+			ctx := context.Background()
+			// Both tables hold a message with the same ID, told apart by its data.
+			if _, err := client.SendMessage(ctx, &dynamomq.SendMessageInput[test.MessageData]{
+				ID:   "A-101",
+				Data: test.NewMessageData(tableName),
+			}); err != nil {
+				t.Errorf("SendMessage() to %s error = %v", tableName, err)
+				return
+			}
+			r, err := client.ReceiveMessage(ctx, &dynamomq.ReceiveMessageInput{})
+			if err != nil {
+				t.Errorf("ReceiveMessage() from %s error = %v", tableName, err)
+				return
+			}
+			if got := r.ReceivedMessage.Data.ID; got != tableName {
+				t.Errorf("ReceiveMessage() from %s data ID = %v, want %v", tableName, got, tableName)
+			}
+			if _, err := client.DeleteMessage(ctx, &dynamomq.DeleteMessageInput{ID: "A-101"}); err != nil {
+				t.Errorf("DeleteMessage() from %s error = %v", tableName, err)
+			}
+		}(client, tableNames[i])
+	}
+	wg.Wait()
+	for i, client := range clients {
+		out, err := client.GetMessage(context.Background(), &dynamomq.GetMessageInput{ID: "A-101"})
+		if err != nil {
+			t.Fatalf("GetMessage() from %s error = %v", tableNames[i], err)
+		}
+		if out.Message != nil {
+			t.Errorf("GetMessage() from %s = %v, want nil", tableNames[i], out.Message)
+		}
+	}
+}
+
+func TestClientWithTableShouldRetryReceiveConflicts(t *testing.T) {
+	t.Parallel()
+	now := test.DefaultTestDate.Add(time.Minute)
+	var items []map[string]types.AttributeValue
+	for _, id := range []string{"A-101", "A-102"} {
+		items = append(items, dynamomqtest.MarshalMap(NewTestMessageItemAsReady(id, test.DefaultTestDate)))
+	}
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		mock.WithClock(mock.Clock{T: now}),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+				return &dynamodb.QueryOutput{Items: items}, nil
+			},
+			UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+				id := params.Key[dynamomq.AttributeNameID].(*types.AttributeValueMemberS).Value
+				if id == "A-101" {
+					return nil, &types.ConditionalCheckFailedException{Message: aws.String("version changed")}
+				}
+				return &dynamodb.UpdateItemOutput{
+					Attributes: dynamomqtest.MarshalMap(NewTestMessageItemAsProcessing(id, now)),
+				}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	derived := client.(*dynamomq.ClientImpl[test.MessageData]).WithTable("tenant-b")
+	got, err := derived.ReceiveMessage(context.Background(), &dynamomq.ReceiveMessageInput{})
+	if err != nil {
+		t.Fatalf("ReceiveMessage() error = %v, want the conflict retried", err)
+	}
+	if got.ReceivedMessage.ID != "A-102" {
+		t.Errorf("ReceiveMessage() ID = %s, want A-102", got.ReceivedMessage.ID)
+	}
+}
+
+func TestClientWithTableShouldSample(t *testing.T) {
+	t.Parallel()
+	var sampled []string
+	now := test.DefaultTestDate.Add(time.Minute)
+	client := newTestClient[test.MessageData](t, newSamplingDynamoDB(func() (string, int) { return "A-101", 0 }, now),
+		mock.WithClock(mock.Clock{T: now}),
+		dynamomq.WithSampler(1, func(ctx context.Context, message *dynamomq.Message[test.MessageData]) {
+			sampled = append(sampled, message.ID)
+		}))
+	derived := client.(*dynamomq.ClientImpl[test.MessageData]).WithTable("tenant-b")
+	if _, err := derived.ReceiveMessage(context.Background(), &dynamomq.ReceiveMessageInput{}); err != nil {
+		t.Fatalf("ReceiveMessage() error = %v", err)
+	}
+	test.AssertDeepEqual(t, sampled, []string{"A-101"}, "sampled IDs")
+}
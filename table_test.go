@@ -0,0 +1,187 @@
+package dynamomq_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func newActiveTableDescription() *types.TableDescription {
+	table := newExpectedTableDescription()
+	table.TableStatus = types.TableStatusActive
+	return table
+}
+
+func TestNewCreateTableInput(t *testing.T) {
+	t.Parallel()
+	in := dynamomq.NewCreateTableInput(&dynamomq.CreateQueueTableInput{
+		TableName:          "sample-table",
+		BillingMode:        types.BillingModeProvisioned,
+		ReadCapacityUnits:  5,
+		WriteCapacityUnits: 10,
+		DeletionProtection: true,
+	})
+	if got := aws.ToString(in.TableName); got != "sample-table" {
+		t.Errorf("TableName = %v, want %v", got, "sample-table")
+	}
+	if got := aws.ToString(in.GlobalSecondaryIndexes[0].IndexName); got != "dynamo-mq-index-queue_type-sent_at" {
+		t.Errorf("IndexName = %v, want %v", got, "dynamo-mq-index-queue_type-sent_at")
+	}
+	if !aws.ToBool(in.DeletionProtectionEnabled) {
+		t.Error("DeletionProtectionEnabled = false, want true")
+	}
+	want := &types.ProvisionedThroughput{ReadCapacityUnits: aws.Int64(5), WriteCapacityUnits: aws.Int64(10)}
+	test.AssertDeepEqual(t, in.ProvisionedThroughput, want, "ProvisionedThroughput")
+	test.AssertDeepEqual(t, in.GlobalSecondaryIndexes[0].ProvisionedThroughput, want, "GSI ProvisionedThroughput")
+
+	in = dynamomq.NewCreateTableInput(nil)
+	if in.BillingMode != types.BillingModePayPerRequest {
+		t.Errorf("BillingMode = %v, want %v", in.BillingMode, types.BillingModePayPerRequest)
+	}
+	if in.ProvisionedThroughput != nil {
+		t.Errorf("ProvisionedThroughput = %v, want nil", in.ProvisionedThroughput)
+	}
+}
+
+func TestCreateQueueTable(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name           string
+		createErr      error
+		table          *types.TableDescription
+		ttl            *types.TimeToLiveDescription
+		wantCreated    bool
+		wantTTLUpdated bool
+		wantErr        bool
+	}{
+		{
+			name:           "should create the table and enable TTL",
+			table:          newActiveTableDescription(),
+			ttl:            &types.TimeToLiveDescription{TimeToLiveStatus: types.TimeToLiveStatusDisabled},
+			wantCreated:    true,
+			wantTTLUpdated: true,
+		},
+		{
+			name:      "should succeed when the table already exists and TTL is enabled",
+			createErr: &types.ResourceInUseException{},
+			table:     newActiveTableDescription(),
+			ttl: &types.TimeToLiveDescription{
+				AttributeName:    aws.String("expires_at"),
+				TimeToLiveStatus: types.TimeToLiveStatusEnabled,
+			},
+		},
+		{
+			name:      "should fail when the existing table has a different schema",
+			createErr: &types.ResourceInUseException{},
+			table: func() *types.TableDescription {
+				table := newActiveTableDescription()
+				table.GlobalSecondaryIndexes = nil
+				return table
+			}(),
+			wantErr: true,
+		},
+		{
+			name:      "should fail when the table cannot be created",
+			createErr: test.ErrTest,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var ttlUpdated bool
+			api := &mock.DynamoDB{
+				CreateTableFunc: func(ctx context.Context, params *dynamodb.CreateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error) {
+					return &dynamodb.CreateTableOutput{}, tt.createErr
+				},
+				DescribeTableFunc: func(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+					return &dynamodb.DescribeTableOutput{Table: tt.table}, nil
+				},
+				DescribeTimeToLiveFunc: func(ctx context.Context, params *dynamodb.DescribeTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error) {
+					return &dynamodb.DescribeTimeToLiveOutput{TimeToLiveDescription: tt.ttl}, nil
+				},
+				UpdateTimeToLiveFunc: func(ctx context.Context, params *dynamodb.UpdateTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateTimeToLiveOutput, error) {
+					ttlUpdated = true
+					return &dynamodb.UpdateTimeToLiveOutput{}, nil
+				},
+			}
+			out, err := dynamomq.CreateQueueTable(context.Background(), api, &dynamomq.CreateQueueTableInput{
+				EnableTTLAttribute: "expires_at",
+				MaxWaitDuration:    time.Second,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateQueueTable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if out.Created != tt.wantCreated {
+				t.Errorf("CreateQueueTable() Created = %v, want %v", out.Created, tt.wantCreated)
+			}
+			if ttlUpdated != tt.wantTTLUpdated {
+				t.Errorf("UpdateTimeToLive() called = %v, want %v", ttlUpdated, tt.wantTTLUpdated)
+			}
+		})
+	}
+}
+
+func TestDeleteQueueTable(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		confirm   bool
+		deleteErr error
+		wantErr   error
+	}{
+		{
+			name:    "should fail without confirmation",
+			wantErr: dynamomq.DeletionNotConfirmedError{TableName: "dynamo-mq-table"},
+		},
+		{
+			name:    "should delete the table when confirmed",
+			confirm: true,
+		},
+		{
+			name:      "should succeed when the table does not exist",
+			confirm:   true,
+			deleteErr: &types.ResourceNotFoundException{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var deleted bool
+			api := &mock.DynamoDB{
+				DeleteTableFunc: func(ctx context.Context, params *dynamodb.DeleteTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteTableOutput, error) {
+					deleted = true
+					return &dynamodb.DeleteTableOutput{}, tt.deleteErr
+				},
+				DescribeTableFunc: func(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+					return nil, &types.ResourceNotFoundException{}
+				},
+			}
+			_, err := dynamomq.DeleteQueueTable(context.Background(), api, &dynamomq.DeleteQueueTableInput{
+				Confirm:         tt.confirm,
+				MaxWaitDuration: time.Second,
+			})
+			test.AssertError(t, err, tt.wantErr, "DeleteQueueTable()")
+			if deleted != tt.confirm {
+				t.Errorf("DeleteTable() called = %v, want %v", deleted, tt.confirm)
+			}
+			var notConfirmed dynamomq.DeletionNotConfirmedError
+			if tt.wantErr != nil && !errors.As(err, &notConfirmed) {
+				t.Errorf("DeleteQueueTable() error = %v, want DeletionNotConfirmedError", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package dynamomq
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+)
+
+// normalizeTags returns the tags sorted without duplicates and empty tags, which a string set cannot hold,
+// or nil if none is left.
+func normalizeTags(tags []string) []string {
+	var normalized []string
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// hasTag is the filter selecting the messages stored with the tag.
+func (c *ClientImpl[T]) hasTag(tag string) expression.ConditionBuilder {
+	return expression.Contains(expression.Name(c.schema.TagsAttribute), tag)
+}
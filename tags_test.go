@@ -0,0 +1,285 @@
+package dynamomq_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestDynamoMQClientSendMessageTags(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		tags []string
+		want types.AttributeValue
+	}{
+		{
+			name: "should store the tags as a string set without duplicates",
+			tags: []string{"backfill-2024-06", "", "priority", "backfill-2024-06"},
+			want: &types.AttributeValueMemberSS{Value: []string{"backfill-2024-06", "priority"}},
+		},
+		{
+			name: "should not store the attribute without tags",
+			tags: []string{""},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var put map[string]types.AttributeValue
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+				mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+						return &dynamodb.GetItemOutput{}, nil
+					},
+					PutItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+						put = params.Item
+						return &dynamodb.PutItemOutput{}, nil
+					},
+				}))
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			_, err = dynamomq.NewProducer[test.MessageData](client).Produce(context.Background(), &dynamomq.ProduceInput[test.MessageData]{
+				ID:   "A-101",
+				Data: test.NewMessageData("A-101"),
+				Tags: tt.tags,
+			})
+			if err != nil {
+				t.Fatalf("Produce() error = %v", err)
+			}
+			test.AssertDeepEqual(t, put[dynamomq.AttributeNameTags], tt.want, "tags")
+		})
+	}
+}
+
+// tagFilteringScan returns the ScanFunc of a table holding items, read at most pageSize items at a time, that applies
+// the tag filter of the scan as DynamoDB would. The scans are recorded into scans.
+func tagFilteringScan(t *testing.T, items []map[string]types.AttributeValue, pageSize int,
+	scans *[]*dynamodb.ScanInput) func(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	t.Helper()
+	return func(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+		*scans = append(*scans, params)
+		var tag string
+		for _, v := range params.ExpressionAttributeValues {
+			tag = v.(*types.AttributeValueMemberS).Value
+		}
+		if tag != "" && !strings.Contains(aws.ToString(params.FilterExpression), "contains (") {
+			t.Errorf("Scan() filter expression = %s, want a contains filter", aws.ToString(params.FilterExpression))
+		}
+		var start int
+		if key, ok := params.ExclusiveStartKey[dynamomq.AttributeNameID].(*types.AttributeValueMemberS); ok {
+			start = slices.IndexFunc(items, func(item map[string]types.AttributeValue) bool {
+				return item[dynamomq.AttributeNameID].(*types.AttributeValueMemberS).Value == key.Value
+			}) + 1
+		}
+		end := min(start+min(int(aws.ToInt32(params.Limit)), pageSize), len(items))
+		out := &dynamodb.ScanOutput{}
+		for _, item := range items[start:end] {
+			tags, _ := item[dynamomq.AttributeNameTags].(*types.AttributeValueMemberSS)
+			if tag == "" || tags != nil && slices.Contains(tags.Value, tag) {
+				out.Items = append(out.Items, item)
+			}
+		}
+		if end < len(items) {
+			out.LastEvaluatedKey = map[string]types.AttributeValue{dynamomq.AttributeNameID: items[end-1][dynamomq.AttributeNameID]}
+		}
+		return out, nil
+	}
+}
+
+func TestDynamoMQClientListMessagesTag(t *testing.T) {
+	t.Parallel()
+	var items []map[string]types.AttributeValue
+	for i, m := range []struct {
+		id   string
+		tags []string
+	}{
+		{id: "A-101", tags: []string{"backfill"}},
+		{id: "A-102"},
+		{id: "A-103", tags: []string{"other"}},
+		{id: "A-104", tags: []string{"backfill", "other"}},
+		{id: "A-105", tags: []string{"backfill"}},
+	} {
+		message := NewTestMessageItemAsReady(m.id, test.DefaultTestDate.Add(time.Duration(i)*time.Second))
+		message.Tags = m.tags
+		items = append(items, dynamomqtest.MarshalMap(message))
+	}
+	tests := []struct {
+		name      string
+		input     *dynamomq.ListMessagesInput
+		want      []string
+		wantScans int
+	}{
+		{
+			name:      "should scan the pages until Size messages have the tag",
+			input:     &dynamomq.ListMessagesInput{Size: 2, Tag: "backfill"},
+			want:      []string{"A-101", "A-104"},
+			wantScans: 2,
+		},
+		{
+			name:      "should scan the whole table when fewer messages have the tag",
+			input:     &dynamomq.ListMessagesInput{Size: 10, Tag: "other", OmitData: true},
+			want:      []string{"A-103", "A-104"},
+			wantScans: 3,
+		},
+		{
+			name:      "should scan a single page without a tag",
+			input:     &dynamomq.ListMessagesInput{Size: 2},
+			want:      []string{"A-101", "A-102"},
+			wantScans: 1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var scans []*dynamodb.ScanInput
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					ScanFunc: tagFilteringScan(t, items, 2, &scans),
+				}))
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			out, err := client.ListMessages(context.Background(), tt.input)
+			if err != nil {
+				t.Fatalf("ListMessages() error = %v", err)
+			}
+			var got []string
+			for _, m := range out.Messages {
+				got = append(got, m.ID)
+			}
+			test.AssertDeepEqual(t, got, tt.want, "ListMessages() IDs")
+			if len(scans) != tt.wantScans {
+				t.Errorf("Scan() calls = %d, want %d", len(scans), tt.wantScans)
+			}
+			if tt.input.Tag == "" && scans[0].ExpressionAttributeValues != nil {
+				t.Errorf("Scan() expression attribute values = %v, want none without a tag", scans[0].ExpressionAttributeValues)
+			}
+		})
+	}
+}
+
+func TestDynamoMQClientListMessagesNextToken(t *testing.T) {
+	t.Parallel()
+	var items []map[string]types.AttributeValue
+	for i, m := range []struct {
+		id   string
+		tags []string
+	}{
+		{id: "A-101", tags: []string{"backfill"}},
+		{id: "A-102", tags: []string{"backfill"}},
+		{id: "A-103"},
+		{id: "A-104", tags: []string{"backfill"}},
+		{id: "A-105"},
+	} {
+		message := NewTestMessageItemAsReady(m.id, test.DefaultTestDate.Add(time.Duration(i)*time.Second))
+		message.Tags = m.tags
+		items = append(items, dynamomqtest.MarshalMap(message))
+	}
+	tests := []struct {
+		name      string
+		input     dynamomq.ListMessagesInput
+		wantPages [][]string
+	}{
+		{
+			name:      "should list the pages of the table until NextToken is empty",
+			input:     dynamomq.ListMessagesInput{Size: 2},
+			wantPages: [][]string{{"A-101", "A-102"}, {"A-103", "A-104"}, {"A-105"}},
+		},
+		{
+			name:      "should resume after the last message listed with a tag",
+			input:     dynamomq.ListMessagesInput{Size: 1, Tag: "backfill"},
+			wantPages: [][]string{{"A-101"}, {"A-102"}, {"A-104"}, nil},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var scans []*dynamodb.ScanInput
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					ScanFunc: tagFilteringScan(t, items, 2, &scans),
+				}))
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			var pages [][]string
+			input := tt.input
+			for {
+				out, err := client.ListMessages(context.Background(), &input)
+				if err != nil {
+					t.Fatalf("ListMessages() error = %v", err)
+				}
+				var page []string
+				for _, m := range out.Messages {
+					page = append(page, m.ID)
+				}
+				pages = append(pages, page)
+				if out.NextToken == "" {
+					break
+				}
+				if len(pages) > len(items) {
+					t.Fatalf("ListMessages() pages = %v, want NextToken to end", pages)
+				}
+				input.NextToken = out.NextToken
+			}
+			test.AssertDeepEqual(t, pages, tt.wantPages, "ListMessages() pages")
+		})
+	}
+}
+
+func TestDynamoMQClientListMessagesInvalidNextToken(t *testing.T) {
+	t.Parallel()
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	_, err = client.ListMessages(context.Background(), &dynamomq.ListMessagesInput{NextToken: "not a token"})
+	if _, ok := err.(dynamomq.InvalidNextTokenError); !ok {
+		t.Errorf("ListMessages() error = %v, want InvalidNextTokenError", err)
+	}
+}
+
+func TestDynamoMQClientListMessageSummariesTag(t *testing.T) {
+	t.Parallel()
+	var got *dynamodb.QueryInput
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+			QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+				got = params
+				return &dynamodb.QueryOutput{}, nil
+			},
+		}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	if _, err := client.ListMessageSummaries(context.Background(), &dynamomq.ListMessageSummariesInput{Tag: "backfill"}); err != nil {
+		t.Fatalf("ListMessageSummaries() error = %v", err)
+	}
+	if !strings.Contains(aws.ToString(got.FilterExpression), "contains (") {
+		t.Errorf("Query() filter expression = %s, want a contains filter", aws.ToString(got.FilterExpression))
+	}
+	var values []string
+	for _, v := range got.ExpressionAttributeValues {
+		values = append(values, v.(*types.AttributeValueMemberS).Value)
+	}
+	if !slices.Contains(values, "backfill") {
+		t.Errorf("Query() expression attribute values = %v, want the tag", values)
+	}
+}
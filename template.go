@@ -0,0 +1,267 @@
+package dynamomq
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// TemplateFormat is the infrastructure as code format of a template generated by GenerateTableTemplate.
+type TemplateFormat string
+
+const (
+	// TemplateFormatCloudFormation generates an AWS CloudFormation template in JSON.
+	TemplateFormatCloudFormation TemplateFormat = "cloudformation"
+	// TemplateFormatTerraform generates an aws_dynamodb_table resource of the Terraform AWS provider.
+	TemplateFormatTerraform TemplateFormat = "terraform"
+)
+
+// GenerateTableTemplateInput represents the input parameters for generating the template of the table of a queue.
+type GenerateTableTemplateInput struct {
+	// Format is the format of the template.
+	Format TemplateFormat
+	// Table defines the table as CreateQueueTable would create it. MaxWaitDuration is ignored.
+	// When EnableTTLAttribute is set, the template enables Time to Live on that attribute.
+	Table CreateQueueTableInput
+	// ShardTableNames are the names of the other tables of a queue sharded across tables with ClientImpl.WithTable.
+	// The template defines each of them like the table, with a note that they must stay identical.
+	ShardTableNames []string
+}
+
+// GenerateTableTemplateOutput represents the result of the generate table template operation.
+type GenerateTableTemplateOutput struct {
+	// Template is the generated template.
+	Template []byte
+}
+
+// GenerateTableTemplate returns the definition of the table of a queue in an infrastructure as code format.
+// It is derived from NewCreateTableInput, so the template defines the same keys and queueing index
+// as CreateQueueTable creates and the DynamoMQ client expects.
+func GenerateTableTemplate(params *GenerateTableTemplateInput) (*GenerateTableTemplateOutput, error) {
+	if params == nil {
+		params = &GenerateTableTemplateInput{}
+	}
+	table := withCreateQueueTableDefaults(&params.Table)
+	if err := table.TableSchema.validate(); err != nil {
+		return &GenerateTableTemplateOutput{}, err
+	}
+	inputs := []*dynamodb.CreateTableInput{NewCreateTableInput(&table)}
+	for _, name := range params.ShardTableNames {
+		shard := table
+		shard.TableName = name
+		inputs = append(inputs, NewCreateTableInput(&shard))
+	}
+	var (
+		template []byte
+		err      error
+	)
+	switch params.Format {
+	case TemplateFormatCloudFormation:
+		template, err = cloudFormationTemplate(inputs, table.EnableTTLAttribute)
+	case TemplateFormatTerraform:
+		template = terraformTemplate(inputs, table.EnableTTLAttribute)
+	default:
+		err = InvalidTemplateFormatError{Format: params.Format}
+	}
+	if err != nil {
+		return &GenerateTableTemplateOutput{}, err
+	}
+	return &GenerateTableTemplateOutput{Template: template}, nil
+}
+
+// shardingNote is written to the templates defining the tables of a sharded queue.
+const shardingNote = "The tables of a queue sharded with ClientImpl.WithTable must keep the same definition."
+
+func cloudFormationTemplate(inputs []*dynamodb.CreateTableInput, ttlAttribute string) ([]byte, error) {
+	description := "DynamoDB table of a DynamoMQ queue."
+	if len(inputs) > 1 {
+		description = "DynamoDB tables of a DynamoMQ queue. " + shardingNote
+	}
+	resources := make(map[string]any, len(inputs))
+	for _, in := range inputs {
+		properties := map[string]any{
+			"TableName":                 aws.ToString(in.TableName),
+			"BillingMode":               in.BillingMode,
+			"AttributeDefinitions":      cloudFormationAttributes(in.AttributeDefinitions),
+			"KeySchema":                 cloudFormationKeySchema(in.KeySchema),
+			"DeletionProtectionEnabled": aws.ToBool(in.DeletionProtectionEnabled),
+		}
+		indexes := make([]map[string]any, 0, len(in.GlobalSecondaryIndexes))
+		for _, gsi := range in.GlobalSecondaryIndexes {
+			index := map[string]any{
+				"IndexName":  aws.ToString(gsi.IndexName),
+				"KeySchema":  cloudFormationKeySchema(gsi.KeySchema),
+				"Projection": map[string]any{"ProjectionType": gsi.Projection.ProjectionType},
+			}
+			if gsi.ProvisionedThroughput != nil {
+				index["ProvisionedThroughput"] = cloudFormationThroughput(gsi.ProvisionedThroughput)
+			}
+			indexes = append(indexes, index)
+		}
+		properties["GlobalSecondaryIndexes"] = indexes
+		if in.ProvisionedThroughput != nil {
+			properties["ProvisionedThroughput"] = cloudFormationThroughput(in.ProvisionedThroughput)
+		}
+		if ttlAttribute != "" {
+			properties["TimeToLiveSpecification"] = map[string]any{
+				"AttributeName": ttlAttribute,
+				"Enabled":       true,
+			}
+		}
+		resources[templateIdentifier(aws.ToString(in.TableName), true)] = map[string]any{
+			"Type":       "AWS::DynamoDB::Table",
+			"Properties": properties,
+		}
+	}
+	template, err := json.MarshalIndent(map[string]any{
+		"AWSTemplateFormatVersion": "2010-09-09",
+		"Description":              description,
+		"Resources":                resources,
+	}, "", "  ")
+	if err != nil {
+		return nil, MarshalingAttributeError{Cause: err}
+	}
+	return append(template, '\n'), nil
+}
+
+func cloudFormationAttributes(definitions []types.AttributeDefinition) []map[string]any {
+	attributes := make([]map[string]any, 0, len(definitions))
+	for _, d := range definitions {
+		attributes = append(attributes, map[string]any{
+			"AttributeName": aws.ToString(d.AttributeName),
+			"AttributeType": d.AttributeType,
+		})
+	}
+	return attributes
+}
+
+func cloudFormationKeySchema(elements []types.KeySchemaElement) []map[string]any {
+	keys := make([]map[string]any, 0, len(elements))
+	for _, e := range elements {
+		keys = append(keys, map[string]any{
+			"AttributeName": aws.ToString(e.AttributeName),
+			"KeyType":       e.KeyType,
+		})
+	}
+	return keys
+}
+
+func cloudFormationThroughput(throughput *types.ProvisionedThroughput) map[string]any {
+	return map[string]any{
+		"ReadCapacityUnits":  aws.ToInt64(throughput.ReadCapacityUnits),
+		"WriteCapacityUnits": aws.ToInt64(throughput.WriteCapacityUnits),
+	}
+}
+
+func terraformTemplate(inputs []*dynamodb.CreateTableInput, ttlAttribute string) []byte {
+	var b bytes.Buffer
+	if len(inputs) > 1 {
+		fmt.Fprintf(&b, "# %s\n\n", shardingNote)
+	}
+	for i, in := range inputs {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "resource \"aws_dynamodb_table\" %q {\n", templateIdentifier(aws.ToString(in.TableName), false))
+		attributes := [][2]string{
+			{"name", fmt.Sprintf("%q", aws.ToString(in.TableName))},
+			{"billing_mode", fmt.Sprintf("%q", in.BillingMode)},
+		}
+		attributes = append(attributes, terraformKeys(in.KeySchema)...)
+		if in.ProvisionedThroughput != nil {
+			attributes = append(attributes, terraformThroughput(in.ProvisionedThroughput)...)
+		}
+		attributes = append(attributes, [2]string{"deletion_protection_enabled", fmt.Sprint(aws.ToBool(in.DeletionProtectionEnabled))})
+		writeTerraformAttributes(&b, "  ", attributes)
+		for _, d := range in.AttributeDefinitions {
+			b.WriteString("\n  attribute {\n")
+			writeTerraformAttributes(&b, "    ", [][2]string{
+				{"name", fmt.Sprintf("%q", aws.ToString(d.AttributeName))},
+				{"type", fmt.Sprintf("%q", d.AttributeType)},
+			})
+			b.WriteString("  }\n")
+		}
+		for _, gsi := range in.GlobalSecondaryIndexes {
+			b.WriteString("\n  global_secondary_index {\n")
+			index := [][2]string{{"name", fmt.Sprintf("%q", aws.ToString(gsi.IndexName))}}
+			index = append(index, terraformKeys(gsi.KeySchema)...)
+			index = append(index, [2]string{"projection_type", fmt.Sprintf("%q", gsi.Projection.ProjectionType)})
+			if gsi.ProvisionedThroughput != nil {
+				index = append(index, terraformThroughput(gsi.ProvisionedThroughput)...)
+			}
+			writeTerraformAttributes(&b, "    ", index)
+			b.WriteString("  }\n")
+		}
+		if ttlAttribute != "" {
+			b.WriteString("\n  ttl {\n")
+			writeTerraformAttributes(&b, "    ", [][2]string{
+				{"attribute_name", fmt.Sprintf("%q", ttlAttribute)},
+				{"enabled", "true"},
+			})
+			b.WriteString("  }\n")
+		}
+		b.WriteString("}\n")
+	}
+	return b.Bytes()
+}
+
+func terraformKeys(elements []types.KeySchemaElement) [][2]string {
+	var keys [][2]string
+	for _, e := range elements {
+		name := "hash_key"
+		if e.KeyType == types.KeyTypeRange {
+			name = "range_key"
+		}
+		keys = append(keys, [2]string{name, fmt.Sprintf("%q", aws.ToString(e.AttributeName))})
+	}
+	return keys
+}
+
+func terraformThroughput(throughput *types.ProvisionedThroughput) [][2]string {
+	return [][2]string{
+		{"read_capacity", fmt.Sprint(aws.ToInt64(throughput.ReadCapacityUnits))},
+		{"write_capacity", fmt.Sprint(aws.ToInt64(throughput.WriteCapacityUnits))},
+	}
+}
+
+// writeTerraformAttributes writes the attributes with their equal signs aligned, as terraform fmt does.
+func writeTerraformAttributes(b *bytes.Buffer, indent string, attributes [][2]string) {
+	width := 0
+	for _, a := range attributes {
+		if len(a[0]) > width {
+			width = len(a[0])
+		}
+	}
+	for _, a := range attributes {
+		fmt.Fprintf(b, "%s%-*s = %s\n", indent, width, a[0], a[1])
+	}
+}
+
+// templateIdentifier returns an identifier for the resource of the table: a CloudFormation logical ID in
+// upper camel case, or a Terraform resource name in snake case.
+func templateIdentifier(tableName string, camel bool) string {
+	words := strings.FieldsFunc(tableName, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if !camel {
+		id := strings.ToLower(strings.Join(words, "_"))
+		if id == "" || unicode.IsDigit(rune(id[0])) {
+			id = "table_" + id
+		}
+		return id
+	}
+	var b strings.Builder
+	for _, w := range words {
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	if b.Len() == 0 {
+		return "Table"
+	}
+	return b.String()
+}
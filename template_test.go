@@ -0,0 +1,108 @@
+package dynamomq_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+)
+
+func TestGenerateTableTemplate(t *testing.T) {
+	t.Parallel()
+	sharded := dynamomq.GenerateTableTemplateInput{
+		Table: dynamomq.CreateQueueTableInput{
+			TableName:          "orders-0",
+			BillingMode:        types.BillingModeProvisioned,
+			ReadCapacityUnits:  5,
+			WriteCapacityUnits: 10,
+			EnableTTLAttribute: "expires_at",
+		},
+		ShardTableNames: []string{"orders-1", "orders-2"},
+	}
+	composite := dynamomq.GenerateTableTemplateInput{
+		Table: dynamomq.CreateQueueTableInput{
+			TableName: "app",
+			TableSchema: dynamomq.TableSchema{
+				PartitionKeyAttribute: "pk",
+				PartitionKeyTemplate:  "QUEUE#orders",
+				SortKeyAttribute:      "sk",
+				QueueingIndexName:     "gsi1",
+			},
+		},
+	}
+	tests := []struct {
+		name   string
+		params dynamomq.GenerateTableTemplateInput
+	}{
+		{name: "default", params: dynamomq.GenerateTableTemplateInput{}},
+		{name: "sharded", params: sharded},
+		{name: "composite", params: composite},
+	}
+	formats := map[dynamomq.TemplateFormat]string{
+		dynamomq.TemplateFormatCloudFormation: ".json",
+		dynamomq.TemplateFormatTerraform:      ".tf",
+	}
+	for _, tt := range tests {
+		for format, ext := range formats {
+			params := tt.params
+			params.Format = format
+			out, err := dynamomq.GenerateTableTemplate(&params)
+			if err != nil {
+				t.Fatalf("GenerateTableTemplate(%s, %s) error = %v", tt.name, format, err)
+			}
+			golden := filepath.Join("testdata", "template_"+tt.name+".golden"+ext)
+			if *update {
+				if err := os.WriteFile(golden, out.Template, 0o600); err != nil {
+					t.Fatalf("failed to update golden file: %v", err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			// A difference means that the table the library expects has changed,
+			// so tables provisioned from the previous templates need to be migrated.
+			if string(out.Template) != string(want) {
+				t.Errorf("GenerateTableTemplate(%s, %s) = %s, want %s", tt.name, format, out.Template, want)
+			}
+		}
+	}
+}
+
+func TestGenerateTableTemplateMatchesRepositoryTemplate(t *testing.T) {
+	t.Parallel()
+	out, err := dynamomq.GenerateTableTemplate(&dynamomq.GenerateTableTemplateInput{
+		Format: dynamomq.TemplateFormatTerraform,
+		Table:  dynamomq.CreateQueueTableInput{DeletionProtection: true},
+	})
+	if err != nil {
+		t.Fatalf("GenerateTableTemplate() error = %v", err)
+	}
+	want, err := os.ReadFile("dynamomq-table.tf")
+	if err != nil {
+		t.Fatalf("failed to read dynamomq-table.tf: %v", err)
+	}
+	if string(out.Template) != string(want) {
+		t.Errorf("dynamomq-table.tf has drifted from the generated template, regenerate it with `dynamomq generate-template --format terraform --deletion-protection`:\n%s", out.Template)
+	}
+}
+
+func TestGenerateTableTemplateErrors(t *testing.T) {
+	t.Parallel()
+	_, err := dynamomq.GenerateTableTemplate(&dynamomq.GenerateTableTemplateInput{Format: "yaml"})
+	if !errors.As(err, &dynamomq.InvalidTemplateFormatError{}) {
+		t.Errorf("GenerateTableTemplate() error = %v, want InvalidTemplateFormatError", err)
+	}
+	_, err = dynamomq.GenerateTableTemplate(&dynamomq.GenerateTableTemplateInput{
+		Format: dynamomq.TemplateFormatTerraform,
+		Table: dynamomq.CreateQueueTableInput{
+			TableSchema: dynamomq.TableSchema{SortKeyAttribute: "queue_type"},
+		},
+	})
+	if !errors.As(err, &dynamomq.InvalidTableSchemaError{}) {
+		t.Errorf("GenerateTableTemplate() error = %v, want InvalidTableSchemaError", err)
+	}
+}
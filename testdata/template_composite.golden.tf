@@ -0,0 +1,34 @@
+resource "aws_dynamodb_table" "app" {
+  name                        = "app"
+  billing_mode                = "PAY_PER_REQUEST"
+  hash_key                    = "pk"
+  range_key                   = "sk"
+  deletion_protection_enabled = false
+
+  attribute {
+    name = "pk"
+    type = "S"
+  }
+
+  attribute {
+    name = "queue_type"
+    type = "S"
+  }
+
+  attribute {
+    name = "sent_at"
+    type = "S"
+  }
+
+  attribute {
+    name = "sk"
+    type = "S"
+  }
+
+  global_secondary_index {
+    name            = "gsi1"
+    hash_key        = "queue_type"
+    range_key       = "sent_at"
+    projection_type = "ALL"
+  }
+}
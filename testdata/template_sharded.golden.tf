@@ -0,0 +1,115 @@
+# The tables of a queue sharded with ClientImpl.WithTable must keep the same definition.
+
+resource "aws_dynamodb_table" "orders_0" {
+  name                        = "orders-0"
+  billing_mode                = "PROVISIONED"
+  hash_key                    = "id"
+  read_capacity               = 5
+  write_capacity              = 10
+  deletion_protection_enabled = false
+
+  attribute {
+    name = "id"
+    type = "S"
+  }
+
+  attribute {
+    name = "queue_type"
+    type = "S"
+  }
+
+  attribute {
+    name = "sent_at"
+    type = "S"
+  }
+
+  global_secondary_index {
+    name            = "dynamo-mq-index-queue_type-sent_at"
+    hash_key        = "queue_type"
+    range_key       = "sent_at"
+    projection_type = "ALL"
+    read_capacity   = 5
+    write_capacity  = 10
+  }
+
+  ttl {
+    attribute_name = "expires_at"
+    enabled        = true
+  }
+}
+
+resource "aws_dynamodb_table" "orders_1" {
+  name                        = "orders-1"
+  billing_mode                = "PROVISIONED"
+  hash_key                    = "id"
+  read_capacity               = 5
+  write_capacity              = 10
+  deletion_protection_enabled = false
+
+  attribute {
+    name = "id"
+    type = "S"
+  }
+
+  attribute {
+    name = "queue_type"
+    type = "S"
+  }
+
+  attribute {
+    name = "sent_at"
+    type = "S"
+  }
+
+  global_secondary_index {
+    name            = "dynamo-mq-index-queue_type-sent_at"
+    hash_key        = "queue_type"
+    range_key       = "sent_at"
+    projection_type = "ALL"
+    read_capacity   = 5
+    write_capacity  = 10
+  }
+
+  ttl {
+    attribute_name = "expires_at"
+    enabled        = true
+  }
+}
+
+resource "aws_dynamodb_table" "orders_2" {
+  name                        = "orders-2"
+  billing_mode                = "PROVISIONED"
+  hash_key                    = "id"
+  read_capacity               = 5
+  write_capacity              = 10
+  deletion_protection_enabled = false
+
+  attribute {
+    name = "id"
+    type = "S"
+  }
+
+  attribute {
+    name = "queue_type"
+    type = "S"
+  }
+
+  attribute {
+    name = "sent_at"
+    type = "S"
+  }
+
+  global_secondary_index {
+    name            = "dynamo-mq-index-queue_type-sent_at"
+    hash_key        = "queue_type"
+    range_key       = "sent_at"
+    projection_type = "ALL"
+    read_capacity   = 5
+    write_capacity  = 10
+  }
+
+  ttl {
+    attribute_name = "expires_at"
+    enabled        = true
+  }
+}
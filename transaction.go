@@ -0,0 +1,241 @@
+package dynamomq
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const cancellationReasonConditionalCheckFailed = "ConditionalCheckFailed"
+
+// SendMessageTransactWriteItem builds the conditional Put of a new message so that callers can include it in their own
+// TransactWriteItems call, for example to write a business record and enqueue a message atomically (the outbox pattern).
+// The Put fails with the ConditionalCheckFailed cancellation reason if a message with the same ID already exists.
+func (c *ClientImpl[T]) SendMessageTransactWriteItem(params *SendMessageInput[T]) (*types.TransactWriteItem, error) {
+	item, _, err := c.sendMessageTransactWriteItem(context.Background(), "SendMessageTransactWriteItem", params)
+	return item, err
+}
+
+func (c *ClientImpl[T]) sendMessageTransactWriteItem(ctx context.Context, operation string,
+	params *SendMessageInput[T]) (*types.TransactWriteItem, *Message[T], error) {
+	if params == nil {
+		params = &SendMessageInput[T]{}
+	}
+	if params.ID == "" {
+		return nil, nil, &IDNotProvidedError{}
+	}
+	if err := c.validator.validate(ctx, operation, params.ID, params.Data); err != nil {
+		return nil, nil, err
+	}
+	message := c.newSentMessage(params)
+	item, err := c.marshalItem(message)
+	if err != nil {
+		return nil, nil, MarshalingAttributeError{Cause: err}
+	}
+	expr := c.static.keyNotExists
+	return &types.TransactWriteItem{
+		Put: &types.Put{
+			TableName:                aws.String(c.tableName),
+			Item:                     item,
+			ConditionExpression:      expr.Condition(),
+			ExpressionAttributeNames: expr.Names(),
+		},
+	}, message, nil
+}
+
+// SendMessagesInTransactionInput represents the input parameters for sending messages in a single DynamoDB transaction.
+type SendMessagesInTransactionInput[T any] struct {
+	// Messages are the messages to be sent. Their IDs must be unique within the transaction.
+	Messages []*SendMessageInput[T]
+	// ExtraItems are other write items, such as business records, committed in the same transaction as the messages.
+	ExtraItems []types.TransactWriteItem
+}
+
+// SendMessagesInTransactionOutput represents the result of sending messages in a single DynamoDB transaction.
+type SendMessagesInTransactionOutput[T any] struct {
+	// SentMessages are the messages that were sent, in the order of the input.
+	SentMessages []*Message[T]
+}
+
+// SendMessagesInTransaction sends messages together with other write items in a single DynamoDB transaction.
+// Either all the messages and the extra items are written or none of them is.
+// If a message with the same ID already exists, or the same ID is given twice, an IDDuplicatedError is returned.
+// A failed condition of one of the extra items is returned as a ConditionalCheckFailedError.
+// Once the transaction is committed, the OnSent hook is invoked for each message, in the order of the input.
+func (c *ClientImpl[T]) SendMessagesInTransaction(ctx context.Context,
+	params *SendMessagesInTransactionInput[T]) (*SendMessagesInTransactionOutput[T], error) {
+	ctx, cancel := c.withDefaultTimeout(ctx, "SendMessagesInTransaction")
+	defer cancel()
+	if params == nil {
+		params = &SendMessagesInTransactionInput[T]{}
+	}
+	out := &SendMessagesInTransactionOutput[T]{}
+	items := make([]types.TransactWriteItem, 0, len(params.Messages)+len(params.ExtraItems))
+	messages := make([]*Message[T], 0, len(params.Messages))
+	seen := make(map[string]struct{}, len(params.Messages))
+	for _, m := range params.Messages {
+		item, message, err := c.sendMessageTransactWriteItem(ctx, "SendMessagesInTransaction", m)
+		if err != nil {
+			return out, err
+		}
+		if _, ok := seen[message.ID]; ok {
+			return out, &IDDuplicatedError{}
+		}
+		seen[message.ID] = struct{}{}
+		items = append(items, *item)
+		messages = append(messages, message)
+	}
+	items = append(items, params.ExtraItems...)
+	_, err := c.dynamoDB.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
+	})
+	if err != nil {
+		return out, handleTransactionError(err, func(i int) error {
+			if i < len(messages) {
+				return &IDDuplicatedError{}
+			}
+			return nil
+		})
+	}
+	out.SentMessages = messages
+	for i, message := range messages {
+		c.hooks.sent(ctx, params.Messages[i], &SendMessageOutput[T]{SentMessage: message})
+	}
+	return out, nil
+}
+
+// handleTransactionError maps the cancellation reasons of a TransactWriteItems call to an error.
+// onConditionFailed is called with the index of the first item whose condition failed; if it returns nil,
+// a ConditionalCheckFailedError is returned.
+func handleTransactionError(err error, onConditionFailed func(i int) error) error {
+	var canceled *types.TransactionCanceledException
+	if !errors.As(err, &canceled) {
+		return handleDynamoDBError(err)
+	}
+	for i, reason := range canceled.CancellationReasons {
+		if aws.ToString(reason.Code) != cancellationReasonConditionalCheckFailed {
+			continue
+		}
+		if mapped := onConditionFailed(i); mapped != nil {
+			return mapped
+		}
+		return &ConditionalCheckFailedError{Cause: err}
+	}
+	return handleDynamoDBError(err)
+}
+
+// ChainMessageInput represents the input parameters for deleting a processed message and sending the next one atomically.
+type ChainMessageInput[T any] struct {
+	// DeleteID is the unique identifier of the message to be deleted.
+	DeleteID string
+	// DeleteVersion is the version the message to be deleted must have, typically the version returned by ReceiveMessage.
+	DeleteVersion int
+	// Next is the message to be sent in place of the deleted one.
+	Next *SendMessageInput[T]
+}
+
+// ChainMessageOutput represents the result of the chain message operation.
+type ChainMessageOutput[T any] struct {
+	// Deleted is the result of deleting the processed message.
+	Deleted *DeleteMessageOutput
+	// Sent is the result of sending the next message.
+	Sent *SendMessageOutput[T]
+}
+
+// ChainMessage deletes a processed message and sends the next message in a single DynamoDB transaction,
+// so that a multi-step workflow never loses or repeats a step.
+// The delete is conditional on the message having DeleteVersion. If it has another version or no longer exists,
+// the whole transaction is aborted with a VersionConflictError and nothing is sent.
+// If a message with the ID of the next message already exists, an IDDuplicatedError is returned.
+// When the client has a limit set with WithMaxInFlight, the message is read before the transaction, and its slot
+// is released if it holds one. With WithArchiver, the message is read and archived before the transaction,
+// which is not run if the archive fails.
+// Once the transaction is committed, the OnDeleted hook is invoked for the deleted message, then the OnSent hook
+// for the next message.
+func (c *ClientImpl[T]) ChainMessage(ctx context.Context, params *ChainMessageInput[T]) (*ChainMessageOutput[T], error) {
+	ctx, cancel := c.withDefaultTimeout(ctx, "ChainMessage")
+	defer cancel()
+	if params == nil {
+		params = &ChainMessageInput[T]{}
+	}
+	out := &ChainMessageOutput[T]{}
+	if params.DeleteID == "" {
+		return out, &IDNotProvidedError{}
+	}
+	put, message, err := c.sendMessageTransactWriteItem(ctx, "ChainMessage", params.Next)
+	if err != nil {
+		return out, err
+	}
+	if message.ID == params.DeleteID {
+		return out, &IDDuplicatedError{}
+	}
+	var deleted *Message[T]
+	if c.archiver != nil || c.maxInFlight > 0 {
+		deleted, err = c.readChainedMessage(ctx, params.DeleteID, params.DeleteVersion, c.archiver == nil)
+		if err != nil {
+			return out, err
+		}
+	}
+	if c.archiver != nil {
+		if err := c.archiver.Archive(ctx, deleted); err != nil {
+			return out, ArchiveError{ID: deleted.ID, Cause: err}
+		}
+	}
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.Name(c.schema.VersionAttribute).Equal(expression.Value(params.DeleteVersion))).
+		Build()
+	if err != nil {
+		return out, BuildingExpressionError{Cause: err}
+	}
+	_, err = c.dynamoDB.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{
+				Delete: &types.Delete{
+					TableName:                 aws.String(c.tableName),
+					Key:                       c.itemKey(params.DeleteID),
+					ConditionExpression:       expr.Condition(),
+					ExpressionAttributeNames:  expr.Names(),
+					ExpressionAttributeValues: expr.Values(),
+				},
+			},
+			*put,
+		},
+	})
+	if err != nil {
+		return out, handleTransactionError(err, func(i int) error {
+			if i == 0 {
+				return VersionConflictError{ID: params.DeleteID, Version: params.DeleteVersion}
+			}
+			return &IDDuplicatedError{}
+		})
+	}
+	// The delete is conditioned on the version of the message read, so the message deleted is the one read.
+	if deleted != nil && deleted.InFlightSlot {
+		c.releaseInFlightSlot(ctx, params.DeleteID)
+	}
+	out.Deleted = &DeleteMessageOutput{}
+	out.Sent = &SendMessageOutput[T]{SentMessage: message}
+	c.hooks.deleted(ctx, &DeleteMessageInput{ID: params.DeleteID, StrictExistenceCheck: true}, out.Deleted)
+	c.hooks.sent(ctx, params.Next, out.Sent)
+	return out, nil
+}
+
+// readChainedMessage reads the message ChainMessage is about to delete, which must still be at the version
+// the deletion is conditioned on. The payload is left out with omitData.
+func (c *ClientImpl[T]) readChainedMessage(ctx context.Context, id string, version int, omitData bool) (*Message[T], error) {
+	retrieved, err := c.GetMessage(ctx, &GetMessageInput{
+		ID:       id,
+		OmitData: omitData,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if retrieved.Message == nil || retrieved.Message.Version != version {
+		return nil, VersionConflictError{ID: id, Version: version}
+	}
+	return retrieved.Message, nil
+}
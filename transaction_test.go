@@ -0,0 +1,317 @@
+package dynamomq_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestDynamoMQClientSendMessageTransactWriteItem(t *testing.T) {
+	t.Parallel()
+	client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+		mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+		dynamomq.WithDynamoDBAPI(&mock.DynamoDB{}))
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	_, err = client.SendMessageTransactWriteItem(&dynamomq.SendMessageInput[test.MessageData]{})
+	test.AssertError(t, err, dynamomq.ErrIDNotProvided, "SendMessageTransactWriteItem()")
+
+	got, err := client.SendMessageTransactWriteItem(&dynamomq.SendMessageInput[test.MessageData]{
+		ID:   "A-101",
+		Data: test.NewMessageData("A-101"),
+	})
+	if err != nil {
+		t.Fatalf("SendMessageTransactWriteItem() error = %v", err)
+	}
+	if got.Put == nil {
+		t.Fatalf("SendMessageTransactWriteItem() Put = nil")
+	}
+	if table := aws.ToString(got.Put.TableName); table != "dynamo-mq-table" {
+		t.Errorf("SendMessageTransactWriteItem() TableName = %v, want dynamo-mq-table", table)
+	}
+	want, err := marshalMap(dynamomq.NewMessage("A-101", test.NewMessageData("A-101"), test.DefaultTestDate))
+	if err != nil {
+		t.Fatalf("marshalMap() error = %v", err)
+	}
+	test.AssertDeepEqual(t, got.Put.Item, want, "SendMessageTransactWriteItem() Item")
+	if cond := aws.ToString(got.Put.ConditionExpression); cond != "attribute_not_exists (#0)" {
+		t.Errorf("SendMessageTransactWriteItem() ConditionExpression = %v", cond)
+	}
+	test.AssertDeepEqual(t, got.Put.ExpressionAttributeNames, map[string]string{"#0": "id"},
+		"SendMessageTransactWriteItem() ExpressionAttributeNames")
+}
+
+func TestDynamoMQClientSendMessagesInTransaction(t *testing.T) {
+	t.Parallel()
+	extra := types.TransactWriteItem{
+		Put: &types.Put{
+			TableName: aws.String("orders"),
+			Item: map[string]types.AttributeValue{
+				"order_id": &types.AttributeValueMemberS{Value: "O-1"},
+			},
+		},
+	}
+	tests := []struct {
+		name      string
+		messages  []string
+		txErr     error
+		wantItems int
+		wantErr   error
+		// wantConditionFailed is set when a ConditionalCheckFailedError is expected.
+		wantConditionFailed bool
+	}{
+		{
+			name:      "should send messages with the extra items",
+			messages:  []string{"A-101", "A-102"},
+			wantItems: 3,
+		},
+		{
+			name:      "should return IDDuplicatedError when a message already exists",
+			messages:  []string{"A-101", "A-102"},
+			txErr:     newTransactionCanceledException("None", "ConditionalCheckFailed", "None"),
+			wantItems: 3,
+			wantErr:   dynamomq.ErrIDDuplicated,
+		},
+		{
+			name:     "should return IDDuplicatedError when an ID is given twice",
+			messages: []string{"A-101", "A-101"},
+			wantErr:  dynamomq.ErrIDDuplicated,
+		},
+		{
+			name:                "should return ConditionalCheckFailedError when an extra item fails",
+			messages:            []string{"A-101"},
+			txErr:               newTransactionCanceledException("None", "ConditionalCheckFailed"),
+			wantItems:           2,
+			wantConditionFailed: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var gotItems int
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+				mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					TransactWriteItemsFunc: func(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+						gotItems = len(params.TransactItems)
+						return &dynamodb.TransactWriteItemsOutput{}, tt.txErr
+					},
+				}))
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			params := &dynamomq.SendMessagesInTransactionInput[test.MessageData]{
+				ExtraItems: []types.TransactWriteItem{extra},
+			}
+			for _, id := range tt.messages {
+				params.Messages = append(params.Messages, &dynamomq.SendMessageInput[test.MessageData]{
+					ID:   id,
+					Data: test.NewMessageData(id),
+				})
+			}
+			got, err := client.SendMessagesInTransaction(context.Background(), params)
+			if gotItems != tt.wantItems {
+				t.Errorf("TransactWriteItems() items = %d, want %d", gotItems, tt.wantItems)
+			}
+			if tt.wantConditionFailed {
+				if _, ok := assertErrorType[*dynamomq.ConditionalCheckFailedError](err); !ok {
+					t.Errorf("SendMessagesInTransaction() error = %v, want ConditionalCheckFailedError", err)
+				}
+				return
+			}
+			if tt.wantErr != nil {
+				test.AssertError(t, err, tt.wantErr, "SendMessagesInTransaction()")
+				return
+			}
+			if err != nil {
+				t.Fatalf("SendMessagesInTransaction() error = %v", err)
+			}
+			if len(got.SentMessages) != len(tt.messages) {
+				t.Fatalf("SendMessagesInTransaction() sent = %d, want %d", len(got.SentMessages), len(tt.messages))
+			}
+			for i, id := range tt.messages {
+				want := dynamomq.NewMessage(id, test.NewMessageData(id), test.DefaultTestDate)
+				test.AssertDeepEqual(t, got.SentMessages[i], want, "SendMessagesInTransaction()")
+			}
+		})
+	}
+}
+
+func TestDynamoMQClientChainMessage(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		nextID   string
+		txErr    error
+		wantCall bool
+		wantErr  error
+	}{
+		{
+			name:     "should delete the message and send the next one",
+			nextID:   "B-101",
+			wantCall: true,
+		},
+		{
+			name:     "should abort with VersionConflictError when the version does not match",
+			nextID:   "B-101",
+			txErr:    newTransactionCanceledException("ConditionalCheckFailed", "None"),
+			wantCall: true,
+			wantErr:  dynamomq.VersionConflictError{ID: "A-101", Version: 2},
+		},
+		{
+			name:     "should return IDDuplicatedError when the next message already exists",
+			nextID:   "B-101",
+			txErr:    newTransactionCanceledException("None", "ConditionalCheckFailed"),
+			wantCall: true,
+			wantErr:  dynamomq.ErrIDDuplicated,
+		},
+		{
+			name:    "should return IDDuplicatedError when the next message has the deleted ID",
+			nextID:  "A-101",
+			wantErr: dynamomq.ErrIDDuplicated,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var input *dynamodb.TransactWriteItemsInput
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+				mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					TransactWriteItemsFunc: func(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+						input = params
+						return &dynamodb.TransactWriteItemsOutput{}, tt.txErr
+					},
+				}))
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			got, err := client.ChainMessage(context.Background(), &dynamomq.ChainMessageInput[test.MessageData]{
+				DeleteID:      "A-101",
+				DeleteVersion: 2,
+				Next: &dynamomq.SendMessageInput[test.MessageData]{
+					ID:   tt.nextID,
+					Data: test.NewMessageData(tt.nextID),
+				},
+			})
+			test.AssertError(t, err, tt.wantErr, "ChainMessage()")
+			if (input != nil) != tt.wantCall {
+				t.Fatalf("TransactWriteItems() called = %v, want %v", input != nil, tt.wantCall)
+			}
+			if tt.wantErr != nil {
+				if got.Sent != nil {
+					t.Errorf("ChainMessage() Sent = %v, want nil", got.Sent)
+				}
+				return
+			}
+			items := input.TransactItems
+			if len(items) != 2 || items[0].Delete == nil || items[1].Put == nil {
+				t.Fatalf("TransactWriteItems() items = %v, want a Delete and a Put", items)
+			}
+			test.AssertDeepEqual(t, items[0].Delete.Key,
+				map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "A-101"}}, "Delete.Key")
+			test.AssertDeepEqual(t, items[0].Delete.ExpressionAttributeValues,
+				map[string]types.AttributeValue{":0": &types.AttributeValueMemberN{Value: "2"}}, "Delete.ExpressionAttributeValues")
+			want := dynamomq.NewMessage("B-101", test.NewMessageData("B-101"), test.DefaultTestDate)
+			test.AssertDeepEqual(t, got.Sent.SentMessage, want, "ChainMessage() Sent")
+		})
+	}
+}
+
+func TestDynamoMQClientChainMessageInFlightSlot(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		inFlightSlot bool
+		version      int
+		wantRelease  bool
+		wantErr      error
+	}{
+		{
+			name:         "should release the slot held by the deleted message",
+			inFlightSlot: true,
+			version:      2,
+			wantRelease:  true,
+		},
+		{
+			name:    "should not release a slot the deleted message does not hold",
+			version: 2,
+		},
+		{
+			name:         "should abort with VersionConflictError when the message read has another version",
+			inFlightSlot: true,
+			version:      3,
+			wantErr:      dynamomq.VersionConflictError{ID: "A-101", Version: 2},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var (
+				read        *dynamodb.GetItemInput
+				transaction bool
+				releases    int
+			)
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+				mock.WithClock(mock.Clock{T: test.DefaultTestDate}),
+				dynamomq.WithMaxInFlight(10),
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+						read = params
+						message := NewTestMessageItemAsProcessing("A-101", test.DefaultTestDate)
+						message.Version = tt.version
+						message.InFlightSlot = tt.inFlightSlot
+						return &dynamodb.GetItemOutput{Item: dynamomqtest.MarshalMap(message)}, nil
+					},
+					TransactWriteItemsFunc: func(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+						transaction = true
+						return &dynamodb.TransactWriteItemsOutput{}, nil
+					},
+					UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+						releases++
+						return &dynamodb.UpdateItemOutput{}, nil
+					},
+				}))
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			_, err = client.ChainMessage(context.Background(), &dynamomq.ChainMessageInput[test.MessageData]{
+				DeleteID:      "A-101",
+				DeleteVersion: 2,
+				Next: &dynamomq.SendMessageInput[test.MessageData]{
+					ID:   "B-101",
+					Data: test.NewMessageData("B-101"),
+				},
+			})
+			test.AssertError(t, err, tt.wantErr, "ChainMessage()")
+			if read == nil || read.ProjectionExpression == nil {
+				t.Errorf("GetItem() = %v, want the message read without its payload", read)
+			}
+			if transaction != (tt.wantErr == nil) {
+				t.Errorf("TransactWriteItems() called = %v, want %v", transaction, tt.wantErr == nil)
+			}
+			if want := map[bool]int{true: 1}[tt.wantRelease]; releases != want {
+				t.Errorf("in-flight counter updates = %d, want %d", releases, want)
+			}
+		})
+	}
+}
+
+func newTransactionCanceledException(codes ...string) error {
+	reasons := make([]types.CancellationReason, len(codes))
+	for i, code := range codes {
+		reasons[i] = types.CancellationReason{Code: aws.String(code)}
+	}
+	return &types.TransactionCanceledException{CancellationReasons: reasons}
+}
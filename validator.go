@@ -0,0 +1,77 @@
+package dynamomq
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Validator checks the payload of a message before it is written to the table, for example that its required fields
+// are set or that it matches a schema. A non-nil error aborts the write, and is returned wrapped
+// in a ValidationFailedError.
+type Validator[T any] func(ctx context.Context, data T) error
+
+// WithValidator is an option function to validate the payload of every message before SendMessage,
+// SendMessagesInTransaction, ChainMessage, ReplaceMessage or UpdateMessageData writes it, or before
+// SendMessageTransactWriteItem builds its Put, so that an invalid message is rejected by the producer instead of
+// being discovered by the consumer. The messages of a Producer are validated
+// by the SendMessage of its client.
+func WithValidator[T any](validator Validator[T]) func(*ClientOptions) {
+	return func(s *ClientOptions) {
+		s.Validator = validator
+	}
+}
+
+// WithProducerValidator is an option function to validate the payload of every message in Produce, before it is
+// handed to the client, for producers whose client is not configured with WithValidator.
+// Produce returns an InvalidValidatorError if the validator is not for the type of message of the Producer.
+func WithProducerValidator[T any](validator Validator[T]) func(o *ProducerOptions) {
+	return func(o *ProducerOptions) {
+		o.Validator = validator
+	}
+}
+
+func validatorOf[T any](validator any) (Validator[T], error) {
+	if validator == nil {
+		return nil, nil
+	}
+	v, ok := validator.(Validator[T])
+	if !ok {
+		return nil, InvalidValidatorError{Validator: typeName(validator), MessageType: typeNameOf[T]()}
+	}
+	return v, nil
+}
+
+// validate runs the validator on the payload of the message id written by operation, if there is a validator.
+func (v Validator[T]) validate(ctx context.Context, operation, id string, data T) error {
+	if v == nil {
+		return nil
+	}
+	if err := v(ctx, data); err != nil {
+		return ValidationFailedError{ID: id, Operation: operation, Cause: err}
+	}
+	return nil
+}
+
+// JSONSchemaValidator adapts a JSON schema to a Validator. The payload is encoded to JSON and decoded again into
+// the generic form JSON schema libraries validate, a tree of map[string]any, []any, json.Number, string, bool and nil,
+// which is passed to validate. For example, with github.com/santhosh-tekuri/jsonschema:
+//
+//	schema, err := jsonschema.NewCompiler().Compile("order.schema.json")
+//	validator := dynamomq.JSONSchemaValidator[Order](schema.Validate)
+func JSONSchemaValidator[T any](validate func(doc any) error) Validator[T] {
+	return func(ctx context.Context, data T) error {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("encoding the payload to JSON: %w", err)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		var doc any
+		if err := decoder.Decode(&doc); err != nil {
+			return fmt.Errorf("decoding the payload from JSON: %w", err)
+		}
+		return validate(doc)
+	}
+}
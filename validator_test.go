@@ -0,0 +1,238 @@
+package dynamomq_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/dynamomqtest"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+var errMissingID = errors.New("id is required")
+
+// requireID rejects the payloads without an ID.
+func requireID(ctx context.Context, data test.MessageData) error {
+	if data.ID == "" {
+		return errMissingID
+	}
+	return nil
+}
+
+// newWriteCountingDynamoDB returns a table without items, which counts the items written into it into writes.
+func newWriteCountingDynamoDB(writes *int) *mock.DynamoDB {
+	return &mock.DynamoDB{
+		GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+			return &dynamodb.GetItemOutput{}, nil
+		},
+		PutItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+			*writes++
+			return &dynamodb.PutItemOutput{}, nil
+		},
+		UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+			*writes++
+			message := NewTestMessageItemAsReady("A-101", test.DefaultTestDate)
+			return &dynamodb.UpdateItemOutput{Attributes: dynamomqtest.MarshalMap(message)}, nil
+		},
+	}
+}
+
+func TestDynamoMQClientValidator(t *testing.T) {
+	t.Parallel()
+	operations := []struct {
+		name string
+		call func(client dynamomq.Client[test.MessageData], data test.MessageData) error
+	}{
+		{
+			name: "SendMessage",
+			call: func(client dynamomq.Client[test.MessageData], data test.MessageData) error {
+				_, err := client.SendMessage(context.Background(), &dynamomq.SendMessageInput[test.MessageData]{ID: "A-101", Data: data})
+				return err
+			},
+		},
+		{
+			name: "ReplaceMessage",
+			call: func(client dynamomq.Client[test.MessageData], data test.MessageData) error {
+				_, err := client.ReplaceMessage(context.Background(), &dynamomq.ReplaceMessageInput[test.MessageData]{
+					Message: dynamomq.NewMessage("A-101", data, test.DefaultTestDate),
+				})
+				return err
+			},
+		},
+		{
+			name: "UpdateMessageData",
+			call: func(client dynamomq.Client[test.MessageData], data test.MessageData) error {
+				_, err := client.UpdateMessageData(context.Background(), &dynamomq.UpdateMessageDataInput[test.MessageData]{ID: "A-101", Data: data})
+				return err
+			},
+		},
+	}
+	for _, op := range operations {
+		op := op
+		t.Run(op.name, func(t *testing.T) {
+			t.Parallel()
+			var writes int
+			client := newTestClient[test.MessageData](t, newWriteCountingDynamoDB(&writes),
+				mock.WithClock(mock.Clock{T: test.DefaultTestDate}), dynamomq.WithValidator(dynamomq.Validator[test.MessageData](requireID)))
+
+			err := op.call(client, test.MessageData{})
+			test.AssertError(t, err, errMissingID, op.name+"()")
+			var validationErr dynamomq.ValidationFailedError
+			if !errors.As(err, &validationErr) || validationErr.ID != "A-101" || validationErr.Operation != op.name {
+				t.Errorf("%s() error = %v, want a ValidationFailedError of A-101", op.name, err)
+			}
+			if writes != 0 {
+				t.Fatalf("%s() wrote %d items for an invalid message, want none", op.name, writes)
+			}
+
+			if err := op.call(client, test.NewMessageData("A-101")); err != nil {
+				t.Fatalf("%s() error = %v", op.name, err)
+			}
+			if writes != 1 {
+				t.Errorf("%s() wrote %d items for a valid message, want 1", op.name, writes)
+			}
+		})
+	}
+}
+
+func TestDynamoMQClientValidatorOnTable(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	client, clean := prepareTestClient(ctx, t, NewSetupFunc(newPutRequestWithReadyItem("A-101", test.DefaultTestDate)),
+		mock.Clock{T: test.DefaultTestDate}, false, nil, nil, nil,
+		dynamomq.WithValidator(dynamomq.Validator[test.MessageData](requireID)))
+	defer clean()
+	_, err := client.SendMessage(ctx, &dynamomq.SendMessageInput[test.MessageData]{ID: "A-102"})
+	test.AssertError(t, err, errMissingID, "SendMessage()")
+	_, err = client.UpdateMessageData(ctx, &dynamomq.UpdateMessageDataInput[test.MessageData]{ID: "A-101"})
+	test.AssertError(t, err, errMissingID, "UpdateMessageData()")
+	_, err = client.ReplaceMessage(ctx, &dynamomq.ReplaceMessageInput[test.MessageData]{
+		Message: dynamomq.NewMessage("A-101", test.MessageData{}, test.DefaultTestDate),
+	})
+	test.AssertError(t, err, errMissingID, "ReplaceMessage()")
+	got, err := client.ListMessages(ctx, &dynamomq.ListMessagesInput{})
+	if err != nil {
+		t.Fatalf("ListMessages() error = %v", err)
+	}
+	if len(got.Messages) != 1 || got.Messages[0].ID != "A-101" || got.Messages[0].Version != 1 {
+		t.Fatalf("ListMessages() = %v, want A-101 left as it was", got.Messages)
+	}
+	test.AssertDeepEqual(t, got.Messages[0].Data, test.NewMessageData("A-101"), "ListMessages() data after invalid writes")
+
+	data := test.NewMessageData("A-101")
+	data.Data1 = "updated"
+	if _, err = client.UpdateMessageData(ctx, &dynamomq.UpdateMessageDataInput[test.MessageData]{ID: "A-101", Data: data}); err != nil {
+		t.Fatalf("UpdateMessageData() error = %v", err)
+	}
+	updated, err := client.GetMessage(ctx, &dynamomq.GetMessageInput{ID: "A-101"})
+	if err != nil {
+		t.Fatalf("GetMessage() error = %v", err)
+	}
+	test.AssertDeepEqual(t, updated.Message.Data, data, "GetMessage() data after a valid write")
+}
+
+func TestDynamoMQClientValidatorSendMessageTransactWriteItem(t *testing.T) {
+	t.Parallel()
+	var writes int
+	client := newTestClient[test.MessageData](t, newWriteCountingDynamoDB(&writes),
+		mock.WithClock(mock.Clock{T: test.DefaultTestDate}), dynamomq.WithValidator(dynamomq.Validator[test.MessageData](requireID)))
+	_, err := client.SendMessageTransactWriteItem(&dynamomq.SendMessageInput[test.MessageData]{ID: "A-101"})
+	test.AssertError(t, err, errMissingID, "SendMessageTransactWriteItem()")
+	item, err := client.SendMessageTransactWriteItem(&dynamomq.SendMessageInput[test.MessageData]{
+		ID:   "A-101",
+		Data: test.NewMessageData("A-101"),
+	})
+	if err != nil || item.Put == nil {
+		t.Errorf("SendMessageTransactWriteItem() = %v, %v, want a Put", item, err)
+	}
+}
+
+func TestProducerValidator(t *testing.T) {
+	t.Parallel()
+	var sent int
+	client := &mock.Client[test.MessageData]{
+		SendMessageFunc: func(ctx context.Context, params *dynamomq.SendMessageInput[test.MessageData]) (*dynamomq.SendMessageOutput[test.MessageData], error) {
+			sent++
+			return &dynamomq.SendMessageOutput[test.MessageData]{
+				SentMessage: dynamomq.NewMessage(params.ID, params.Data, test.DefaultTestDate),
+			}, nil
+		},
+	}
+	producer := dynamomq.NewProducer[test.MessageData](client,
+		dynamomq.WithProducerValidator(dynamomq.Validator[test.MessageData](requireID)))
+	_, err := producer.Produce(context.Background(), &dynamomq.ProduceInput[test.MessageData]{ID: "A-101"})
+	test.AssertError(t, err, errMissingID, "Produce()")
+	if _, err := producer.Produce(context.Background(), &dynamomq.ProduceInput[test.MessageData]{
+		ID:   "A-101",
+		Data: test.NewMessageData("A-101"),
+	}); err != nil {
+		t.Fatalf("Produce() error = %v", err)
+	}
+	if sent != 1 {
+		t.Errorf("SendMessage() calls = %d, want 1", sent)
+	}
+}
+
+func TestValidatorOfAnotherType(t *testing.T) {
+	t.Parallel()
+	validator := dynamomq.Validator[json.RawMessage](func(ctx context.Context, data json.RawMessage) error { return nil })
+	_, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{}, dynamomq.WithValidator(validator))
+	var clientErr dynamomq.InvalidValidatorError
+	if !errors.As(err, &clientErr) {
+		t.Errorf("NewFromConfig() error = %v, want an InvalidValidatorError", err)
+	}
+	producer := dynamomq.NewProducer[test.MessageData](&mock.Client[test.MessageData]{},
+		dynamomq.WithProducerValidator(validator))
+	_, err = producer.Produce(context.Background(), &dynamomq.ProduceInput[test.MessageData]{})
+	var producerErr dynamomq.InvalidValidatorError
+	if !errors.As(err, &producerErr) {
+		t.Errorf("Produce() error = %v, want an InvalidValidatorError", err)
+	}
+}
+
+func TestJSONSchemaValidator(t *testing.T) {
+	t.Parallel()
+	type invoice struct {
+		ID     string `json:"id"`
+		Amount int64  `json:"amount"`
+	}
+	// requirePositiveAmount stands in for a JSON schema requiring a string "id" and a positive integer "amount".
+	requirePositiveAmount := func(doc any) error {
+		object, ok := doc.(map[string]any)
+		if !ok {
+			return fmt.Errorf("want an object, got %T", doc)
+		}
+		if id, ok := object["id"].(string); !ok || id == "" {
+			return errors.New(`"id" is required`)
+		}
+		amount, ok := object["amount"].(json.Number)
+		if !ok {
+			return fmt.Errorf(`"amount" must be a number, got %T`, object["amount"])
+		}
+		if n, err := amount.Int64(); err != nil || n <= 0 {
+			return errors.New(`"amount" must be a positive integer`)
+		}
+		return nil
+	}
+	validator := dynamomq.JSONSchemaValidator[invoice](requirePositiveAmount)
+	tests := []struct {
+		name    string
+		data    invoice
+		wantErr bool
+	}{
+		{name: "should pass a valid payload", data: invoice{ID: "A-101", Amount: 9007199254740993}},
+		{name: "should reject a payload without an ID", data: invoice{Amount: 1}, wantErr: true},
+		{name: "should reject a payload with a negative amount", data: invoice{ID: "A-101", Amount: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := validator(context.Background(), tt.data); (err != nil) != tt.wantErr {
+			t.Errorf("%s: JSONSchemaValidator() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
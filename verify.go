@@ -0,0 +1,221 @@
+package dynamomq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+// verifyIDPrefix prefixes the ID of the throwaway item on which VerifyQueue checks the permissions.
+const verifyIDPrefix = "dynamomq-verify-"
+
+// Names of the checks of VerifyQueue.
+const (
+	VerificationCheckTableExists    = "table exists"
+	VerificationCheckTableActive    = "table is active"
+	VerificationCheckTableKey       = "table key schema"
+	VerificationCheckQueueingIndex  = "queueing index"
+	VerificationCheckTimeToLive     = "time to live"
+	VerificationCheckQueryAllowed   = "Query allowed"
+	VerificationCheckGetItemAllowed = "GetItem allowed"
+	VerificationCheckPutItemAllowed = "PutItem allowed"
+	VerificationCheckUpdateAllowed  = "UpdateItem allowed"
+	VerificationCheckDeleteAllowed  = "DeleteItem allowed"
+)
+
+// VerifyQueueInput represents the input parameters for verifying a queue.
+type VerifyQueueInput struct {
+	// ExpectedTTLAttribute is the attribute on which Time to Live must be enabled,
+	// such as when the retention of the messages relies on it. When it is empty, Time to Live is not checked.
+	ExpectedTTLAttribute string
+}
+
+// VerificationCheck is an item of the checklist of VerifyQueue.
+type VerificationCheck struct {
+	// Name is the name of the check, one of the VerificationCheck constants.
+	Name string `json:"name"`
+	// Passed is true if the check succeeded.
+	Passed bool `json:"passed"`
+	// Detail explains why the check failed.
+	Detail string `json:"detail,omitempty"`
+}
+
+// VerifyQueueOutput represents the checklist of VerifyQueue.
+type VerifyQueueOutput struct {
+	// TableName is the name of the table used by the client.
+	TableName string `json:"table_name"`
+	// Checks are the checks performed, in order.
+	Checks []VerificationCheck `json:"checks"`
+}
+
+// Passed reports whether every check succeeded.
+func (o *VerifyQueueOutput) Passed() bool {
+	for _, check := range o.Checks {
+		if !check.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// VerifyQueue checks that the table used by the client is ready for use: it exists and is active, its primary key
+// and its queueing index match the TableSchema, as validated by ValidateSchema, Time to Live is enabled on
+// the ExpectedTTLAttribute if any, and the credentials of the client are allowed to call Query, GetItem, PutItem,
+// UpdateItem and DeleteItem on the table.
+//
+// The permissions are checked with calls on a throwaway item whose ID is random. The writes are conditioned on
+// the existence of that item, so they fail with a conditional check failure once they are allowed,
+// and nothing is written to the table. A failed check does not make VerifyQueue return an error;
+// an error is only returned when the table cannot be described for another reason than missing permissions.
+func (c *ClientImpl[T]) VerifyQueue(ctx context.Context, params *VerifyQueueInput) (*VerifyQueueOutput, error) {
+	if params == nil {
+		params = &VerifyQueueInput{}
+	}
+	out := &VerifyQueueOutput{
+		TableName: c.tableName,
+	}
+	described, err := c.dynamoDB.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+		TableName: &c.tableName,
+	})
+	var resourceNotFound *types.ResourceNotFoundException
+	switch {
+	case errors.As(err, &resourceNotFound):
+		out.fail(VerificationCheckTableExists, fmt.Sprintf("table '%s' does not exist", c.tableName))
+		return out, nil
+	case isAccessDenied(err):
+		out.fail(VerificationCheckTableExists, fmt.Sprintf("DescribeTable was denied: %v", err))
+	case err != nil:
+		return out, handleDynamoDBError(err)
+	case described.Table == nil:
+		out.fail(VerificationCheckTableExists, "table description is empty")
+	default:
+		out.pass(VerificationCheckTableExists)
+		verifyTable(out, described.Table, c.schema)
+	}
+	if params.ExpectedTTLAttribute != "" {
+		if err := c.verifyTimeToLive(ctx, out, params.ExpectedTTLAttribute); err != nil {
+			return out, err
+		}
+	}
+	c.verifyPermissions(ctx, out)
+	return out, nil
+}
+
+func verifyTable(out *VerifyQueueOutput, table *types.TableDescription, schema TableSchema) {
+	if table.TableStatus == types.TableStatusActive {
+		out.pass(VerificationCheckTableActive)
+	} else {
+		out.fail(VerificationCheckTableActive, fmt.Sprintf("table is %s", table.TableStatus))
+	}
+	out.check(VerificationCheckTableKey, checkTableKey(table, schema))
+	out.check(VerificationCheckQueueingIndex, checkQueueingIndex(table, schema))
+}
+
+func (c *ClientImpl[T]) verifyTimeToLive(ctx context.Context, out *VerifyQueueOutput, attribute string) error {
+	ttl, err := c.dynamoDB.DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{
+		TableName: &c.tableName,
+	})
+	switch {
+	case isAccessDenied(err):
+		out.fail(VerificationCheckTimeToLive, fmt.Sprintf("DescribeTimeToLive was denied: %v", err))
+		return nil
+	case err != nil:
+		return handleDynamoDBError(err)
+	}
+	described := &DescribeQueueOutput{}
+	describeTimeToLive(described, ttl.TimeToLiveDescription, attribute)
+	if !described.TTLEnabled {
+		out.fail(VerificationCheckTimeToLive, fmt.Sprintf("TTL is not enabled on '%s'", attribute))
+		return nil
+	}
+	out.pass(VerificationCheckTimeToLive)
+	return nil
+}
+
+func (c *ClientImpl[T]) verifyPermissions(ctx context.Context, out *VerifyQueueOutput) {
+	id := verifyIDPrefix + uuid.NewString()
+	key := c.schema.key(id)
+	// The item does not exist, so the writes conditioned on its existence are never performed.
+	condition := aws.String("attribute_exists(#pk)")
+	names := map[string]string{"#pk": c.schema.PartitionKeyAttribute}
+	_, err := c.dynamoDB.Query(ctx, &dynamodb.QueryInput{
+		TableName:              &c.tableName,
+		IndexName:              aws.String(c.schema.QueueingIndexName),
+		KeyConditionExpression: aws.String("#queue_type = :queue_type"),
+		ExpressionAttributeNames: map[string]string{
+			"#queue_type": c.schema.QueueTypeAttribute,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			// No message is in the queue type named after the throwaway item, so nothing is read.
+			":queue_type": &types.AttributeValueMemberS{Value: id},
+		},
+		Limit: aws.Int32(1),
+	})
+	out.checkPermission(VerificationCheckQueryAllowed, err)
+	_, err = c.dynamoDB.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: &c.tableName,
+		Key:       key,
+	})
+	out.checkPermission(VerificationCheckGetItemAllowed, err)
+	_, err = c.dynamoDB.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                &c.tableName,
+		Item:                     key,
+		ConditionExpression:      condition,
+		ExpressionAttributeNames: names,
+	})
+	out.checkPermission(VerificationCheckPutItemAllowed, err)
+	_, err = c.dynamoDB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           &c.tableName,
+		Key:                 key,
+		UpdateExpression:    aws.String("REMOVE #data"),
+		ConditionExpression: condition,
+		ExpressionAttributeNames: map[string]string{
+			"#pk":   c.schema.PartitionKeyAttribute,
+			"#data": c.schema.DataAttribute,
+		},
+	})
+	out.checkPermission(VerificationCheckUpdateAllowed, err)
+	_, err = c.dynamoDB.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:                &c.tableName,
+		Key:                      key,
+		ConditionExpression:      condition,
+		ExpressionAttributeNames: names,
+	})
+	out.checkPermission(VerificationCheckDeleteAllowed, err)
+}
+
+func (o *VerifyQueueOutput) pass(name string) {
+	o.Checks = append(o.Checks, VerificationCheck{Name: name, Passed: true})
+}
+
+func (o *VerifyQueueOutput) fail(name, detail string) {
+	o.Checks = append(o.Checks, VerificationCheck{Name: name, Detail: detail})
+}
+
+func (o *VerifyQueueOutput) check(name string, problems []string) {
+	if len(problems) > 0 {
+		o.fail(name, strings.Join(problems, "; "))
+		return
+	}
+	o.pass(name)
+}
+
+// checkPermission passes the check of a call that succeeded or failed on its condition,
+// as the condition is only evaluated once the call is allowed.
+func (o *VerifyQueueOutput) checkPermission(name string, err error) {
+	var conditionalCheckFailed *types.ConditionalCheckFailedException
+	switch {
+	case err == nil, errors.As(err, &conditionalCheckFailed):
+		o.pass(name)
+	case isAccessDenied(err):
+		o.fail(name, fmt.Sprintf("denied: %v", err))
+	default:
+		o.fail(name, err.Error())
+	}
+}
@@ -0,0 +1,192 @@
+package dynamomq_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/vvatanabe/dynamomq"
+	"github.com/vvatanabe/dynamomq/internal/mock"
+	"github.com/vvatanabe/dynamomq/internal/test"
+)
+
+func TestDynamoMQClientVerifyQueue(t *testing.T) {
+	t.Parallel()
+	accessDenied := &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "denied"}
+	conditionFailed := &types.ConditionalCheckFailedException{}
+	passed := func(names ...string) []dynamomq.VerificationCheck {
+		checks := make([]dynamomq.VerificationCheck, len(names))
+		for i, name := range names {
+			checks[i] = dynamomq.VerificationCheck{Name: name, Passed: true}
+		}
+		return checks
+	}
+	permissions := []string{
+		dynamomq.VerificationCheckQueryAllowed,
+		dynamomq.VerificationCheckGetItemAllowed,
+		dynamomq.VerificationCheckPutItemAllowed,
+		dynamomq.VerificationCheckUpdateAllowed,
+		dynamomq.VerificationCheckDeleteAllowed,
+	}
+	tests := []struct {
+		name       string
+		table      *types.TableDescription
+		tableErr   error
+		ttl        *types.TimeToLiveDescription
+		putErr     error
+		input      *dynamomq.VerifyQueueInput
+		want       []dynamomq.VerificationCheck
+		wantPassed bool
+		wantErr    bool
+		noWrites   bool
+	}{
+		{
+			name:  "should pass every check of a ready queue",
+			table: newActiveTableDescription(),
+			ttl: &types.TimeToLiveDescription{
+				AttributeName:    aws.String("expires_at"),
+				TimeToLiveStatus: types.TimeToLiveStatusEnabled,
+			},
+			putErr: conditionFailed,
+			input:  &dynamomq.VerifyQueueInput{ExpectedTTLAttribute: "expires_at"},
+			want: passed(append([]string{
+				dynamomq.VerificationCheckTableExists,
+				dynamomq.VerificationCheckTableActive,
+				dynamomq.VerificationCheckTableKey,
+				dynamomq.VerificationCheckQueueingIndex,
+				dynamomq.VerificationCheckTimeToLive,
+			}, permissions...)...),
+			wantPassed: true,
+		},
+		{
+			name: "should fail the checks of a mismatched index, a disabled TTL and a denied PutItem",
+			table: func() *types.TableDescription {
+				table := newActiveTableDescription()
+				table.GlobalSecondaryIndexes[0].Projection = &types.Projection{ProjectionType: types.ProjectionTypeKeysOnly}
+				return table
+			}(),
+			ttl:    &types.TimeToLiveDescription{TimeToLiveStatus: types.TimeToLiveStatusDisabled},
+			putErr: accessDenied,
+			input:  &dynamomq.VerifyQueueInput{ExpectedTTLAttribute: "expires_at"},
+			want: []dynamomq.VerificationCheck{
+				{Name: dynamomq.VerificationCheckTableExists, Passed: true},
+				{Name: dynamomq.VerificationCheckTableActive, Passed: true},
+				{Name: dynamomq.VerificationCheckTableKey, Passed: true},
+				{
+					Name:   dynamomq.VerificationCheckQueueingIndex,
+					Detail: "index 'dynamo-mq-index-queue_type-sent_at' must project all attributes",
+				},
+				{Name: dynamomq.VerificationCheckTimeToLive, Detail: "TTL is not enabled on 'expires_at'"},
+				{Name: dynamomq.VerificationCheckQueryAllowed, Passed: true},
+				{Name: dynamomq.VerificationCheckGetItemAllowed, Passed: true},
+				{Name: dynamomq.VerificationCheckPutItemAllowed, Detail: "denied: api error AccessDeniedException: denied"},
+				{Name: dynamomq.VerificationCheckUpdateAllowed, Passed: true},
+				{Name: dynamomq.VerificationCheckDeleteAllowed, Passed: true},
+			},
+		},
+		{
+			name: "should not check TTL when it is not expected and report an inactive table",
+			table: func() *types.TableDescription {
+				table := newExpectedTableDescription()
+				table.TableStatus = types.TableStatusCreating
+				return table
+			}(),
+			want: append([]dynamomq.VerificationCheck{
+				{Name: dynamomq.VerificationCheckTableExists, Passed: true},
+				{Name: dynamomq.VerificationCheckTableActive, Detail: "table is CREATING"},
+				{Name: dynamomq.VerificationCheckTableKey, Passed: true},
+				{Name: dynamomq.VerificationCheckQueueingIndex, Passed: true},
+			}, passed(permissions...)...),
+		},
+		{
+			name:     "should check the permissions when DescribeTable is denied",
+			tableErr: accessDenied,
+			want: append([]dynamomq.VerificationCheck{
+				{
+					Name:   dynamomq.VerificationCheckTableExists,
+					Detail: "DescribeTable was denied: api error AccessDeniedException: denied",
+				},
+			}, passed(permissions...)...),
+		},
+		{
+			name:     "should stop when the table does not exist",
+			tableErr: &types.ResourceNotFoundException{},
+			want: []dynamomq.VerificationCheck{
+				{Name: dynamomq.VerificationCheckTableExists, Detail: "table 'dynamo-mq-table' does not exist"},
+			},
+			noWrites: true,
+		},
+		{
+			name:     "should return an error when the table cannot be described",
+			tableErr: test.ErrTest,
+			wantErr:  true,
+			noWrites: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var writes []string
+			record := func(operation string, key map[string]types.AttributeValue, condition *string) {
+				id, _ := key["id"].(*types.AttributeValueMemberS)
+				if id == nil || !strings.HasPrefix(id.Value, "dynamomq-verify-") || aws.ToString(condition) == "" {
+					t.Errorf("%s() is not conditioned on a throwaway item, key = %v, condition = %v",
+						operation, key, aws.ToString(condition))
+				}
+				writes = append(writes, operation)
+			}
+			client, err := dynamomq.NewFromConfig[test.MessageData](aws.Config{},
+				dynamomq.WithDynamoDBAPI(&mock.DynamoDB{
+					DescribeTableFunc: func(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+						if tt.tableErr != nil {
+							return nil, tt.tableErr
+						}
+						return &dynamodb.DescribeTableOutput{Table: tt.table}, nil
+					},
+					DescribeTimeToLiveFunc: func(ctx context.Context, params *dynamodb.DescribeTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error) {
+						return &dynamodb.DescribeTimeToLiveOutput{TimeToLiveDescription: tt.ttl}, nil
+					},
+					QueryFunc: func(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+						return &dynamodb.QueryOutput{}, nil
+					},
+					GetItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+						return &dynamodb.GetItemOutput{}, nil
+					},
+					PutItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+						record("PutItem", params.Item, params.ConditionExpression)
+						return nil, tt.putErr
+					},
+					UpdateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+						record("UpdateItem", params.Key, params.ConditionExpression)
+						return nil, conditionFailed
+					},
+					DeleteItemFunc: func(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+						record("DeleteItem", params.Key, params.ConditionExpression)
+						return nil, conditionFailed
+					},
+				}))
+			if err != nil {
+				t.Fatalf("NewFromConfig() error = %v", err)
+			}
+			got, err := client.VerifyQueue(context.Background(), tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyQueue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.noWrites && len(writes) > 0 {
+				t.Errorf("VerifyQueue() wrote with %v, want no write", writes)
+			}
+			if tt.wantErr {
+				return
+			}
+			test.AssertDeepEqual(t, got, &dynamomq.VerifyQueueOutput{TableName: "dynamo-mq-table", Checks: tt.want}, "VerifyQueue()")
+			if got.Passed() != tt.wantPassed {
+				t.Errorf("Passed() = %v, want %v", got.Passed(), tt.wantPassed)
+			}
+		})
+	}
+}